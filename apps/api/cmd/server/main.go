@@ -14,12 +14,17 @@ import (
 	accountrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/account"
 	activityrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/activity"
 	activitytyperepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/activity_type"
+	aiconversationrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/ai_conversation"
+	aimodelusagerepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/ai_model_usage"
 	aisettingsrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/ai_settings"
+	auditlogrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/audit_log"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/postgres/auth"
 	categoryrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/category"
 	contactrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/contact"
 	contactrolerepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/contact_role"
 	dealrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/deal"
+	dealitemrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/deal_item"
+	dealstagehistoryrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/deal_stage_history"
 	leadrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/lead"
 	notificationrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/notification"
 	permissionrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/permission"
@@ -30,13 +35,28 @@ import (
 	reminderrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/reminder"
 	rolerepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/role"
 	taskrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/task"
+	duplicaterepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/duplicate"
+	importjobrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/import_job"
+	leadassignmentrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/lead_assignment"
+	leadscoringrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/lead_scoring"
+	salestargetrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/sales_target"
+	reportsubscriptionrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/report_subscription"
+	quotationrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/quotation"
+	lossreasonrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/loss_reason"
+	competitorrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/competitor"
+	emailoutboxrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/email_outbox"
+	messagingrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/messaging"
+	teamrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/team"
 	userrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/user"
 	visitreportrepo "github.com/gilabs/crm-healthcare/api/internal/repository/postgres/visit_report"
 	accountservice "github.com/gilabs/crm-healthcare/api/internal/service/account"
 	activityservice "github.com/gilabs/crm-healthcare/api/internal/service/activity"
 	activitytypeservice "github.com/gilabs/crm-healthcare/api/internal/service/activity_type"
 	aiservice "github.com/gilabs/crm-healthcare/api/internal/service/ai"
+	aiconversationservice "github.com/gilabs/crm-healthcare/api/internal/service/ai_conversation"
 	aisettingsservice "github.com/gilabs/crm-healthcare/api/internal/service/ai_settings"
+	aiusageservice "github.com/gilabs/crm-healthcare/api/internal/service/ai_usage"
+	auditlogservice "github.com/gilabs/crm-healthcare/api/internal/service/audit_log"
 	authservice "github.com/gilabs/crm-healthcare/api/internal/service/auth"
 	categoryservice "github.com/gilabs/crm-healthcare/api/internal/service/category"
 	contactservice "github.com/gilabs/crm-healthcare/api/internal/service/contact"
@@ -48,15 +68,29 @@ import (
 	permissionservice "github.com/gilabs/crm-healthcare/api/internal/service/permission"
 	pipelineservice "github.com/gilabs/crm-healthcare/api/internal/service/pipeline"
 	productservice "github.com/gilabs/crm-healthcare/api/internal/service/product"
+	quotationservice "github.com/gilabs/crm-healthcare/api/internal/service/quotation"
+	lossreasonservice "github.com/gilabs/crm-healthcare/api/internal/service/loss_reason"
+	competitorservice "github.com/gilabs/crm-healthcare/api/internal/service/competitor"
 	reportservice "github.com/gilabs/crm-healthcare/api/internal/service/report"
 	roleservice "github.com/gilabs/crm-healthcare/api/internal/service/role"
 	taskservice "github.com/gilabs/crm-healthcare/api/internal/service/task"
+	duplicateservice "github.com/gilabs/crm-healthcare/api/internal/service/duplicate"
+	importjobservice "github.com/gilabs/crm-healthcare/api/internal/service/import_job"
+	leadassignmentservice "github.com/gilabs/crm-healthcare/api/internal/service/lead_assignment"
+	leadscoringservice "github.com/gilabs/crm-healthcare/api/internal/service/lead_scoring"
+	salestargetservice "github.com/gilabs/crm-healthcare/api/internal/service/sales_target"
+	reportsubscriptionservice "github.com/gilabs/crm-healthcare/api/internal/service/report_subscription"
+	emailoutboxservice "github.com/gilabs/crm-healthcare/api/internal/service/email_outbox"
+	messagingservice "github.com/gilabs/crm-healthcare/api/internal/service/messaging"
+	teamservice "github.com/gilabs/crm-healthcare/api/internal/service/team"
 	userservice "github.com/gilabs/crm-healthcare/api/internal/service/user"
 	visitreportservice "github.com/gilabs/crm-healthcare/api/internal/service/visit_report"
 	"github.com/gilabs/crm-healthcare/api/internal/worker"
 	"github.com/gilabs/crm-healthcare/api/pkg/cerebras"
 	"github.com/gilabs/crm-healthcare/api/pkg/jwt"
 	"github.com/gilabs/crm-healthcare/api/pkg/logger"
+	"github.com/gilabs/crm-healthcare/api/pkg/mailer"
+	"github.com/gilabs/crm-healthcare/api/pkg/messaging"
 	"github.com/gilabs/crm-healthcare/api/pkg/response"
 	"github.com/gilabs/crm-healthcare/api/seeders"
 	"github.com/gin-gonic/gin"
@@ -99,6 +133,7 @@ func main() {
 	refreshTokenRepo := refreshtokenrepo.NewRepository(database.DB)
 	userRepo := userrepo.NewRepository(database.DB)
 	roleRepo := rolerepo.NewRepository(database.DB)
+	teamRepo := teamrepo.NewRepository(database.DB)
 	permissionRepo := permissionrepo.NewRepository(database.DB)
 	categoryRepo := categoryrepo.NewRepository(database.DB)
 	contactRoleRepo := contactrolerepo.NewRepository(database.DB)
@@ -106,6 +141,10 @@ func main() {
 	contactRepo := contactrepo.NewRepository(database.DB)
 	pipelineRepo := pipelinerepo.NewRepository(database.DB)
 	dealRepo := dealrepo.NewRepository(database.DB)
+	dealStageHistoryRepo := dealstagehistoryrepo.NewRepository(database.DB)
+	dealItemRepo := dealitemrepo.NewRepository(database.DB)
+	lossReasonRepo := lossreasonrepo.NewRepository(database.DB)
+	competitorRepo := competitorrepo.NewRepository(database.DB)
 	leadRepo := leadrepo.NewRepository(database.DB)
 	visitReportRepo := visitreportrepo.NewRepository(database.DB)
 	activityRepo := activityrepo.NewRepository(database.DB)
@@ -116,23 +155,61 @@ func main() {
 	reminderRepo := reminderrepo.NewRepository(database.DB)
 	notificationRepo := notificationrepo.NewRepository(database.DB)
 	aiSettingsRepo := aisettingsrepo.NewRepository(database.DB)
+	aiConversationRepo := aiconversationrepo.NewRepository(database.DB)
+	aiModelUsageRepo := aimodelusagerepo.NewRepository(database.DB)
+	auditLogRepo := auditlogrepo.NewRepository(database.DB)
+	salesTargetRepo := salestargetrepo.NewRepository(database.DB)
+	reportSubscriptionRepo := reportsubscriptionrepo.NewRepository(database.DB)
+	quotationRepo := quotationrepo.NewRepository(database.DB)
+	emailOutboxRepo := emailoutboxrepo.NewRepository(database.DB)
+	messagingRepo := messagingrepo.NewRepository(database.DB)
+	leadScoringRuleRepo := leadscoringrepo.NewRepository(database.DB)
+	leadAssignmentRuleRepo := leadassignmentrepo.NewRepository(database.DB)
+	duplicateRepo := duplicaterepo.NewRepository(database.DB)
+	importJobRepo := importjobrepo.NewRepository(database.DB)
 
 	// Setup services
 	authService := authservice.NewService(authRepo, refreshTokenRepo, jwtManager)
-	userService := userservice.NewService(userRepo, roleRepo)
+	userService := userservice.NewService(userRepo, roleRepo, teamRepo)
 	profileService := userservice.NewProfileService(userRepo, activityRepo, dealRepo, visitReportRepo, taskRepo)
 	roleService := roleservice.NewService(roleRepo)
-	permissionService := permissionservice.NewService(permissionRepo, userRepo)
+	permissionService := permissionservice.NewService(permissionRepo, userRepo, roleRepo)
+	teamService := teamservice.NewService(teamRepo, userRepo)
+	auditLogService := auditlogservice.NewService(auditLogRepo)
+	roleService.SetPermissionCache(permissionService)
 	categoryService := categoryservice.NewService(categoryRepo)
 	contactRoleService := contactroleservice.NewService(contactRoleRepo)
 	accountService := accountservice.NewService(accountRepo, categoryRepo)
 	contactService := contactservice.NewService(contactRepo, accountRepo, contactRoleRepo)
-	pipelineService := pipelineservice.NewService(pipelineRepo, dealRepo, accountRepo)
-	leadService := leadservice.NewService(leadRepo, dealRepo, pipelineRepo, accountRepo, contactRepo, categoryRepo, contactRoleRepo, userRepo, activityRepo, visitReportRepo)
+	pipelineService := pipelineservice.NewService(pipelineRepo, dealRepo, accountRepo, dealStageHistoryRepo)
+	pipelineService.SetDealItemRepositories(dealItemRepo, productRepo)
+	pipelineService.SetLossRepositories(lossReasonRepo, competitorRepo)
+	pipelineService.SetLossRequirements(config.AppConfig.Deals.RequireLostReason, config.AppConfig.Deals.RequireLostCompetitor)
+	lossReasonService := lossreasonservice.NewService(lossReasonRepo)
+	competitorService := competitorservice.NewService(competitorRepo)
+	leadService := leadservice.NewService(leadRepo, dealRepo, pipelineRepo, accountRepo, contactRepo, categoryRepo, contactRoleRepo, userRepo, activityRepo, visitReportRepo, leadScoringRuleRepo, leadAssignmentRuleRepo)
 	activityService := activityservice.NewService(activityRepo, activityTypeRepo, accountRepo, contactRepo, userRepo)
 	activityTypeService := activitytypeservice.NewService(activityTypeRepo)
 	visitReportService := visitreportservice.NewService(visitReportRepo, accountRepo, contactRepo, userRepo, activityRepo)
-	dashboardService := dashboardservice.NewService(visitReportRepo, accountRepo, activityRepo, userRepo, dealRepo, taskRepo, pipelineRepo, leadRepo)
+	dashboardService := dashboardservice.NewService(visitReportRepo, accountRepo, activityRepo, userRepo, dealRepo, taskRepo, pipelineRepo, leadRepo, salesTargetRepo)
+	salesTargetService := salestargetservice.NewService(salesTargetRepo, userRepo, teamRepo)
+	leadScoringService := leadscoringservice.NewService(leadScoringRuleRepo)
+	leadScoringService.SetLeadScorer(leadService)
+	activityService.SetLeadScorer(leadService)
+	visitReportService.SetLeadScorer(leadService)
+	leadAssignmentService := leadassignmentservice.NewService(leadAssignmentRuleRepo, userRepo, teamRepo)
+	duplicateService := duplicateservice.NewService(duplicateRepo, leadRepo, accountRepo, contactRepo)
+	duplicateService.SetLeadScorer(leadService)
+	leadService.SetDuplicateChecker(duplicateService)
+
+	// Give deals created before stage history was recorded an entry for their current stage
+	if backfilled, err := dealStageHistoryRepo.BackfillMissing(); err != nil {
+		log.Printf("Warning: Failed to backfill deal stage history: %v", err)
+	} else if backfilled > 0 {
+		log.Printf("Backfilled stage history of %d deals", backfilled)
+	}
+	accountService.SetDuplicateChecker(duplicateService)
+	contactService.SetDuplicateChecker(duplicateService)
 
 	// Setup file service with storage provider
 	var storageProvider fileservice.StorageProvider
@@ -161,9 +238,77 @@ func main() {
 	}
 
 	fileService := fileservice.NewService(storageProvider)
-	reportService := reportservice.NewService(visitReportRepo, accountRepo, activityRepo, userRepo, dealRepo)
+	reportService := reportservice.NewService(visitReportRepo, accountRepo, activityRepo, userRepo, dealRepo, salesTargetRepo)
+	reportService.SetBrandName(config.AppConfig.Report.BrandName)
+	reportService.SetPipelineAnalytics(pipelineService)
+	reportService.SetDealItemRepository(dealItemRepo)
+	quotationService := quotationservice.NewService(quotationRepo, dealRepo, dealItemRepo, pipelineRepo, pipelineService, reportService, storageProvider)
+
+	// Setup email delivery; without SMTP_HOST nothing is emailed
+	var emailSender mailer.Sender
+	if smtpConfig := config.AppConfig.SMTP; smtpConfig.Host != "" {
+		emailSender = mailer.NewSMTPSender(mailer.SMTPConfig{
+			Host:     smtpConfig.Host,
+			Port:     smtpConfig.Port,
+			Username: smtpConfig.Username,
+			Password: smtpConfig.Password,
+			From:     smtpConfig.From,
+			FromName: smtpConfig.FromName,
+		})
+	} else {
+		log.Println("SMTP_HOST is not set; email delivery is disabled")
+	}
+
+	reportSubscriptionService := reportsubscriptionservice.NewService(reportSubscriptionRepo, accountRepo, userRepo, reportService)
+	reportSubscriptionService.SetSender(emailSender)
+	emailOutboxService := emailoutboxservice.NewService(emailOutboxRepo, userRepo, reminderRepo)
+	emailOutboxService.SetSender(emailSender)
+	emailOutboxService.SetBrandName(config.AppConfig.Report.BrandName)
+	emailOutboxService.SetMaxAttempts(config.AppConfig.SMTP.MaxAttempts)
+
+	// Setup SMS and WhatsApp delivery; a channel without a gateway URL is disabled
+	messagingConfig := config.AppConfig.Messaging
+	messagingService := messagingservice.NewService(messagingRepo, reminderRepo)
+	messagingService.SetBrandName(config.AppConfig.Report.BrandName)
+	messagingService.SetMaxAttempts(messagingConfig.MaxAttempts)
+	messagingService.SetDefaultCountryCode(messagingConfig.DefaultCountryCode)
+	messagingService.SetWebhookSecret(messagingConfig.WebhookSecret)
+	messagingEnabled := false
+	for channel, gatewayConfig := range map[string]config.MessagingGatewayConfig{
+		messaging.ChannelSMS:      messagingConfig.SMS,
+		messaging.ChannelWhatsApp: messagingConfig.WhatsApp,
+	} {
+		if gatewayConfig.URL == "" {
+			continue
+		}
+		gateway, err := messaging.NewWebhookGateway(messaging.WebhookConfig{
+			URL:                gatewayConfig.URL,
+			AuthHeader:         gatewayConfig.AuthHeader,
+			ContentType:        gatewayConfig.ContentType,
+			BodyTemplate:       gatewayConfig.BodyTemplate,
+			MessageIDField:     gatewayConfig.MessageIDField,
+			ReceiptIDField:     gatewayConfig.ReceiptIDField,
+			ReceiptStatusField: gatewayConfig.ReceiptStatusField,
+			ReceiptErrorField:  gatewayConfig.ReceiptErrorField,
+		})
+		if err != nil {
+			log.Fatalf("Invalid %s gateway configuration: %v", channel, err)
+		}
+		messagingService.SetGateway(channel, gateway)
+		messagingEnabled = true
+	}
+	if !messagingEnabled {
+		log.Println("No SMS or WhatsApp gateway is configured; text message delivery is disabled")
+	}
 	productService := productservice.NewService(productRepo, productCategoryRepo)
-	taskService := taskservice.NewService(taskRepo, reminderRepo, userRepo, accountRepo, contactRepo, dealRepo)
+	taskService := taskservice.NewService(taskRepo, reminderRepo, userRepo, accountRepo, contactRepo, dealRepo, visitReportRepo)
+	importJobService := importjobservice.NewService(importJobRepo, accountRepo, leadRepo, productRepo, categoryRepo, contactRoleRepo, productCategoryRepo, userRepo, accountService, contactService, leadService, productService)
+	importJobService.SetDuplicateChecker(duplicateService)
+	if failed, err := importJobService.FailInterrupted(); err != nil {
+		log.Printf("Warning: Failed to fail interrupted import jobs: %v", err)
+	} else if failed > 0 {
+		log.Printf("Marked %d interrupted import job(s) as failed", failed)
+	}
 
 	// Setup WebSocket hub
 	notificationHub := hub.NewNotificationHub()
@@ -172,6 +317,14 @@ func main() {
 	// Setup notification service with hub
 	notificationService := notificationservice.NewService(notificationRepo)
 	notificationService.SetHub(notificationHub)
+	leadService.SetNotifier(notificationService)
+	taskService.SetNotifier(notificationService)
+	if emailSender != nil {
+		notificationService.SetEmailer(emailOutboxService)
+	}
+	if messagingEnabled {
+		notificationService.SetMessenger(messagingService)
+	}
 
 	// Setup Cerebras AI Client
 	cerebrasClient := cerebras.NewClient(
@@ -198,29 +351,52 @@ func main() {
 		permissionRepo,
 		config.AppConfig.Cerebras.APIKey,
 	)
+	aiService.SetConversationRepository(aiConversationRepo)
+	aiService.SetUsageRepository(aiModelUsageRepo, userRepo)
+
+	// Setup AI Conversation Service
+	aiConversationService := aiconversationservice.NewService(aiConversationRepo)
+
+	// Setup AI Usage Service
+	aiUsageService := aiusageservice.NewService(aiModelUsageRepo)
 
 	// Setup handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	userHandler := handlers.NewUserHandler(userService, profileService)
 	roleHandler := handlers.NewRoleHandler(roleService)
+	teamHandler := handlers.NewTeamHandler(teamService)
+	auditLogHandler := handlers.NewAuditLogHandler(auditLogService)
 	permissionHandler := handlers.NewPermissionHandler(permissionService)
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
 	contactRoleHandler := handlers.NewContactRoleHandler(contactRoleService)
-	accountHandler := handlers.NewAccountHandler(accountService)
-	contactHandler := handlers.NewContactHandler(contactService)
+	accountHandler := handlers.NewAccountHandler(accountService, auditLogService)
+	contactHandler := handlers.NewContactHandler(contactService, auditLogService)
 	pipelineHandler := handlers.NewPipelineHandler(pipelineService)
-	dealHandler := handlers.NewDealHandler(pipelineService, visitReportService, activityService)
-	leadHandler := handlers.NewLeadHandler(leadService, visitReportService, activityService)
+	dealHandler := handlers.NewDealHandler(pipelineService, visitReportService, activityService, auditLogService)
+	quotationHandler := handlers.NewQuotationHandler(quotationService, auditLogService)
+	lossReasonHandler := handlers.NewLossReasonHandler(lossReasonService, auditLogService)
+	competitorHandler := handlers.NewCompetitorHandler(competitorService, auditLogService)
+	leadHandler := handlers.NewLeadHandler(leadService, visitReportService, activityService, auditLogService)
 	activityHandler := handlers.NewActivityHandler(activityService)
 	activityTypeHandler := handlers.NewActivityTypeHandler(activityTypeService)
-	visitReportHandler := handlers.NewVisitReportHandler(visitReportService, fileService)
+	visitReportHandler := handlers.NewVisitReportHandler(visitReportService, fileService, auditLogService)
 	dashboardHandler := handlers.NewDashboardHandler(dashboardService)
 	reportHandler := handlers.NewReportHandler(reportService)
+	salesTargetHandler := handlers.NewSalesTargetHandler(salesTargetService)
+	reportSubscriptionHandler := handlers.NewReportSubscriptionHandler(reportSubscriptionService)
+	emailOutboxHandler := handlers.NewEmailOutboxHandler(emailOutboxService)
+	messagingHandler := handlers.NewMessagingHandler(messagingService)
+	leadScoringHandler := handlers.NewLeadScoringHandler(leadScoringService)
+	leadAssignmentHandler := handlers.NewLeadAssignmentHandler(leadAssignmentService)
+	duplicateHandler := handlers.NewDuplicateHandler(duplicateService, auditLogService)
+	importHandler := handlers.NewImportHandler(importJobService, auditLogService)
 	productHandler := handlers.NewProductHandler(productService)
-	taskHandler := handlers.NewTaskHandler(taskService)
+	taskHandler := handlers.NewTaskHandler(taskService, auditLogService)
 	notificationHandler := handlers.NewNotificationHandler(notificationService)
 	aiHandler := handlers.NewAIHandler(aiService)
 	aiSettingsHandler := handlers.NewAISettingsHandler(aiSettingsService)
+	aiConversationHandler := handlers.NewAIConversationHandler(aiConversationService)
+	aiUsageHandler := handlers.NewAIUsageHandler(aiUsageService)
 
 	// Setup WebSocket handler
 	wsHandler := handlers.NewWebSocketHandler(notificationHub, jwtManager)
@@ -230,6 +406,8 @@ func main() {
 		reminderRepo,
 		notificationService,
 		notificationHub,
+		emailOutboxService,
+		messagingService,
 		1*time.Minute, // Run every 1 minute
 	)
 	reminderWorker.Start()
@@ -242,12 +420,36 @@ func main() {
 	)
 	refreshTokenCleanupWorker.Start()
 
+	// Setup report subscription worker
+	reportSubscriptionWorker := worker.NewReportSubscriptionWorker(
+		reportSubscriptionService,
+		1*time.Minute, // Run every 1 minute
+	)
+	reportSubscriptionWorker.Start()
+
+	// Setup email outbox worker
+	emailOutboxWorker := worker.NewEmailOutboxWorker(
+		emailOutboxService,
+		30*time.Second, // Run every 30 seconds
+	)
+	emailOutboxWorker.Start()
+
+	// Setup messaging worker
+	messagingWorker := worker.NewMessagingWorker(
+		messagingService,
+		30*time.Second, // Run every 30 seconds
+	)
+	messagingWorker.Start()
+
 	// Setup router
 	router := setupRouter(
 		jwtManager,
+		permissionService,
 		authHandler,
 		userHandler,
 		roleHandler,
+		teamHandler,
+		auditLogHandler,
 		permissionHandler,
 		categoryHandler,
 		contactRoleHandler,
@@ -255,18 +457,31 @@ func main() {
 		contactHandler,
 		pipelineHandler,
 		dealHandler,
+		quotationHandler,
+		lossReasonHandler,
+		competitorHandler,
 		leadHandler,
 		activityHandler,
 		activityTypeHandler,
 		visitReportHandler,
 		dashboardHandler,
 		reportHandler,
+		salesTargetHandler,
+		reportSubscriptionHandler,
+		emailOutboxHandler,
+		messagingHandler,
+		leadScoringHandler,
+		leadAssignmentHandler,
+		duplicateHandler,
+		importHandler,
 		productHandler,
 		taskHandler,
 		notificationHandler,
 		wsHandler,
 		aiHandler,
 		aiSettingsHandler,
+		aiConversationHandler,
+		aiUsageHandler,
 	)
 
 	// Run server
@@ -279,9 +494,12 @@ func main() {
 
 func setupRouter(
 	jwtManager *jwt.JWTManager,
+	permissionChecker middleware.PermissionChecker,
 	authHandler *handlers.AuthHandler,
 	userHandler *handlers.UserHandler,
 	roleHandler *handlers.RoleHandler,
+	teamHandler *handlers.TeamHandler,
+	auditLogHandler *handlers.AuditLogHandler,
 	permissionHandler *handlers.PermissionHandler,
 	categoryHandler *handlers.CategoryHandler,
 	contactRoleHandler *handlers.ContactRoleHandler,
@@ -289,18 +507,31 @@ func setupRouter(
 	contactHandler *handlers.ContactHandler,
 	pipelineHandler *handlers.PipelineHandler,
 	dealHandler *handlers.DealHandler,
+	quotationHandler *handlers.QuotationHandler,
+	lossReasonHandler *handlers.LossReasonHandler,
+	competitorHandler *handlers.CompetitorHandler,
 	leadHandler *handlers.LeadHandler,
 	activityHandler *handlers.ActivityHandler,
 	activityTypeHandler *handlers.ActivityTypeHandler,
 	visitReportHandler *handlers.VisitReportHandler,
 	dashboardHandler *handlers.DashboardHandler,
 	reportHandler *handlers.ReportHandler,
+	salesTargetHandler *handlers.SalesTargetHandler,
+	reportSubscriptionHandler *handlers.ReportSubscriptionHandler,
+	emailOutboxHandler *handlers.EmailOutboxHandler,
+	messagingHandler *handlers.MessagingHandler,
+	leadScoringHandler *handlers.LeadScoringHandler,
+	leadAssignmentHandler *handlers.LeadAssignmentHandler,
+	duplicateHandler *handlers.DuplicateHandler,
+	importHandler *handlers.ImportHandler,
 	productHandler *handlers.ProductHandler,
 	taskHandler *handlers.TaskHandler,
 	notificationHandler *handlers.NotificationHandler,
 	wsHandler *handlers.WebSocketHandler,
 	aiHandler *handlers.AIHandler,
 	aiSettingsHandler *handlers.AISettingsHandler,
+	aiConversationHandler *handlers.AIConversationHandler,
+	aiUsageHandler *handlers.AIUsageHandler,
 ) *gin.Engine {
 	// Set Gin mode
 	if config.AppConfig.Server.Env == "production" {
@@ -351,58 +582,89 @@ func setupRouter(
 		routes.SetupAuthRoutes(v1, authHandler, permissionHandler, userHandler, jwtManager)
 
 		// User routes
-		routes.SetupUserRoutes(v1, userHandler, permissionHandler, jwtManager)
+		routes.SetupUserRoutes(v1, userHandler, permissionHandler, jwtManager, permissionChecker)
 
 		// Role routes
-		routes.SetupRoleRoutes(v1, roleHandler, jwtManager)
+		routes.SetupRoleRoutes(v1, roleHandler, jwtManager, permissionChecker)
+
+		// Team routes
+		routes.SetupTeamRoutes(v1, teamHandler, jwtManager, permissionChecker)
+
+		// Audit log routes
+		routes.SetupAuditLogRoutes(v1, auditLogHandler, jwtManager, permissionChecker)
 
 		// Permission routes
-		routes.SetupPermissionRoutes(v1, permissionHandler, jwtManager)
+		routes.SetupPermissionRoutes(v1, permissionHandler, jwtManager, permissionChecker)
 
 		// Category routes
-		routes.SetupCategoryRoutes(v1, categoryHandler, jwtManager)
+		routes.SetupCategoryRoutes(v1, categoryHandler, jwtManager, permissionChecker)
 
 		// Contact Role routes
-		routes.SetupContactRoleRoutes(v1, contactRoleHandler, jwtManager)
+		routes.SetupContactRoleRoutes(v1, contactRoleHandler, jwtManager, permissionChecker)
 
 		// Account routes
-		routes.SetupAccountRoutes(v1, accountHandler, jwtManager)
+		routes.SetupAccountRoutes(v1, accountHandler, jwtManager, permissionChecker)
 
 		// Contact routes
-		routes.SetupContactRoutes(v1, contactHandler, jwtManager)
+		routes.SetupContactRoutes(v1, contactHandler, jwtManager, permissionChecker)
 
 		// Visit Report routes
-		routes.SetupVisitReportRoutes(v1, visitReportHandler, activityTypeHandler, jwtManager)
+		routes.SetupVisitReportRoutes(v1, visitReportHandler, activityTypeHandler, jwtManager, permissionChecker)
 
 		// Activity routes
-		routes.SetupActivityRoutes(v1, activityHandler, jwtManager)
+		routes.SetupActivityRoutes(v1, activityHandler, jwtManager, permissionChecker)
 
 		// Pipeline & Deals routes
-		routes.SetupPipelineRoutes(v1, pipelineHandler, dealHandler, jwtManager)
+		routes.SetupPipelineRoutes(v1, pipelineHandler, dealHandler, jwtManager, permissionChecker)
+
+		// Quotation routes
+		routes.SetupQuotationRoutes(v1, quotationHandler, jwtManager, permissionChecker)
+
+		// Loss reason & competitor routes
+		routes.SetupLossReasonRoutes(v1, lossReasonHandler, jwtManager, permissionChecker)
+		routes.SetupCompetitorRoutes(v1, competitorHandler, jwtManager, permissionChecker)
 
 		// Lead routes
-		routes.SetupLeadRoutes(v1, leadHandler, jwtManager)
+		routes.SetupLeadRoutes(v1, leadHandler, jwtManager, permissionChecker)
+		routes.SetupLeadScoringRoutes(v1, leadScoringHandler, jwtManager, permissionChecker)
+		routes.SetupLeadAssignmentRoutes(v1, leadAssignmentHandler, jwtManager, permissionChecker)
+		routes.SetupDuplicateRoutes(v1, duplicateHandler, jwtManager, permissionChecker)
+
+		// Bulk import routes
+		routes.SetupImportRoutes(v1, importHandler, jwtManager, permissionChecker)
 
 		// Dashboard routes
-		routes.SetupDashboardRoutes(v1, dashboardHandler, jwtManager)
+		routes.SetupDashboardRoutes(v1, dashboardHandler, jwtManager, permissionChecker)
 
 		// Report routes
-		routes.SetupReportRoutes(v1, reportHandler, jwtManager)
+		routes.SetupReportRoutes(v1, reportHandler, jwtManager, permissionChecker)
+
+		// Sales target routes
+		routes.SetupSalesTargetRoutes(v1, salesTargetHandler, jwtManager, permissionChecker)
+
+		// Report subscription routes
+		routes.SetupReportSubscriptionRoutes(v1, reportSubscriptionHandler, jwtManager, permissionChecker)
+
+		// Email outbox routes
+		routes.SetupEmailOutboxRoutes(v1, emailOutboxHandler, jwtManager, permissionChecker)
+
+		// SMS & WhatsApp messaging routes
+		routes.SetupMessagingRoutes(v1, messagingHandler, jwtManager, permissionChecker)
 
 		// Master Data routes
 		routes.SetupMasterDataRoutes(v1, jwtManager)
 
 		// Product routes
-		routes.SetupProductRoutes(v1, productHandler, jwtManager)
+		routes.SetupProductRoutes(v1, productHandler, jwtManager, permissionChecker)
 
 		// Task & Reminder routes
-		routes.SetupTaskRoutes(v1, taskHandler, jwtManager)
+		routes.SetupTaskRoutes(v1, taskHandler, jwtManager, permissionChecker)
 
 		// Notification routes
 		routes.SetupNotificationRoutes(v1, notificationHandler, wsHandler, jwtManager)
 
 		// AI routes
-		routes.SetupAIRoutes(v1, aiHandler, aiSettingsHandler, jwtManager)
+		routes.SetupAIRoutes(v1, aiHandler, aiSettingsHandler, aiConversationHandler, aiUsageHandler, jwtManager, permissionChecker)
 	}
 
 	return router
@@ -1,21 +1,27 @@
 package handlers
 
 import (
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/audit_log"
 	accountservice "github.com/gilabs/crm-healthcare/api/internal/service/account"
+	auditlogservice "github.com/gilabs/crm-healthcare/api/internal/service/audit_log"
 	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"github.com/gilabs/crm-healthcare/api/pkg/response"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
 type AccountHandler struct {
-	accountService *accountservice.Service
+	accountService  *accountservice.Service
+	auditLogService *auditlogservice.Service
 }
 
-func NewAccountHandler(accountService *accountservice.Service) *AccountHandler {
+func NewAccountHandler(accountService *accountservice.Service, auditLogService *auditlogservice.Service) *AccountHandler {
 	return &AccountHandler{
-		accountService: accountService,
+		accountService:  accountService,
+		auditLogService: auditLogService,
 	}
 }
 
@@ -32,7 +38,7 @@ func (h *AccountHandler) List(c *gin.Context) {
 		return
 	}
 
-	accounts, pagination, err := h.accountService.List(&req)
+	accounts, pagination, err := h.accountService.WithScope(datascope.FromContext(c)).List(&req)
 	if err != nil {
 		errors.InternalServerErrorResponse(c, "")
 		return
@@ -66,11 +72,30 @@ func (h *AccountHandler) List(c *gin.Context) {
 	response.SuccessResponse(c, accounts, meta)
 }
 
+// Export handles streaming every account that matches the list filters as CSV, XLSX or NDJSON
+func (h *AccountHandler) Export(c *gin.Context) {
+	var req account.ListAccountsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	scoped := h.accountService.WithScope(datascope.FromContext(c))
+	streamExport(c, "accounts", account.ExportColumns, func(w export.Writer) error {
+		return scoped.Export(&req, w)
+	})
+}
+
 // GetByID handles get account by ID request
 func (h *AccountHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
 
-	account, err := h.accountService.GetByID(id)
+	account, err := h.accountService.WithScope(datascope.FromContext(c)).GetByID(id)
 	if err != nil {
 		if err == accountservice.ErrAccountNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -101,10 +126,15 @@ func (h *AccountHandler) Create(c *gin.Context) {
 
 	createdAccount, err := h.accountService.Create(&req)
 	if err != nil {
+		if duplicateFoundResponse(c, err) {
+			return
+		}
 		errors.InternalServerErrorResponse(c, "")
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionCreate, "account", createdAccount.ID, nil, createdAccount)
+
 	meta := &response.Meta{}
 	if userID, exists := c.Get("user_id"); exists {
 		if id, ok := userID.(string); ok {
@@ -129,7 +159,9 @@ func (h *AccountHandler) Update(c *gin.Context) {
 		return
 	}
 
-	updatedAccount, err := h.accountService.Update(id, &req)
+	scopedService := h.accountService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetByID(id)
+	updatedAccount, err := scopedService.Update(id, &req)
 	if err != nil {
 		if err == accountservice.ErrAccountNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -142,6 +174,8 @@ func (h *AccountHandler) Update(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionUpdate, "account", id, before, updatedAccount)
+
 	meta := &response.Meta{}
 	if userID, exists := c.Get("user_id"); exists {
 		if id, ok := userID.(string); ok {
@@ -156,7 +190,9 @@ func (h *AccountHandler) Update(c *gin.Context) {
 func (h *AccountHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	err := h.accountService.Delete(id)
+	scopedService := h.accountService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetByID(id)
+	err := scopedService.Delete(id)
 	if err != nil {
 		if err == accountservice.ErrAccountNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -169,6 +205,8 @@ func (h *AccountHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionDelete, "account", id, before, nil)
+
 	// Get user ID for meta
 	meta := &response.Meta{}
 	if userIDVal, exists := c.Get("user_id"); exists {
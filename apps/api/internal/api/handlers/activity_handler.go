@@ -4,6 +4,7 @@ import (
 	"github.com/gilabs/crm-healthcare/api/internal/domain/activity"
 	activityservice "github.com/gilabs/crm-healthcare/api/internal/service/activity"
 	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"github.com/gilabs/crm-healthcare/api/pkg/response"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -66,6 +67,24 @@ func (h *ActivityHandler) List(c *gin.Context) {
 	response.SuccessResponse(c, activities, meta)
 }
 
+// Export handles streaming every activity that matches the list filters as CSV, XLSX or NDJSON
+func (h *ActivityHandler) Export(c *gin.Context) {
+	var req activity.ListActivitiesRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	streamExport(c, "activities", activity.ExportColumns, func(w export.Writer) error {
+		return h.activityService.Export(&req, w)
+	})
+}
+
 // GetByID handles get activity by ID request
 func (h *ActivityHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
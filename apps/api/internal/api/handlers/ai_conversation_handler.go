@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_conversation"
+	aiconversationservice "github.com/gilabs/crm-healthcare/api/internal/service/ai_conversation"
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type AIConversationHandler struct {
+	conversationService *aiconversationservice.Service
+}
+
+func NewAIConversationHandler(conversationService *aiconversationservice.Service) *AIConversationHandler {
+	return &AIConversationHandler{
+		conversationService: conversationService,
+	}
+}
+
+// List handles list of the current user's AI conversations request
+func (h *AIConversationHandler) List(c *gin.Context) {
+	var req ai_conversation.ListConversationsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	conversations, pagination, err := h.conversationService.List(c.GetString("user_id"), &req)
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	meta := &response.Meta{
+		Pagination: &response.PaginationMeta{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+			HasNext:    pagination.Page < pagination.TotalPages,
+			HasPrev:    pagination.Page > 1,
+		},
+		Filters: map[string]interface{}{},
+	}
+	if req.Search != "" {
+		meta.Filters["search"] = req.Search
+	}
+
+	response.SuccessResponse(c, conversations, meta)
+}
+
+// GetByID handles get conversation with messages request, used to resume a conversation
+func (h *AIConversationHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	conversation, err := h.conversationService.GetByID(id, c.GetString("user_id"))
+	if err != nil {
+		if err == aiconversationservice.ErrConversationNotFound {
+			errors.NotFoundResponse(c, "ai_conversation", id)
+			return
+		}
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	response.SuccessResponse(c, conversation, nil)
+}
+
+// Update handles rename conversation request
+func (h *AIConversationHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	var req ai_conversation.UpdateConversationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	conversation, err := h.conversationService.Rename(id, c.GetString("user_id"), &req)
+	if err != nil {
+		if err == aiconversationservice.ErrConversationNotFound {
+			errors.NotFoundResponse(c, "ai_conversation", id)
+			return
+		}
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	response.SuccessResponse(c, conversation, nil)
+}
+
+// Delete handles delete conversation request
+func (h *AIConversationHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.conversationService.Delete(id, c.GetString("user_id")); err != nil {
+		if err == aiconversationservice.ErrConversationNotFound {
+			errors.NotFoundResponse(c, "ai_conversation", id)
+			return
+		}
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	response.SuccessResponseDeleted(c, "ai_conversation", id, nil)
+}
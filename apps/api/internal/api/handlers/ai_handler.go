@@ -1,14 +1,29 @@
 package handlers
 
 import (
+	"log"
+	"net/http"
 	"strings"
+	"time"
 
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/ai"
+	"github.com/gilabs/crm-healthcare/api/internal/hub"
 	aiservice "github.com/gilabs/crm-healthcare/api/internal/service/ai"
 	"github.com/gilabs/crm-healthcare/api/pkg/errors"
 	"github.com/gilabs/crm-healthcare/api/pkg/response"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// Time allowed to write a chat event to the WebSocket peer
+	chatWebSocketWriteWait = 10 * time.Second
+
+	// Maximum chat request size
+	chatWebSocketMaxMessageSize = 64 * 1024
 )
 
 type AIHandler struct {
@@ -34,13 +49,15 @@ func (h *AIHandler) AnalyzeVisitReport(c *gin.Context) {
 		return
 	}
 
-	insight, tokens, err := h.aiService.AnalyzeVisitReport(req.VisitReportID)
+	insight, tokens, err := h.aiService.AnalyzeVisitReport(req.VisitReportID, c.GetString("user_id"))
 	if err != nil {
 		// Check for specific errors
-		if err.Error() == "AI service not configured: Cerebras API key is empty" {
-			errors.ErrorResponse(c, "AI_SERVICE_NOT_CONFIGURED", map[string]interface{}{
-				"error": "Cerebras API key is not configured. Please set CEREBRAS_API_KEY environment variable",
-			}, nil)
+		if err == aiservice.ErrTokenQuotaExceeded {
+			errors.ErrorResponse(c, "AI_TOKEN_QUOTA_EXCEEDED", nil, nil)
+			return
+		}
+		if strings.Contains(err.Error(), "AI service not configured") {
+			errors.ErrorResponse(c, "AI_SERVICE_NOT_CONFIGURED", aiNotConfiguredDetails(err), nil)
 			return
 		}
 		errors.ErrorResponse(c, "AI_ANALYSIS_FAILED", map[string]interface{}{
@@ -69,12 +86,6 @@ func (h *AIHandler) Chat(c *gin.Context) {
 		return
 	}
 
-	// Use conversation history if provided, otherwise use empty slice
-	history := req.ConversationHistory
-	if history == nil {
-		history = []ai.ChatMessage{}
-	}
-
 	// Get user ID from context (set by AuthMiddleware)
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -88,40 +99,250 @@ func (h *AIHandler) Chat(c *gin.Context) {
 		return
 	}
 
-	chatResponse, err := h.aiService.Chat(req.Message, req.Context, req.ContextType, history, req.Model, userIDStr)
+	chatResponse, err := h.aiService.WithScope(datascope.FromContext(c)).Chat(&req, userIDStr)
 	if err != nil {
-		// Check for specific errors
-		errMsg := err.Error()
-		
-		if strings.Contains(errMsg, "AI service not configured") || strings.Contains(errMsg, "API key is empty") {
-			errors.ErrorResponse(c, "AI_SERVICE_NOT_CONFIGURED", map[string]interface{}{
-				"error": "Cerebras API key is not configured. Please set CEREBRAS_API_KEY environment variable",
-			}, nil)
+		code, details := chatErrorCode(err)
+		errors.ErrorResponse(c, code, details, nil)
+		return
+	}
+
+	response.SuccessResponse(c, chatResponse, nil)
+}
+
+// ChatStream handles chat request and streams the answer as Server-Sent Events.
+// Events: "delta" for each chunk, then "done" with the full message and tokens, or "error".
+func (h *AIHandler) ChatStream(c *gin.Context) {
+	var req ai.ChatRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
 			return
 		}
-		
-		// Check for model not found errors
-		if strings.Contains(errMsg, "tidak ditemukan") || strings.Contains(errMsg, "does not exist") || strings.Contains(errMsg, "model_not_found") {
-			errors.ErrorResponse(c, "AI_MODEL_NOT_FOUND", map[string]interface{}{
-				"error": errMsg,
-			}, nil)
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	// Headers are only sent with the first chunk so that errors before streaming starts
+	// are still returned as regular JSON error responses
+	started := false
+	startStream := func() {
+		if started {
 			return
 		}
-		
-		// Check for unsupported model errors
-		if strings.Contains(errMsg, "tidak didukung") || strings.Contains(errMsg, "not supported") {
-			errors.ErrorResponse(c, "AI_MODEL_NOT_SUPPORTED", map[string]interface{}{
-				"error": errMsg,
-			}, nil)
+		started = true
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no") // Disable nginx response buffering
+		c.Status(http.StatusOK)
+	}
+
+	ctx := c.Request.Context()
+	chatResponse, err := h.aiService.WithScope(datascope.FromContext(c)).ChatStream(ctx, &req, c.GetString("user_id"), func(content string) error {
+		startStream()
+		c.SSEvent(string(ai.ChatStreamEventDelta), &ai.ChatStreamDelta{Content: content})
+		c.Writer.Flush()
+		return ctx.Err() // Stop generating when the client disconnects
+	})
+	if err != nil {
+		code, details := chatErrorCode(err)
+		if !started {
+			errors.ErrorResponse(c, code, details, nil)
 			return
 		}
-		
-		errors.ErrorResponse(c, "AI_CHAT_FAILED", map[string]interface{}{
+		c.SSEvent(string(ai.ChatStreamEventError), &ai.ChatStreamError{Code: code, Message: err.Error()})
+		c.Writer.Flush()
+		return
+	}
+
+	startStream()
+	c.SSEvent(string(ai.ChatStreamEventDone), chatResponse)
+	c.Writer.Flush()
+}
+
+// ChatWebSocket handles a chat session over WebSocket.
+// Each text message from the client is a chat request; the answer is sent back as delta events
+// followed by a done or error event before the next request is read.
+func (h *AIHandler) ChatWebSocket(c *gin.Context) {
+	upgrader := hub.GetUpgrader()
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("AI chat WebSocket upgrade error: %v (Origin: %s)", err, c.GetHeader("Origin"))
+		return
+	}
+	defer conn.Close()
+
+	conn.SetReadLimit(chatWebSocketMaxMessageSize)
+	userID := c.GetString("user_id")
+	aiService := h.aiService.WithScope(datascope.FromContext(c))
+
+	writeEvent := func(eventType ai.ChatStreamEventType, data interface{}) error {
+		conn.SetWriteDeadline(time.Now().Add(chatWebSocketWriteWait))
+		return conn.WriteJSON(&ai.ChatStreamEvent{Type: eventType, Data: data})
+	}
+
+	for {
+		var req ai.ChatRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("AI chat WebSocket error: %v", err)
+			}
+			return
+		}
+
+		if err := binding.Validator.ValidateStruct(&req); err != nil {
+			if writeErr := writeEvent(ai.ChatStreamEventError, &ai.ChatStreamError{Code: "VALIDATION_ERROR", Message: err.Error()}); writeErr != nil {
+				return
+			}
+			continue
+		}
+
+		chatResponse, err := aiService.ChatStream(c.Request.Context(), &req, userID, func(content string) error {
+			return writeEvent(ai.ChatStreamEventDelta, &ai.ChatStreamDelta{Content: content})
+		})
+		if err != nil {
+			code, _ := chatErrorCode(err)
+			if writeErr := writeEvent(ai.ChatStreamEventError, &ai.ChatStreamError{Code: code, Message: err.Error()}); writeErr != nil {
+				return
+			}
+			continue
+		}
+
+		if err := writeEvent(ai.ChatStreamEventDone, chatResponse); err != nil {
+			return
+		}
+	}
+}
+
+// chatErrorCode maps a chat service error to an API error code and details
+func chatErrorCode(err error) (string, map[string]interface{}) {
+	if err == aiservice.ErrConversationNotFound {
+		return "NOT_FOUND", map[string]interface{}{
+			"resource": "ai_conversation",
+		}
+	}
+	if err == aiservice.ErrTokenQuotaExceeded {
+		return "AI_TOKEN_QUOTA_EXCEEDED", nil
+	}
+
+	errMsg := err.Error()
+
+	if strings.Contains(errMsg, "AI service not configured") || strings.Contains(errMsg, "API key is empty") {
+		return "AI_SERVICE_NOT_CONFIGURED", aiNotConfiguredDetails(err)
+	}
+
+	// Check for model not found errors
+	if strings.Contains(errMsg, "tidak ditemukan") || strings.Contains(errMsg, "does not exist") || strings.Contains(errMsg, "model_not_found") {
+		return "AI_MODEL_NOT_FOUND", map[string]interface{}{
 			"error": errMsg,
-		}, nil)
+		}
+	}
+
+	// Check for unsupported model errors
+	if strings.Contains(errMsg, "tidak didukung") || strings.Contains(errMsg, "not supported") {
+		return "AI_MODEL_NOT_SUPPORTED", map[string]interface{}{
+			"error": errMsg,
+		}
+	}
+
+	return "AI_CHAT_FAILED", map[string]interface{}{
+		"error": errMsg,
+	}
+}
+
+
+// AnalyzeDeal handles deal analysis request
+func (h *AIHandler) AnalyzeDeal(c *gin.Context) {
+	id := c.Param("id")
+
+	insight, tokens, err := h.aiService.WithScope(datascope.FromContext(c)).AnalyzeDeal(id, c.GetString("user_id"))
+	if err != nil {
+		h.handleInsightError(c, err, "deal", id)
 		return
 	}
 
-	response.SuccessResponse(c, chatResponse, nil)
+	response.SuccessResponse(c, &ai.InsightResponse{
+		Type:   ai.InsightTypeDeal,
+		Data:   insight,
+		Tokens: tokens,
+	}, nil)
+}
+
+// AnalyzeAccount handles account health analysis request
+func (h *AIHandler) AnalyzeAccount(c *gin.Context) {
+	id := c.Param("id")
+
+	insight, tokens, err := h.aiService.WithScope(datascope.FromContext(c)).AnalyzeAccount(id, c.GetString("user_id"))
+	if err != nil {
+		h.handleInsightError(c, err, "account", id)
+		return
+	}
+
+	response.SuccessResponse(c, &ai.InsightResponse{
+		Type:   ai.InsightTypeAccount,
+		Data:   insight,
+		Tokens: tokens,
+	}, nil)
+}
+
+// AnalyzePipeline handles pipeline forecast analysis request
+func (h *AIHandler) AnalyzePipeline(c *gin.Context) {
+	var req ai.AnalyzePipelineRequest
+
+	// Body is optional; filters default to the whole open pipeline
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			if validationErrors, ok := err.(validator.ValidationErrors); ok {
+				errors.HandleValidationError(c, validationErrors)
+				return
+			}
+			errors.InvalidRequestBodyResponse(c)
+			return
+		}
+	}
+
+	insight, tokens, err := h.aiService.WithScope(datascope.FromContext(c)).AnalyzePipeline(&req, c.GetString("user_id"))
+	if err != nil {
+		h.handleInsightError(c, err, "pipeline", "")
+		return
+	}
+
+	response.SuccessResponse(c, &ai.InsightResponse{
+		Type:   ai.InsightTypePipeline,
+		Data:   insight,
+		Tokens: tokens,
+	}, nil)
+}
+
+// handleInsightError maps AI insight errors to API error responses
+func (h *AIHandler) handleInsightError(c *gin.Context, err error, resource string, resourceID string) {
+	switch {
+	case err == aiservice.ErrInsightTargetNotFound:
+		errors.NotFoundResponse(c, resource, resourceID)
+	case err == aiservice.ErrTokenQuotaExceeded:
+		errors.ErrorResponse(c, "AI_TOKEN_QUOTA_EXCEEDED", nil, nil)
+	case err == aiservice.ErrDataAccessDenied:
+		errors.ErrorResponse(c, "AI_DATA_ACCESS_DENIED", map[string]interface{}{
+			"resource": resource,
+		}, nil)
+	case strings.Contains(err.Error(), "AI service not configured"):
+		errors.ErrorResponse(c, "AI_SERVICE_NOT_CONFIGURED", aiNotConfiguredDetails(err), nil)
+	default:
+		errors.ErrorResponse(c, "AI_ANALYSIS_FAILED", map[string]interface{}{
+			"error": err.Error(),
+		}, nil)
+	}
 }
 
+// aiNotConfiguredDetails explains why the AI provider could not be used
+func aiNotConfiguredDetails(err error) map[string]interface{} {
+	if strings.Contains(err.Error(), aiservice.ErrAPIKeyEmpty.Error()) {
+		return map[string]interface{}{
+			"error": "Cerebras API key is not configured. Please set CEREBRAS_API_KEY environment variable or an API key in AI settings",
+		}
+	}
+	return map[string]interface{}{
+		"error": err.Error(),
+	}
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	goerrors "errors"
+
 	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_settings"
 	aisettingsservice "github.com/gilabs/crm-healthcare/api/internal/service/ai_settings"
 	"github.com/gilabs/crm-healthcare/api/pkg/errors"
@@ -61,6 +63,12 @@ func (h *AISettingsHandler) UpdateSettings(c *gin.Context) {
 
 	settings, err := h.settingsService.UpdateSettings(&req)
 	if err != nil {
+		if goerrors.Is(err, aisettingsservice.ErrInvalidProviderConfig) {
+			errors.ErrorResponse(c, "AI_PROVIDER_CONFIG_INVALID", map[string]interface{}{
+				"error": err.Error(),
+			}, nil)
+			return
+		}
 		errors.InternalServerErrorResponse(c, "")
 		return
 	}
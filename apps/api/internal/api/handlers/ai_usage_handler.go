@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_model_usage"
+	aiusageservice "github.com/gilabs/crm-healthcare/api/internal/service/ai_usage"
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type AIUsageHandler struct {
+	usageService *aiusageservice.Service
+}
+
+func NewAIUsageHandler(usageService *aiusageservice.Service) *AIUsageHandler {
+	return &AIUsageHandler{
+		usageService: usageService,
+	}
+}
+
+// GetReport handles AI token usage report request
+func (h *AIUsageHandler) GetReport(c *gin.Context) {
+	var req ai_model_usage.UsageReportRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	report, err := h.usageService.GetReport(&req)
+	if err != nil {
+		if err == aiusageservice.ErrInvalidDateRange {
+			errors.ErrorResponse(c, "INVALID_QUERY_PARAM", map[string]interface{}{
+				"error": err.Error(),
+			}, nil)
+			return
+		}
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	response.SuccessResponse(c, report, nil)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/audit_log"
+	auditlogservice "github.com/gilabs/crm-healthcare/api/internal/service/audit_log"
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type AuditLogHandler struct {
+	auditLogService *auditlogservice.Service
+}
+
+func NewAuditLogHandler(auditLogService *auditlogservice.Service) *AuditLogHandler {
+	return &AuditLogHandler{
+		auditLogService: auditLogService,
+	}
+}
+
+// List handles list audit logs request
+func (h *AuditLogHandler) List(c *gin.Context) {
+	var req audit_log.ListAuditLogsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	logs, pagination, err := h.auditLogService.List(&req)
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	meta := auditLogListMeta(pagination)
+	if req.ActorID != "" {
+		meta.Filters["actor_id"] = req.ActorID
+	}
+	if req.Action != "" {
+		meta.Filters["action"] = req.Action
+	}
+	if req.ResourceType != "" {
+		meta.Filters["resource_type"] = req.ResourceType
+	}
+	if req.ResourceID != "" {
+		meta.Filters["resource_id"] = req.ResourceID
+	}
+	if req.RequestID != "" {
+		meta.Filters["request_id"] = req.RequestID
+	}
+	if req.StartDate != "" {
+		meta.Filters["start_date"] = req.StartDate
+	}
+	if req.EndDate != "" {
+		meta.Filters["end_date"] = req.EndDate
+	}
+
+	response.SuccessResponse(c, logs, meta)
+}
+
+// GetResourceHistory handles get change history of a single record request
+func (h *AuditLogHandler) GetResourceHistory(c *gin.Context) {
+	resourceType := c.Param("resource_type")
+	resourceID := c.Param("resource_id")
+
+	var req audit_log.ListAuditLogsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	logs, pagination, err := h.auditLogService.ListByResource(resourceType, resourceID, &req)
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	meta := auditLogListMeta(pagination)
+	meta.Filters["resource_type"] = resourceType
+	meta.Filters["resource_id"] = resourceID
+
+	response.SuccessResponse(c, logs, meta)
+}
+
+func auditLogListMeta(pagination *auditlogservice.PaginationResult) *response.Meta {
+	return &response.Meta{
+		Pagination: &response.PaginationMeta{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+			HasNext:    pagination.Page < pagination.TotalPages,
+			HasPrev:    pagination.Page > 1,
+		},
+		Filters: map[string]interface{}{},
+	}
+}
+
+// auditActorFromContext builds the audit actor from values set by AuthMiddleware and RequestIDMiddleware
+func auditActorFromContext(c *gin.Context) *auditlogservice.Actor {
+	return &auditlogservice.Actor{
+		UserID:    c.GetString("user_id"),
+		Email:     c.GetString("user_email"),
+		Role:      c.GetString("user_role"),
+		RequestID: c.GetString("request_id"),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+// recordAudit stores an audit entry for the current request.
+// Failures are logged and never fail the request that already succeeded.
+func recordAudit(c *gin.Context, auditLogService *auditlogservice.Service, action, resourceType, resourceID string, before, after interface{}) {
+	if auditLogService == nil {
+		return
+	}
+	if err := auditLogService.Record(auditActorFromContext(c), action, resourceType, resourceID, before, after); err != nil {
+		log.Printf("Warning: Failed to record audit log for %s %s: %v", resourceType, resourceID, err)
+	}
+}
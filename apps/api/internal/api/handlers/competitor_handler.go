@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	goerrors "errors"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/audit_log"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/competitor"
+	auditlogservice "github.com/gilabs/crm-healthcare/api/internal/service/audit_log"
+	competitorservice "github.com/gilabs/crm-healthcare/api/internal/service/competitor"
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type CompetitorHandler struct {
+	competitorService *competitorservice.Service
+	auditLogService   *auditlogservice.Service
+}
+
+func NewCompetitorHandler(competitorService *competitorservice.Service, auditLogService *auditlogservice.Service) *CompetitorHandler {
+	return &CompetitorHandler{
+		competitorService: competitorService,
+		auditLogService:   auditLogService,
+	}
+}
+
+// List handles list competitors request
+func (h *CompetitorHandler) List(c *gin.Context) {
+	var req competitor.ListCompetitorsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	items, err := h.competitorService.List(&req)
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	response.SuccessResponse(c, items, nil)
+}
+
+// GetByID handles get competitor by ID request
+func (h *CompetitorHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	item, err := h.competitorService.GetByID(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	response.SuccessResponse(c, item, nil)
+}
+
+// Create handles create competitor request
+func (h *CompetitorHandler) Create(c *gin.Context) {
+	var req competitor.CreateCompetitorRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	item, err := h.competitorService.Create(&req)
+	if err != nil {
+		h.handleError(c, err, "")
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionCreate, "competitor", item.ID, nil, item)
+
+	meta := &response.Meta{CreatedBy: c.GetString("user_id")}
+	response.SuccessResponseCreated(c, item, meta)
+}
+
+// Update handles update competitor request
+func (h *CompetitorHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	var req competitor.UpdateCompetitorRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	before, err := h.competitorService.GetByID(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	item, err := h.competitorService.Update(id, &req)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionUpdate, "competitor", id, before, item)
+
+	meta := &response.Meta{UpdatedBy: c.GetString("user_id")}
+	response.SuccessResponse(c, item, meta)
+}
+
+// Delete handles delete competitor request
+func (h *CompetitorHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	before, err := h.competitorService.GetByID(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	if err := h.competitorService.Delete(id); err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionDelete, "competitor", id, before, nil)
+
+	meta := &response.Meta{DeletedBy: c.GetString("user_id")}
+	response.SuccessResponseDeleted(c, "competitor", id, meta)
+}
+
+// handleError maps competitor service errors to error responses
+func (h *CompetitorHandler) handleError(c *gin.Context, err error, id string) {
+	switch {
+	case goerrors.Is(err, competitorservice.ErrCompetitorNotFound):
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource":    "competitor",
+			"resource_id": id,
+		}, nil)
+	case goerrors.Is(err, competitorservice.ErrCompetitorAlreadyExists):
+		errors.ErrorResponse(c, "RESOURCE_ALREADY_EXISTS", map[string]interface{}{
+			"resource": "competitor",
+			"field":    "name",
+		}, nil)
+	default:
+		errors.InternalServerErrorResponse(c, "")
+	}
+}
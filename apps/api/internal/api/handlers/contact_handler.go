@@ -1,21 +1,26 @@
 package handlers
 
 import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/audit_log"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/contact"
+	auditlogservice "github.com/gilabs/crm-healthcare/api/internal/service/audit_log"
 	contactservice "github.com/gilabs/crm-healthcare/api/internal/service/contact"
 	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"github.com/gilabs/crm-healthcare/api/pkg/response"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
 type ContactHandler struct {
-	contactService *contactservice.Service
+	contactService  *contactservice.Service
+	auditLogService *auditlogservice.Service
 }
 
-func NewContactHandler(contactService *contactservice.Service) *ContactHandler {
+func NewContactHandler(contactService *contactservice.Service, auditLogService *auditlogservice.Service) *ContactHandler {
 	return &ContactHandler{
-		contactService: contactService,
+		contactService:  contactService,
+		auditLogService: auditLogService,
 	}
 }
 
@@ -63,6 +68,24 @@ func (h *ContactHandler) List(c *gin.Context) {
 	response.SuccessResponse(c, contacts, meta)
 }
 
+// Export handles streaming every contact that matches the list filters as CSV, XLSX or NDJSON
+func (h *ContactHandler) Export(c *gin.Context) {
+	var req contact.ListContactsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	streamExport(c, "contacts", contact.ExportColumns, func(w export.Writer) error {
+		return h.contactService.Export(&req, w)
+	})
+}
+
 // GetByID handles get contact by ID request
 func (h *ContactHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
@@ -105,10 +128,15 @@ func (h *ContactHandler) Create(c *gin.Context) {
 			}, nil)
 			return
 		}
+		if duplicateFoundResponse(c, err) {
+			return
+		}
 		errors.InternalServerErrorResponse(c, "")
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionCreate, "contact", createdContact.ID, nil, createdContact)
+
 	meta := &response.Meta{}
 	if userID, exists := c.Get("user_id"); exists {
 		if id, ok := userID.(string); ok {
@@ -133,6 +161,7 @@ func (h *ContactHandler) Update(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.contactService.GetByID(id)
 	updatedContact, err := h.contactService.Update(id, &req)
 	if err != nil {
 		if err == contactservice.ErrContactNotFound {
@@ -153,6 +182,8 @@ func (h *ContactHandler) Update(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionUpdate, "contact", id, before, updatedContact)
+
 	meta := &response.Meta{}
 	if userID, exists := c.Get("user_id"); exists {
 		if id, ok := userID.(string); ok {
@@ -167,6 +198,7 @@ func (h *ContactHandler) Update(c *gin.Context) {
 func (h *ContactHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
+	before, _ := h.contactService.GetByID(id)
 	err := h.contactService.Delete(id)
 	if err != nil {
 		if err == contactservice.ErrContactNotFound {
@@ -180,6 +212,8 @@ func (h *ContactHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionDelete, "contact", id, before, nil)
+
 	// Get user ID for meta
 	meta := &response.Meta{}
 	if userIDVal, exists := c.Get("user_id"); exists {
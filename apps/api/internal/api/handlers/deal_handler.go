@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/activity"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/audit_log"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/visit_report"
 	activityservice "github.com/gilabs/crm-healthcare/api/internal/service/activity"
+	auditlogservice "github.com/gilabs/crm-healthcare/api/internal/service/audit_log"
 	pipelineservice "github.com/gilabs/crm-healthcare/api/internal/service/pipeline"
 	visitreportservice "github.com/gilabs/crm-healthcare/api/internal/service/visit_report"
 	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"github.com/gilabs/crm-healthcare/api/pkg/response"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -17,13 +21,15 @@ type DealHandler struct {
 	dealService        *pipelineservice.Service
 	visitReportService *visitreportservice.Service
 	activityService    *activityservice.Service
+	auditLogService    *auditlogservice.Service
 }
 
-func NewDealHandler(dealService *pipelineservice.Service, visitReportService *visitreportservice.Service, activityService *activityservice.Service) *DealHandler {
+func NewDealHandler(dealService *pipelineservice.Service, visitReportService *visitreportservice.Service, activityService *activityservice.Service, auditLogService *auditlogservice.Service) *DealHandler {
 	return &DealHandler{
 		dealService:        dealService,
 		visitReportService: visitReportService,
 		activityService:    activityService,
+		auditLogService:    auditLogService,
 	}
 }
 
@@ -40,7 +46,7 @@ func (h *DealHandler) List(c *gin.Context) {
 		return
 	}
 
-	deals, pagination, err := h.dealService.ListDeals(&req)
+	deals, pagination, err := h.dealService.WithScope(datascope.FromContext(c)).ListDeals(&req)
 	if err != nil {
 		errors.InternalServerErrorResponse(c, "")
 		return
@@ -80,11 +86,30 @@ func (h *DealHandler) List(c *gin.Context) {
 	response.SuccessResponse(c, deals, meta)
 }
 
+// Export handles streaming every deal that matches the list filters as CSV, XLSX or NDJSON
+func (h *DealHandler) Export(c *gin.Context) {
+	var req pipeline.ListDealsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	scoped := h.dealService.WithScope(datascope.FromContext(c))
+	streamExport(c, "deals", pipeline.ExportColumns, func(w export.Writer) error {
+		return scoped.ExportDeals(&req, w)
+	})
+}
+
 // GetByID handles get deal by ID request
 func (h *DealHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
 
-	deal, err := h.dealService.GetDealByID(id)
+	deal, err := h.dealService.WithScope(datascope.FromContext(c)).GetDealByID(id)
 	if err != nil {
 		if err == pipelineservice.ErrDealNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -143,10 +168,21 @@ func (h *DealHandler) Create(c *gin.Context) {
 			}, nil)
 			return
 		}
+		if err == pipelineservice.ErrStageNotInPipeline {
+			errors.ErrorResponse(c, "STAGE_NOT_IN_PIPELINE", map[string]interface{}{
+				"stage_id": req.StageID,
+			}, nil)
+			return
+		}
+		if handleLossError(c, err) {
+			return
+		}
 		errors.InternalServerErrorResponse(c, "")
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionCreate, "deal", createdDeal.ID, nil, createdDeal)
+
 	meta := &response.Meta{}
 	if userID != "" {
 		meta.CreatedBy = userID
@@ -169,7 +205,9 @@ func (h *DealHandler) Update(c *gin.Context) {
 		return
 	}
 
-	updatedDeal, err := h.dealService.UpdateDeal(id, &req)
+	scopedService := h.dealService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetDealByID(id)
+	updatedDeal, err := scopedService.UpdateDeal(id, &req, c.GetString("user_id"))
 	if err != nil {
 		if err == pipelineservice.ErrDealNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -192,10 +230,15 @@ func (h *DealHandler) Update(c *gin.Context) {
 			}, nil)
 			return
 		}
+		if handleLossError(c, err) {
+			return
+		}
 		errors.InternalServerErrorResponse(c, "")
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionUpdate, "deal", id, before, updatedDeal)
+
 	meta := &response.Meta{}
 	if userID, exists := c.Get("user_id"); exists {
 		if id, ok := userID.(string); ok {
@@ -220,7 +263,9 @@ func (h *DealHandler) Move(c *gin.Context) {
 		return
 	}
 
-	movedDeal, err := h.dealService.MoveDeal(id, &req)
+	scopedService := h.dealService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetDealByID(id)
+	movedDeal, err := scopedService.MoveDeal(id, &req, c.GetString("user_id"))
 	if err != nil {
 		if err == pipelineservice.ErrDealNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -236,10 +281,21 @@ func (h *DealHandler) Move(c *gin.Context) {
 			}, nil)
 			return
 		}
+		if err == pipelineservice.ErrStageNotInPipeline {
+			errors.ErrorResponse(c, "STAGE_NOT_IN_PIPELINE", map[string]interface{}{
+				"stage_id": req.StageID,
+			}, nil)
+			return
+		}
+		if handleLossError(c, err) {
+			return
+		}
 		errors.InternalServerErrorResponse(c, "")
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionMove, "deal", id, before, movedDeal)
+
 	meta := &response.Meta{}
 	if userID, exists := c.Get("user_id"); exists {
 		if id, ok := userID.(string); ok {
@@ -254,7 +310,9 @@ func (h *DealHandler) Move(c *gin.Context) {
 func (h *DealHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	err := h.dealService.DeleteDeal(id)
+	scopedService := h.dealService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetDealByID(id)
+	err := scopedService.DeleteDeal(id)
 	if err != nil {
 		if err == pipelineservice.ErrDealNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -267,6 +325,8 @@ func (h *DealHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionDelete, "deal", id, before, nil)
+
 	// Get user ID for meta
 	meta := &response.Meta{}
 	if userIDVal, exists := c.Get("user_id"); exists {
@@ -283,7 +343,7 @@ func (h *DealHandler) GetVisitReportsByDeal(c *gin.Context) {
 	dealID := c.Param("id")
 
 	// Verify deal exists
-	_, err := h.dealService.GetDealByID(dealID)
+	_, err := h.dealService.WithScope(datascope.FromContext(c)).GetDealByID(dealID)
 	if err != nil {
 		if err == pipelineservice.ErrDealNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -342,12 +402,139 @@ func (h *DealHandler) GetVisitReportsByDeal(c *gin.Context) {
 	response.SuccessResponse(c, visitReports, meta)
 }
 
+// GetStageHistory handles get deal stage history request
+func (h *DealHandler) GetStageHistory(c *gin.Context) {
+	dealID := c.Param("id")
+
+	history, err := h.dealService.WithScope(datascope.FromContext(c)).GetDealStageHistory(dealID)
+	if err != nil {
+		if err == pipelineservice.ErrDealNotFound {
+			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+				"resource":    "deal",
+				"resource_id": dealID,
+			}, nil)
+			return
+		}
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	response.SuccessResponse(c, history, nil)
+}
+
+// ListItems handles list deal items request
+func (h *DealHandler) ListItems(c *gin.Context) {
+	dealID := c.Param("id")
+
+	items, err := h.dealService.WithScope(datascope.FromContext(c)).ListDealItems(dealID)
+	if err != nil {
+		h.handleItemError(c, err, dealID, "")
+		return
+	}
+
+	response.SuccessResponse(c, items, nil)
+}
+
+// AddItem handles add deal item request
+func (h *DealHandler) AddItem(c *gin.Context) {
+	dealID := c.Param("id")
+	var req pipeline.CreateDealItemRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	item, err := h.dealService.WithScope(datascope.FromContext(c)).AddDealItem(dealID, &req)
+	if err != nil {
+		if err == pipelineservice.ErrProductNotFound {
+			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+				"resource":    "product",
+				"resource_id": req.ProductID,
+			}, nil)
+			return
+		}
+		h.handleItemError(c, err, dealID, "")
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionCreate, "deal_item", item.ID, nil, item)
+
+	meta := &response.Meta{CreatedBy: c.GetString("user_id")}
+	response.SuccessResponseCreated(c, item, meta)
+}
+
+// UpdateItem handles update deal item request
+func (h *DealHandler) UpdateItem(c *gin.Context) {
+	dealID := c.Param("id")
+	itemID := c.Param("itemId")
+	var req pipeline.UpdateDealItemRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	item, err := h.dealService.WithScope(datascope.FromContext(c)).UpdateDealItem(dealID, itemID, &req)
+	if err != nil {
+		h.handleItemError(c, err, dealID, itemID)
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionUpdate, "deal_item", itemID, nil, item)
+
+	meta := &response.Meta{UpdatedBy: c.GetString("user_id")}
+	response.SuccessResponse(c, item, meta)
+}
+
+// DeleteItem handles delete deal item request
+func (h *DealHandler) DeleteItem(c *gin.Context) {
+	dealID := c.Param("id")
+	itemID := c.Param("itemId")
+
+	if err := h.dealService.WithScope(datascope.FromContext(c)).DeleteDealItem(dealID, itemID); err != nil {
+		h.handleItemError(c, err, dealID, itemID)
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionDelete, "deal_item", itemID, nil, nil)
+
+	meta := &response.Meta{DeletedBy: c.GetString("user_id")}
+	response.SuccessResponseDeleted(c, "deal_item", itemID, meta)
+}
+
+// handleItemError maps deal item service errors to error responses
+func (h *DealHandler) handleItemError(c *gin.Context, err error, dealID, itemID string) {
+	switch err {
+	case pipelineservice.ErrDealNotFound:
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource":    "deal",
+			"resource_id": dealID,
+		}, nil)
+	case pipelineservice.ErrDealItemNotFound:
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource":    "deal_item",
+			"resource_id": itemID,
+		}, nil)
+	default:
+		errors.InternalServerErrorResponse(c, "")
+	}
+}
+
 // GetActivitiesByDeal handles get activities by deal ID request
 func (h *DealHandler) GetActivitiesByDeal(c *gin.Context) {
 	dealID := c.Param("id")
 
 	// Verify deal exists
-	_, err := h.dealService.GetDealByID(dealID)
+	_, err := h.dealService.WithScope(datascope.FromContext(c)).GetDealByID(dealID)
 	if err != nil {
 		if err == pipelineservice.ErrDealNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -406,3 +593,27 @@ func (h *DealHandler) GetActivitiesByDeal(c *gin.Context) {
 	response.SuccessResponse(c, activities, meta)
 }
 
+// handleLossError writes the error response of a missing or invalid loss detail and reports whether err was one
+func handleLossError(c *gin.Context, err error) bool {
+	switch err {
+	case pipelineservice.ErrLostReasonRequired:
+		errors.ErrorResponse(c, "LOST_REASON_REQUIRED", map[string]interface{}{
+			"field": "lost_reason_id",
+		}, nil)
+	case pipelineservice.ErrCompetitorRequired:
+		errors.ErrorResponse(c, "COMPETITOR_REQUIRED", map[string]interface{}{
+			"field": "competitor_id",
+		}, nil)
+	case pipelineservice.ErrInvalidLossReason:
+		errors.ErrorResponse(c, "INVALID_LOSS_REASON", map[string]interface{}{
+			"field": "lost_reason_id",
+		}, nil)
+	case pipelineservice.ErrInvalidCompetitor:
+		errors.ErrorResponse(c, "INVALID_COMPETITOR", map[string]interface{}{
+			"field": "competitor_id",
+		}, nil)
+	default:
+		return false
+	}
+	return true
+}
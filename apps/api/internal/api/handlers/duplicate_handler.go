@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	goerrors "errors"
+
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/audit_log"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/duplicate"
+	auditlogservice "github.com/gilabs/crm-healthcare/api/internal/service/audit_log"
+	duplicateservice "github.com/gilabs/crm-healthcare/api/internal/service/duplicate"
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type DuplicateHandler struct {
+	duplicateService *duplicateservice.Service
+	auditLogService  *auditlogservice.Service
+}
+
+func NewDuplicateHandler(duplicateService *duplicateservice.Service, auditLogService *auditlogservice.Service) *DuplicateHandler {
+	return &DuplicateHandler{
+		duplicateService: duplicateService,
+		auditLogService:  auditLogService,
+	}
+}
+
+// Check handles checking a record that is not saved yet for duplicates
+func (h *DuplicateHandler) Check(c *gin.Context) {
+	var req duplicate.CheckDuplicatesRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	matches, err := h.duplicateService.Check(&req)
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	response.SuccessResponse(c, matches, nil)
+}
+
+// LeadDuplicates handles listing the possible duplicates of a lead
+func (h *DuplicateHandler) LeadDuplicates(c *gin.Context) {
+	id := c.Param("id")
+
+	matches, err := h.duplicateService.WithScope(datascope.FromContext(c)).LeadDuplicates(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	response.SuccessResponse(c, matches, nil)
+}
+
+// AccountDuplicates handles listing the possible duplicates of an account
+func (h *DuplicateHandler) AccountDuplicates(c *gin.Context) {
+	id := c.Param("id")
+
+	matches, err := h.duplicateService.WithScope(datascope.FromContext(c)).AccountDuplicates(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	response.SuccessResponse(c, matches, nil)
+}
+
+// ContactDuplicates handles listing the possible duplicates of a contact
+func (h *DuplicateHandler) ContactDuplicates(c *gin.Context) {
+	id := c.Param("id")
+
+	matches, err := h.duplicateService.WithScope(datascope.FromContext(c)).ContactDuplicates(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	response.SuccessResponse(c, matches, nil)
+}
+
+// MergeLeads handles merging a duplicate lead into the lead in the URL
+func (h *DuplicateHandler) MergeLeads(c *gin.Context) {
+	id := c.Param("id")
+	req, ok := bindMergeRequest(c)
+	if !ok {
+		return
+	}
+
+	merged, err := h.duplicateService.WithScope(datascope.FromContext(c)).MergeLeads(id, req.DuplicateID)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionMerge, "lead", id, map[string]interface{}{"merged_id": req.DuplicateID}, merged)
+
+	response.SuccessResponse(c, merged, mergeMeta(c))
+}
+
+// MergeAccounts handles merging a duplicate account into the account in the URL
+func (h *DuplicateHandler) MergeAccounts(c *gin.Context) {
+	id := c.Param("id")
+	req, ok := bindMergeRequest(c)
+	if !ok {
+		return
+	}
+
+	merged, err := h.duplicateService.WithScope(datascope.FromContext(c)).MergeAccounts(id, req.DuplicateID)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionMerge, "account", id, map[string]interface{}{"merged_id": req.DuplicateID}, merged)
+
+	response.SuccessResponse(c, merged, mergeMeta(c))
+}
+
+// MergeContacts handles merging a duplicate contact into the contact in the URL
+func (h *DuplicateHandler) MergeContacts(c *gin.Context) {
+	id := c.Param("id")
+	req, ok := bindMergeRequest(c)
+	if !ok {
+		return
+	}
+
+	merged, err := h.duplicateService.WithScope(datascope.FromContext(c)).MergeContacts(id, req.DuplicateID)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionMerge, "contact", id, map[string]interface{}{"merged_id": req.DuplicateID}, merged)
+
+	response.SuccessResponse(c, merged, mergeMeta(c))
+}
+
+// bindMergeRequest binds a merge request, writing the error response when it is invalid
+func bindMergeRequest(c *gin.Context) (*duplicate.MergeRequest, bool) {
+	var req duplicate.MergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return nil, false
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return nil, false
+	}
+	return &req, true
+}
+
+// mergeMeta returns the response meta of a merge
+func mergeMeta(c *gin.Context) *response.Meta {
+	meta := &response.Meta{}
+	if userID := c.GetString("user_id"); userID != "" {
+		meta.UpdatedBy = userID
+	}
+	return meta
+}
+
+// handleError maps duplicate service errors to API errors
+func (h *DuplicateHandler) handleError(c *gin.Context, err error, id string) {
+	switch err {
+	case duplicateservice.ErrLeadNotFound:
+		errors.ErrorResponse(c, "LEAD_NOT_FOUND", map[string]interface{}{
+			"resource": "lead",
+		}, nil)
+	case duplicateservice.ErrAccountNotFound:
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource": "account",
+		}, nil)
+	case duplicateservice.ErrContactNotFound:
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource": "contact",
+		}, nil)
+	case duplicateservice.ErrMergeSameRecord, duplicateservice.ErrMergeConvertedLeads:
+		errors.ErrorResponse(c, "MERGE_NOT_ALLOWED", map[string]interface{}{
+			"resource_id": id,
+			"reason":      err.Error(),
+		}, nil)
+	default:
+		errors.InternalServerErrorResponse(c, "")
+	}
+}
+
+// duplicateFoundResponse writes a DUPLICATE_FOUND response listing the possible duplicates when err is a
+// duplicate.FoundError, and reports whether it did
+func duplicateFoundResponse(c *gin.Context, err error) bool {
+	var found *duplicate.FoundError
+	if !goerrors.As(err, &found) {
+		return false
+	}
+	errors.ErrorResponse(c, "DUPLICATE_FOUND", map[string]interface{}{
+		"entity_type": found.EntityType,
+		"duplicates":  found.Matches,
+	}, nil)
+	return true
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/email_outbox"
+	emailoutboxservice "github.com/gilabs/crm-healthcare/api/internal/service/email_outbox"
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type EmailOutboxHandler struct {
+	emailOutboxService *emailoutboxservice.Service
+}
+
+func NewEmailOutboxHandler(emailOutboxService *emailoutboxservice.Service) *EmailOutboxHandler {
+	return &EmailOutboxHandler{
+		emailOutboxService: emailOutboxService,
+	}
+}
+
+// List handles list email outbox request
+func (h *EmailOutboxHandler) List(c *gin.Context) {
+	var req email_outbox.ListEmailMessagesRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	messages, pagination, err := h.emailOutboxService.List(&req)
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	meta := &response.Meta{
+		Pagination: &response.PaginationMeta{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+			HasNext:    pagination.Page < pagination.TotalPages,
+			HasPrev:    pagination.Page > 1,
+		},
+		Filters: map[string]interface{}{},
+	}
+
+	if req.Status != "" {
+		meta.Filters["status"] = req.Status
+	}
+	if req.Template != "" {
+		meta.Filters["template"] = req.Template
+	}
+	if req.UserID != "" {
+		meta.Filters["user_id"] = req.UserID
+	}
+
+	response.SuccessResponse(c, messages, meta)
+}
+
+// GetByID handles get queued email by ID request
+func (h *EmailOutboxHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	message, err := h.emailOutboxService.GetByID(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	response.SuccessResponse(c, message, nil)
+}
+
+// Retry handles retry failed email request
+func (h *EmailOutboxHandler) Retry(c *gin.Context) {
+	id := c.Param("id")
+
+	message, err := h.emailOutboxService.Retry(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID := c.GetString("user_id"); userID != "" {
+		meta.UpdatedBy = userID
+	}
+
+	response.SuccessResponse(c, message, meta)
+}
+
+// handleError maps email outbox service errors to API errors
+func (h *EmailOutboxHandler) handleError(c *gin.Context, err error, id string) {
+	switch err {
+	case emailoutboxservice.ErrEmailNotFound:
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource":    "email",
+			"resource_id": id,
+		}, nil)
+	case emailoutboxservice.ErrEmailNotRetryable:
+		errors.ErrorResponse(c, "EMAIL_NOT_RETRYABLE", nil, nil)
+	default:
+		errors.InternalServerErrorResponse(c, "")
+	}
+}
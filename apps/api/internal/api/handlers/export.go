@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
+	"github.com/gin-gonic/gin"
+)
+
+// streamExport streams a list export to the client in the format of the format query parameter (csv, xlsx or ndjson).
+// write is called with the open writer and must write every row. A failure before the first byte is sent
+// becomes an error response; a later one can only cut the download short.
+func streamExport(c *gin.Context, name string, columns []export.Column, write func(w export.Writer) error) {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		errors.ErrorResponse(c, "INVALID_FORMAT", map[string]interface{}{
+			"format":        c.Query("format"),
+			"valid_formats": export.Formats,
+		}, nil)
+		return
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", export.FileName(name, format, time.Now())))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	w, err := export.NewWriter(format, c.Writer, name, columns)
+	if err == nil {
+		if err = write(w); err == nil {
+			err = w.Close()
+		}
+	}
+	if err == nil {
+		return
+	}
+
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		if err == export.ErrTooManyRows {
+			errors.ErrorResponse(c, "EXPORT_TOO_LARGE", map[string]interface{}{
+				"message": err.Error(),
+			}, nil)
+			return
+		}
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+	log.Printf("Warning: %s export stopped early: %v", name, err)
+	c.Abort()
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	goerrors "errors"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/audit_log"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/import_job"
+	auditlogservice "github.com/gilabs/crm-healthcare/api/internal/service/audit_log"
+	importjobservice "github.com/gilabs/crm-healthcare/api/internal/service/import_job"
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// importPermissions maps each entity type to the permission needed to import it; contacts belong to accounts
+var importPermissions = map[string]string{
+	import_job.EntityAccount: "IMPORT_ACCOUNTS",
+	import_job.EntityContact: "IMPORT_ACCOUNTS",
+	import_job.EntityLead:    "IMPORT_LEADS",
+	import_job.EntityProduct: "IMPORT_PRODUCTS",
+}
+
+type ImportHandler struct {
+	importJobService *importjobservice.Service
+	auditLogService  *auditlogservice.Service
+}
+
+func NewImportHandler(importJobService *importjobservice.Service, auditLogService *auditlogservice.Service) *ImportHandler {
+	return &ImportHandler{
+		importJobService: importJobService,
+		auditLogService:  auditLogService,
+	}
+}
+
+// Fields handles listing the importable fields of an entity type
+func (h *ImportHandler) Fields(c *gin.Context) {
+	entityType := c.Param("entity_type")
+
+	fields, err := h.importJobService.Fields(entityType)
+	if err != nil {
+		h.handleError(c, err, "")
+		return
+	}
+
+	response.SuccessResponse(c, fields, nil)
+}
+
+// List handles list import jobs request
+func (h *ImportHandler) List(c *gin.Context) {
+	var req import_job.ListImportJobsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	jobs, pagination, err := h.importJobService.List(&req)
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	meta := &response.Meta{
+		Pagination: &response.PaginationMeta{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+			HasNext:    pagination.Page < pagination.TotalPages,
+			HasPrev:    pagination.Page > 1,
+		},
+		Filters: map[string]interface{}{},
+	}
+
+	if req.EntityType != "" {
+		meta.Filters["entity_type"] = req.EntityType
+	}
+	if req.Status != "" {
+		meta.Filters["status"] = req.Status
+	}
+
+	response.SuccessResponse(c, jobs, meta)
+}
+
+// GetByID handles get import job request; used to poll the progress of a committed import
+func (h *ImportHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	job, err := h.importJobService.GetByID(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+	if !canImport(c, job.EntityType) {
+		return
+	}
+
+	response.SuccessResponse(c, job, nil)
+}
+
+// Create handles uploading a CSV or XLSX file; the response is the dry-run validation report
+func (h *ImportHandler) Create(c *gin.Context) {
+	var req import_job.CreateImportRequest
+
+	if err := c.ShouldBind(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+	if !canImport(c, req.EntityType) {
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		errors.ErrorResponse(c, "INVALID_IMPORT_FILE", map[string]interface{}{
+			"message": "No file provided. Upload a .csv or .xlsx file in the 'file' field",
+		}, nil)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+	defer file.Close()
+
+	job, err := h.importJobService.Create(&req, fileHeader.Filename, file, c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err, "")
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID := c.GetString("user_id"); userID != "" {
+		meta.CreatedBy = userID
+	}
+
+	response.SuccessResponseCreated(c, job, meta)
+}
+
+// Validate handles a new dry run of an import job with another column mapping or duplicate mode
+func (h *ImportHandler) Validate(c *gin.Context) {
+	id := c.Param("id")
+	var req import_job.ValidateImportRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+	if !h.authorizeJob(c, id) {
+		return
+	}
+
+	job, err := h.importJobService.Validate(id, &req)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	response.SuccessResponse(c, job, nil)
+}
+
+// Commit handles starting the import of a validated job; rows are written in the background
+func (h *ImportHandler) Commit(c *gin.Context) {
+	id := c.Param("id")
+	if !h.authorizeJob(c, id) {
+		return
+	}
+
+	job, err := h.importJobService.Commit(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionImport, "import_job", id, nil, job)
+	response.SuccessResponse(c, job, nil)
+}
+
+// authorizeJob writes an error response and returns false when the caller may not import the job's entity type
+func (h *ImportHandler) authorizeJob(c *gin.Context, id string) bool {
+	job, err := h.importJobService.GetByID(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return false
+	}
+	return canImport(c, job.EntityType)
+}
+
+// canImport writes a forbidden response and returns false when the caller lacks the import permission of the entity type
+func canImport(c *gin.Context, entityType string) bool {
+	required := importPermissions[entityType]
+	granted := c.GetStringSlice("user_permissions")
+	for _, code := range granted {
+		if code == required {
+			return true
+		}
+	}
+	errors.ForbiddenResponse(c, required, granted)
+	return false
+}
+
+func (h *ImportHandler) handleError(c *gin.Context, err error, id string) {
+	var mappingErr *importjobservice.MappingError
+	if goerrors.As(err, &mappingErr) {
+		errors.ErrorResponse(c, "INVALID_IMPORT_MAPPING", map[string]interface{}{
+			"problems": mappingErr.Problems,
+		}, nil)
+		return
+	}
+	if goerrors.Is(err, importjobservice.ErrInvalidFile) {
+		errors.ErrorResponse(c, "INVALID_IMPORT_FILE", map[string]interface{}{
+			"message": err.Error(),
+		}, nil)
+		return
+	}
+
+	switch err {
+	case importjobservice.ErrImportJobNotFound:
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource":    "import_job",
+			"resource_id": id,
+		}, nil)
+	case importjobservice.ErrInvalidEntityType:
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource":    "import_entity_type",
+			"resource_id": c.Param("entity_type"),
+		}, nil)
+	case importjobservice.ErrInvalidMappingJSON:
+		errors.ErrorResponse(c, "INVALID_IMPORT_MAPPING", map[string]interface{}{
+			"message": err.Error(),
+		}, nil)
+	case importjobservice.ErrImportNotAllowed:
+		errors.ErrorResponse(c, "IMPORT_NOT_ALLOWED", nil, nil)
+	default:
+		errors.InternalServerErrorResponse(c, "")
+	}
+}
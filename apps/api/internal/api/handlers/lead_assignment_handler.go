@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_assignment"
+	leadassignmentservice "github.com/gilabs/crm-healthcare/api/internal/service/lead_assignment"
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type LeadAssignmentHandler struct {
+	leadAssignmentService *leadassignmentservice.Service
+}
+
+func NewLeadAssignmentHandler(leadAssignmentService *leadassignmentservice.Service) *LeadAssignmentHandler {
+	return &LeadAssignmentHandler{
+		leadAssignmentService: leadAssignmentService,
+	}
+}
+
+// List handles list assignment rules request
+func (h *LeadAssignmentHandler) List(c *gin.Context) {
+	rules, err := h.leadAssignmentService.List()
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	response.SuccessResponse(c, rules, nil)
+}
+
+// GetByID handles get assignment rule by ID request
+func (h *LeadAssignmentHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	rule, err := h.leadAssignmentService.GetByID(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	response.SuccessResponse(c, rule, nil)
+}
+
+// Create handles create assignment rule request
+func (h *LeadAssignmentHandler) Create(c *gin.Context) {
+	var req lead_assignment.CreateAssignmentRuleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	rule, err := h.leadAssignmentService.Create(&req)
+	if err != nil {
+		h.handleError(c, err, "")
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID := c.GetString("user_id"); userID != "" {
+		meta.CreatedBy = userID
+	}
+
+	response.SuccessResponseCreated(c, rule, meta)
+}
+
+// Update handles update assignment rule request
+func (h *LeadAssignmentHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	var req lead_assignment.UpdateAssignmentRuleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	rule, err := h.leadAssignmentService.Update(id, &req)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID := c.GetString("user_id"); userID != "" {
+		meta.UpdatedBy = userID
+	}
+
+	response.SuccessResponse(c, rule, meta)
+}
+
+// Delete handles delete assignment rule request
+func (h *LeadAssignmentHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.leadAssignmentService.Delete(id); err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID := c.GetString("user_id"); userID != "" {
+		meta.DeletedBy = userID
+	}
+
+	response.SuccessResponseDeleted(c, "lead_assignment_rule", id, meta)
+}
+
+// handleError maps lead assignment service errors to API errors
+func (h *LeadAssignmentHandler) handleError(c *gin.Context, err error, id string) {
+	switch err {
+	case leadassignmentservice.ErrAssignmentRuleNotFound:
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource":    "lead_assignment_rule",
+			"resource_id": id,
+		}, nil)
+	case leadassignmentservice.ErrInvalidAssignmentRule:
+		errors.ErrorResponse(c, "INVALID_ASSIGNMENT_RULE", nil, nil)
+	case leadassignmentservice.ErrUserNotFound:
+		errors.ErrorResponse(c, "USER_NOT_FOUND", map[string]interface{}{
+			"field": "user_id",
+		}, nil)
+	case leadassignmentservice.ErrTeamNotFound:
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource": "team",
+			"field":    "team_id",
+		}, nil)
+	default:
+		errors.InternalServerErrorResponse(c, "")
+	}
+}
@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/activity"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/audit_log"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/visit_report"
 	activityservice "github.com/gilabs/crm-healthcare/api/internal/service/activity"
+	auditlogservice "github.com/gilabs/crm-healthcare/api/internal/service/audit_log"
 	leadservice "github.com/gilabs/crm-healthcare/api/internal/service/lead"
 	visitreportservice "github.com/gilabs/crm-healthcare/api/internal/service/visit_report"
 	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"github.com/gilabs/crm-healthcare/api/pkg/response"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -17,13 +21,15 @@ type LeadHandler struct {
 	leadService        *leadservice.Service
 	visitReportService *visitreportservice.Service
 	activityService    *activityservice.Service
+	auditLogService    *auditlogservice.Service
 }
 
-func NewLeadHandler(leadService *leadservice.Service, visitReportService *visitreportservice.Service, activityService *activityservice.Service) *LeadHandler {
+func NewLeadHandler(leadService *leadservice.Service, visitReportService *visitreportservice.Service, activityService *activityservice.Service, auditLogService *auditlogservice.Service) *LeadHandler {
 	return &LeadHandler{
 		leadService:        leadService,
 		visitReportService: visitReportService,
 		activityService:    activityService,
+		auditLogService:    auditLogService,
 	}
 }
 
@@ -40,7 +46,7 @@ func (h *LeadHandler) List(c *gin.Context) {
 		return
 	}
 
-	leads, pagination, err := h.leadService.List(&req)
+	leads, pagination, err := h.leadService.WithScope(datascope.FromContext(c)).List(&req)
 	if err != nil {
 		errors.InternalServerErrorResponse(c, "")
 		return
@@ -80,11 +86,30 @@ func (h *LeadHandler) List(c *gin.Context) {
 	response.SuccessResponse(c, leads, meta)
 }
 
+// Export handles streaming every lead that matches the list filters as CSV, XLSX or NDJSON
+func (h *LeadHandler) Export(c *gin.Context) {
+	var req lead.ListLeadsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	scoped := h.leadService.WithScope(datascope.FromContext(c))
+	streamExport(c, "leads", lead.ExportColumns, func(w export.Writer) error {
+		return scoped.Export(&req, w)
+	})
+}
+
 // GetByID handles get lead by ID request
 func (h *LeadHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
 
-	lead, err := h.leadService.GetByID(id)
+	lead, err := h.leadService.WithScope(datascope.FromContext(c)).GetByID(id)
 	if err != nil {
 		if err == leadservice.ErrLeadNotFound {
 			errors.ErrorResponse(c, "LEAD_NOT_FOUND", map[string]interface{}{
@@ -123,10 +148,15 @@ func (h *LeadHandler) Create(c *gin.Context) {
 
 	createdLead, err := h.leadService.Create(&req, userID)
 	if err != nil {
+		if duplicateFoundResponse(c, err) {
+			return
+		}
 		errors.InternalServerErrorResponse(c, "")
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionCreate, "lead", createdLead.ID, nil, createdLead)
+
 	meta := &response.Meta{}
 	if userID != "" {
 		meta.CreatedBy = userID
@@ -149,7 +179,9 @@ func (h *LeadHandler) Update(c *gin.Context) {
 		return
 	}
 
-	updatedLead, err := h.leadService.Update(id, &req)
+	scopedService := h.leadService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetByID(id)
+	updatedLead, err := scopedService.Update(id, &req)
 	if err != nil {
 		if err == leadservice.ErrLeadNotFound {
 			errors.ErrorResponse(c, "LEAD_NOT_FOUND", map[string]interface{}{
@@ -168,6 +200,8 @@ func (h *LeadHandler) Update(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionUpdate, "lead", id, before, updatedLead)
+
 	meta := &response.Meta{}
 	if userID, exists := c.Get("user_id"); exists {
 		if id, ok := userID.(string); ok {
@@ -182,7 +216,9 @@ func (h *LeadHandler) Update(c *gin.Context) {
 func (h *LeadHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	err := h.leadService.Delete(id)
+	scopedService := h.leadService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetByID(id)
+	err := scopedService.Delete(id)
 	if err != nil {
 		if err == leadservice.ErrLeadNotFound {
 			errors.ErrorResponse(c, "LEAD_NOT_FOUND", map[string]interface{}{
@@ -201,6 +237,8 @@ func (h *LeadHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionDelete, "lead", id, before, nil)
+
 	// Get user ID for meta
 	meta := &response.Meta{}
 	if userIDVal, exists := c.Get("user_id"); exists {
@@ -234,7 +272,7 @@ func (h *LeadHandler) Convert(c *gin.Context) {
 		}
 	}
 
-	convertResponse, err := h.leadService.Convert(id, &req, userID)
+	convertResponse, err := h.leadService.WithScope(datascope.FromContext(c)).Convert(id, &req, userID)
 	if err != nil {
 		if err == leadservice.ErrLeadNotFound {
 			errors.ErrorResponse(c, "LEAD_NOT_FOUND", map[string]interface{}{
@@ -275,6 +313,9 @@ func (h *LeadHandler) Convert(c *gin.Context) {
 			errors.ErrorResponse(c, "OPPORTUNITY_CREATION_FAILED", nil, nil)
 			return
 		}
+		if duplicateFoundResponse(c, err) {
+			return
+		}
 		errors.InternalServerErrorResponse(c, "")
 		return
 	}
@@ -338,7 +379,7 @@ func (h *LeadHandler) GetVisitReportsByLead(c *gin.Context) {
 	leadID := c.Param("id")
 
 	// Verify lead exists
-	_, err := h.leadService.GetByID(leadID)
+	_, err := h.leadService.WithScope(datascope.FromContext(c)).GetByID(leadID)
 	if err != nil {
 		if err == leadservice.ErrLeadNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -392,7 +433,7 @@ func (h *LeadHandler) GetActivitiesByLead(c *gin.Context) {
 	leadID := c.Param("id")
 
 	// Verify lead exists
-	_, err := h.leadService.GetByID(leadID)
+	_, err := h.leadService.WithScope(datascope.FromContext(c)).GetByID(leadID)
 	if err != nil {
 		if err == leadservice.ErrLeadNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -463,7 +504,7 @@ func (h *LeadHandler) CreateAccountFromLead(c *gin.Context) {
 		}
 	}
 
-	createResponse, err := h.leadService.CreateAccountFromLead(id, &req, userID)
+	createResponse, err := h.leadService.WithScope(datascope.FromContext(c)).CreateAccountFromLead(id, &req, userID)
 	if err != nil {
 		if err == leadservice.ErrLeadNotFound {
 			errors.ErrorResponse(c, "LEAD_NOT_FOUND", map[string]interface{}{
@@ -490,6 +531,9 @@ func (h *LeadHandler) CreateAccountFromLead(c *gin.Context) {
 			}, nil)
 			return
 		}
+		if duplicateFoundResponse(c, err) {
+			return
+		}
 		errors.InternalServerErrorResponse(c, "")
 		return
 	}
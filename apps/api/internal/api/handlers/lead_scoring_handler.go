@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_scoring"
+	leadscoringservice "github.com/gilabs/crm-healthcare/api/internal/service/lead_scoring"
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type LeadScoringHandler struct {
+	leadScoringService *leadscoringservice.Service
+}
+
+func NewLeadScoringHandler(leadScoringService *leadscoringservice.Service) *LeadScoringHandler {
+	return &LeadScoringHandler{
+		leadScoringService: leadScoringService,
+	}
+}
+
+// List handles list scoring rules request
+func (h *LeadScoringHandler) List(c *gin.Context) {
+	rules, err := h.leadScoringService.List()
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	response.SuccessResponse(c, rules, nil)
+}
+
+// GetByID handles get scoring rule by ID request
+func (h *LeadScoringHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	rule, err := h.leadScoringService.GetByID(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	response.SuccessResponse(c, rule, nil)
+}
+
+// Create handles create scoring rule request
+func (h *LeadScoringHandler) Create(c *gin.Context) {
+	var req lead_scoring.CreateScoringRuleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	rule, err := h.leadScoringService.Create(&req)
+	if err != nil {
+		h.handleError(c, err, "")
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID := c.GetString("user_id"); userID != "" {
+		meta.CreatedBy = userID
+	}
+
+	response.SuccessResponseCreated(c, rule, meta)
+}
+
+// Update handles update scoring rule request
+func (h *LeadScoringHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	var req lead_scoring.UpdateScoringRuleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	rule, err := h.leadScoringService.Update(id, &req)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID := c.GetString("user_id"); userID != "" {
+		meta.UpdatedBy = userID
+	}
+
+	response.SuccessResponse(c, rule, meta)
+}
+
+// Delete handles delete scoring rule request
+func (h *LeadScoringHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.leadScoringService.Delete(id); err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID := c.GetString("user_id"); userID != "" {
+		meta.DeletedBy = userID
+	}
+
+	response.SuccessResponseDeleted(c, "lead_scoring_rule", id, meta)
+}
+
+// Recompute handles rescoring all leads with the active rules
+func (h *LeadScoringHandler) Recompute(c *gin.Context) {
+	result, err := h.leadScoringService.Recompute()
+	if err != nil {
+		h.handleError(c, err, "")
+		return
+	}
+
+	response.SuccessResponse(c, result, nil)
+}
+
+// handleError maps lead scoring service errors to API errors
+func (h *LeadScoringHandler) handleError(c *gin.Context, err error, id string) {
+	switch err {
+	case leadscoringservice.ErrScoringRuleNotFound:
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource":    "lead_scoring_rule",
+			"resource_id": id,
+		}, nil)
+	case leadscoringservice.ErrInvalidScoringRule:
+		errors.ErrorResponse(c, "INVALID_SCORING_RULE", nil, nil)
+	case leadscoringservice.ErrScoringUnavailable:
+		errors.ErrorResponse(c, "SERVICE_UNAVAILABLE", nil, nil)
+	default:
+		errors.InternalServerErrorResponse(c, "")
+	}
+}
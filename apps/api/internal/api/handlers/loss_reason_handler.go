@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	goerrors "errors"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/audit_log"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/loss_reason"
+	auditlogservice "github.com/gilabs/crm-healthcare/api/internal/service/audit_log"
+	lossreasonservice "github.com/gilabs/crm-healthcare/api/internal/service/loss_reason"
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type LossReasonHandler struct {
+	lossReasonService *lossreasonservice.Service
+	auditLogService   *auditlogservice.Service
+}
+
+func NewLossReasonHandler(lossReasonService *lossreasonservice.Service, auditLogService *auditlogservice.Service) *LossReasonHandler {
+	return &LossReasonHandler{
+		lossReasonService: lossReasonService,
+		auditLogService:   auditLogService,
+	}
+}
+
+// List handles list loss reasons request
+func (h *LossReasonHandler) List(c *gin.Context) {
+	var req loss_reason.ListLossReasonsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	items, err := h.lossReasonService.List(&req)
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	response.SuccessResponse(c, items, nil)
+}
+
+// GetByID handles get loss reason by ID request
+func (h *LossReasonHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	item, err := h.lossReasonService.GetByID(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	response.SuccessResponse(c, item, nil)
+}
+
+// Create handles create loss reason request
+func (h *LossReasonHandler) Create(c *gin.Context) {
+	var req loss_reason.CreateLossReasonRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	item, err := h.lossReasonService.Create(&req)
+	if err != nil {
+		h.handleError(c, err, "")
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionCreate, "loss_reason", item.ID, nil, item)
+
+	meta := &response.Meta{CreatedBy: c.GetString("user_id")}
+	response.SuccessResponseCreated(c, item, meta)
+}
+
+// Update handles update loss reason request
+func (h *LossReasonHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	var req loss_reason.UpdateLossReasonRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	before, err := h.lossReasonService.GetByID(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	item, err := h.lossReasonService.Update(id, &req)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionUpdate, "loss_reason", id, before, item)
+
+	meta := &response.Meta{UpdatedBy: c.GetString("user_id")}
+	response.SuccessResponse(c, item, meta)
+}
+
+// Delete handles delete loss reason request
+func (h *LossReasonHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	before, err := h.lossReasonService.GetByID(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	if err := h.lossReasonService.Delete(id); err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionDelete, "loss_reason", id, before, nil)
+
+	meta := &response.Meta{DeletedBy: c.GetString("user_id")}
+	response.SuccessResponseDeleted(c, "loss_reason", id, meta)
+}
+
+// handleError maps loss reason service errors to error responses
+func (h *LossReasonHandler) handleError(c *gin.Context, err error, id string) {
+	switch {
+	case goerrors.Is(err, lossreasonservice.ErrLossReasonNotFound):
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource":    "loss_reason",
+			"resource_id": id,
+		}, nil)
+	case goerrors.Is(err, lossreasonservice.ErrLossReasonAlreadyExists):
+		errors.ErrorResponse(c, "RESOURCE_ALREADY_EXISTS", map[string]interface{}{
+			"resource": "loss_reason",
+			"field":    "code",
+		}, nil)
+	default:
+		errors.InternalServerErrorResponse(c, "")
+	}
+}
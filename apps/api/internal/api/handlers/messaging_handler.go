@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/messaging"
+	messagingservice "github.com/gilabs/crm-healthcare/api/internal/service/messaging"
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type MessagingHandler struct {
+	messagingService *messagingservice.Service
+}
+
+func NewMessagingHandler(messagingService *messagingservice.Service) *MessagingHandler {
+	return &MessagingHandler{
+		messagingService: messagingService,
+	}
+}
+
+// GetPreference handles get messaging preference of the current user request
+func (h *MessagingHandler) GetPreference(c *gin.Context) {
+	preference, err := h.messagingService.GetPreference(c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, preference, nil)
+}
+
+// UpdatePreference handles update messaging preference of the current user request
+func (h *MessagingHandler) UpdatePreference(c *gin.Context) {
+	var req messaging.UpdatePreferenceRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	userID := c.GetString("user_id")
+	preference, err := h.messagingService.UpdatePreference(userID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID != "" {
+		meta.UpdatedBy = userID
+	}
+
+	response.SuccessResponse(c, preference, meta)
+}
+
+// ListMessages handles list outbound messages request
+func (h *MessagingHandler) ListMessages(c *gin.Context) {
+	var req messaging.ListOutboundMessagesRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	messages, pagination, err := h.messagingService.ListMessages(&req)
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	meta := &response.Meta{
+		Pagination: &response.PaginationMeta{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+			HasNext:    pagination.Page < pagination.TotalPages,
+			HasPrev:    pagination.Page > 1,
+		},
+		Filters: map[string]interface{}{},
+	}
+
+	if req.Channel != "" {
+		meta.Filters["channel"] = req.Channel
+	}
+	if req.Status != "" {
+		meta.Filters["status"] = req.Status
+	}
+	if req.Template != "" {
+		meta.Filters["template"] = req.Template
+	}
+	if req.UserID != "" {
+		meta.Filters["user_id"] = req.UserID
+	}
+
+	response.SuccessResponse(c, messages, meta)
+}
+
+// VerifyWebhook answers the subscription handshake of the WhatsApp Business API, which sends the
+// configured token as hub.verify_token and expects hub.challenge back
+func (h *MessagingHandler) VerifyWebhook(c *gin.Context) {
+	if c.Query("hub.mode") != "subscribe" || !h.messagingService.VerifyWebhookToken(c.Query("hub.verify_token")) {
+		errors.UnauthorizedResponse(c, "invalid webhook token")
+		return
+	}
+
+	c.String(http.StatusOK, c.Query("hub.challenge"))
+}
+
+// Receipt handles delivery receipts posted by a gateway; the webhook URL carries the configured token
+func (h *MessagingHandler) Receipt(c *gin.Context) {
+	if !h.messagingService.VerifyWebhookToken(c.Query("token")) {
+		errors.UnauthorizedResponse(c, "invalid webhook token")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	if err != nil {
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	channel := c.Param("channel")
+	if err := h.messagingService.HandleReceipt(channel, c.ContentType(), body); err != nil {
+		switch err {
+		case messagingservice.ErrChannelNotConfigured:
+			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+				"resource":    "messaging channel",
+				"resource_id": channel,
+			}, nil)
+		default:
+			errors.InvalidRequestBodyResponse(c)
+		}
+		return
+	}
+
+	response.SuccessResponse(c, nil, nil)
+}
+
+// handleError maps messaging service errors to API errors
+func (h *MessagingHandler) handleError(c *gin.Context, err error) {
+	switch err {
+	case messagingservice.ErrPhoneRequired:
+		errors.ErrorResponse(c, "PHONE_REQUIRED", nil, nil)
+	case messagingservice.ErrInvalidPhone:
+		errors.ErrorResponse(c, "INVALID_PHONE", nil, nil)
+	default:
+		errors.InternalServerErrorResponse(c, "")
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
 	pipelineservice "github.com/gilabs/crm-healthcare/api/internal/service/pipeline"
 	"github.com/gilabs/crm-healthcare/api/pkg/errors"
@@ -42,6 +43,9 @@ func (h *PipelineHandler) ListStages(c *gin.Context) {
 		Filters: map[string]interface{}{},
 	}
 
+	if req.PipelineID != "" {
+		meta.Filters["pipeline_id"] = req.PipelineID
+	}
 	if req.IsActive != nil {
 		meta.Filters["is_active"] = *req.IsActive
 	}
@@ -84,6 +88,13 @@ func (h *PipelineHandler) CreateStage(c *gin.Context) {
 
 	stage, err := h.pipelineService.CreateStage(&req)
 	if err != nil {
+		if err == pipelineservice.ErrPipelineNotFound {
+			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+				"resource":    "pipeline",
+				"resource_id": req.PipelineID,
+			}, nil)
+			return
+		}
 		if err.Error() == "pipeline stage with this code already exists" {
 			errors.ErrorResponse(c, "DUPLICATE_ENTRY", map[string]interface{}{
 				"field": "code",
@@ -198,6 +209,12 @@ func (h *PipelineHandler) UpdateStagesOrder(c *gin.Context) {
 			}, nil)
 			return
 		}
+		if err == pipelineservice.ErrStageNotInPipeline {
+			errors.ErrorResponse(c, "STAGE_NOT_IN_PIPELINE", map[string]interface{}{
+				"pipeline_id": req.PipelineID,
+			}, nil)
+			return
+		}
 		errors.InternalServerErrorResponse(c, "")
 		return
 	}
@@ -214,13 +231,33 @@ func (h *PipelineHandler) UpdateStagesOrder(c *gin.Context) {
 
 // GetSummary handles get pipeline summary request
 func (h *PipelineHandler) GetSummary(c *gin.Context) {
-	summary, err := h.pipelineService.GetSummary()
+	var req pipeline.PipelineFilterRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	summary, err := h.pipelineService.GetSummary(req.PipelineID)
 	if err != nil {
-		errors.InternalServerErrorResponse(c, "")
+		h.handlePipelineError(c, err, req.PipelineID)
 		return
 	}
 
-	response.SuccessResponse(c, summary, nil)
+	var meta *response.Meta
+	if req.PipelineID != "" {
+		meta = &response.Meta{
+			Filters: map[string]interface{}{
+				"pipeline_id": req.PipelineID,
+			},
+		}
+	}
+
+	response.SuccessResponse(c, summary, meta)
 }
 
 // GetForecast handles get forecast request
@@ -230,9 +267,19 @@ func (h *PipelineHandler) GetForecast(c *gin.Context) {
 		periodType = "month"
 	}
 
-	forecast, err := h.pipelineService.GetForecast(periodType)
+	var req pipeline.PipelineFilterRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	forecast, err := h.pipelineService.GetForecast(periodType, req.PipelineID)
 	if err != nil {
-		errors.InternalServerErrorResponse(c, "")
+		h.handlePipelineError(c, err, req.PipelineID)
 		return
 	}
 
@@ -241,10 +288,173 @@ func (h *PipelineHandler) GetForecast(c *gin.Context) {
 			"period": periodType,
 		},
 	}
+	if req.PipelineID != "" {
+		meta.Filters["pipeline_id"] = req.PipelineID
+	}
 
 	response.SuccessResponse(c, forecast, meta)
 }
 
+// GetAnalytics handles pipeline analytics request
+func (h *PipelineHandler) GetAnalytics(c *gin.Context) {
+	var req pipeline.PipelineAnalyticsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	analytics, err := h.pipelineService.WithScope(datascope.FromContext(c)).GetAnalytics(&req)
+	if err != nil {
+		h.handlePipelineError(c, err, req.PipelineID)
+		return
+	}
+
+	meta := &response.Meta{
+		Filters: map[string]interface{}{
+			"pipeline_id": analytics.PipelineID,
+			"start_date":  req.StartDate,
+			"end_date":    req.EndDate,
+			"assigned_to": req.AssignedTo,
+		},
+	}
+
+	response.SuccessResponse(c, analytics, meta)
+}
+
+// ListPipelines handles list pipelines request
+func (h *PipelineHandler) ListPipelines(c *gin.Context) {
+	var req pipeline.ListPipelinesRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	pipelines, err := h.pipelineService.ListPipelines(&req)
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	meta := &response.Meta{
+		Filters: map[string]interface{}{},
+	}
+
+	if req.IsActive != nil {
+		meta.Filters["is_active"] = *req.IsActive
+	}
+
+	response.SuccessResponse(c, pipelines, meta)
+}
+
+// GetPipelineByID handles get pipeline by ID request
+func (h *PipelineHandler) GetPipelineByID(c *gin.Context) {
+	id := c.Param("id")
+
+	p, err := h.pipelineService.GetPipelineByID(id)
+	if err != nil {
+		h.handlePipelineError(c, err, id)
+		return
+	}
+
+	response.SuccessResponse(c, p, nil)
+}
+
+// CreatePipeline handles create pipeline request
+func (h *PipelineHandler) CreatePipeline(c *gin.Context) {
+	var req pipeline.CreatePipelineRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	p, err := h.pipelineService.CreatePipeline(&req)
+	if err != nil {
+		h.handlePipelineError(c, err, "")
+		return
+	}
+
+	meta := &response.Meta{CreatedBy: c.GetString("user_id")}
+	response.SuccessResponseCreated(c, p, meta)
+}
+
+// UpdatePipeline handles update pipeline request
+func (h *PipelineHandler) UpdatePipeline(c *gin.Context) {
+	id := c.Param("id")
+	var req pipeline.UpdatePipelineRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	p, err := h.pipelineService.UpdatePipeline(id, &req)
+	if err != nil {
+		h.handlePipelineError(c, err, id)
+		return
+	}
+
+	meta := &response.Meta{UpdatedBy: c.GetString("user_id")}
+	response.SuccessResponse(c, p, meta)
+}
+
+// DeletePipeline handles delete pipeline request
+func (h *PipelineHandler) DeletePipeline(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.pipelineService.DeletePipeline(id); err != nil {
+		h.handlePipelineError(c, err, id)
+		return
+	}
+
+	meta := &response.Meta{DeletedBy: c.GetString("user_id")}
+	response.SuccessResponseDeleted(c, "pipeline", id, meta)
+}
+
+// handlePipelineError maps pipeline service errors to error responses
+func (h *PipelineHandler) handlePipelineError(c *gin.Context, err error, id string) {
+	switch err {
+	case pipelineservice.ErrPipelineNotFound:
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource":    "pipeline",
+			"resource_id": id,
+		}, nil)
+	case pipelineservice.ErrPipelineCodeExists:
+		errors.ErrorResponse(c, "CONFLICT", map[string]interface{}{
+			"field":  "code",
+			"reason": err.Error(),
+		}, nil)
+	case pipelineservice.ErrPipelineInUse:
+		errors.ErrorResponse(c, "PIPELINE_IN_USE", map[string]interface{}{
+			"pipeline_id": id,
+		}, nil)
+	case pipelineservice.ErrDefaultPipeline:
+		errors.ErrorResponse(c, "DEFAULT_PIPELINE_REQUIRED", map[string]interface{}{
+			"pipeline_id": id,
+		}, nil)
+	default:
+		errors.InternalServerErrorResponse(c, "")
+	}
+}
+
 // Flow Rules Handlers
 // TODO: Flow Rules feature is not yet implemented. Uncomment when implementing flow rules.
 /*
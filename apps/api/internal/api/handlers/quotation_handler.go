@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	goerrors "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/audit_log"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/quotation"
+	auditlogservice "github.com/gilabs/crm-healthcare/api/internal/service/audit_log"
+	quotationservice "github.com/gilabs/crm-healthcare/api/internal/service/quotation"
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type QuotationHandler struct {
+	quotationService *quotationservice.Service
+	auditLogService  *auditlogservice.Service
+}
+
+func NewQuotationHandler(quotationService *quotationservice.Service, auditLogService *auditlogservice.Service) *QuotationHandler {
+	return &QuotationHandler{
+		quotationService: quotationService,
+		auditLogService:  auditLogService,
+	}
+}
+
+// List handles list quotations request
+func (h *QuotationHandler) List(c *gin.Context) {
+	var req quotation.ListQuotationsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	quotations, pagination, err := h.quotationService.WithScope(datascope.FromContext(c)).List(&req)
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	meta := &response.Meta{
+		Pagination: &response.PaginationMeta{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+			HasNext:    pagination.Page < pagination.TotalPages,
+			HasPrev:    pagination.Page > 1,
+		},
+		Filters: map[string]interface{}{},
+	}
+
+	if req.Search != "" {
+		meta.Filters["search"] = req.Search
+	}
+	if req.DealID != "" {
+		meta.Filters["deal_id"] = req.DealID
+	}
+	if req.AccountID != "" {
+		meta.Filters["account_id"] = req.AccountID
+	}
+	if req.Status != "" {
+		meta.Filters["status"] = req.Status
+	}
+
+	response.SuccessResponse(c, quotations, meta)
+}
+
+// GetByID handles get quotation by ID request
+func (h *QuotationHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	q, err := h.quotationService.WithScope(datascope.FromContext(c)).GetByID(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	response.SuccessResponse(c, q, nil)
+}
+
+// ListRevisions handles list quotation revisions request
+func (h *QuotationHandler) ListRevisions(c *gin.Context) {
+	id := c.Param("id")
+
+	revisions, err := h.quotationService.WithScope(datascope.FromContext(c)).ListRevisions(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	response.SuccessResponse(c, revisions, nil)
+}
+
+// DownloadRevisionPDF handles download quotation revision PDF request
+func (h *QuotationHandler) DownloadRevisionPDF(c *gin.Context) {
+	id := c.Param("id")
+	revision, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		h.handleError(c, quotationservice.ErrRevisionNotFound, id)
+		return
+	}
+
+	content, fileName, err := h.quotationService.WithScope(datascope.FromContext(c)).RevisionPDF(id, revision)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename="+fileName)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/pdf", content)
+}
+
+// Create handles create quotation request
+func (h *QuotationHandler) Create(c *gin.Context) {
+	var req quotation.CreateQuotationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	userID := c.GetString("user_id")
+	q, err := h.quotationService.WithScope(datascope.FromContext(c)).Create(&req, userID)
+	if err != nil {
+		if goerrors.Is(err, quotationservice.ErrDealNotFound) {
+			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+				"resource":    "deal",
+				"resource_id": req.DealID,
+			}, nil)
+			return
+		}
+		h.handleError(c, err, "")
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionCreate, "quotation", q.ID, nil, q)
+
+	meta := &response.Meta{CreatedBy: userID}
+	response.SuccessResponseCreated(c, q, meta)
+}
+
+// Revise handles revise quotation request
+func (h *QuotationHandler) Revise(c *gin.Context) {
+	id := c.Param("id")
+	var req quotation.ReviseQuotationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	userID := c.GetString("user_id")
+	q, err := h.quotationService.WithScope(datascope.FromContext(c)).Revise(id, &req, userID)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionUpdate, "quotation", q.ID, nil, q)
+
+	meta := &response.Meta{CreatedBy: userID}
+	response.SuccessResponseCreated(c, q, meta)
+}
+
+// Send handles mark quotation as sent request
+func (h *QuotationHandler) Send(c *gin.Context) {
+	id := c.Param("id")
+
+	q, err := h.quotationService.WithScope(datascope.FromContext(c)).Send(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionUpdate, "quotation", q.ID, nil, q)
+
+	meta := &response.Meta{UpdatedBy: c.GetString("user_id")}
+	response.SuccessResponse(c, q, meta)
+}
+
+// Accept handles accept quotation request; the deal of the quotation is moved to the won stage
+func (h *QuotationHandler) Accept(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("user_id")
+
+	q, err := h.quotationService.WithScope(datascope.FromContext(c)).Accept(id, userID)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionApprove, "quotation", q.ID, nil, q)
+
+	meta := &response.Meta{UpdatedBy: userID}
+	response.SuccessResponse(c, q, meta)
+}
+
+// Reject handles reject quotation request
+func (h *QuotationHandler) Reject(c *gin.Context) {
+	id := c.Param("id")
+	var req quotation.RejectQuotationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	q, err := h.quotationService.WithScope(datascope.FromContext(c)).Reject(id, &req)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	recordAudit(c, h.auditLogService, audit_log.ActionReject, "quotation", q.ID, nil, q)
+
+	meta := &response.Meta{UpdatedBy: c.GetString("user_id")}
+	response.SuccessResponse(c, q, meta)
+}
+
+// handleError maps quotation service errors to error responses
+func (h *QuotationHandler) handleError(c *gin.Context, err error, id string) {
+	switch {
+	case goerrors.Is(err, quotationservice.ErrQuotationNotFound):
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource":    "quotation",
+			"resource_id": id,
+		}, nil)
+	case goerrors.Is(err, quotationservice.ErrRevisionNotFound):
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource":    "quotation_revision",
+			"resource_id": c.Param("rev"),
+		}, nil)
+	case goerrors.Is(err, quotationservice.ErrDealNotFound):
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource": "deal",
+		}, nil)
+	case goerrors.Is(err, quotationservice.ErrDealWithoutItems):
+		errors.ErrorResponse(c, "QUOTATION_WITHOUT_ITEMS", nil, nil)
+	case goerrors.Is(err, quotationservice.ErrInvalidStatus):
+		errors.ErrorResponse(c, "INVALID_QUOTATION_STATUS", nil, nil)
+	case goerrors.Is(err, quotationservice.ErrInvalidValidUntil):
+		errors.ErrorResponse(c, "INVALID_VALID_UNTIL", map[string]interface{}{
+			"field": "valid_until",
+		}, nil)
+	case goerrors.Is(err, quotationservice.ErrNoWonStage):
+		errors.ErrorResponse(c, "NO_WON_STAGE", nil, nil)
+	default:
+		errors.InternalServerErrorResponse(c, "")
+	}
+}
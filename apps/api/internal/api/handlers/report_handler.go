@@ -115,7 +115,7 @@ func (h *ReportHandler) GetAccountActivityReport(c *gin.Context) {
 	response.SuccessResponse(c, reportData, nil)
 }
 
-// ExportVisitReportReport exports visit report report as CSV
+// ExportVisitReportReport exports visit report report as CSV, Excel or PDF
 func (h *ReportHandler) ExportVisitReportReport(c *gin.Context) {
 	var req report.ReportRequest
 
@@ -129,10 +129,10 @@ func (h *ReportHandler) ExportVisitReportReport(c *gin.Context) {
 	}
 
 	format := c.DefaultQuery("format", "csv")
-	if format != "csv" && format != "excel" {
+	if !isValidReportFormat(format) {
 		errors.ErrorResponse(c, "INVALID_FORMAT", map[string]interface{}{
 			"format":      format,
-			"valid_formats": []string{"csv", "excel"},
+			"valid_formats": reportFormats,
 		}, nil)
 		return
 	}
@@ -143,17 +143,14 @@ func (h *ReportHandler) ExportVisitReportReport(c *gin.Context) {
 		return
 	}
 
-	contentType := "text/csv"
-	if format == "excel" {
-		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
-	}
+	contentType := reportContentType(format)
 
 	c.Header("Content-Type", contentType)
 	c.Header("Content-Disposition", "attachment; filename="+filename)
 	c.Data(200, contentType, csvData)
 }
 
-// ExportPipelineReport exports pipeline report as CSV
+// ExportPipelineReport exports pipeline report as CSV, Excel or PDF
 func (h *ReportHandler) ExportPipelineReport(c *gin.Context) {
 	var req report.ReportRequest
 
@@ -167,10 +164,10 @@ func (h *ReportHandler) ExportPipelineReport(c *gin.Context) {
 	}
 
 	format := c.DefaultQuery("format", "csv")
-	if format != "csv" && format != "excel" {
+	if !isValidReportFormat(format) {
 		errors.ErrorResponse(c, "INVALID_FORMAT", map[string]interface{}{
 			"format":      format,
-			"valid_formats": []string{"csv", "excel"},
+			"valid_formats": reportFormats,
 		}, nil)
 		return
 	}
@@ -181,17 +178,14 @@ func (h *ReportHandler) ExportPipelineReport(c *gin.Context) {
 		return
 	}
 
-	contentType := "text/csv"
-	if format == "excel" {
-		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
-	}
+	contentType := reportContentType(format)
 
 	c.Header("Content-Type", contentType)
 	c.Header("Content-Disposition", "attachment; filename="+filename)
 	c.Data(200, contentType, csvData)
 }
 
-// ExportSalesPerformanceReport exports sales performance report as CSV
+// ExportSalesPerformanceReport exports sales performance report as CSV, Excel or PDF
 func (h *ReportHandler) ExportSalesPerformanceReport(c *gin.Context) {
 	var req report.ReportRequest
 
@@ -205,10 +199,10 @@ func (h *ReportHandler) ExportSalesPerformanceReport(c *gin.Context) {
 	}
 
 	format := c.DefaultQuery("format", "csv")
-	if format != "csv" && format != "excel" {
+	if !isValidReportFormat(format) {
 		errors.ErrorResponse(c, "INVALID_FORMAT", map[string]interface{}{
 			"format":      format,
-			"valid_formats": []string{"csv", "excel"},
+			"valid_formats": reportFormats,
 		}, nil)
 		return
 	}
@@ -219,17 +213,14 @@ func (h *ReportHandler) ExportSalesPerformanceReport(c *gin.Context) {
 		return
 	}
 
-	contentType := "text/csv"
-	if format == "excel" {
-		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
-	}
+	contentType := reportContentType(format)
 
 	c.Header("Content-Type", contentType)
 	c.Header("Content-Disposition", "attachment; filename="+filename)
 	c.Data(200, contentType, csvData)
 }
 
-// ExportAccountActivityReport exports account activity report as CSV
+// ExportAccountActivityReport exports account activity report as CSV, Excel or PDF
 func (h *ReportHandler) ExportAccountActivityReport(c *gin.Context) {
 	var req report.ReportRequest
 
@@ -256,10 +247,10 @@ func (h *ReportHandler) ExportAccountActivityReport(c *gin.Context) {
 	}
 
 	format := c.DefaultQuery("format", "csv")
-	if format != "csv" && format != "excel" {
+	if !isValidReportFormat(format) {
 		errors.ErrorResponse(c, "INVALID_FORMAT", map[string]interface{}{
 			"format":      format,
-			"valid_formats": []string{"csv", "excel"},
+			"valid_formats": reportFormats,
 		}, nil)
 		return
 	}
@@ -277,13 +268,90 @@ func (h *ReportHandler) ExportAccountActivityReport(c *gin.Context) {
 		return
 	}
 
-	contentType := "text/csv"
-	if format == "excel" {
-		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
-	}
+	contentType := reportContentType(format)
 
 	c.Header("Content-Type", contentType)
 	c.Header("Content-Disposition", "attachment; filename="+filename)
 	c.Data(200, contentType, csvData)
 }
 
+// GetWinLossReport handles win/loss analysis report request
+func (h *ReportHandler) GetWinLossReport(c *gin.Context) {
+	var req report.ReportRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	reportData, err := h.reportService.GetWinLossReport(&req)
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	response.SuccessResponse(c, reportData, nil)
+}
+
+// ExportWinLossReport exports win/loss analysis report as CSV, Excel or PDF
+func (h *ReportHandler) ExportWinLossReport(c *gin.Context) {
+	var req report.ReportRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	if !isValidReportFormat(format) {
+		errors.ErrorResponse(c, "INVALID_FORMAT", map[string]interface{}{
+			"format":        format,
+			"valid_formats": reportFormats,
+		}, nil)
+		return
+	}
+
+	data, filename, err := h.reportService.ExportWinLossReport(&req, format)
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	contentType := reportContentType(format)
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(200, contentType, data)
+}
+
+
+// reportFormats are the values accepted by the format query parameter of report exports
+var reportFormats = []string{"csv", "excel", "pdf"}
+
+func isValidReportFormat(format string) bool {
+	for _, f := range reportFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// reportContentType returns the MIME type of a report export format
+func reportContentType(format string) string {
+	switch format {
+	case "excel":
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case "pdf":
+		return "application/pdf"
+	}
+	return "text/csv"
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	goerrors "errors"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/report_subscription"
+	reportsubscriptionservice "github.com/gilabs/crm-healthcare/api/internal/service/report_subscription"
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ReportSubscriptionHandler struct {
+	subscriptionService *reportsubscriptionservice.Service
+}
+
+func NewReportSubscriptionHandler(subscriptionService *reportsubscriptionservice.Service) *ReportSubscriptionHandler {
+	return &ReportSubscriptionHandler{
+		subscriptionService: subscriptionService,
+	}
+}
+
+// List handles list report subscriptions request
+func (h *ReportSubscriptionHandler) List(c *gin.Context) {
+	var req report_subscription.ListReportSubscriptionsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	subscriptions, pagination, err := h.subscriptionService.List(&req)
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	meta := &response.Meta{
+		Pagination: &response.PaginationMeta{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+			HasNext:    pagination.Page < pagination.TotalPages,
+			HasPrev:    pagination.Page > 1,
+		},
+		Filters: map[string]interface{}{},
+	}
+
+	if req.ReportType != "" {
+		meta.Filters["report_type"] = req.ReportType
+	}
+	if req.IsActive != nil {
+		meta.Filters["is_active"] = *req.IsActive
+	}
+
+	response.SuccessResponse(c, subscriptions, meta)
+}
+
+// GetByID handles get report subscription by ID request
+func (h *ReportSubscriptionHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	subscription, err := h.subscriptionService.GetByID(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	response.SuccessResponse(c, subscription, nil)
+}
+
+// Create handles create report subscription request
+func (h *ReportSubscriptionHandler) Create(c *gin.Context) {
+	var req report_subscription.CreateReportSubscriptionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	userID := c.GetString("user_id")
+	createdSubscription, err := h.subscriptionService.Create(&req, userID)
+	if err != nil {
+		h.handleError(c, err, "")
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID != "" {
+		meta.CreatedBy = userID
+	}
+
+	response.SuccessResponseCreated(c, createdSubscription, meta)
+}
+
+// Update handles update report subscription request
+func (h *ReportSubscriptionHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	var req report_subscription.UpdateReportSubscriptionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	updatedSubscription, err := h.subscriptionService.Update(id, &req)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID := c.GetString("user_id"); userID != "" {
+		meta.UpdatedBy = userID
+	}
+
+	response.SuccessResponse(c, updatedSubscription, meta)
+}
+
+// Delete handles delete report subscription request
+func (h *ReportSubscriptionHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.subscriptionService.Delete(id); err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID := c.GetString("user_id"); userID != "" {
+		meta.DeletedBy = userID
+	}
+
+	response.SuccessResponseDeleted(c, "report_subscription", id, meta)
+}
+
+// Send handles send report subscription now request. A failed delivery is still returned;
+// its status and error describe the failure.
+func (h *ReportSubscriptionHandler) Send(c *gin.Context) {
+	id := c.Param("id")
+
+	delivery, err := h.subscriptionService.SendNow(id, c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	response.SuccessResponse(c, delivery, nil)
+}
+
+// ListDeliveries handles list report subscription deliveries request
+func (h *ReportSubscriptionHandler) ListDeliveries(c *gin.Context) {
+	id := c.Param("id")
+	var req report_subscription.ListReportDeliveriesRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	deliveries, pagination, err := h.subscriptionService.ListDeliveries(id, &req)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	meta := &response.Meta{
+		Pagination: &response.PaginationMeta{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+			HasNext:    pagination.Page < pagination.TotalPages,
+			HasPrev:    pagination.Page > 1,
+		},
+		Filters: map[string]interface{}{},
+	}
+
+	if req.Status != "" {
+		meta.Filters["status"] = req.Status
+	}
+
+	response.SuccessResponse(c, deliveries, meta)
+}
+
+// handleError maps report subscription service errors to API errors
+func (h *ReportSubscriptionHandler) handleError(c *gin.Context, err error, id string) {
+	switch {
+	case goerrors.Is(err, reportsubscriptionservice.ErrReportSubscriptionNotFound):
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource":    "report_subscription",
+			"resource_id": id,
+		}, nil)
+	case goerrors.Is(err, reportsubscriptionservice.ErrInvalidSchedule):
+		errors.ErrorResponse(c, "INVALID_SCHEDULE", map[string]interface{}{
+			"field": "schedule",
+			"error": err.Error(),
+		}, nil)
+	case goerrors.Is(err, reportsubscriptionservice.ErrAccountRequired):
+		errors.ErrorResponse(c, "ACCOUNT_REQUIRED", map[string]interface{}{
+			"field": "account_id",
+		}, nil)
+	case goerrors.Is(err, reportsubscriptionservice.ErrAccountNotFound):
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource": "account",
+			"field":    "account_id",
+		}, nil)
+	case goerrors.Is(err, reportsubscriptionservice.ErrUserNotFound):
+		errors.ErrorResponse(c, "USER_NOT_FOUND", map[string]interface{}{
+			"field": "sales_rep_id",
+		}, nil)
+	default:
+		errors.InternalServerErrorResponse(c, "")
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/sales_target"
+	salestargetservice "github.com/gilabs/crm-healthcare/api/internal/service/sales_target"
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type SalesTargetHandler struct {
+	salesTargetService *salestargetservice.Service
+}
+
+func NewSalesTargetHandler(salesTargetService *salestargetservice.Service) *SalesTargetHandler {
+	return &SalesTargetHandler{
+		salesTargetService: salesTargetService,
+	}
+}
+
+// List handles list sales targets request
+func (h *SalesTargetHandler) List(c *gin.Context) {
+	var req sales_target.ListSalesTargetsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	targets, pagination, err := h.salesTargetService.List(&req)
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	meta := &response.Meta{
+		Pagination: &response.PaginationMeta{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+			HasNext:    pagination.Page < pagination.TotalPages,
+			HasPrev:    pagination.Page > 1,
+		},
+		Filters: map[string]interface{}{},
+	}
+
+	if req.Level != "" {
+		meta.Filters["level"] = req.Level
+	}
+	if req.UserID != "" {
+		meta.Filters["user_id"] = req.UserID
+	}
+	if req.TeamID != "" {
+		meta.Filters["team_id"] = req.TeamID
+	}
+	if req.PeriodType != "" {
+		meta.Filters["period_type"] = req.PeriodType
+	}
+	if req.Year != 0 {
+		meta.Filters["year"] = req.Year
+	}
+
+	response.SuccessResponse(c, targets, meta)
+}
+
+// GetByID handles get sales target by ID request
+func (h *SalesTargetHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	target, err := h.salesTargetService.GetByID(id)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	response.SuccessResponse(c, target, nil)
+}
+
+// Create handles create sales target request
+func (h *SalesTargetHandler) Create(c *gin.Context) {
+	var req sales_target.CreateSalesTargetRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	userID := c.GetString("user_id")
+	createdTarget, err := h.salesTargetService.Create(&req, userID)
+	if err != nil {
+		h.handleError(c, err, "")
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID != "" {
+		meta.CreatedBy = userID
+	}
+
+	response.SuccessResponseCreated(c, createdTarget, meta)
+}
+
+// Update handles update sales target request
+func (h *SalesTargetHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	var req sales_target.UpdateSalesTargetRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	updatedTarget, err := h.salesTargetService.Update(id, &req)
+	if err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID := c.GetString("user_id"); userID != "" {
+		meta.UpdatedBy = userID
+	}
+
+	response.SuccessResponse(c, updatedTarget, meta)
+}
+
+// Delete handles delete sales target request
+func (h *SalesTargetHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.salesTargetService.Delete(id); err != nil {
+		h.handleError(c, err, id)
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID := c.GetString("user_id"); userID != "" {
+		meta.DeletedBy = userID
+	}
+
+	response.SuccessResponseDeleted(c, "sales_target", id, meta)
+}
+
+// handleError maps sales target service errors to API errors
+func (h *SalesTargetHandler) handleError(c *gin.Context, err error, id string) {
+	switch err {
+	case salestargetservice.ErrSalesTargetNotFound:
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource":    "sales_target",
+			"resource_id": id,
+		}, nil)
+	case salestargetservice.ErrSalesTargetAlreadyExists:
+		errors.ErrorResponse(c, "CONFLICT", map[string]interface{}{
+			"resource": "sales_target",
+			"reason":   err.Error(),
+		}, nil)
+	case salestargetservice.ErrInvalidPeriod:
+		errors.ErrorResponse(c, "INVALID_TARGET_PERIOD", nil, nil)
+	case salestargetservice.ErrUserNotFound:
+		errors.ErrorResponse(c, "USER_NOT_FOUND", map[string]interface{}{
+			"field": "user_id",
+		}, nil)
+	case salestargetservice.ErrTeamNotFound:
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource": "team",
+			"field":    "team_id",
+		}, nil)
+	default:
+		errors.InternalServerErrorResponse(c, "")
+	}
+}
@@ -1,22 +1,28 @@
 package handlers
 
 import (
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/audit_log"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/reminder"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/task"
+	auditlogservice "github.com/gilabs/crm-healthcare/api/internal/service/audit_log"
 	taskservice "github.com/gilabs/crm-healthcare/api/internal/service/task"
 	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"github.com/gilabs/crm-healthcare/api/pkg/response"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
 type TaskHandler struct {
-	taskService *taskservice.Service
+	taskService     *taskservice.Service
+	auditLogService *auditlogservice.Service
 }
 
-func NewTaskHandler(taskService *taskservice.Service) *TaskHandler {
+func NewTaskHandler(taskService *taskservice.Service, auditLogService *auditlogservice.Service) *TaskHandler {
 	return &TaskHandler{
-		taskService: taskService,
+		taskService:     taskService,
+		auditLogService: auditLogService,
 	}
 }
 
@@ -33,7 +39,7 @@ func (h *TaskHandler) List(c *gin.Context) {
 		return
 	}
 
-	tasks, pagination, err := h.taskService.ListTasks(&req)
+	tasks, pagination, err := h.taskService.WithScope(datascope.FromContext(c)).ListTasks(&req)
 	if err != nil {
 		errors.InternalServerErrorResponse(c, "")
 		return
@@ -85,11 +91,30 @@ func (h *TaskHandler) List(c *gin.Context) {
 	response.SuccessResponse(c, tasks, meta)
 }
 
+// Export handles streaming every task that matches the list filters as CSV, XLSX or NDJSON
+func (h *TaskHandler) Export(c *gin.Context) {
+	var req task.ListTasksRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidQueryParamResponse(c)
+		return
+	}
+
+	scoped := h.taskService.WithScope(datascope.FromContext(c))
+	streamExport(c, "tasks", task.ExportColumns, func(w export.Writer) error {
+		return scoped.ExportTasks(&req, w)
+	})
+}
+
 // GetByID handles get task by ID request
 func (h *TaskHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
 
-	t, err := h.taskService.GetTaskByID(id)
+	t, err := h.taskService.WithScope(datascope.FromContext(c)).GetTaskByID(id)
 	if err != nil {
 		if err == taskservice.ErrTaskNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -159,6 +184,8 @@ func (h *TaskHandler) Create(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionCreate, "task", createdTask.ID, nil, createdTask)
+
 	meta := &response.Meta{}
 	if userID != "" {
 		meta.CreatedBy = userID
@@ -167,6 +194,61 @@ func (h *TaskHandler) Create(c *gin.Context) {
 	response.SuccessResponseCreated(c, createdTask, meta)
 }
 
+// CreateFromActionItems handles turning AI action items of a visit report into tasks
+func (h *TaskHandler) CreateFromActionItems(c *gin.Context) {
+	visitReportID := c.Param("visit_report_id")
+	var req task.CreateTasksFromActionItemsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	userID := c.GetString("user_id")
+
+	createdTasks, err := h.taskService.WithScope(datascope.FromContext(c)).CreateTasksFromActionItems(visitReportID, &req, userID)
+	if err != nil {
+		if err == taskservice.ErrVisitReportNotFound {
+			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+				"resource":    "visit_report",
+				"resource_id": visitReportID,
+			}, nil)
+			return
+		}
+		if err == taskservice.ErrInvalidActionItem {
+			errors.ErrorResponse(c, "INVALID_ACTION_ITEM", map[string]interface{}{
+				"visit_report_id": visitReportID,
+			}, nil)
+			return
+		}
+		if err == taskservice.ErrActionItemAlreadyConverted {
+			errors.ErrorResponse(c, "ACTION_ITEM_ALREADY_CONVERTED", nil, nil)
+			return
+		}
+		if err == taskservice.ErrUserNotFound {
+			errors.ErrorResponse(c, "USER_NOT_FOUND", nil, nil)
+			return
+		}
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	for i := range createdTasks {
+		recordAudit(c, h.auditLogService, audit_log.ActionCreate, "task", createdTasks[i].ID, nil, &createdTasks[i])
+	}
+
+	meta := &response.Meta{}
+	if userID != "" {
+		meta.CreatedBy = userID
+	}
+
+	response.SuccessResponseCreated(c, createdTasks, meta)
+}
+
 // Update handles update task request
 func (h *TaskHandler) Update(c *gin.Context) {
 	id := c.Param("id")
@@ -181,7 +263,9 @@ func (h *TaskHandler) Update(c *gin.Context) {
 		return
 	}
 
-	updatedTask, err := h.taskService.UpdateTask(id, &req)
+	scopedService := h.taskService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetTaskByID(id)
+	updatedTask, err := scopedService.UpdateTask(id, &req)
 	if err != nil {
 		if err == taskservice.ErrTaskNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -221,6 +305,8 @@ func (h *TaskHandler) Update(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionUpdate, "task", id, before, updatedTask)
+
 	meta := &response.Meta{}
 	if userID, exists := c.Get("user_id"); exists {
 		if id, ok := userID.(string); ok {
@@ -235,7 +321,9 @@ func (h *TaskHandler) Update(c *gin.Context) {
 func (h *TaskHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	err := h.taskService.DeleteTask(id)
+	scopedService := h.taskService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetTaskByID(id)
+	err := scopedService.DeleteTask(id)
 	if err != nil {
 		if err == taskservice.ErrTaskNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -248,6 +336,8 @@ func (h *TaskHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionDelete, "task", id, before, nil)
+
 	// Get user ID for meta
 	meta := &response.Meta{}
 	if userIDVal, exists := c.Get("user_id"); exists {
@@ -281,7 +371,9 @@ func (h *TaskHandler) Assign(c *gin.Context) {
 		}
 	}
 
-	assignedTask, err := h.taskService.AssignTask(id, &req, userID)
+	scopedService := h.taskService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetTaskByID(id)
+	assignedTask, err := scopedService.AssignTask(id, &req, userID)
 	if err != nil {
 		if err == taskservice.ErrTaskNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -300,6 +392,8 @@ func (h *TaskHandler) Assign(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionAssign, "task", id, before, assignedTask)
+
 	meta := &response.Meta{}
 	if userID != "" {
 		meta.UpdatedBy = userID
@@ -312,7 +406,9 @@ func (h *TaskHandler) Assign(c *gin.Context) {
 func (h *TaskHandler) Complete(c *gin.Context) {
 	id := c.Param("id")
 
-	completedTask, err := h.taskService.CompleteTask(id)
+	scopedService := h.taskService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetTaskByID(id)
+	completedTask, err := scopedService.CompleteTask(id)
 	if err != nil {
 		if err == taskservice.ErrTaskNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -331,6 +427,8 @@ func (h *TaskHandler) Complete(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionComplete, "task", id, before, completedTask)
+
 	meta := &response.Meta{}
 	if userID, exists := c.Get("user_id"); exists {
 		if id, ok := userID.(string); ok {
@@ -516,7 +614,9 @@ func (h *TaskHandler) DeleteReminder(c *gin.Context) {
 func (h *TaskHandler) MarkInProgress(c *gin.Context) {
 	id := c.Param("id")
 
-	updatedTask, err := h.taskService.MarkInProgress(id)
+	scopedService := h.taskService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetTaskByID(id)
+	updatedTask, err := scopedService.MarkInProgress(id)
 	if err != nil {
 		if err == taskservice.ErrTaskNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -535,6 +635,8 @@ func (h *TaskHandler) MarkInProgress(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionStart, "task", id, before, updatedTask)
+
 	meta := &response.Meta{}
 	if userID, exists := c.Get("user_id"); exists {
 		if id, ok := userID.(string); ok {
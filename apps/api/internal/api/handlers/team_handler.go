@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/team"
+	teamservice "github.com/gilabs/crm-healthcare/api/internal/service/team"
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type TeamHandler struct {
+	teamService *teamservice.Service
+}
+
+func NewTeamHandler(teamService *teamservice.Service) *TeamHandler {
+	return &TeamHandler{
+		teamService: teamService,
+	}
+}
+
+// List handles list teams request
+func (h *TeamHandler) List(c *gin.Context) {
+	teams, err := h.teamService.List()
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	response.SuccessResponse(c, teams, nil)
+}
+
+// GetByID handles get team by ID request
+func (h *TeamHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	t, err := h.teamService.GetByID(id)
+	if err != nil {
+		if err == teamservice.ErrTeamNotFound {
+			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+				"resource": "team",
+				"team_id":  id,
+			}, nil)
+			return
+		}
+		errors.InternalServerErrorResponse(c, "")
+		return
+	}
+
+	response.SuccessResponse(c, t, nil)
+}
+
+// Create handles create team request
+func (h *TeamHandler) Create(c *gin.Context) {
+	var req team.CreateTeamRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	createdTeam, err := h.teamService.Create(&req)
+	if err != nil {
+		h.handleWriteError(c, err, "")
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID, exists := c.Get("user_id"); exists {
+		if id, ok := userID.(string); ok {
+			meta.CreatedBy = id
+		}
+	}
+
+	response.SuccessResponseCreated(c, createdTeam, meta)
+}
+
+// Update handles update team request
+func (h *TeamHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	var req team.UpdateTeamRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errors.HandleValidationError(c, validationErrors)
+			return
+		}
+		errors.InvalidRequestBodyResponse(c)
+		return
+	}
+
+	updatedTeam, err := h.teamService.Update(id, &req)
+	if err != nil {
+		h.handleWriteError(c, err, id)
+		return
+	}
+
+	meta := &response.Meta{}
+	if userID, exists := c.Get("user_id"); exists {
+		if id, ok := userID.(string); ok {
+			meta.UpdatedBy = id
+		}
+	}
+
+	response.SuccessResponse(c, updatedTeam, meta)
+}
+
+// Delete handles delete team request
+func (h *TeamHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.teamService.Delete(id); err != nil {
+		h.handleWriteError(c, err, id)
+		return
+	}
+
+	meta := &response.Meta{}
+	if userIDVal, exists := c.Get("user_id"); exists {
+		if id, ok := userIDVal.(string); ok {
+			meta.DeletedBy = id
+		}
+	}
+
+	response.SuccessResponseDeleted(c, "team", id, meta)
+}
+
+// handleWriteError maps team service errors to API errors
+func (h *TeamHandler) handleWriteError(c *gin.Context, err error, id string) {
+	switch err {
+	case teamservice.ErrTeamNotFound:
+		errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+			"resource": "team",
+			"team_id":  id,
+		}, nil)
+	case teamservice.ErrTeamAlreadyExists:
+		errors.ErrorResponse(c, "CONFLICT", map[string]interface{}{
+			"resource": "team",
+			"field":    "name",
+		}, nil)
+	case teamservice.ErrManagerNotFound:
+		errors.ErrorResponse(c, "USER_NOT_FOUND", map[string]interface{}{
+			"field": "manager_id",
+		}, nil)
+	default:
+		errors.InternalServerErrorResponse(c, "")
+	}
+}
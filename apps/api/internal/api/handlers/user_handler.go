@@ -114,6 +114,12 @@ func (h *UserHandler) Create(c *gin.Context) {
 			}, nil)
 			return
 		}
+		if err == userservice.ErrTeamNotFound {
+			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+				"resource": "team",
+			}, nil)
+			return
+		}
 		errors.InternalServerErrorResponse(c, "")
 		return
 	}
@@ -163,6 +169,12 @@ func (h *UserHandler) Update(c *gin.Context) {
 			}, nil)
 			return
 		}
+		if err == userservice.ErrTeamNotFound {
+			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
+				"resource": "team",
+			}, nil)
+			return
+		}
 		errors.InternalServerErrorResponse(c, "")
 		return
 	}
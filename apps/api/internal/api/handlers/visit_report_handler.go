@@ -3,7 +3,10 @@ package handlers
 import (
 	"strings"
 
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/audit_log"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/visit_report"
+	auditlogservice "github.com/gilabs/crm-healthcare/api/internal/service/audit_log"
 	fileservice "github.com/gilabs/crm-healthcare/api/internal/service/file"
 	visitreportservice "github.com/gilabs/crm-healthcare/api/internal/service/visit_report"
 	"github.com/gilabs/crm-healthcare/api/pkg/errors"
@@ -15,12 +18,14 @@ import (
 type VisitReportHandler struct {
 	visitReportService *visitreportservice.Service
 	fileService        *fileservice.Service
+	auditLogService    *auditlogservice.Service
 }
 
-func NewVisitReportHandler(visitReportService *visitreportservice.Service, fileService *fileservice.Service) *VisitReportHandler {
+func NewVisitReportHandler(visitReportService *visitreportservice.Service, fileService *fileservice.Service, auditLogService *auditlogservice.Service) *VisitReportHandler {
 	return &VisitReportHandler{
 		visitReportService: visitReportService,
 		fileService:        fileService,
+		auditLogService:    auditLogService,
 	}
 }
 
@@ -37,7 +42,7 @@ func (h *VisitReportHandler) List(c *gin.Context) {
 		return
 	}
 
-	visitReports, pagination, err := h.visitReportService.List(&req)
+	visitReports, pagination, err := h.visitReportService.WithScope(datascope.FromContext(c)).List(&req)
 	if err != nil {
 		errors.InternalServerErrorResponse(c, "")
 		return
@@ -75,7 +80,7 @@ func (h *VisitReportHandler) List(c *gin.Context) {
 func (h *VisitReportHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
 
-	visitReport, err := h.visitReportService.GetByID(id)
+	visitReport, err := h.visitReportService.WithScope(datascope.FromContext(c)).GetByID(id)
 	if err != nil {
 		if err == visitreportservice.ErrVisitReportNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -132,6 +137,8 @@ func (h *VisitReportHandler) Create(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionCreate, "visit_report", createdVisitReport.ID, nil, createdVisitReport)
+
 	meta := &response.Meta{}
 	if userID, exists := c.Get("user_id"); exists {
 		if id, ok := userID.(string); ok {
@@ -156,7 +163,9 @@ func (h *VisitReportHandler) Update(c *gin.Context) {
 		return
 	}
 
-	updatedVisitReport, err := h.visitReportService.Update(id, &req)
+	scopedService := h.visitReportService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetByID(id)
+	updatedVisitReport, err := scopedService.Update(id, &req)
 	if err != nil {
 		if err == visitreportservice.ErrVisitReportNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -175,6 +184,8 @@ func (h *VisitReportHandler) Update(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionUpdate, "visit_report", id, before, updatedVisitReport)
+
 	meta := &response.Meta{}
 	if userID, exists := c.Get("user_id"); exists {
 		if id, ok := userID.(string); ok {
@@ -189,7 +200,9 @@ func (h *VisitReportHandler) Update(c *gin.Context) {
 func (h *VisitReportHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	err := h.visitReportService.Delete(id)
+	scopedService := h.visitReportService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetByID(id)
+	err := scopedService.Delete(id)
 	if err != nil {
 		if err == visitreportservice.ErrVisitReportNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -202,6 +215,8 @@ func (h *VisitReportHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionDelete, "visit_report", id, before, nil)
+
 	// Get user ID for meta
 	meta := &response.Meta{}
 	if userIDVal, exists := c.Get("user_id"); exists {
@@ -240,7 +255,9 @@ func (h *VisitReportHandler) CheckIn(c *gin.Context) {
 		return
 	}
 
-	visitReport, err := h.visitReportService.CheckIn(id, &req, userIDStr)
+	scopedService := h.visitReportService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetByID(id)
+	visitReport, err := scopedService.CheckIn(id, &req, userIDStr)
 	if err != nil {
 		if err == visitreportservice.ErrVisitReportNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -255,6 +272,8 @@ func (h *VisitReportHandler) CheckIn(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionCheckIn, "visit_report", id, before, visitReport)
+
 	response.SuccessResponse(c, visitReport, nil)
 }
 
@@ -285,7 +304,9 @@ func (h *VisitReportHandler) CheckOut(c *gin.Context) {
 		return
 	}
 
-	visitReport, err := h.visitReportService.CheckOut(id, &req, userIDStr)
+	scopedService := h.visitReportService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetByID(id)
+	visitReport, err := scopedService.CheckOut(id, &req, userIDStr)
 	if err != nil {
 		if err == visitreportservice.ErrVisitReportNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -300,6 +321,8 @@ func (h *VisitReportHandler) CheckOut(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionCheckOut, "visit_report", id, before, visitReport)
+
 	response.SuccessResponse(c, visitReport, nil)
 }
 
@@ -320,7 +343,9 @@ func (h *VisitReportHandler) Approve(c *gin.Context) {
 		return
 	}
 
-	visitReport, err := h.visitReportService.Approve(id, userIDStr)
+	scopedService := h.visitReportService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetByID(id)
+	visitReport, err := scopedService.Approve(id, userIDStr)
 	if err != nil {
 		if err == visitreportservice.ErrVisitReportNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -339,6 +364,8 @@ func (h *VisitReportHandler) Approve(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionApprove, "visit_report", id, before, visitReport)
+
 	response.SuccessResponse(c, visitReport, nil)
 }
 
@@ -369,7 +396,9 @@ func (h *VisitReportHandler) Reject(c *gin.Context) {
 		return
 	}
 
-	visitReport, err := h.visitReportService.Reject(id, &req, userIDStr)
+	scopedService := h.visitReportService.WithScope(datascope.FromContext(c))
+	before, _ := scopedService.GetByID(id)
+	visitReport, err := scopedService.Reject(id, &req, userIDStr)
 	if err != nil {
 		if err == visitreportservice.ErrVisitReportNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
@@ -388,6 +417,8 @@ func (h *VisitReportHandler) Reject(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditLogService, audit_log.ActionReject, "visit_report", id, before, visitReport)
+
 	response.SuccessResponse(c, visitReport, nil)
 }
 
@@ -445,7 +476,7 @@ func (h *VisitReportHandler) UploadPhoto(c *gin.Context) {
 		PhotoURL: photoURL,
 	}
 
-	visitReport, err := h.visitReportService.UploadPhoto(id, &req)
+	visitReport, err := h.visitReportService.WithScope(datascope.FromContext(c)).UploadPhoto(id, &req)
 	if err != nil {
 		if err == visitreportservice.ErrVisitReportNotFound {
 			errors.ErrorResponse(c, "NOT_FOUND", map[string]interface{}{
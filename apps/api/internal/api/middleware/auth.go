@@ -49,3 +49,44 @@ func AuthMiddleware(jwtManager *jwt.JWTManager) gin.HandlerFunc {
 	}
 }
 
+
+// WebSocketAuthMiddleware validates JWT token for WebSocket upgrade requests and sets user info in context.
+// Browsers cannot set headers on WebSocket connections, so the token is read from the "token" cookie
+// or query parameter before falling back to the Authorization header.
+func WebSocketAuthMiddleware(jwtManager *jwt.JWTManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, _ := c.Cookie("token")
+		if tokenString == "" {
+			tokenString = c.Query("token")
+		}
+		if tokenString == "" {
+			parts := strings.Split(c.GetHeader("Authorization"), " ")
+			if len(parts) == 2 && parts[0] == "Bearer" {
+				tokenString = parts[1]
+			}
+		}
+
+		if tokenString == "" {
+			errors.UnauthorizedResponse(c, "token missing")
+			c.Abort()
+			return
+		}
+
+		claims, err := jwtManager.ValidateToken(tokenString)
+		if err != nil {
+			if err == jwt.ErrExpiredToken {
+				errors.ErrorResponse(c, "TOKEN_EXPIRED", nil, nil)
+			} else {
+				errors.ErrorResponse(c, "TOKEN_INVALID", nil, nil)
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", claims.UserID)
+		c.Set("user_email", claims.Email)
+		c.Set("user_role", claims.Role)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
+	"github.com/gilabs/crm-healthcare/api/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionChecker resolves the permission codes and record data scope granted to a caller
+type PermissionChecker interface {
+	GetRolePermissionCodes(roleCode string) ([]string, error)
+	GetDataScope(userID, roleCode string) (*datascope.Scope, error)
+}
+
+// RequirePermission allows the request when the caller's role has at least one of the given permission codes.
+// Must be used after AuthMiddleware, which sets user_role in context.
+func RequirePermission(checker PermissionChecker, codes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !checkPermission(c, checker, codes) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePermissionOrSelf behaves like RequirePermission but also allows users acting on their own record,
+// identified by the given route parameter (e.g. "id" for /users/:id/profile)
+func RequirePermissionOrSelf(checker PermissionChecker, param string, codes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userID, exists := c.Get("user_id"); exists {
+			if id, ok := userID.(string); ok && id != "" && id == c.Param(param) {
+				c.Next()
+				return
+			}
+		}
+
+		if !checkPermission(c, checker, codes) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// DataScopeMiddleware resolves the caller's record data scope (own, team, all) and stores it in context
+// so handlers can restrict repository queries to the records the caller may access
+func DataScopeMiddleware(checker PermissionChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		roleCode := c.GetString("user_role")
+		if userID == "" || roleCode == "" {
+			errors.UnauthorizedResponse(c, "user missing")
+			c.Abort()
+			return
+		}
+
+		scope, err := checker.GetDataScope(userID, roleCode)
+		if err != nil {
+			errors.InternalServerErrorResponse(c, "")
+			c.Abort()
+			return
+		}
+
+		c.Set(datascope.ContextKey, scope)
+		c.Next()
+	}
+}
+
+// checkPermission writes an error response and returns false when the caller lacks all of the codes
+func checkPermission(c *gin.Context, checker PermissionChecker, codes []string) bool {
+	roleCode := c.GetString("user_role")
+	if roleCode == "" {
+		errors.UnauthorizedResponse(c, "role missing")
+		return false
+	}
+
+	userPermissions, err := checker.GetRolePermissionCodes(roleCode)
+	if err != nil {
+		errors.InternalServerErrorResponse(c, "")
+		return false
+	}
+
+	for _, granted := range userPermissions {
+		for _, required := range codes {
+			if granted == required {
+				// Expose granted codes to handlers that refine access further
+				c.Set("user_permissions", userPermissions)
+				return true
+			}
+		}
+	}
+
+	errors.ForbiddenResponse(c, strings.Join(codes, "|"), userPermissions)
+	return false
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/role"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	permissionservice "github.com/gilabs/crm-healthcare/api/internal/service/permission"
+	"github.com/gin-gonic/gin"
+)
+
+type fakePermissionRepo struct {
+	interfaces.PermissionRepository
+	codes map[string][]string
+	loads int
+}
+
+func (r *fakePermissionRepo) GetCodesByRoleCode(roleCode string) ([]string, error) {
+	r.loads++
+	return r.codes[roleCode], nil
+}
+
+type fakeRoleRepo struct {
+	interfaces.RoleRepository
+}
+
+func (r *fakeRoleRepo) FindByCode(code string) (*role.Role, error) {
+	return &role.Role{Code: code, DataScope: "own"}, nil
+}
+
+// serve runs a request of a caller with the given role through RequirePermission and returns the status code
+func serve(checker PermissionChecker, roleCode string, codes ...string) int {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/deals", func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		c.Set("user_role", roleCode)
+		c.Next()
+	}, RequirePermission(checker, codes...), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/deals", nil))
+	return w.Code
+}
+
+func TestRequirePermission(t *testing.T) {
+	repo := &fakePermissionRepo{codes: map[string][]string{
+		"sales":  {"VIEW_PIPELINE", "VIEW_LEADS"},
+		"viewer": {"VIEW_DASHBOARD"},
+	}}
+	checker := permissionservice.NewService(repo, nil, &fakeRoleRepo{})
+
+	tests := []struct {
+		name  string
+		role  string
+		codes []string
+		want  int
+	}{
+		{"granted code", "sales", []string{"VIEW_PIPELINE"}, http.StatusOK},
+		{"one of several codes", "sales", []string{"VIEW_ACCOUNTS", "VIEW_LEADS"}, http.StatusOK},
+		{"missing code", "viewer", []string{"VIEW_PIPELINE"}, http.StatusForbidden},
+		{"no role", "", []string{"VIEW_PIPELINE"}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if got := serve(checker, tt.role, tt.codes...); got != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRequirePermission_CacheInvalidation(t *testing.T) {
+	repo := &fakePermissionRepo{codes: map[string][]string{"sales": {"VIEW_PIPELINE"}}}
+	checker := permissionservice.NewService(repo, nil, &fakeRoleRepo{})
+
+	if got := serve(checker, "sales", "VIEW_PIPELINE"); got != http.StatusOK {
+		t.Fatalf("expected 200, got %d", got)
+	}
+
+	// A revoked code stays granted until the cache is invalidated
+	repo.codes["sales"] = nil
+	if got := serve(checker, "sales", "VIEW_PIPELINE"); got != http.StatusOK {
+		t.Errorf("expected the cached grant to be used, got %d", got)
+	}
+	if repo.loads != 1 {
+		t.Errorf("expected the codes to be loaded once, got %d", repo.loads)
+	}
+
+	checker.InvalidateRolePermissions()
+	if got := serve(checker, "sales", "VIEW_PIPELINE"); got != http.StatusForbidden {
+		t.Errorf("expected 403 after invalidation, got %d", got)
+	}
+	if repo.loads != 2 {
+		t.Errorf("expected the codes to be reloaded, got %d loads", repo.loads)
+	}
+}
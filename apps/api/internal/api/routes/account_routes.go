@@ -7,15 +7,17 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupAccountRoutes(router *gin.RouterGroup, accountHandler *handlers.AccountHandler, jwtManager *jwt.JWTManager) {
+func SetupAccountRoutes(router *gin.RouterGroup, accountHandler *handlers.AccountHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
 	accounts := router.Group("/accounts")
 	accounts.Use(middleware.AuthMiddleware(jwtManager))
+	accounts.Use(middleware.DataScopeMiddleware(permissionChecker))
 	{
-		accounts.GET("", accountHandler.List)
-		accounts.GET("/:id", accountHandler.GetByID)
-		accounts.POST("", accountHandler.Create)
-		accounts.PUT("/:id", accountHandler.Update)
-		accounts.DELETE("/:id", accountHandler.Delete)
+		accounts.GET("", middleware.RequirePermission(permissionChecker, "VIEW_ACCOUNTS"), accountHandler.List)
+		accounts.GET("/export", middleware.RequirePermission(permissionChecker, "VIEW_ACCOUNTS"), accountHandler.Export)
+		accounts.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_ACCOUNTS", "DETAIL_ACCOUNTS"), accountHandler.GetByID)
+		accounts.POST("", middleware.RequirePermission(permissionChecker, "CREATE_ACCOUNTS"), accountHandler.Create)
+		accounts.PUT("/:id", middleware.RequirePermission(permissionChecker, "EDIT_ACCOUNTS"), accountHandler.Update)
+		accounts.DELETE("/:id", middleware.RequirePermission(permissionChecker, "DELETE_ACCOUNTS"), accountHandler.Delete)
 	}
 }
 
@@ -7,14 +7,15 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupActivityRoutes(router *gin.RouterGroup, activityHandler *handlers.ActivityHandler, jwtManager *jwt.JWTManager) {
+func SetupActivityRoutes(router *gin.RouterGroup, activityHandler *handlers.ActivityHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
 	activities := router.Group("/activities")
 	activities.Use(middleware.AuthMiddleware(jwtManager))
 	{
-		activities.GET("", activityHandler.List)
-		activities.GET("/:id", activityHandler.GetByID)
-		activities.POST("", activityHandler.Create)
-		activities.GET("/timeline", activityHandler.GetTimeline)
+		activities.GET("", middleware.RequirePermission(permissionChecker, "VIEW_ACCOUNTS", "VIEW_LEADS", "VIEW_PIPELINE", "VIEW_VISIT_REPORTS"), activityHandler.List)
+		activities.GET("/export", middleware.RequirePermission(permissionChecker, "VIEW_ACCOUNTS", "VIEW_LEADS", "VIEW_PIPELINE", "VIEW_VISIT_REPORTS"), activityHandler.Export)
+		activities.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_ACCOUNTS", "VIEW_LEADS", "VIEW_PIPELINE", "VIEW_VISIT_REPORTS"), activityHandler.GetByID)
+		activities.POST("", middleware.RequirePermission(permissionChecker, "CREATE_VISIT_REPORTS", "EDIT_ACCOUNTS", "EDIT_LEADS", "EDIT_DEALS"), activityHandler.Create)
+		activities.GET("/timeline", middleware.RequirePermission(permissionChecker, "VIEW_ACCOUNTS", "VIEW_LEADS", "VIEW_PIPELINE", "VIEW_VISIT_REPORTS"), activityHandler.GetTimeline)
 	}
 }
 
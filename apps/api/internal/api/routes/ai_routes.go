@@ -7,20 +7,38 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupAIRoutes(v1 *gin.RouterGroup, aiHandler *handlers.AIHandler, aiSettingsHandler *handlers.AISettingsHandler, jwtManager *jwt.JWTManager) {
+func SetupAIRoutes(v1 *gin.RouterGroup, aiHandler *handlers.AIHandler, aiSettingsHandler *handlers.AISettingsHandler, aiConversationHandler *handlers.AIConversationHandler, aiUsageHandler *handlers.AIUsageHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
 	ai := v1.Group("/ai")
 	ai.Use(middleware.AuthMiddleware(jwtManager))
 
 	{
 		// Visit Report Insights
-		ai.POST("/analyze/visit-report", aiHandler.AnalyzeVisitReport)
+		ai.POST("/analyze/visit-report", middleware.RequirePermission(permissionChecker, "VIEW_AI_CHATBOT", "VIEW_VISIT_REPORTS"), aiHandler.AnalyzeVisitReport)
+
+		// Deal, Account and Pipeline Insights (restricted to the caller's data scope)
+		ai.POST("/analyze/deal/:id", middleware.RequirePermission(permissionChecker, "VIEW_AI_CHATBOT", "VIEW_PIPELINE"), middleware.DataScopeMiddleware(permissionChecker), aiHandler.AnalyzeDeal)
+		ai.POST("/analyze/account/:id", middleware.RequirePermission(permissionChecker, "VIEW_AI_CHATBOT", "VIEW_ACCOUNTS"), middleware.DataScopeMiddleware(permissionChecker), aiHandler.AnalyzeAccount)
+		ai.POST("/analyze/pipeline", middleware.RequirePermission(permissionChecker, "VIEW_AI_CHATBOT", "VIEW_PIPELINE"), middleware.DataScopeMiddleware(permissionChecker), aiHandler.AnalyzePipeline)
 
 		// Chat
-		ai.POST("/chat", aiHandler.Chat)
+		ai.POST("/chat", middleware.RequirePermission(permissionChecker, "VIEW_AI_CHATBOT"), middleware.DataScopeMiddleware(permissionChecker), aiHandler.Chat)
+		ai.POST("/chat/stream", middleware.RequirePermission(permissionChecker, "VIEW_AI_CHATBOT"), middleware.DataScopeMiddleware(permissionChecker), aiHandler.ChatStream)
+
+		// Conversations (always limited to the caller's own conversations)
+		ai.GET("/conversations", middleware.RequirePermission(permissionChecker, "VIEW_AI_CHATBOT"), aiConversationHandler.List)
+		ai.GET("/conversations/:id", middleware.RequirePermission(permissionChecker, "VIEW_AI_CHATBOT"), aiConversationHandler.GetByID)
+		ai.PUT("/conversations/:id", middleware.RequirePermission(permissionChecker, "VIEW_AI_CHATBOT"), aiConversationHandler.Update)
+		ai.DELETE("/conversations/:id", middleware.RequirePermission(permissionChecker, "VIEW_AI_CHATBOT"), aiConversationHandler.Delete)
 
 		// Settings
-		ai.GET("/settings", aiSettingsHandler.GetSettings)
-		ai.PUT("/settings", aiSettingsHandler.UpdateSettings)
+		ai.GET("/settings", middleware.RequirePermission(permissionChecker, "VIEW_AI_SETTINGS"), aiSettingsHandler.GetSettings)
+		ai.PUT("/settings", middleware.RequirePermission(permissionChecker, "EDIT_AI_SETTINGS"), aiSettingsHandler.UpdateSettings)
+
+		// Token usage report (per user, model and feature)
+		ai.GET("/usage", middleware.RequirePermission(permissionChecker, "VIEW_AI_SETTINGS"), aiUsageHandler.GetReport)
 	}
+
+	// Streaming chat over WebSocket (token from cookie or query, since browsers cannot set headers)
+	v1.GET("/ws/ai/chat", middleware.WebSocketAuthMiddleware(jwtManager), middleware.RequirePermission(permissionChecker, "VIEW_AI_CHATBOT"), middleware.DataScopeMiddleware(permissionChecker), aiHandler.ChatWebSocket)
 }
 
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/api/handlers"
+	"github.com/gilabs/crm-healthcare/api/internal/api/middleware"
+	"github.com/gilabs/crm-healthcare/api/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAuditLogRoutes sets up audit log routes
+func SetupAuditLogRoutes(router *gin.RouterGroup, auditLogHandler *handlers.AuditLogHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
+	auditLogs := router.Group("/audit-logs")
+	auditLogs.Use(middleware.AuthMiddleware(jwtManager))
+	auditLogs.Use(middleware.RequirePermission(permissionChecker, "VIEW_AUDIT_LOGS"))
+	{
+		auditLogs.GET("", auditLogHandler.List)
+		auditLogs.GET("/:resource_type/:resource_id", auditLogHandler.GetResourceHistory)
+	}
+}
@@ -7,15 +7,15 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupCategoryRoutes(router *gin.RouterGroup, categoryHandler *handlers.CategoryHandler, jwtManager *jwt.JWTManager) {
+func SetupCategoryRoutes(router *gin.RouterGroup, categoryHandler *handlers.CategoryHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
 	categories := router.Group("/categories")
 	categories.Use(middleware.AuthMiddleware(jwtManager))
 	{
-		categories.GET("", categoryHandler.List)
-		categories.GET("/:id", categoryHandler.GetByID)
-		categories.POST("", categoryHandler.Create)
-		categories.PUT("/:id", categoryHandler.Update)
-		categories.DELETE("/:id", categoryHandler.Delete)
+		categories.GET("", middleware.RequirePermission(permissionChecker, "VIEW_ACCOUNTS", "CATEGORY"), categoryHandler.List)
+		categories.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_ACCOUNTS", "CATEGORY"), categoryHandler.GetByID)
+		categories.POST("", middleware.RequirePermission(permissionChecker, "CATEGORY"), categoryHandler.Create)
+		categories.PUT("/:id", middleware.RequirePermission(permissionChecker, "CATEGORY"), categoryHandler.Update)
+		categories.DELETE("/:id", middleware.RequirePermission(permissionChecker, "CATEGORY"), categoryHandler.Delete)
 	}
 }
 
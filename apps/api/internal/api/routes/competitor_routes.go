@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/api/handlers"
+	"github.com/gilabs/crm-healthcare/api/internal/api/middleware"
+	"github.com/gilabs/crm-healthcare/api/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCompetitorRoutes sets up competitor routes; anyone who can view the pipeline can pick from the list
+func SetupCompetitorRoutes(router *gin.RouterGroup, competitorHandler *handlers.CompetitorHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
+	competitors := router.Group("/competitors")
+	competitors.Use(middleware.AuthMiddleware(jwtManager))
+	{
+		competitors.GET("", middleware.RequirePermission(permissionChecker, "VIEW_PIPELINE", "COMPETITORS"), competitorHandler.List)
+		competitors.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_PIPELINE", "COMPETITORS"), competitorHandler.GetByID)
+		competitors.POST("", middleware.RequirePermission(permissionChecker, "COMPETITORS"), competitorHandler.Create)
+		competitors.PUT("/:id", middleware.RequirePermission(permissionChecker, "COMPETITORS"), competitorHandler.Update)
+		competitors.DELETE("/:id", middleware.RequirePermission(permissionChecker, "COMPETITORS"), competitorHandler.Delete)
+	}
+}
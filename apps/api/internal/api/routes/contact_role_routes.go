@@ -7,15 +7,15 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupContactRoleRoutes(router *gin.RouterGroup, contactRoleHandler *handlers.ContactRoleHandler, jwtManager *jwt.JWTManager) {
+func SetupContactRoleRoutes(router *gin.RouterGroup, contactRoleHandler *handlers.ContactRoleHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
 	contactRoles := router.Group("/contact-roles")
 	contactRoles.Use(middleware.AuthMiddleware(jwtManager))
 	{
-		contactRoles.GET("", contactRoleHandler.List)
-		contactRoles.GET("/:id", contactRoleHandler.GetByID)
-		contactRoles.POST("", contactRoleHandler.Create)
-		contactRoles.PUT("/:id", contactRoleHandler.Update)
-		contactRoles.DELETE("/:id", contactRoleHandler.Delete)
+		contactRoles.GET("", middleware.RequirePermission(permissionChecker, "VIEW_ACCOUNTS", "ROLE"), contactRoleHandler.List)
+		contactRoles.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_ACCOUNTS", "ROLE"), contactRoleHandler.GetByID)
+		contactRoles.POST("", middleware.RequirePermission(permissionChecker, "ROLE"), contactRoleHandler.Create)
+		contactRoles.PUT("/:id", middleware.RequirePermission(permissionChecker, "ROLE"), contactRoleHandler.Update)
+		contactRoles.DELETE("/:id", middleware.RequirePermission(permissionChecker, "ROLE"), contactRoleHandler.Delete)
 	}
 }
 
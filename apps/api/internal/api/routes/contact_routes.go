@@ -7,15 +7,16 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupContactRoutes(router *gin.RouterGroup, contactHandler *handlers.ContactHandler, jwtManager *jwt.JWTManager) {
+func SetupContactRoutes(router *gin.RouterGroup, contactHandler *handlers.ContactHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
 	contacts := router.Group("/contacts")
 	contacts.Use(middleware.AuthMiddleware(jwtManager))
 	{
-		contacts.GET("", contactHandler.List)
-		contacts.GET("/:id", contactHandler.GetByID)
-		contacts.POST("", contactHandler.Create)
-		contacts.PUT("/:id", contactHandler.Update)
-		contacts.DELETE("/:id", contactHandler.Delete)
+		contacts.GET("", middleware.RequirePermission(permissionChecker, "VIEW_ACCOUNTS"), contactHandler.List)
+		contacts.GET("/export", middleware.RequirePermission(permissionChecker, "VIEW_ACCOUNTS"), contactHandler.Export)
+		contacts.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_ACCOUNTS", "DETAIL_ACCOUNTS"), contactHandler.GetByID)
+		contacts.POST("", middleware.RequirePermission(permissionChecker, "CREATE_ACCOUNTS"), contactHandler.Create)
+		contacts.PUT("/:id", middleware.RequirePermission(permissionChecker, "EDIT_ACCOUNTS"), contactHandler.Update)
+		contacts.DELETE("/:id", middleware.RequirePermission(permissionChecker, "DELETE_ACCOUNTS"), contactHandler.Delete)
 	}
 }
 
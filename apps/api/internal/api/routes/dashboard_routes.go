@@ -7,9 +7,10 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupDashboardRoutes(router *gin.RouterGroup, dashboardHandler *handlers.DashboardHandler, jwtManager *jwt.JWTManager) {
+func SetupDashboardRoutes(router *gin.RouterGroup, dashboardHandler *handlers.DashboardHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
 	dashboard := router.Group("/dashboard")
 	dashboard.Use(middleware.AuthMiddleware(jwtManager))
+	dashboard.Use(middleware.RequirePermission(permissionChecker, "VIEW_DASHBOARD"))
 	{
 		dashboard.GET("/overview", dashboardHandler.GetOverview)
 		dashboard.GET("/visits", dashboardHandler.GetVisitStatistics)
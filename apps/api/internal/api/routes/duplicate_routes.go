@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/api/handlers"
+	"github.com/gilabs/crm-healthcare/api/internal/api/middleware"
+	"github.com/gilabs/crm-healthcare/api/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupDuplicateRoutes sets up duplicate detection and merge routes
+func SetupDuplicateRoutes(router *gin.RouterGroup, duplicateHandler *handlers.DuplicateHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
+	duplicates := router.Group("/duplicates")
+	duplicates.Use(middleware.AuthMiddleware(jwtManager))
+	duplicates.Use(middleware.DataScopeMiddleware(permissionChecker))
+	{
+		duplicates.POST("/check", middleware.RequirePermission(permissionChecker, "CREATE_LEADS", "CREATE_ACCOUNTS", "CONVERT_LEADS"), duplicateHandler.Check)
+
+		duplicates.GET("/leads/:id", middleware.RequirePermission(permissionChecker, "VIEW_LEADS"), duplicateHandler.LeadDuplicates)
+		duplicates.POST("/leads/:id/merge", middleware.RequirePermission(permissionChecker, "MERGE_LEADS"), duplicateHandler.MergeLeads)
+
+		duplicates.GET("/accounts/:id", middleware.RequirePermission(permissionChecker, "VIEW_ACCOUNTS"), duplicateHandler.AccountDuplicates)
+		duplicates.POST("/accounts/:id/merge", middleware.RequirePermission(permissionChecker, "MERGE_ACCOUNTS"), duplicateHandler.MergeAccounts)
+
+		duplicates.GET("/contacts/:id", middleware.RequirePermission(permissionChecker, "VIEW_ACCOUNTS"), duplicateHandler.ContactDuplicates)
+		duplicates.POST("/contacts/:id/merge", middleware.RequirePermission(permissionChecker, "MERGE_ACCOUNTS"), duplicateHandler.MergeContacts)
+	}
+}
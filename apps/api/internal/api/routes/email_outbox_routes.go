@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/api/handlers"
+	"github.com/gilabs/crm-healthcare/api/internal/api/middleware"
+	"github.com/gilabs/crm-healthcare/api/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupEmailOutboxRoutes sets up email outbox routes
+func SetupEmailOutboxRoutes(router *gin.RouterGroup, emailOutboxHandler *handlers.EmailOutboxHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
+	outbox := router.Group("/email-outbox")
+	outbox.Use(middleware.AuthMiddleware(jwtManager))
+	outbox.Use(middleware.RequirePermission(permissionChecker, "MANAGE_EMAIL_OUTBOX"))
+	{
+		outbox.GET("", emailOutboxHandler.List)
+		outbox.GET("/:id", emailOutboxHandler.GetByID)
+		outbox.POST("/:id/retry", emailOutboxHandler.Retry)
+	}
+}
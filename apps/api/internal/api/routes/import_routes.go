@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/api/handlers"
+	"github.com/gilabs/crm-healthcare/api/internal/api/middleware"
+	"github.com/gilabs/crm-healthcare/api/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupImportRoutes sets up bulk import routes.
+// Any import permission opens the group; handlers check the permission of the job's entity type.
+func SetupImportRoutes(router *gin.RouterGroup, importHandler *handlers.ImportHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
+	imports := router.Group("/imports")
+	imports.Use(middleware.AuthMiddleware(jwtManager))
+	imports.Use(middleware.RequirePermission(permissionChecker, "IMPORT_ACCOUNTS", "IMPORT_LEADS", "IMPORT_PRODUCTS"))
+	{
+		imports.GET("/fields/:entity_type", importHandler.Fields)
+		imports.GET("", importHandler.List)
+		imports.GET("/:id", importHandler.GetByID)
+		imports.POST("", importHandler.Create)
+		imports.POST("/:id/validate", importHandler.Validate)
+		imports.POST("/:id/commit", importHandler.Commit)
+	}
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/api/handlers"
+	"github.com/gilabs/crm-healthcare/api/internal/api/middleware"
+	"github.com/gilabs/crm-healthcare/api/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupLeadAssignmentRoutes sets up lead assignment rule routes
+func SetupLeadAssignmentRoutes(router *gin.RouterGroup, leadAssignmentHandler *handlers.LeadAssignmentHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
+	rules := router.Group("/lead-assignment/rules")
+	rules.Use(middleware.AuthMiddleware(jwtManager))
+	{
+		rules.GET("", middleware.RequirePermission(permissionChecker, "MANAGE_LEAD_ASSIGNMENT"), leadAssignmentHandler.List)
+		rules.GET("/:id", middleware.RequirePermission(permissionChecker, "MANAGE_LEAD_ASSIGNMENT"), leadAssignmentHandler.GetByID)
+		rules.POST("", middleware.RequirePermission(permissionChecker, "MANAGE_LEAD_ASSIGNMENT"), leadAssignmentHandler.Create)
+		rules.PUT("/:id", middleware.RequirePermission(permissionChecker, "MANAGE_LEAD_ASSIGNMENT"), leadAssignmentHandler.Update)
+		rules.DELETE("/:id", middleware.RequirePermission(permissionChecker, "MANAGE_LEAD_ASSIGNMENT"), leadAssignmentHandler.Delete)
+	}
+}
@@ -8,22 +8,24 @@ import (
 )
 
 // SetupLeadRoutes sets up lead routes
-func SetupLeadRoutes(router *gin.RouterGroup, leadHandler *handlers.LeadHandler, jwtManager *jwt.JWTManager) {
+func SetupLeadRoutes(router *gin.RouterGroup, leadHandler *handlers.LeadHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
 	leads := router.Group("/leads")
 	leads.Use(middleware.AuthMiddleware(jwtManager))
+	leads.Use(middleware.DataScopeMiddleware(permissionChecker))
 	{
-		leads.GET("", leadHandler.List)
-		leads.GET("/form-data", leadHandler.GetFormData)
-		leads.GET("/analytics", leadHandler.GetAnalytics)
-		leads.GET("/:id", leadHandler.GetByID)
-		leads.POST("", leadHandler.Create)
-		leads.PUT("/:id", leadHandler.Update)
-		leads.DELETE("/:id", leadHandler.Delete)
-		leads.POST("/:id/convert", leadHandler.Convert)
-		leads.POST("/:id/create-account", leadHandler.CreateAccountFromLead)
+		leads.GET("", middleware.RequirePermission(permissionChecker, "VIEW_LEADS"), leadHandler.List)
+		leads.GET("/export", middleware.RequirePermission(permissionChecker, "VIEW_LEADS"), leadHandler.Export)
+		leads.GET("/form-data", middleware.RequirePermission(permissionChecker, "CREATE_LEADS", "EDIT_LEADS"), leadHandler.GetFormData)
+		leads.GET("/analytics", middleware.RequirePermission(permissionChecker, "VIEW_ANALYTICS"), leadHandler.GetAnalytics)
+		leads.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_LEADS"), leadHandler.GetByID)
+		leads.POST("", middleware.RequirePermission(permissionChecker, "CREATE_LEADS"), leadHandler.Create)
+		leads.PUT("/:id", middleware.RequirePermission(permissionChecker, "EDIT_LEADS"), leadHandler.Update)
+		leads.DELETE("/:id", middleware.RequirePermission(permissionChecker, "DELETE_LEADS"), leadHandler.Delete)
+		leads.POST("/:id/convert", middleware.RequirePermission(permissionChecker, "CONVERT_LEADS"), leadHandler.Convert)
+		leads.POST("/:id/create-account", middleware.RequirePermission(permissionChecker, "CREATE_ACCOUNT_FROM_LEAD"), leadHandler.CreateAccountFromLead)
 		// Lead related resources
-		leads.GET("/:id/visit-reports", leadHandler.GetVisitReportsByLead)
-		leads.GET("/:id/activities", leadHandler.GetActivitiesByLead)
+		leads.GET("/:id/visit-reports", middleware.RequirePermission(permissionChecker, "VIEW_LEADS"), leadHandler.GetVisitReportsByLead)
+		leads.GET("/:id/activities", middleware.RequirePermission(permissionChecker, "VIEW_LEADS"), leadHandler.GetActivitiesByLead)
 	}
 }
 
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/api/handlers"
+	"github.com/gilabs/crm-healthcare/api/internal/api/middleware"
+	"github.com/gilabs/crm-healthcare/api/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupLeadScoringRoutes sets up lead scoring rule routes
+func SetupLeadScoringRoutes(router *gin.RouterGroup, leadScoringHandler *handlers.LeadScoringHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
+	rules := router.Group("/lead-scoring/rules")
+	rules.Use(middleware.AuthMiddleware(jwtManager))
+	{
+		rules.GET("", middleware.RequirePermission(permissionChecker, "VIEW_LEADS"), leadScoringHandler.List)
+		rules.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_LEADS"), leadScoringHandler.GetByID)
+		rules.POST("", middleware.RequirePermission(permissionChecker, "MANAGE_LEAD_SCORING"), leadScoringHandler.Create)
+		rules.POST("/recompute", middleware.RequirePermission(permissionChecker, "MANAGE_LEAD_SCORING"), leadScoringHandler.Recompute)
+		rules.PUT("/:id", middleware.RequirePermission(permissionChecker, "MANAGE_LEAD_SCORING"), leadScoringHandler.Update)
+		rules.DELETE("/:id", middleware.RequirePermission(permissionChecker, "MANAGE_LEAD_SCORING"), leadScoringHandler.Delete)
+	}
+}
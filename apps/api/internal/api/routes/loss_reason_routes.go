@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/api/handlers"
+	"github.com/gilabs/crm-healthcare/api/internal/api/middleware"
+	"github.com/gilabs/crm-healthcare/api/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupLossReasonRoutes sets up loss reason routes; anyone who can view the pipeline can pick from the list
+func SetupLossReasonRoutes(router *gin.RouterGroup, lossReasonHandler *handlers.LossReasonHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
+	lossReasons := router.Group("/loss-reasons")
+	lossReasons.Use(middleware.AuthMiddleware(jwtManager))
+	{
+		lossReasons.GET("", middleware.RequirePermission(permissionChecker, "VIEW_PIPELINE", "LOSS_REASONS"), lossReasonHandler.List)
+		lossReasons.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_PIPELINE", "LOSS_REASONS"), lossReasonHandler.GetByID)
+		lossReasons.POST("", middleware.RequirePermission(permissionChecker, "LOSS_REASONS"), lossReasonHandler.Create)
+		lossReasons.PUT("/:id", middleware.RequirePermission(permissionChecker, "LOSS_REASONS"), lossReasonHandler.Update)
+		lossReasons.DELETE("/:id", middleware.RequirePermission(permissionChecker, "LOSS_REASONS"), lossReasonHandler.Delete)
+	}
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/api/handlers"
+	"github.com/gilabs/crm-healthcare/api/internal/api/middleware"
+	"github.com/gilabs/crm-healthcare/api/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupMessagingRoutes sets up SMS and WhatsApp messaging routes
+func SetupMessagingRoutes(router *gin.RouterGroup, messagingHandler *handlers.MessagingHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
+	messaging := router.Group("/messaging")
+
+	// Providers post delivery receipts with the webhook token instead of a user session
+	webhooks := messaging.Group("/webhooks")
+	{
+		webhooks.GET("/:channel", messagingHandler.VerifyWebhook)
+		webhooks.POST("/:channel", messagingHandler.Receipt)
+	}
+
+	// Every user manages their own phone number and opt-in
+	preferences := messaging.Group("/preferences")
+	preferences.Use(middleware.AuthMiddleware(jwtManager))
+	{
+		preferences.GET("", messagingHandler.GetPreference)
+		preferences.PUT("", messagingHandler.UpdatePreference)
+	}
+
+	messages := messaging.Group("/messages")
+	messages.Use(middleware.AuthMiddleware(jwtManager))
+	messages.Use(middleware.RequirePermission(permissionChecker, "MANAGE_MESSAGING"))
+	{
+		messages.GET("", messagingHandler.ListMessages)
+	}
+}
@@ -7,12 +7,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupPermissionRoutes(router *gin.RouterGroup, permissionHandler *handlers.PermissionHandler, jwtManager *jwt.JWTManager) {
+func SetupPermissionRoutes(router *gin.RouterGroup, permissionHandler *handlers.PermissionHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
 	permissions := router.Group("/permissions")
 	permissions.Use(middleware.AuthMiddleware(jwtManager))
 	{
-		permissions.GET("", permissionHandler.List)
-		permissions.GET("/:id", permissionHandler.GetByID)
+		permissions.GET("", middleware.RequirePermission(permissionChecker, "PERMISSIONS", "ROLES"), permissionHandler.List)
+		permissions.GET("/:id", middleware.RequirePermission(permissionChecker, "PERMISSIONS", "ROLES"), permissionHandler.GetByID)
 	}
 }
 
@@ -8,36 +8,56 @@ import (
 )
 
 // SetupPipelineRoutes sets up pipeline routes
-func SetupPipelineRoutes(router *gin.RouterGroup, pipelineHandler *handlers.PipelineHandler, dealHandler *handlers.DealHandler, jwtManager *jwt.JWTManager) {
+func SetupPipelineRoutes(router *gin.RouterGroup, pipelineHandler *handlers.PipelineHandler, dealHandler *handlers.DealHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
 	pipelines := router.Group("/pipelines")
 	pipelines.Use(middleware.AuthMiddleware(jwtManager))
 	{
 	// Pipeline stages
-	pipelines.GET("", pipelineHandler.ListStages)
-	pipelines.GET("/:id", pipelineHandler.GetStageByID)
-	pipelines.POST("", pipelineHandler.CreateStage)
-	pipelines.PUT("/:id", pipelineHandler.UpdateStage)
-	pipelines.DELETE("/:id", pipelineHandler.DeleteStage)
-	pipelines.PUT("/order", pipelineHandler.UpdateStagesOrder)
+	pipelines.GET("", middleware.RequirePermission(permissionChecker, "VIEW_PIPELINE", "STAGES"), pipelineHandler.ListStages)
+	pipelines.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_PIPELINE", "STAGES"), pipelineHandler.GetStageByID)
+	pipelines.POST("", middleware.RequirePermission(permissionChecker, "STAGES"), pipelineHandler.CreateStage)
+	pipelines.PUT("/:id", middleware.RequirePermission(permissionChecker, "STAGES"), pipelineHandler.UpdateStage)
+	pipelines.DELETE("/:id", middleware.RequirePermission(permissionChecker, "STAGES"), pipelineHandler.DeleteStage)
+	pipelines.PUT("/order", middleware.RequirePermission(permissionChecker, "STAGES"), pipelineHandler.UpdateStagesOrder)
 	
 	// Pipeline summary and forecast
-	pipelines.GET("/summary", pipelineHandler.GetSummary)
-	pipelines.GET("/forecast", pipelineHandler.GetForecast)
+	pipelines.GET("/summary", middleware.RequirePermission(permissionChecker, "VIEW_SUMMARY"), pipelineHandler.GetSummary)
+	pipelines.GET("/forecast", middleware.RequirePermission(permissionChecker, "VIEW_FORECAST"), pipelineHandler.GetForecast)
+	pipelines.GET("/analytics", middleware.RequirePermission(permissionChecker, "VIEW_PIPELINE_ANALYTICS"), middleware.DataScopeMiddleware(permissionChecker), pipelineHandler.GetAnalytics)
+	}
+
+	// Sales pipelines, each with its own set of stages
+	salesPipelines := router.Group("/sales-pipelines")
+	salesPipelines.Use(middleware.AuthMiddleware(jwtManager))
+	{
+		salesPipelines.GET("", middleware.RequirePermission(permissionChecker, "VIEW_PIPELINE", "PIPELINES"), pipelineHandler.ListPipelines)
+		salesPipelines.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_PIPELINE", "PIPELINES"), pipelineHandler.GetPipelineByID)
+		salesPipelines.POST("", middleware.RequirePermission(permissionChecker, "PIPELINES"), pipelineHandler.CreatePipeline)
+		salesPipelines.PUT("/:id", middleware.RequirePermission(permissionChecker, "PIPELINES"), pipelineHandler.UpdatePipeline)
+		salesPipelines.DELETE("/:id", middleware.RequirePermission(permissionChecker, "PIPELINES"), pipelineHandler.DeletePipeline)
 	}
 
 	// Deals routes
 	deals := router.Group("/deals")
 	deals.Use(middleware.AuthMiddleware(jwtManager))
+	deals.Use(middleware.DataScopeMiddleware(permissionChecker))
 	{
-		deals.GET("", dealHandler.List)
-		deals.GET("/:id", dealHandler.GetByID)
-		deals.POST("", dealHandler.Create)
-		deals.PUT("/:id", dealHandler.Update)
-		deals.DELETE("/:id", dealHandler.Delete)
-		deals.POST("/:id/move", dealHandler.Move)
+		deals.GET("", middleware.RequirePermission(permissionChecker, "VIEW_PIPELINE"), dealHandler.List)
+		deals.GET("/export", middleware.RequirePermission(permissionChecker, "VIEW_PIPELINE"), dealHandler.Export)
+		deals.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_PIPELINE", "DETAIL_DEALS"), dealHandler.GetByID)
+		deals.POST("", middleware.RequirePermission(permissionChecker, "CREATE_DEALS"), dealHandler.Create)
+		deals.PUT("/:id", middleware.RequirePermission(permissionChecker, "EDIT_DEALS"), dealHandler.Update)
+		deals.DELETE("/:id", middleware.RequirePermission(permissionChecker, "DELETE_DEALS"), dealHandler.Delete)
+		deals.POST("/:id/move", middleware.RequirePermission(permissionChecker, "MOVE_DEALS"), dealHandler.Move)
 		// Deal related resources
-		deals.GET("/:id/visit-reports", dealHandler.GetVisitReportsByDeal)
-		deals.GET("/:id/activities", dealHandler.GetActivitiesByDeal)
+		deals.GET("/:id/visit-reports", middleware.RequirePermission(permissionChecker, "VIEW_PIPELINE", "DETAIL_DEALS"), dealHandler.GetVisitReportsByDeal)
+		deals.GET("/:id/activities", middleware.RequirePermission(permissionChecker, "VIEW_PIPELINE", "DETAIL_DEALS"), dealHandler.GetActivitiesByDeal)
+		deals.GET("/:id/stage-history", middleware.RequirePermission(permissionChecker, "VIEW_PIPELINE", "DETAIL_DEALS"), dealHandler.GetStageHistory)
+		// Deal line items
+		deals.GET("/:id/items", middleware.RequirePermission(permissionChecker, "VIEW_PIPELINE", "DETAIL_DEALS"), dealHandler.ListItems)
+		deals.POST("/:id/items", middleware.RequirePermission(permissionChecker, "EDIT_DEALS"), dealHandler.AddItem)
+		deals.PUT("/:id/items/:itemId", middleware.RequirePermission(permissionChecker, "EDIT_DEALS"), dealHandler.UpdateItem)
+		deals.DELETE("/:id/items/:itemId", middleware.RequirePermission(permissionChecker, "EDIT_DEALS"), dealHandler.DeleteItem)
 	}
 }
 
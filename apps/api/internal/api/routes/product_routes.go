@@ -8,25 +8,25 @@ import (
 )
 
 // SetupProductRoutes sets up product routes.
-func SetupProductRoutes(router *gin.RouterGroup, productHandler *handlers.ProductHandler, jwtManager *jwt.JWTManager) {
+func SetupProductRoutes(router *gin.RouterGroup, productHandler *handlers.ProductHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
 	products := router.Group("/products")
 	products.Use(middleware.AuthMiddleware(jwtManager))
 	{
-		products.GET("", productHandler.List)
-		products.GET("/:id", productHandler.GetByID)
-		products.POST("", productHandler.Create)
-		products.PUT("/:id", productHandler.Update)
-		products.DELETE("/:id", productHandler.Delete)
+		products.GET("", middleware.RequirePermission(permissionChecker, "VIEW_PRODUCTS"), productHandler.List)
+		products.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_PRODUCTS"), productHandler.GetByID)
+		products.POST("", middleware.RequirePermission(permissionChecker, "CREATE_PRODUCTS"), productHandler.Create)
+		products.PUT("/:id", middleware.RequirePermission(permissionChecker, "EDIT_PRODUCTS"), productHandler.Update)
+		products.DELETE("/:id", middleware.RequirePermission(permissionChecker, "DELETE_PRODUCTS"), productHandler.Delete)
 	}
 
 	productCategories := router.Group("/product-categories")
 	productCategories.Use(middleware.AuthMiddleware(jwtManager))
 	{
-		productCategories.GET("", productHandler.ListCategories)
-		productCategories.GET("/:id", productHandler.GetCategoryByID)
-		productCategories.POST("", productHandler.CreateCategory)
-		productCategories.PUT("/:id", productHandler.UpdateCategory)
-		productCategories.DELETE("/:id", productHandler.DeleteCategory)
+		productCategories.GET("", middleware.RequirePermission(permissionChecker, "VIEW_PRODUCT_CATEGORIES", "VIEW_PRODUCTS"), productHandler.ListCategories)
+		productCategories.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_PRODUCT_CATEGORIES", "VIEW_PRODUCTS"), productHandler.GetCategoryByID)
+		productCategories.POST("", middleware.RequirePermission(permissionChecker, "CREATE_PRODUCT_CATEGORIES"), productHandler.CreateCategory)
+		productCategories.PUT("/:id", middleware.RequirePermission(permissionChecker, "EDIT_PRODUCT_CATEGORIES"), productHandler.UpdateCategory)
+		productCategories.DELETE("/:id", middleware.RequirePermission(permissionChecker, "DELETE_PRODUCT_CATEGORIES"), productHandler.DeleteCategory)
 	}
 }
 
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/api/handlers"
+	"github.com/gilabs/crm-healthcare/api/internal/api/middleware"
+	"github.com/gilabs/crm-healthcare/api/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupQuotationRoutes sets up quotation routes
+func SetupQuotationRoutes(router *gin.RouterGroup, quotationHandler *handlers.QuotationHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
+	quotations := router.Group("/quotations")
+	quotations.Use(middleware.AuthMiddleware(jwtManager))
+	quotations.Use(middleware.DataScopeMiddleware(permissionChecker))
+	{
+		quotations.GET("", middleware.RequirePermission(permissionChecker, "VIEW_QUOTATIONS"), quotationHandler.List)
+		quotations.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_QUOTATIONS"), quotationHandler.GetByID)
+		quotations.GET("/:id/revisions", middleware.RequirePermission(permissionChecker, "VIEW_QUOTATIONS"), quotationHandler.ListRevisions)
+		// Quotation PDFs are only served here, within the caller's data scope
+		quotations.GET("/:id/revisions/:rev/pdf", middleware.RequirePermission(permissionChecker, "VIEW_QUOTATIONS"), quotationHandler.DownloadRevisionPDF)
+		quotations.POST("", middleware.RequirePermission(permissionChecker, "MANAGE_QUOTATIONS"), quotationHandler.Create)
+		quotations.POST("/:id/revisions", middleware.RequirePermission(permissionChecker, "MANAGE_QUOTATIONS"), quotationHandler.Revise)
+		quotations.POST("/:id/send", middleware.RequirePermission(permissionChecker, "MANAGE_QUOTATIONS"), quotationHandler.Send)
+		// Accepting a quotation wins its deal
+		quotations.POST("/:id/accept", middleware.RequirePermission(permissionChecker, "CLOSE_QUOTATIONS"), quotationHandler.Accept)
+		quotations.POST("/:id/reject", middleware.RequirePermission(permissionChecker, "CLOSE_QUOTATIONS"), quotationHandler.Reject)
+	}
+}
@@ -7,20 +7,22 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupReportRoutes(router *gin.RouterGroup, reportHandler *handlers.ReportHandler, jwtManager *jwt.JWTManager) {
+func SetupReportRoutes(router *gin.RouterGroup, reportHandler *handlers.ReportHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
 	reports := router.Group("/reports")
 	reports.Use(middleware.AuthMiddleware(jwtManager))
 	{
-		reports.GET("/visit-reports", reportHandler.GetVisitReportReport)
-		reports.GET("/pipeline", reportHandler.GetPipelineReport)
-		reports.GET("/sales-performance", reportHandler.GetSalesPerformanceReport)
-		reports.GET("/account-activity", reportHandler.GetAccountActivityReport)
+		reports.GET("/visit-reports", middleware.RequirePermission(permissionChecker, "VIEW_REPORTS"), reportHandler.GetVisitReportReport)
+		reports.GET("/pipeline", middleware.RequirePermission(permissionChecker, "VIEW_REPORTS"), reportHandler.GetPipelineReport)
+		reports.GET("/sales-performance", middleware.RequirePermission(permissionChecker, "VIEW_REPORTS"), reportHandler.GetSalesPerformanceReport)
+		reports.GET("/account-activity", middleware.RequirePermission(permissionChecker, "VIEW_REPORTS"), reportHandler.GetAccountActivityReport)
+		reports.GET("/win-loss", middleware.RequirePermission(permissionChecker, "VIEW_REPORTS"), reportHandler.GetWinLossReport)
 		
 		// Export endpoints
-		reports.GET("/visit-reports/export", reportHandler.ExportVisitReportReport)
-		reports.GET("/pipeline/export", reportHandler.ExportPipelineReport)
-		reports.GET("/sales-performance/export", reportHandler.ExportSalesPerformanceReport)
-		reports.GET("/account-activity/export", reportHandler.ExportAccountActivityReport)
+		reports.GET("/visit-reports/export", middleware.RequirePermission(permissionChecker, "EXPORT_REPORTS"), reportHandler.ExportVisitReportReport)
+		reports.GET("/pipeline/export", middleware.RequirePermission(permissionChecker, "EXPORT_REPORTS"), reportHandler.ExportPipelineReport)
+		reports.GET("/sales-performance/export", middleware.RequirePermission(permissionChecker, "EXPORT_REPORTS"), reportHandler.ExportSalesPerformanceReport)
+		reports.GET("/account-activity/export", middleware.RequirePermission(permissionChecker, "EXPORT_REPORTS"), reportHandler.ExportAccountActivityReport)
+		reports.GET("/win-loss/export", middleware.RequirePermission(permissionChecker, "EXPORT_REPORTS"), reportHandler.ExportWinLossReport)
 	}
 }
 
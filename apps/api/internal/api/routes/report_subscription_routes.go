@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/api/handlers"
+	"github.com/gilabs/crm-healthcare/api/internal/api/middleware"
+	"github.com/gilabs/crm-healthcare/api/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupReportSubscriptionRoutes sets up scheduled report subscription routes
+func SetupReportSubscriptionRoutes(router *gin.RouterGroup, subscriptionHandler *handlers.ReportSubscriptionHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
+	subscriptions := router.Group("/reports/subscriptions")
+	subscriptions.Use(middleware.AuthMiddleware(jwtManager))
+	subscriptions.Use(middleware.RequirePermission(permissionChecker, "MANAGE_REPORT_SUBSCRIPTIONS"))
+	{
+		subscriptions.GET("", subscriptionHandler.List)
+		subscriptions.GET("/:id", subscriptionHandler.GetByID)
+		subscriptions.POST("", subscriptionHandler.Create)
+		subscriptions.PUT("/:id", subscriptionHandler.Update)
+		subscriptions.DELETE("/:id", subscriptionHandler.Delete)
+		subscriptions.POST("/:id/send", subscriptionHandler.Send)
+		subscriptions.GET("/:id/deliveries", subscriptionHandler.ListDeliveries)
+	}
+}
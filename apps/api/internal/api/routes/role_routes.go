@@ -7,18 +7,18 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoleRoutes(router *gin.RouterGroup, roleHandler *handlers.RoleHandler, jwtManager *jwt.JWTManager) {
+func SetupRoleRoutes(router *gin.RouterGroup, roleHandler *handlers.RoleHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
 	roles := router.Group("/roles")
 	roles.Use(middleware.AuthMiddleware(jwtManager))
 	{
-		roles.GET("", roleHandler.List)
-		roles.GET("/:id", roleHandler.GetByID)
-		roles.POST("", roleHandler.Create)
-		roles.PUT("/:id", roleHandler.Update)
-		roles.DELETE("/:id", roleHandler.Delete)
-		roles.PUT("/:id/permissions", roleHandler.AssignPermissions)
-		roles.GET("/:id/mobile-permissions", roleHandler.GetMobilePermissions)
-		roles.PUT("/:id/mobile-permissions", roleHandler.UpdateMobilePermissions)
+		roles.GET("", middleware.RequirePermission(permissionChecker, "ROLES", "VIEW_USERS"), roleHandler.List)
+		roles.GET("/:id", middleware.RequirePermission(permissionChecker, "ROLES", "VIEW_USERS"), roleHandler.GetByID)
+		roles.POST("", middleware.RequirePermission(permissionChecker, "ROLES"), roleHandler.Create)
+		roles.PUT("/:id", middleware.RequirePermission(permissionChecker, "ROLES"), roleHandler.Update)
+		roles.DELETE("/:id", middleware.RequirePermission(permissionChecker, "ROLES"), roleHandler.Delete)
+		roles.PUT("/:id/permissions", middleware.RequirePermission(permissionChecker, "PERMISSIONS"), roleHandler.AssignPermissions)
+		roles.GET("/:id/mobile-permissions", middleware.RequirePermission(permissionChecker, "PERMISSIONS", "ROLES"), roleHandler.GetMobilePermissions)
+		roles.PUT("/:id/mobile-permissions", middleware.RequirePermission(permissionChecker, "PERMISSIONS"), roleHandler.UpdateMobilePermissions)
 	}
 }
 
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/api/handlers"
+	"github.com/gilabs/crm-healthcare/api/internal/api/middleware"
+	"github.com/gilabs/crm-healthcare/api/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSalesTargetRoutes sets up sales target routes
+func SetupSalesTargetRoutes(router *gin.RouterGroup, salesTargetHandler *handlers.SalesTargetHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
+	targets := router.Group("/sales-targets")
+	targets.Use(middleware.AuthMiddleware(jwtManager))
+	{
+		targets.GET("", middleware.RequirePermission(permissionChecker, "VIEW_SALES_TARGETS"), salesTargetHandler.List)
+		targets.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_SALES_TARGETS"), salesTargetHandler.GetByID)
+		targets.POST("", middleware.RequirePermission(permissionChecker, "MANAGE_SALES_TARGETS"), salesTargetHandler.Create)
+		targets.PUT("/:id", middleware.RequirePermission(permissionChecker, "MANAGE_SALES_TARGETS"), salesTargetHandler.Update)
+		targets.DELETE("/:id", middleware.RequirePermission(permissionChecker, "MANAGE_SALES_TARGETS"), salesTargetHandler.Delete)
+	}
+}
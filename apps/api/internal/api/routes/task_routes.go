@@ -8,38 +8,42 @@ import (
 )
 
 // SetupTaskRoutes sets up task and reminder routes
-func SetupTaskRoutes(router *gin.RouterGroup, taskHandler *handlers.TaskHandler, jwtManager *jwt.JWTManager) {
+func SetupTaskRoutes(router *gin.RouterGroup, taskHandler *handlers.TaskHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
 	tasks := router.Group("/tasks")
 	tasks.Use(middleware.AuthMiddleware(jwtManager))
+	tasks.Use(middleware.DataScopeMiddleware(permissionChecker))
 	{
 		// Task CRUD
-		tasks.GET("", taskHandler.List)
-		tasks.GET("/:id", taskHandler.GetByID)
-		tasks.POST("", taskHandler.Create)
-		tasks.PUT("/:id", taskHandler.Update)
-		tasks.DELETE("/:id", taskHandler.Delete)
+		tasks.GET("", middleware.RequirePermission(permissionChecker, "VIEW_TASKS"), taskHandler.List)
+		tasks.GET("/export", middleware.RequirePermission(permissionChecker, "VIEW_TASKS"), taskHandler.Export)
+		tasks.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_TASKS"), taskHandler.GetByID)
+		tasks.POST("", middleware.RequirePermission(permissionChecker, "CREATE_TASKS"), taskHandler.Create)
+		tasks.POST("/from-visit-report/:visit_report_id", middleware.RequirePermission(permissionChecker, "CREATE_TASKS"), taskHandler.CreateFromActionItems)
+		tasks.PUT("/:id", middleware.RequirePermission(permissionChecker, "EDIT_TASKS"), taskHandler.Update)
+		tasks.DELETE("/:id", middleware.RequirePermission(permissionChecker, "DELETE_TASKS"), taskHandler.Delete)
 		
 		// Task actions
-		tasks.POST("/:id/assign", taskHandler.Assign)
-		tasks.POST("/:id/complete", taskHandler.Complete)
-		tasks.POST("/:id/mark-in-progress", taskHandler.MarkInProgress)
+		tasks.POST("/:id/assign", middleware.RequirePermission(permissionChecker, "ASSIGN_TASKS"), taskHandler.Assign)
+		tasks.POST("/:id/complete", middleware.RequirePermission(permissionChecker, "EDIT_TASKS"), taskHandler.Complete)
+		tasks.POST("/:id/mark-in-progress", middleware.RequirePermission(permissionChecker, "EDIT_TASKS"), taskHandler.MarkInProgress)
 		
 		// Reminder CRUD
-		tasks.GET("/reminders", taskHandler.ListReminders)
-		tasks.GET("/reminders/:id", taskHandler.GetReminderByID)
-		tasks.POST("/reminders", taskHandler.CreateReminder)
-		tasks.PUT("/reminders/:id", taskHandler.UpdateReminder)
-		tasks.DELETE("/reminders/:id", taskHandler.DeleteReminder)
+		tasks.GET("/reminders", middleware.RequirePermission(permissionChecker, "VIEW_TASKS"), taskHandler.ListReminders)
+		tasks.GET("/reminders/:id", middleware.RequirePermission(permissionChecker, "VIEW_TASKS"), taskHandler.GetReminderByID)
+		tasks.POST("/reminders", middleware.RequirePermission(permissionChecker, "CREATE_TASKS", "EDIT_TASKS"), taskHandler.CreateReminder)
+		tasks.PUT("/reminders/:id", middleware.RequirePermission(permissionChecker, "EDIT_TASKS"), taskHandler.UpdateReminder)
+		tasks.DELETE("/reminders/:id", middleware.RequirePermission(permissionChecker, "DELETE_TASKS", "EDIT_TASKS"), taskHandler.DeleteReminder)
 	}
 
 	// Mobile-specific routes
 	mobile := router.Group("/mobile")
 	mobile.Use(middleware.AuthMiddleware(jwtManager))
+	mobile.Use(middleware.DataScopeMiddleware(permissionChecker))
 	{
 		mobileTasks := mobile.Group("/tasks")
 		{
 			// Get tasks for logged-in user
-			mobileTasks.GET("/my-tasks", taskHandler.GetMyTasks)
+			mobileTasks.GET("/my-tasks", middleware.RequirePermission(permissionChecker, "VIEW_TASKS"), taskHandler.GetMyTasks)
 		}
 	}
 }
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/api/handlers"
+	"github.com/gilabs/crm-healthcare/api/internal/api/middleware"
+	"github.com/gilabs/crm-healthcare/api/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupTeamRoutes sets up sales team routes
+func SetupTeamRoutes(router *gin.RouterGroup, teamHandler *handlers.TeamHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
+	teams := router.Group("/teams")
+	teams.Use(middleware.AuthMiddleware(jwtManager))
+	{
+		teams.GET("", middleware.RequirePermission(permissionChecker, "VIEW_USERS"), teamHandler.List)
+		teams.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_USERS"), teamHandler.GetByID)
+		teams.POST("", middleware.RequirePermission(permissionChecker, "CREATE_USERS", "ROLES"), teamHandler.Create)
+		teams.PUT("/:id", middleware.RequirePermission(permissionChecker, "EDIT_USERS", "ROLES"), teamHandler.Update)
+		teams.DELETE("/:id", middleware.RequirePermission(permissionChecker, "DELETE_USERS", "ROLES"), teamHandler.Delete)
+	}
+}
@@ -7,20 +7,20 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupUserRoutes(router *gin.RouterGroup, userHandler *handlers.UserHandler, permissionHandler *handlers.PermissionHandler, jwtManager *jwt.JWTManager) {
+func SetupUserRoutes(router *gin.RouterGroup, userHandler *handlers.UserHandler, permissionHandler *handlers.PermissionHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
 	users := router.Group("/users")
 	users.Use(middleware.AuthMiddleware(jwtManager))
 	{
-		users.GET("", userHandler.List)
-		users.GET("/:id", userHandler.GetByID)
-		users.POST("", userHandler.Create)
-		users.PUT("/:id", userHandler.Update)
-		users.DELETE("/:id", userHandler.Delete)
-		users.GET("/:id/permissions", permissionHandler.GetUserPermissions)
+		users.GET("", middleware.RequirePermission(permissionChecker, "VIEW_USERS", "VIEW_TASKS", "VIEW_LEADS", "VIEW_PIPELINE"), userHandler.List)
+		users.GET("/:id", middleware.RequirePermissionOrSelf(permissionChecker, "id", "VIEW_USERS"), userHandler.GetByID)
+		users.POST("", middleware.RequirePermission(permissionChecker, "CREATE_USERS"), userHandler.Create)
+		users.PUT("/:id", middleware.RequirePermission(permissionChecker, "EDIT_USERS"), userHandler.Update)
+		users.DELETE("/:id", middleware.RequirePermission(permissionChecker, "DELETE_USERS"), userHandler.Delete)
+		users.GET("/:id/permissions", middleware.RequirePermissionOrSelf(permissionChecker, "id", "PERMISSIONS", "VIEW_USERS"), permissionHandler.GetUserPermissions)
 		// Profile routes
-		users.GET("/:id/profile", userHandler.GetProfile)
-		users.PUT("/:id/profile", userHandler.UpdateProfile)
-		users.PUT("/:id/password", userHandler.ChangePassword)
+		users.GET("/:id/profile", middleware.RequirePermissionOrSelf(permissionChecker, "id", "VIEW_USERS"), userHandler.GetProfile)
+		users.PUT("/:id/profile", middleware.RequirePermissionOrSelf(permissionChecker, "id", "EDIT_USERS"), userHandler.UpdateProfile)
+		users.PUT("/:id/password", middleware.RequirePermissionOrSelf(permissionChecker, "id", "EDIT_USERS"), userHandler.ChangePassword)
 	}
 }
 
@@ -7,37 +7,39 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupVisitReportRoutes(router *gin.RouterGroup, visitReportHandler *handlers.VisitReportHandler, activityTypeHandler *handlers.ActivityTypeHandler, jwtManager *jwt.JWTManager) {
+func SetupVisitReportRoutes(router *gin.RouterGroup, visitReportHandler *handlers.VisitReportHandler, activityTypeHandler *handlers.ActivityTypeHandler, jwtManager *jwt.JWTManager, permissionChecker middleware.PermissionChecker) {
 	visitReports := router.Group("/visit-reports")
 	visitReports.Use(middleware.AuthMiddleware(jwtManager))
+	visitReports.Use(middleware.DataScopeMiddleware(permissionChecker))
 	{
-		visitReports.GET("", visitReportHandler.List)
-		visitReports.GET("/:id", visitReportHandler.GetByID)
-		visitReports.POST("", visitReportHandler.Create)
-		visitReports.PUT("/:id", visitReportHandler.Update)
-		visitReports.DELETE("/:id", visitReportHandler.Delete)
-		visitReports.POST("/:id/check-in", visitReportHandler.CheckIn)
-		visitReports.POST("/:id/check-out", visitReportHandler.CheckOut)
-		visitReports.POST("/:id/approve", visitReportHandler.Approve)
-		visitReports.POST("/:id/reject", visitReportHandler.Reject)
-		visitReports.POST("/:id/photos", visitReportHandler.UploadPhoto)
+		visitReports.GET("", middleware.RequirePermission(permissionChecker, "VIEW_VISIT_REPORTS"), visitReportHandler.List)
+		visitReports.GET("/:id", middleware.RequirePermission(permissionChecker, "VIEW_VISIT_REPORTS"), visitReportHandler.GetByID)
+		visitReports.POST("", middleware.RequirePermission(permissionChecker, "CREATE_VISIT_REPORTS"), visitReportHandler.Create)
+		visitReports.PUT("/:id", middleware.RequirePermission(permissionChecker, "EDIT_VISIT_REPORTS"), visitReportHandler.Update)
+		visitReports.DELETE("/:id", middleware.RequirePermission(permissionChecker, "DELETE_VISIT_REPORTS"), visitReportHandler.Delete)
+		visitReports.POST("/:id/check-in", middleware.RequirePermission(permissionChecker, "EDIT_VISIT_REPORTS", "CREATE_VISIT_REPORTS"), visitReportHandler.CheckIn)
+		visitReports.POST("/:id/check-out", middleware.RequirePermission(permissionChecker, "EDIT_VISIT_REPORTS", "CREATE_VISIT_REPORTS"), visitReportHandler.CheckOut)
+		visitReports.POST("/:id/approve", middleware.RequirePermission(permissionChecker, "APPROVE_VISIT_REPORTS"), visitReportHandler.Approve)
+		visitReports.POST("/:id/reject", middleware.RequirePermission(permissionChecker, "REJECT_VISIT_REPORTS"), visitReportHandler.Reject)
+		visitReports.POST("/:id/photos", middleware.RequirePermission(permissionChecker, "EDIT_VISIT_REPORTS", "CREATE_VISIT_REPORTS"), visitReportHandler.UploadPhoto)
 
 		// Activity Types management
-		visitReports.GET("/activity-types", activityTypeHandler.List)
-		visitReports.GET("/activity-types/:id", activityTypeHandler.GetByID)
-		visitReports.POST("/activity-types", activityTypeHandler.Create)
-		visitReports.PUT("/activity-types/:id", activityTypeHandler.Update)
-		visitReports.DELETE("/activity-types/:id", activityTypeHandler.Delete)
+		visitReports.GET("/activity-types", middleware.RequirePermission(permissionChecker, "VIEW_VISIT_REPORTS", "ACTIVITY"), activityTypeHandler.List)
+		visitReports.GET("/activity-types/:id", middleware.RequirePermission(permissionChecker, "VIEW_VISIT_REPORTS", "ACTIVITY"), activityTypeHandler.GetByID)
+		visitReports.POST("/activity-types", middleware.RequirePermission(permissionChecker, "ACTIVITY"), activityTypeHandler.Create)
+		visitReports.PUT("/activity-types/:id", middleware.RequirePermission(permissionChecker, "ACTIVITY"), activityTypeHandler.Update)
+		visitReports.DELETE("/activity-types/:id", middleware.RequirePermission(permissionChecker, "ACTIVITY"), activityTypeHandler.Delete)
 	}
 
 	// Mobile-specific routes
 	mobile := router.Group("/mobile")
 	mobile.Use(middleware.AuthMiddleware(jwtManager))
+	mobile.Use(middleware.DataScopeMiddleware(permissionChecker))
 	{
 		mobileVisitReports := mobile.Group("/visit-reports")
 		{
 			// Get visit reports for logged-in user (sales rep)
-			mobileVisitReports.GET("/my-visit-reports", visitReportHandler.GetMyVisitReports)
+			mobileVisitReports.GET("/my-visit-reports", middleware.RequirePermission(permissionChecker, "VIEW_VISIT_REPORTS"), visitReportHandler.GetMyVisitReports)
 		}
 	}
 }
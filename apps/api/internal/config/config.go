@@ -15,6 +15,10 @@ type Config struct {
 	Storage   StorageConfig
 	RateLimit RateLimitConfig
 	HSTS      HSTSConfig
+	Report    ReportConfig
+	SMTP      SMTPConfig
+	Messaging MessagingConfig
+	Deals     DealsConfig
 }
 
 type ServerConfig struct {
@@ -80,6 +84,49 @@ type HSTSConfig struct {
 	Preload           bool // Enable HSTS preload
 }
 
+// ReportConfig defines how exported reports are rendered
+type ReportConfig struct {
+	BrandName string // Company name in the header of PDF reports
+}
+
+// DealsConfig defines which loss details are required to close a deal as lost
+type DealsConfig struct {
+	RequireLostReason     bool
+	RequireLostCompetitor bool
+}
+
+// SMTPConfig defines the mail server used for notification emails and scheduled reports; email is disabled while Host is empty
+type SMTPConfig struct {
+	Host        string
+	Port        int
+	Username    string // Leave empty for servers without authentication, e.g. Mailpit on localhost:1025
+	Password    string
+	From        string
+	FromName    string
+	MaxAttempts int // Attempts of a queued notification email before it is marked failed
+}
+
+// MessagingConfig defines the SMS and WhatsApp gateways used for reminders and task notifications
+type MessagingConfig struct {
+	DefaultCountryCode string // Calling code for phone numbers entered without one
+	WebhookSecret      string // Token providers must send with delivery receipts; receipts are rejected while empty
+	MaxAttempts        int    // Attempts of a queued message before it is marked failed
+	SMS                MessagingGatewayConfig
+	WhatsApp           MessagingGatewayConfig
+}
+
+// MessagingGatewayConfig defines the HTTP API of one provider; the channel is disabled while URL is empty
+type MessagingGatewayConfig struct {
+	URL                string
+	AuthHeader         string // Value of the Authorization header, e.g. "Bearer <token>" or "Basic <base64>"
+	ContentType        string
+	BodyTemplate       string // text/template over To, Body and Channel
+	MessageIDField     string
+	ReceiptIDField     string
+	ReceiptStatusField string
+	ReceiptErrorField  string
+}
+
 var AppConfig *Config
 
 func Load() error {
@@ -159,11 +206,50 @@ func Load() error {
 			IncludeSubDomains: getEnv("HSTS_INCLUDE_SUBDOMAINS", "true") == "true",
 			Preload:           getEnv("HSTS_PRELOAD", "true") == "true",
 		},
+		Report: ReportConfig{
+			BrandName: getEnv("REPORT_BRAND_NAME", "CRM Healthcare"),
+		},
+		SMTP: SMTPConfig{
+			Host:        getEnv("SMTP_HOST", ""),
+			Port:        getEnvAsInt("SMTP_PORT", 587),
+			Username:    getEnv("SMTP_USERNAME", ""),
+			Password:    getEnv("SMTP_PASSWORD", ""),
+			From:        getEnv("SMTP_FROM", "noreply@crm-healthcare.local"),
+			FromName:    getEnv("SMTP_FROM_NAME", "CRM Healthcare"),
+			MaxAttempts: getEnvAsInt("EMAIL_MAX_ATTEMPTS", 6),
+		},
+		Messaging: MessagingConfig{
+			DefaultCountryCode: getEnv("MESSAGING_DEFAULT_COUNTRY_CODE", "62"),
+			WebhookSecret:      getEnv("MESSAGING_WEBHOOK_SECRET", ""),
+			MaxAttempts:        getEnvAsInt("MESSAGING_MAX_ATTEMPTS", 5),
+			SMS:                getMessagingGatewayConfig("SMS"),
+			WhatsApp:           getMessagingGatewayConfig("WHATSAPP"),
+		},
+		// Required unless a deployment opts out with "false", so win/loss reports always have loss details
+		Deals: DealsConfig{
+			RequireLostReason:     getEnv("DEAL_REQUIRE_LOST_REASON", "true") != "false",
+			RequireLostCompetitor: getEnv("DEAL_REQUIRE_LOST_COMPETITOR", "true") != "false",
+		},
 	}
 
 	return nil
 }
 
+// getMessagingGatewayConfig reads the MESSAGING_<channel>_* variables of one channel
+func getMessagingGatewayConfig(channel string) MessagingGatewayConfig {
+	prefix := "MESSAGING_" + channel + "_"
+	return MessagingGatewayConfig{
+		URL:                getEnv(prefix+"URL", ""),
+		AuthHeader:         getEnv(prefix+"AUTH_HEADER", ""),
+		ContentType:        getEnv(prefix+"CONTENT_TYPE", "application/json"),
+		BodyTemplate:       getEnv(prefix+"BODY_TEMPLATE", ""),
+		MessageIDField:     getEnv(prefix+"MESSAGE_ID_FIELD", ""),
+		ReceiptIDField:     getEnv(prefix+"RECEIPT_ID_FIELD", ""),
+		ReceiptStatusField: getEnv(prefix+"RECEIPT_STATUS_FIELD", ""),
+		ReceiptErrorField:  getEnv(prefix+"RECEIPT_ERROR_FIELD", ""),
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -1,30 +1,47 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"strings"
 
 	"github.com/gilabs/crm-healthcare/api/internal/config"
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/activity"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/activity_type"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_conversation"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_model_usage"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_settings"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/audit_log"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/category"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/contact"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/competitor"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/contact_role"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/import_job"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_assignment"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_scoring"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/loss_reason"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/notification"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/permission"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/product"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/quotation"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/refresh_token"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/reminder"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/role"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/sales_target"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/report_subscription"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/email_outbox"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/messaging"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/task"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/team"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/user"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/visit_report"
+	"github.com/gilabs/crm-healthcare/api/pkg/llm"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -74,10 +91,14 @@ func AutoMigrate() error {
 		log.Printf("Warning: Could not handle constraint issues (this may be expected): %v", err)
 	}
 
+	// Roles created before data scopes existed get the column with its 'all' default
+	backfillRoleScopes := DB.Migrator().HasTable(&role.Role{}) && !DB.Migrator().HasColumn(&role.Role{}, "data_scope")
+
 	// Use a custom migration approach that handles constraint errors gracefully
 		err := migrateWithErrorHandling(
 		&user.User{},
 		&role.Role{},
+		&team.Team{},
 		&permission.Permission{},
 		&permission.Menu{},
 		&category.Category{},
@@ -85,23 +106,63 @@ func AutoMigrate() error {
 		&account.Account{},
 		&contact.Contact{},
 		&lead.Lead{},
+		&lead_scoring.ScoringRule{},
+		&lead_assignment.AssignmentRule{},
+		&pipeline.Pipeline{},
 		&pipeline.PipelineStage{},
+		&loss_reason.LossReason{},
+		&competitor.Competitor{},
 		&pipeline.Deal{},
+		&pipeline.DealStageHistory{},
+		&pipeline.DealItem{},
 		&product.ProductCategory{},
 		&product.Product{},
+		&quotation.Quotation{},
+		&quotation.QuotationRevision{},
+		&quotation.QuotationItem{},
+		&import_job.ImportJob{},
 		&task.Task{},
 		&reminder.Reminder{},
 		&notification.Notification{},
 		&visit_report.VisitReport{},
 		&activity_type.ActivityType{},
 		&activity.Activity{},
+		&sales_target.SalesTarget{},
+		&report_subscription.ReportSubscription{},
+		&report_subscription.ReportDelivery{},
+		&email_outbox.EmailMessage{},
+		&messaging.Preference{},
+		&messaging.OutboundMessage{},
 		&ai_settings.AISettings{},
+		&ai_conversation.Conversation{},
+		&ai_conversation.Message{},
+		&ai_model_usage.ModelUsage{},
 		&refresh_token.RefreshToken{},
+		&audit_log.AuditLog{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	if err := migrateDefaultPipeline(); err != nil {
+		return fmt.Errorf("failed to migrate default pipeline: %w", err)
+	}
+
+	// Existing duplicate targets must be removed before the index can be created; keep starting meanwhile
+	if err := migrateSalesTargetOwnerPeriodIndex(); err != nil {
+		log.Printf("Warning: Could not create unique sales target index (remove duplicate targets): %v", err)
+	}
+
+	if err := migrateAIProviders(); err != nil {
+		return fmt.Errorf("failed to migrate AI providers: %w", err)
+	}
+
+	if backfillRoleScopes {
+		if err := migrateRoleDataScopes(); err != nil {
+			return fmt.Errorf("failed to migrate role data scopes: %w", err)
+		}
+	}
+
 	log.Println("Database migrations completed")
 	return nil
 }
@@ -238,6 +299,72 @@ func migrateWithErrorHandling(models ...interface{}) error {
 	return nil
 }
 
+// migrateDefaultPipeline moves stages and deals created before pipelines existed into the default pipeline
+func migrateDefaultPipeline() error {
+	var defaultPipeline pipeline.Pipeline
+	err := DB.Where("is_default = ?", true).First(&defaultPipeline).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		defaultPipeline = pipeline.Pipeline{
+			Name:        "Sales Pipeline",
+			Code:        pipeline.DefaultPipelineCode,
+			Description: "Default sales pipeline",
+			IsActive:    true,
+			IsDefault:   true,
+		}
+		err = DB.Create(&defaultPipeline).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	// Stage codes are unique per pipeline now, not globally
+	if err := DB.Exec("DROP INDEX IF EXISTS idx_pipeline_stages_code").Error; err != nil {
+		return err
+	}
+
+	if err := DB.Exec("UPDATE pipeline_stages SET pipeline_id = ? WHERE pipeline_id IS NULL", defaultPipeline.ID).Error; err != nil {
+		return err
+	}
+
+	// Deals follow the pipeline of their stage
+	return DB.Exec(`
+		UPDATE deals SET pipeline_id = COALESCE(
+			(SELECT s.pipeline_id FROM pipeline_stages s WHERE s.id = deals.stage_id),
+			?
+		)
+		WHERE pipeline_id IS NULL
+	`, defaultPipeline.ID).Error
+}
+
+// migrateRoleDataScopes restricts the existing sales role to its own records, matching newly seeded databases.
+// It runs once, when the data_scope column is first added, so scopes changed by admins later are kept
+func migrateRoleDataScopes() error {
+	return DB.Model(&role.Role{}).Where("code = ?", "sales").Update("data_scope", datascope.LevelOwn).Error
+}
+
+// migrateSalesTargetOwnerPeriodIndex allows one active target per level, owner and period.
+// Company targets have no owner, so missing owners are compared as equal.
+func migrateSalesTargetOwnerPeriodIndex() error {
+	return DB.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_sales_targets_owner_period
+		ON sales_targets (level, (COALESCE(user_id::text, team_id::text, '')), period_type, period_start)
+		WHERE deleted_at IS NULL
+	`).Error
+}
+
+// migrateAIProviders moves AI settings off providers that have no implementation (e.g. anthropic).
+// Those requests were always served by the env-configured Cerebras client, so switching keeps the chatbot working as before.
+// The old base URL and API key belong to the unimplemented provider and are cleared, otherwise they would override that client.
+func migrateAIProviders() error {
+	return DB.Model(&ai_settings.AISettings{}).
+		Where("provider NOT IN ?", []string{llm.ProviderCerebras, llm.ProviderOpenAI, llm.ProviderOpenAICompatible}).
+		Updates(map[string]interface{}{
+			"provider": llm.ProviderCerebras,
+			"base_url": "",
+			"api_key":  "",
+		}).Error
+}
+
 // handleConstraintIssues attempts to fix common constraint issues before migration
 func handleConstraintIssues() error {
 	// Check if roles table exists
@@ -0,0 +1,70 @@
+package datascope
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Data scope levels stored on role.Role.DataScope
+const (
+	LevelOwn  = "own"  // Only records assigned to the user
+	LevelTeam = "team" // Records assigned to any member of the user's team
+	LevelAll  = "all"  // All records
+)
+
+// ContextKey is the gin context key holding the caller's *Scope
+const ContextKey = "data_scope"
+
+// Scope describes which owners' records a caller may access
+type Scope struct {
+	Level   string   `json:"level"`
+	UserID  string   `json:"user_id"`
+	UserIDs []string `json:"user_ids,omitempty"` // Team member IDs (including the user) for team level
+}
+
+// IsUnrestricted reports whether the scope grants access to all records
+func (s *Scope) IsUnrestricted() bool {
+	return s == nil || s.Level == LevelAll || s.Level == ""
+}
+
+// Apply returns a GORM scope restricting rows by the given owner column (e.g. "assigned_to").
+// Unassigned rows (NULL owner) stay visible to every scope, so reps can see and claim them.
+// A nil or unrestricted scope leaves the query untouched.
+func (s *Scope) Apply(column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if s.IsUnrestricted() {
+			return db
+		}
+		if s.Level == LevelTeam && len(s.UserIDs) > 0 {
+			return db.Where("("+column+" IN ? OR "+column+" IS NULL)", s.UserIDs)
+		}
+		return db.Where("("+column+" = ? OR "+column+" IS NULL)", s.UserID)
+	}
+}
+
+// Authorize returns gorm.ErrRecordNotFound when the record with the given ID lies outside the scope,
+// so out-of-scope updates and deletes behave exactly like missing records
+func (s *Scope) Authorize(db *gorm.DB, model interface{}, column, id string) error {
+	if s.IsUnrestricted() {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(model).Scopes(s.Apply(column)).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FromContext returns the scope set by DataScopeMiddleware, or nil when none was resolved
+func FromContext(c *gin.Context) *Scope {
+	if value, exists := c.Get(ContextKey); exists {
+		if scope, ok := value.(*Scope); ok {
+			return scope
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package datascope
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type deal struct {
+	ID         string
+	AssignedTo *string
+}
+
+// dryRunDB returns a database that builds statements without a server, recording the last query
+func dryRunDB(t *testing.T, sql *string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.Callback().Query().After("gorm:query").Register("test:record", func(tx *gorm.DB) {
+		*sql = tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...)
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	return db
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope *Scope
+		want  string
+	}{
+		{"nil scope", nil, `SELECT * FROM "deals"`},
+		{"all", &Scope{Level: LevelAll, UserID: "u1"}, `SELECT * FROM "deals"`},
+		{"own", &Scope{Level: LevelOwn, UserID: "u1"},
+			`SELECT * FROM "deals" WHERE (deals.assigned_to = 'u1' OR deals.assigned_to IS NULL)`},
+		{"team", &Scope{Level: LevelTeam, UserID: "u1", UserIDs: []string{"u1", "u2"}},
+			`SELECT * FROM "deals" WHERE (deals.assigned_to IN ('u1','u2') OR deals.assigned_to IS NULL)`},
+		{"team without members", &Scope{Level: LevelTeam, UserID: "u1"},
+			`SELECT * FROM "deals" WHERE (deals.assigned_to = 'u1' OR deals.assigned_to IS NULL)`},
+	}
+	for _, tt := range tests {
+		var sql string
+		db := dryRunDB(t, &sql)
+		var deals []deal
+		db.Scopes(tt.scope.Apply("deals.assigned_to")).Find(&deals)
+		if sql != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, sql)
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	// Unrestricted scopes never query the database
+	if err := (&Scope{Level: LevelAll}).Authorize(nil, &deal{}, "assigned_to", "d1"); err != nil {
+		t.Errorf("expected an unrestricted scope to authorize, got %v", err)
+	}
+	if err := (*Scope)(nil).Authorize(nil, &deal{}, "assigned_to", "d1"); err != nil {
+		t.Errorf("expected a nil scope to authorize, got %v", err)
+	}
+
+	// A dry run counts no rows, so the record is treated as out of scope
+	var sql string
+	db := dryRunDB(t, &sql)
+	err := (&Scope{Level: LevelOwn, UserID: "u1"}).Authorize(db, &deal{}, "assigned_to", "d1")
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+	want := `SELECT count(*) FROM "deals" WHERE id = 'd1' AND ((assigned_to = 'u1' OR assigned_to IS NULL))`
+	if sql != want {
+		t.Errorf("expected %q, got %q", want, sql)
+	}
+}
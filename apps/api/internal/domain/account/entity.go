@@ -3,6 +3,8 @@ package account
 import (
 	"time"
 
+	"github.com/gilabs/crm-healthcare/api/internal/domain/duplicate"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -48,6 +50,17 @@ func (a *Account) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// DuplicateRecord returns the attributes compared when looking for duplicate accounts
+func (a *Account) DuplicateRecord() duplicate.Record {
+	return duplicate.Record{
+		ID:    a.ID,
+		Name:  a.Name,
+		Email: a.Email,
+		Phone: a.Phone,
+		City:  a.City,
+	}
+}
+
 // AccountResponse represents account response DTO
 type AccountResponse struct {
 	ID         string    `json:"id"`
@@ -106,15 +119,16 @@ func (a *Account) ToAccountResponse() *AccountResponse {
 
 // CreateAccountRequest represents create account request DTO
 type CreateAccountRequest struct {
-	Name       string `json:"name" binding:"required,min=3"`
-	CategoryID string `json:"category_id" binding:"required,uuid"`
-	Address    string `json:"address" binding:"omitempty"`
-	City       string `json:"city" binding:"omitempty"`
-	Province   string `json:"province" binding:"omitempty"`
-	Phone      string `json:"phone" binding:"omitempty"`
-	Email      string `json:"email" binding:"omitempty,email"`
-	Status     string `json:"status" binding:"omitempty,oneof=active inactive"`
-	AssignedTo string `json:"assigned_to" binding:"omitempty,uuid"`
+	Name             string `json:"name" binding:"required,min=3"`
+	CategoryID       string `json:"category_id" binding:"required,uuid"`
+	Address          string `json:"address" binding:"omitempty"`
+	City             string `json:"city" binding:"omitempty"`
+	Province         string `json:"province" binding:"omitempty"`
+	Phone            string `json:"phone" binding:"omitempty"`
+	Email            string `json:"email" binding:"omitempty,email"`
+	Status           string `json:"status" binding:"omitempty,oneof=active inactive"`
+	AssignedTo       string `json:"assigned_to" binding:"omitempty,uuid"`
+	IgnoreDuplicates bool   `json:"ignore_duplicates"` // Create even when similar accounts exist
 }
 
 // UpdateAccountRequest represents update account request DTO
@@ -140,3 +154,30 @@ type ListAccountsRequest struct {
 	AssignedTo string `form:"assigned_to" binding:"omitempty,uuid"`
 }
 
+// ExportColumns are the columns of an account export
+var ExportColumns = []export.Column{
+	{Key: "id", Header: "ID"},
+	{Key: "name", Header: "Name"},
+	{Key: "category", Header: "Category"},
+	{Key: "address", Header: "Address"},
+	{Key: "city", Header: "City"},
+	{Key: "province", Header: "Province"},
+	{Key: "phone", Header: "Phone"},
+	{Key: "email", Header: "Email"},
+	{Key: "status", Header: "Status"},
+	{Key: "assigned_to", Header: "Assigned To (User ID)"},
+	{Key: "created_at", Header: "Created At"},
+	{Key: "updated_at", Header: "Updated At"},
+}
+
+// ExportRow returns the account's values in the order of ExportColumns
+func (a *Account) ExportRow() []interface{} {
+	var category string
+	if a.Category != nil {
+		category = a.Category.Name
+	}
+	return []interface{}{
+		a.ID, a.Name, category, a.Address, a.City, a.Province, a.Phone, a.Email,
+		a.Status, a.AssignedTo, a.CreatedAt, a.UpdatedAt,
+	}
+}
@@ -3,6 +3,7 @@ package activity
 import (
 	"time"
 
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
@@ -136,3 +137,24 @@ type ActivityTimelineRequest struct {
 	Limit     int    `form:"limit" binding:"omitempty,min=1,max=100"`
 }
 
+// ExportColumns are the columns of an activity export
+var ExportColumns = []export.Column{
+	{Key: "id", Header: "ID"},
+	{Key: "type", Header: "Type"},
+	{Key: "timestamp", Header: "Timestamp"},
+	{Key: "description", Header: "Description"},
+	{Key: "user_id", Header: "User ID"},
+	{Key: "account_id", Header: "Account ID"},
+	{Key: "contact_id", Header: "Contact ID"},
+	{Key: "deal_id", Header: "Deal ID"},
+	{Key: "lead_id", Header: "Lead ID"},
+	{Key: "created_at", Header: "Created At"},
+}
+
+// ExportRow returns the activity's values in the order of ExportColumns
+func (a *Activity) ExportRow() []interface{} {
+	return []interface{}{
+		a.ID, a.Type, a.Timestamp, a.Description, a.UserID, a.AccountID, a.ContactID, a.DealID, a.LeadID,
+		a.CreatedAt,
+	}
+}
@@ -1,5 +1,12 @@
 package ai
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
 // InsightType represents type of AI insight
 type InsightType string
 
@@ -14,12 +21,55 @@ const (
 // VisitReportInsight represents AI insight for visit report
 type VisitReportInsight struct {
 	Summary     string   `json:"summary"`
-	ActionItems []string `json:"action_items"`
+	ActionItems []ActionItem `json:"action_items"`
 	Sentiment   string   `json:"sentiment"` // positive, neutral, negative
 	KeyPoints   []string `json:"key_points"`
 	Recommendations []string `json:"recommendations"`
 }
 
+// ActionItem represents a suggested next step from a visit report analysis
+type ActionItem struct {
+	ID               string     `json:"id"` // Stable ID derived from the visit report and item text
+	Text             string     `json:"text"`
+	Priority         string     `json:"priority"`           // low, medium, high, urgent
+	SuggestedDueDate *time.Time `json:"suggested_due_date"` // Suggested task due date
+}
+
+// ActionItemID returns the stable ID of an action item of a visit report.
+// The same text gives the same ID across analyses, so an item can be recognized when it is converted to a task.
+func ActionItemID(visitReportID string, text string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	sum := sha256.Sum256([]byte(visitReportID + "\n" + normalized))
+	return hex.EncodeToString(sum[:16])
+}
+
+// actionItemDueDays is the default number of days until an action item is due, by priority
+var actionItemDueDays = map[string]int{
+	"urgent": 1,
+	"high":   3,
+	"medium": 7,
+	"low":    14,
+}
+
+// NormalizeActionItemPriority returns a valid task priority, falling back to medium
+func NormalizeActionItemPriority(priority string) string {
+	priority = strings.ToLower(strings.TrimSpace(priority))
+	if _, ok := actionItemDueDays[priority]; !ok {
+		return "medium"
+	}
+	return priority
+}
+
+// SuggestedDueDate returns the end of the working day dueInDays after now.
+// Without a sensible dueInDays the number of days follows the priority.
+func SuggestedDueDate(priority string, dueInDays int, now time.Time) time.Time {
+	if dueInDays <= 0 || dueInDays > 90 {
+		dueInDays = actionItemDueDays[NormalizeActionItemPriority(priority)]
+	}
+	due := now.AddDate(0, 0, dueInDays)
+	return time.Date(due.Year(), due.Month(), due.Day(), 17, 0, 0, 0, due.Location())
+}
+
 // DealInsight represents AI insight for deal
 type DealInsight struct {
 	WinProbability    float64  `json:"win_probability"`
@@ -88,17 +138,45 @@ type ChatMessage struct {
 
 // ChatRequest represents chat request
 type ChatRequest struct {
-	Message         string        `json:"message" binding:"required,min=1"`
-	Context         string        `json:"context,omitempty"` // Optional context (visit_report_id, deal_id, etc.)
-	ContextType     string        `json:"context_type,omitempty"` // visit_report, deal, contact, account
-	ConversationHistory []ChatMessage `json:"conversation_history,omitempty"` // Previous messages in the conversation
-	Model           string        `json:"model,omitempty"` // Optional model override
+	Message        string `json:"message" binding:"required,min=1"`
+	ConversationID string `json:"conversation_id,omitempty" binding:"omitempty,uuid"` // Conversation to continue, a new one is started when empty
+	Context        string `json:"context,omitempty"` // Optional context (visit_report_id, deal_id, etc.)
+	ContextType    string `json:"context_type,omitempty"` // visit_report, deal, contact, account
+	Model          string `json:"model,omitempty"` // Optional model override
 }
 
 // ChatResponse represents chat response
 type ChatResponse struct {
+	ConversationID string `json:"conversation_id,omitempty"`
+	Message        string `json:"message"`
+	Tokens         int    `json:"tokens,omitempty"`
+	Model          string `json:"model,omitempty"`
+}
+
+// ChatStreamEventType represents type of event sent while streaming a chat response
+type ChatStreamEventType string
+
+const (
+	ChatStreamEventDelta ChatStreamEventType = "delta" // A chunk of the assistant message
+	ChatStreamEventDone  ChatStreamEventType = "done"  // Full message and token usage, sent once at the end
+	ChatStreamEventError ChatStreamEventType = "error" // Stream failed, no further events follow
+)
+
+// ChatStreamDelta represents a chunk of the assistant message
+type ChatStreamDelta struct {
+	Content string `json:"content"`
+}
+
+// ChatStreamError represents an error that ended a chat stream
+type ChatStreamError struct {
+	Code    string `json:"code"`
 	Message string `json:"message"`
-	Tokens  int    `json:"tokens,omitempty"`
+}
+
+// ChatStreamEvent represents a chat stream event sent over WebSocket
+type ChatStreamEvent struct {
+	Type ChatStreamEventType `json:"type"`
+	Data interface{}         `json:"data"`
 }
 
 // InsightResponse represents generic insight response
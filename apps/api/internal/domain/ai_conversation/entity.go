@@ -0,0 +1,139 @@
+package ai_conversation
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Message roles
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Conversation represents an AI chat conversation owned by a user
+type Conversation struct {
+	ID            string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID        string         `gorm:"type:uuid;not null;index" json:"user_id"`
+	Title         string         `gorm:"type:varchar(255);not null" json:"title"`
+	ContextID     string         `gorm:"type:varchar(100)" json:"context_id"`  // Optional record the chat is about (visit_report_id, deal_id, etc.)
+	ContextType   string         `gorm:"type:varchar(50)" json:"context_type"` // visit_report, deal, contact, account
+	Model         string         `gorm:"type:varchar(100)" json:"model"`       // Model of the latest answer
+	TotalTokens   int            `gorm:"type:integer;not null;default:0" json:"total_tokens"`
+	LastMessageAt time.Time      `gorm:"index" json:"last_message_at"`
+	Messages      []Message      `gorm:"foreignKey:ConversationID" json:"messages,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for Conversation
+func (Conversation) TableName() string {
+	return "ai_conversations"
+}
+
+// BeforeCreate hook to generate UUID
+func (c *Conversation) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Message represents a single message in an AI conversation
+type Message struct {
+	ID             string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ConversationID string    `gorm:"type:uuid;not null;index" json:"conversation_id"`
+	Role           string    `gorm:"type:varchar(20);not null" json:"role"` // user, assistant
+	Content        string    `gorm:"type:text;not null" json:"content"`
+	Tokens         int       `gorm:"type:integer;not null;default:0" json:"tokens"` // Tokens billed for an answer, estimated size for a question
+	Model          string    `gorm:"type:varchar(100)" json:"model"`                // Model that produced an answer
+	CreatedAt      time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName specifies the table name for Message
+func (Message) TableName() string {
+	return "ai_messages"
+}
+
+// BeforeCreate hook to generate UUID
+func (m *Message) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// ConversationResponse represents conversation response DTO
+type ConversationResponse struct {
+	ID            string            `json:"id"`
+	Title         string            `json:"title"`
+	ContextID     string            `json:"context_id,omitempty"`
+	ContextType   string            `json:"context_type,omitempty"`
+	Model         string            `json:"model"`
+	TotalTokens   int               `json:"total_tokens"`
+	LastMessageAt time.Time         `json:"last_message_at"`
+	Messages      []MessageResponse `json:"messages,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// ToConversationResponse converts Conversation to ConversationResponse
+func (c *Conversation) ToConversationResponse() *ConversationResponse {
+	resp := &ConversationResponse{
+		ID:            c.ID,
+		Title:         c.Title,
+		ContextID:     c.ContextID,
+		ContextType:   c.ContextType,
+		Model:         c.Model,
+		TotalTokens:   c.TotalTokens,
+		LastMessageAt: c.LastMessageAt,
+		CreatedAt:     c.CreatedAt,
+		UpdatedAt:     c.UpdatedAt,
+	}
+
+	if len(c.Messages) > 0 {
+		resp.Messages = make([]MessageResponse, len(c.Messages))
+		for i, m := range c.Messages {
+			resp.Messages[i] = *m.ToMessageResponse()
+		}
+	}
+
+	return resp
+}
+
+// MessageResponse represents conversation message response DTO
+type MessageResponse struct {
+	ID        string    `json:"id"`
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	Tokens    int       `json:"tokens"`
+	Model     string    `json:"model,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ToMessageResponse converts Message to MessageResponse
+func (m *Message) ToMessageResponse() *MessageResponse {
+	return &MessageResponse{
+		ID:        m.ID,
+		Role:      m.Role,
+		Content:   m.Content,
+		Tokens:    m.Tokens,
+		Model:     m.Model,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// ListConversationsRequest represents list conversations query parameters
+type ListConversationsRequest struct {
+	Page    int    `form:"page" binding:"omitempty,min=1"`
+	PerPage int    `form:"per_page" binding:"omitempty,min=1,max=100"`
+	Search  string `form:"search" binding:"omitempty"`
+}
+
+// UpdateConversationRequest represents rename conversation request DTO
+type UpdateConversationRequest struct {
+	Title string `json:"title" binding:"required,min=1,max=255"`
+}
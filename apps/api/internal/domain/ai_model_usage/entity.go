@@ -7,16 +7,27 @@ import (
 	"gorm.io/gorm"
 )
 
-// ModelUsage represents token usage per model
+// AI features that consume tokens
+const (
+	FeatureChat                = "chat"
+	FeatureVisitReportAnalysis = "visit_report_analysis"
+	FeatureDealInsight         = "deal_insight"
+	FeatureAccountInsight      = "account_insight"
+	FeaturePipelineInsight     = "pipeline_insight"
+)
+
+// ModelUsage represents daily token usage of a user per model and feature
 type ModelUsage struct {
-	ID          string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	Model       string    `gorm:"type:varchar(100);not null;index" json:"model"` // Model name (e.g., "llama-3.1-8b")
-	Tokens      int64     `gorm:"type:bigint;not null;default:0" json:"tokens"`   // Total tokens used for this model
-	RequestCount int64    `gorm:"type:bigint;not null;default:0" json:"request_count"` // Number of requests
-	LastUsedAt  time.Time `gorm:"type:timestamp" json:"last_used_at"`           // Last time this model was used
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID           string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID       string    `gorm:"type:uuid;not null;uniqueIndex:idx_ai_model_usage_daily,priority:1" json:"user_id"`
+	UsageDate    time.Time `gorm:"type:date;not null;uniqueIndex:idx_ai_model_usage_daily,priority:2;index" json:"usage_date"`
+	Model        string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_ai_model_usage_daily,priority:3" json:"model"`  // Model name (e.g., "llama-3.1-8b")
+	Feature      string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_ai_model_usage_daily,priority:4" json:"feature"` // chat, visit_report_analysis, deal_insight, ...
+	Tokens       int64     `gorm:"type:bigint;not null;default:0" json:"tokens"`                                             // Tokens used on this day
+	RequestCount int64     `gorm:"type:bigint;not null;default:0" json:"request_count"`                                      // Number of requests on this day
+	LastUsedAt   time.Time `gorm:"type:timestamp" json:"last_used_at"`                                                       // Last time this model was used
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 // TableName specifies the table name for ModelUsage
@@ -32,20 +43,43 @@ func (m *ModelUsage) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
-// ModelUsageResponse represents model usage response DTO
-type ModelUsageResponse struct {
-	Model        string    `json:"model"`
-	Tokens       int64     `json:"tokens"`
-	RequestCount int64     `json:"request_count"`
-	LastUsedAt   time.Time `json:"last_used_at"`
+// UsageReportRequest represents usage report query parameters
+type UsageReportRequest struct {
+	StartDate string `form:"start_date" binding:"omitempty,datetime=2006-01-02"`
+	EndDate   string `form:"end_date" binding:"omitempty,datetime=2006-01-02"`
+	UserID    string `form:"user_id" binding:"omitempty,uuid"`
 }
 
-// ToModelUsageResponse converts ModelUsage to ModelUsageResponse
-func (m *ModelUsage) ToModelUsageResponse() *ModelUsageResponse {
-	return &ModelUsageResponse{
-		Model:        m.Model,
-		Tokens:       m.Tokens,
-		RequestCount: m.RequestCount,
-		LastUsedAt:   m.LastUsedAt,
-	}
+// UserUsage represents token usage of a single user
+type UserUsage struct {
+	UserID       string `json:"user_id"`
+	UserName     string `json:"user_name"`
+	UserEmail    string `json:"user_email"`
+	Tokens       int64  `json:"tokens"`
+	RequestCount int64  `json:"request_count"`
+}
+
+// ModelUsageSummary represents token usage of a single model
+type ModelUsageSummary struct {
+	Model        string `json:"model"`
+	Tokens       int64  `json:"tokens"`
+	RequestCount int64  `json:"request_count"`
+}
+
+// FeatureUsage represents token usage of a single AI feature
+type FeatureUsage struct {
+	Feature      string `json:"feature"`
+	Tokens       int64  `json:"tokens"`
+	RequestCount int64  `json:"request_count"`
+}
+
+// UsageReportResponse represents usage report broken down by user, model and feature
+type UsageReportResponse struct {
+	StartDate     string              `json:"start_date"`
+	EndDate       string              `json:"end_date"`
+	TotalTokens   int64               `json:"total_tokens"`
+	TotalRequests int64               `json:"total_requests"`
+	ByUser        []UserUsage         `json:"by_user"`
+	ByModel       []ModelUsageSummary `json:"by_model"`
+	ByFeature     []FeatureUsage      `json:"by_feature"`
 }
@@ -11,17 +11,18 @@ import (
 
 // AISettings represents AI settings entity
 type AISettings struct {
-	ID          string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	Enabled     bool           `gorm:"type:boolean;not null;default:true" json:"enabled"`
-	Provider    string         `gorm:"type:varchar(50);not null;default:'cerebras'" json:"provider"` // cerebras, openai, anthropic, etc.
-	APIKey      string         `gorm:"type:text" json:"-"` // Hidden from JSON, stored encrypted
-	Model       string         `gorm:"type:varchar(100);not null;default:'llama-3.1-8b'" json:"model"`
-	BaseURL     string         `gorm:"type:text" json:"base_url,omitempty"` // Optional custom base URL
-	DataPrivacy datatypes.JSON `gorm:"type:jsonb" json:"data_privacy"`      // JSON object with data privacy settings
-	Timezone    string         `gorm:"type:varchar(50);default:'Asia/Jakarta'" json:"timezone"` // Timezone for AI context (e.g., "Asia/Jakarta", "UTC", "America/New_York")
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID              string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Enabled         bool           `gorm:"type:boolean;not null;default:true" json:"enabled"`
+	Provider        string         `gorm:"type:varchar(50);not null;default:'cerebras'" json:"provider"` // cerebras, openai, openai_compatible (Ollama, vLLM, ...)
+	APIKey          string         `gorm:"type:text" json:"-"` // Hidden from JSON, stored encrypted
+	Model           string         `gorm:"type:varchar(100);not null;default:'llama-3.1-8b'" json:"model"`
+	BaseURL         string         `gorm:"type:text" json:"base_url,omitempty"` // Optional custom base URL
+	DataPrivacy     datatypes.JSON `gorm:"type:jsonb" json:"data_privacy"`      // JSON object with data privacy settings
+	Timezone        string         `gorm:"type:varchar(50);default:'Asia/Jakarta'" json:"timezone"` // Timezone for AI context (e.g., "Asia/Jakarta", "UTC", "America/New_York")
+	RoleTokenQuotas datatypes.JSON `gorm:"type:jsonb" json:"role_token_quotas"` // Monthly token quota per user by role code, e.g. {"sales_rep": 200000}
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // TableName specifies the table name for AISettings
@@ -51,15 +52,16 @@ type DataPrivacySettings struct {
 
 // AISettingsResponse represents AI settings response DTO
 type AISettingsResponse struct {
-	ID          string              `json:"id"`
-	Enabled     bool                `json:"enabled"`
-	Provider    string              `json:"provider"`
-	Model       string              `json:"model"`
-	BaseURL     string              `json:"base_url,omitempty"`
-	DataPrivacy DataPrivacySettings `json:"data_privacy"`
-	Timezone    string              `json:"timezone"`
-	CreatedAt   time.Time           `json:"created_at"`
-	UpdatedAt   time.Time           `json:"updated_at"`
+	ID              string              `json:"id"`
+	Enabled         bool                `json:"enabled"`
+	Provider        string              `json:"provider"`
+	Model           string              `json:"model"`
+	BaseURL         string              `json:"base_url,omitempty"`
+	DataPrivacy     DataPrivacySettings `json:"data_privacy"`
+	Timezone        string              `json:"timezone"`
+	RoleTokenQuotas map[string]int64    `json:"role_token_quotas"` // Monthly token quota per user by role code; roles without a quota are unlimited
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
 }
 
 // ToAISettingsResponse converts AISettings to AISettingsResponse
@@ -78,26 +80,37 @@ func (a *AISettings) ToAISettingsResponse() *AISettingsResponse {
 	}
 
 	return &AISettingsResponse{
-		ID:          a.ID,
-		Enabled:     a.Enabled,
-		Provider:    a.Provider,
-		Model:       a.Model,
-		BaseURL:     a.BaseURL,
-		DataPrivacy: dataPrivacy,
-		Timezone:    timezone,
-		CreatedAt:   a.CreatedAt,
-		UpdatedAt:   a.UpdatedAt,
+		ID:              a.ID,
+		Enabled:         a.Enabled,
+		Provider:        a.Provider,
+		Model:           a.Model,
+		BaseURL:         a.BaseURL,
+		DataPrivacy:     dataPrivacy,
+		Timezone:        timezone,
+		RoleTokenQuotas: a.TokenQuotas(),
+		CreatedAt:       a.CreatedAt,
+		UpdatedAt:       a.UpdatedAt,
 	}
 }
 
 // UpdateAISettingsRequest represents update AI settings request DTO
 type UpdateAISettingsRequest struct {
-	Enabled     *bool              `json:"enabled" binding:"omitempty"`
-	Provider    string              `json:"provider" binding:"omitempty,oneof=cerebras openai anthropic"`
-	APIKey      string              `json:"api_key" binding:"omitempty"`
-	Model       string              `json:"model" binding:"omitempty"`
-	BaseURL     string              `json:"base_url" binding:"omitempty"`
-	DataPrivacy *DataPrivacySettings `json:"data_privacy" binding:"omitempty"`
-	Timezone    string              `json:"timezone" binding:"omitempty"` // Timezone string (e.g., "Asia/Jakarta", "UTC")
+	Enabled         *bool              `json:"enabled" binding:"omitempty"`
+	Provider        string              `json:"provider" binding:"omitempty,oneof=cerebras openai openai_compatible"`
+	APIKey          string              `json:"api_key" binding:"omitempty"`
+	Model           string              `json:"model" binding:"omitempty"`
+	BaseURL         string              `json:"base_url" binding:"omitempty"`
+	DataPrivacy     *DataPrivacySettings `json:"data_privacy" binding:"omitempty"`
+	Timezone        string              `json:"timezone" binding:"omitempty"` // Timezone string (e.g., "Asia/Jakarta", "UTC")
+	RoleTokenQuotas map[string]int64    `json:"role_token_quotas" binding:"omitempty,dive,keys,required,endkeys,min=0"` // Replaces all quotas; 0 removes the quota of a role
+}
+
+// TokenQuotas returns the monthly token quota per role code
+func (a *AISettings) TokenQuotas() map[string]int64 {
+	quotas := map[string]int64{}
+	if a.RoleTokenQuotas != nil {
+		_ = json.Unmarshal(a.RoleTokenQuotas, &quotas)
+	}
+	return quotas
 }
 
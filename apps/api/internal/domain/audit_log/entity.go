@@ -0,0 +1,111 @@
+package audit_log
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Audit actions
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+
+	// Domain state transitions
+	ActionMove     = "move"
+	ActionAssign   = "assign"
+	ActionComplete = "complete"
+	ActionStart    = "start"
+	ActionCheckIn  = "check_in"
+	ActionCheckOut = "check_out"
+	ActionApprove  = "approve"
+	ActionReject   = "reject"
+	ActionMerge    = "merge"
+	ActionImport   = "import"
+)
+
+// AuditLog represents an immutable record of a change made by a user
+type AuditLog struct {
+	ID           string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ActorID      *string        `gorm:"type:uuid;index" json:"actor_id"`
+	ActorEmail   string         `gorm:"type:varchar(255)" json:"actor_email"`
+	ActorRole    string         `gorm:"type:varchar(50)" json:"actor_role"`
+	RequestID    string         `gorm:"type:varchar(100);index" json:"request_id"`
+	Action       string         `gorm:"type:varchar(50);not null;index" json:"action"`                                // create, update, delete, or a domain action such as move/approve
+	ResourceType string         `gorm:"type:varchar(50);not null;index:idx_audit_logs_resource" json:"resource_type"` // account, contact, lead, deal, task, visit_report
+	ResourceID   string         `gorm:"type:varchar(100);not null;index:idx_audit_logs_resource" json:"resource_id"`
+	Changes      datatypes.JSON `gorm:"type:jsonb" json:"changes"` // map[field]{old,new}
+	IPAddress    string         `gorm:"type:varchar(45)" json:"ip_address"`
+	UserAgent    string         `gorm:"type:text" json:"user_agent"`
+	CreatedAt    time.Time      `gorm:"index" json:"created_at"`
+}
+
+// TableName specifies the table name for AuditLog
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// BeforeCreate hook to generate UUID
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// AuditLogResponse represents audit log response DTO
+type AuditLogResponse struct {
+	ID           string                          `json:"id"`
+	ActorID      *string                         `json:"actor_id"`
+	ActorEmail   string                          `json:"actor_email"`
+	ActorRole    string                          `json:"actor_role"`
+	RequestID    string                          `json:"request_id"`
+	Action       string                          `json:"action"`
+	ResourceType string                          `json:"resource_type"`
+	ResourceID   string                          `json:"resource_id"`
+	Changes      map[string]response.ChangeValue `json:"changes"`
+	IPAddress    string                          `json:"ip_address"`
+	UserAgent    string                          `json:"user_agent"`
+	CreatedAt    time.Time                       `json:"created_at"`
+}
+
+// ToAuditLogResponse converts AuditLog to AuditLogResponse
+func (a *AuditLog) ToAuditLogResponse() *AuditLogResponse {
+	changes := map[string]response.ChangeValue{}
+	if len(a.Changes) > 0 {
+		_ = json.Unmarshal(a.Changes, &changes)
+	}
+
+	return &AuditLogResponse{
+		ID:           a.ID,
+		ActorID:      a.ActorID,
+		ActorEmail:   a.ActorEmail,
+		ActorRole:    a.ActorRole,
+		RequestID:    a.RequestID,
+		Action:       a.Action,
+		ResourceType: a.ResourceType,
+		ResourceID:   a.ResourceID,
+		Changes:      changes,
+		IPAddress:    a.IPAddress,
+		UserAgent:    a.UserAgent,
+		CreatedAt:    a.CreatedAt,
+	}
+}
+
+// ListAuditLogsRequest represents list audit logs query parameters
+type ListAuditLogsRequest struct {
+	Page         int    `form:"page" binding:"omitempty,min=1"`
+	PerPage      int    `form:"per_page" binding:"omitempty,min=1,max=100"`
+	ActorID      string `form:"actor_id" binding:"omitempty,uuid"`
+	Action       string `form:"action" binding:"omitempty"`
+	ResourceType string `form:"resource_type" binding:"omitempty"`
+	ResourceID   string `form:"resource_id" binding:"omitempty"`
+	RequestID    string `form:"request_id" binding:"omitempty"`
+	StartDate    string `form:"start_date" binding:"omitempty"` // YYYY-MM-DD
+	EndDate      string `form:"end_date" binding:"omitempty"`   // YYYY-MM-DD
+}
@@ -0,0 +1,79 @@
+package competitor
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Competitor represents a company that deals are lost to
+type Competitor struct {
+	ID          string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name        string         `gorm:"type:varchar(255);uniqueIndex;not null" json:"name"`
+	Website     string         `gorm:"type:varchar(255)" json:"website"`
+	Description string         `gorm:"type:text" json:"description"`
+	Status      string         `gorm:"type:varchar(20);not null;default:'active'" json:"status"` // Only active competitors can be chosen
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for Competitor
+func (Competitor) TableName() string {
+	return "competitors"
+}
+
+// BeforeCreate hook to generate UUID
+func (c *Competitor) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// CompetitorResponse represents competitor response DTO
+type CompetitorResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Website     string    `json:"website"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ToCompetitorResponse converts Competitor to CompetitorResponse
+func (c *Competitor) ToCompetitorResponse() *CompetitorResponse {
+	return &CompetitorResponse{
+		ID:          c.ID,
+		Name:        c.Name,
+		Website:     c.Website,
+		Description: c.Description,
+		Status:      c.Status,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+// CreateCompetitorRequest represents create competitor request DTO
+type CreateCompetitorRequest struct {
+	Name        string `json:"name" binding:"required,min=2,max=255"`
+	Website     string `json:"website" binding:"omitempty,url,max=255"`
+	Description string `json:"description"`
+	Status      string `json:"status" binding:"omitempty,oneof=active inactive"`
+}
+
+// UpdateCompetitorRequest represents update competitor request DTO
+type UpdateCompetitorRequest struct {
+	Name        string `json:"name" binding:"omitempty,min=2,max=255"`
+	Website     string `json:"website" binding:"omitempty,url,max=255"`
+	Description string `json:"description"`
+	Status      string `json:"status" binding:"omitempty,oneof=active inactive"`
+}
+
+// ListCompetitorsRequest represents list competitors query parameters
+type ListCompetitorsRequest struct {
+	Search string `form:"search" binding:"omitempty"`
+	Status string `form:"status" binding:"omitempty,oneof=active inactive"`
+}
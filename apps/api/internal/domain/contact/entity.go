@@ -3,6 +3,8 @@ package contact
 import (
 	"time"
 
+	"github.com/gilabs/crm-healthcare/api/internal/domain/duplicate"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -46,6 +48,17 @@ func (c *Contact) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// DuplicateRecord returns the attributes compared when looking for duplicate contacts
+func (c *Contact) DuplicateRecord() duplicate.Record {
+	return duplicate.Record{
+		ID:        c.ID,
+		Name:      c.Name,
+		Email:     c.Email,
+		Phone:     c.Phone,
+		AccountID: c.AccountID,
+	}
+}
+
 // ContactResponse represents contact response DTO
 type ContactResponse struct {
 	ID        string    `json:"id"`
@@ -100,13 +113,14 @@ func (c *Contact) ToContactResponse() *ContactResponse {
 
 // CreateContactRequest represents create contact request DTO
 type CreateContactRequest struct {
-	AccountID string `json:"account_id" binding:"required,uuid"`
-	Name      string `json:"name" binding:"required,min=3"`
-	RoleID    string `json:"role_id" binding:"required,uuid"`
-	Phone     string `json:"phone" binding:"omitempty"`
-	Email     string `json:"email" binding:"omitempty,email"`
-	Position  string `json:"position" binding:"omitempty"`
-	Notes     string `json:"notes" binding:"omitempty"`
+	AccountID        string `json:"account_id" binding:"required,uuid"`
+	Name             string `json:"name" binding:"required,min=3"`
+	RoleID           string `json:"role_id" binding:"required,uuid"`
+	Phone            string `json:"phone" binding:"omitempty"`
+	Email            string `json:"email" binding:"omitempty,email"`
+	Position         string `json:"position" binding:"omitempty"`
+	Notes            string `json:"notes" binding:"omitempty"`
+	IgnoreDuplicates bool   `json:"ignore_duplicates"` // Create even when similar contacts exist
 }
 
 // UpdateContactRequest represents update contact request DTO
@@ -129,3 +143,28 @@ type ListContactsRequest struct {
 	RoleID    string `form:"role_id" binding:"omitempty,uuid"`
 }
 
+// ExportColumns are the columns of a contact export
+var ExportColumns = []export.Column{
+	{Key: "id", Header: "ID"},
+	{Key: "account_id", Header: "Account ID"},
+	{Key: "name", Header: "Name"},
+	{Key: "role", Header: "Role"},
+	{Key: "phone", Header: "Phone"},
+	{Key: "email", Header: "Email"},
+	{Key: "position", Header: "Position"},
+	{Key: "notes", Header: "Notes"},
+	{Key: "created_at", Header: "Created At"},
+	{Key: "updated_at", Header: "Updated At"},
+}
+
+// ExportRow returns the contact's values in the order of ExportColumns
+func (c *Contact) ExportRow() []interface{} {
+	var role string
+	if c.Role != nil {
+		role = c.Role.Name
+	}
+	return []interface{}{
+		c.ID, c.AccountID, c.Name, role, c.Phone, c.Email, c.Position, c.Notes,
+		c.CreatedAt, c.UpdatedAt,
+	}
+}
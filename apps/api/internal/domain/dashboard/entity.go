@@ -1,6 +1,10 @@
 package dashboard
 
-import "time"
+import (
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/sales_target"
+)
 
 // DashboardOverviewResponse represents dashboard overview data
 type DashboardOverviewResponse struct {
@@ -187,6 +191,8 @@ type TopSalesRepResponse struct {
 	VisitCount    int     `json:"visit_count"`
 	AccountCount  int     `json:"account_count"`
 	ActivityCount int     `json:"activity_count"`
+	// Attainment is set when the sales rep has a target in the period
+	Attainment *sales_target.Attainment `json:"attainment,omitempty"`
 }
 
 // RecentActivityResponse represents recent activity data
@@ -229,9 +235,10 @@ type ActivityDateStat struct {
 
 // DashboardRequest represents request parameters for dashboard
 type DashboardRequest struct {
-	StartDate string `form:"start_date"`
-	EndDate   string `form:"end_date"`
-	Period    string `form:"period"` // today, week, month, year
-	Limit     int    `form:"limit"`
+	StartDate  string `form:"start_date"`
+	EndDate    string `form:"end_date"`
+	Period     string `form:"period"` // today, week, month, year
+	Limit      int    `form:"limit"`
+	PipelineID string `form:"pipeline_id" binding:"omitempty,uuid"` // Pipeline summary of one pipeline; all pipelines when empty
 }
 
@@ -0,0 +1,318 @@
+package duplicate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Entity types checked for duplicates
+const (
+	EntityLead    = "lead"
+	EntityAccount = "account"
+	EntityContact = "contact"
+)
+
+// Match reasons
+const (
+	ReasonEmail = "email" // Same normalized email
+	ReasonPhone = "phone" // Same normalized phone number
+	ReasonName  = "name"  // Similar name in the same city, company or account
+)
+
+// NameThreshold is the minimum name similarity (0-1) for a fuzzy name match
+const NameThreshold = 0.85
+
+// CandidateLimit caps the number of records prefiltered in the database for one check
+const CandidateLimit = 500
+
+// Match scores
+const (
+	scoreEmail    = 100
+	scorePhone    = 95
+	scoreName     = 80 // Scaled by the name similarity
+	scoreSameCity = 10 // Added to name matches when both cities are known and equal
+)
+
+// honorifics are titles and legal forms dropped before comparing names
+var honorifics = map[string]bool{
+	"dr": true, "drg": true, "dra": true, "drs": true, "prof": true, "ir": true,
+	"h": true, "hj": true, "bpk": true, "ibu": true, "pt": true, "cv": true, "tbk": true,
+}
+
+// genericTokens are too common in healthcare names to prefilter candidates on
+var genericTokens = map[string]bool{
+	"rs": true, "rsu": true, "rsud": true, "rsia": true, "klinik": true, "apotek": true,
+	"puskesmas": true, "medika": true, "medical": true, "hospital": true, "clinic": true,
+	"pharmacy": true, "farma": true, "husada": true,
+}
+
+// cityPrefixes are administrative prefixes dropped before comparing cities
+var cityPrefixes = []string{"kota administrasi ", "kabupaten ", "kota ", "kab. ", "kab "}
+
+// Record represents the attributes of a lead, account or contact compared for duplicates
+type Record struct {
+	ID        string
+	Name      string
+	Company   string // Leads only: company name
+	Email     string
+	Phone     string
+	City      string
+	AccountID string // Contacts only: account the contact belongs to
+}
+
+// Match represents an existing record that may duplicate another
+type Match struct {
+	EntityType string   `json:"entity_type"`
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Email      string   `json:"email"`
+	Phone      string   `json:"phone"`
+	City       string   `json:"city,omitempty"`
+	Score      int      `json:"score"`   // 0-100; 100 is a certain duplicate
+	Reasons    []string `json:"reasons"` // email, phone, name
+}
+
+// FoundError is returned when a record being created matches existing records
+type FoundError struct {
+	EntityType string
+	Matches    []Match
+}
+
+func (e *FoundError) Error() string {
+	return fmt.Sprintf("%d possible duplicate %s record(s) found", len(e.Matches), e.EntityType)
+}
+
+// Found returns a FoundError listing the matches, or nil when there are none
+func Found(entityType string, matches []Match) error {
+	if len(matches) == 0 {
+		return nil
+	}
+	return &FoundError{EntityType: entityType, Matches: matches}
+}
+
+// Query represents the normalized values used to prefilter duplicate candidates in the database
+type Query struct {
+	ExcludeID   string
+	Email       string
+	PhoneSuffix string   // Last 9 digits of the normalized phone; matches numbers stored in any format
+	NameKeys    []string // Distinctive lowercase name tokens
+	AccountID   string
+}
+
+// NewQuery builds the candidate query for a record
+func NewQuery(r Record) Query {
+	q := Query{
+		ExcludeID: r.ID,
+		Email:     NormalizeEmail(r.Email),
+		AccountID: r.AccountID,
+	}
+	if phone := NormalizePhone(r.Phone); len(phone) >= 9 {
+		q.PhoneSuffix = phone[len(phone)-9:]
+	}
+	for _, token := range strings.Fields(NormalizeName(r.Name)) {
+		if len([]rune(token)) >= 3 && !genericTokens[token] {
+			q.NameKeys = append(q.NameKeys, token)
+		}
+	}
+	return q
+}
+
+// NormalizeEmail lowercases and trims an email address
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizePhone reduces an Indonesian phone number to its national format (0812..., 021...),
+// so +62 812-3456-789, 62812 3456 789 and 0812.3456.789 compare equal.
+// Numbers too short to identify a line return an empty string.
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	digits := b.String()
+
+	digits = strings.TrimPrefix(digits, "00") // International dialing prefix
+	if strings.HasPrefix(digits, "62") {
+		digits = digits[2:]
+	}
+	if digits != "" && !strings.HasPrefix(digits, "0") {
+		digits = "0" + digits
+	}
+	if len(digits) < 8 {
+		return ""
+	}
+	return digits
+}
+
+// NormalizeName lowercases a name, drops academic titles after the first comma, punctuation,
+// honorifics and legal forms, and spells "rumah sakit" as "rs"
+func NormalizeName(name string) string {
+	if i := strings.Index(name, ","); i > 0 {
+		name = name[:i]
+	}
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, name)
+	name = strings.Join(strings.Fields(name), " ")
+	name = strings.ReplaceAll(" "+name+" ", " rumah sakit ", " rs ")
+
+	var tokens []string
+	for _, token := range strings.Fields(name) {
+		if !honorifics[token] {
+			tokens = append(tokens, token)
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+// NormalizeCity lowercases a city and drops administrative prefixes such as "Kota" and "Kabupaten"
+func NormalizeCity(city string) string {
+	city = strings.Join(strings.Fields(strings.ToLower(city)), " ")
+	for _, prefix := range cityPrefixes {
+		if strings.HasPrefix(city, prefix) {
+			return strings.TrimSpace(city[len(prefix):])
+		}
+	}
+	return city
+}
+
+// NameSimilarity returns the similarity (0-1) of two names after normalization, ignoring word order
+func NameSimilarity(a string, b string) float64 {
+	a, b = sortedTokens(NormalizeName(a)), sortedTokens(NormalizeName(b))
+	if a == "" || b == "" {
+		return 0
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// Compare scores how likely a candidate duplicates the subject and lists the reasons; 0 when it does not
+func Compare(subject Record, candidate Record) (int, []string) {
+	score := 0
+	var reasons []string
+	add := func(reason string, points int) {
+		reasons = append(reasons, reason)
+		if points > score {
+			score = points
+		}
+	}
+
+	if email := NormalizeEmail(subject.Email); email != "" && email == NormalizeEmail(candidate.Email) {
+		add(ReasonEmail, scoreEmail)
+	}
+	if phone := NormalizePhone(subject.Phone); phone != "" && phone == NormalizePhone(candidate.Phone) {
+		add(ReasonPhone, scorePhone)
+	}
+	if points, ok := nameMatch(subject, candidate); ok {
+		add(ReasonName, points)
+	}
+	return score, reasons
+}
+
+// nameMatch reports whether two records have similar names and do not differ in city, company or account
+func nameMatch(subject Record, candidate Record) (int, bool) {
+	if subject.AccountID != "" && candidate.AccountID != "" && subject.AccountID != candidate.AccountID {
+		return 0, false
+	}
+	if subject.Company != "" && candidate.Company != "" && NameSimilarity(subject.Company, candidate.Company) < NameThreshold {
+		return 0, false
+	}
+	cityA, cityB := NormalizeCity(subject.City), NormalizeCity(candidate.City)
+	if cityA != "" && cityB != "" && cityA != cityB {
+		return 0, false
+	}
+
+	similarity := NameSimilarity(subject.Name, candidate.Name)
+	if similarity < NameThreshold {
+		return 0, false
+	}
+	points := int(similarity * scoreName)
+	if cityA != "" && cityA == cityB {
+		points += scoreSameCity
+	}
+	return points, true
+}
+
+// FindMatches compares the subject with each candidate and returns the matches, most likely first
+func FindMatches(entityType string, subject Record, candidates []Record) []Match {
+	matches := make([]Match, 0)
+	for _, c := range candidates {
+		if c.ID == subject.ID {
+			continue
+		}
+		score, reasons := Compare(subject, c)
+		if len(reasons) == 0 {
+			continue
+		}
+		matches = append(matches, Match{
+			EntityType: entityType,
+			ID:         c.ID,
+			Name:       c.Name,
+			Email:      c.Email,
+			Phone:      c.Phone,
+			City:       c.City,
+			Score:      score,
+			Reasons:    reasons,
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+// sortedTokens returns the words of a name in alphabetical order
+func sortedTokens(name string) string {
+	tokens := strings.Fields(name)
+	sort.Strings(tokens)
+	return strings.Join(tokens, " ")
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a []rune, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// CheckDuplicatesRequest represents a duplicate check for a record that is not saved yet
+type CheckDuplicatesRequest struct {
+	EntityType string `json:"entity_type" binding:"required,oneof=lead account contact"`
+	Name       string `json:"name" binding:"omitempty,max=255"`
+	Company    string `json:"company" binding:"omitempty,max=255"`
+	Email      string `json:"email" binding:"omitempty,max=255"`
+	Phone      string `json:"phone" binding:"omitempty,max=30"`
+	City       string `json:"city" binding:"omitempty,max=100"`
+	AccountID  string `json:"account_id" binding:"omitempty,uuid"`
+}
+
+// MergeRequest represents a merge of a duplicate record into the record in the URL, which survives
+type MergeRequest struct {
+	DuplicateID string `json:"duplicate_id" binding:"required,uuid"`
+}
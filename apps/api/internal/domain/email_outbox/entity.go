@@ -0,0 +1,116 @@
+package email_outbox
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Outbox statuses
+const (
+	StatusPending = "pending" // Waiting for its next attempt
+	StatusSent    = "sent"
+	StatusFailed  = "failed" // Gave up after the last attempt or on a permanent error
+)
+
+// EmailMessage represents an email queued for delivery. The worker claims due messages, sends them
+// and retries failures with an increasing delay until MaxAttempts is reached.
+type EmailMessage struct {
+	ID            string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID        *string    `gorm:"type:uuid;index" json:"user_id"` // Recipient user
+	Recipient     string     `gorm:"type:varchar(255);not null" json:"recipient"`
+	Template      string     `gorm:"type:varchar(50);not null" json:"template"` // Notification type the email was rendered from
+	Subject       string     `gorm:"type:varchar(255);not null" json:"subject"`
+	TextBody      string     `gorm:"type:text;not null" json:"text_body"`
+	HTMLBody      string     `gorm:"type:text" json:"html_body"`
+	Status        string     `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"` // pending, sent, failed
+	Attempts      int        `gorm:"type:integer;not null;default:0" json:"attempts"`
+	MaxAttempts   int        `gorm:"type:integer;not null" json:"max_attempts"`
+	NextAttemptAt time.Time  `gorm:"type:timestamp;not null;index" json:"next_attempt_at"`
+	LastError     string     `gorm:"type:text" json:"last_error"`
+	SentAt        *time.Time `gorm:"type:timestamp" json:"sent_at"`
+	ReminderID    *string    `gorm:"type:uuid;index" json:"reminder_id"` // Reminder whose delivery status follows this email
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for EmailMessage
+func (EmailMessage) TableName() string {
+	return "email_outbox"
+}
+
+// BeforeCreate hook to generate UUID
+func (m *EmailMessage) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Detail is a labelled value listed in a notification email
+type Detail struct {
+	Label string
+	Value string
+}
+
+// NotificationEmail is a notification to render and queue for a user
+type NotificationEmail struct {
+	UserID     string
+	Type       string // reminder, task, deal, activity, lead
+	Title      string
+	Message    string
+	Details    []Detail
+	ReminderID *string
+}
+
+// EmailMessageResponse represents email outbox response DTO; the bodies are left out
+type EmailMessageResponse struct {
+	ID            string     `json:"id"`
+	UserID        *string    `json:"user_id"`
+	Recipient     string     `json:"recipient"`
+	Template      string     `json:"template"`
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	MaxAttempts   int        `json:"max_attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at"` // Nil once sent or failed
+	LastError     string     `json:"last_error,omitempty"`
+	SentAt        *time.Time `json:"sent_at"`
+	ReminderID    *string    `json:"reminder_id,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// ToEmailMessageResponse converts EmailMessage to EmailMessageResponse
+func (m *EmailMessage) ToEmailMessageResponse() *EmailMessageResponse {
+	resp := &EmailMessageResponse{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		Recipient:   m.Recipient,
+		Template:    m.Template,
+		Subject:     m.Subject,
+		Status:      m.Status,
+		Attempts:    m.Attempts,
+		MaxAttempts: m.MaxAttempts,
+		LastError:   m.LastError,
+		SentAt:      m.SentAt,
+		ReminderID:  m.ReminderID,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+	if m.Status == StatusPending {
+		next := m.NextAttemptAt
+		resp.NextAttemptAt = &next
+	}
+	return resp
+}
+
+// ListEmailMessagesRequest represents list email outbox query parameters
+type ListEmailMessagesRequest struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PerPage  int    `form:"per_page" binding:"omitempty,min=1,max=100"`
+	Status   string `form:"status" binding:"omitempty,oneof=pending sent failed"`
+	Template string `form:"template" binding:"omitempty,oneof=reminder task deal activity lead"`
+	UserID   string `form:"user_id" binding:"omitempty,uuid"`
+}
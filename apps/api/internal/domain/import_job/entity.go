@@ -0,0 +1,362 @@
+package import_job
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Entity types that can be imported
+const (
+	EntityAccount = "account"
+	EntityContact = "contact"
+	EntityLead    = "lead"
+	EntityProduct = "product"
+)
+
+// Import job statuses
+const (
+	StatusNeedsMapping = "needs_mapping" // Parsed, but required fields are not mapped to columns yet
+	StatusValidated    = "validated"     // Parsed and dry-run validated; waiting to be committed
+	StatusImporting    = "importing"     // Committed; rows are being written in the background
+	StatusCompleted    = "completed"
+	StatusFailed       = "failed"
+)
+
+// Duplicate modes decide what happens to rows matching an existing record
+const (
+	DuplicateSkip   = "skip"   // Leave the existing record untouched
+	DuplicateUpsert = "upsert" // Overwrite the existing record with the non-empty row values
+)
+
+// Duplicate actions reported per row
+const (
+	ActionSkip   = "skip"
+	ActionUpdate = "update"
+)
+
+// Import limits
+const (
+	MaxFileSize       = 10 * 1024 * 1024 // 10MB
+	MaxRows           = 5000
+	MaxReportedIssues = 500 // Row errors and duplicates kept in the report
+)
+
+// UpsertMinScore is the minimum duplicate score for a row to update an existing record.
+// Email and phone matches and exact names in the same city qualify; weaker fuzzy name matches are skipped.
+const UpsertMinScore = 90
+
+// ImportJob represents an uploaded CSV or XLSX file imported into accounts, contacts, leads or products
+type ImportJob struct {
+	ID            string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EntityType    string         `gorm:"type:varchar(20);not null;index" json:"entity_type"`
+	FileName      string         `gorm:"type:varchar(255);not null" json:"file_name"`
+	Status        string         `gorm:"type:varchar(20);not null;default:'validated';index" json:"status"`
+	DuplicateMode string         `gorm:"type:varchar(20);not null;default:'skip'" json:"duplicate_mode"`
+	Headers       datatypes.JSON `gorm:"type:jsonb" json:"-"` // Column headers ([]string)
+	Mapping       datatypes.JSON `gorm:"type:jsonb" json:"-"` // Field key to column header (map[string]string)
+	Rows          datatypes.JSON `gorm:"type:jsonb" json:"-"` // Parsed data rows ([]Row)
+	TotalRows     int            `gorm:"type:integer;not null;default:0" json:"total_rows"`
+	ValidRows     int            `gorm:"type:integer;not null;default:0" json:"valid_rows"`
+	InvalidRows   int            `gorm:"type:integer;not null;default:0" json:"invalid_rows"`
+	DuplicateRows int            `gorm:"type:integer;not null;default:0" json:"duplicate_rows"`
+	ProcessedRows int            `gorm:"type:integer;not null;default:0" json:"processed_rows"`
+	CreatedCount  int            `gorm:"type:integer;not null;default:0" json:"created_count"`
+	UpdatedCount  int            `gorm:"type:integer;not null;default:0" json:"updated_count"`
+	SkippedCount  int            `gorm:"type:integer;not null;default:0" json:"skipped_count"`
+	FailedCount   int            `gorm:"type:integer;not null;default:0" json:"failed_count"`
+	Errors        datatypes.JSON `gorm:"type:jsonb" json:"-"`            // Row-level errors ([]RowError)
+	Duplicates    datatypes.JSON `gorm:"type:jsonb" json:"-"`            // Rows matching existing records ([]RowDuplicate)
+	ErrorMessage  string         `gorm:"type:text" json:"error_message"` // Set when the whole job failed
+	CreatedBy     string         `gorm:"type:uuid;not null;index" json:"created_by"`
+	StartedAt     *time.Time     `json:"started_at"`
+	CompletedAt   *time.Time     `json:"completed_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for ImportJob
+func (ImportJob) TableName() string {
+	return "import_jobs"
+}
+
+// BeforeCreate hook to generate UUID
+func (j *ImportJob) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == "" {
+		j.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Row represents a non-blank data row of the uploaded file
+type Row struct {
+	Line  int      `json:"line"` // Line or sheet row number in the file; the header is line 1
+	Cells []string `json:"cells"`
+}
+
+// RowError represents a problem that keeps a row from being imported
+type RowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// RowDuplicate represents a row matching an existing record or an earlier row of the file
+type RowDuplicate struct {
+	Row       int    `json:"row"`
+	MatchID   string `json:"match_id,omitempty"`  // Existing record
+	MatchRow  int    `json:"match_row,omitempty"` // Earlier row of the same file
+	MatchName string `json:"match_name,omitempty"`
+	Score     int    `json:"score"`
+	Action    string `json:"action"` // skip or update
+}
+
+// Field represents an importable field of an entity
+type Field struct {
+	Key      string   `json:"key"`
+	Label    string   `json:"label"`
+	Required bool     `json:"required"`
+	Hint     string   `json:"hint,omitempty"`
+	Aliases  []string `json:"-"` // Other column headers mapped to the field automatically
+}
+
+// Fields lists the importable fields of each entity type
+var Fields = map[string][]Field{
+	EntityAccount: {
+		{Key: "name", Label: "Name", Required: true, Aliases: []string{"account name", "account", "nama"}},
+		{Key: "category", Label: "Category", Required: true, Hint: "Category name or code", Aliases: []string{"category code", "kategori"}},
+		{Key: "address", Label: "Address", Aliases: []string{"alamat"}},
+		{Key: "city", Label: "City", Aliases: []string{"kota"}},
+		{Key: "province", Label: "Province", Aliases: []string{"provinsi"}},
+		{Key: "phone", Label: "Phone", Aliases: []string{"telepon", "phone number"}},
+		{Key: "email", Label: "Email"},
+		{Key: "status", Label: "Status", Hint: "active or inactive"},
+		{Key: "assigned_to", Label: "Assigned To", Hint: "Email of the sales rep", Aliases: []string{"owner", "sales rep"}},
+	},
+	EntityContact: {
+		{Key: "account", Label: "Account", Required: true, Hint: "Account name or ID", Aliases: []string{"account name", "account id"}},
+		{Key: "name", Label: "Name", Required: true, Aliases: []string{"contact name", "nama"}},
+		{Key: "role", Label: "Role", Required: true, Hint: "Contact role name or code", Aliases: []string{"role code"}},
+		{Key: "phone", Label: "Phone", Aliases: []string{"telepon", "phone number"}},
+		{Key: "email", Label: "Email"},
+		{Key: "position", Label: "Position", Aliases: []string{"jabatan", "title"}},
+		{Key: "notes", Label: "Notes", Aliases: []string{"catatan"}},
+	},
+	EntityLead: {
+		{Key: "first_name", Label: "First Name", Required: true},
+		{Key: "last_name", Label: "Last Name"},
+		{Key: "company_name", Label: "Company Name", Aliases: []string{"company"}},
+		{Key: "email", Label: "Email", Required: true},
+		{Key: "phone", Label: "Phone", Aliases: []string{"telepon", "phone number"}},
+		{Key: "job_title", Label: "Job Title", Aliases: []string{"title", "jabatan"}},
+		{Key: "industry", Label: "Industry"},
+		{Key: "lead_source", Label: "Lead Source", Required: true, Hint: "website, referral, cold_call, event, social_media, email_campaign, partner or other", Aliases: []string{"source"}},
+		{Key: "lead_status", Label: "Lead Status", Aliases: []string{"status"}},
+		{Key: "notes", Label: "Notes", Aliases: []string{"catatan"}},
+		{Key: "address", Label: "Address", Aliases: []string{"alamat"}},
+		{Key: "city", Label: "City", Aliases: []string{"kota"}},
+		{Key: "province", Label: "Province", Aliases: []string{"provinsi"}},
+		{Key: "postal_code", Label: "Postal Code", Aliases: []string{"zip", "zip code", "kode pos"}},
+		{Key: "country", Label: "Country"},
+		{Key: "website", Label: "Website"},
+		{Key: "assigned_to", Label: "Assigned To", Hint: "Email of the sales rep", Aliases: []string{"owner", "sales rep"}},
+	},
+	EntityProduct: {
+		{Key: "name", Label: "Name", Required: true, Aliases: []string{"product name", "nama"}},
+		{Key: "sku", Label: "SKU", Required: true},
+		{Key: "barcode", Label: "Barcode"},
+		{Key: "price", Label: "Price", Required: true, Hint: "In rupiah", Aliases: []string{"harga"}},
+		{Key: "cost", Label: "Cost", Hint: "In rupiah"},
+		{Key: "stock", Label: "Stock", Aliases: []string{"stok"}},
+		{Key: "category", Label: "Category", Required: true, Hint: "Product category name or slug", Aliases: []string{"kategori"}},
+		{Key: "status", Label: "Status", Hint: "active or inactive"},
+		{Key: "taxable", Label: "Taxable", Hint: "yes or no"},
+		{Key: "description", Label: "Description", Aliases: []string{"deskripsi"}},
+	},
+}
+
+// IsEntityType reports whether entity type can be imported
+func IsEntityType(entityType string) bool {
+	_, ok := Fields[entityType]
+	return ok
+}
+
+// AutoMapping maps each field to the first column whose header matches the field key, label or an alias,
+// ignoring case, spaces and punctuation
+func AutoMapping(entityType string, headers []string) map[string]string {
+	mapping := make(map[string]string)
+	used := make(map[string]bool)
+	for _, field := range Fields[entityType] {
+		names := map[string]bool{headerKey(field.Key): true, headerKey(field.Label): true}
+		for _, alias := range field.Aliases {
+			names[headerKey(alias)] = true
+		}
+		for _, header := range headers {
+			if !used[header] && names[headerKey(header)] {
+				mapping[field.Key] = header
+				used[header] = true
+				break
+			}
+		}
+	}
+	return mapping
+}
+
+// MappingProblems lists why a mapping cannot be used: unknown fields, missing columns and unmapped required fields
+func MappingProblems(entityType string, mapping map[string]string, headers []string) []string {
+	known := make(map[string]bool)
+	for _, field := range Fields[entityType] {
+		known[field.Key] = true
+	}
+	columns := make(map[string]bool)
+	for _, header := range headers {
+		columns[header] = true
+	}
+
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	problems := make([]string, 0)
+	for _, key := range keys {
+		header := mapping[key]
+		if !known[key] {
+			problems = append(problems, "unknown field "+key)
+		} else if header != "" && !columns[header] {
+			problems = append(problems, "column "+header+" mapped to "+key+" is not in the file")
+		}
+	}
+	for _, field := range Fields[entityType] {
+		if field.Required && mapping[field.Key] == "" {
+			problems = append(problems, "required field "+field.Key+" is not mapped")
+		}
+	}
+	return problems
+}
+
+// Values returns the trimmed cell values of a row keyed by field, following the mapping
+func Values(mapping map[string]string, headers []string, row Row) map[string]string {
+	index := make(map[string]int, len(headers))
+	for i, header := range headers {
+		index[header] = i
+	}
+	values := make(map[string]string, len(mapping))
+	for key, header := range mapping {
+		if i, ok := index[header]; ok && i < len(row.Cells) {
+			values[key] = strings.TrimSpace(row.Cells[i])
+		}
+	}
+	return values
+}
+
+// headerKey lowercases a header and drops spaces and punctuation, so "First Name" and "first_name" compare equal
+func headerKey(header string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, header)
+}
+
+// ImportJobResponse represents import job response DTO
+type ImportJobResponse struct {
+	ID              string            `json:"id"`
+	EntityType      string            `json:"entity_type"`
+	FileName        string            `json:"file_name"`
+	Status          string            `json:"status"`
+	DuplicateMode   string            `json:"duplicate_mode"`
+	Headers         []string          `json:"headers"`
+	Mapping         map[string]string `json:"mapping"`
+	TotalRows       int               `json:"total_rows"`
+	ValidRows       int               `json:"valid_rows"`
+	InvalidRows     int               `json:"invalid_rows"`
+	DuplicateRows   int               `json:"duplicate_rows"`
+	ProcessedRows   int               `json:"processed_rows"`
+	CreatedCount    int               `json:"created_count"`
+	UpdatedCount    int               `json:"updated_count"`
+	SkippedCount    int               `json:"skipped_count"`
+	FailedCount     int               `json:"failed_count"`
+	Errors          []RowError        `json:"errors"`
+	Duplicates      []RowDuplicate    `json:"duplicates"`
+	ErrorMessage    string            `json:"error_message,omitempty"`
+	MappingProblems []string          `json:"mapping_problems"` // Why the mapping cannot be validated yet
+	CreatedBy       string            `json:"created_by"`
+	StartedAt       *time.Time        `json:"started_at"`
+	CompletedAt     *time.Time        `json:"completed_at"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// ToImportJobResponse converts ImportJob to ImportJobResponse
+func (j *ImportJob) ToImportJobResponse() *ImportJobResponse {
+	resp := &ImportJobResponse{
+		ID:            j.ID,
+		EntityType:    j.EntityType,
+		FileName:      j.FileName,
+		Status:        j.Status,
+		DuplicateMode: j.DuplicateMode,
+		Headers:       make([]string, 0),
+		Mapping:       make(map[string]string),
+		TotalRows:     j.TotalRows,
+		ValidRows:     j.ValidRows,
+		InvalidRows:   j.InvalidRows,
+		DuplicateRows: j.DuplicateRows,
+		ProcessedRows: j.ProcessedRows,
+		CreatedCount:  j.CreatedCount,
+		UpdatedCount:  j.UpdatedCount,
+		SkippedCount:  j.SkippedCount,
+		FailedCount:   j.FailedCount,
+		Errors:        make([]RowError, 0),
+		Duplicates:    make([]RowDuplicate, 0),
+		ErrorMessage:  j.ErrorMessage,
+		CreatedBy:     j.CreatedBy,
+		StartedAt:     j.StartedAt,
+		CompletedAt:   j.CompletedAt,
+		CreatedAt:     j.CreatedAt,
+		UpdatedAt:     j.UpdatedAt,
+	}
+	_ = json.Unmarshal(j.Headers, &resp.Headers)
+	_ = json.Unmarshal(j.Mapping, &resp.Mapping)
+	_ = json.Unmarshal(j.Errors, &resp.Errors)
+	_ = json.Unmarshal(j.Duplicates, &resp.Duplicates)
+	resp.MappingProblems = MappingProblems(j.EntityType, resp.Mapping, resp.Headers)
+	return resp
+}
+
+// FieldsResponse represents the importable fields of an entity type
+type FieldsResponse struct {
+	EntityType string  `json:"entity_type"`
+	Fields     []Field `json:"fields"`
+}
+
+// CreateImportRequest represents the form fields sent with an uploaded import file.
+// Mapping is a JSON object of field key to column header; fields left out are mapped from matching headers.
+type CreateImportRequest struct {
+	EntityType    string `form:"entity_type" binding:"required,oneof=account contact lead product"`
+	DuplicateMode string `form:"duplicate_mode" binding:"omitempty,oneof=skip upsert"`
+	Mapping       string `form:"mapping" binding:"omitempty"`
+}
+
+// ValidateImportRequest represents a new dry run of an import job with another mapping or duplicate mode
+type ValidateImportRequest struct {
+	Mapping       map[string]string `json:"mapping"`
+	DuplicateMode string            `json:"duplicate_mode" binding:"omitempty,oneof=skip upsert"`
+}
+
+// ListImportJobsRequest represents list import jobs query parameters
+type ListImportJobsRequest struct {
+	Page       int    `form:"page" binding:"omitempty,min=1"`
+	PerPage    int    `form:"per_page" binding:"omitempty,min=1,max=100"`
+	EntityType string `form:"entity_type" binding:"omitempty,oneof=account contact lead product"`
+	Status     string `form:"status" binding:"omitempty,oneof=needs_mapping validated importing completed failed"`
+}
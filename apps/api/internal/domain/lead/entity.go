@@ -5,7 +5,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gilabs/crm-healthcare/api/internal/domain/duplicate"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_scoring"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -22,6 +26,8 @@ type Lead struct {
 	LeadSource        string         `gorm:"type:varchar(100);not null" json:"lead_source"` // website, referral, cold_call, event, etc.
 	LeadStatus        string         `gorm:"type:varchar(50);not null;default:'new'" json:"lead_status"` // new, contacted, qualified, converted, lost
 	LeadScore         int            `gorm:"type:integer;default:0" json:"lead_score"` // 0-100
+	ScoreBreakdown    datatypes.JSON `gorm:"type:jsonb" json:"-"`                 // Matched scoring rules ([]lead_scoring.ScoreItem)
+	ScoredAt          *time.Time     `gorm:"type:timestamp" json:"scored_at"`       // Last automatic scoring; nil when scored by hand
 	AssignedTo        *string        `gorm:"type:uuid;index" json:"assigned_to"` // Sales rep ID
 	AssignedUser      *UserRef       `gorm:"foreignKey:AssignedTo" json:"assigned_user,omitempty"`
 	AccountID         *string        `gorm:"type:uuid;index" json:"account_id"` // Created account after conversion
@@ -45,6 +51,9 @@ type Lead struct {
 	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// ClosedStatuses are lead statuses no longer worked on by a sales rep
+var ClosedStatuses = []string{"converted", "lost", "disqualified", "unqualified"}
+
 // TableName specifies the table name for Lead
 func (Lead) TableName() string {
 	return "leads"
@@ -72,6 +81,18 @@ func (l *Lead) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// DuplicateRecord returns the attributes compared when looking for duplicate leads
+func (l *Lead) DuplicateRecord() duplicate.Record {
+	return duplicate.Record{
+		ID:      l.ID,
+		Name:    strings.TrimSpace(l.FirstName + " " + l.LastName),
+		Company: l.CompanyName,
+		Email:   l.Email,
+		Phone:   l.Phone,
+		City:    l.City,
+	}
+}
+
 // UserRef represents user reference in lead
 type UserRef struct {
 	ID        string `gorm:"type:uuid;primary_key" json:"id"`
@@ -134,6 +155,8 @@ type LeadResponse struct {
 	LeadSource        string             `json:"lead_source"`
 	LeadStatus        string             `json:"lead_status"`
 	LeadScore         int                `json:"lead_score"`
+	ScoreBreakdown    []lead_scoring.ScoreItem `json:"score_breakdown,omitempty"`
+	ScoredAt          *time.Time         `json:"scored_at"`
 	AssignedTo        string             `json:"assigned_to"`
 	AssignedUser      *UserRefResponse   `json:"assigned_user,omitempty"`
 	AccountID         string             `json:"account_id"`
@@ -200,6 +223,7 @@ func (l *Lead) ToLeadResponse() *LeadResponse {
 		LeadSource:    l.LeadSource,
 		LeadStatus:    l.LeadStatus,
 		LeadScore:     l.LeadScore,
+		ScoredAt:      l.ScoredAt,
 		AssignedTo:    getStringValue(l.AssignedTo),
 		AccountID:     getStringValue(l.AccountID),
 		ContactID:     getStringValue(l.ContactID),
@@ -305,24 +329,25 @@ func formatNumber(n float64) string {
 
 // CreateLeadRequest represents create lead request DTO
 type CreateLeadRequest struct {
-	FirstName   string `json:"first_name" binding:"required,min=1,max=100"`
-	LastName    string `json:"last_name" binding:"omitempty,max=100"`
-	CompanyName string `json:"company_name" binding:"omitempty,max=255"`
-	Email       string `json:"email" binding:"required,email"`
-	Phone       string `json:"phone" binding:"omitempty,max=20"`
-	JobTitle    string `json:"job_title" binding:"omitempty,max=100"`
-	Industry    string `json:"industry" binding:"omitempty,max=100"`
-	LeadSource  string `json:"lead_source" binding:"required,oneof=website referral cold_call event social_media email_campaign partner other"`
-	LeadStatus  string `json:"lead_status" binding:"omitempty,oneof=new contacted qualified unqualified nurturing disqualified converted lost"`
-	LeadScore   int    `json:"lead_score" binding:"omitempty,min=0,max=100"`
-	AssignedTo  string `json:"assigned_to" binding:"omitempty,uuid"`
-	Notes       string `json:"notes" binding:"omitempty"`
-	Address     string `json:"address" binding:"omitempty"`
-	City        string `json:"city" binding:"omitempty,max=100"`
-	Province    string `json:"province" binding:"omitempty,max=100"`
-	PostalCode  string `json:"postal_code" binding:"omitempty,max=20"`
-	Country     string `json:"country" binding:"omitempty,max=100"`
-	Website     string `json:"website" binding:"omitempty,url"`
+	FirstName        string `json:"first_name" binding:"required,min=1,max=100"`
+	LastName         string `json:"last_name" binding:"omitempty,max=100"`
+	CompanyName      string `json:"company_name" binding:"omitempty,max=255"`
+	Email            string `json:"email" binding:"required,email"`
+	Phone            string `json:"phone" binding:"omitempty,max=20"`
+	JobTitle         string `json:"job_title" binding:"omitempty,max=100"`
+	Industry         string `json:"industry" binding:"omitempty,max=100"`
+	LeadSource       string `json:"lead_source" binding:"required,oneof=website referral cold_call event social_media email_campaign partner other"`
+	LeadStatus       string `json:"lead_status" binding:"omitempty,oneof=new contacted qualified unqualified nurturing disqualified converted lost"`
+	LeadScore        int    `json:"lead_score" binding:"omitempty,min=0,max=100"` // Overridden while scoring rules are active
+	AssignedTo       string `json:"assigned_to" binding:"omitempty,uuid"`
+	Notes            string `json:"notes" binding:"omitempty"`
+	Address          string `json:"address" binding:"omitempty"`
+	City             string `json:"city" binding:"omitempty,max=100"`
+	Province         string `json:"province" binding:"omitempty,max=100"`
+	PostalCode       string `json:"postal_code" binding:"omitempty,max=20"`
+	Country          string `json:"country" binding:"omitempty,max=100"`
+	Website          string `json:"website" binding:"omitempty,url"`
+	IgnoreDuplicates bool   `json:"ignore_duplicates"` // Create even when similar leads exist
 }
 
 // UpdateLeadRequest represents update lead request DTO
@@ -336,7 +361,7 @@ type UpdateLeadRequest struct {
 	Industry    string `json:"industry" binding:"omitempty,max=100"`
 	LeadSource  string `json:"lead_source" binding:"omitempty,oneof=website referral cold_call event social_media email_campaign partner other"`
 	LeadStatus  string `json:"lead_status" binding:"omitempty,oneof=new contacted qualified unqualified nurturing disqualified converted lost"`
-	LeadScore   *int   `json:"lead_score" binding:"omitempty,min=0,max=100"`
+	LeadScore   *int   `json:"lead_score" binding:"omitempty,min=0,max=100"` // Overridden while scoring rules are active
 	AssignedTo  string `json:"assigned_to" binding:"omitempty,uuid"`
 	Notes       string `json:"notes" binding:"omitempty"`
 	Address     string `json:"address" binding:"omitempty"`
@@ -355,10 +380,11 @@ type ConvertLeadRequest struct {
 	Value                  *int64     `json:"value" binding:"omitempty,min=0"`
 	Probability            *int       `json:"probability" binding:"omitempty,min=0,max=100"`
 	ExpectedCloseDate      *time.Time `json:"expected_close_date" binding:"omitempty"`
-	CreateAccount          bool        `json:"create_account" binding:"omitempty"`
-	CreateContact          bool        `json:"create_contact" binding:"omitempty"`
+	CreateAccount          bool       `json:"create_account" binding:"omitempty"`
+	CreateContact          bool       `json:"create_contact" binding:"omitempty"`
 	AccountID              string     `json:"account_id" binding:"omitempty,uuid"`
 	ContactID              string     `json:"contact_id" binding:"omitempty,uuid"`
+	IgnoreDuplicates       bool       `json:"ignore_duplicates"` // Create the account and contact even when similar ones exist
 }
 
 // ConvertLeadResponse represents convert lead response DTO
@@ -371,8 +397,9 @@ type ConvertLeadResponse struct {
 
 // CreateAccountFromLeadRequest represents create account from lead request DTO
 type CreateAccountFromLeadRequest struct {
-	CategoryID string `json:"category_id" binding:"omitempty,uuid"` // If not provided, will use first available category
-	CreateContact bool `json:"create_contact" binding:"omitempty"`   // Also create contact from lead
+	CategoryID       string `json:"category_id" binding:"omitempty,uuid"` // If not provided, will use first available category
+	CreateContact    bool   `json:"create_contact" binding:"omitempty"`   // Also create contact from lead
+	IgnoreDuplicates bool   `json:"ignore_duplicates"`                    // Create even when similar accounts exist
 }
 
 // CreateAccountFromLeadResponse represents create account from lead response DTO
@@ -463,4 +490,50 @@ type LeadFormDefaults struct {
 	LeadScore  int    `json:"lead_score"`
 }
 
-
+// ExportColumns are the columns of a lead export
+var ExportColumns = []export.Column{
+	{Key: "id", Header: "ID"},
+	{Key: "first_name", Header: "First Name"},
+	{Key: "last_name", Header: "Last Name"},
+	{Key: "company_name", Header: "Company"},
+	{Key: "email", Header: "Email"},
+	{Key: "phone", Header: "Phone"},
+	{Key: "job_title", Header: "Job Title"},
+	{Key: "industry", Header: "Industry"},
+	{Key: "lead_source", Header: "Source"},
+	{Key: "lead_status", Header: "Status"},
+	{Key: "lead_score", Header: "Score"},
+	{Key: "assigned_to", Header: "Assigned To"},
+	{Key: "account", Header: "Account"},
+	{Key: "opportunity", Header: "Opportunity"},
+	{Key: "converted_at", Header: "Converted At"},
+	{Key: "address", Header: "Address"},
+	{Key: "city", Header: "City"},
+	{Key: "province", Header: "Province"},
+	{Key: "postal_code", Header: "Postal Code"},
+	{Key: "country", Header: "Country"},
+	{Key: "website", Header: "Website"},
+	{Key: "notes", Header: "Notes"},
+	{Key: "created_at", Header: "Created At"},
+	{Key: "updated_at", Header: "Updated At"},
+}
+
+// ExportRow returns the lead's values in the order of ExportColumns
+func (l *Lead) ExportRow() []interface{} {
+	var assignedTo, account, opportunity string
+	if l.AssignedUser != nil {
+		assignedTo = l.AssignedUser.Name
+	}
+	if l.Account != nil {
+		account = l.Account.Name
+	}
+	if l.Opportunity != nil {
+		opportunity = l.Opportunity.Title
+	}
+	return []interface{}{
+		l.ID, l.FirstName, l.LastName, l.CompanyName, l.Email, l.Phone, l.JobTitle, l.Industry,
+		l.LeadSource, l.LeadStatus, l.LeadScore, assignedTo, account, opportunity, l.ConvertedAt,
+		l.Address, l.City, l.Province, l.PostalCode, l.Country, l.Website, l.Notes,
+		l.CreatedAt, l.UpdatedAt,
+	}
+}
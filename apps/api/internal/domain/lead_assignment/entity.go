@@ -0,0 +1,151 @@
+package lead_assignment
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Assignment strategies
+const (
+	StrategyUser        = "user"         // Always assign to a specific user
+	StrategyRoundRobin  = "round_robin"  // Rotate through the active members of a team
+	StrategyLeastLoaded = "least_loaded" // Active team member with the fewest open leads
+)
+
+// AssignmentRule represents a rule routing new unassigned leads to a sales rep.
+// Match fields left empty match every lead; rules are tried by ascending priority.
+type AssignmentRule struct {
+	ID                 string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name               string         `gorm:"type:varchar(100);not null" json:"name"`
+	Priority           int            `gorm:"type:integer;not null;default:0;index" json:"priority"` // Lower runs first
+	IsActive           bool           `gorm:"not null;default:true" json:"is_active"`
+	LeadSources        string         `gorm:"type:varchar(255)" json:"lead_sources"` // Comma-separated lead sources
+	Provinces          string         `gorm:"type:text" json:"provinces"`            // Comma-separated provinces
+	Cities             string         `gorm:"type:text" json:"cities"`               // Comma-separated cities
+	Industries         string         `gorm:"type:text" json:"industries"`           // Comma-separated industries
+	MinScore           *int           `gorm:"type:integer" json:"min_score"`
+	MaxScore           *int           `gorm:"type:integer" json:"max_score"`
+	Strategy           string         `gorm:"type:varchar(20);not null" json:"strategy"` // user, round_robin, least_loaded
+	UserID             *string        `gorm:"type:uuid" json:"user_id"`                  // Set for the user strategy
+	TeamID             *string        `gorm:"type:uuid" json:"team_id"`                  // Set for the round_robin and least_loaded strategies
+	LastAssignedUserID *string        `gorm:"type:uuid" json:"last_assigned_user_id"`    // Round-robin position
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for AssignmentRule
+func (AssignmentRule) TableName() string {
+	return "lead_assignment_rules"
+}
+
+// BeforeCreate hook to generate UUID
+func (r *AssignmentRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// LeadFacts represents the lead attributes assignment rules match on
+type LeadFacts struct {
+	LeadSource string
+	Province   string
+	City       string
+	Industry   string
+	LeadScore  int
+}
+
+// Matches reports whether the lead satisfies every condition of the rule
+func (r *AssignmentRule) Matches(facts LeadFacts) bool {
+	if !matchList(r.LeadSources, facts.LeadSource) ||
+		!matchList(r.Provinces, facts.Province) ||
+		!matchList(r.Cities, facts.City) ||
+		!matchList(r.Industries, facts.Industry) {
+		return false
+	}
+	if r.MinScore != nil && facts.LeadScore < *r.MinScore {
+		return false
+	}
+	if r.MaxScore != nil && facts.LeadScore > *r.MaxScore {
+		return false
+	}
+	return true
+}
+
+// matchList reports whether a value equals one of the comma-separated values, ignoring case; an empty list matches all
+func matchList(list string, value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	empty := true
+	for _, v := range strings.Split(list, ",") {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v == "" {
+			continue
+		}
+		empty = false
+		if v == value {
+			return true
+		}
+	}
+	return empty
+}
+
+// NextRoundRobin returns the member after lastUserID, wrapping around; the first member when lastUserID is not a member
+func NextRoundRobin(memberIDs []string, lastUserID string) string {
+	if len(memberIDs) == 0 {
+		return ""
+	}
+	for i, id := range memberIDs {
+		if id == lastUserID {
+			return memberIDs[(i+1)%len(memberIDs)]
+		}
+	}
+	return memberIDs[0]
+}
+
+// LeastLoaded returns the member with the fewest open leads; ties go to the earlier member
+func LeastLoaded(memberIDs []string, openLeads map[string]int64) string {
+	chosen := ""
+	for _, id := range memberIDs {
+		if chosen == "" || openLeads[id] < openLeads[chosen] {
+			chosen = id
+		}
+	}
+	return chosen
+}
+
+// CreateAssignmentRuleRequest represents create assignment rule request DTO
+type CreateAssignmentRuleRequest struct {
+	Name        string  `json:"name" binding:"required,min=1,max=100"`
+	Priority    int     `json:"priority" binding:"min=0"`
+	IsActive    *bool   `json:"is_active"`
+	LeadSources string  `json:"lead_sources" binding:"omitempty,max=255"`
+	Provinces   string  `json:"provinces"`
+	Cities      string  `json:"cities"`
+	Industries  string  `json:"industries"`
+	MinScore    *int    `json:"min_score" binding:"omitempty,min=0,max=100"`
+	MaxScore    *int    `json:"max_score" binding:"omitempty,min=0,max=100"`
+	Strategy    string  `json:"strategy" binding:"required,oneof=user round_robin least_loaded"`
+	UserID      *string `json:"user_id" binding:"required_if=Strategy user,omitempty,uuid"`
+	TeamID      *string `json:"team_id" binding:"required_unless=Strategy user,omitempty,uuid"`
+}
+
+// UpdateAssignmentRuleRequest represents update assignment rule request DTO.
+// Match fields are replaced as sent; send an empty string to stop matching on a field.
+type UpdateAssignmentRuleRequest struct {
+	Name        string  `json:"name" binding:"omitempty,min=1,max=100"`
+	Priority    *int    `json:"priority" binding:"omitempty,min=0"`
+	IsActive    *bool   `json:"is_active"`
+	LeadSources *string `json:"lead_sources" binding:"omitempty,max=255"`
+	Provinces   *string `json:"provinces"`
+	Cities      *string `json:"cities"`
+	Industries  *string `json:"industries"`
+	MinScore    *int    `json:"min_score" binding:"omitempty,min=0,max=100"`
+	MaxScore    *int    `json:"max_score" binding:"omitempty,min=0,max=100"`
+	Strategy    string  `json:"strategy" binding:"omitempty,oneof=user round_robin least_loaded"`
+	UserID      *string `json:"user_id" binding:"omitempty,uuid"`
+	TeamID      *string `json:"team_id" binding:"omitempty,uuid"`
+}
@@ -0,0 +1,254 @@
+package lead_scoring
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Lead attributes a scoring rule can test
+const (
+	CriterionLeadSource         = "lead_source"
+	CriterionIndustry           = "industry"
+	CriterionJobTitle           = "job_title"
+	CriterionHasEmail           = "has_email"
+	CriterionHasPhone           = "has_phone"
+	CriterionActivityCount      = "activity_count"
+	CriterionVisitReportCount   = "visit_report_count"
+	CriterionDaysSinceLastTouch = "days_since_last_touch"
+)
+
+// Rule operators
+const (
+	OperatorEquals   = "equals"   // Case-insensitive match against one of the comma-separated values
+	OperatorContains = "contains" // Case-insensitive substring match against one of the comma-separated values
+	OperatorIsSet    = "is_set"   // Attribute is filled in
+	OperatorGTE      = "gte"      // Number is at least the value
+	OperatorLTE      = "lte"      // Number is at most the value
+)
+
+// Score bounds
+const (
+	MinScore = 0
+	MaxScore = 100
+)
+
+// criterionOperators lists the operators each criterion supports
+var criterionOperators = map[string][]string{
+	CriterionLeadSource:         {OperatorEquals},
+	CriterionIndustry:           {OperatorEquals, OperatorContains},
+	CriterionJobTitle:           {OperatorEquals, OperatorContains},
+	CriterionHasEmail:           {OperatorIsSet},
+	CriterionHasPhone:           {OperatorIsSet},
+	CriterionActivityCount:      {OperatorGTE, OperatorLTE},
+	CriterionVisitReportCount:   {OperatorGTE, OperatorLTE},
+	CriterionDaysSinceLastTouch: {OperatorGTE, OperatorLTE},
+}
+
+// ScoringRule represents an admin-editable rule adding points to the score of leads matching it
+type ScoringRule struct {
+	ID        string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name      string         `gorm:"type:varchar(100);not null" json:"name"`
+	Criterion string         `gorm:"type:varchar(50);not null" json:"criterion"` // lead_source, industry, job_title, has_email, ...
+	Operator  string         `gorm:"type:varchar(20);not null" json:"operator"`  // equals, contains, is_set, gte, lte
+	Value     string         `gorm:"type:varchar(255)" json:"value"`             // Comma-separated text values or a whole number
+	Points    int            `gorm:"type:integer;not null" json:"points"`        // Negative points lower the score
+	IsActive  bool           `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for ScoringRule
+func (ScoringRule) TableName() string {
+	return "lead_scoring_rules"
+}
+
+// BeforeCreate hook to generate UUID
+func (r *ScoringRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// ValidateRule reports whether the operator is supported by the criterion and the value fits the operator
+func ValidateRule(criterion string, operator string, value string) bool {
+	supported := false
+	for _, op := range criterionOperators[criterion] {
+		if op == operator {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return false
+	}
+
+	switch operator {
+	case OperatorEquals, OperatorContains:
+		return len(splitValues(value)) > 0
+	case OperatorGTE, OperatorLTE:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		return err == nil && n >= 0
+	default:
+		return true
+	}
+}
+
+// Engagement represents the activities and visit reports linked to a lead
+type Engagement struct {
+	ActivityCount    int
+	VisitReportCount int
+	LastTouchAt      *time.Time // Latest activity or visit; nil when the lead was never touched
+}
+
+// Facts represents the lead attributes scoring rules are evaluated against
+type Facts struct {
+	LeadSource string
+	Industry   string
+	JobTitle   string
+	Email      string
+	Phone      string
+	Engagement
+}
+
+// ScoreItem represents the points a single matched rule added to a score
+type ScoreItem struct {
+	RuleID    string `json:"rule_id"`
+	Name      string `json:"name"`
+	Criterion string `json:"criterion"`
+	Points    int    `json:"points"`
+}
+
+// Evaluate adds up the points of the active rules matching the facts and clamps the total to 0-100.
+// The breakdown lists matched rules in the given order.
+func Evaluate(rules []ScoringRule, facts Facts, now time.Time) (int, []ScoreItem) {
+	total := 0
+	breakdown := make([]ScoreItem, 0)
+	for _, rule := range rules {
+		if !rule.IsActive || !matches(rule, facts, now) {
+			continue
+		}
+		total += rule.Points
+		breakdown = append(breakdown, ScoreItem{
+			RuleID:    rule.ID,
+			Name:      rule.Name,
+			Criterion: rule.Criterion,
+			Points:    rule.Points,
+		})
+	}
+
+	if total < MinScore {
+		total = MinScore
+	}
+	if total > MaxScore {
+		total = MaxScore
+	}
+	return total, breakdown
+}
+
+// matches reports whether a rule applies to the facts
+func matches(rule ScoringRule, facts Facts, now time.Time) bool {
+	switch rule.Criterion {
+	case CriterionLeadSource:
+		return matchText(rule.Operator, rule.Value, facts.LeadSource)
+	case CriterionIndustry:
+		return matchText(rule.Operator, rule.Value, facts.Industry)
+	case CriterionJobTitle:
+		return matchText(rule.Operator, rule.Value, facts.JobTitle)
+	case CriterionHasEmail:
+		return strings.TrimSpace(facts.Email) != ""
+	case CriterionHasPhone:
+		return strings.TrimSpace(facts.Phone) != ""
+	case CriterionActivityCount:
+		return matchNumber(rule.Operator, rule.Value, facts.ActivityCount)
+	case CriterionVisitReportCount:
+		return matchNumber(rule.Operator, rule.Value, facts.VisitReportCount)
+	case CriterionDaysSinceLastTouch:
+		if facts.LastTouchAt == nil {
+			return false
+		}
+		days := int(now.Sub(*facts.LastTouchAt).Hours() / 24)
+		if days < 0 {
+			days = 0
+		}
+		return matchNumber(rule.Operator, rule.Value, days)
+	}
+	return false
+}
+
+// matchText compares an attribute against the comma-separated values of a rule, ignoring case
+func matchText(operator string, value string, attribute string) bool {
+	attribute = strings.ToLower(strings.TrimSpace(attribute))
+	if attribute == "" {
+		return false
+	}
+	for _, v := range splitValues(value) {
+		switch operator {
+		case OperatorEquals:
+			if attribute == v {
+				return true
+			}
+		case OperatorContains:
+			if strings.Contains(attribute, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchNumber compares a number against the whole number value of a rule
+func matchNumber(operator string, value string, n int) bool {
+	threshold, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return false
+	}
+	switch operator {
+	case OperatorGTE:
+		return n >= threshold
+	case OperatorLTE:
+		return n <= threshold
+	}
+	return false
+}
+
+// splitValues returns the non-empty lowercase values of a comma-separated list
+func splitValues(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// CreateScoringRuleRequest represents create scoring rule request DTO
+type CreateScoringRuleRequest struct {
+	Name      string `json:"name" binding:"required,min=1,max=100"`
+	Criterion string `json:"criterion" binding:"required,oneof=lead_source industry job_title has_email has_phone activity_count visit_report_count days_since_last_touch"`
+	Operator  string `json:"operator" binding:"required,oneof=equals contains is_set gte lte"`
+	Value     string `json:"value" binding:"omitempty,max=255"`
+	Points    int    `json:"points" binding:"min=-100,max=100"`
+	IsActive  *bool  `json:"is_active"`
+}
+
+// UpdateScoringRuleRequest represents update scoring rule request DTO
+type UpdateScoringRuleRequest struct {
+	Name      string  `json:"name" binding:"omitempty,min=1,max=100"`
+	Criterion string  `json:"criterion" binding:"omitempty,oneof=lead_source industry job_title has_email has_phone activity_count visit_report_count days_since_last_touch"`
+	Operator  string  `json:"operator" binding:"omitempty,oneof=equals contains is_set gte lte"`
+	Value     *string `json:"value" binding:"omitempty,max=255"`
+	Points    *int    `json:"points" binding:"omitempty,min=-100,max=100"`
+	IsActive  *bool   `json:"is_active"`
+}
+
+// RecomputeScoresResponse represents the result of recomputing all lead scores
+type RecomputeScoresResponse struct {
+	Updated int `json:"updated"`
+}
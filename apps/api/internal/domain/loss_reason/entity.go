@@ -0,0 +1,78 @@
+package loss_reason
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LossReason represents a reason a deal was lost, e.g. Price, Budget Cut or No Decision
+type LossReason struct {
+	ID          string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name        string         `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+	Code        string         `gorm:"type:varchar(50);uniqueIndex;not null" json:"code"`
+	Description string         `gorm:"type:text" json:"description"`
+	Status      string         `gorm:"type:varchar(20);not null;default:'active'" json:"status"` // Only active reasons can be chosen
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for LossReason
+func (LossReason) TableName() string {
+	return "loss_reasons"
+}
+
+// BeforeCreate hook to generate UUID
+func (lr *LossReason) BeforeCreate(tx *gorm.DB) error {
+	if lr.ID == "" {
+		lr.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// LossReasonResponse represents loss reason response DTO
+type LossReasonResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Code        string    `json:"code"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ToLossReasonResponse converts LossReason to LossReasonResponse
+func (lr *LossReason) ToLossReasonResponse() *LossReasonResponse {
+	return &LossReasonResponse{
+		ID:          lr.ID,
+		Name:        lr.Name,
+		Code:        lr.Code,
+		Description: lr.Description,
+		Status:      lr.Status,
+		CreatedAt:   lr.CreatedAt,
+		UpdatedAt:   lr.UpdatedAt,
+	}
+}
+
+// CreateLossReasonRequest represents create loss reason request DTO
+type CreateLossReasonRequest struct {
+	Name        string `json:"name" binding:"required,min=2,max=100"`
+	Code        string `json:"code" binding:"required,min=2,max=50"`
+	Description string `json:"description"`
+	Status      string `json:"status" binding:"omitempty,oneof=active inactive"`
+}
+
+// UpdateLossReasonRequest represents update loss reason request DTO
+type UpdateLossReasonRequest struct {
+	Name        string `json:"name" binding:"omitempty,min=2,max=100"`
+	Code        string `json:"code" binding:"omitempty,min=2,max=50"`
+	Description string `json:"description"`
+	Status      string `json:"status" binding:"omitempty,oneof=active inactive"`
+}
+
+// ListLossReasonsRequest represents list loss reasons query parameters
+type ListLossReasonsRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=active inactive"`
+}
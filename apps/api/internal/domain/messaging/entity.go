@@ -0,0 +1,173 @@
+package messaging
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Outbound message statuses
+const (
+	StatusPending   = "pending" // Waiting for its next attempt
+	StatusSent      = "sent"    // Accepted by the gateway
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// Preference holds the phone number of a user and the channels they agreed to receive messages on
+type Preference struct {
+	UserID        string     `gorm:"type:uuid;primary_key" json:"user_id"`
+	Phone         string     `gorm:"type:varchar(20)" json:"phone"` // E.164
+	SMSOptIn      bool       `gorm:"not null;default:false" json:"sms_opt_in"`
+	WhatsAppOptIn bool       `gorm:"column:whatsapp_opt_in;not null;default:false" json:"whatsapp_opt_in"`
+	OptedInAt     *time.Time `gorm:"type:timestamp" json:"opted_in_at"` // Last time a channel was switched on
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for Preference
+func (Preference) TableName() string {
+	return "messaging_preferences"
+}
+
+// OptedIn reports whether the user agreed to messages on the channel
+func (p *Preference) OptedIn(channel string) bool {
+	switch channel {
+	case "sms":
+		return p.SMSOptIn && p.Phone != ""
+	case "whatsapp":
+		return p.WhatsAppOptIn && p.Phone != ""
+	}
+	return false
+}
+
+// OutboundMessage represents an SMS or WhatsApp message queued for delivery. The worker sends it with
+// retries; the provider's delivery receipt moves it from sent to delivered or failed.
+type OutboundMessage struct {
+	ID                string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID            *string    `gorm:"type:uuid;index" json:"user_id"`
+	Channel           string     `gorm:"type:varchar(20);not null" json:"channel"` // sms, whatsapp
+	Recipient         string     `gorm:"type:varchar(20);not null" json:"recipient"`
+	Template          string     `gorm:"type:varchar(50);not null" json:"template"` // Notification type the message was rendered from
+	Body              string     `gorm:"type:text;not null" json:"body"`
+	Status            string     `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"` // pending, sent, delivered, failed
+	Attempts          int        `gorm:"type:integer;not null;default:0" json:"attempts"`
+	MaxAttempts       int        `gorm:"type:integer;not null" json:"max_attempts"`
+	NextAttemptAt     time.Time  `gorm:"type:timestamp;not null;index" json:"next_attempt_at"`
+	ProviderMessageID string     `gorm:"type:varchar(255);index" json:"provider_message_id"`
+	LastError         string     `gorm:"type:text" json:"last_error"`
+	SentAt            *time.Time `gorm:"type:timestamp" json:"sent_at"`
+	DeliveredAt       *time.Time `gorm:"type:timestamp" json:"delivered_at"`
+	ReminderID        *string    `gorm:"type:uuid;index" json:"reminder_id"` // Reminder whose delivery status follows this message
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for OutboundMessage
+func (OutboundMessage) TableName() string {
+	return "outbound_messages"
+}
+
+// BeforeCreate hook to generate UUID
+func (m *OutboundMessage) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// NotificationMessage is a notification to render and queue for a user's phone
+type NotificationMessage struct {
+	UserID     string
+	Channel    string // sms or whatsapp; empty for the user's preferred channel
+	Type       string // reminder, task, deal, activity, lead
+	Title      string
+	Message    string
+	When       string // Due or reminder time, already formatted
+	ReminderID *string
+}
+
+// PreferenceResponse represents messaging preference response DTO
+type PreferenceResponse struct {
+	Phone         string     `json:"phone"`
+	SMSOptIn      bool       `json:"sms_opt_in"`
+	WhatsAppOptIn bool       `json:"whatsapp_opt_in"`
+	OptedInAt     *time.Time `json:"opted_in_at"`
+}
+
+// ToPreferenceResponse converts Preference to PreferenceResponse
+func (p *Preference) ToPreferenceResponse() *PreferenceResponse {
+	return &PreferenceResponse{
+		Phone:         p.Phone,
+		SMSOptIn:      p.SMSOptIn,
+		WhatsAppOptIn: p.WhatsAppOptIn,
+		OptedInAt:     p.OptedInAt,
+	}
+}
+
+// UpdatePreferenceRequest represents update messaging preference request DTO.
+// Send an empty phone to remove the number, which also opts out of every channel.
+type UpdatePreferenceRequest struct {
+	Phone         *string `json:"phone" binding:"omitempty,max=30"`
+	SMSOptIn      *bool   `json:"sms_opt_in"`
+	WhatsAppOptIn *bool   `json:"whatsapp_opt_in"`
+}
+
+// OutboundMessageResponse represents outbound message response DTO
+type OutboundMessageResponse struct {
+	ID                string     `json:"id"`
+	UserID            *string    `json:"user_id"`
+	Channel           string     `json:"channel"`
+	Recipient         string     `json:"recipient"`
+	Template          string     `json:"template"`
+	Body              string     `json:"body"`
+	Status            string     `json:"status"`
+	Attempts          int        `json:"attempts"`
+	MaxAttempts       int        `json:"max_attempts"`
+	NextAttemptAt     *time.Time `json:"next_attempt_at"` // Nil once the gateway accepted or rejected it
+	ProviderMessageID string     `json:"provider_message_id,omitempty"`
+	LastError         string     `json:"last_error,omitempty"`
+	SentAt            *time.Time `json:"sent_at"`
+	DeliveredAt       *time.Time `json:"delivered_at"`
+	ReminderID        *string    `json:"reminder_id,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// ToOutboundMessageResponse converts OutboundMessage to OutboundMessageResponse
+func (m *OutboundMessage) ToOutboundMessageResponse() *OutboundMessageResponse {
+	resp := &OutboundMessageResponse{
+		ID:                m.ID,
+		UserID:            m.UserID,
+		Channel:           m.Channel,
+		Recipient:         m.Recipient,
+		Template:          m.Template,
+		Body:              m.Body,
+		Status:            m.Status,
+		Attempts:          m.Attempts,
+		MaxAttempts:       m.MaxAttempts,
+		ProviderMessageID: m.ProviderMessageID,
+		LastError:         m.LastError,
+		SentAt:            m.SentAt,
+		DeliveredAt:       m.DeliveredAt,
+		ReminderID:        m.ReminderID,
+		CreatedAt:         m.CreatedAt,
+		UpdatedAt:         m.UpdatedAt,
+	}
+	if m.Status == StatusPending {
+		next := m.NextAttemptAt
+		resp.NextAttemptAt = &next
+	}
+	return resp
+}
+
+// ListOutboundMessagesRequest represents list outbound messages query parameters
+type ListOutboundMessagesRequest struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PerPage  int    `form:"per_page" binding:"omitempty,min=1,max=100"`
+	Channel  string `form:"channel" binding:"omitempty,oneof=sms whatsapp"`
+	Status   string `form:"status" binding:"omitempty,oneof=pending sent delivered failed"`
+	Template string `form:"template" binding:"omitempty,oneof=reminder task deal activity lead"`
+	UserID   string `form:"user_id" binding:"omitempty,uuid"`
+}
@@ -13,7 +13,7 @@ type Notification struct {
 	UserID    string         `gorm:"type:uuid;not null;index" json:"user_id"`
 	Title     string         `gorm:"type:varchar(255);not null" json:"title"`
 	Message   string         `gorm:"type:text" json:"message"`
-	Type      string         `gorm:"type:varchar(50);not null;default:'reminder'" json:"type"` // reminder, task, deal, activity
+	Type      string         `gorm:"type:varchar(50);not null;default:'reminder'" json:"type"` // reminder, task, deal, activity, lead
 	IsRead    bool           `gorm:"type:boolean;default:false;index" json:"is_read"`
 	ReadAt    *time.Time     `gorm:"type:timestamp" json:"read_at"`
 	Data      string         `gorm:"type:jsonb" json:"data"` // Additional data as JSON
@@ -67,11 +67,14 @@ func (n *Notification) ToNotificationResponse() *NotificationResponse {
 
 // CreateNotificationRequest represents create notification request DTO
 type CreateNotificationRequest struct {
-	UserID  string `json:"user_id" binding:"required,uuid"`
-	Title   string `json:"title" binding:"required"`
-	Message string `json:"message" binding:"omitempty"`
-	Type    string `json:"type" binding:"omitempty,oneof=reminder task deal activity"`
-	Data    string `json:"data" binding:"omitempty"`
+	UserID    string `json:"user_id" binding:"required,uuid"`
+	Title     string `json:"title" binding:"required"`
+	Message   string `json:"message" binding:"omitempty"`
+	Type      string `json:"type" binding:"omitempty,oneof=reminder task deal activity lead"`
+	Data      string `json:"data" binding:"omitempty"`
+	Email     bool   `json:"email"`     // Also email the notification to the user
+	Messaging bool   `json:"messaging"` // Also send it by WhatsApp or SMS if the user opted in
+	When      string `json:"-"`         // Due or reminder time shown in the text message
 }
 
 // ListNotificationsRequest represents list notifications query parameters
@@ -79,7 +82,7 @@ type ListNotificationsRequest struct {
 	Page    int    `form:"page" binding:"omitempty,min=1"`
 	PerPage int    `form:"per_page" binding:"omitempty,min=1,max=100"`
 	UserID  string `form:"user_id" binding:"omitempty,uuid"`
-	Type    string `form:"type" binding:"omitempty,oneof=reminder task deal activity"`
+	Type    string `form:"type" binding:"omitempty,oneof=reminder task deal activity lead"`
 	IsRead  *bool  `form:"is_read" binding:"omitempty"`
 }
 
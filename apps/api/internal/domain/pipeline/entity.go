@@ -2,19 +2,89 @@ package pipeline
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// DefaultPipelineCode is the code of the pipeline that existing stages and deals were migrated into
+const DefaultPipelineCode = "sales"
+
+// Pipeline represents a sales process with its own set of stages (e.g., hospital tenders, pharmacy distribution)
+type Pipeline struct {
+	ID          string          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name        string          `gorm:"type:varchar(255);not null" json:"name"`
+	Code        string          `gorm:"type:varchar(50);not null;uniqueIndex" json:"code"`
+	Description string          `gorm:"type:text" json:"description"`
+	Order       int             `gorm:"type:integer;not null;default:0" json:"order"`
+	IsActive    bool            `gorm:"type:boolean;default:true" json:"is_active"`
+	IsDefault   bool            `gorm:"type:boolean;default:false" json:"is_default"` // Used when a request names no pipeline
+	Stages      []PipelineStage `gorm:"foreignKey:PipelineID" json:"stages,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt  `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for Pipeline
+func (Pipeline) TableName() string {
+	return "pipelines"
+}
+
+// BeforeCreate hook to generate UUID
+func (p *Pipeline) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// PipelineResponse represents pipeline response DTO
+type PipelineResponse struct {
+	ID          string                  `json:"id"`
+	Name        string                  `json:"name"`
+	Code        string                  `json:"code"`
+	Description string                  `json:"description"`
+	Order       int                     `json:"order"`
+	IsActive    bool                    `json:"is_active"`
+	IsDefault   bool                    `json:"is_default"`
+	Stages      []PipelineStageResponse `json:"stages,omitempty"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+}
+
+// ToPipelineResponse converts Pipeline to PipelineResponse
+func (p *Pipeline) ToPipelineResponse() *PipelineResponse {
+	resp := &PipelineResponse{
+		ID:          p.ID,
+		Name:        p.Name,
+		Code:        p.Code,
+		Description: p.Description,
+		Order:       p.Order,
+		IsActive:    p.IsActive,
+		IsDefault:   p.IsDefault,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+	if len(p.Stages) > 0 {
+		resp.Stages = make([]PipelineStageResponse, len(p.Stages))
+		for i := range p.Stages {
+			resp.Stages[i] = *p.Stages[i].ToPipelineStageResponse()
+		}
+	}
+	return resp
+}
+
 // PipelineStage represents a pipeline stage (e.g., Lead, Qualification, Proposal, Negotiation, Closed Won, Closed Lost)
 type PipelineStage struct {
 	ID          string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PipelineID  string         `gorm:"type:uuid;index;uniqueIndex:idx_pipeline_stages_pipeline_code" json:"pipeline_id"`
 	Name        string         `gorm:"type:varchar(255);not null" json:"name"`
-	Code        string         `gorm:"type:varchar(50);not null;uniqueIndex" json:"code"`
-	Order       int            `gorm:"type:integer;not null;default:0" json:"order"`
+	Code        string         `gorm:"type:varchar(50);not null;uniqueIndex:idx_pipeline_stages_pipeline_code" json:"code"` // Unique per pipeline
+	Order       int            `gorm:"type:integer;not null;default:0" json:"order"` // Position within the pipeline
 	Color       string         `gorm:"type:varchar(20);default:'#3B82F6'" json:"color"`
 	IsActive    bool           `gorm:"type:boolean;default:true" json:"is_active"`
 	IsWon       bool           `gorm:"type:boolean;default:false" json:"is_won"`  // True for "Closed Won"
@@ -41,6 +111,7 @@ func (ps *PipelineStage) BeforeCreate(tx *gorm.DB) error {
 // PipelineStageResponse represents pipeline stage response DTO
 type PipelineStageResponse struct {
 	ID          string    `json:"id"`
+	PipelineID  string    `json:"pipeline_id"`
 	Name        string    `json:"name"`
 	Code        string    `json:"code"`
 	Order       int       `json:"order"`
@@ -57,6 +128,7 @@ type PipelineStageResponse struct {
 func (ps *PipelineStage) ToPipelineStageResponse() *PipelineStageResponse {
 	return &PipelineStageResponse{
 		ID:          ps.ID,
+		PipelineID:  ps.PipelineID,
 		Name:        ps.Name,
 		Code:        ps.Code,
 		Order:       ps.Order,
@@ -79,6 +151,7 @@ type Deal struct {
 	Account           *AccountRef    `gorm:"foreignKey:AccountID" json:"account,omitempty"`
 	ContactID         string         `gorm:"type:uuid;index" json:"contact_id"` // Optional contact
 	Contact           *ContactRef    `gorm:"foreignKey:ContactID" json:"contact,omitempty"`
+	PipelineID        string         `gorm:"type:uuid;index" json:"pipeline_id"` // Pipeline of the stage
 	StageID           string         `gorm:"type:uuid;not null;index" json:"stage_id"`
 	Stage             *PipelineStage `gorm:"foreignKey:StageID" json:"stage,omitempty"`
 	Value             int64          `gorm:"type:bigint;not null;default:0" json:"value"` // Deal value in smallest currency unit (sen)
@@ -91,6 +164,13 @@ type Deal struct {
 	Status            string         `gorm:"type:varchar(20);not null;default:'open'" json:"status"` // open, won, lost
 	Source            string         `gorm:"type:varchar(100)" json:"source"`                        // e.g., "website", "referral", "cold_call"
 	Notes             string         `gorm:"type:text" json:"notes"`
+	LostReasonID      *string        `gorm:"type:uuid;index" json:"lost_reason_id,omitempty"` // Set when the deal is closed as lost
+	LostReason        *LossReasonRef `gorm:"foreignKey:LostReasonID" json:"lost_reason,omitempty"`
+	CompetitorID      *string        `gorm:"type:uuid;index" json:"competitor_id,omitempty"` // Competitor the deal was lost to
+	Competitor        *CompetitorRef `gorm:"foreignKey:CompetitorID" json:"competitor,omitempty"`
+	LostFromStageID   *string        `gorm:"type:uuid;index" json:"lost_from_stage_id,omitempty"` // Stage the deal was in before it was lost
+	LostFromStage     *PipelineStage `gorm:"foreignKey:LostFromStageID" json:"lost_from_stage,omitempty"`
+	LostNotes         string         `gorm:"type:text" json:"lost_notes"`
 	CreatedBy         string         `gorm:"type:uuid;index" json:"created_by"`
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
@@ -147,6 +227,29 @@ func (UserRef) TableName() string {
 	return "users"
 }
 
+// LossReasonRef represents loss reason reference in deal
+type LossReasonRef struct {
+	ID   string `gorm:"type:uuid;primary_key" json:"id"`
+	Name string `json:"name"`
+	Code string `json:"code"`
+}
+
+// TableName specifies the table name for LossReasonRef
+func (LossReasonRef) TableName() string {
+	return "loss_reasons"
+}
+
+// CompetitorRef represents competitor reference in deal
+type CompetitorRef struct {
+	ID   string `gorm:"type:uuid;primary_key" json:"id"`
+	Name string `json:"name"`
+}
+
+// TableName specifies the table name for CompetitorRef
+func (CompetitorRef) TableName() string {
+	return "competitors"
+}
+
 // DealResponse represents deal response DTO
 type DealResponse struct {
 	ID                string                 `json:"id"`
@@ -156,6 +259,7 @@ type DealResponse struct {
 	Account           *AccountRefResponse    `json:"account,omitempty"`
 	ContactID         string                 `json:"contact_id"`
 	Contact           *ContactRefResponse    `json:"contact,omitempty"`
+	PipelineID        string                 `json:"pipeline_id"`
 	StageID           string                 `json:"stage_id"`
 	Stage             *PipelineStageResponse `json:"stage,omitempty"`
 	Value             int64                  `json:"value"`
@@ -169,6 +273,13 @@ type DealResponse struct {
 	Status            string                 `json:"status"`
 	Source            string                 `json:"source"`
 	Notes             string                 `json:"notes"`
+	LostReasonID      *string                `json:"lost_reason_id,omitempty"`
+	LostReason        *LossReasonRefResponse `json:"lost_reason,omitempty"`
+	CompetitorID      *string                `json:"competitor_id,omitempty"`
+	Competitor        *CompetitorRefResponse `json:"competitor,omitempty"`
+	LostFromStageID   *string                `json:"lost_from_stage_id,omitempty"`
+	LostFromStage     *PipelineStageResponse `json:"lost_from_stage,omitempty"`
+	LostNotes         string                 `json:"lost_notes"`
 	CreatedBy         string                 `json:"created_by"`
 	CreatedAt         time.Time              `json:"created_at"`
 	UpdatedAt         time.Time              `json:"updated_at"`
@@ -196,6 +307,19 @@ type UserRefResponse struct {
 	AvatarURL string `json:"avatar_url"`
 }
 
+// LossReasonRefResponse represents loss reason in deal response
+type LossReasonRefResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Code string `json:"code"`
+}
+
+// CompetitorRefResponse represents competitor in deal response
+type CompetitorRefResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
 // ToDealResponse converts Deal to DealResponse
 func (d *Deal) ToDealResponse() *DealResponse {
 	resp := &DealResponse{
@@ -204,6 +328,7 @@ func (d *Deal) ToDealResponse() *DealResponse {
 		Description:       d.Description,
 		AccountID:         d.AccountID,
 		ContactID:         d.ContactID,
+		PipelineID:        d.PipelineID,
 		StageID:           d.StageID,
 		Value:             d.Value,
 		Probability:       d.Probability,
@@ -214,6 +339,10 @@ func (d *Deal) ToDealResponse() *DealResponse {
 		Status:            d.Status,
 		Source:            d.Source,
 		Notes:             d.Notes,
+		LostReasonID:      d.LostReasonID,
+		CompetitorID:      d.CompetitorID,
+		LostFromStageID:   d.LostFromStageID,
+		LostNotes:         d.LostNotes,
 		CreatedBy:         d.CreatedBy,
 		CreatedAt:         d.CreatedAt,
 		UpdatedAt:         d.UpdatedAt,
@@ -251,6 +380,25 @@ func (d *Deal) ToDealResponse() *DealResponse {
 		}
 	}
 
+	if d.LostReason != nil {
+		resp.LostReason = &LossReasonRefResponse{
+			ID:   d.LostReason.ID,
+			Name: d.LostReason.Name,
+			Code: d.LostReason.Code,
+		}
+	}
+
+	if d.Competitor != nil {
+		resp.Competitor = &CompetitorRefResponse{
+			ID:   d.Competitor.ID,
+			Name: d.Competitor.Name,
+		}
+	}
+
+	if d.LostFromStage != nil {
+		resp.LostFromStage = d.LostFromStage.ToPipelineStageResponse()
+	}
+
 	return resp
 }
 
@@ -309,6 +457,7 @@ type CreateDealRequest struct {
 	Description       string     `json:"description" binding:"omitempty"`
 	AccountID         string     `json:"account_id" binding:"required,uuid"`
 	ContactID         string     `json:"contact_id" binding:"omitempty,uuid"`
+	PipelineID        string     `json:"pipeline_id" binding:"omitempty,uuid"` // Optional: must match the pipeline of the stage
 	StageID           string     `json:"stage_id" binding:"required,uuid"`
 	Value             int64      `json:"value" binding:"required,min=0"`
 	Probability       int        `json:"probability" binding:"omitempty,min=0,max=100"`
@@ -317,6 +466,9 @@ type CreateDealRequest struct {
 	LeadID            *string    `json:"lead_id" binding:"omitempty,uuid"` // Optional: track source lead
 	Source            string     `json:"source" binding:"omitempty,max=100"`
 	Notes             string     `json:"notes" binding:"omitempty"`
+	LostReasonID      *string    `json:"lost_reason_id" binding:"omitempty,uuid"` // Used when the stage is a lost stage
+	CompetitorID      *string    `json:"competitor_id" binding:"omitempty,uuid"`
+	LostNotes         string     `json:"lost_notes" binding:"omitempty,max=1000"`
 }
 
 // UpdateDealRequest represents update deal request DTO
@@ -334,11 +486,17 @@ type UpdateDealRequest struct {
 	Status            string     `json:"status" binding:"omitempty,oneof=open won lost"`
 	Source            string     `json:"source" binding:"omitempty,max=100"`
 	Notes             string     `json:"notes" binding:"omitempty"`
+	LostReasonID      *string    `json:"lost_reason_id" binding:"omitempty,uuid"` // Used when the deal is or becomes lost
+	CompetitorID      *string    `json:"competitor_id" binding:"omitempty,uuid"`
+	LostNotes         string     `json:"lost_notes" binding:"omitempty,max=1000"`
 }
 
 // MoveDealRequest represents move deal request DTO
 type MoveDealRequest struct {
-	StageID string `json:"stage_id" binding:"required,uuid"`
+	StageID      string  `json:"stage_id" binding:"required,uuid"`
+	LostReasonID *string `json:"lost_reason_id" binding:"omitempty,uuid"` // Used when the target stage is a lost stage
+	CompetitorID *string `json:"competitor_id" binding:"omitempty,uuid"`
+	LostNotes    string  `json:"lost_notes" binding:"omitempty,max=1000"`
 }
 
 // ListDealsRequest represents list deals query parameters
@@ -346,6 +504,7 @@ type ListDealsRequest struct {
 	Page       int    `form:"page" binding:"omitempty,min=1"`
 	PerPage    int    `form:"per_page" binding:"omitempty,min=1,max=100"`
 	Search     string `form:"search" binding:"omitempty"`
+	PipelineID string `form:"pipeline_id" binding:"omitempty,uuid"`
 	StageID    string `form:"stage_id" binding:"omitempty,uuid"`
 	AccountID  string `form:"account_id" binding:"omitempty,uuid"`
 	AssignedTo string `form:"assigned_to" binding:"omitempty,uuid"`
@@ -355,11 +514,44 @@ type ListDealsRequest struct {
 
 // ListPipelineStagesRequest represents list pipeline stages query parameters
 type ListPipelineStagesRequest struct {
-	IsActive *bool `form:"is_active" binding:"omitempty"`
+	PipelineID string `form:"pipeline_id" binding:"omitempty,uuid"`
+	IsActive   *bool  `form:"is_active" binding:"omitempty"`
+}
+
+// ListPipelinesRequest represents list pipelines query parameters
+type ListPipelinesRequest struct {
+	IsActive      *bool `form:"is_active" binding:"omitempty"`
+	IncludeStages bool  `form:"include_stages" binding:"omitempty"`
+}
+
+// CreatePipelineRequest represents create pipeline request DTO
+type CreatePipelineRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=255"`
+	Code        string `json:"code" binding:"required,min=1,max=50"`
+	Description string `json:"description" binding:"omitempty"`
+	Order       int    `json:"order" binding:"omitempty,min=0"`
+	IsActive    *bool  `json:"is_active" binding:"omitempty"` // Defaults to true
+	IsDefault   bool   `json:"is_default" binding:"omitempty"`
+}
+
+// UpdatePipelineRequest represents update pipeline request DTO
+type UpdatePipelineRequest struct {
+	Name        string `json:"name" binding:"omitempty,min=1,max=255"`
+	Code        string `json:"code" binding:"omitempty,min=1,max=50"`
+	Description string `json:"description" binding:"omitempty"`
+	Order       *int   `json:"order" binding:"omitempty,min=0"`
+	IsActive    *bool  `json:"is_active" binding:"omitempty"`
+	IsDefault   *bool  `json:"is_default" binding:"omitempty"` // Only true is accepted; make another pipeline the default instead
+}
+
+// PipelineFilterRequest represents the pipeline filter of the summary and forecast
+type PipelineFilterRequest struct {
+	PipelineID string `form:"pipeline_id" binding:"omitempty,uuid"`
 }
 
 // CreateStageRequest represents create pipeline stage request DTO
 type CreateStageRequest struct {
+	PipelineID  string `json:"pipeline_id" binding:"omitempty,uuid"` // Defaults to the default pipeline
 	Name        string `json:"name" binding:"required,min=1,max=255"`
 	Code        string `json:"code" binding:"required,min=1,max=50"`
 	Order       int    `json:"order" binding:"required,min=0"`
@@ -384,7 +576,8 @@ type UpdateStageRequest struct {
 
 // UpdateStagesOrderRequest represents update stages order request DTO
 type UpdateStagesOrderRequest struct {
-	Stages []StageOrderItem `json:"stages" binding:"required,min=1,dive"`
+	PipelineID string           `json:"pipeline_id" binding:"omitempty,uuid"` // Defaults to the pipeline of the first stage
+	Stages     []StageOrderItem `json:"stages" binding:"required,min=1,dive"`
 }
 
 // StageOrderItem represents a stage order item
@@ -453,3 +646,376 @@ type ForecastDeal struct {
 	WeightedValueFormatted string     `json:"weighted_value_formatted"`
 	ExpectedCloseDate      *time.Time `json:"expected_close_date"`
 }
+
+// ExportColumns are the columns of a deal export
+var ExportColumns = []export.Column{
+	{Key: "id", Header: "ID"},
+	{Key: "title", Header: "Title"},
+	{Key: "account", Header: "Account"},
+	{Key: "contact", Header: "Contact"},
+	{Key: "stage", Header: "Stage"},
+	{Key: "status", Header: "Status"},
+	{Key: "value", Header: "Value (Rp)"},
+	{Key: "probability", Header: "Probability (%)"},
+	{Key: "expected_close_date", Header: "Expected Close Date"},
+	{Key: "actual_close_date", Header: "Actual Close Date"},
+	{Key: "assigned_to", Header: "Assigned To"},
+	{Key: "source", Header: "Source"},
+	{Key: "lead_id", Header: "Lead ID"},
+	{Key: "description", Header: "Description"},
+	{Key: "notes", Header: "Notes"},
+	{Key: "created_at", Header: "Created At"},
+	{Key: "updated_at", Header: "Updated At"},
+}
+
+// ExportRow returns the deal's values in the order of ExportColumns; the value is in rupiah
+func (d *Deal) ExportRow() []interface{} {
+	var account, contact, stage, assignedTo string
+	if d.Account != nil {
+		account = d.Account.Name
+	}
+	if d.Contact != nil {
+		contact = d.Contact.Name
+	}
+	if d.Stage != nil {
+		stage = d.Stage.Name
+	}
+	if d.AssignedUser != nil {
+		assignedTo = d.AssignedUser.Name
+	}
+	return []interface{}{
+		d.ID, d.Title, account, contact, stage, d.Status, float64(d.Value) / 100, d.Probability,
+		d.ExpectedCloseDate, d.ActualCloseDate, assignedTo, d.Source, d.LeadID, d.Description, d.Notes,
+		d.CreatedAt, d.UpdatedAt,
+	}
+}
+
+
+// Sources of a stage history entry
+const (
+	StageChangeCreate         = "create"          // Deal created in the stage
+	StageChangeMove           = "move"            // Moved on the pipeline board
+	StageChangeUpdate         = "update"          // Stage changed while editing the deal
+	StageChangeLeadConversion = "lead_conversion" // Deal created by converting a lead
+	StageChangeBackfill       = "backfill"        // Recorded for a deal that existed before stage history
+)
+
+// DealStageHistory records a period a deal spent in a stage. The entry of the current stage has no ExitedAt.
+type DealStageHistory struct {
+	ID          string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	DealID      string         `gorm:"type:uuid;not null;index" json:"deal_id"`
+	Deal        *Deal          `gorm:"foreignKey:DealID" json:"-"`
+	FromStageID *string        `gorm:"type:uuid" json:"from_stage_id"` // Nil for the first stage of a deal
+	FromStage   *PipelineStage `gorm:"foreignKey:FromStageID" json:"from_stage,omitempty"`
+	ToStageID   string         `gorm:"type:uuid;not null;index" json:"to_stage_id"`
+	ToStage     *PipelineStage `gorm:"foreignKey:ToStageID" json:"to_stage,omitempty"`
+	EnteredAt   time.Time      `gorm:"type:timestamp;not null;index" json:"entered_at"`
+	ExitedAt    *time.Time     `gorm:"type:timestamp" json:"exited_at"`
+	ChangedBy   *string        `gorm:"type:uuid" json:"changed_by"`
+	ChangedUser *UserRef       `gorm:"foreignKey:ChangedBy" json:"changed_user,omitempty"`
+	Source      string         `gorm:"type:varchar(20);not null" json:"source"` // create, move, update, lead_conversion, backfill
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// TableName specifies the table name for DealStageHistory
+func (DealStageHistory) TableName() string {
+	return "deal_stage_history"
+}
+
+// BeforeCreate hook to generate UUID
+func (h *DealStageHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == "" {
+		h.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// DealStageHistoryResponse represents deal stage history response DTO
+type DealStageHistoryResponse struct {
+	ID            string                 `json:"id"`
+	DealID        string                 `json:"deal_id"`
+	FromStageID   *string                `json:"from_stage_id"`
+	FromStage     *PipelineStageResponse `json:"from_stage,omitempty"`
+	ToStageID     string                 `json:"to_stage_id"`
+	ToStage       *PipelineStageResponse `json:"to_stage,omitempty"`
+	EnteredAt     time.Time              `json:"entered_at"`
+	ExitedAt      *time.Time             `json:"exited_at"`
+	DurationHours float64                `json:"duration_hours"` // Time in the stage so far for the current stage
+	ChangedBy     *string                `json:"changed_by"`
+	ChangedUser   *UserRefResponse       `json:"changed_user,omitempty"`
+	Source        string                 `json:"source"`
+}
+
+// ToDealStageHistoryResponse converts DealStageHistory to DealStageHistoryResponse; now ends the current stage
+func (h *DealStageHistory) ToDealStageHistoryResponse(now time.Time) *DealStageHistoryResponse {
+	resp := &DealStageHistoryResponse{
+		ID:            h.ID,
+		DealID:        h.DealID,
+		FromStageID:   h.FromStageID,
+		ToStageID:     h.ToStageID,
+		EnteredAt:     h.EnteredAt,
+		ExitedAt:      h.ExitedAt,
+		DurationHours: h.Duration(now).Hours(),
+		ChangedBy:     h.ChangedBy,
+		Source:        h.Source,
+	}
+	if h.FromStage != nil {
+		resp.FromStage = h.FromStage.ToPipelineStageResponse()
+	}
+	if h.ToStage != nil {
+		resp.ToStage = h.ToStage.ToPipelineStageResponse()
+	}
+	if h.ChangedUser != nil {
+		resp.ChangedUser = &UserRefResponse{
+			ID:        h.ChangedUser.ID,
+			Name:      h.ChangedUser.Name,
+			Email:     h.ChangedUser.Email,
+			AvatarURL: h.ChangedUser.AvatarURL,
+		}
+	}
+	return resp
+}
+
+// Duration returns the time the deal spent in the stage, up to now for the current stage
+func (h *DealStageHistory) Duration(now time.Time) time.Duration {
+	end := now
+	if h.ExitedAt != nil {
+		end = *h.ExitedAt
+	}
+	if end.Before(h.EnteredAt) {
+		return 0
+	}
+	return end.Sub(h.EnteredAt)
+}
+
+// PipelineAnalyticsRequest represents pipeline analytics query parameters. The analytics cover the deals
+// created in the period; it defaults to the last 90 days.
+type PipelineAnalyticsRequest struct {
+	PipelineID string `form:"pipeline_id" binding:"omitempty,uuid"` // Defaults to the default pipeline
+	StartDate  string `form:"start_date" binding:"omitempty,datetime=2006-01-02"`
+	EndDate    string `form:"end_date" binding:"omitempty,datetime=2006-01-02"`
+	AssignedTo string `form:"assigned_to" binding:"omitempty,uuid"`
+}
+
+// PipelineAnalyticsResponse represents pipeline analytics built from the stage history
+type PipelineAnalyticsResponse struct {
+	PipelineID  string            `json:"pipeline_id"`
+	Period      ForecastPeriod    `json:"period"`
+	TimeInStage []StageTimeStat   `json:"time_in_stage"`
+	Conversions []StageConversion `json:"conversions"`
+	Velocity    PipelineVelocity  `json:"velocity"`
+	Funnel      []FunnelStage     `json:"funnel"`
+}
+
+// StageTimeStat represents the time deals spent in a stage
+type StageTimeStat struct {
+	StageID        string  `json:"stage_id"`
+	StageName      string  `json:"stage_name"`
+	StageCode      string  `json:"stage_code"`
+	CompletedStays int     `json:"completed_stays"` // Stays that ended by leaving the stage
+	CurrentDeals   int     `json:"current_deals"`   // Deals in the stage now
+	AvgDays        float64 `json:"avg_days"`        // Average of the completed stays
+	MedianDays     float64 `json:"median_days"`
+}
+
+// StageConversion represents how many deals that reached a stage went on to reach the next one
+type StageConversion struct {
+	FromStageID   string  `json:"from_stage_id"`
+	FromStageName string  `json:"from_stage_name"`
+	ToStageID     string  `json:"to_stage_id"`
+	ToStageName   string  `json:"to_stage_name"`
+	Reached       int     `json:"reached"`   // Deals that reached the from stage
+	Converted     int     `json:"converted"` // Of those, deals that reached the to stage
+	Rate          float64 `json:"rate"`      // Percentage
+}
+
+// PipelineVelocity represents the revenue the pipeline produces per day:
+// open deals × average deal value × win rate ÷ average sales cycle
+type PipelineVelocity struct {
+	OpenDeals               int     `json:"open_deals"`
+	AvgDealValue            int64   `json:"avg_deal_value"` // Of the open deals, in sen
+	AvgDealValueFormatted   string  `json:"avg_deal_value_formatted"`
+	WonDeals                int     `json:"won_deals"`
+	LostDeals               int     `json:"lost_deals"`
+	WinRate                 float64 `json:"win_rate"`         // Percentage of closed deals that were won
+	AvgCycleDays            float64 `json:"avg_cycle_days"`   // From the first stage of a won deal to its win
+	VelocityPerDay          int64   `json:"velocity_per_day"` // In sen
+	VelocityPerDayFormatted string  `json:"velocity_per_day_formatted"`
+}
+
+// FunnelStage represents the deals that reached a stage, counting the stages they skipped on the way
+type FunnelStage struct {
+	StageID        string  `json:"stage_id"`
+	StageName      string  `json:"stage_name"`
+	StageCode      string  `json:"stage_code"`
+	Color          string  `json:"color"`
+	Deals          int     `json:"deals"`
+	Value          int64   `json:"value"` // In sen
+	ValueFormatted string  `json:"value_formatted"`
+	Percentage     float64 `json:"percentage"` // Of the deals that entered the funnel
+}
+
+// FormatValues fills in the formatted currency fields of the analytics
+func (a *PipelineAnalyticsResponse) FormatValues() {
+	a.Velocity.AvgDealValueFormatted = formatCurrency(a.Velocity.AvgDealValue)
+	a.Velocity.VelocityPerDayFormatted = formatCurrency(a.Velocity.VelocityPerDay)
+	for i := range a.Funnel {
+		a.Funnel[i].ValueFormatted = formatCurrency(a.Funnel[i].Value)
+	}
+}
+
+// DefaultTaxRate is the VAT (PPN) percentage applied to line items of taxable products when none is given
+const DefaultTaxRate = 11.0
+
+// DealItem represents a product line of a deal. The deal value is the sum of the totals of its items.
+type DealItem struct {
+	ID              string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	DealID          string         `gorm:"type:uuid;not null;index" json:"deal_id"`
+	ProductID       string         `gorm:"type:uuid;not null;index" json:"product_id"`
+	Product         *ProductRef    `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	Quantity        int            `gorm:"type:integer;not null;default:1" json:"quantity"`
+	UnitPrice       int64          `gorm:"type:bigint;not null;default:0" json:"unit_price"`             // In sen; defaults to the product price
+	DiscountPercent float64        `gorm:"type:numeric(5,2);not null;default:0" json:"discount_percent"` // 0-100
+	DiscountAmount  int64          `gorm:"type:bigint;not null;default:0" json:"discount_amount"`        // In sen
+	TaxRate         float64        `gorm:"type:numeric(5,2);not null;default:0" json:"tax_rate"`         // Percentage; 0 for products that are not taxable
+	TaxAmount       int64          `gorm:"type:bigint;not null;default:0" json:"tax_amount"`             // In sen, on the discounted amount
+	Subtotal        int64          `gorm:"type:bigint;not null;default:0" json:"subtotal"`               // Quantity × unit price, in sen
+	Total           int64          `gorm:"type:bigint;not null;default:0" json:"total"`                  // Subtotal - discount + tax, in sen
+	Notes           string         `gorm:"type:text" json:"notes"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for DealItem
+func (DealItem) TableName() string {
+	return "deal_items"
+}
+
+// BeforeCreate hook to generate UUID
+func (i *DealItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Calculate fills in the subtotal, discount, tax and total of the item from its quantity, unit price,
+// discount percentage and tax rate
+func (i *DealItem) Calculate() {
+	i.Subtotal = int64(i.Quantity) * i.UnitPrice
+	i.DiscountAmount = int64(math.Round(float64(i.Subtotal) * i.DiscountPercent / 100))
+	net := i.Subtotal - i.DiscountAmount
+	i.TaxAmount = int64(math.Round(float64(net) * i.TaxRate / 100))
+	i.Total = net + i.TaxAmount
+}
+
+// NetAmount returns the amount of the item after discount and before tax, which counts as revenue
+func (i *DealItem) NetAmount() int64 {
+	return i.Subtotal - i.DiscountAmount
+}
+
+// ProductRef represents product reference in deal item
+type ProductRef struct {
+	ID         string `gorm:"type:uuid;primary_key" json:"id"`
+	Name       string `json:"name"`
+	SKU        string `json:"sku"`
+	CategoryID string `json:"category_id"`
+	Taxable    bool   `json:"taxable"`
+}
+
+// TableName specifies the table name for ProductRef
+func (ProductRef) TableName() string {
+	return "products"
+}
+
+// DealItemResponse represents deal item response DTO
+type DealItemResponse struct {
+	ID                      string      `json:"id"`
+	DealID                  string      `json:"deal_id"`
+	ProductID               string      `json:"product_id"`
+	Product                 *ProductRef `json:"product,omitempty"`
+	Quantity                int         `json:"quantity"`
+	UnitPrice               int64       `json:"unit_price"`
+	UnitPriceFormatted      string      `json:"unit_price_formatted"`
+	DiscountPercent         float64     `json:"discount_percent"`
+	DiscountAmount          int64       `json:"discount_amount"`
+	DiscountAmountFormatted string      `json:"discount_amount_formatted"`
+	TaxRate                 float64     `json:"tax_rate"`
+	TaxAmount               int64       `json:"tax_amount"`
+	TaxAmountFormatted      string      `json:"tax_amount_formatted"`
+	Subtotal                int64       `json:"subtotal"`
+	SubtotalFormatted       string      `json:"subtotal_formatted"`
+	Total                   int64       `json:"total"`
+	TotalFormatted          string      `json:"total_formatted"`
+	Notes                   string      `json:"notes"`
+	CreatedAt               time.Time   `json:"created_at"`
+	UpdatedAt               time.Time   `json:"updated_at"`
+}
+
+// ToDealItemResponse converts DealItem to DealItemResponse
+func (i *DealItem) ToDealItemResponse() *DealItemResponse {
+	return &DealItemResponse{
+		ID:                      i.ID,
+		DealID:                  i.DealID,
+		ProductID:               i.ProductID,
+		Product:                 i.Product,
+		Quantity:                i.Quantity,
+		UnitPrice:               i.UnitPrice,
+		UnitPriceFormatted:      formatCurrency(i.UnitPrice),
+		DiscountPercent:         i.DiscountPercent,
+		DiscountAmount:          i.DiscountAmount,
+		DiscountAmountFormatted: formatCurrency(i.DiscountAmount),
+		TaxRate:                 i.TaxRate,
+		TaxAmount:               i.TaxAmount,
+		TaxAmountFormatted:      formatCurrency(i.TaxAmount),
+		Subtotal:                i.Subtotal,
+		SubtotalFormatted:       formatCurrency(i.Subtotal),
+		Total:                   i.Total,
+		TotalFormatted:          formatCurrency(i.Total),
+		Notes:                   i.Notes,
+		CreatedAt:               i.CreatedAt,
+		UpdatedAt:               i.UpdatedAt,
+	}
+}
+
+// CreateDealItemRequest represents create deal item request DTO
+type CreateDealItemRequest struct {
+	ProductID       string   `json:"product_id" binding:"required,uuid"`
+	Quantity        int      `json:"quantity" binding:"required,min=1"`
+	UnitPrice       *int64   `json:"unit_price" binding:"omitempty,min=0"` // Defaults to the product price
+	DiscountPercent float64  `json:"discount_percent" binding:"omitempty,min=0,max=100"`
+	TaxRate         *float64 `json:"tax_rate" binding:"omitempty,min=0,max=100"` // Defaults to DefaultTaxRate; ignored for products that are not taxable
+	Notes           string   `json:"notes" binding:"omitempty"`
+}
+
+// UpdateDealItemRequest represents update deal item request DTO
+type UpdateDealItemRequest struct {
+	Quantity        *int     `json:"quantity" binding:"omitempty,min=1"`
+	UnitPrice       *int64   `json:"unit_price" binding:"omitempty,min=0"`
+	DiscountPercent *float64 `json:"discount_percent" binding:"omitempty,min=0,max=100"`
+	TaxRate         *float64 `json:"tax_rate" binding:"omitempty,min=0,max=100"` // Ignored for products that are not taxable
+	Notes           *string  `json:"notes" binding:"omitempty"`
+}
+
+// ProductRevenueRequest filters the deals whose line items count towards product revenue
+type ProductRevenueRequest struct {
+	PipelineID string
+	AssignedTo string
+	Status     string     // open, won or lost; all deals when empty
+	ClosedFrom *time.Time // With ClosedTo, only deals closed in the range
+	ClosedTo   *time.Time
+}
+
+// ProductRevenue represents the line items of one product across the deals of a report
+type ProductRevenue struct {
+	ProductID    string `json:"product_id"`
+	ProductName  string `json:"product_name"`
+	SKU          string `json:"sku"`
+	CategoryID   string `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	Deals        int    `json:"deals"`
+	Quantity     int64  `json:"quantity"`
+	Revenue      int64  `json:"revenue"`     // After discount and before tax, in sen
+	WonRevenue   int64  `json:"won_revenue"` // Of the won deals, in sen
+}
@@ -0,0 +1,400 @@
+package quotation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Quotation statuses
+const (
+	StatusDraft    = "draft"
+	StatusSent     = "sent"
+	StatusAccepted = "accepted"
+	StatusRejected = "rejected"
+)
+
+const (
+	// NumberPrefix starts every quotation number, e.g. QUO-202610-0001
+	NumberPrefix = "QUO"
+	// DefaultValidityDays is how long a quotation is valid when no valid_until date is given
+	DefaultValidityDays = 30
+	// DefaultPaymentTerms are printed when no payment terms are given
+	DefaultPaymentTerms = "30 days after invoice"
+)
+
+// Quotation represents a numbered quote for the products of a deal. Its content lives in immutable
+// revisions; CurrentRevision is the revision that is sent, accepted or rejected.
+type Quotation struct {
+	ID              string              `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Number          string              `gorm:"type:varchar(30);not null;uniqueIndex" json:"number"`
+	DealID          string              `gorm:"type:uuid;not null;index" json:"deal_id"`
+	AccountID       string              `gorm:"type:uuid;not null;index" json:"account_id"`
+	Title           string              `gorm:"type:varchar(255);not null" json:"title"`
+	Status          string              `gorm:"type:varchar(20);not null;default:'draft';index" json:"status"` // draft, sent, accepted, rejected
+	CurrentRevision int                 `gorm:"type:integer;not null;default:1" json:"current_revision"`
+	SentAt          *time.Time          `gorm:"type:timestamp" json:"sent_at"`
+	AcceptedAt      *time.Time          `gorm:"type:timestamp" json:"accepted_at"`
+	RejectedAt      *time.Time          `gorm:"type:timestamp" json:"rejected_at"`
+	RejectionReason string              `gorm:"type:text" json:"rejection_reason"`
+	CreatedBy       string              `gorm:"type:uuid;index" json:"created_by"`
+	Revisions       []QuotationRevision `gorm:"foreignKey:QuotationID" json:"revisions,omitempty"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt      `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for Quotation
+func (Quotation) TableName() string {
+	return "quotations"
+}
+
+// BeforeCreate hook to generate UUID
+func (q *Quotation) BeforeCreate(tx *gorm.DB) error {
+	if q.ID == "" {
+		q.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Revision returns the loaded revision with the given number, or nil
+func (q *Quotation) Revision(number int) *QuotationRevision {
+	for i := range q.Revisions {
+		if q.Revisions[i].Revision == number {
+			return &q.Revisions[i]
+		}
+	}
+	return nil
+}
+
+// QuotationRevision is one immutable version of a quotation: its terms, the recipient and the line items
+// as they were when the revision was made, and the PDF rendered from them
+type QuotationRevision struct {
+	ID            string          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	QuotationID   string          `gorm:"type:uuid;not null;uniqueIndex:idx_quotation_revisions_revision" json:"quotation_id"`
+	Revision      int             `gorm:"type:integer;not null;uniqueIndex:idx_quotation_revisions_revision" json:"revision"` // 1 for the first version
+	IssueDate     time.Time       `gorm:"type:date;not null" json:"issue_date"`
+	ValidUntil    time.Time       `gorm:"type:date;not null" json:"valid_until"`
+	PaymentTerms  string          `gorm:"type:varchar(255)" json:"payment_terms"`
+	Notes         string          `gorm:"type:text" json:"notes"`
+	CustomerName  string          `gorm:"type:varchar(255)" json:"customer_name"` // Account name when the revision was made
+	ContactName   string          `gorm:"type:varchar(255)" json:"contact_name"`
+	ContactEmail  string          `gorm:"type:varchar(255)" json:"contact_email"`
+	Subtotal      int64           `gorm:"type:bigint;not null;default:0" json:"subtotal"` // Amounts in sen
+	DiscountTotal int64           `gorm:"type:bigint;not null;default:0" json:"discount_total"`
+	TaxTotal      int64           `gorm:"type:bigint;not null;default:0" json:"tax_total"`
+	Total         int64           `gorm:"type:bigint;not null;default:0" json:"total"`
+	FileName      string          `gorm:"type:varchar(255)" json:"-"` // Storage name of the rendered PDF, served only through the API
+	Items         []QuotationItem `gorm:"foreignKey:RevisionID" json:"items,omitempty"`
+	CreatedBy     string          `gorm:"type:uuid" json:"created_by"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// TableName specifies the table name for QuotationRevision
+func (QuotationRevision) TableName() string {
+	return "quotation_revisions"
+}
+
+// BeforeCreate hook to generate UUID
+func (r *QuotationRevision) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// CalculateTotals sums the amounts of the line items into the totals of the revision
+func (r *QuotationRevision) CalculateTotals() {
+	r.Subtotal, r.DiscountTotal, r.TaxTotal, r.Total = 0, 0, 0, 0
+	for _, item := range r.Items {
+		r.Subtotal += item.Subtotal
+		r.DiscountTotal += item.DiscountAmount
+		r.TaxTotal += item.TaxAmount
+		r.Total += item.Total
+	}
+}
+
+// TaxSummary groups the line items by tax rate, lowest rate first
+func (r *QuotationRevision) TaxSummary() []TaxLine {
+	byRate := make(map[float64]*TaxLine)
+	for _, item := range r.Items {
+		line, ok := byRate[item.TaxRate]
+		if !ok {
+			line = &TaxLine{Rate: item.TaxRate}
+			byRate[item.TaxRate] = line
+		}
+		line.TaxableAmount += item.Subtotal - item.DiscountAmount
+		line.Tax += item.TaxAmount
+	}
+
+	lines := make([]TaxLine, 0, len(byRate))
+	for _, line := range byRate {
+		lines = append(lines, *line)
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].Rate < lines[j].Rate })
+	return lines
+}
+
+// NewFileName returns a random storage name for the PDF of a revision, so stored quotes cannot be enumerated
+func NewFileName() string {
+	return fmt.Sprintf("quotation-%s.pdf", uuid.New().String())
+}
+
+// DownloadFileName returns the name the PDF of a quotation revision is downloaded as, e.g. QUO-202610-0001-R2.pdf
+func DownloadFileName(number string, revision int) string {
+	return fmt.Sprintf("%s-R%d.pdf", number, revision)
+}
+
+// PDFPath returns the API path serving the PDF of a quotation revision
+func PDFPath(quotationID string, revision int) string {
+	return fmt.Sprintf("/api/v1/quotations/%s/revisions/%d/pdf", quotationID, revision)
+}
+
+// QuotationItem is a deal line item copied into a quotation revision
+type QuotationItem struct {
+	ID              string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RevisionID      string    `gorm:"type:uuid;not null;index" json:"revision_id"`
+	ProductID       string    `gorm:"type:uuid;not null" json:"product_id"`
+	ProductName     string    `gorm:"type:varchar(255);not null" json:"product_name"`
+	SKU             string    `gorm:"type:varchar(100)" json:"sku"`
+	Quantity        int       `gorm:"type:integer;not null" json:"quantity"`
+	UnitPrice       int64     `gorm:"type:bigint;not null" json:"unit_price"` // Amounts in sen
+	DiscountPercent float64   `gorm:"type:decimal(5,2);not null;default:0" json:"discount_percent"`
+	DiscountAmount  int64     `gorm:"type:bigint;not null;default:0" json:"discount_amount"`
+	TaxRate         float64   `gorm:"type:decimal(5,2);not null;default:0" json:"tax_rate"`
+	TaxAmount       int64     `gorm:"type:bigint;not null;default:0" json:"tax_amount"`
+	Subtotal        int64     `gorm:"type:bigint;not null" json:"subtotal"`
+	Total           int64     `gorm:"type:bigint;not null" json:"total"`
+	Notes           string    `gorm:"type:text" json:"notes"`
+	SortOrder       int       `gorm:"type:integer;not null;default:0" json:"sort_order"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for QuotationItem
+func (QuotationItem) TableName() string {
+	return "quotation_items"
+}
+
+// BeforeCreate hook to generate UUID
+func (i *QuotationItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TaxLine is the taxable amount and tax of the line items sharing a tax rate
+type TaxLine struct {
+	Rate          float64 `json:"rate"`
+	TaxableAmount int64   `json:"taxable_amount"`
+	Tax           int64   `json:"tax"`
+}
+
+// QuotationResponse represents quotation response DTO; Revision is the current revision when it was loaded
+type QuotationResponse struct {
+	ID              string                     `json:"id"`
+	Number          string                     `json:"number"`
+	DealID          string                     `json:"deal_id"`
+	AccountID       string                     `json:"account_id"`
+	Title           string                     `json:"title"`
+	Status          string                     `json:"status"`
+	CurrentRevision int                        `json:"current_revision"`
+	Total           int64                      `json:"total"`
+	TotalFormatted  string                     `json:"total_formatted"`
+	ValidUntil      *time.Time                 `json:"valid_until"`
+	SentAt          *time.Time                 `json:"sent_at"`
+	AcceptedAt      *time.Time                 `json:"accepted_at"`
+	RejectedAt      *time.Time                 `json:"rejected_at"`
+	RejectionReason string                     `json:"rejection_reason"`
+	Revision        *QuotationRevisionResponse `json:"revision,omitempty"`
+	CreatedBy       string                     `json:"created_by"`
+	CreatedAt       time.Time                  `json:"created_at"`
+	UpdatedAt       time.Time                  `json:"updated_at"`
+}
+
+// ToQuotationResponse converts Quotation to QuotationResponse
+func (q *Quotation) ToQuotationResponse() *QuotationResponse {
+	resp := &QuotationResponse{
+		ID:              q.ID,
+		Number:          q.Number,
+		DealID:          q.DealID,
+		AccountID:       q.AccountID,
+		Title:           q.Title,
+		Status:          q.Status,
+		CurrentRevision: q.CurrentRevision,
+		SentAt:          q.SentAt,
+		AcceptedAt:      q.AcceptedAt,
+		RejectedAt:      q.RejectedAt,
+		RejectionReason: q.RejectionReason,
+		CreatedBy:       q.CreatedBy,
+		CreatedAt:       q.CreatedAt,
+		UpdatedAt:       q.UpdatedAt,
+	}
+
+	if current := q.Revision(q.CurrentRevision); current != nil {
+		resp.Total = current.Total
+		resp.TotalFormatted = formatCurrency(current.Total)
+		validUntil := current.ValidUntil
+		resp.ValidUntil = &validUntil
+		if current.Items != nil {
+			resp.Revision = current.ToQuotationRevisionResponse()
+		}
+	}
+
+	return resp
+}
+
+// QuotationRevisionResponse represents quotation revision response DTO
+type QuotationRevisionResponse struct {
+	ID                     string                  `json:"id"`
+	QuotationID            string                  `json:"quotation_id"`
+	Revision               int                     `json:"revision"`
+	IssueDate              time.Time               `json:"issue_date"`
+	ValidUntil             time.Time               `json:"valid_until"`
+	PaymentTerms           string                  `json:"payment_terms"`
+	Notes                  string                  `json:"notes"`
+	CustomerName           string                  `json:"customer_name"`
+	ContactName            string                  `json:"contact_name"`
+	ContactEmail           string                  `json:"contact_email"`
+	Items                  []QuotationItemResponse `json:"items"`
+	TaxSummary             []TaxLine               `json:"tax_summary"`
+	Subtotal               int64                   `json:"subtotal"`
+	SubtotalFormatted      string                  `json:"subtotal_formatted"`
+	DiscountTotal          int64                   `json:"discount_total"`
+	DiscountTotalFormatted string                  `json:"discount_total_formatted"`
+	TaxTotal               int64                   `json:"tax_total"`
+	TaxTotalFormatted      string                  `json:"tax_total_formatted"`
+	Total                  int64                   `json:"total"`
+	TotalFormatted         string                  `json:"total_formatted"`
+	PDFURL                 string                  `json:"pdf_url"`
+	CreatedBy              string                  `json:"created_by"`
+	CreatedAt              time.Time               `json:"created_at"`
+}
+
+// ToQuotationRevisionResponse converts QuotationRevision to QuotationRevisionResponse
+func (r *QuotationRevision) ToQuotationRevisionResponse() *QuotationRevisionResponse {
+	items := make([]QuotationItemResponse, len(r.Items))
+	for i := range r.Items {
+		items[i] = *r.Items[i].ToQuotationItemResponse()
+	}
+
+	return &QuotationRevisionResponse{
+		ID:                     r.ID,
+		QuotationID:            r.QuotationID,
+		Revision:               r.Revision,
+		IssueDate:              r.IssueDate,
+		ValidUntil:             r.ValidUntil,
+		PaymentTerms:           r.PaymentTerms,
+		Notes:                  r.Notes,
+		CustomerName:           r.CustomerName,
+		ContactName:            r.ContactName,
+		ContactEmail:           r.ContactEmail,
+		Items:                  items,
+		TaxSummary:             r.TaxSummary(),
+		Subtotal:               r.Subtotal,
+		SubtotalFormatted:      formatCurrency(r.Subtotal),
+		DiscountTotal:          r.DiscountTotal,
+		DiscountTotalFormatted: formatCurrency(r.DiscountTotal),
+		TaxTotal:               r.TaxTotal,
+		TaxTotalFormatted:      formatCurrency(r.TaxTotal),
+		Total:                  r.Total,
+		TotalFormatted:         formatCurrency(r.Total),
+		PDFURL:                 PDFPath(r.QuotationID, r.Revision),
+		CreatedBy:              r.CreatedBy,
+		CreatedAt:              r.CreatedAt,
+	}
+}
+
+// QuotationItemResponse represents quotation item response DTO
+type QuotationItemResponse struct {
+	ID                 string  `json:"id"`
+	ProductID          string  `json:"product_id"`
+	ProductName        string  `json:"product_name"`
+	SKU                string  `json:"sku"`
+	Quantity           int     `json:"quantity"`
+	UnitPrice          int64   `json:"unit_price"`
+	UnitPriceFormatted string  `json:"unit_price_formatted"`
+	DiscountPercent    float64 `json:"discount_percent"`
+	DiscountAmount     int64   `json:"discount_amount"`
+	TaxRate            float64 `json:"tax_rate"`
+	TaxAmount          int64   `json:"tax_amount"`
+	Subtotal           int64   `json:"subtotal"`
+	Total              int64   `json:"total"`
+	TotalFormatted     string  `json:"total_formatted"`
+	Notes              string  `json:"notes"`
+}
+
+// ToQuotationItemResponse converts QuotationItem to QuotationItemResponse
+func (i *QuotationItem) ToQuotationItemResponse() *QuotationItemResponse {
+	return &QuotationItemResponse{
+		ID:                 i.ID,
+		ProductID:          i.ProductID,
+		ProductName:        i.ProductName,
+		SKU:                i.SKU,
+		Quantity:           i.Quantity,
+		UnitPrice:          i.UnitPrice,
+		UnitPriceFormatted: formatCurrency(i.UnitPrice),
+		DiscountPercent:    i.DiscountPercent,
+		DiscountAmount:     i.DiscountAmount,
+		TaxRate:            i.TaxRate,
+		TaxAmount:          i.TaxAmount,
+		Subtotal:           i.Subtotal,
+		Total:              i.Total,
+		TotalFormatted:     formatCurrency(i.Total),
+		Notes:              i.Notes,
+	}
+}
+
+// formatCurrency formats an amount in sen as rupiah, e.g. Rp 1.500.000
+func formatCurrency(amount int64) string {
+	rupiah := amount / 100
+	sign := ""
+	if rupiah < 0 {
+		sign, rupiah = "-", -rupiah
+	}
+	digits := fmt.Sprintf("%d", rupiah)
+	var parts []string
+	for len(digits) > 3 {
+		parts = append([]string{digits[len(digits)-3:]}, parts...)
+		digits = digits[:len(digits)-3]
+	}
+	parts = append([]string{digits}, parts...)
+	return "Rp " + sign + strings.Join(parts, ".")
+}
+
+// CreateQuotationRequest represents create quotation request DTO. The line items are taken from the deal;
+// valid_until defaults to DefaultValidityDays after today.
+type CreateQuotationRequest struct {
+	DealID       string  `json:"deal_id" binding:"required,uuid"`
+	Title        string  `json:"title" binding:"omitempty,max=255"` // Defaults to the deal title
+	ValidUntil   *string `json:"valid_until" binding:"omitempty,datetime=2006-01-02"`
+	PaymentTerms string  `json:"payment_terms" binding:"omitempty,max=255"`
+	Notes        string  `json:"notes" binding:"omitempty"`
+}
+
+// ReviseQuotationRequest represents revise quotation request DTO. The new revision copies the current line
+// items of the deal; terms that are left out are carried over from the previous revision.
+type ReviseQuotationRequest struct {
+	ValidUntil   *string `json:"valid_until" binding:"omitempty,datetime=2006-01-02"`
+	PaymentTerms *string `json:"payment_terms" binding:"omitempty,max=255"`
+	Notes        *string `json:"notes" binding:"omitempty"`
+}
+
+// RejectQuotationRequest represents reject quotation request DTO
+type RejectQuotationRequest struct {
+	Reason string `json:"reason" binding:"omitempty,max=1000"`
+}
+
+// ListQuotationsRequest represents list quotations query parameters
+type ListQuotationsRequest struct {
+	Page      int    `form:"page" binding:"omitempty,min=1"`
+	PerPage   int    `form:"per_page" binding:"omitempty,min=1,max=100"`
+	Search    string `form:"search" binding:"omitempty"` // Number or title
+	DealID    string `form:"deal_id" binding:"omitempty,uuid"`
+	AccountID string `form:"account_id" binding:"omitempty,uuid"`
+	Status    string `form:"status" binding:"omitempty,oneof=draft sent accepted rejected"`
+}
@@ -7,15 +7,27 @@ import (
 	"gorm.io/gorm"
 )
 
+// Delivery statuses of a reminder
+const (
+	DeliveryStatusPending   = "pending" // Not due yet
+	DeliveryStatusQueued    = "queued"  // Handed to a delivery channel that reports back, e.g. the email outbox
+	DeliveryStatusSent      = "sent"
+	DeliveryStatusDelivered = "delivered" // Confirmed by a delivery receipt of the SMS or WhatsApp provider
+	DeliveryStatusFailed    = "failed"
+	DeliveryStatusSkipped   = "skipped" // The channel is not available or the user did not opt in
+)
+
 // Reminder represents a reminder for a task
 type Reminder struct {
 	ID          string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	TaskID      string         `gorm:"type:uuid;not null;index" json:"task_id"`
 	Task        *TaskRef       `gorm:"foreignKey:TaskID" json:"task,omitempty"`
 	RemindAt    time.Time      `gorm:"type:timestamp;not null" json:"remind_at"`
-	ReminderType string        `gorm:"type:varchar(50);not null;default:'in_app'" json:"reminder_type"` // in_app, email, sms
+	ReminderType string        `gorm:"type:varchar(50);not null;default:'in_app'" json:"reminder_type"` // in_app, email, sms, whatsapp
 	IsSent      bool           `gorm:"type:boolean;default:false" json:"is_sent"`
 	SentAt      *time.Time     `gorm:"type:timestamp" json:"sent_at"`
+	DeliveryStatus string      `gorm:"type:varchar(20);not null;default:'pending'" json:"delivery_status"` // pending, queued, sent, delivered, failed, skipped
+	DeliveryError  string      `gorm:"type:text" json:"delivery_error"`
 	Message     string         `gorm:"type:text" json:"message"`
 	CreatedBy   string         `gorm:"type:uuid;index" json:"created_by"`
 	CreatedAt   time.Time      `json:"created_at"`
@@ -56,6 +68,8 @@ type ReminderResponse struct {
 	ReminderType string        `json:"reminder_type"`
 	IsSent      bool           `json:"is_sent"`
 	SentAt      *time.Time     `json:"sent_at"`
+	DeliveryStatus string      `json:"delivery_status"`
+	DeliveryError  string      `json:"delivery_error,omitempty"`
 	Message     string         `json:"message"`
 	CreatedBy   string         `json:"created_by"`
 	CreatedAt   time.Time      `json:"created_at"`
@@ -77,6 +91,8 @@ func (r *Reminder) ToReminderResponse() *ReminderResponse {
 		ReminderType: r.ReminderType,
 		IsSent:      r.IsSent,
 		SentAt:      r.SentAt,
+		DeliveryStatus: r.DeliveryStatus,
+		DeliveryError:  r.DeliveryError,
 		Message:     r.Message,
 		CreatedBy:   r.CreatedBy,
 		CreatedAt:   r.CreatedAt,
@@ -97,14 +113,14 @@ func (r *Reminder) ToReminderResponse() *ReminderResponse {
 type CreateReminderRequest struct {
 	TaskID      string    `json:"task_id" binding:"required,uuid"`
 	RemindAt    time.Time `json:"remind_at" binding:"required"`
-	ReminderType string   `json:"reminder_type" binding:"omitempty,oneof=in_app email sms"`
+	ReminderType string   `json:"reminder_type" binding:"omitempty,oneof=in_app email sms whatsapp"`
 	Message     string    `json:"message" binding:"omitempty"`
 }
 
 // UpdateReminderRequest represents update reminder request DTO
 type UpdateReminderRequest struct {
 	RemindAt    *time.Time `json:"remind_at" binding:"omitempty"`
-	ReminderType string    `json:"reminder_type" binding:"omitempty,oneof=in_app email sms"`
+	ReminderType string    `json:"reminder_type" binding:"omitempty,oneof=in_app email sms whatsapp"`
 	Message     string     `json:"message" binding:"omitempty"`
 }
 
@@ -113,7 +129,7 @@ type ListRemindersRequest struct {
 	Page         int    `form:"page" binding:"omitempty,min=1"`
 	PerPage      int    `form:"per_page" binding:"omitempty,min=1,max=100"`
 	TaskID       string `form:"task_id" binding:"omitempty,uuid"`
-	ReminderType string `form:"reminder_type" binding:"omitempty,oneof=in_app email sms"`
+	ReminderType string `form:"reminder_type" binding:"omitempty,oneof=in_app email sms whatsapp"`
 	IsSent       *bool  `form:"is_sent" binding:"omitempty"`
 	RemindAtFrom *time.Time `form:"remind_at_from" binding:"omitempty"`
 	RemindAtTo   *time.Time `form:"remind_at_to" binding:"omitempty"`
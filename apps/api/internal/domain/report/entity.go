@@ -1,6 +1,29 @@
 package report
 
-import "time"
+import (
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/sales_target"
+)
+
+// Report types, as used by report subscriptions
+const (
+	TypeVisitReports     = "visit_reports"
+	TypePipeline         = "pipeline"
+	TypeSalesPerformance = "sales_performance"
+	TypeAccountActivity  = "account_activity"
+	TypeWinLoss          = "win_loss"
+)
+
+// TypeTitles are the display names of the report types
+var TypeTitles = map[string]string{
+	TypeVisitReports:     "Visit Report",
+	TypePipeline:         "Pipeline Report",
+	TypeSalesPerformance: "Sales Performance Report",
+	TypeAccountActivity:  "Account Activity Report",
+	TypeWinLoss:          "Win/Loss Analysis",
+}
 
 // VisitReportReportResponse represents visit report report data
 type VisitReportReportResponse struct {
@@ -64,6 +87,9 @@ type PipelineReportResponse struct {
 	} `json:"summary"`
 	ByStage map[string]int `json:"by_stage"`
 	Deals   []DealReportItem `json:"deals,omitempty"` // Individual deals for Sales Funnel table
+	Analytics *pipeline.PipelineAnalyticsResponse `json:"analytics,omitempty"` // Time in stage, conversion, velocity and funnel of the deals created in the period
+	ByProduct         []ProductRevenueStat  `json:"by_product"`          // Line items of the deals of the report
+	ByProductCategory []CategoryRevenueStat `json:"by_product_category"`
 }
 
 // DealReportItem represents a deal in the sales funnel report
@@ -97,6 +123,30 @@ type SalesPerformanceReportResponse struct {
 		TotalAccounts    int     `json:"total_accounts"`
 		AverageVisitsPerAccount float64 `json:"average_visits_per_account"`
 	} `json:"summary"`
+	ByProduct         []ProductRevenueStat  `json:"by_product"` // Line items of the deals won in the period
+	ByProductCategory []CategoryRevenueStat `json:"by_product_category"`
+}
+
+// ProductRevenueStat represents the revenue of a product in the line items of deals
+type ProductRevenueStat struct {
+	ProductID    string  `json:"product_id"`
+	ProductName  string  `json:"product_name"`
+	SKU          string  `json:"sku"`
+	CategoryName string  `json:"category_name"`
+	Deals        int     `json:"deals"`
+	Quantity     int64   `json:"quantity"`
+	Revenue      float64 `json:"revenue"`     // After discount and before tax
+	WonRevenue   float64 `json:"won_revenue"` // Of the won deals
+}
+
+// CategoryRevenueStat represents the revenue of a product category in the line items of deals
+type CategoryRevenueStat struct {
+	CategoryID   string  `json:"category_id"`
+	CategoryName string  `json:"category_name"`
+	Products     int     `json:"products"`
+	Quantity     int64   `json:"quantity"`
+	Revenue      float64 `json:"revenue"`
+	WonRevenue   float64 `json:"won_revenue"`
 }
 
 // SalesPerformanceStat represents sales performance statistics
@@ -110,6 +160,8 @@ type SalesPerformanceStat struct {
 	AccountCount  int     `json:"account_count"`
 	ActivityCount int     `json:"activity_count"`
 	CompletionRate float64 `json:"completion_rate"`
+	// Attainment is set when the sales rep has a target in the period
+	Attainment *sales_target.Attainment `json:"attainment,omitempty"`
 }
 
 // AccountActivityReportResponse represents account activity report
@@ -153,13 +205,85 @@ type VisitDetail struct {
 	} `json:"sales_rep"`
 }
 
+// WinLossReportResponse represents the win/loss analysis of the deals closed in the period
+type WinLossReportResponse struct {
+	Period struct {
+		Start time.Time `json:"start"`
+		End   time.Time `json:"end"`
+	} `json:"period"`
+	Summary struct {
+		ClosedDeals int     `json:"closed_deals"`
+		WonDeals    int     `json:"won_deals"`
+		WonValue    float64 `json:"won_value"`
+		LostDeals   int     `json:"lost_deals"`
+		LostValue   float64 `json:"lost_value"`
+		WinRate     float64 `json:"win_rate"` // Percentage of the closed deals that were won
+	} `json:"summary"`
+	ByReason     []LossBreakdownStat  `json:"by_reason"`
+	ByCompetitor []LossBreakdownStat  `json:"by_competitor"`
+	ByStage      []LossBreakdownStat  `json:"by_stage"` // Stage the deals were lost from
+	BySalesRep   []WinLossRepStat     `json:"by_sales_rep"`
+	ByProduct    []WinLossProductStat `json:"by_product"` // Line items of the closed deals
+	LostDeals    []LostDealItem       `json:"lost_deals"`
+}
+
+// LossBreakdownStat represents the lost deals of one loss reason, competitor or stage
+type LossBreakdownStat struct {
+	ID    string  `json:"id"` // Empty for the lost deals without one
+	Name  string  `json:"name"`
+	Deals int     `json:"deals"`
+	Value float64 `json:"value"`
+	Share float64 `json:"share"` // Percentage of the lost deals
+}
+
+// WinLossRepStat represents the closed deals of a sales rep
+type WinLossRepStat struct {
+	SalesRep struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"sales_rep"`
+	WonDeals  int     `json:"won_deals"`
+	WonValue  float64 `json:"won_value"`
+	LostDeals int     `json:"lost_deals"`
+	LostValue float64 `json:"lost_value"`
+	WinRate   float64 `json:"win_rate"`
+}
+
+// WinLossProductStat represents the line items of a product in the closed deals
+type WinLossProductStat struct {
+	ProductID    string  `json:"product_id"`
+	ProductName  string  `json:"product_name"`
+	SKU          string  `json:"sku"`
+	CategoryName string  `json:"category_name"`
+	WonDeals     int     `json:"won_deals"`
+	WonRevenue   float64 `json:"won_revenue"`
+	LostDeals    int     `json:"lost_deals"`
+	LostRevenue  float64 `json:"lost_revenue"`
+	WinRate      float64 `json:"win_rate"`
+}
+
+// LostDealItem represents a lost deal in the win/loss analysis
+type LostDealItem struct {
+	ID            string     `json:"id"`
+	Title         string     `json:"title"`
+	AccountName   string     `json:"account_name"`
+	SalesRep      string     `json:"sales_rep"`
+	Value         float64    `json:"value"`
+	LostReason    string     `json:"lost_reason"`
+	Competitor    string     `json:"competitor"`
+	LostFromStage string     `json:"lost_from_stage"`
+	LostNotes     string     `json:"lost_notes"`
+	ClosedOn      *time.Time `json:"closed_on"`
+}
+
 // ReportRequest represents request parameters for reports
 type ReportRequest struct {
-	StartDate string `form:"start_date"`
-	EndDate   string `form:"end_date"`
-	AccountID string `form:"account_id"`
+	StartDate  string `form:"start_date"`
+	EndDate    string `form:"end_date"`
+	AccountID  string `form:"account_id"`
 	SalesRepID string `form:"sales_rep_id"`
-	Status    string `form:"status"`
-	Limit     int    `form:"limit"`
+	PipelineID string `form:"pipeline_id"` // Pipeline report only; the default pipeline's analytics when empty
+	Status     string `form:"status"`
+	Limit      int    `form:"limit"`
 }
 
@@ -0,0 +1,272 @@
+package report_subscription
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Relative report periods, resolved on the day a subscription runs
+const (
+	PeriodYesterday   = "yesterday"
+	PeriodLast7Days   = "last_7_days"
+	PeriodLastWeek    = "last_week" // Monday to Sunday of the previous week
+	PeriodLast30Days  = "last_30_days"
+	PeriodMonthToDate = "month_to_date"
+	PeriodLastMonth   = "last_month"
+	PeriodLastQuarter = "last_quarter"
+)
+
+// Delivery statuses
+const (
+	DeliveryStatusSent   = "sent"
+	DeliveryStatusFailed = "failed"
+)
+
+// Delivery triggers
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// ReportSubscription represents a report emailed to a list of recipients on a cron schedule.
+// The schedule is evaluated in WIB and NextRunAt is kept in sync with it.
+type ReportSubscription struct {
+	ID          string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name        string         `gorm:"type:varchar(100);not null" json:"name"`
+	ReportType  string         `gorm:"type:varchar(30);not null" json:"report_type"` // visit_reports, pipeline, sales_performance, account_activity, win_loss
+	Format      string         `gorm:"type:varchar(10);not null" json:"format"`      // csv, excel, pdf
+	Period      string         `gorm:"type:varchar(20);not null" json:"period"`      // Relative period, e.g. last_week
+	AccountID   *string        `gorm:"type:uuid" json:"account_id"`                  // Required for account_activity
+	SalesRepID  *string        `gorm:"type:uuid" json:"sales_rep_id"`
+	VisitStatus string         `gorm:"type:varchar(20)" json:"visit_status"`       // Visit report status filter
+	Recipients  string         `gorm:"type:text;not null" json:"recipients"`       // Comma-separated email addresses
+	Schedule    string         `gorm:"type:varchar(100);not null" json:"schedule"` // Five-field cron expression
+	IsActive    bool           `gorm:"not null;default:true" json:"is_active"`
+	NextRunAt   *time.Time     `gorm:"type:timestamp;index" json:"next_run_at"` // Nil while inactive
+	LastRunAt   *time.Time     `gorm:"type:timestamp" json:"last_run_at"`
+	LastStatus  string         `gorm:"type:varchar(20)" json:"last_status"` // Status of the last delivery
+	CreatedBy   string         `gorm:"type:uuid" json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for ReportSubscription
+func (ReportSubscription) TableName() string {
+	return "report_subscriptions"
+}
+
+// BeforeCreate hook to generate UUID
+func (s *ReportSubscription) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// RecipientList returns the recipients as a slice
+func (s *ReportSubscription) RecipientList() []string {
+	return splitList(s.Recipients)
+}
+
+// ReportDelivery represents one attempt to email a subscribed report
+type ReportDelivery struct {
+	ID             string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	SubscriptionID string    `gorm:"type:uuid;not null;index" json:"subscription_id"`
+	Trigger        string    `gorm:"type:varchar(20);not null" json:"trigger"` // schedule, manual
+	Status         string    `gorm:"type:varchar(20);not null" json:"status"`  // sent, failed
+	PeriodStart    time.Time `gorm:"type:date;not null" json:"period_start"`
+	PeriodEnd      time.Time `gorm:"type:date;not null" json:"period_end"`
+	Recipients     string    `gorm:"type:text" json:"recipients"` // Comma-separated, as sent
+	FileName       string    `gorm:"type:varchar(255)" json:"file_name"`
+	FileSize       int       `gorm:"type:integer" json:"file_size"` // In bytes
+	Error          string    `gorm:"type:text" json:"error"`
+	TriggeredBy    *string   `gorm:"type:uuid" json:"triggered_by"` // User who sent it manually
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for ReportDelivery
+func (ReportDelivery) TableName() string {
+	return "report_deliveries"
+}
+
+// BeforeCreate hook to generate UUID
+func (d *ReportDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// PeriodRange returns the first and last day of a relative period as seen on the calendar day of now.
+// Both are dates at midnight UTC, the form report requests use.
+func PeriodRange(period string, now time.Time) (time.Time, time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+
+	switch period {
+	case PeriodLast7Days:
+		return today.AddDate(0, 0, -7), yesterday
+	case PeriodLastWeek:
+		monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		return monday.AddDate(0, 0, -7), monday.AddDate(0, 0, -1)
+	case PeriodLast30Days:
+		return today.AddDate(0, 0, -30), yesterday
+	case PeriodMonthToDate:
+		return time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC), today
+	case PeriodLastMonth:
+		firstOfMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return firstOfMonth.AddDate(0, -1, 0), firstOfMonth.AddDate(0, 0, -1)
+	case PeriodLastQuarter:
+		quarterStart := time.Date(today.Year(), time.Month((int(today.Month())-1)/3*3+1), 1, 0, 0, 0, 0, time.UTC)
+		return quarterStart.AddDate(0, -3, 0), quarterStart.AddDate(0, 0, -1)
+	default:
+		return yesterday, yesterday
+	}
+}
+
+// JoinList joins values into a comma-separated list, dropping blanks
+func JoinList(values []string) string {
+	kept := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			kept = append(kept, v)
+		}
+	}
+	return strings.Join(kept, ",")
+}
+
+func splitList(list string) []string {
+	values := []string{}
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// ReportSubscriptionResponse represents report subscription response DTO
+type ReportSubscriptionResponse struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	ReportType  string     `json:"report_type"`
+	Format      string     `json:"format"`
+	Period      string     `json:"period"`
+	AccountID   *string    `json:"account_id"`
+	SalesRepID  *string    `json:"sales_rep_id"`
+	VisitStatus string     `json:"visit_status"`
+	Recipients  []string   `json:"recipients"`
+	Schedule    string     `json:"schedule"`
+	IsActive    bool       `json:"is_active"`
+	NextRunAt   *time.Time `json:"next_run_at"`
+	LastRunAt   *time.Time `json:"last_run_at"`
+	LastStatus  string     `json:"last_status"`
+	CreatedBy   string     `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// ToReportSubscriptionResponse converts ReportSubscription to ReportSubscriptionResponse
+func (s *ReportSubscription) ToReportSubscriptionResponse() *ReportSubscriptionResponse {
+	return &ReportSubscriptionResponse{
+		ID:          s.ID,
+		Name:        s.Name,
+		ReportType:  s.ReportType,
+		Format:      s.Format,
+		Period:      s.Period,
+		AccountID:   s.AccountID,
+		SalesRepID:  s.SalesRepID,
+		VisitStatus: s.VisitStatus,
+		Recipients:  s.RecipientList(),
+		Schedule:    s.Schedule,
+		IsActive:    s.IsActive,
+		NextRunAt:   s.NextRunAt,
+		LastRunAt:   s.LastRunAt,
+		LastStatus:  s.LastStatus,
+		CreatedBy:   s.CreatedBy,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+// ReportDeliveryResponse represents report delivery response DTO
+type ReportDeliveryResponse struct {
+	ID             string    `json:"id"`
+	SubscriptionID string    `json:"subscription_id"`
+	Trigger        string    `json:"trigger"`
+	Status         string    `json:"status"`
+	PeriodStart    time.Time `json:"period_start"`
+	PeriodEnd      time.Time `json:"period_end"`
+	Recipients     []string  `json:"recipients"`
+	FileName       string    `json:"file_name"`
+	FileSize       int       `json:"file_size"`
+	Error          string    `json:"error,omitempty"`
+	TriggeredBy    *string   `json:"triggered_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ToReportDeliveryResponse converts ReportDelivery to ReportDeliveryResponse
+func (d *ReportDelivery) ToReportDeliveryResponse() *ReportDeliveryResponse {
+	return &ReportDeliveryResponse{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		Trigger:        d.Trigger,
+		Status:         d.Status,
+		PeriodStart:    d.PeriodStart,
+		PeriodEnd:      d.PeriodEnd,
+		Recipients:     splitList(d.Recipients),
+		FileName:       d.FileName,
+		FileSize:       d.FileSize,
+		Error:          d.Error,
+		TriggeredBy:    d.TriggeredBy,
+		CreatedAt:      d.CreatedAt,
+	}
+}
+
+// CreateReportSubscriptionRequest represents create report subscription request DTO.
+// Schedule is a five-field cron expression in WIB, e.g. "0 7 * * 1" for Mondays at 07:00.
+type CreateReportSubscriptionRequest struct {
+	Name        string   `json:"name" binding:"required,min=1,max=100"`
+	ReportType  string   `json:"report_type" binding:"required,oneof=visit_reports pipeline sales_performance account_activity win_loss"`
+	Format      string   `json:"format" binding:"required,oneof=csv excel pdf"`
+	Period      string   `json:"period" binding:"required,oneof=yesterday last_7_days last_week last_30_days month_to_date last_month last_quarter"`
+	AccountID   *string  `json:"account_id" binding:"required_if=ReportType account_activity,omitempty,uuid"`
+	SalesRepID  *string  `json:"sales_rep_id" binding:"omitempty,uuid"`
+	VisitStatus string   `json:"visit_status" binding:"omitempty,oneof=draft submitted approved rejected"`
+	Recipients  []string `json:"recipients" binding:"required,min=1,max=20,dive,email"`
+	Schedule    string   `json:"schedule" binding:"required,max=100"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// UpdateReportSubscriptionRequest represents update report subscription request DTO; the report type cannot change.
+// Send an empty sales_rep_id or visit_status to stop filtering on it.
+type UpdateReportSubscriptionRequest struct {
+	Name        string   `json:"name" binding:"omitempty,min=1,max=100"`
+	Format      string   `json:"format" binding:"omitempty,oneof=csv excel pdf"`
+	Period      string   `json:"period" binding:"omitempty,oneof=yesterday last_7_days last_week last_30_days month_to_date last_month last_quarter"`
+	AccountID   *string  `json:"account_id" binding:"omitempty,uuid"`
+	SalesRepID  *string  `json:"sales_rep_id" binding:"omitempty,uuid"`
+	VisitStatus *string  `json:"visit_status" binding:"omitempty,oneof=draft submitted approved rejected"`
+	Recipients  []string `json:"recipients" binding:"omitempty,min=1,max=20,dive,email"`
+	Schedule    string   `json:"schedule" binding:"omitempty,max=100"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// ListReportSubscriptionsRequest represents list report subscriptions query parameters
+type ListReportSubscriptionsRequest struct {
+	Page       int    `form:"page" binding:"omitempty,min=1"`
+	PerPage    int    `form:"per_page" binding:"omitempty,min=1,max=100"`
+	ReportType string `form:"report_type" binding:"omitempty,oneof=visit_reports pipeline sales_performance account_activity win_loss"`
+	IsActive   *bool  `form:"is_active"`
+}
+
+// ListReportDeliveriesRequest represents list report deliveries query parameters
+type ListReportDeliveriesRequest struct {
+	Page    int    `form:"page" binding:"omitempty,min=1"`
+	PerPage int    `form:"per_page" binding:"omitempty,min=1,max=100"`
+	Status  string `form:"status" binding:"omitempty,oneof=sent failed"`
+}
@@ -16,6 +16,7 @@ type Role struct {
 	Description string    `gorm:"type:text" json:"description"`
 	Status      string    `gorm:"type:varchar(20);not null;default:'active'" json:"status"`
 	MobileAccess bool     `gorm:"type:boolean;default:false" json:"mobile_access"`
+	DataScope   string    `gorm:"type:varchar(20);not null;default:'all'" json:"data_scope"` // own, team, all - which assigned records the role can access
 	Permissions []permission.Permission `gorm:"many2many:role_permissions;" json:"permissions,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
@@ -43,6 +44,7 @@ type RoleResponse struct {
 	Description string             `json:"description"`
 	Status      string             `json:"status"`
 	MobileAccess bool              `json:"mobile_access"`
+	DataScope   string             `json:"data_scope"`
 	Permissions []permission.PermissionResponse `json:"permissions,omitempty"`
 	CreatedAt   time.Time          `json:"created_at"`
 	UpdatedAt   time.Time          `json:"updated_at"`
@@ -57,6 +59,7 @@ func (r *Role) ToRoleResponse() *RoleResponse {
 		Description: r.Description,
 		Status:      r.Status,
 		MobileAccess: r.MobileAccess,
+		DataScope:   r.DataScope,
 		CreatedAt:   r.CreatedAt,
 		UpdatedAt:   r.UpdatedAt,
 	}
@@ -76,6 +79,7 @@ type CreateRoleRequest struct {
 	Description string `json:"description"`
 	Status      string `json:"status" binding:"omitempty,oneof=active inactive"`
 	MobileAccess *bool `json:"mobile_access"`
+	DataScope   string `json:"data_scope" binding:"omitempty,oneof=own team all"`
 }
 
 // UpdateRoleRequest represents update role request DTO
@@ -85,6 +89,7 @@ type UpdateRoleRequest struct {
 	Description string `json:"description"`
 	Status      string `json:"status" binding:"omitempty,oneof=active inactive"`
 	MobileAccess *bool `json:"mobile_access"`
+	DataScope   string `json:"data_scope" binding:"omitempty,oneof=own team all"`
 }
 
 // AssignPermissionsRequest represents assign permissions to role request DTO
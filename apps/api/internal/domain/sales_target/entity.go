@@ -0,0 +1,255 @@
+package sales_target
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Target levels
+const (
+	LevelUser    = "user"
+	LevelTeam    = "team"
+	LevelCompany = "company"
+)
+
+// Target period types
+const (
+	PeriodMonth   = "month"
+	PeriodQuarter = "quarter"
+	PeriodYear    = "year"
+)
+
+// SalesTarget represents a revenue and visit target of a user, team or the whole company for a period
+type SalesTarget struct {
+	ID            string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Level         string         `gorm:"type:varchar(20);not null;index" json:"level"`         // user, team, company
+	UserID        *string        `gorm:"type:uuid;index" json:"user_id"`                       // Set for user targets
+	TeamID        *string        `gorm:"type:uuid;index" json:"team_id"`                       // Set for team targets
+	PeriodType    string         `gorm:"type:varchar(20);not null" json:"period_type"`         // month, quarter, year
+	PeriodStart   time.Time      `gorm:"type:date;not null;index" json:"period_start"`         // First day of the period
+	PeriodEnd     time.Time      `gorm:"type:date;not null;index" json:"period_end"`           // Last day of the period
+	RevenueTarget int64          `gorm:"type:bigint;not null;default:0" json:"revenue_target"` // Won deal value in smallest currency unit (sen)
+	VisitTarget   int            `gorm:"type:integer;not null;default:0" json:"visit_target"`  // Approved visit reports
+	Notes         string         `gorm:"type:text" json:"notes"`
+	CreatedBy     string         `gorm:"type:uuid" json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for SalesTarget
+func (SalesTarget) TableName() string {
+	return "sales_targets"
+}
+
+// BeforeCreate hook to generate UUID
+func (t *SalesTarget) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// PeriodBounds returns the first and last day of the period of the given type containing the number
+// (month 1-12 or quarter 1-4) of the year; number is ignored for yearly periods
+func PeriodBounds(periodType string, year int, number int) (time.Time, time.Time) {
+	switch periodType {
+	case PeriodMonth:
+		start := time.Date(year, time.Month(number), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1)
+	case PeriodQuarter:
+		start := time.Date(year, time.Month((number-1)*3+1), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 3, -1)
+	default:
+		start := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, -1)
+	}
+}
+
+// periodRank orders period types from finest to coarsest
+var periodRank = map[string]int{
+	PeriodMonth:   0,
+	PeriodQuarter: 1,
+	PeriodYear:    2,
+}
+
+// TargetFor returns the revenue and visit target of a user, team or the company (empty ownerID) for the days in [from, to].
+// Only targets of the finest period type overlapping the range are used, prorated by the days they overlap,
+// so a monthly target is not counted again as part of a yearly one. ok is false when no target overlaps.
+func TargetFor(targets []SalesTarget, level string, ownerID string, from time.Time, to time.Time) (revenue int64, visits int, ok bool) {
+	from, to = dateOf(from), dateOf(to)
+
+	var matching []SalesTarget
+	finest := len(periodRank)
+	for _, t := range targets {
+		if t.Level != level || dateOf(t.PeriodEnd).Before(from) || dateOf(t.PeriodStart).After(to) {
+			continue
+		}
+		if (level == LevelUser && (t.UserID == nil || *t.UserID != ownerID)) ||
+			(level == LevelTeam && (t.TeamID == nil || *t.TeamID != ownerID)) {
+			continue
+		}
+		matching = append(matching, t)
+		if rank := periodRank[t.PeriodType]; rank < finest {
+			finest = rank
+		}
+	}
+
+	var revenueTotal, visitTotal float64
+	for _, t := range matching {
+		if periodRank[t.PeriodType] != finest {
+			continue
+		}
+		start, end := dateOf(t.PeriodStart), dateOf(t.PeriodEnd)
+		overlapStart, overlapEnd := start, end
+		if from.After(overlapStart) {
+			overlapStart = from
+		}
+		if to.Before(overlapEnd) {
+			overlapEnd = to
+		}
+		share := float64(daysBetween(overlapStart, overlapEnd)) / float64(daysBetween(start, end))
+		revenueTotal += float64(t.RevenueTarget) * share
+		visitTotal += float64(t.VisitTarget) * share
+		ok = true
+	}
+
+	return int64(math.Round(revenueTotal)), int(math.Round(visitTotal)), ok
+}
+
+// AchievementFor sums the revenue and visits of the given users; nil userIDs sums all users
+func AchievementFor(achievements []UserAchievement, userIDs []string) (revenue int64, visits int) {
+	var include map[string]bool
+	if userIDs != nil {
+		include = make(map[string]bool, len(userIDs))
+		for _, id := range userIDs {
+			include[id] = true
+		}
+	}
+	for _, a := range achievements {
+		if include != nil && !include[a.UserID] {
+			continue
+		}
+		revenue += a.Revenue
+		visits += a.Visits
+	}
+	return revenue, visits
+}
+
+// dateOf truncates a time to its calendar day
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween returns the number of days in [from, to], counting both ends
+func daysBetween(from time.Time, to time.Time) int {
+	return int(to.Sub(from).Hours()/24) + 1
+}
+
+// Attainment represents achievement against a target
+type Attainment struct {
+	RevenueTarget          int64   `json:"revenue_target"`
+	RevenueAchieved        int64   `json:"revenue_achieved"`
+	RevenueProgressPercent float64 `json:"revenue_progress_percent"`
+	VisitTarget            int     `json:"visit_target"`
+	VisitsAchieved         int     `json:"visits_achieved"`
+	VisitProgressPercent   float64 `json:"visit_progress_percent"`
+}
+
+// NewAttainment computes progress percentages of achieved revenue and visits against the targets
+func NewAttainment(revenueTarget int64, revenueAchieved int64, visitTarget int, visitsAchieved int) Attainment {
+	a := Attainment{
+		RevenueTarget:   revenueTarget,
+		RevenueAchieved: revenueAchieved,
+		VisitTarget:     visitTarget,
+		VisitsAchieved:  visitsAchieved,
+	}
+	if revenueTarget > 0 {
+		a.RevenueProgressPercent = float64(revenueAchieved) * 100.0 / float64(revenueTarget)
+	}
+	if visitTarget > 0 {
+		a.VisitProgressPercent = float64(visitsAchieved) * 100.0 / float64(visitTarget)
+	}
+	return a
+}
+
+// SalesTargetResponse represents sales target response DTO
+type SalesTargetResponse struct {
+	ID            string      `json:"id"`
+	Level         string      `json:"level"`
+	UserID        *string     `json:"user_id"`
+	UserName      string      `json:"user_name,omitempty"`
+	TeamID        *string     `json:"team_id"`
+	TeamName      string      `json:"team_name,omitempty"`
+	PeriodType    string      `json:"period_type"`
+	PeriodStart   time.Time   `json:"period_start"`
+	PeriodEnd     time.Time   `json:"period_end"`
+	RevenueTarget int64       `json:"revenue_target"`
+	VisitTarget   int         `json:"visit_target"`
+	Notes         string      `json:"notes"`
+	Attainment    *Attainment `json:"attainment,omitempty"`
+	CreatedBy     string      `json:"created_by"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
+// ToSalesTargetResponse converts SalesTarget to SalesTargetResponse
+func (t *SalesTarget) ToSalesTargetResponse() *SalesTargetResponse {
+	return &SalesTargetResponse{
+		ID:            t.ID,
+		Level:         t.Level,
+		UserID:        t.UserID,
+		TeamID:        t.TeamID,
+		PeriodType:    t.PeriodType,
+		PeriodStart:   t.PeriodStart,
+		PeriodEnd:     t.PeriodEnd,
+		RevenueTarget: t.RevenueTarget,
+		VisitTarget:   t.VisitTarget,
+		Notes:         t.Notes,
+		CreatedBy:     t.CreatedBy,
+		CreatedAt:     t.CreatedAt,
+		UpdatedAt:     t.UpdatedAt,
+	}
+}
+
+// CreateSalesTargetRequest represents create sales target request DTO.
+// Period is the month (1-12) or quarter (1-4) of the year and is ignored for yearly targets.
+type CreateSalesTargetRequest struct {
+	Level         string  `json:"level" binding:"required,oneof=user team company"`
+	UserID        *string `json:"user_id" binding:"required_if=Level user,omitempty,uuid"`
+	TeamID        *string `json:"team_id" binding:"required_if=Level team,omitempty,uuid"`
+	PeriodType    string  `json:"period_type" binding:"required,oneof=month quarter year"`
+	Year          int     `json:"year" binding:"required,min=2000,max=2100"`
+	Period        int     `json:"period" binding:"omitempty,min=1,max=12"`
+	RevenueTarget int64   `json:"revenue_target" binding:"min=0"`
+	VisitTarget   int     `json:"visit_target" binding:"min=0"`
+	Notes         string  `json:"notes"`
+}
+
+// UpdateSalesTargetRequest represents update sales target request DTO; the owner and period cannot change
+type UpdateSalesTargetRequest struct {
+	RevenueTarget *int64  `json:"revenue_target" binding:"omitempty,min=0"`
+	VisitTarget   *int    `json:"visit_target" binding:"omitempty,min=0"`
+	Notes         *string `json:"notes"`
+}
+
+// ListSalesTargetsRequest represents list sales targets query parameters
+type ListSalesTargetsRequest struct {
+	Page       int    `form:"page" binding:"omitempty,min=1"`
+	PerPage    int    `form:"per_page" binding:"omitempty,min=1,max=100"`
+	Level      string `form:"level" binding:"omitempty,oneof=user team company"`
+	UserID     string `form:"user_id" binding:"omitempty,uuid"`
+	TeamID     string `form:"team_id" binding:"omitempty,uuid"`
+	PeriodType string `form:"period_type" binding:"omitempty,oneof=month quarter year"`
+	Year       int    `form:"year" binding:"omitempty,min=2000,max=2100"`
+}
+
+// UserAchievement represents won deal revenue and approved visits of a single user in a period
+type UserAchievement struct {
+	UserID  string
+	Revenue int64
+	Visits  int
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -29,6 +30,8 @@ type Task struct {
 	Contact      *ContactRef    `gorm:"foreignKey:ContactID" json:"contact,omitempty"`
 	DealID       *string        `gorm:"type:uuid;index" json:"deal_id"` // Optional: link to deal
 	Deal         *DealRef       `gorm:"foreignKey:DealID" json:"deal,omitempty"`
+	VisitReportID *string       `gorm:"type:uuid;index" json:"visit_report_id"`              // Optional: visit report the task came from
+	ActionItemID  *string       `gorm:"type:varchar(64);uniqueIndex" json:"action_item_id"` // Optional: AI action item the task was created from
 	CreatedBy   string         `gorm:"type:uuid;index" json:"created_by"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
@@ -115,6 +118,8 @@ type TaskResponse struct {
 	Contact     *ContactRefResponse `json:"contact,omitempty"`
 	DealID      string            `json:"deal_id"`
 	Deal        *DealRefResponse  `json:"deal,omitempty"`
+	VisitReportID string          `json:"visit_report_id"`
+	ActionItemID  string          `json:"action_item_id"`
 	CreatedBy   string            `json:"created_by"`
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
@@ -180,6 +185,12 @@ func (t *Task) ToTaskResponse() *TaskResponse {
 	if t.DealID != nil {
 		resp.DealID = *t.DealID
 	}
+	if t.VisitReportID != nil {
+		resp.VisitReportID = *t.VisitReportID
+	}
+	if t.ActionItemID != nil {
+		resp.ActionItemID = *t.ActionItemID
+	}
 
 	if t.AssignedUser != nil {
 		resp.AssignedUser = &UserRefResponse{
@@ -240,6 +251,20 @@ type CreateTaskRequest struct {
 	DealID      string     `json:"deal_id" binding:"omitempty,uuid"`
 }
 
+// CreateTasksFromActionItemsRequest represents request to turn AI action items of a visit report into tasks
+type CreateTasksFromActionItemsRequest struct {
+	Items []ActionItemTaskRequest `json:"items" binding:"required,min=1,max=20,dive"`
+}
+
+// ActionItemTaskRequest represents a selected action item; priority and due date default to the AI suggestion
+type ActionItemTaskRequest struct {
+	ID         string     `json:"id" binding:"required,len=32,hexadecimal"`
+	Text       string     `json:"text" binding:"required,min=3"`
+	Priority   string     `json:"priority" binding:"omitempty,oneof=low medium high urgent"`
+	DueDate    *time.Time `json:"due_date" binding:"omitempty"`
+	AssignedTo string     `json:"assigned_to" binding:"omitempty,uuid"`
+}
+
 // UpdateTaskRequest represents update task request DTO
 type UpdateTaskRequest struct {
 	Title       string     `json:"title" binding:"omitempty,min=3,max=255"`
@@ -314,5 +339,45 @@ func formatNumber(n float64) string {
 	return result
 }
 
+// ExportColumns are the columns of a task export
+var ExportColumns = []export.Column{
+	{Key: "id", Header: "ID"},
+	{Key: "title", Header: "Title"},
+	{Key: "type", Header: "Type"},
+	{Key: "status", Header: "Status"},
+	{Key: "priority", Header: "Priority"},
+	{Key: "due_date", Header: "Due Date"},
+	{Key: "completed_at", Header: "Completed At"},
+	{Key: "assigned_to", Header: "Assigned To"},
+	{Key: "assigned_from", Header: "Assigned By"},
+	{Key: "account", Header: "Account"},
+	{Key: "contact", Header: "Contact"},
+	{Key: "deal", Header: "Deal"},
+	{Key: "description", Header: "Description"},
+	{Key: "created_at", Header: "Created At"},
+	{Key: "updated_at", Header: "Updated At"},
+}
 
-
+// ExportRow returns the task's values in the order of ExportColumns
+func (t *Task) ExportRow() []interface{} {
+	var assignedTo, assignedFrom, account, contact, deal string
+	if t.AssignedUser != nil {
+		assignedTo = t.AssignedUser.Name
+	}
+	if t.AssignedFromUser != nil {
+		assignedFrom = t.AssignedFromUser.Name
+	}
+	if t.Account != nil {
+		account = t.Account.Name
+	}
+	if t.Contact != nil {
+		contact = t.Contact.Name
+	}
+	if t.Deal != nil {
+		deal = t.Deal.Title
+	}
+	return []interface{}{
+		t.ID, t.Title, t.Type, t.Status, t.Priority, t.DueDate, t.CompletedAt, assignedTo, assignedFrom,
+		account, contact, deal, t.Description, t.CreatedAt, t.UpdatedAt,
+	}
+}
@@ -0,0 +1,74 @@
+package team
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Team represents a sales team used for team-level data scoping
+type Team struct {
+	ID          string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name        string         `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+	Description string         `gorm:"type:text" json:"description"`
+	ManagerID   *string        `gorm:"type:uuid;index" json:"manager_id"` // User leading the team (optional)
+	Status      string         `gorm:"type:varchar(20);not null;default:'active'" json:"status"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for Team
+func (Team) TableName() string {
+	return "teams"
+}
+
+// BeforeCreate hook to generate UUID
+func (t *Team) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TeamResponse represents team response DTO
+type TeamResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	ManagerID   *string   `json:"manager_id"`
+	Status      string    `json:"status"`
+	MemberCount int64     `json:"member_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ToTeamResponse converts Team to TeamResponse
+func (t *Team) ToTeamResponse() *TeamResponse {
+	return &TeamResponse{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		ManagerID:   t.ManagerID,
+		Status:      t.Status,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+// CreateTeamRequest represents create team request DTO
+type CreateTeamRequest struct {
+	Name        string  `json:"name" binding:"required,min=2"`
+	Description string  `json:"description"`
+	ManagerID   *string `json:"manager_id" binding:"omitempty,uuid"`
+	Status      string  `json:"status" binding:"omitempty,oneof=active inactive"`
+}
+
+// UpdateTeamRequest represents update team request DTO
+type UpdateTeamRequest struct {
+	Name        string  `json:"name" binding:"omitempty,min=2"`
+	Description string  `json:"description"`
+	ManagerID   *string `json:"manager_id" binding:"omitempty,uuid"`
+	Status      string  `json:"status" binding:"omitempty,oneof=active inactive"`
+}
@@ -18,6 +18,7 @@ type User struct {
 	RoleID    string    `gorm:"type:uuid;not null;index" json:"role_id"`
 	Role      *role.Role     `gorm:"foreignKey:RoleID" json:"role,omitempty"`
 	Status    string    `gorm:"type:varchar(20);not null;default:'active'" json:"status"`
+	TeamID    *string   `gorm:"type:uuid;index" json:"team_id"` // Sales team for team-level data scope (optional)
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
@@ -45,6 +46,7 @@ type UserResponse struct {
 	RoleID    string         `json:"role_id"`
 	Role      *role.RoleResponse  `json:"role,omitempty"`
 	Status    string         `json:"status"`
+	TeamID    *string        `json:"team_id"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 }
@@ -58,6 +60,7 @@ func (u *User) ToUserResponse() *UserResponse {
 		AvatarURL: u.AvatarURL,
 		RoleID:    u.RoleID,
 		Status:    u.Status,
+		TeamID:    u.TeamID,
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
 	}
@@ -74,6 +77,7 @@ type CreateUserRequest struct {
 	Name     string `json:"name" binding:"required,min=3"`
 	RoleID   string `json:"role_id" binding:"required,uuid"`
 	Status   string `json:"status" binding:"omitempty,oneof=active inactive"`
+	TeamID   *string `json:"team_id" binding:"omitempty,uuid"`
 }
 
 // UpdateUserRequest represents update user request DTO
@@ -82,6 +86,7 @@ type UpdateUserRequest struct {
 	Name   string `json:"name" binding:"omitempty,min=3"`
 	RoleID string `json:"role_id" binding:"omitempty,uuid"`
 	Status string `json:"status" binding:"omitempty,oneof=active inactive"`
+	TeamID *string `json:"team_id" binding:"omitempty"` // Empty string removes the user from their team
 }
 
 // ListUsersRequest represents list users query parameters
@@ -91,5 +96,6 @@ type ListUsersRequest struct {
 	Search  string `form:"search" binding:"omitempty"`
 	Status  string `form:"status" binding:"omitempty,oneof=active inactive"`
 	RoleID  string `form:"role_id" binding:"omitempty,uuid"`
+	TeamID  string `form:"team_id" binding:"omitempty,uuid"`
 }
 
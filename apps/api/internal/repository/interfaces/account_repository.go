@@ -1,17 +1,27 @@
 package interfaces
 
 import (
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
 )
 
 // AccountRepository defines the interface for account repository
 type AccountRepository interface {
+	// WithScope returns a repository whose list, get, update and delete queries are limited to the data scope
+	WithScope(scope *datascope.Scope) AccountRepository
+
 	// FindByID finds an account by ID
 	FindByID(id string) (*account.Account, error)
+
+	// FindByName finds the accounts with the given name, ignoring case and surrounding spaces
+	FindByName(name string) ([]account.Account, error)
 	
 	// List returns a list of accounts with pagination
 	List(req *account.ListAccountsRequest) ([]account.Account, int64, error)
 	
+	// FindInBatches passes every account matching the list filters to fn, a batch at a time in ID order
+	FindInBatches(req *account.ListAccountsRequest, fn func([]account.Account) error) error
+	
 	// Create creates a new account
 	Create(account *account.Account) error
 	
@@ -12,6 +12,9 @@ type ActivityRepository interface {
 	// List returns a list of activities with pagination
 	List(req *activity.ListActivitiesRequest) ([]activity.Activity, int64, error)
 	
+	// FindInBatches passes every activity matching the list filters to fn, a batch at a time in ID order
+	FindInBatches(req *activity.ListActivitiesRequest, fn func([]activity.Activity) error) error
+	
 	// Create creates a new activity
 	Create(a *activity.Activity) error
 	
@@ -0,0 +1,32 @@
+package interfaces
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_conversation"
+)
+
+// AIConversationRepository defines the interface for AI conversation repository
+type AIConversationRepository interface {
+	// FindByID finds a conversation by ID without its messages
+	FindByID(id string) (*ai_conversation.Conversation, error)
+
+	// FindByIDWithMessages finds a conversation by ID with all messages in chronological order
+	FindByIDWithMessages(id string) (*ai_conversation.Conversation, error)
+
+	// ListByUserID returns a user's conversations, most recently active first
+	ListByUserID(userID string, req *ai_conversation.ListConversationsRequest) ([]ai_conversation.Conversation, int64, error)
+
+	// Create creates a new conversation
+	Create(conversation *ai_conversation.Conversation) error
+
+	// Update updates a conversation
+	Update(conversation *ai_conversation.Conversation) error
+
+	// Delete soft deletes a conversation and removes its messages
+	Delete(id string) error
+
+	// AddMessage stores a message and updates the conversation activity time, model and token total
+	AddMessage(message *ai_conversation.Message) error
+
+	// ListRecentMessages returns up to limit of the latest messages of a conversation in chronological order
+	ListRecentMessages(conversationID string, limit int) ([]ai_conversation.Message, error)
+}
@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_model_usage"
+)
+
+// AIModelUsageRepository defines the interface for AI token usage repository
+type AIModelUsageRepository interface {
+	// Record adds tokens and one request to the daily usage of a user, model and feature
+	Record(userID string, model string, feature string, tokens int, usedAt time.Time) error
+
+	// SumTokensByUser returns the tokens used by a user on days in [from, to]
+	SumTokensByUser(userID string, from time.Time, to time.Time) (int64, error)
+
+	// SummarizeByUser returns usage per user on days in [from, to], optionally for a single user
+	SummarizeByUser(from time.Time, to time.Time, userID string) ([]ai_model_usage.UserUsage, error)
+
+	// SummarizeByModel returns usage per model on days in [from, to], optionally for a single user
+	SummarizeByModel(from time.Time, to time.Time, userID string) ([]ai_model_usage.ModelUsageSummary, error)
+
+	// SummarizeByFeature returns usage per feature on days in [from, to], optionally for a single user
+	SummarizeByFeature(from time.Time, to time.Time, userID string) ([]ai_model_usage.FeatureUsage, error)
+}
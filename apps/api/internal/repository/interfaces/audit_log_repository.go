@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/audit_log"
+)
+
+// AuditLogRepository defines the interface for audit log repository
+type AuditLogRepository interface {
+	// Create stores a new audit log entry
+	Create(entry *audit_log.AuditLog) error
+
+	// List returns a list of audit logs with pagination
+	List(req *audit_log.ListAuditLogsRequest) ([]audit_log.AuditLog, int64, error)
+}
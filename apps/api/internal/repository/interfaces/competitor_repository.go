@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/competitor"
+)
+
+// CompetitorRepository defines the interface for competitor repository
+type CompetitorRepository interface {
+	// FindByID finds a competitor by ID
+	FindByID(id string) (*competitor.Competitor, error)
+
+	// FindByName finds a competitor by name, ignoring case
+	FindByName(name string) (*competitor.Competitor, error)
+
+	// List returns a list of competitors ordered by name
+	List(req *competitor.ListCompetitorsRequest) ([]competitor.Competitor, error)
+
+	// Create creates a new competitor
+	Create(c *competitor.Competitor) error
+
+	// Update updates a competitor
+	Update(c *competitor.Competitor) error
+
+	// Delete soft deletes a competitor; lost deals keep referring to it
+	Delete(id string) error
+}
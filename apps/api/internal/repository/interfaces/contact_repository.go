@@ -12,6 +12,9 @@ type ContactRepository interface {
 	// List returns a list of contacts with pagination
 	List(req *contact.ListContactsRequest) ([]contact.Contact, int64, error)
 	
+	// FindInBatches passes every contact matching the list filters to fn, a batch at a time in ID order
+	FindInBatches(req *contact.ListContactsRequest, fn func([]contact.Contact) error) error
+	
 	// Create creates a new contact
 	Create(contact *contact.Contact) error
 	
@@ -0,0 +1,29 @@
+package interfaces
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/contact"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/duplicate"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
+)
+
+// DuplicateRepository defines the interface for duplicate detection and merge repository
+type DuplicateRepository interface {
+	// FindLeadCandidates returns leads sharing the email, phone or a name token of the query, ignoring the data scope
+	FindLeadCandidates(q duplicate.Query) ([]duplicate.Record, error)
+
+	// FindAccountCandidates returns accounts sharing the email, phone or a name token of the query, ignoring the data scope
+	FindAccountCandidates(q duplicate.Query) ([]duplicate.Record, error)
+
+	// FindContactCandidates returns contacts sharing the email or phone of the query, or a name token within its account
+	FindContactCandidates(q duplicate.Query) ([]duplicate.Record, error)
+
+	// MergeLeads saves the survivor, re-points deals, activities and visit reports from the loser and deletes the loser in one transaction
+	MergeLeads(survivor *lead.Lead, loserID string) error
+
+	// MergeAccounts saves the survivor, re-points deals, contacts, leads, activities, tasks and visit reports from the loser and deletes the loser in one transaction
+	MergeAccounts(survivor *account.Account, loserID string) error
+
+	// MergeContacts saves the survivor, re-points deals, leads, activities, tasks and visit reports from the loser and deletes the loser in one transaction
+	MergeContacts(survivor *contact.Contact, loserID string) error
+}
@@ -0,0 +1,29 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/email_outbox"
+)
+
+// EmailOutboxRepository defines the interface for email outbox repository
+type EmailOutboxRepository interface {
+	// FindByID finds a queued email by ID
+	FindByID(id string) (*email_outbox.EmailMessage, error)
+
+	// List returns a list of queued emails with pagination, newest first
+	List(req *email_outbox.ListEmailMessagesRequest) ([]email_outbox.EmailMessage, int64, error)
+
+	// Create queues a new email
+	Create(m *email_outbox.EmailMessage) error
+
+	// Update updates a queued email
+	Update(m *email_outbox.EmailMessage) error
+
+	// FindDue returns up to limit pending emails whose next attempt is at or before now, oldest first
+	FindDue(now time.Time, limit int) ([]email_outbox.EmailMessage, error)
+
+	// ClaimAttempt counts a new attempt of a pending email that has had the given number of attempts and
+	// holds it until leaseUntil. It reports false when another worker claimed the attempt first.
+	ClaimAttempt(id string, attempts int, leaseUntil time.Time) (bool, error)
+}
@@ -0,0 +1,29 @@
+package interfaces
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/import_job"
+)
+
+// ImportJobRepository defines the interface for import job repository
+type ImportJobRepository interface {
+	// FindByID finds an import job by ID, without its parsed rows
+	FindByID(id string) (*import_job.ImportJob, error)
+
+	// FindRows returns the parsed rows of an import job
+	FindRows(id string) ([]import_job.Row, error)
+
+	// List returns a list of import jobs with pagination, without their parsed rows
+	List(req *import_job.ListImportJobsRequest) ([]import_job.ImportJob, int64, error)
+
+	// Create creates a new import job together with its parsed rows
+	Create(job *import_job.ImportJob) error
+
+	// Update updates an import job; the parsed rows are never rewritten
+	Update(job *import_job.ImportJob) error
+
+	// Start marks a validated import job as importing; false when the job is not validated (anymore)
+	Start(id string) (bool, error)
+
+	// MarkImportingAsFailed fails jobs left importing, e.g. by a server restart, and returns how many were failed
+	MarkImportingAsFailed(message string) (int64, error)
+}
@@ -0,0 +1,30 @@
+package interfaces
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_assignment"
+)
+
+// LeadAssignmentRuleRepository defines the interface for lead assignment rule repository
+type LeadAssignmentRuleRepository interface {
+	// FindByID finds an assignment rule by ID
+	FindByID(id string) (*lead_assignment.AssignmentRule, error)
+
+	// List returns all assignment rules by priority
+	List() ([]lead_assignment.AssignmentRule, error)
+
+	// ListActive returns the active assignment rules by priority
+	ListActive() ([]lead_assignment.AssignmentRule, error)
+
+	// Create creates a new assignment rule
+	Create(rule *lead_assignment.AssignmentRule) error
+
+	// Update updates an assignment rule
+	Update(rule *lead_assignment.AssignmentRule) error
+
+	// Delete soft deletes an assignment rule
+	Delete(id string) error
+
+	// AdvanceRoundRobin picks the member after the rule's last assigned user and stores it as the new position.
+	// The rule row is locked, so concurrent leads are not given to the same member.
+	AdvanceRoundRobin(ruleID string, memberIDs []string) (string, error)
+}
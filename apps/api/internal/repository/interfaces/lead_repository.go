@@ -1,17 +1,28 @@
 package interfaces
 
 import (
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_scoring"
+	"gorm.io/datatypes"
 )
 
 // LeadRepository defines the interface for lead repository
 type LeadRepository interface {
+	// WithScope returns a repository whose list, get, update and delete queries are limited to the data scope
+	WithScope(scope *datascope.Scope) LeadRepository
+
 	// FindByID finds a lead by ID
 	FindByID(id string) (*lead.Lead, error)
 
 	// List returns a list of leads with pagination
 	List(req *lead.ListLeadsRequest) ([]lead.Lead, int64, error)
 
+	// FindInBatches passes every lead matching the list filters to fn, a batch at a time in ID order
+	FindInBatches(req *lead.ListLeadsRequest, fn func([]lead.Lead) error) error
+
 	// Create creates a new lead
 	Create(lead *lead.Lead) error
 
@@ -23,5 +34,20 @@ type LeadRepository interface {
 
 	// GetAnalytics returns lead analytics
 	GetAnalytics(req *lead.LeadAnalyticsRequest) (*lead.LeadAnalyticsResponse, error)
+
+	// ListIDs returns the IDs of all leads, ignoring the data scope
+	ListIDs() ([]string, error)
+
+	// GetEngagement returns the counts of activities and visit reports linked to a lead and its last touch
+	GetEngagement(id string) (*lead_scoring.Engagement, error)
+
+	// CountOpenByAssignee returns the number of open leads assigned to each of the users
+	CountOpenByAssignee(userIDs []string) (map[string]int64, error)
+
+	// Assign sets the sales rep of a lead, ignoring the data scope
+	Assign(id string, userID string) error
+
+	// UpdateScore stores an automatically computed score and its breakdown without changing updated_at
+	UpdateScore(id string, score int, breakdown datatypes.JSON, scoredAt time.Time) error
 }
 
@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_scoring"
+)
+
+// LeadScoringRuleRepository defines the interface for lead scoring rule repository
+type LeadScoringRuleRepository interface {
+	// FindByID finds a scoring rule by ID
+	FindByID(id string) (*lead_scoring.ScoringRule, error)
+
+	// List returns all scoring rules in creation order
+	List() ([]lead_scoring.ScoringRule, error)
+
+	// ListActive returns the active scoring rules in creation order
+	ListActive() ([]lead_scoring.ScoringRule, error)
+
+	// Create creates a new scoring rule
+	Create(rule *lead_scoring.ScoringRule) error
+
+	// Update updates a scoring rule
+	Update(rule *lead_scoring.ScoringRule) error
+
+	// Delete soft deletes a scoring rule
+	Delete(id string) error
+}
@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/loss_reason"
+)
+
+// LossReasonRepository defines the interface for loss reason repository
+type LossReasonRepository interface {
+	// FindByID finds a loss reason by ID
+	FindByID(id string) (*loss_reason.LossReason, error)
+
+	// FindByCode finds a loss reason by code
+	FindByCode(code string) (*loss_reason.LossReason, error)
+
+	// List returns a list of loss reasons ordered by name
+	List(req *loss_reason.ListLossReasonsRequest) ([]loss_reason.LossReason, error)
+
+	// Create creates a new loss reason
+	Create(lr *loss_reason.LossReason) error
+
+	// Update updates a loss reason
+	Update(lr *loss_reason.LossReason) error
+
+	// Delete soft deletes a loss reason; lost deals keep referring to it
+	Delete(id string) error
+}
@@ -0,0 +1,39 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/messaging"
+)
+
+// MessagingRepository defines the interface for messaging preference and outbound message repository
+type MessagingRepository interface {
+	// FindPreference finds the messaging preference of a user
+	FindPreference(userID string) (*messaging.Preference, error)
+
+	// SavePreference creates or updates the messaging preference of a user
+	SavePreference(p *messaging.Preference) error
+
+	// FindMessageByID finds an outbound message by ID
+	FindMessageByID(id string) (*messaging.OutboundMessage, error)
+
+	// FindMessageByProviderID finds an outbound message by the ID its gateway returned
+	FindMessageByProviderID(channel string, providerMessageID string) (*messaging.OutboundMessage, error)
+
+	// ListMessages returns a list of outbound messages with pagination, newest first
+	ListMessages(req *messaging.ListOutboundMessagesRequest) ([]messaging.OutboundMessage, int64, error)
+
+	// CreateMessage queues a new outbound message
+	CreateMessage(m *messaging.OutboundMessage) error
+
+	// UpdateMessage updates an outbound message
+	UpdateMessage(m *messaging.OutboundMessage) error
+
+	// FindDueMessages returns up to limit pending messages of the given channels whose next attempt is at or
+	// before now, oldest first
+	FindDueMessages(channels []string, now time.Time, limit int) ([]messaging.OutboundMessage, error)
+
+	// ClaimAttempt counts a new attempt of a pending message that has had the given number of attempts and
+	// holds it until leaseUntil. It reports false when another worker claimed the attempt first.
+	ClaimAttempt(id string, attempts int, leaseUntil time.Time) (bool, error)
+}
@@ -26,6 +26,9 @@ type PermissionRepository interface {
 	
 	// GetMobilePermissions returns mobile-specific permissions for a user
 	GetMobilePermissions(userID string) (*permission.MobilePermissionsResponse, error)
+	
+	// GetCodesByRoleCode returns permission codes granted to a role (all codes for admin)
+	GetCodesByRoleCode(roleCode string) ([]string, error)
 }
 
 // MenuRepository defines the interface for menu repository
@@ -3,16 +3,41 @@ package interfaces
 import (
 	"time"
 
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
 )
 
 // PipelineRepository defines the interface for pipeline repository
 type PipelineRepository interface {
+	// FindPipelineByID finds a pipeline by ID
+	FindPipelineByID(id string) (*pipeline.Pipeline, error)
+
+	// FindPipelineByCode finds a pipeline by code
+	FindPipelineByCode(code string) (*pipeline.Pipeline, error)
+
+	// FindDefaultPipeline finds the pipeline used when a request names none
+	FindDefaultPipeline() (*pipeline.Pipeline, error)
+
+	// ListPipelines returns a list of pipelines ordered by order and name
+	ListPipelines(req *pipeline.ListPipelinesRequest) ([]pipeline.Pipeline, error)
+
+	// CreatePipeline creates a new pipeline
+	CreatePipeline(p *pipeline.Pipeline) error
+
+	// UpdatePipeline updates a pipeline
+	UpdatePipeline(p *pipeline.Pipeline) error
+
+	// SetDefaultPipeline makes the pipeline the default one and clears the flag on every other pipeline
+	SetDefaultPipeline(id string) error
+
+	// DeletePipeline soft deletes a pipeline together with its stages
+	DeletePipeline(id string) error
+
 	// FindStageByID finds a pipeline stage by ID
 	FindStageByID(id string) (*pipeline.PipelineStage, error)
 	
-	// FindStageByCode finds a pipeline stage by code
-	FindStageByCode(code string) (*pipeline.PipelineStage, error)
+	// FindStageByCode finds a pipeline stage by code within a pipeline; an empty pipelineID searches the default pipeline
+	FindStageByCode(pipelineID string, code string) (*pipeline.PipelineStage, error)
 	
 	// ListStages returns a list of pipeline stages
 	ListStages(req *pipeline.ListPipelineStagesRequest) ([]pipeline.PipelineStage, error)
@@ -29,25 +54,76 @@ type PipelineRepository interface {
 
 // DealRepository defines the interface for deal repository
 type DealRepository interface {
+	// WithScope returns a repository whose list, get, update and delete queries are limited to the data scope
+	WithScope(scope *datascope.Scope) DealRepository
+
 	// FindByID finds a deal by ID
 	FindByID(id string) (*pipeline.Deal, error)
 	
 	// List returns a list of deals with pagination
 	List(req *pipeline.ListDealsRequest) ([]pipeline.Deal, int64, error)
 	
-	// Create creates a new deal
-	Create(deal *pipeline.Deal) error
+	// FindInBatches passes every deal matching the list filters to fn, a batch at a time in ID order
+	FindInBatches(req *pipeline.ListDealsRequest, fn func([]pipeline.Deal) error) error
+
+	// ListClosed returns the won and lost deals closed in [start, end], optionally of one pipeline and of one
+	// sales rep, with their loss details
+	ListClosed(start, end time.Time, pipelineID, assignedTo string) ([]pipeline.Deal, error)
+	
+	// Create creates a new deal and, in the same transaction, the stage history entry of its first stage
+	Create(deal *pipeline.Deal, stageChange *pipeline.DealStageHistory) error
 	
-	// Update updates a deal
-	Update(deal *pipeline.Deal) error
+	// Update updates a deal; a non-nil stageChange is recorded in the stage history in the same transaction
+	Update(deal *pipeline.Deal, stageChange *pipeline.DealStageHistory) error
+
+	// UpdateValue sets the value of a deal, including to zero
+	UpdateValue(id string, value int64) error
 	
 	// Delete soft deletes a deal
 	Delete(id string) error
 	
-	// GetSummary returns pipeline summary statistics
-	GetSummary() (*pipeline.PipelineSummaryResponse, error)
+	// GetSummary returns pipeline summary statistics of one pipeline, or of all pipelines when pipelineID is empty
+	GetSummary(pipelineID string) (*pipeline.PipelineSummaryResponse, error)
 	
-	// GetForecast returns forecast data
-	GetForecast(periodType string, start, end time.Time) (*pipeline.ForecastResponse, error)
+	// GetForecast returns forecast data of one pipeline, or of all pipelines when pipelineID is empty
+	GetForecast(periodType string, start, end time.Time, pipelineID string) (*pipeline.ForecastResponse, error)
 }
 
+
+// DealStageHistoryRepository defines the interface for deal stage history repository
+type DealStageHistoryRepository interface {
+	// WithScope returns a repository whose analytics queries are limited to the data scope
+	WithScope(scope *datascope.Scope) DealStageHistoryRepository
+
+	// ListByDeal returns the stage history of a deal, oldest first
+	ListByDeal(dealID string) ([]pipeline.DealStageHistory, error)
+
+	// ListForAnalytics returns the stage history of the deals of a pipeline created in [start, end), optionally
+	// of one sales rep, ordered by deal and then oldest first
+	ListForAnalytics(pipelineID string, start, end time.Time, assignedTo string) ([]pipeline.DealStageHistory, error)
+
+	// BackfillMissing records the current stage of every deal that has no stage history and returns how many
+	// entries it created
+	BackfillMissing() (int64, error)
+}
+
+// DealItemRepository defines the interface for deal line item repository
+type DealItemRepository interface {
+	// FindByID finds a deal item by ID with its product
+	FindByID(id string) (*pipeline.DealItem, error)
+
+	// ListByDeal returns the items of a deal with their products, oldest first
+	ListByDeal(dealID string) ([]pipeline.DealItem, error)
+
+	// Create creates a new deal item
+	Create(item *pipeline.DealItem) error
+
+	// Update updates a deal item
+	Update(item *pipeline.DealItem) error
+
+	// Delete soft deletes a deal item
+	Delete(id string) error
+
+	// ProductRevenue returns the items of the matching deals grouped by product, highest revenue first
+	ProductRevenue(req *pipeline.ProductRevenueRequest) ([]pipeline.ProductRevenue, error)
+}
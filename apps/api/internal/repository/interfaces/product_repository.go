@@ -9,6 +9,9 @@ type ProductRepository interface {
 	// FindByID finds a product by ID.
 	FindByID(id string) (*product.Product, error)
 
+	// FindBySKU finds a product by SKU, ignoring case.
+	FindBySKU(sku string) (*product.Product, error)
+
 	// List returns a list of products with pagination.
 	List(req *product.ListProductsRequest) ([]product.Product, int64, error)
 
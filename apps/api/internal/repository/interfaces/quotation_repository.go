@@ -0,0 +1,35 @@
+package interfaces
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/quotation"
+)
+
+// QuotationRepository defines the interface for quotation repository
+type QuotationRepository interface {
+	// WithScope returns a repository limited to the quotations of deals visible within the given data scope
+	WithScope(scope *datascope.Scope) QuotationRepository
+
+	// FindByID finds a quotation by ID with its revisions and their items
+	FindByID(id string) (*quotation.Quotation, error)
+
+	// List returns a list of quotations with their revisions, without items, and pagination
+	List(req *quotation.ListQuotationsRequest) ([]quotation.Quotation, int64, error)
+
+	// Create numbers a quotation as prefix plus the next sequence of that prefix, padded to four digits, and creates it
+	// together with its revisions and their items
+	Create(q *quotation.Quotation, prefix string) error
+
+	// AddRevision numbers a revision as the next revision of the quotation, creates it with its items and
+	// makes it the current revision of the quotation, saving the quotation status. It reports false, and adds
+	// nothing, when the quotation is no longer in one of the from statuses.
+	AddRevision(q *quotation.Quotation, rev *quotation.QuotationRevision, from ...string) (bool, error)
+
+	// SetRevisionFile records the PDF of a revision that has none yet; it reports false when the revision
+	// already has a PDF
+	SetRevisionFile(revisionID string, fileName string) (bool, error)
+
+	// UpdateStatus saves the status, its timestamps and the rejection reason of a quotation if it is still in
+	// one of the from statuses; it reports false when another request changed the status first
+	UpdateStatus(q *quotation.Quotation, from ...string) (bool, error)
+}
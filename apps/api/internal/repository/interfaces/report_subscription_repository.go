@@ -0,0 +1,38 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/report_subscription"
+)
+
+// ReportSubscriptionRepository defines the interface for report subscription repository
+type ReportSubscriptionRepository interface {
+	// FindByID finds a report subscription by ID
+	FindByID(id string) (*report_subscription.ReportSubscription, error)
+
+	// List returns a list of report subscriptions with pagination
+	List(req *report_subscription.ListReportSubscriptionsRequest) ([]report_subscription.ReportSubscription, int64, error)
+
+	// FindDue returns active subscriptions whose next run is at or before now
+	FindDue(now time.Time) ([]report_subscription.ReportSubscription, error)
+
+	// Create creates a new report subscription
+	Create(s *report_subscription.ReportSubscription) error
+
+	// Update updates a report subscription
+	Update(s *report_subscription.ReportSubscription) error
+
+	// Delete soft deletes a report subscription
+	Delete(id string) error
+
+	// ClaimRun moves the next run of a subscription from scheduledAt to nextRunAt. It reports false when
+	// another worker already claimed the run, so each scheduled run is delivered once.
+	ClaimRun(id string, scheduledAt time.Time, nextRunAt *time.Time) (bool, error)
+
+	// RecordDelivery saves a delivery and sets the last run and status of its subscription
+	RecordDelivery(d *report_subscription.ReportDelivery) error
+
+	// ListDeliveries returns the deliveries of a subscription, newest first, with pagination
+	ListDeliveries(subscriptionID string, req *report_subscription.ListReportDeliveriesRequest) ([]report_subscription.ReportDelivery, int64, error)
+}
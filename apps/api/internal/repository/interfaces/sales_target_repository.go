@@ -0,0 +1,34 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/sales_target"
+)
+
+// SalesTargetRepository defines the interface for sales target repository
+type SalesTargetRepository interface {
+	// FindByID finds a sales target by ID
+	FindByID(id string) (*sales_target.SalesTarget, error)
+
+	// FindByOwnerAndPeriod finds the target of a user, team or the company for a period
+	FindByOwnerAndPeriod(level string, ownerID string, periodType string, periodStart time.Time) (*sales_target.SalesTarget, error)
+
+	// List returns a list of sales targets with pagination
+	List(req *sales_target.ListSalesTargetsRequest) ([]sales_target.SalesTarget, int64, error)
+
+	// ListOverlapping returns all targets whose period overlaps the days in [from, to]
+	ListOverlapping(from time.Time, to time.Time) ([]sales_target.SalesTarget, error)
+
+	// Create creates a new sales target, returning gorm.ErrDuplicatedKey when the owner already has a target for the period
+	Create(t *sales_target.SalesTarget) error
+
+	// Update updates a sales target
+	Update(t *sales_target.SalesTarget) error
+
+	// Delete soft deletes a sales target
+	Delete(id string) error
+
+	// AchievementByUser returns won deal revenue and approved visits per user on days in [from, to]
+	AchievementByUser(from time.Time, to time.Time) ([]sales_target.UserAchievement, error)
+}
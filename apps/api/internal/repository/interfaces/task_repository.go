@@ -3,18 +3,25 @@ package interfaces
 import (
 	"time"
 
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/reminder"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/task"
 )
 
 // TaskRepository defines the interface for task repository
 type TaskRepository interface {
+	// WithScope returns a repository whose list, get, update and delete queries are limited to the data scope
+	WithScope(scope *datascope.Scope) TaskRepository
+
 	// FindByID finds a task by ID
 	FindByID(id string) (*task.Task, error)
 	
 	// List returns a list of tasks with pagination
 	List(req *task.ListTasksRequest) ([]task.Task, int64, error)
 	
+	// FindInBatches passes every task matching the list filters to fn, a batch at a time in ID order
+	FindInBatches(req *task.ListTasksRequest, fn func([]task.Task) error) error
+	
 	// Create creates a new task
 	Create(task *task.Task) error
 	
@@ -23,6 +30,12 @@ type TaskRepository interface {
 	
 	// Delete soft deletes a task
 	Delete(id string) error
+
+	// FindByActionItemIDs finds tasks created from the given AI action items, regardless of data scope
+	FindByActionItemIDs(ids []string) ([]task.Task, error)
+
+	// CreateWithReminders creates tasks and their reminders in one transaction; reminders[i] belongs to tasks[i]
+	CreateWithReminders(tasks []*task.Task, reminders []*reminder.Reminder) error
 }
 
 // ReminderRepository defines the interface for reminder repository
@@ -50,5 +63,8 @@ type ReminderRepository interface {
 	
 	// MarkAsSent marks a reminder as sent
 	MarkAsSent(id string, sentAt time.Time) error
+	
+	// UpdateDeliveryStatus sets the delivery status of a reminder and the error of a failed delivery
+	UpdateDeliveryStatus(id string, status string, deliveryError string) error
 }
 
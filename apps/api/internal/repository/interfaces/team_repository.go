@@ -0,0 +1,29 @@
+package interfaces
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/team"
+)
+
+// TeamRepository defines the interface for team repository
+type TeamRepository interface {
+	// FindByID finds a team by ID
+	FindByID(id string) (*team.Team, error)
+
+	// FindByName finds a team by name
+	FindByName(name string) (*team.Team, error)
+
+	// List returns a list of teams
+	List() ([]team.Team, error)
+
+	// CountMembers returns the number of users in a team
+	CountMembers(teamID string) (int64, error)
+
+	// Create creates a new team
+	Create(t *team.Team) error
+
+	// Update updates a team
+	Update(t *team.Team) error
+
+	// Delete soft deletes a team
+	Delete(id string) error
+}
@@ -15,6 +15,9 @@ type UserRepository interface {
 	// List returns a list of users with pagination
 	List(req *user.ListUsersRequest) ([]user.User, int64, error)
 	
+	// ListIDsByTeam returns the IDs of users belonging to a team
+	ListIDsByTeam(teamID string) ([]string, error)
+	
 	// Create creates a new user
 	Create(user *user.User) error
 	
@@ -1,11 +1,15 @@
 package interfaces
 
 import (
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/visit_report"
 )
 
 // VisitReportRepository defines the interface for visit report repository
 type VisitReportRepository interface {
+	// WithScope returns a repository whose list, get, update and delete queries are limited to the data scope
+	WithScope(scope *datascope.Scope) VisitReportRepository
+
 	// FindByID finds a visit report by ID
 	FindByID(id string) (*visit_report.VisitReport, error)
 	
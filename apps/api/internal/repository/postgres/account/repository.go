@@ -3,13 +3,16 @@ package account
 import (
 	"strings"
 
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"gorm.io/gorm"
 )
 
 type repository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	scope *datascope.Scope
 }
 
 // NewRepository creates a new account repository
@@ -17,41 +20,34 @@ func NewRepository(db *gorm.DB) interfaces.AccountRepository {
 	return &repository{db: db}
 }
 
+// WithScope returns a repository restricted to the accounts visible within the given data scope
+func (r *repository) WithScope(scope *datascope.Scope) interfaces.AccountRepository {
+	return &repository{db: r.db, scope: scope}
+}
+
 func (r *repository) FindByID(id string) (*account.Account, error) {
 	var a account.Account
-	err := r.db.Preload("Category").Where("id = ?", id).First(&a).Error
+	err := r.db.Scopes(r.scope.Apply("accounts.assigned_to")).Preload("Category").Where("id = ?", id).First(&a).Error
 	if err != nil {
 		return nil, err
 	}
 	return &a, nil
 }
 
+func (r *repository) FindByName(name string) ([]account.Account, error) {
+	var accounts []account.Account
+	err := r.db.Scopes(r.scope.Apply("accounts.assigned_to")).
+		Where("LOWER(TRIM(name)) = ?", strings.ToLower(strings.TrimSpace(name))).
+		Order("created_at ASC").
+		Find(&accounts).Error
+	return accounts, err
+}
+
 func (r *repository) List(req *account.ListAccountsRequest) ([]account.Account, int64, error) {
 	var accounts []account.Account
 	var total int64
 
-	query := r.db.Model(&account.Account{})
-
-	// Apply filters
-	if req.Search != "" {
-		search := "%" + strings.ToLower(req.Search) + "%"
-		query = query.Where(
-			"LOWER(name) LIKE ? OR LOWER(city) LIKE ? OR LOWER(province) LIKE ? OR LOWER(email) LIKE ? OR LOWER(phone) LIKE ?",
-			search, search, search, search, search,
-		)
-	}
-
-	if req.Status != "" {
-		query = query.Where("status = ?", req.Status)
-	}
-
-	if req.CategoryID != "" {
-		query = query.Where("category_id = ?", req.CategoryID)
-	}
-
-	if req.AssignedTo != "" {
-		query = query.Where("assigned_to = ?", req.AssignedTo)
-	}
+	query := r.filter(req)
 
 	// Count total
 	if err := query.Count(&total).Error; err != nil {
@@ -82,15 +78,58 @@ func (r *repository) List(req *account.ListAccountsRequest) ([]account.Account,
 	return accounts, total, nil
 }
 
+// filter builds the query of accounts matching the list filters
+func (r *repository) filter(req *account.ListAccountsRequest) *gorm.DB {
+	query := r.db.Model(&account.Account{}).Scopes(r.scope.Apply("accounts.assigned_to"))
+
+	// Apply filters
+	if req.Search != "" {
+		search := "%" + strings.ToLower(req.Search) + "%"
+		query = query.Where(
+			"LOWER(name) LIKE ? OR LOWER(city) LIKE ? OR LOWER(province) LIKE ? OR LOWER(email) LIKE ? OR LOWER(phone) LIKE ?",
+			search, search, search, search, search,
+		)
+	}
+
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	if req.CategoryID != "" {
+		query = query.Where("category_id = ?", req.CategoryID)
+	}
+
+	if req.AssignedTo != "" {
+		query = query.Where("assigned_to = ?", req.AssignedTo)
+	}
+
+	return query
+}
+
+func (r *repository) FindInBatches(req *account.ListAccountsRequest, fn func([]account.Account) error) error {
+	var batch []account.Account
+	return r.filter(req).
+		Preload("Category").
+		FindInBatches(&batch, export.BatchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
+}
+
 func (r *repository) Create(a *account.Account) error {
 	return r.db.Create(a).Error
 }
 
 func (r *repository) Update(a *account.Account) error {
+	if err := r.scope.Authorize(r.db, &account.Account{}, "accounts.assigned_to", a.ID); err != nil {
+		return err
+	}
 	return r.db.Save(a).Error
 }
 
 func (r *repository) Delete(id string) error {
+	if err := r.scope.Authorize(r.db, &account.Account{}, "accounts.assigned_to", id); err != nil {
+		return err
+	}
 	return r.db.Where("id = ?", id).Delete(&account.Account{}).Error
 }
 
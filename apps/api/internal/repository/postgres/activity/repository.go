@@ -5,6 +5,7 @@ import (
 
 	"github.com/gilabs/crm-healthcare/api/internal/domain/activity"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"gorm.io/gorm"
 )
 
@@ -30,6 +31,39 @@ func (r *repository) List(req *activity.ListActivitiesRequest) ([]activity.Activ
 	var activities []activity.Activity
 	var total int64
 
+	query := r.filter(req)
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	offset := (page - 1) * perPage
+
+	// Fetch data
+	err := query.Order("timestamp DESC").Offset(offset).Limit(perPage).Find(&activities).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return activities, total, nil
+}
+
+// filter builds the query of activities matching the list filters
+func (r *repository) filter(req *activity.ListActivitiesRequest) *gorm.DB {
 	query := r.db.Model(&activity.Activity{})
 
 	// Apply filters
@@ -74,33 +108,14 @@ func (r *repository) List(req *activity.ListActivitiesRequest) ([]activity.Activ
 		}
 	}
 
-	// Count total
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	// Apply pagination
-	page := req.Page
-	if page < 1 {
-		page = 1
-	}
-	perPage := req.PerPage
-	if perPage < 1 {
-		perPage = 20
-	}
-	if perPage > 100 {
-		perPage = 100
-	}
-
-	offset := (page - 1) * perPage
-
-	// Fetch data
-	err := query.Order("timestamp DESC").Offset(offset).Limit(perPage).Find(&activities).Error
-	if err != nil {
-		return nil, 0, err
-	}
+	return query
+}
 
-	return activities, total, nil
+func (r *repository) FindInBatches(req *activity.ListActivitiesRequest, fn func([]activity.Activity) error) error {
+	var batch []activity.Activity
+	return r.filter(req).FindInBatches(&batch, export.BatchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
 }
 
 func (r *repository) Create(a *activity.Activity) error {
@@ -0,0 +1,140 @@
+package ai_conversation
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_conversation"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new AI conversation repository
+func NewRepository(db *gorm.DB) interfaces.AIConversationRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindByID(id string) (*ai_conversation.Conversation, error) {
+	var conversation ai_conversation.Conversation
+	err := r.db.Where("id = ?", id).First(&conversation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+func (r *repository) FindByIDWithMessages(id string) (*ai_conversation.Conversation, error) {
+	var conversation ai_conversation.Conversation
+	err := r.db.
+		Preload("Messages", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Where("id = ?", id).
+		First(&conversation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+func (r *repository) ListByUserID(userID string, req *ai_conversation.ListConversationsRequest) ([]ai_conversation.Conversation, int64, error) {
+	var conversations []ai_conversation.Conversation
+	var total int64
+
+	query := r.db.Model(&ai_conversation.Conversation{}).Where("user_id = ?", userID)
+
+	// Apply filters
+	if req.Search != "" {
+		query = query.Where("title ILIKE ?", "%"+req.Search+"%")
+	}
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	offset := (page - 1) * perPage
+
+	// Fetch data
+	err := query.
+		Order("last_message_at DESC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&conversations).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return conversations, total, nil
+}
+
+func (r *repository) Create(conversation *ai_conversation.Conversation) error {
+	return r.db.Create(conversation).Error
+}
+
+func (r *repository) Update(conversation *ai_conversation.Conversation) error {
+	return r.db.Model(conversation).Updates(conversation).Error
+}
+
+func (r *repository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("conversation_id = ?", id).Delete(&ai_conversation.Message{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&ai_conversation.Conversation{}).Error
+	})
+}
+
+func (r *repository) AddMessage(message *ai_conversation.Message) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"last_message_at": message.CreatedAt,
+		}
+		// Only answers carry billed tokens; question token counts are estimates
+		if message.Role == ai_conversation.RoleAssistant {
+			updates["total_tokens"] = gorm.Expr("total_tokens + ?", message.Tokens)
+		}
+		if message.Model != "" {
+			updates["model"] = message.Model
+		}
+		return tx.Model(&ai_conversation.Conversation{}).
+			Where("id = ?", message.ConversationID).
+			Updates(updates).Error
+	})
+}
+
+func (r *repository) ListRecentMessages(conversationID string, limit int) ([]ai_conversation.Message, error) {
+	var messages []ai_conversation.Message
+	err := r.db.
+		Where("conversation_id = ?", conversationID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Return in chronological order
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, nil
+}
@@ -0,0 +1,94 @@
+package ai_model_usage
+
+import (
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_model_usage"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const dateFormat = "2006-01-02"
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new AI model usage repository
+func NewRepository(db *gorm.DB) interfaces.AIModelUsageRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) Record(userID string, model string, feature string, tokens int, usedAt time.Time) error {
+	usage := &ai_model_usage.ModelUsage{
+		UserID:       userID,
+		UsageDate:    time.Date(usedAt.Year(), usedAt.Month(), usedAt.Day(), 0, 0, 0, 0, time.UTC),
+		Model:        model,
+		Feature:      feature,
+		Tokens:       int64(tokens),
+		RequestCount: 1,
+		LastUsedAt:   usedAt,
+	}
+
+	// One row per user, day, model and feature; concurrent requests add to the same row
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "usage_date"}, {Name: "model"}, {Name: "feature"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"tokens":        gorm.Expr("ai_model_usage.tokens + EXCLUDED.tokens"),
+			"request_count": gorm.Expr("ai_model_usage.request_count + 1"),
+			"last_used_at":  gorm.Expr("EXCLUDED.last_used_at"),
+			"updated_at":    gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	}).Create(usage).Error
+}
+
+func (r *repository) SumTokensByUser(userID string, from time.Time, to time.Time) (int64, error) {
+	var total int64
+	err := r.db.Model(&ai_model_usage.ModelUsage{}).
+		Select("COALESCE(SUM(tokens), 0)").
+		Where("user_id = ? AND usage_date BETWEEN ? AND ?", userID, from.Format(dateFormat), to.Format(dateFormat)).
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *repository) SummarizeByUser(from time.Time, to time.Time, userID string) ([]ai_model_usage.UserUsage, error) {
+	var results []ai_model_usage.UserUsage
+	err := r.periodQuery(from, to, userID).
+		Select("ai_model_usage.user_id, COALESCE(users.name, '') AS user_name, COALESCE(users.email, '') AS user_email, SUM(ai_model_usage.tokens) AS tokens, SUM(ai_model_usage.request_count) AS request_count").
+		Joins("LEFT JOIN users ON users.id = ai_model_usage.user_id").
+		Group("ai_model_usage.user_id, users.name, users.email").
+		Order("tokens DESC").
+		Scan(&results).Error
+	return results, err
+}
+
+func (r *repository) SummarizeByModel(from time.Time, to time.Time, userID string) ([]ai_model_usage.ModelUsageSummary, error) {
+	var results []ai_model_usage.ModelUsageSummary
+	err := r.periodQuery(from, to, userID).
+		Select("ai_model_usage.model, SUM(ai_model_usage.tokens) AS tokens, SUM(ai_model_usage.request_count) AS request_count").
+		Group("ai_model_usage.model").
+		Order("tokens DESC").
+		Scan(&results).Error
+	return results, err
+}
+
+func (r *repository) SummarizeByFeature(from time.Time, to time.Time, userID string) ([]ai_model_usage.FeatureUsage, error) {
+	var results []ai_model_usage.FeatureUsage
+	err := r.periodQuery(from, to, userID).
+		Select("ai_model_usage.feature, SUM(ai_model_usage.tokens) AS tokens, SUM(ai_model_usage.request_count) AS request_count").
+		Group("ai_model_usage.feature").
+		Order("tokens DESC").
+		Scan(&results).Error
+	return results, err
+}
+
+// periodQuery returns usage rows on days in [from, to], optionally for a single user
+func (r *repository) periodQuery(from time.Time, to time.Time, userID string) *gorm.DB {
+	query := r.db.Model(&ai_model_usage.ModelUsage{}).
+		Where("ai_model_usage.usage_date BETWEEN ? AND ?", from.Format(dateFormat), to.Format(dateFormat))
+	if userID != "" {
+		query = query.Where("ai_model_usage.user_id = ?", userID)
+	}
+	return query
+}
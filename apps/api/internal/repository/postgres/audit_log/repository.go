@@ -0,0 +1,97 @@
+package audit_log
+
+import (
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/audit_log"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new audit log repository
+func NewRepository(db *gorm.DB) interfaces.AuditLogRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(entry *audit_log.AuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *repository) List(req *audit_log.ListAuditLogsRequest) ([]audit_log.AuditLog, int64, error) {
+	var logs []audit_log.AuditLog
+	var total int64
+
+	query := r.db.Model(&audit_log.AuditLog{})
+
+	// Apply filters
+	if req.ActorID != "" {
+		query = query.Where("actor_id = ?", req.ActorID)
+	}
+
+	if req.Action != "" {
+		query = query.Where("action = ?", req.Action)
+	}
+
+	if req.ResourceType != "" {
+		query = query.Where("resource_type = ?", req.ResourceType)
+	}
+
+	if req.ResourceID != "" {
+		query = query.Where("resource_id = ?", req.ResourceID)
+	}
+
+	if req.RequestID != "" {
+		query = query.Where("request_id = ?", req.RequestID)
+	}
+
+	if req.StartDate != "" {
+		startDate, err := time.Parse("2006-01-02", req.StartDate)
+		if err == nil {
+			query = query.Where("created_at >= ?", startDate)
+		}
+	}
+
+	if req.EndDate != "" {
+		endDate, err := time.Parse("2006-01-02", req.EndDate)
+		if err == nil {
+			// Add one day to include the end date
+			query = query.Where("created_at < ?", endDate.Add(24*time.Hour))
+		}
+	}
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	offset := (page - 1) * perPage
+
+	// Fetch data
+	err := query.
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&logs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
@@ -0,0 +1,64 @@
+package competitor
+
+import (
+	"strings"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/competitor"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new competitor repository
+func NewRepository(db *gorm.DB) interfaces.CompetitorRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindByID(id string) (*competitor.Competitor, error) {
+	var c competitor.Competitor
+	err := r.db.Where("id = ?", id).First(&c).Error
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *repository) FindByName(name string) (*competitor.Competitor, error) {
+	var c competitor.Competitor
+	err := r.db.Where("LOWER(name) = ?", strings.ToLower(name)).First(&c).Error
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *repository) List(req *competitor.ListCompetitorsRequest) ([]competitor.Competitor, error) {
+	var competitors []competitor.Competitor
+	query := r.db.Model(&competitor.Competitor{})
+	if req.Search != "" {
+		query = query.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(req.Search)+"%")
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	err := query.Order("name ASC").Find(&competitors).Error
+	if err != nil {
+		return nil, err
+	}
+	return competitors, nil
+}
+
+func (r *repository) Create(c *competitor.Competitor) error {
+	return r.db.Create(c).Error
+}
+
+func (r *repository) Update(c *competitor.Competitor) error {
+	return r.db.Save(c).Error
+}
+
+func (r *repository) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&competitor.Competitor{}).Error
+}
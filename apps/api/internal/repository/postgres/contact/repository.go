@@ -5,6 +5,7 @@ import (
 
 	"github.com/gilabs/crm-healthcare/api/internal/domain/contact"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"gorm.io/gorm"
 )
 
@@ -30,24 +31,7 @@ func (r *repository) List(req *contact.ListContactsRequest) ([]contact.Contact,
 	var contacts []contact.Contact
 	var total int64
 
-	query := r.db.Model(&contact.Contact{})
-
-	// Apply filters
-	if req.Search != "" {
-		search := "%" + strings.ToLower(req.Search) + "%"
-		query = query.Where(
-			"LOWER(name) LIKE ? OR LOWER(email) LIKE ? OR LOWER(phone) LIKE ? OR LOWER(position) LIKE ?",
-			search, search, search, search,
-		)
-	}
-
-	if req.AccountID != "" {
-		query = query.Where("account_id = ?", req.AccountID)
-	}
-
-	if req.RoleID != "" {
-		query = query.Where("role_id = ?", req.RoleID)
-	}
+	query := r.filter(req)
 
 	// Count total
 	if err := query.Count(&total).Error; err != nil {
@@ -78,6 +62,39 @@ func (r *repository) List(req *contact.ListContactsRequest) ([]contact.Contact,
 	return contacts, total, nil
 }
 
+// filter builds the query of contacts matching the list filters
+func (r *repository) filter(req *contact.ListContactsRequest) *gorm.DB {
+	query := r.db.Model(&contact.Contact{})
+
+	// Apply filters
+	if req.Search != "" {
+		search := "%" + strings.ToLower(req.Search) + "%"
+		query = query.Where(
+			"LOWER(name) LIKE ? OR LOWER(email) LIKE ? OR LOWER(phone) LIKE ? OR LOWER(position) LIKE ?",
+			search, search, search, search,
+		)
+	}
+
+	if req.AccountID != "" {
+		query = query.Where("account_id = ?", req.AccountID)
+	}
+
+	if req.RoleID != "" {
+		query = query.Where("role_id = ?", req.RoleID)
+	}
+
+	return query
+}
+
+func (r *repository) FindInBatches(req *contact.ListContactsRequest, fn func([]contact.Contact) error) error {
+	var batch []contact.Contact
+	return r.filter(req).
+		Preload("Role").
+		FindInBatches(&batch, export.BatchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
+}
+
 func (r *repository) Create(c *contact.Contact) error {
 	return r.db.Create(c).Error
 }
@@ -5,13 +5,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"gorm.io/gorm"
 )
 
 type repository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	scope *datascope.Scope
 }
 
 // NewRepository creates a new deal repository
@@ -19,13 +22,22 @@ func NewRepository(db *gorm.DB) interfaces.DealRepository {
 	return &repository{db: db}
 }
 
+// WithScope returns a repository restricted to the deals visible within the given data scope
+func (r *repository) WithScope(scope *datascope.Scope) interfaces.DealRepository {
+	return &repository{db: r.db, scope: scope}
+}
+
 func (r *repository) FindByID(id string) (*pipeline.Deal, error) {
 	var deal pipeline.Deal
 	err := r.db.
+		Scopes(r.scope.Apply("deals.assigned_to")).
 		Preload("Account").
 		Preload("Contact").
 		Preload("Stage").
 		Preload("AssignedUser").
+		Preload("LostReason").
+		Preload("Competitor").
+		Preload("LostFromStage").
 		Where("id = ?", id).
 		First(&deal).Error
 	if err != nil {
@@ -38,7 +50,50 @@ func (r *repository) List(req *pipeline.ListDealsRequest) ([]pipeline.Deal, int6
 	var deals []pipeline.Deal
 	var total int64
 
-	query := r.db.Model(&pipeline.Deal{})
+	query := r.filter(req)
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	offset := (page - 1) * perPage
+
+	// Fetch data with preload
+	err := query.
+		Preload("Account").
+		Preload("Contact").
+		Preload("Stage").
+		Preload("AssignedUser").
+		Preload("LostReason").
+		Preload("Competitor").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&deals).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deals, total, nil
+}
+
+// filter builds the query of deals matching the list filters
+func (r *repository) filter(req *pipeline.ListDealsRequest) *gorm.DB {
+	query := r.db.Model(&pipeline.Deal{}).Scopes(r.scope.Apply("deals.assigned_to"))
 
 	// Apply filters
 	if req.Search != "" {
@@ -49,6 +104,10 @@ func (r *repository) List(req *pipeline.ListDealsRequest) ([]pipeline.Deal, int6
 		)
 	}
 
+	if req.PipelineID != "" {
+		query = query.Where("pipeline_id = ?", req.PipelineID)
+	}
+
 	if req.StageID != "" {
 		query = query.Where("stage_id = ?", req.StageID)
 	}
@@ -69,102 +128,161 @@ func (r *repository) List(req *pipeline.ListDealsRequest) ([]pipeline.Deal, int6
 		query = query.Where("source = ?", req.Source)
 	}
 
-	// Count total
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
+	return query
+}
 
-	// Apply pagination
-	page := req.Page
-	if page < 1 {
-		page = 1
-	}
-	perPage := req.PerPage
-	if perPage < 1 {
-		perPage = 20
-	}
-	if perPage > 100 {
-		perPage = 100
-	}
+func (r *repository) FindInBatches(req *pipeline.ListDealsRequest, fn func([]pipeline.Deal) error) error {
+	var batch []pipeline.Deal
+	return r.filter(req).
+		Preload("Account").
+		Preload("Contact").
+		Preload("Stage").
+		Preload("AssignedUser").
+		Preload("LostReason").
+		Preload("Competitor").
+		FindInBatches(&batch, export.BatchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
+}
 
-	offset := (page - 1) * perPage
+func (r *repository) ListClosed(start, end time.Time, pipelineID, assignedTo string) ([]pipeline.Deal, error) {
+	query := r.db.Model(&pipeline.Deal{}).
+		Scopes(r.scope.Apply("deals.assigned_to"), inPipeline(pipelineID)).
+		Where("status IN ?", []string{"won", "lost"}).
+		// Deals closed without a close date count on the day they were last updated, as for sales targets
+		Where("COALESCE(actual_close_date, updated_at::date) BETWEEN ? AND ?", start.Format("2006-01-02"), end.Format("2006-01-02"))
 
-	// Fetch data with preload
+	if assignedTo != "" {
+		query = query.Where("assigned_to = ?", assignedTo)
+	}
+
+	var deals []pipeline.Deal
 	err := query.
 		Preload("Account").
-		Preload("Contact").
-		Preload("Stage").
 		Preload("AssignedUser").
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(perPage).
+		Preload("LostReason").
+		Preload("Competitor").
+		Preload("LostFromStage").
+		Order("actual_close_date DESC, created_at DESC").
 		Find(&deals).Error
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
-
-	return deals, total, nil
+	return deals, nil
 }
 
-func (r *repository) Create(deal *pipeline.Deal) error {
-	return r.db.Create(deal).Error
+func (r *repository) Create(deal *pipeline.Deal, stageChange *pipeline.DealStageHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(deal).Error; err != nil {
+			return err
+		}
+		return recordStageChange(tx, deal.ID, stageChange)
+	})
 }
 
-func (r *repository) Update(deal *pipeline.Deal) error {
+func (r *repository) Update(deal *pipeline.Deal, stageChange *pipeline.DealStageHistory) error {
+	if err := r.scope.Authorize(r.db, &pipeline.Deal{}, "deals.assigned_to", deal.ID); err != nil {
+		return err
+	}
 
 	deal.Account = nil
 	deal.Contact = nil
 	deal.Stage = nil
 	deal.AssignedUser = nil
+	deal.LostReason = nil
+	deal.Competitor = nil
+	deal.LostFromStage = nil
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(deal).Omit("Account", "Contact", "Stage", "AssignedUser", "LostReason", "Competitor", "LostFromStage").Updates(deal).Error; err != nil {
+			return err
+		}
+
+		// Updates skips zero values, so the loss details are written explicitly to clear them when a deal is reopened
+		err := tx.Model(&pipeline.Deal{}).Where("id = ?", deal.ID).Updates(map[string]interface{}{
+			"lost_reason_id":     deal.LostReasonID,
+			"competitor_id":      deal.CompetitorID,
+			"lost_from_stage_id": deal.LostFromStageID,
+			"lost_notes":         deal.LostNotes,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return recordStageChange(tx, deal.ID, stageChange)
+	})
+}
+
+// recordStageChange closes the open stage history entry of the deal, if any, at entry.EnteredAt and creates entry.
+// It runs in the transaction that saves the deal, so the history never diverges from deals.stage_id.
+func recordStageChange(tx *gorm.DB, dealID string, entry *pipeline.DealStageHistory) error {
+	if entry == nil {
+		return nil
+	}
+	entry.DealID = dealID
+	err := tx.Model(&pipeline.DealStageHistory{}).
+		Where("deal_id = ? AND exited_at IS NULL", dealID).
+		Update("exited_at", entry.EnteredAt).Error
+	if err != nil {
+		return err
+	}
+	return tx.Create(entry).Error
+}
 
-	return r.db.Model(deal).Omit("Account", "Contact", "Stage", "AssignedUser").Updates(deal).Error
+func (r *repository) UpdateValue(id string, value int64) error {
+	if err := r.scope.Authorize(r.db, &pipeline.Deal{}, "deals.assigned_to", id); err != nil {
+		return err
+	}
+	return r.db.Model(&pipeline.Deal{}).Where("id = ?", id).Update("value", value).Error
 }
 
 func (r *repository) Delete(id string) error {
+	if err := r.scope.Authorize(r.db, &pipeline.Deal{}, "deals.assigned_to", id); err != nil {
+		return err
+	}
 	return r.db.Where("id = ?", id).Delete(&pipeline.Deal{}).Error
 }
 
-func (r *repository) GetSummary() (*pipeline.PipelineSummaryResponse, error) {
+func (r *repository) GetSummary(pipelineID string) (*pipeline.PipelineSummaryResponse, error) {
 	var totalDeals, wonDeals, lostDeals, openDeals int64
 	var totalValue, wonValue, lostValue, openValue int64
 
 	// Count total deals
-	if err := r.db.Model(&pipeline.Deal{}).Count(&totalDeals).Error; err != nil {
+	if err := r.db.Model(&pipeline.Deal{}).Scopes(r.scope.Apply("deals.assigned_to"), inPipeline(pipelineID)).Count(&totalDeals).Error; err != nil {
 		return nil, err
 	}
 
 	// Sum total value
-	if err := r.db.Model(&pipeline.Deal{}).Select("COALESCE(SUM(value), 0)").Scan(&totalValue).Error; err != nil {
+	if err := r.db.Model(&pipeline.Deal{}).Scopes(r.scope.Apply("deals.assigned_to"), inPipeline(pipelineID)).Select("COALESCE(SUM(value), 0)").Scan(&totalValue).Error; err != nil {
 		return nil, err
 	}
 
 	// Count and sum won deals
-	if err := r.db.Model(&pipeline.Deal{}).Where("status = ?", "won").Count(&wonDeals).Error; err != nil {
+	if err := r.db.Model(&pipeline.Deal{}).Scopes(r.scope.Apply("deals.assigned_to"), inPipeline(pipelineID)).Where("status = ?", "won").Count(&wonDeals).Error; err != nil {
 		return nil, err
 	}
-	if err := r.db.Model(&pipeline.Deal{}).Where("status = ?", "won").Select("COALESCE(SUM(value), 0)").Scan(&wonValue).Error; err != nil {
+	if err := r.db.Model(&pipeline.Deal{}).Scopes(r.scope.Apply("deals.assigned_to"), inPipeline(pipelineID)).Where("status = ?", "won").Select("COALESCE(SUM(value), 0)").Scan(&wonValue).Error; err != nil {
 		return nil, err
 	}
 
 	// Count and sum lost deals
-	if err := r.db.Model(&pipeline.Deal{}).Where("status = ?", "lost").Count(&lostDeals).Error; err != nil {
+	if err := r.db.Model(&pipeline.Deal{}).Scopes(r.scope.Apply("deals.assigned_to"), inPipeline(pipelineID)).Where("status = ?", "lost").Count(&lostDeals).Error; err != nil {
 		return nil, err
 	}
-	if err := r.db.Model(&pipeline.Deal{}).Where("status = ?", "lost").Select("COALESCE(SUM(value), 0)").Scan(&lostValue).Error; err != nil {
+	if err := r.db.Model(&pipeline.Deal{}).Scopes(r.scope.Apply("deals.assigned_to"), inPipeline(pipelineID)).Where("status = ?", "lost").Select("COALESCE(SUM(value), 0)").Scan(&lostValue).Error; err != nil {
 		return nil, err
 	}
 
 	// Count and sum open deals
-	if err := r.db.Model(&pipeline.Deal{}).Where("status = ?", "open").Count(&openDeals).Error; err != nil {
+	if err := r.db.Model(&pipeline.Deal{}).Scopes(r.scope.Apply("deals.assigned_to"), inPipeline(pipelineID)).Where("status = ?", "open").Count(&openDeals).Error; err != nil {
 		return nil, err
 	}
-	if err := r.db.Model(&pipeline.Deal{}).Where("status = ?", "open").Select("COALESCE(SUM(value), 0)").Scan(&openValue).Error; err != nil {
+	if err := r.db.Model(&pipeline.Deal{}).Scopes(r.scope.Apply("deals.assigned_to"), inPipeline(pipelineID)).Where("status = ?", "open").Select("COALESCE(SUM(value), 0)").Scan(&openValue).Error; err != nil {
 		return nil, err
 	}
 
 	// Get summary by stage
 	var stageSummaries []pipeline.StageSummary
-	err := r.db.Model(&pipeline.Deal{}).
+	err := r.db.Model(&pipeline.Deal{}).Scopes(r.scope.Apply("deals.assigned_to"), inPipeline(pipelineID)).
 		Select(`
 			stage_id,
 			COUNT(*) as deal_count,
@@ -206,10 +324,11 @@ func (r *repository) GetSummary() (*pipeline.PipelineSummaryResponse, error) {
 	return summary, nil
 }
 
-func (r *repository) GetForecast(periodType string, start, end time.Time) (*pipeline.ForecastResponse, error) {
+func (r *repository) GetForecast(periodType string, start, end time.Time, pipelineID string) (*pipeline.ForecastResponse, error) {
 	// Get deals with expected close date in the period
 	var deals []pipeline.Deal
 	err := r.db.
+		Scopes(inPipeline(pipelineID)).
 		Preload("Account").
 		Preload("Contact").
 		Preload("Stage").
@@ -276,6 +395,16 @@ func (r *repository) GetForecast(periodType string, start, end time.Time) (*pipe
 	return forecast, nil
 }
 
+// inPipeline limits deal queries to one pipeline; an empty pipelineID covers all pipelines
+func inPipeline(pipelineID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if pipelineID == "" {
+			return db
+		}
+		return db.Where("deals.pipeline_id = ?", pipelineID)
+	}
+}
+
 // formatCurrency formats integer (sen) to formatted currency string
 func formatCurrency(amount int64) string {
 	// Convert to Rupiah (divide by 100 if stored in sen)
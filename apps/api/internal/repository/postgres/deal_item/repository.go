@@ -0,0 +1,89 @@
+package deal_item
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+const dateFormat = "2006-01-02"
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new deal item repository
+func NewRepository(db *gorm.DB) interfaces.DealItemRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindByID(id string) (*pipeline.DealItem, error) {
+	var item pipeline.DealItem
+	err := r.db.Preload("Product").Where("id = ?", id).First(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *repository) ListByDeal(dealID string) ([]pipeline.DealItem, error) {
+	var items []pipeline.DealItem
+	err := r.db.Preload("Product").
+		Where("deal_id = ?", dealID).
+		Order("created_at ASC").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *repository) Create(item *pipeline.DealItem) error {
+	return r.db.Omit("Product").Create(item).Error
+}
+
+func (r *repository) Update(item *pipeline.DealItem) error {
+	return r.db.Omit("Product").Save(item).Error
+}
+
+func (r *repository) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&pipeline.DealItem{}).Error
+}
+
+func (r *repository) ProductRevenue(req *pipeline.ProductRevenueRequest) ([]pipeline.ProductRevenue, error) {
+	query := r.db.Table("deal_items").
+		Select(`products.id AS product_id, products.name AS product_name, products.sku AS sku,
+			products.category_id AS category_id, COALESCE(product_categories.name, '') AS category_name,
+			COUNT(DISTINCT deals.id) AS deals, COALESCE(SUM(deal_items.quantity), 0) AS quantity,
+			COALESCE(SUM(deal_items.subtotal - deal_items.discount_amount), 0) AS revenue,
+			COALESCE(SUM(CASE WHEN deals.status = 'won' THEN deal_items.subtotal - deal_items.discount_amount ELSE 0 END), 0) AS won_revenue`).
+		Joins("JOIN deals ON deals.id = deal_items.deal_id AND deals.deleted_at IS NULL").
+		Joins("JOIN products ON products.id = deal_items.product_id").
+		Joins("LEFT JOIN product_categories ON product_categories.id = products.category_id").
+		Where("deal_items.deleted_at IS NULL")
+
+	if req.PipelineID != "" {
+		query = query.Where("deals.pipeline_id = ?", req.PipelineID)
+	}
+	if req.AssignedTo != "" {
+		query = query.Where("deals.assigned_to = ?", req.AssignedTo)
+	}
+	if req.Status != "" {
+		query = query.Where("deals.status = ?", req.Status)
+	}
+	if req.ClosedFrom != nil && req.ClosedTo != nil {
+		// Deals closed without a close date count on the day they were last updated, as for sales targets
+		query = query.Where("COALESCE(deals.actual_close_date, deals.updated_at::date) BETWEEN ? AND ?",
+			req.ClosedFrom.Format(dateFormat), req.ClosedTo.Format(dateFormat))
+	}
+
+	var rows []pipeline.ProductRevenue
+	err := query.
+		Group("products.id, products.name, products.sku, products.category_id, product_categories.name").
+		Order("revenue DESC, product_name ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,69 @@
+package deal_stage_history
+
+import (
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db    *gorm.DB
+	scope *datascope.Scope
+}
+
+// NewRepository creates a new deal stage history repository
+func NewRepository(db *gorm.DB) interfaces.DealStageHistoryRepository {
+	return &repository{db: db}
+}
+
+// WithScope returns a repository whose analytics only cover the deals visible within the given data scope
+func (r *repository) WithScope(scope *datascope.Scope) interfaces.DealStageHistoryRepository {
+	return &repository{db: r.db, scope: scope}
+}
+
+func (r *repository) ListByDeal(dealID string) ([]pipeline.DealStageHistory, error) {
+	var entries []pipeline.DealStageHistory
+	err := r.db.
+		Preload("FromStage").
+		Preload("ToStage").
+		Preload("ChangedUser").
+		Where("deal_id = ?", dealID).
+		Order("entered_at ASC, created_at ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *repository) ListForAnalytics(pipelineID string, start, end time.Time, assignedTo string) ([]pipeline.DealStageHistory, error) {
+	var entries []pipeline.DealStageHistory
+
+	query := r.db.
+		Joins("JOIN deals ON deals.id = deal_stage_history.deal_id AND deals.deleted_at IS NULL").
+		Scopes(r.scope.Apply("deals.assigned_to")).
+		Preload("Deal").
+		Where("deals.pipeline_id = ?", pipelineID).
+		Where("deals.created_at >= ? AND deals.created_at < ?", start, end)
+
+	if assignedTo != "" {
+		query = query.Where("deals.assigned_to = ?", assignedTo)
+	}
+
+	err := query.
+		Order("deal_stage_history.deal_id ASC, deal_stage_history.entered_at ASC, deal_stage_history.created_at ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *repository) BackfillMissing() (int64, error) {
+	result := r.db.Exec(`
+		INSERT INTO deal_stage_history (id, deal_id, to_stage_id, entered_at, changed_by, source, created_at)
+		SELECT gen_random_uuid(), d.id, d.stage_id, d.created_at, d.created_by, ?, NOW()
+		FROM deals d
+		WHERE d.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM deal_stage_history h WHERE h.deal_id = d.id)`,
+		pipeline.StageChangeBackfill,
+	)
+	return result.RowsAffected, result.Error
+}
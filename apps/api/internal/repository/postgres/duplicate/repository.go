@@ -0,0 +1,136 @@
+package duplicate
+
+import (
+	"strings"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/contact"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/duplicate"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// phoneSuffixSQL compares the last 9 digits of the phone column, whatever format it was stored in
+const phoneSuffixSQL = "RIGHT(REGEXP_REPLACE(phone, '[^0-9]', '', 'g'), 9) = ?"
+
+// Tables re-pointed from a merged loser to the survivor
+var (
+	leadReferences    = []string{"deals", "activities", "visit_reports"}
+	accountReferences = []string{"deals", "contacts", "leads", "activities", "tasks", "visit_reports", "quotations", "report_subscriptions"}
+	contactReferences = []string{"deals", "leads", "activities", "tasks", "visit_reports"}
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new duplicate repository
+func NewRepository(db *gorm.DB) interfaces.DuplicateRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindLeadCandidates(q duplicate.Query) ([]duplicate.Record, error) {
+	nameSQL := "LOWER(first_name || ' ' || COALESCE(last_name, '')) LIKE ?"
+	query := r.db.Table("leads").
+		Select("id, TRIM(first_name || ' ' || COALESCE(last_name, '')) AS name, company_name AS company, email, phone, city")
+	return r.findCandidates(query, q, nameSQL)
+}
+
+func (r *repository) FindAccountCandidates(q duplicate.Query) ([]duplicate.Record, error) {
+	query := r.db.Table("accounts").Select("id, name, email, phone, city")
+	return r.findCandidates(query, q, "LOWER(name) LIKE ?")
+}
+
+func (r *repository) FindContactCandidates(q duplicate.Query) ([]duplicate.Record, error) {
+	// The same doctor may work at several hospitals, so names only match within one account
+	nameSQL := ""
+	var nameArgs []interface{}
+	if q.AccountID != "" {
+		nameSQL = "(account_id = ? AND LOWER(name) LIKE ?)"
+		nameArgs = []interface{}{q.AccountID}
+	}
+	query := r.db.Table("contacts").Select("id, name, email, phone, account_id")
+	return r.findCandidates(query, q, nameSQL, nameArgs...)
+}
+
+// findCandidates prefilters records sharing the email, the phone suffix or a name key of the query.
+// nameSQL takes the name pattern as its last argument; an empty nameSQL skips name candidates.
+func (r *repository) findCandidates(query *gorm.DB, q duplicate.Query, nameSQL string, nameArgs ...interface{}) ([]duplicate.Record, error) {
+	var conditions []string
+	var args []interface{}
+	if q.Email != "" {
+		conditions = append(conditions, "LOWER(TRIM(email)) = ?")
+		args = append(args, q.Email)
+	}
+	if q.PhoneSuffix != "" {
+		conditions = append(conditions, phoneSuffixSQL)
+		args = append(args, q.PhoneSuffix)
+	}
+	if nameSQL != "" {
+		for _, key := range q.NameKeys {
+			conditions = append(conditions, nameSQL)
+			args = append(args, nameArgs...)
+			args = append(args, "%"+key+"%")
+		}
+	}
+
+	records := make([]duplicate.Record, 0)
+	if len(conditions) == 0 {
+		return records, nil
+	}
+
+	query = query.Where("deleted_at IS NULL").Where("("+strings.Join(conditions, " OR ")+")", args...)
+	if q.ExcludeID != "" {
+		query = query.Where("id <> ?", q.ExcludeID)
+	}
+	err := query.Limit(duplicate.CandidateLimit).Scan(&records).Error
+	return records, err
+}
+
+func (r *repository) MergeLeads(survivor *lead.Lead, loserID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(survivor).Error; err != nil {
+			return err
+		}
+		if err := repoint(tx, leadReferences, "lead_id", survivor.ID, loserID); err != nil {
+			return err
+		}
+		return tx.Where("id = ?", loserID).Delete(&lead.Lead{}).Error
+	})
+}
+
+func (r *repository) MergeAccounts(survivor *account.Account, loserID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(survivor).Error; err != nil {
+			return err
+		}
+		if err := repoint(tx, accountReferences, "account_id", survivor.ID, loserID); err != nil {
+			return err
+		}
+		return tx.Where("id = ?", loserID).Delete(&account.Account{}).Error
+	})
+}
+
+func (r *repository) MergeContacts(survivor *contact.Contact, loserID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(survivor).Error; err != nil {
+			return err
+		}
+		if err := repoint(tx, contactReferences, "contact_id", survivor.ID, loserID); err != nil {
+			return err
+		}
+		return tx.Where("id = ?", loserID).Delete(&contact.Contact{}).Error
+	})
+}
+
+// repoint moves every row of the tables referencing the loser to the survivor, soft-deleted rows included
+func repoint(tx *gorm.DB, tables []string, column string, survivorID string, loserID string) error {
+	for _, table := range tables {
+		if err := tx.Table(table).Where(column+" = ?", loserID).UpdateColumn(column, survivorID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
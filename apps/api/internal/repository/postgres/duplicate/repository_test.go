@@ -0,0 +1,55 @@
+package duplicate
+
+import (
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB returns a database that builds statements without a server, recording every update
+func dryRunDB(t *testing.T, statements *[]string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("test:record", func(tx *gorm.DB) {
+		*statements = append(*statements, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	return db
+}
+
+func TestRepointAccountReferences(t *testing.T) {
+	var statements []string
+	db := dryRunDB(t, &statements)
+
+	if err := repoint(db, accountReferences, "account_id", "keep", "drop"); err != nil {
+		t.Fatalf("repoint failed: %v", err)
+	}
+
+	// Report subscriptions of the loser would otherwise keep pointing at a deleted account and send empty reports
+	want := map[string]bool{
+		`UPDATE "quotations" SET "account_id"='keep' WHERE account_id = 'drop'`:           false,
+		`UPDATE "report_subscriptions" SET "account_id"='keep' WHERE account_id = 'drop'`: false,
+	}
+	for _, statement := range statements {
+		if _, ok := want[statement]; ok {
+			want[statement] = true
+		}
+	}
+	for statement, found := range want {
+		if !found {
+			t.Errorf("expected %s, got %v", statement, statements)
+		}
+	}
+	if len(statements) != len(accountReferences) {
+		t.Errorf("expected one update per referencing table, got %d", len(statements))
+	}
+}
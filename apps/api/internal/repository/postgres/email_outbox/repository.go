@@ -0,0 +1,102 @@
+package email_outbox
+
+import (
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/email_outbox"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new email outbox repository
+func NewRepository(db *gorm.DB) interfaces.EmailOutboxRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindByID(id string) (*email_outbox.EmailMessage, error) {
+	var m email_outbox.EmailMessage
+	err := r.db.Where("id = ?", id).First(&m).Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *repository) List(req *email_outbox.ListEmailMessagesRequest) ([]email_outbox.EmailMessage, int64, error) {
+	var messages []email_outbox.EmailMessage
+	var total int64
+
+	query := r.db.Model(&email_outbox.EmailMessage{})
+
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.Template != "" {
+		query = query.Where("template = ?", req.Template)
+	}
+	if req.UserID != "" {
+		query = query.Where("user_id = ?", req.UserID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	err := query.
+		Order("created_at DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&messages).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
+
+func (r *repository) Create(m *email_outbox.EmailMessage) error {
+	return r.db.Create(m).Error
+}
+
+func (r *repository) Update(m *email_outbox.EmailMessage) error {
+	return r.db.Save(m).Error
+}
+
+func (r *repository) FindDue(now time.Time, limit int) ([]email_outbox.EmailMessage, error) {
+	var messages []email_outbox.EmailMessage
+	err := r.db.
+		Where("status = ? AND next_attempt_at <= ?", email_outbox.StatusPending, now.UTC()).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+func (r *repository) ClaimAttempt(id string, attempts int, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&email_outbox.EmailMessage{}).
+		Where("id = ? AND status = ? AND attempts = ?", id, email_outbox.StatusPending, attempts).
+		UpdateColumns(map[string]interface{}{
+			"attempts":        attempts + 1,
+			"next_attempt_at": leaseUntil.UTC(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,115 @@
+package import_job
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/import_job"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new import job repository
+func NewRepository(db *gorm.DB) interfaces.ImportJobRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindByID(id string) (*import_job.ImportJob, error) {
+	var job import_job.ImportJob
+	// Rows can hold thousands of records; status polling does not need them
+	err := r.db.Omit("rows").Where("id = ?", id).First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *repository) FindRows(id string) ([]import_job.Row, error) {
+	var job import_job.ImportJob
+	if err := r.db.Select("id", "rows").Where("id = ?", id).First(&job).Error; err != nil {
+		return nil, err
+	}
+	rows := make([]import_job.Row, 0)
+	if len(job.Rows) > 0 {
+		if err := json.Unmarshal(job.Rows, &rows); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+func (r *repository) List(req *import_job.ListImportJobsRequest) ([]import_job.ImportJob, int64, error) {
+	var jobs []import_job.ImportJob
+	var total int64
+
+	query := r.db.Model(&import_job.ImportJob{})
+
+	if req.EntityType != "" {
+		query = query.Where("entity_type = ?", req.EntityType)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	err := query.
+		Omit("rows").
+		Order("created_at DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return jobs, total, nil
+}
+
+func (r *repository) Create(job *import_job.ImportJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *repository) Update(job *import_job.ImportJob) error {
+	return r.db.Omit("rows").Save(job).Error
+}
+
+func (r *repository) Start(id string) (bool, error) {
+	// Conditional update, so a job committed twice at once is imported only once
+	result := r.db.Model(&import_job.ImportJob{}).
+		Where("id = ? AND status = ?", id, import_job.StatusValidated).
+		Updates(map[string]interface{}{
+			"status":     import_job.StatusImporting,
+			"started_at": time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *repository) MarkImportingAsFailed(message string) (int64, error) {
+	result := r.db.Model(&import_job.ImportJob{}).
+		Where("status = ?", import_job.StatusImporting).
+		Updates(map[string]interface{}{
+			"status":        import_job.StatusFailed,
+			"error_message": message,
+			"completed_at":  time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
@@ -5,13 +5,18 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_scoring"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
 type repository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	scope *datascope.Scope
 }
 
 // NewRepository creates a new lead repository
@@ -19,9 +24,15 @@ func NewRepository(db *gorm.DB) interfaces.LeadRepository {
 	return &repository{db: db}
 }
 
+// WithScope returns a repository restricted to the leads visible within the given data scope
+func (r *repository) WithScope(scope *datascope.Scope) interfaces.LeadRepository {
+	return &repository{db: r.db, scope: scope}
+}
+
 func (r *repository) FindByID(id string) (*lead.Lead, error) {
 	var l lead.Lead
 	err := r.db.
+		Scopes(r.scope.Apply("leads.assigned_to")).
 		Preload("AssignedUser").
 		Preload("Account").
 		Preload("Contact").
@@ -38,28 +49,7 @@ func (r *repository) List(req *lead.ListLeadsRequest) ([]lead.Lead, int64, error
 	var leads []lead.Lead
 	var total int64
 
-	query := r.db.Model(&lead.Lead{})
-
-	// Apply filters
-	if req.Search != "" {
-		search := "%" + strings.ToLower(req.Search) + "%"
-		query = query.Where(
-			"LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ? OR LOWER(email) LIKE ? OR LOWER(company_name) LIKE ? OR LOWER(phone) LIKE ?",
-			search, search, search, search, search,
-		)
-	}
-
-	if req.Status != "" {
-		query = query.Where("lead_status = ?", req.Status)
-	}
-
-	if req.Source != "" {
-		query = query.Where("lead_source = ?", req.Source)
-	}
-
-	if req.AssignedTo != "" {
-		query = query.Where("assigned_to = ?", req.AssignedTo)
-	}
+	query := r.filter(req)
 
 	// Count total
 	if err := query.Count(&total).Error; err != nil {
@@ -108,11 +98,54 @@ func (r *repository) List(req *lead.ListLeadsRequest) ([]lead.Lead, int64, error
 	return leads, total, nil
 }
 
+// filter builds the query of leads matching the list filters
+func (r *repository) filter(req *lead.ListLeadsRequest) *gorm.DB {
+	query := r.db.Model(&lead.Lead{}).Scopes(r.scope.Apply("leads.assigned_to"))
+
+	// Apply filters
+	if req.Search != "" {
+		search := "%" + strings.ToLower(req.Search) + "%"
+		query = query.Where(
+			"LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ? OR LOWER(email) LIKE ? OR LOWER(company_name) LIKE ? OR LOWER(phone) LIKE ?",
+			search, search, search, search, search,
+		)
+	}
+
+	if req.Status != "" {
+		query = query.Where("lead_status = ?", req.Status)
+	}
+
+	if req.Source != "" {
+		query = query.Where("lead_source = ?", req.Source)
+	}
+
+	if req.AssignedTo != "" {
+		query = query.Where("assigned_to = ?", req.AssignedTo)
+	}
+
+	return query
+}
+
+func (r *repository) FindInBatches(req *lead.ListLeadsRequest, fn func([]lead.Lead) error) error {
+	var batch []lead.Lead
+	return r.filter(req).
+		Preload("AssignedUser").
+		Preload("Account").
+		Preload("Opportunity").
+		FindInBatches(&batch, export.BatchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
+}
+
 func (r *repository) Create(l *lead.Lead) error {
 	return r.db.Create(l).Error
 }
 
 func (r *repository) Update(l *lead.Lead) error {
+	if err := r.scope.Authorize(r.db, &lead.Lead{}, "leads.assigned_to", l.ID); err != nil {
+		return err
+	}
+
 	// Clear relations to avoid updating them
 	l.AssignedUser = nil
 	l.Account = nil
@@ -123,6 +156,9 @@ func (r *repository) Update(l *lead.Lead) error {
 }
 
 func (r *repository) Delete(id string) error {
+	if err := r.scope.Authorize(r.db, &lead.Lead{}, "leads.assigned_to", id); err != nil {
+		return err
+	}
 	return r.db.Where("id = ?", id).Delete(&lead.Lead{}).Error
 }
 
@@ -253,9 +289,78 @@ func (r *repository) GetAnalytics(req *lead.LeadAnalyticsRequest) (*lead.LeadAna
 	return analytics, nil
 }
 
+func (r *repository) ListIDs() ([]string, error) {
+	var ids []string
+	err := r.db.Model(&lead.Lead{}).Order("created_at ASC").Pluck("id", &ids).Error
+	return ids, err
+}
 
+func (r *repository) GetEngagement(id string) (*lead_scoring.Engagement, error) {
+	var row struct {
+		ActivityCount    int
+		VisitReportCount int
+		LastActivityAt   *time.Time
+		LastVisitAt      *time.Time
+	}
 
+	// Only touches that already happened count towards recency
+	err := r.db.Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM activities WHERE lead_id = @id AND deleted_at IS NULL) AS activity_count,
+			(SELECT COUNT(*) FROM visit_reports WHERE lead_id = @id AND deleted_at IS NULL) AS visit_report_count,
+			(SELECT MAX(timestamp) FROM activities WHERE lead_id = @id AND deleted_at IS NULL AND timestamp <= NOW()) AS last_activity_at,
+			(SELECT MAX(COALESCE(check_in_time, visit_date::timestamp)) FROM visit_reports
+				WHERE lead_id = @id AND deleted_at IS NULL AND COALESCE(check_in_time, visit_date::timestamp) <= NOW()) AS last_visit_at`,
+		map[string]interface{}{"id": id},
+	).Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+
+	engagement := &lead_scoring.Engagement{
+		ActivityCount:    row.ActivityCount,
+		VisitReportCount: row.VisitReportCount,
+		LastTouchAt:      row.LastActivityAt,
+	}
+	if row.LastVisitAt != nil && (engagement.LastTouchAt == nil || row.LastVisitAt.After(*engagement.LastTouchAt)) {
+		engagement.LastTouchAt = row.LastVisitAt
+	}
+	return engagement, nil
+}
 
+func (r *repository) UpdateScore(id string, score int, breakdown datatypes.JSON, scoredAt time.Time) error {
+	return r.db.Model(&lead.Lead{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"lead_score":      score,
+		"score_breakdown": breakdown,
+		"scored_at":       scoredAt,
+	}).Error
+}
 
+func (r *repository) CountOpenByAssignee(userIDs []string) (map[string]int64, error) {
+	var rows []struct {
+		AssignedTo string
+		Count      int64
+	}
+	err := r.db.Model(&lead.Lead{}).
+		Select("assigned_to, COUNT(*) AS count").
+		Where("assigned_to IN ?", userIDs).
+		Where("lead_status NOT IN ?", lead.ClosedStatuses).
+		Group("assigned_to").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
 
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.AssignedTo] = row.Count
+	}
+	return counts, nil
+}
 
+func (r *repository) Assign(id string, userID string) error {
+	return r.db.Model(&lead.Lead{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"assigned_to": userID,
+		"updated_at":  time.Now(),
+	}).Error
+}
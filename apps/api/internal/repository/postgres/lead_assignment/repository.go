@@ -0,0 +1,73 @@
+package lead_assignment
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_assignment"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new lead assignment rule repository
+func NewRepository(db *gorm.DB) interfaces.LeadAssignmentRuleRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindByID(id string) (*lead_assignment.AssignmentRule, error) {
+	var rule lead_assignment.AssignmentRule
+	if err := r.db.Where("id = ?", id).First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *repository) List() ([]lead_assignment.AssignmentRule, error) {
+	var rules []lead_assignment.AssignmentRule
+	err := r.db.Order("priority ASC, created_at ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *repository) ListActive() ([]lead_assignment.AssignmentRule, error) {
+	var rules []lead_assignment.AssignmentRule
+	err := r.db.Where("is_active = ?", true).Order("priority ASC, created_at ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *repository) Create(rule *lead_assignment.AssignmentRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *repository) Update(rule *lead_assignment.AssignmentRule) error {
+	// Save writes zero values and nil pointers too, so match fields can be cleared
+	return r.db.Save(rule).Error
+}
+
+func (r *repository) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&lead_assignment.AssignmentRule{}).Error
+}
+
+func (r *repository) AdvanceRoundRobin(ruleID string, memberIDs []string) (string, error) {
+	var next string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var rule lead_assignment.AssignmentRule
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", ruleID).First(&rule).Error; err != nil {
+			return err
+		}
+
+		last := ""
+		if rule.LastAssignedUserID != nil {
+			last = *rule.LastAssignedUserID
+		}
+		next = lead_assignment.NextRoundRobin(memberIDs, last)
+		if next == "" {
+			return nil
+		}
+
+		return tx.Model(&lead_assignment.AssignmentRule{}).Where("id = ?", ruleID).
+			UpdateColumn("last_assigned_user_id", next).Error
+	})
+	return next, err
+}
@@ -0,0 +1,49 @@
+package lead_scoring
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_scoring"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new lead scoring rule repository
+func NewRepository(db *gorm.DB) interfaces.LeadScoringRuleRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindByID(id string) (*lead_scoring.ScoringRule, error) {
+	var rule lead_scoring.ScoringRule
+	if err := r.db.Where("id = ?", id).First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *repository) List() ([]lead_scoring.ScoringRule, error) {
+	var rules []lead_scoring.ScoringRule
+	err := r.db.Order("created_at ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *repository) ListActive() ([]lead_scoring.ScoringRule, error) {
+	var rules []lead_scoring.ScoringRule
+	err := r.db.Where("is_active = ?", true).Order("created_at ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *repository) Create(rule *lead_scoring.ScoringRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *repository) Update(rule *lead_scoring.ScoringRule) error {
+	// Save writes zero values too, so rules can be deactivated or set to zero points
+	return r.db.Save(rule).Error
+}
+
+func (r *repository) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&lead_scoring.ScoringRule{}).Error
+}
@@ -0,0 +1,59 @@
+package loss_reason
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/loss_reason"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new loss reason repository
+func NewRepository(db *gorm.DB) interfaces.LossReasonRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindByID(id string) (*loss_reason.LossReason, error) {
+	var lr loss_reason.LossReason
+	err := r.db.Where("id = ?", id).First(&lr).Error
+	if err != nil {
+		return nil, err
+	}
+	return &lr, nil
+}
+
+func (r *repository) FindByCode(code string) (*loss_reason.LossReason, error) {
+	var lr loss_reason.LossReason
+	err := r.db.Where("code = ?", code).First(&lr).Error
+	if err != nil {
+		return nil, err
+	}
+	return &lr, nil
+}
+
+func (r *repository) List(req *loss_reason.ListLossReasonsRequest) ([]loss_reason.LossReason, error) {
+	var reasons []loss_reason.LossReason
+	query := r.db.Model(&loss_reason.LossReason{})
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	err := query.Order("name ASC").Find(&reasons).Error
+	if err != nil {
+		return nil, err
+	}
+	return reasons, nil
+}
+
+func (r *repository) Create(lr *loss_reason.LossReason) error {
+	return r.db.Create(lr).Error
+}
+
+func (r *repository) Update(lr *loss_reason.LossReason) error {
+	return r.db.Save(lr).Error
+}
+
+func (r *repository) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&loss_reason.LossReason{}).Error
+}
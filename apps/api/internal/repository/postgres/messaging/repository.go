@@ -0,0 +1,127 @@
+package messaging
+
+import (
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/messaging"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new messaging repository
+func NewRepository(db *gorm.DB) interfaces.MessagingRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindPreference(userID string) (*messaging.Preference, error) {
+	var p messaging.Preference
+	err := r.db.Where("user_id = ?", userID).First(&p).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *repository) SavePreference(p *messaging.Preference) error {
+	return r.db.Save(p).Error
+}
+
+func (r *repository) FindMessageByID(id string) (*messaging.OutboundMessage, error) {
+	var m messaging.OutboundMessage
+	err := r.db.Where("id = ?", id).First(&m).Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *repository) FindMessageByProviderID(channel string, providerMessageID string) (*messaging.OutboundMessage, error) {
+	var m messaging.OutboundMessage
+	err := r.db.Where("channel = ? AND provider_message_id = ?", channel, providerMessageID).First(&m).Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *repository) ListMessages(req *messaging.ListOutboundMessagesRequest) ([]messaging.OutboundMessage, int64, error) {
+	var messages []messaging.OutboundMessage
+	var total int64
+
+	query := r.db.Model(&messaging.OutboundMessage{})
+
+	if req.Channel != "" {
+		query = query.Where("channel = ?", req.Channel)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.Template != "" {
+		query = query.Where("template = ?", req.Template)
+	}
+	if req.UserID != "" {
+		query = query.Where("user_id = ?", req.UserID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	err := query.
+		Order("created_at DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&messages).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
+
+func (r *repository) CreateMessage(m *messaging.OutboundMessage) error {
+	return r.db.Create(m).Error
+}
+
+func (r *repository) UpdateMessage(m *messaging.OutboundMessage) error {
+	return r.db.Save(m).Error
+}
+
+func (r *repository) FindDueMessages(channels []string, now time.Time, limit int) ([]messaging.OutboundMessage, error) {
+	var messages []messaging.OutboundMessage
+	err := r.db.
+		Where("status = ? AND channel IN ? AND next_attempt_at <= ?", messaging.StatusPending, channels, now.UTC()).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+func (r *repository) ClaimAttempt(id string, attempts int, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&messaging.OutboundMessage{}).
+		Where("id = ? AND status = ? AND attempts = ?", id, messaging.StatusPending, attempts).
+		UpdateColumns(map[string]interface{}{
+			"attempts":        attempts + 1,
+			"next_attempt_at": leaseUntil.UTC(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -65,6 +65,28 @@ func (r *repository) GetByRoleID(roleID string) ([]permission.Permission, error)
 	return permissions, nil
 }
 
+func (r *repository) GetCodesByRoleCode(roleCode string) ([]string, error) {
+	var codes []string
+
+	// Admin has ALL permissions, consistent with GetUserPermissions
+	if roleCode == "admin" {
+		if err := r.db.Model(&permission.Permission{}).Pluck("code", &codes).Error; err != nil {
+			return nil, err
+		}
+		return codes, nil
+	}
+
+	err := r.db.Model(&permission.Permission{}).
+		Joins("INNER JOIN role_permissions ON permissions.id = role_permissions.permission_id").
+		Joins("INNER JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.code = ? AND roles.status = ? AND roles.deleted_at IS NULL", roleCode, "active").
+		Pluck("permissions.code", &codes).Error
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
 func (r *repository) GetUserPermissions(userID string) (*permission.GetUserPermissionsResponse, error) {
 	// Get user's role
 	var roleID string
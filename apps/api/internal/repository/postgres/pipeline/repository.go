@@ -15,6 +15,86 @@ func NewRepository(db *gorm.DB) interfaces.PipelineRepository {
 	return &repository{db: db}
 }
 
+func (r *repository) FindPipelineByID(id string) (*pipeline.Pipeline, error) {
+	var p pipeline.Pipeline
+	err := r.db.
+		Preload("Stages", func(db *gorm.DB) *gorm.DB {
+			return db.Order("\"order\" ASC")
+		}).
+		Where("id = ?", id).
+		First(&p).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *repository) FindPipelineByCode(code string) (*pipeline.Pipeline, error) {
+	var p pipeline.Pipeline
+	err := r.db.Where("code = ?", code).First(&p).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *repository) FindDefaultPipeline() (*pipeline.Pipeline, error) {
+	var p pipeline.Pipeline
+	err := r.db.Where("is_default = ?", true).First(&p).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *repository) ListPipelines(req *pipeline.ListPipelinesRequest) ([]pipeline.Pipeline, error) {
+	var pipelines []pipeline.Pipeline
+
+	query := r.db.Model(&pipeline.Pipeline{})
+
+	if req.IsActive != nil {
+		query = query.Where("is_active = ?", *req.IsActive)
+	}
+	if req.IncludeStages {
+		query = query.Preload("Stages", func(db *gorm.DB) *gorm.DB {
+			return db.Order("\"order\" ASC")
+		})
+	}
+
+	err := query.Order("\"order\" ASC, name ASC").Find(&pipelines).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return pipelines, nil
+}
+
+func (r *repository) CreatePipeline(p *pipeline.Pipeline) error {
+	return r.db.Omit("Stages").Create(p).Error
+}
+
+func (r *repository) UpdatePipeline(p *pipeline.Pipeline) error {
+	return r.db.Omit("Stages").Save(p).Error
+}
+
+func (r *repository) SetDefaultPipeline(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&pipeline.Pipeline{}).Where("id <> ? AND is_default = ?", id, true).Update("is_default", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&pipeline.Pipeline{}).Where("id = ?", id).Update("is_default", true).Error
+	})
+}
+
+func (r *repository) DeletePipeline(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("pipeline_id = ?", id).Delete(&pipeline.PipelineStage{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&pipeline.Pipeline{}).Error
+	})
+}
+
 func (r *repository) FindStageByID(id string) (*pipeline.PipelineStage, error) {
 	var stage pipeline.PipelineStage
 	err := r.db.Where("id = ?", id).First(&stage).Error
@@ -24,9 +104,15 @@ func (r *repository) FindStageByID(id string) (*pipeline.PipelineStage, error) {
 	return &stage, nil
 }
 
-func (r *repository) FindStageByCode(code string) (*pipeline.PipelineStage, error) {
+func (r *repository) FindStageByCode(pipelineID string, code string) (*pipeline.PipelineStage, error) {
 	var stage pipeline.PipelineStage
-	err := r.db.Where("code = ?", code).First(&stage).Error
+	query := r.db.Where("code = ?", code)
+	if pipelineID != "" {
+		query = query.Where("pipeline_id = ?", pipelineID)
+	} else {
+		query = query.Where("pipeline_id = (?)", r.db.Model(&pipeline.Pipeline{}).Select("id").Where("is_default = ?", true).Limit(1))
+	}
+	err := query.First(&stage).Error
 	if err != nil {
 		return nil, err
 	}
@@ -39,6 +125,9 @@ func (r *repository) ListStages(req *pipeline.ListPipelineStagesRequest) ([]pipe
 	query := r.db.Model(&pipeline.PipelineStage{})
 
 	// Apply filters
+	if req.PipelineID != "" {
+		query = query.Where("pipeline_id = ?", req.PipelineID)
+	}
 	if req.IsActive != nil {
 		query = query.Where("is_active = ?", *req.IsActive)
 	}
@@ -29,6 +29,18 @@ func (r *repository) FindByID(id string) (*product.Product, error) {
 	return &p, nil
 }
 
+func (r *repository) FindBySKU(sku string) (*product.Product, error) {
+	var p product.Product
+	err := r.db.
+		Preload("Category").
+		Where("LOWER(sku) = ?", strings.ToLower(strings.TrimSpace(sku))).
+		First(&p).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
 func (r *repository) List(req *product.ListProductsRequest) ([]product.Product, int64, error) {
 	var products []product.Product
 	var total int64
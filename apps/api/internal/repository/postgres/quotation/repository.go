@@ -0,0 +1,210 @@
+package quotation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/quotation"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type repository struct {
+	db    *gorm.DB
+	scope *datascope.Scope
+}
+
+// NewRepository creates a new quotation repository
+func NewRepository(db *gorm.DB) interfaces.QuotationRepository {
+	return &repository{db: db}
+}
+
+// WithScope returns a repository whose quotations are limited to the deals visible within the given data scope
+func (r *repository) WithScope(scope *datascope.Scope) interfaces.QuotationRepository {
+	return &repository{db: r.db, scope: scope}
+}
+
+// scoped restricts quotations to those of deals within the data scope
+func (r *repository) scoped() *gorm.DB {
+	if r.scope.IsUnrestricted() {
+		return r.db.Model(&quotation.Quotation{})
+	}
+	return r.db.Model(&quotation.Quotation{}).
+		Joins("JOIN deals ON deals.id = quotations.deal_id").
+		Scopes(r.scope.Apply("deals.assigned_to"))
+}
+
+func (r *repository) FindByID(id string) (*quotation.Quotation, error) {
+	var q quotation.Quotation
+	err := r.scoped().
+		Preload("Revisions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("revision DESC")
+		}).
+		Preload("Revisions.Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order ASC")
+		}).
+		Where("quotations.id = ?", id).
+		First(&q).Error
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+func (r *repository) List(req *quotation.ListQuotationsRequest) ([]quotation.Quotation, int64, error) {
+	var quotations []quotation.Quotation
+	var total int64
+
+	query := r.scoped()
+
+	if req.Search != "" {
+		search := "%" + strings.ToLower(req.Search) + "%"
+		query = query.Where("LOWER(quotations.number) LIKE ? OR LOWER(quotations.title) LIKE ?", search, search)
+	}
+	if req.DealID != "" {
+		query = query.Where("quotations.deal_id = ?", req.DealID)
+	}
+	if req.AccountID != "" {
+		query = query.Where("quotations.account_id = ?", req.AccountID)
+	}
+	if req.Status != "" {
+		query = query.Where("quotations.status = ?", req.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page, perPage := pageOf(req.Page, req.PerPage)
+	err := query.
+		Preload("Revisions").
+		Order("quotations.created_at DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&quotations).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return quotations, total, nil
+}
+
+func (r *repository) Create(q *quotation.Quotation, prefix string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Serialize numbering so two quotations never get the same number
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('quotations'))").Error; err != nil {
+			return err
+		}
+
+		// Sequences are compared as numbers, as "10000" sorts before "9999" once a month passes four digits
+		var last int
+		err := tx.Unscoped().Model(&quotation.Quotation{}).
+			Where("number LIKE ? AND SUBSTRING(number FROM ?) ~ '^[0-9]+$'", prefix+"%", len(prefix)+1).
+			Select("COALESCE(MAX(CAST(SUBSTRING(number FROM ?) AS INTEGER)), 0)", len(prefix)+1).
+			Scan(&last).Error
+		if err != nil {
+			return err
+		}
+
+		q.Number = fmt.Sprintf("%s%04d", prefix, last+1)
+
+		return tx.Create(q).Error
+	})
+}
+
+func (r *repository) AddRevision(q *quotation.Quotation, rev *quotation.QuotationRevision, from ...string) (bool, error) {
+	added := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the quotation so concurrent revisions are numbered one after the other, and so the status
+		// cannot change between the check and the revision
+		var locked quotation.Quotation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", q.ID).First(&locked).Error; err != nil {
+			return err
+		}
+		if !hasStatus(locked.Status, from) {
+			return nil
+		}
+
+		var latest int
+		err := tx.Model(&quotation.QuotationRevision{}).
+			Where("quotation_id = ?", q.ID).
+			Select("COALESCE(MAX(revision), 0)").
+			Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+
+		rev.QuotationID = q.ID
+		rev.Revision = latest + 1
+		if err := tx.Create(rev).Error; err != nil {
+			return err
+		}
+		q.CurrentRevision = rev.Revision
+		err = tx.Model(&quotation.Quotation{}).
+			Where("id = ?", q.ID).
+			Updates(map[string]interface{}{
+				"current_revision": q.CurrentRevision,
+				"status":           q.Status,
+				"sent_at":          q.SentAt,
+			}).Error
+		if err != nil {
+			return err
+		}
+		added = true
+		return nil
+	})
+	return added, err
+}
+
+func (r *repository) SetRevisionFile(revisionID string, fileName string) (bool, error) {
+	result := r.db.Model(&quotation.QuotationRevision{}).
+		Where("id = ? AND (file_name IS NULL OR file_name = '')", revisionID).
+		Update("file_name", fileName)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) UpdateStatus(q *quotation.Quotation, from ...string) (bool, error) {
+	// Only the status columns are written, so a concurrent revision's current_revision is never overwritten
+	result := r.db.Model(&quotation.Quotation{}).
+		Where("id = ? AND status IN ?", q.ID, from).
+		Updates(map[string]interface{}{
+			"status":           q.Status,
+			"sent_at":          q.SentAt,
+			"accepted_at":      q.AcceptedAt,
+			"rejected_at":      q.RejectedAt,
+			"rejection_reason": q.RejectionReason,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// hasStatus reports whether status is one of statuses
+func hasStatus(status string, statuses []string) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// pageOf applies the default page size of 20 and the maximum of 100
+func pageOf(page int, perPage int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+	return page, perPage
+}
@@ -141,5 +141,14 @@ func (r *repository) MarkAsSent(id string, sentAt time.Time) error {
 		}).Error
 }
 
+func (r *repository) UpdateDeliveryStatus(id string, status string, deliveryError string) error {
+	return r.db.Model(&reminder.Reminder{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"delivery_status": status,
+			"delivery_error":  deliveryError,
+		}).Error
+}
+
 
 
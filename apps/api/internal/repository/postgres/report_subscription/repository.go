@@ -0,0 +1,142 @@
+package report_subscription
+
+import (
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/report_subscription"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new report subscription repository
+func NewRepository(db *gorm.DB) interfaces.ReportSubscriptionRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindByID(id string) (*report_subscription.ReportSubscription, error) {
+	var s report_subscription.ReportSubscription
+	err := r.db.Where("id = ?", id).First(&s).Error
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *repository) List(req *report_subscription.ListReportSubscriptionsRequest) ([]report_subscription.ReportSubscription, int64, error) {
+	var subscriptions []report_subscription.ReportSubscription
+	var total int64
+
+	query := r.db.Model(&report_subscription.ReportSubscription{})
+
+	if req.ReportType != "" {
+		query = query.Where("report_type = ?", req.ReportType)
+	}
+	if req.IsActive != nil {
+		query = query.Where("is_active = ?", *req.IsActive)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page, perPage := pageOf(req.Page, req.PerPage)
+	err := query.
+		Order("name ASC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return subscriptions, total, nil
+}
+
+func (r *repository) FindDue(now time.Time) ([]report_subscription.ReportSubscription, error) {
+	var subscriptions []report_subscription.ReportSubscription
+	err := r.db.
+		Where("is_active = ? AND next_run_at IS NOT NULL AND next_run_at <= ?", true, now.UTC()).
+		Order("next_run_at ASC").
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *repository) Create(s *report_subscription.ReportSubscription) error {
+	return r.db.Create(s).Error
+}
+
+func (r *repository) Update(s *report_subscription.ReportSubscription) error {
+	return r.db.Save(s).Error
+}
+
+func (r *repository) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&report_subscription.ReportSubscription{}).Error
+}
+
+func (r *repository) ClaimRun(id string, scheduledAt time.Time, nextRunAt *time.Time) (bool, error) {
+	result := r.db.Model(&report_subscription.ReportSubscription{}).
+		Where("id = ? AND next_run_at = ?", id, scheduledAt.UTC()).
+		Update("next_run_at", nextRunAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) RecordDelivery(d *report_subscription.ReportDelivery) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(d).Error; err != nil {
+			return err
+		}
+		return tx.Model(&report_subscription.ReportSubscription{}).
+			Where("id = ?", d.SubscriptionID).
+			UpdateColumns(map[string]interface{}{
+				"last_run_at": d.CreatedAt,
+				"last_status": d.Status,
+			}).Error
+	})
+}
+
+func (r *repository) ListDeliveries(subscriptionID string, req *report_subscription.ListReportDeliveriesRequest) ([]report_subscription.ReportDelivery, int64, error) {
+	var deliveries []report_subscription.ReportDelivery
+	var total int64
+
+	query := r.db.Model(&report_subscription.ReportDelivery{}).Where("subscription_id = ?", subscriptionID)
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page, perPage := pageOf(req.Page, req.PerPage)
+	err := query.
+		Order("created_at DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+// pageOf applies the default page size of 20 and the maximum of 100
+func pageOf(page int, perPage int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+	return page, perPage
+}
@@ -0,0 +1,173 @@
+package sales_target
+
+import (
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/sales_target"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+const dateFormat = "2006-01-02"
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new sales target repository
+func NewRepository(db *gorm.DB) interfaces.SalesTargetRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindByID(id string) (*sales_target.SalesTarget, error) {
+	var t sales_target.SalesTarget
+	err := r.db.Where("id = ?", id).First(&t).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *repository) FindByOwnerAndPeriod(level string, ownerID string, periodType string, periodStart time.Time) (*sales_target.SalesTarget, error) {
+	query := r.db.Where("level = ? AND period_type = ? AND period_start = ?", level, periodType, periodStart.Format(dateFormat))
+	switch level {
+	case sales_target.LevelUser:
+		query = query.Where("user_id = ?", ownerID)
+	case sales_target.LevelTeam:
+		query = query.Where("team_id = ?", ownerID)
+	}
+
+	var t sales_target.SalesTarget
+	if err := query.First(&t).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *repository) List(req *sales_target.ListSalesTargetsRequest) ([]sales_target.SalesTarget, int64, error) {
+	var targets []sales_target.SalesTarget
+	var total int64
+
+	query := r.db.Model(&sales_target.SalesTarget{})
+
+	if req.Level != "" {
+		query = query.Where("level = ?", req.Level)
+	}
+	if req.UserID != "" {
+		query = query.Where("user_id = ?", req.UserID)
+	}
+	if req.TeamID != "" {
+		query = query.Where("team_id = ?", req.TeamID)
+	}
+	if req.PeriodType != "" {
+		query = query.Where("period_type = ?", req.PeriodType)
+	}
+	if req.Year != 0 {
+		query = query.Where("EXTRACT(YEAR FROM period_start) = ?", req.Year)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	err := query.
+		Order("period_start DESC, level ASC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&targets).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return targets, total, nil
+}
+
+func (r *repository) ListOverlapping(from time.Time, to time.Time) ([]sales_target.SalesTarget, error) {
+	var targets []sales_target.SalesTarget
+	err := r.db.
+		Where("period_start <= ? AND period_end >= ?", to.Format(dateFormat), from.Format(dateFormat)).
+		Order("period_start ASC").
+		Find(&targets).Error
+	return targets, err
+}
+
+func (r *repository) Create(t *sales_target.SalesTarget) error {
+	err := r.db.Create(t).Error
+	// Report a concurrent target for the same owner and period as gorm.ErrDuplicatedKey
+	if translator, ok := r.db.Dialector.(gorm.ErrorTranslator); ok && err != nil {
+		return translator.Translate(err)
+	}
+	return err
+}
+
+func (r *repository) Update(t *sales_target.SalesTarget) error {
+	return r.db.Save(t).Error
+}
+
+func (r *repository) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&sales_target.SalesTarget{}).Error
+}
+
+func (r *repository) AchievementByUser(from time.Time, to time.Time) ([]sales_target.UserAchievement, error) {
+	fromDate, toDate := from.Format(dateFormat), to.Format(dateFormat)
+	byUser := make(map[string]*sales_target.UserAchievement)
+	achievementOf := func(userID string) *sales_target.UserAchievement {
+		if byUser[userID] == nil {
+			byUser[userID] = &sales_target.UserAchievement{UserID: userID}
+		}
+		return byUser[userID]
+	}
+
+	// Revenue counts won deals on the day they closed
+	var revenues []struct {
+		UserID  string
+		Revenue int64
+	}
+	err := r.db.Table("deals").
+		Select("COALESCE(assigned_to::text, '') AS user_id, COALESCE(SUM(value), 0) AS revenue").
+		Where("deleted_at IS NULL AND status = ?", "won").
+		Where("COALESCE(actual_close_date, updated_at::date) BETWEEN ? AND ?", fromDate, toDate).
+		Group("assigned_to").
+		Scan(&revenues).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, rev := range revenues {
+		achievementOf(rev.UserID).Revenue += rev.Revenue
+	}
+
+	var visits []struct {
+		UserID string
+		Visits int
+	}
+	err = r.db.Table("visit_reports").
+		Select("sales_rep_id::text AS user_id, COUNT(*) AS visits").
+		Where("deleted_at IS NULL AND status = ?", "approved").
+		Where("visit_date BETWEEN ? AND ?", fromDate, toDate).
+		Group("sales_rep_id").
+		Scan(&visits).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range visits {
+		achievementOf(v.UserID).Visits += v.Visits
+	}
+
+	results := make([]sales_target.UserAchievement, 0, len(byUser))
+	for _, a := range byUser {
+		results = append(results, *a)
+	}
+	return results, nil
+}
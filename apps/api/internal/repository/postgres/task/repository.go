@@ -3,13 +3,17 @@ package task
 import (
 	"strings"
 
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/reminder"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/task"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"gorm.io/gorm"
 )
 
 type repository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	scope *datascope.Scope
 }
 
 // NewRepository creates a new task repository
@@ -17,9 +21,15 @@ func NewRepository(db *gorm.DB) interfaces.TaskRepository {
 	return &repository{db: db}
 }
 
+// WithScope returns a repository restricted to the tasks visible within the given data scope
+func (r *repository) WithScope(scope *datascope.Scope) interfaces.TaskRepository {
+	return &repository{db: r.db, scope: scope}
+}
+
 func (r *repository) FindByID(id string) (*task.Task, error) {
 	var t task.Task
 	err := r.db.
+		Scopes(r.scope.Apply("tasks.assigned_to")).
 		Preload("AssignedUser").
 		Preload("AssignedFromUser").
 		Preload("Account").
@@ -37,7 +47,49 @@ func (r *repository) List(req *task.ListTasksRequest) ([]task.Task, int64, error
 	var tasks []task.Task
 	var total int64
 
-	query := r.db.Model(&task.Task{})
+	query := r.filter(req)
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	offset := (page - 1) * perPage
+
+	// Fetch data with preload
+	err := query.
+		Preload("AssignedUser").
+		Preload("AssignedFromUser").
+		Preload("Account").
+		Preload("Contact").
+		Preload("Deal").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&tasks).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tasks, total, nil
+}
+
+// filter builds the query of tasks matching the list filters
+func (r *repository) filter(req *task.ListTasksRequest) *gorm.DB {
+	query := r.db.Model(&task.Task{}).Scopes(r.scope.Apply("tasks.assigned_to"))
 
 	// Apply filters
 	if req.Search != "" {
@@ -84,42 +136,20 @@ func (r *repository) List(req *task.ListTasksRequest) ([]task.Task, int64, error
 		query = query.Where("due_date <= ?", *req.DueDateTo)
 	}
 
-	// Count total
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	// Apply pagination
-	page := req.Page
-	if page < 1 {
-		page = 1
-	}
-	perPage := req.PerPage
-	if perPage < 1 {
-		perPage = 20
-	}
-	if perPage > 100 {
-		perPage = 100
-	}
-
-	offset := (page - 1) * perPage
+	return query
+}
 
-	// Fetch data with preload
-	err := query.
+func (r *repository) FindInBatches(req *task.ListTasksRequest, fn func([]task.Task) error) error {
+	var batch []task.Task
+	return r.filter(req).
 		Preload("AssignedUser").
 		Preload("AssignedFromUser").
 		Preload("Account").
 		Preload("Contact").
 		Preload("Deal").
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(perPage).
-		Find(&tasks).Error
-	if err != nil {
-		return nil, 0, err
-	}
-
-	return tasks, total, nil
+		FindInBatches(&batch, export.BatchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
 }
 
 func (r *repository) Create(t *task.Task) error {
@@ -127,6 +157,10 @@ func (r *repository) Create(t *task.Task) error {
 }
 
 func (r *repository) Update(t *task.Task) error {
+	if err := r.scope.Authorize(r.db, &task.Task{}, "tasks.assigned_to", t.ID); err != nil {
+		return err
+	}
+
 	// Clear relations to avoid updating them
 	t.AssignedUser = nil
 	t.AssignedFromUser = nil
@@ -138,6 +172,44 @@ func (r *repository) Update(t *task.Task) error {
 }
 
 func (r *repository) Delete(id string) error {
+	if err := r.scope.Authorize(r.db, &task.Task{}, "tasks.assigned_to", id); err != nil {
+		return err
+	}
 	return r.db.Where("id = ?", id).Delete(&task.Task{}).Error
 }
 
+
+func (r *repository) FindByActionItemIDs(ids []string) ([]task.Task, error) {
+	var tasks []task.Task
+	if len(ids) == 0 {
+		return tasks, nil
+	}
+	// Include deleted tasks, since their action items keep the unique ID
+	err := r.db.Unscoped().
+		Preload("AssignedUser").
+		Preload("AssignedFromUser").
+		Preload("Account").
+		Preload("Contact").
+		Preload("Deal").
+		Where("action_item_id IN ?", ids).
+		Order("created_at ASC").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *repository) CreateWithReminders(tasks []*task.Task, reminders []*reminder.Reminder) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, t := range tasks {
+			if err := tx.Create(t).Error; err != nil {
+				return err
+			}
+			if i < len(reminders) && reminders[i] != nil {
+				reminders[i].TaskID = t.ID
+				if err := tx.Create(reminders[i]).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,68 @@
+package team
+
+import (
+	"github.com/gilabs/crm-healthcare/api/internal/domain/team"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/user"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new team repository
+func NewRepository(db *gorm.DB) interfaces.TeamRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindByID(id string) (*team.Team, error) {
+	var t team.Team
+	err := r.db.Where("id = ?", id).First(&t).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *repository) FindByName(name string) (*team.Team, error) {
+	var t team.Team
+	err := r.db.Where("name = ?", name).First(&t).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *repository) List() ([]team.Team, error) {
+	var teams []team.Team
+	err := r.db.Order("name ASC").Find(&teams).Error
+	if err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+func (r *repository) CountMembers(teamID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&user.User{}).Where("team_id = ?", teamID).Count(&count).Error
+	return count, err
+}
+
+func (r *repository) Create(t *team.Team) error {
+	return r.db.Create(t).Error
+}
+
+func (r *repository) Update(t *team.Team) error {
+	return r.db.Save(t).Error
+}
+
+func (r *repository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Detach members so they fall back to their own records
+		if err := tx.Model(&user.User{}).Where("team_id = ?", id).Update("team_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&team.Team{}).Error
+	})
+}
@@ -59,6 +59,10 @@ func (r *repository) List(req *user.ListUsersRequest) ([]user.User, int64, error
 		query = query.Where("users.role_id = ?", req.RoleID)
 	}
 
+	if req.TeamID != "" {
+		query = query.Where("users.team_id = ?", req.TeamID)
+	}
+
 	// Count total
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -88,6 +92,15 @@ func (r *repository) List(req *user.ListUsersRequest) ([]user.User, int64, error
 	return users, total, nil
 }
 
+func (r *repository) ListIDsByTeam(teamID string) ([]string, error) {
+	var ids []string
+	err := r.db.Model(&user.User{}).Where("team_id = ?", teamID).Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
 func (r *repository) Create(u *user.User) error {
 	return r.db.Create(u).Error
 }
@@ -4,13 +4,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/visit_report"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
 	"gorm.io/gorm"
 )
 
 type repository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	scope *datascope.Scope
 }
 
 // NewRepository creates a new visit report repository
@@ -18,9 +20,14 @@ func NewRepository(db *gorm.DB) interfaces.VisitReportRepository {
 	return &repository{db: db}
 }
 
+// WithScope returns a repository restricted to the visit reports visible within the given data scope
+func (r *repository) WithScope(scope *datascope.Scope) interfaces.VisitReportRepository {
+	return &repository{db: r.db, scope: scope}
+}
+
 func (r *repository) FindByID(id string) (*visit_report.VisitReport, error) {
 	var vr visit_report.VisitReport
-	err := r.db.Where("id = ?", id).First(&vr).Error
+	err := r.db.Scopes(r.scope.Apply("visit_reports.sales_rep_id")).Where("id = ?", id).First(&vr).Error
 	if err != nil {
 		return nil, err
 	}
@@ -31,7 +38,7 @@ func (r *repository) List(req *visit_report.ListVisitReportsRequest) ([]visit_re
 	var visitReports []visit_report.VisitReport
 	var total int64
 
-	query := r.db.Model(&visit_report.VisitReport{})
+	query := r.db.Model(&visit_report.VisitReport{}).Scopes(r.scope.Apply("visit_reports.sales_rep_id"))
 
 	// Apply filters
 	if req.Search != "" {
@@ -116,10 +123,16 @@ func (r *repository) Create(vr *visit_report.VisitReport) error {
 }
 
 func (r *repository) Update(vr *visit_report.VisitReport) error {
+	if err := r.scope.Authorize(r.db, &visit_report.VisitReport{}, "visit_reports.sales_rep_id", vr.ID); err != nil {
+		return err
+	}
 	return r.db.Save(vr).Error
 }
 
 func (r *repository) Delete(id string) error {
+	if err := r.scope.Authorize(r.db, &visit_report.VisitReport{}, "visit_reports.sales_rep_id", id); err != nil {
+		return err
+	}
 	return r.db.Where("id = ?", id).Delete(&visit_report.VisitReport{}).Error
 }
 
@@ -3,8 +3,11 @@ package account
 import (
 	"errors"
 
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/duplicate"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"gorm.io/gorm"
 )
 
@@ -13,18 +16,37 @@ var (
 	ErrCategoryNotFound  = errors.New("category not found")
 )
 
+// DuplicateCheckerInterface defines interface for finding existing accounts a new account may duplicate
+type DuplicateCheckerInterface interface {
+	FindAccountMatches(record duplicate.Record) ([]duplicate.Match, error)
+}
+
 type Service struct {
-	accountRepo  interfaces.AccountRepository
-	categoryRepo interfaces.CategoryRepository
+	accountRepo      interfaces.AccountRepository
+	categoryRepo     interfaces.CategoryRepository
+	duplicateChecker DuplicateCheckerInterface
 }
 
 func NewService(accountRepo interfaces.AccountRepository, categoryRepo interfaces.CategoryRepository) *Service {
 	return &Service{
-		accountRepo:  accountRepo,
-		categoryRepo: categoryRepo,
+		accountRepo:      accountRepo,
+		categoryRepo:     categoryRepo,
+		duplicateChecker: nil, // Will be set via SetDuplicateChecker if needed
 	}
 }
 
+// SetDuplicateChecker sets the checker used to stop accounts from being created twice
+func (s *Service) SetDuplicateChecker(checker DuplicateCheckerInterface) {
+	s.duplicateChecker = checker
+}
+
+// WithScope returns a copy of the service whose accounts are limited to the given data scope
+func (s *Service) WithScope(scope *datascope.Scope) *Service {
+	scoped := *s
+	scoped.accountRepo = s.accountRepo.WithScope(scope)
+	return &scoped
+}
+
 // PaginationResult represents pagination information
 type PaginationResult struct {
 	Page       int
@@ -67,6 +89,18 @@ func (s *Service) List(req *account.ListAccountsRequest) ([]account.AccountRespo
 	return responses, pagination, nil
 }
 
+// Export writes every account matching the list filters to w; rows are flushed to the client after each batch
+func (s *Service) Export(req *account.ListAccountsRequest, w export.Writer) error {
+	return s.accountRepo.FindInBatches(req, func(accounts []account.Account) error {
+		for i := range accounts {
+			if err := w.WriteRow(accounts[i].ExportRow()); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	})
+}
+
 // GetByID returns an account by ID
 func (s *Service) GetByID(id string) (*account.AccountResponse, error) {
 	a, err := s.accountRepo.FindByID(id)
@@ -112,6 +146,16 @@ func (s *Service) Create(req *account.CreateAccountRequest) (*account.AccountRes
 		a.Status = "active"
 	}
 
+	if s.duplicateChecker != nil && !req.IgnoreDuplicates {
+		matches, err := s.duplicateChecker.FindAccountMatches(a.DuplicateRecord())
+		if err != nil {
+			return nil, err
+		}
+		if err := duplicate.Found(duplicate.EntityAccount, matches); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.accountRepo.Create(a); err != nil {
 		return nil, err
 	}
@@ -3,10 +3,12 @@ package activity
 import (
 	"encoding/json"
 	"errors"
+	"log"
 	"time"
 
 	"github.com/gilabs/crm-healthcare/api/internal/domain/activity"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
@@ -15,12 +17,18 @@ var (
 	ErrActivityNotFound = errors.New("activity not found")
 )
 
+// LeadScorerInterface defines interface for rescoring a lead after its activities change
+type LeadScorerInterface interface {
+	RecomputeScore(leadID string) error
+}
+
 type Service struct {
 	activityRepo     interfaces.ActivityRepository
 	activityTypeRepo interfaces.ActivityTypeRepository
 	accountRepo      interfaces.AccountRepository
 	contactRepo      interfaces.ContactRepository
 	userRepo         interfaces.UserRepository
+	leadScorer       LeadScorerInterface
 }
 
 func NewService(activityRepo interfaces.ActivityRepository, activityTypeRepo interfaces.ActivityTypeRepository, accountRepo interfaces.AccountRepository, contactRepo interfaces.ContactRepository, userRepo interfaces.UserRepository) *Service {
@@ -33,6 +41,21 @@ func NewService(activityRepo interfaces.ActivityRepository, activityTypeRepo int
 	}
 }
 
+// SetLeadScorer sets the lead scorer used to rescore linked leads
+func (s *Service) SetLeadScorer(scorer LeadScorerInterface) {
+	s.leadScorer = scorer
+}
+
+// rescoreLead rescores a linked lead; failures are logged only, since the activity itself was saved
+func (s *Service) rescoreLead(leadID *string) {
+	if s.leadScorer == nil || leadID == nil || *leadID == "" {
+		return
+	}
+	if err := s.leadScorer.RecomputeScore(*leadID); err != nil {
+		log.Printf("Failed to recompute score of lead %s: %v", *leadID, err)
+	}
+}
+
 // PaginationResult represents pagination information
 type PaginationResult struct {
 	Page       int
@@ -114,6 +137,18 @@ func (s *Service) List(req *activity.ListActivitiesRequest) ([]activity.Activity
 	return responses, pagination, nil
 }
 
+// Export writes every activity matching the list filters to w; rows are flushed to the client after each batch
+func (s *Service) Export(req *activity.ListActivitiesRequest, w export.Writer) error {
+	return s.activityRepo.FindInBatches(req, func(activities []activity.Activity) error {
+		for i := range activities {
+			if err := w.WriteRow(activities[i].ExportRow()); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	})
+}
+
 // GetByID returns an activity by ID
 func (s *Service) GetByID(id string) (*activity.ActivityResponse, error) {
 	a, err := s.activityRepo.FindByID(id)
@@ -215,6 +250,7 @@ func (s *Service) Create(req *activity.CreateActivityRequest) (*activity.Activit
 	if err := s.activityRepo.Create(a); err != nil {
 		return nil, err
 	}
+	s.rescoreLead(a.LeadID)
 
 	// Reload
 	createdActivity, err := s.activityRepo.FindByID(a.ID)
@@ -0,0 +1,47 @@
+package ai
+
+import (
+	"github.com/gilabs/crm-healthcare/api/pkg/llm"
+)
+
+// maxToolRounds caps how many times the model may call tools before it has to answer
+const maxToolRounds = 5
+
+// runToolLoop sends the chat request and runs the tools the model calls until it answers.
+// send performs a single completion; the returned response carries the token usage of all rounds.
+// On error the returned response carries the tokens used before the failure, so they can still be counted.
+func (s *Service) runToolLoop(request *llm.ChatRequest, userID string, send func(req *llm.ChatRequest) (*llm.ChatResponse, error)) (*llm.ChatResponse, error) {
+	totalTokens := 0
+	for round := 0; ; round++ {
+		if round == maxToolRounds {
+			request.Tools = nil // Answer from the data gathered so far
+		}
+
+		response, err := send(request)
+		if err != nil {
+			if response != nil {
+				totalTokens += response.Tokens
+			}
+			return &llm.ChatResponse{Tokens: totalTokens}, err
+		}
+		totalTokens += response.Tokens
+
+		if len(response.Message.ToolCalls) == 0 || len(request.Tools) == 0 {
+			response.Tokens = totalTokens
+			return response, nil
+		}
+
+		request.Messages = append(request.Messages, llm.Message{
+			Role:      "assistant",
+			Content:   response.Message.Content,
+			ToolCalls: response.Message.ToolCalls,
+		})
+		for _, call := range response.Message.ToolCalls {
+			request.Messages = append(request.Messages, llm.Message{
+				Role:       "tool",
+				Content:    s.runChatTool(call, userID),
+				ToolCallID: call.ID,
+			})
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_settings"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/llm"
+)
+
+func TestRunToolLoopRunsToolsUntilAnswer(t *testing.T) {
+	service, _ := newInsightTestService(t, "", nil)
+
+	var requests []llm.ChatRequest
+	send := func(req *llm.ChatRequest) (*llm.ChatResponse, error) {
+		requests = append(requests, *req)
+		if len(requests) == 1 {
+			return &llm.ChatResponse{
+				Message: llm.Message{Role: "assistant", ToolCalls: []llm.ToolCall{
+					{ID: "call-1", Name: "search_accounts", Arguments: `{"query":"jakarta"}`},
+					{ID: "call-2", Name: "delete_everything", Arguments: `{}`},
+				}},
+				Tokens: 10,
+			}, nil
+		}
+		return &llm.ChatResponse{Message: llm.Message{Role: "assistant", Content: "RSUD Jakarta is active."}, Tokens: 5}, nil
+	}
+
+	request := &llm.ChatRequest{
+		Messages: []llm.Message{{Role: "user", Content: "Which accounts are in Jakarta?"}},
+		Tools:    chatToolDefinitions(),
+	}
+	response, err := service.runToolLoop(request, "user-1", send)
+	if err != nil {
+		t.Fatalf("runToolLoop returned error: %v", err)
+	}
+
+	if response.Message.Content != "RSUD Jakarta is active." {
+		t.Errorf("unexpected answer: %q", response.Message.Content)
+	}
+	if response.Tokens != 15 {
+		t.Errorf("expected tokens of both rounds (15), got %d", response.Tokens)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 model calls, got %d", len(requests))
+	}
+
+	messages := requests[1].Messages
+	if len(messages) != 4 {
+		t.Fatalf("expected user, assistant and two tool messages, got %d", len(messages))
+	}
+	if messages[2].ToolCallID != "call-1" || !strings.Contains(messages[2].Content, "RSUD Jakarta") {
+		t.Errorf("expected account search result, got %+v", messages[2])
+	}
+	if messages[3].ToolCallID != "call-2" || !strings.Contains(messages[3].Content, `"error"`) {
+		t.Errorf("expected error for unknown tool, got %+v", messages[3])
+	}
+}
+
+func TestRunChatToolRespectsDataPrivacy(t *testing.T) {
+	service, _ := newInsightTestService(t, "", &ai_settings.DataPrivacySettings{AllowDeals: true})
+
+	result := service.runChatTool(llm.ToolCall{ID: "call-1", Name: "search_accounts", Arguments: `{"query":"jakarta"}`}, "user-1")
+	if !strings.Contains(result, `"error"`) || strings.Contains(result, "RSUD Jakarta") {
+		t.Errorf("expected account data to be denied, got %s", result)
+	}
+}
+
+func TestRunToolLoopStopsCallingToolsAfterMaxRounds(t *testing.T) {
+	service, _ := newInsightTestService(t, "", nil)
+
+	calls := 0
+	send := func(req *llm.ChatRequest) (*llm.ChatResponse, error) {
+		calls++
+		if len(req.Tools) == 0 {
+			return &llm.ChatResponse{Message: llm.Message{Role: "assistant", Content: "done"}}, nil
+		}
+		return &llm.ChatResponse{Message: llm.Message{Role: "assistant", ToolCalls: []llm.ToolCall{
+			{ID: "call", Name: "get_data_access_settings"},
+		}}}, nil
+	}
+
+	request := &llm.ChatRequest{Messages: []llm.Message{{Role: "user", Content: "loop"}}, Tools: chatToolDefinitions()}
+	response, err := service.runToolLoop(request, "user-1", send)
+	if err != nil {
+		t.Fatalf("runToolLoop returned error: %v", err)
+	}
+	if response.Message.Content != "done" || calls != maxToolRounds+1 {
+		t.Errorf("expected final answer after %d calls, got %q after %d", maxToolRounds+1, response.Message.Content, calls)
+	}
+}
+
+type fakePipelineRepo struct {
+	interfaces.PipelineRepository
+	pipelines []pipeline.Pipeline
+	stages    []pipeline.PipelineStage
+}
+
+func (r *fakePipelineRepo) ListPipelines(req *pipeline.ListPipelinesRequest) ([]pipeline.Pipeline, error) {
+	return r.pipelines, nil
+}
+
+func (r *fakePipelineRepo) ListStages(req *pipeline.ListPipelineStagesRequest) ([]pipeline.PipelineStage, error) {
+	var stages []pipeline.PipelineStage
+	for _, stage := range r.stages {
+		if req.PipelineID == "" || stage.PipelineID == req.PipelineID {
+			stages = append(stages, stage)
+		}
+	}
+	return stages, nil
+}
+
+func TestFindStageWithSeveralPipelines(t *testing.T) {
+	service, _ := newInsightTestService(t, "", nil)
+	service.pipelineRepo = &fakePipelineRepo{
+		pipelines: []pipeline.Pipeline{
+			{ID: "pipeline-sales", Name: "Sales", Code: "sales", IsDefault: true},
+			{ID: "pipeline-tender", Name: "Hospital Tender", Code: "tender"},
+		},
+		stages: []pipeline.PipelineStage{
+			{ID: "sales-proposal", PipelineID: "pipeline-sales", Code: "proposal", Name: "Proposal"},
+			{ID: "sales-negotiation", PipelineID: "pipeline-sales", Code: "negotiation", Name: "Negotiation"},
+			{ID: "tender-proposal", PipelineID: "pipeline-tender", Code: "proposal", Name: "Proposal"},
+		},
+	}
+
+	if _, err := service.findStage("proposal", ""); err == nil || !strings.Contains(err.Error(), "several pipelines") {
+		t.Errorf("expected an ambiguous stage error, got %v", err)
+	}
+
+	stage, err := service.findStage("Proposal", "Hospital Tender")
+	if err != nil || stage.ID != "tender-proposal" {
+		t.Errorf("expected the tender pipeline's proposal stage, got %+v, %v", stage, err)
+	}
+
+	stage, err = service.findStage("negotiation", "")
+	if err != nil || stage.ID != "sales-negotiation" {
+		t.Errorf("expected the only negotiation stage, got %+v, %v", stage, err)
+	}
+
+	if _, err := service.findStage("proposal", "unknown"); err == nil {
+		t.Error("expected an error for an unknown pipeline")
+	}
+}
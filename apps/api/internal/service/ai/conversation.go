@@ -0,0 +1,221 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_conversation"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_model_usage"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrConversationNotFound = errors.New("conversation not found")
+)
+
+const (
+	// chatHistoryTokenBudget caps the estimated size of previous messages sent with a question
+	chatHistoryTokenBudget = 3000
+
+	// chatHistoryLoadLimit caps how many stored messages are loaded before trimming to the token budget
+	chatHistoryLoadLimit = 50
+
+	// conversationTitleLength is the maximum length of a title derived from the first question
+	conversationTitleLength = 80
+)
+
+// SetConversationRepository enables server-side conversation history.
+// Without it, chat answers are not stored and each question is answered without history.
+func (s *Service) SetConversationRepository(conversationRepo interfaces.AIConversationRepository) {
+	s.conversationRepo = conversationRepo
+}
+
+// Chat answers a chat message, continuing the requested conversation or starting a new one.
+// History is loaded from the stored conversation; the question and answer are stored with token usage and model.
+func (s *Service) Chat(req *ai.ChatRequest, userID string) (*ai.ChatResponse, error) {
+	if err := s.checkTokenQuota(userID); err != nil {
+		return nil, err
+	}
+
+	conversation, history, err := s.beginTurn(req, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.complete(req.Message, chatContextID(req, conversation), chatContextType(req, conversation), history, req.Model, userID)
+	s.recordChatUsage(response, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.finishTurn(conversation, req.Message, response, userID); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ChatStream answers a chat message like Chat, forwarding the answer to onDelta as it is generated.
+// The answer is stored once the stream completes.
+func (s *Service) ChatStream(ctx context.Context, req *ai.ChatRequest, userID string, onDelta func(content string) error) (*ai.ChatResponse, error) {
+	if err := s.checkTokenQuota(userID); err != nil {
+		return nil, err
+	}
+
+	conversation, history, err := s.beginTurn(req, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.completeStream(ctx, req.Message, chatContextID(req, conversation), chatContextType(req, conversation), history, req.Model, userID, onDelta)
+	// Tokens of aborted streams count too, so disconnecting early does not bypass the quota
+	s.recordChatUsage(response, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.finishTurn(conversation, req.Message, response, userID); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// beginTurn resolves the conversation of a chat request and loads its history.
+// A new conversation titled after the question is prepared when no conversation ID is given;
+// nothing is stored until the answer arrives, so failed model calls leave no orphan questions.
+func (s *Service) beginTurn(req *ai.ChatRequest, userID string) (*ai_conversation.Conversation, []ai.ChatMessage, error) {
+	if s.conversationRepo == nil {
+		return nil, []ai.ChatMessage{}, nil
+	}
+
+	history := []ai.ChatMessage{}
+
+	if req.ConversationID == "" {
+		return &ai_conversation.Conversation{
+			UserID:      userID,
+			Title:       conversationTitle(req.Message),
+			ContextID:   req.Context,
+			ContextType: req.ContextType,
+		}, history, nil
+	}
+
+	conversation, err := s.conversationRepo.FindByID(req.ConversationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrConversationNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to load conversation: %w", err)
+	}
+	// Conversations of other users are reported as not found
+	if conversation.UserID != userID {
+		return nil, nil, ErrConversationNotFound
+	}
+
+	messages, err := s.conversationRepo.ListRecentMessages(conversation.ID, chatHistoryLoadLimit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load conversation history: %w", err)
+	}
+	for _, m := range messages {
+		history = append(history, ai.ChatMessage{Role: m.Role, Content: m.Content})
+	}
+
+	return conversation, history, nil
+}
+
+// recordChatUsage records the tokens of a chat turn, whether it was answered or failed after calling the model.
+// Answers without a model were produced locally and used no tokens.
+func (s *Service) recordChatUsage(response *ai.ChatResponse, userID string) {
+	if response == nil || response.Model == "" {
+		return
+	}
+	s.recordUsage(userID, response.Model, ai_model_usage.FeatureChat, response.Tokens)
+}
+
+// finishTurn stores the question and answer, creating the conversation when it is new
+func (s *Service) finishTurn(conversation *ai_conversation.Conversation, question string, response *ai.ChatResponse, userID string) error {
+	if conversation == nil {
+		return nil
+	}
+
+	if conversation.ID == "" {
+		conversation.LastMessageAt = time.Now()
+		if err := s.conversationRepo.Create(conversation); err != nil {
+			return fmt.Errorf("failed to create conversation: %w", err)
+		}
+	}
+
+	messages := []*ai_conversation.Message{
+		{
+			ConversationID: conversation.ID,
+			Role:           ai_conversation.RoleUser,
+			Content:        question,
+			Tokens:         estimateTokens(question),
+		},
+		{
+			ConversationID: conversation.ID,
+			Role:           ai_conversation.RoleAssistant,
+			Content:        response.Message,
+			Tokens:         response.Tokens,
+			Model:          response.Model,
+		},
+	}
+	for _, message := range messages {
+		if err := s.conversationRepo.AddMessage(message); err != nil {
+			return fmt.Errorf("failed to save message: %w", err)
+		}
+	}
+
+	response.ConversationID = conversation.ID
+	return nil
+}
+
+// chatContextID returns the context record of the request, or of the conversation when resuming
+func chatContextID(req *ai.ChatRequest, conversation *ai_conversation.Conversation) string {
+	if req.Context == "" && conversation != nil {
+		return conversation.ContextID
+	}
+	return req.Context
+}
+
+// chatContextType returns the context type of the request, or of the conversation when resuming
+func chatContextType(req *ai.ChatRequest, conversation *ai_conversation.Conversation) string {
+	if req.ContextType == "" && conversation != nil {
+		return conversation.ContextType
+	}
+	return req.ContextType
+}
+
+// trimHistoryToTokenBudget keeps the most recent messages whose estimated size fits the budget
+func trimHistoryToTokenBudget(history []ai.ChatMessage, budget int) []ai.ChatMessage {
+	used := 0
+	start := len(history)
+	for i := len(history) - 1; i >= 0; i-- {
+		tokens := estimateTokens(history[i].Content)
+		if used+tokens > budget {
+			break
+		}
+		used += tokens
+		start = i
+	}
+	return history[start:]
+}
+
+// estimateTokens approximates the token count of a text (about 4 characters per token)
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// conversationTitle derives a conversation title from the first question
+func conversationTitle(message string) string {
+	title := strings.Join(strings.Fields(message), " ")
+	if utf8.RuneCountInString(title) <= conversationTitleLength {
+		return title
+	}
+	runes := []rune(title)
+	return strings.TrimSpace(string(runes[:conversationTitleLength])) + "..."
+}
@@ -0,0 +1,205 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_conversation"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_model_usage"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/llm"
+)
+
+// fakeChatProvider answers every chat with a canned reply, or fails with err
+type fakeChatProvider struct {
+	llm.Provider
+	answer string
+	err    error
+	calls  int
+}
+
+func (p *fakeChatProvider) Name() string { return llm.ProviderOpenAICompatible }
+
+func (p *fakeChatProvider) Chat(req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &llm.ChatResponse{Message: llm.Message{Role: "assistant", Content: p.answer}, Tokens: 12}, nil
+}
+
+// fakeToolStreamProvider streams a tool call in its first round and fails every later round,
+// after streaming partial, if set
+type fakeToolStreamProvider struct {
+	fakeChatProvider
+	partial string
+}
+
+func (p *fakeToolStreamProvider) ChatStream(ctx context.Context, req *llm.ChatRequest, onDelta func(content string) error) (*llm.ChatResponse, error) {
+	p.calls++
+	if p.calls == 1 {
+		return &llm.ChatResponse{Message: llm.Message{Role: "assistant", ToolCalls: []llm.ToolCall{
+			{ID: "call-1", Name: "get_data_access_settings"},
+		}}, Tokens: 30}, nil
+	}
+	if p.partial != "" {
+		if err := onDelta(p.partial); err != nil {
+			return nil, err
+		}
+	}
+	return nil, errors.New("stream interrupted")
+}
+
+type fakeConversationRepo struct {
+	interfaces.AIConversationRepository
+	conversations map[string]*ai_conversation.Conversation
+	messages      []ai_conversation.Message
+}
+
+func (r *fakeConversationRepo) FindByID(id string) (*ai_conversation.Conversation, error) {
+	if conversation, ok := r.conversations[id]; ok {
+		return conversation, nil
+	}
+	return nil, ErrConversationNotFound
+}
+
+func (r *fakeConversationRepo) Create(conversation *ai_conversation.Conversation) error {
+	conversation.ID = "conversation-new"
+	r.conversations[conversation.ID] = conversation
+	return nil
+}
+
+func (r *fakeConversationRepo) AddMessage(message *ai_conversation.Message) error {
+	r.messages = append(r.messages, *message)
+	return nil
+}
+
+func (r *fakeConversationRepo) ListRecentMessages(conversationID string, limit int) ([]ai_conversation.Message, error) {
+	var messages []ai_conversation.Message
+	for _, m := range r.messages {
+		if m.ConversationID == conversationID {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+func newConversationTestService(t *testing.T, provider *fakeChatProvider) (*Service, *fakeConversationRepo) {
+	service, _ := newInsightTestService(t, "", nil)
+	service.defaultProvider = provider
+
+	repo := &fakeConversationRepo{conversations: map[string]*ai_conversation.Conversation{
+		"conversation-1": {ID: "conversation-1", UserID: "user-1", Title: "Pipeline"},
+	}}
+	service.SetConversationRepository(repo)
+	return service, repo
+}
+
+func TestChat_StoresQuestionAndAnswer(t *testing.T) {
+	service, repo := newConversationTestService(t, &fakeChatProvider{answer: "Two deals are open."})
+
+	response, err := service.Chat(&ai.ChatRequest{Message: "How many deals are open?"}, "user-1")
+	if err != nil {
+		t.Fatalf("Chat returned error: %v", err)
+	}
+
+	if response.ConversationID != "conversation-new" {
+		t.Errorf("expected the new conversation ID, got %q", response.ConversationID)
+	}
+	if conversation := repo.conversations["conversation-new"]; conversation == nil || conversation.Title != "How many deals are open?" {
+		t.Errorf("expected a conversation titled after the question, got %+v", conversation)
+	}
+	if len(repo.messages) != 2 {
+		t.Fatalf("expected question and answer to be stored, got %d messages", len(repo.messages))
+	}
+	if repo.messages[0].Role != ai_conversation.RoleUser || repo.messages[0].Content != "How many deals are open?" {
+		t.Errorf("unexpected question %+v", repo.messages[0])
+	}
+	if repo.messages[1].Role != ai_conversation.RoleAssistant || repo.messages[1].Content != "Two deals are open." || repo.messages[1].Tokens != 12 {
+		t.Errorf("unexpected answer %+v", repo.messages[1])
+	}
+}
+
+func TestChat_ProviderErrorStoresNothing(t *testing.T) {
+	provider := &fakeChatProvider{err: errors.New("upstream unavailable")}
+	service, repo := newConversationTestService(t, provider)
+
+	for _, conversationID := range []string{"", "conversation-1"} {
+		req := &ai.ChatRequest{Message: "How many deals are open?", ConversationID: conversationID}
+		if _, err := service.Chat(req, "user-1"); err == nil {
+			t.Fatalf("expected the provider error for conversation %q", conversationID)
+		}
+	}
+
+	if provider.calls != 2 {
+		t.Errorf("expected 2 model calls, got %d", provider.calls)
+	}
+	if len(repo.conversations) != 1 {
+		t.Errorf("expected no conversation to be created, got %d", len(repo.conversations))
+	}
+	if len(repo.messages) != 0 {
+		t.Errorf("expected no messages to be stored, got %+v", repo.messages)
+	}
+}
+
+func TestChatStream_FailureAfterToolRoundRecordsUsage(t *testing.T) {
+	for _, partial := range []string{"", "There are two open"} {
+		provider := &fakeToolStreamProvider{partial: partial}
+		service, repo := newConversationTestService(t, &provider.fakeChatProvider)
+		service.defaultProvider = provider
+		service.settingsRepo.(*fakeSettingsRepo).settings.Model = "llama-3.3-70b"
+		usageRepo := &fakeUsageRepo{}
+		service.SetUsageRepository(usageRepo, &fakeUserRepo{roleCode: "sales_rep"})
+
+		req := &ai.ChatRequest{Message: "How many deals are open?", ConversationID: "conversation-1"}
+		if _, err := service.ChatStream(context.Background(), req, "user-1", func(string) error { return nil }); err == nil {
+			t.Fatalf("expected the stream error with partial %q", partial)
+		}
+
+		if len(usageRepo.records) != 1 {
+			t.Fatalf("expected one usage record with partial %q, got %+v", partial, usageRepo.records)
+		}
+		record := usageRepo.records[0]
+		if record.userID != "user-1" || record.feature != ai_model_usage.FeatureChat {
+			t.Errorf("unexpected usage record %+v", record)
+		}
+		// The finished tool round is always counted; a cut-off answer adds its estimate on top
+		if partial == "" && record.tokens != 30 {
+			t.Errorf("expected the tool round's 30 tokens, got %d", record.tokens)
+		}
+		if partial != "" && record.tokens <= 30 {
+			t.Errorf("expected the cut-off round to add to the tool round's tokens, got %d", record.tokens)
+		}
+		if len(repo.messages) != 0 {
+			t.Errorf("expected no messages to be stored, got %+v", repo.messages)
+		}
+	}
+}
+
+func TestChat_QuotaExceededStoresNothing(t *testing.T) {
+	provider := &fakeChatProvider{answer: "unused"}
+	service, repo := newConversationTestService(t, provider)
+	service.settingsRepo.(*fakeSettingsRepo).settings.RoleTokenQuotas = []byte(`{"sales_rep": 1000}`)
+	service.SetUsageRepository(&fakeUsageRepo{used: 1000}, &fakeUserRepo{roleCode: "sales_rep"})
+
+	if _, err := service.Chat(&ai.ChatRequest{Message: "Hi", ConversationID: "conversation-1"}, "user-1"); err != ErrTokenQuotaExceeded {
+		t.Fatalf("expected ErrTokenQuotaExceeded, got %v", err)
+	}
+	if provider.calls != 0 || len(repo.messages) != 0 {
+		t.Errorf("expected no model call and no stored messages, got %d calls and %d messages", provider.calls, len(repo.messages))
+	}
+}
+
+func TestChat_OtherUsersConversationNotFound(t *testing.T) {
+	provider := &fakeChatProvider{answer: "unused"}
+	service, _ := newConversationTestService(t, provider)
+
+	if _, err := service.Chat(&ai.ChatRequest{Message: "Hi", ConversationID: "conversation-1"}, "user-2"); err != ErrConversationNotFound {
+		t.Fatalf("expected ErrConversationNotFound, got %v", err)
+	}
+	if provider.calls != 0 {
+		t.Errorf("expected no model call, got %d", provider.calls)
+	}
+}
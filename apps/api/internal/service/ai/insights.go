@@ -0,0 +1,280 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/activity"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_model_usage"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/contact"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/task"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/visit_report"
+	"github.com/gilabs/crm-healthcare/api/pkg/llm"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInsightTargetNotFound = errors.New("insight target not found")
+	ErrDataAccessDenied      = errors.New("access to this data is disabled by AI data privacy settings or user permissions")
+)
+
+// insightRelatedLimit caps how many related records are sent to the model per type
+const insightRelatedLimit = 10
+
+// WithScope returns a copy of the service whose deal, account, visit report, task and lead lookups are limited to the data scope
+func (s *Service) WithScope(scope *datascope.Scope) *Service {
+	scoped := *s
+	scoped.dealRepo = s.dealRepo.WithScope(scope)
+	scoped.accountRepo = s.accountRepo.WithScope(scope)
+	scoped.visitReportRepo = s.visitReportRepo.WithScope(scope)
+	scoped.taskRepo = s.taskRepo.WithScope(scope)
+	if s.leadRepo != nil {
+		scoped.leadRepo = s.leadRepo.WithScope(scope)
+	}
+	return &scoped
+}
+
+// AnalyzeDeal analyzes a deal with its activities, visit reports and tasks and returns AI insights
+func (s *Service) AnalyzeDeal(dealID string, userID string) (*ai.DealInsight, int, error) {
+	if err := s.checkTokenQuota(userID); err != nil {
+		return nil, 0, err
+	}
+	if err := s.requireDataAccess("deal", userID); err != nil {
+		return nil, 0, err
+	}
+
+	deal, err := s.dealRepo.FindByID(dealID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, ErrInsightTargetNotFound
+		}
+		return nil, 0, err
+	}
+
+	var activities []activity.Activity
+	if s.isDataAllowed("activity", userID) {
+		activities, _, _ = s.activityRepo.List(&activity.ListActivitiesRequest{DealID: dealID, PerPage: insightRelatedLimit})
+	}
+
+	var visitReports []visit_report.VisitReport
+	if s.isDataAllowed("visit_report", userID) {
+		visitReports, _, _ = s.visitReportRepo.List(&visit_report.ListVisitReportsRequest{DealID: dealID, PerPage: insightRelatedLimit})
+	}
+
+	var tasks []task.Task
+	if s.isDataAllowed("task", userID) {
+		tasks, _, _ = s.taskRepo.List(&task.ListTasksRequest{DealID: dealID, PerPage: insightRelatedLimit})
+	}
+
+	context := BuildDealInsightContext(deal, activities, visitReports, tasks)
+	text, tokens, err := s.generateInsight(BuildDealInsightPrompt(context), userID, ai_model_usage.FeatureDealInsight)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	insight := &ai.DealInsight{
+		NextSteps:       []string{},
+		RiskFactors:     []string{},
+		Recommendations: []string{},
+		ConfidenceLevel: "low",
+	}
+	if err := parseInsightJSON(text, insight); err != nil {
+		// If parsing fails, return raw response as the only recommendation
+		insight.Recommendations = []string{text}
+	}
+	insight.WinProbability = clampFloat(insight.WinProbability, 0, 100)
+
+	return insight, tokens, nil
+}
+
+// AnalyzeAccount analyzes an account relationship and returns a health score with risks and opportunities
+func (s *Service) AnalyzeAccount(accountID string, userID string) (*ai.AccountInsight, int, error) {
+	if err := s.checkTokenQuota(userID); err != nil {
+		return nil, 0, err
+	}
+	if err := s.requireDataAccess("account", userID); err != nil {
+		return nil, 0, err
+	}
+
+	accountEntity, err := s.accountRepo.FindByID(accountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, ErrInsightTargetNotFound
+		}
+		return nil, 0, err
+	}
+
+	var contacts []contact.Contact
+	if s.isDataAllowed("contact", userID) {
+		contacts, _ = s.contactRepo.FindByAccountID(accountID)
+	}
+
+	var deals []pipeline.Deal
+	if s.isDataAllowed("deal", userID) {
+		deals, _, _ = s.dealRepo.List(&pipeline.ListDealsRequest{AccountID: accountID, PerPage: insightRelatedLimit})
+	}
+
+	var activities []activity.Activity
+	if s.isDataAllowed("activity", userID) {
+		activities, _, _ = s.activityRepo.List(&activity.ListActivitiesRequest{AccountID: accountID, PerPage: insightRelatedLimit})
+	}
+
+	var visitReports []visit_report.VisitReport
+	if s.isDataAllowed("visit_report", userID) {
+		visitReports, _, _ = s.visitReportRepo.List(&visit_report.ListVisitReportsRequest{AccountID: accountID, PerPage: insightRelatedLimit})
+	}
+
+	var tasks []task.Task
+	if s.isDataAllowed("task", userID) {
+		tasks, _, _ = s.taskRepo.List(&task.ListTasksRequest{AccountID: accountID, PerPage: insightRelatedLimit})
+	}
+
+	context := BuildAccountInsightContext(accountEntity, contacts, deals, activities, visitReports, tasks)
+	text, tokens, err := s.generateInsight(BuildAccountInsightPrompt(context), userID, ai_model_usage.FeatureAccountInsight)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	insight := &ai.AccountInsight{
+		RiskIndicators:  []string{},
+		Opportunities:   []string{},
+		Recommendations: []string{},
+	}
+	if err := parseInsightJSON(text, insight); err != nil {
+		insight.Recommendations = []string{text}
+	}
+	insight.HealthScore = int(clampFloat(float64(insight.HealthScore), 0, 100))
+
+	return insight, tokens, nil
+}
+
+// AnalyzePipeline analyzes open deals and pipeline summary and returns a revenue forecast with trends
+func (s *Service) AnalyzePipeline(req *ai.AnalyzePipelineRequest, userID string) (*ai.PipelineInsight, int, error) {
+	if err := s.checkTokenQuota(userID); err != nil {
+		return nil, 0, err
+	}
+	if err := s.requireDataAccess("deal", userID); err != nil {
+		return nil, 0, err
+	}
+
+	summary, err := s.dealRepo.GetSummary("")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get pipeline summary: %w", err)
+	}
+
+	openDeals, _, err := s.dealRepo.List(&pipeline.ListDealsRequest{Status: "open", PerPage: 100})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list open deals: %w", err)
+	}
+	openDeals = filterDealsByCloseDate(openDeals, req.StartDate, req.EndDate)
+
+	context := BuildPipelineInsightContext(summary, openDeals, req.StartDate, req.EndDate)
+	text, tokens, err := s.generateInsight(BuildPipelineInsightPrompt(context), userID, ai_model_usage.FeaturePipelineInsight)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	insight := &ai.PipelineInsight{
+		ConfidenceLevel: "low",
+		Trends:          []string{},
+		Recommendations: []string{},
+	}
+	if err := parseInsightJSON(text, insight); err != nil {
+		insight.Recommendations = []string{text}
+	}
+
+	return insight, tokens, nil
+}
+
+// requireDataAccess returns ErrDataAccessDenied when the primary data type of an insight is not allowed
+func (s *Service) requireDataAccess(dataType string, userID string) error {
+	allowed, err := s.checkDataPrivacy(dataType, userID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrDataAccessDenied
+	}
+	return nil
+}
+
+// isDataAllowed reports whether related data of the given type may be included in the prompt
+func (s *Service) isDataAllowed(dataType string, userID string) bool {
+	allowed, err := s.checkDataPrivacy(dataType, userID)
+	return err == nil && allowed
+}
+
+// generateInsight sends an analysis prompt to the configured provider, records token usage and returns the raw text
+func (s *Service) generateInsight(prompt string, userID string, feature string) (string, int, error) {
+	provider, err := s.currentProvider()
+	if err != nil {
+		return "", 0, fmt.Errorf("AI service not configured: %w", err)
+	}
+
+	response, err := provider.Generate(&llm.GenerateRequest{
+		Prompt:      prompt,
+		MaxTokens:   800,
+		Temperature: 0.5,
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to generate insight: %w", err)
+	}
+	s.recordUsage(userID, "", feature, response.Tokens)
+	return response.Text, response.Tokens, nil
+}
+
+// parseInsightJSON extracts the first JSON object from the model output and decodes it into target
+func parseInsightJSON(text string, target interface{}) error {
+	cleaned := strings.TrimSpace(text)
+	jsonStart := strings.Index(cleaned, "{")
+	jsonEnd := strings.LastIndex(cleaned, "}")
+	if jsonStart == -1 || jsonEnd == -1 || jsonEnd < jsonStart {
+		return fmt.Errorf("no JSON found in response")
+	}
+
+	if err := json.Unmarshal([]byte(cleaned[jsonStart:jsonEnd+1]), target); err != nil {
+		return fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return nil
+}
+
+// filterDealsByCloseDate keeps deals whose expected close date falls within the optional YYYY-MM-DD range
+func filterDealsByCloseDate(deals []pipeline.Deal, startDate, endDate string) []pipeline.Deal {
+	start, startErr := time.Parse(dateFormat, startDate)
+	end, endErr := time.Parse(dateFormat, endDate)
+	if startErr != nil && endErr != nil {
+		return deals
+	}
+
+	filtered := make([]pipeline.Deal, 0, len(deals))
+	for _, d := range deals {
+		if d.ExpectedCloseDate == nil {
+			continue
+		}
+		if startErr == nil && d.ExpectedCloseDate.Before(start) {
+			continue
+		}
+		if endErr == nil && !d.ExpectedCloseDate.Before(end.Add(24*time.Hour)) {
+			continue
+		}
+		filtered = append(filtered, d)
+	}
+	return filtered
+}
+
+// clampFloat limits value to the [min, max] range
+func clampFloat(value, minValue, maxValue float64) float64 {
+	if value < minValue {
+		return minValue
+	}
+	if value > maxValue {
+		return maxValue
+	}
+	return value
+}
@@ -0,0 +1,390 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/activity"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_settings"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/contact"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/permission"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/task"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/visit_report"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/cerebras"
+	"gorm.io/gorm"
+)
+
+// fakeCerebras serves canned completions and records the prompts it receives
+type fakeCerebras struct {
+	server  *httptest.Server
+	text    string
+	prompts []string
+}
+
+func newFakeCerebras(t *testing.T, text string) *fakeCerebras {
+	f := &fakeCerebras{text: text}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Prompt string `json:"prompt"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.prompts = append(f.prompts, body.Prompt)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]string{{"text": f.text}},
+			"usage":   map[string]int{"total_tokens": 42},
+		})
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+type fakeDealRepo struct {
+	interfaces.DealRepository
+	deals   map[string]*pipeline.Deal
+	summary *pipeline.PipelineSummaryResponse
+}
+
+func (r *fakeDealRepo) WithScope(scope *datascope.Scope) interfaces.DealRepository { return r }
+
+func (r *fakeDealRepo) FindByID(id string) (*pipeline.Deal, error) {
+	if d, ok := r.deals[id]; ok {
+		return d, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeDealRepo) List(req *pipeline.ListDealsRequest) ([]pipeline.Deal, int64, error) {
+	var result []pipeline.Deal
+	for _, d := range r.deals {
+		if req.AccountID != "" && d.AccountID != req.AccountID {
+			continue
+		}
+		if req.Status != "" && d.Status != req.Status {
+			continue
+		}
+		result = append(result, *d)
+	}
+	return result, int64(len(result)), nil
+}
+
+func (r *fakeDealRepo) GetSummary(pipelineID string) (*pipeline.PipelineSummaryResponse, error) {
+	return r.summary, nil
+}
+
+type fakeAccountRepo struct {
+	interfaces.AccountRepository
+	accounts map[string]*account.Account
+}
+
+func (r *fakeAccountRepo) WithScope(scope *datascope.Scope) interfaces.AccountRepository { return r }
+
+func (r *fakeAccountRepo) FindByID(id string) (*account.Account, error) {
+	if a, ok := r.accounts[id]; ok {
+		return a, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeAccountRepo) List(req *account.ListAccountsRequest) ([]account.Account, int64, error) {
+	var accounts []account.Account
+	for _, a := range r.accounts {
+		if strings.Contains(strings.ToLower(a.Name), strings.ToLower(req.Search)) {
+			accounts = append(accounts, *a)
+		}
+	}
+	return accounts, int64(len(accounts)), nil
+}
+
+type fakeContactRepo struct {
+	interfaces.ContactRepository
+	contacts []contact.Contact
+}
+
+func (r *fakeContactRepo) FindByAccountID(accountID string) ([]contact.Contact, error) {
+	return r.contacts, nil
+}
+
+type fakeActivityRepo struct {
+	interfaces.ActivityRepository
+	activities []activity.Activity
+}
+
+func (r *fakeActivityRepo) List(req *activity.ListActivitiesRequest) ([]activity.Activity, int64, error) {
+	return r.activities, int64(len(r.activities)), nil
+}
+
+type fakeVisitReportRepo struct {
+	interfaces.VisitReportRepository
+}
+
+func (r *fakeVisitReportRepo) WithScope(scope *datascope.Scope) interfaces.VisitReportRepository {
+	return r
+}
+
+func (r *fakeVisitReportRepo) List(req *visit_report.ListVisitReportsRequest) ([]visit_report.VisitReport, int64, error) {
+	return nil, 0, nil
+}
+
+type fakeTaskRepo struct {
+	interfaces.TaskRepository
+	tasks []task.Task
+}
+
+func (r *fakeTaskRepo) WithScope(scope *datascope.Scope) interfaces.TaskRepository { return r }
+
+func (r *fakeTaskRepo) List(req *task.ListTasksRequest) ([]task.Task, int64, error) {
+	return r.tasks, int64(len(r.tasks)), nil
+}
+
+type fakeSettingsRepo struct {
+	interfaces.AISettingsRepository
+	settings *ai_settings.AISettings
+}
+
+func (r *fakeSettingsRepo) GetSettings() (*ai_settings.AISettings, error) {
+	return r.settings, nil
+}
+
+type fakePermissionRepo struct {
+	interfaces.PermissionRepository
+	codes []string
+}
+
+func (r *fakePermissionRepo) GetUserPermissions(userID string) (*permission.GetUserPermissionsResponse, error) {
+	actions := make([]permission.ActionResponse, len(r.codes))
+	for i, code := range r.codes {
+		actions[i] = permission.ActionResponse{Code: code, Access: true}
+	}
+	return &permission.GetUserPermissionsResponse{
+		Menus: []permission.MenuWithActionsResponse{{Actions: actions}},
+	}, nil
+}
+
+// newInsightTestService builds a service backed by in-memory repositories and the fake model server
+func newInsightTestService(t *testing.T, modelText string, privacy *ai_settings.DataPrivacySettings) (*Service, *fakeCerebras) {
+	fake := newFakeCerebras(t, modelText)
+
+	settings := &ai_settings.AISettings{Enabled: true}
+	if privacy != nil {
+		data, err := json.Marshal(privacy)
+		if err != nil {
+			t.Fatalf("failed to marshal data privacy: %v", err)
+		}
+		settings.DataPrivacy = data
+	}
+
+	closeDate := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	lateCloseDate := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	deals := map[string]*pipeline.Deal{
+		"deal-1": {
+			ID:                "deal-1",
+			Title:             "MRI Scanner Procurement",
+			AccountID:         "account-1",
+			Status:            "open",
+			Value:             500000000,
+			Probability:       40,
+			ExpectedCloseDate: &closeDate,
+			Stage:             &pipeline.PipelineStage{Name: "Negotiation"},
+		},
+		"deal-2": {
+			ID:                "deal-2",
+			Title:             "Lab Reagent Contract",
+			AccountID:         "account-1",
+			Status:            "open",
+			Value:             75000000,
+			Probability:       60,
+			ExpectedCloseDate: &lateCloseDate,
+		},
+	}
+
+	service := NewService(
+		cerebras.NewClient(fake.server.URL, "test-key", "llama-3.1-8b"),
+		&fakeVisitReportRepo{},
+		&fakeAccountRepo{accounts: map[string]*account.Account{
+			"account-1": {ID: "account-1", Name: "RSUD Jakarta", City: "Jakarta"},
+		}},
+		&fakeContactRepo{contacts: []contact.Contact{{ID: "contact-1", Name: "Dr. Siti"}}},
+		&fakeDealRepo{deals: deals, summary: &pipeline.PipelineSummaryResponse{
+			TotalDeals: 2,
+			OpenDeals:  2,
+			OpenValue:  575000000,
+		}},
+		nil,
+		&fakeActivityRepo{activities: []activity.Activity{{
+			ID:          "activity-1",
+			Type:        "call",
+			Description: "Discussed tender timeline",
+			Timestamp:   time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC),
+		}}},
+		&fakeTaskRepo{tasks: []task.Task{{ID: "task-1", Title: "Send revised quotation", Status: "pending"}}},
+		nil,
+		&fakeSettingsRepo{settings: settings},
+		&fakePermissionRepo{codes: []string{"VIEW_PIPELINE", "VIEW_ACCOUNTS", "VIEW_CONTACTS", "VIEW_TASKS", "VIEW_SALES_CRM"}},
+		"test-key",
+	)
+
+	return service, fake
+}
+
+func TestAnalyzeDeal(t *testing.T) {
+	modelText := `Here is the analysis:
+{"win_probability": 65, "next_steps": ["Schedule demo"], "risk_factors": ["Budget approval pending"], "recommendations": ["Engage procurement"], "confidence_level": "medium"}`
+	service, fake := newInsightTestService(t, modelText, nil)
+
+	insight, tokens, err := service.AnalyzeDeal("deal-1", "user-1")
+	if err != nil {
+		t.Fatalf("AnalyzeDeal returned error: %v", err)
+	}
+
+	if tokens != 42 {
+		t.Errorf("expected 42 tokens, got %d", tokens)
+	}
+	if insight.WinProbability != 65 {
+		t.Errorf("expected win probability 65, got %v", insight.WinProbability)
+	}
+	if insight.ConfidenceLevel != "medium" {
+		t.Errorf("expected confidence level medium, got %s", insight.ConfidenceLevel)
+	}
+	if len(insight.NextSteps) != 1 || insight.NextSteps[0] != "Schedule demo" {
+		t.Errorf("unexpected next steps: %v", insight.NextSteps)
+	}
+	if len(insight.RiskFactors) != 1 {
+		t.Errorf("unexpected risk factors: %v", insight.RiskFactors)
+	}
+
+	if len(fake.prompts) != 1 {
+		t.Fatalf("expected 1 request to the model, got %d", len(fake.prompts))
+	}
+	prompt := fake.prompts[0]
+	for _, expected := range []string{"MRI Scanner Procurement", "Negotiation", "Discussed tender timeline", "Send revised quotation"} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("prompt should contain %q", expected)
+		}
+	}
+}
+
+func TestAnalyzeDeal_ExcludesDisallowedRelatedData(t *testing.T) {
+	modelText := `{"win_probability": 50, "next_steps": [], "risk_factors": [], "recommendations": [], "confidence_level": "low"}`
+	service, fake := newInsightTestService(t, modelText, &ai_settings.DataPrivacySettings{
+		AllowDeals:      true,
+		AllowActivities: false,
+		AllowTasks:      true,
+	})
+
+	if _, _, err := service.AnalyzeDeal("deal-1", "user-1"); err != nil {
+		t.Fatalf("AnalyzeDeal returned error: %v", err)
+	}
+
+	prompt := fake.prompts[0]
+	if strings.Contains(prompt, "Discussed tender timeline") {
+		t.Error("prompt should not contain activities when activities are disabled")
+	}
+	if !strings.Contains(prompt, "Send revised quotation") {
+		t.Error("prompt should contain tasks when tasks are allowed")
+	}
+}
+
+func TestAnalyzeDeal_DataAccessDenied(t *testing.T) {
+	service, fake := newInsightTestService(t, "{}", &ai_settings.DataPrivacySettings{AllowDeals: false})
+
+	_, _, err := service.AnalyzeDeal("deal-1", "user-1")
+	if !errors.Is(err, ErrDataAccessDenied) {
+		t.Fatalf("expected ErrDataAccessDenied, got %v", err)
+	}
+	if len(fake.prompts) != 0 {
+		t.Error("model should not be called when data access is denied")
+	}
+}
+
+func TestAnalyzeDeal_NotFound(t *testing.T) {
+	service, _ := newInsightTestService(t, "{}", nil)
+
+	_, _, err := service.AnalyzeDeal("missing", "user-1")
+	if !errors.Is(err, ErrInsightTargetNotFound) {
+		t.Fatalf("expected ErrInsightTargetNotFound, got %v", err)
+	}
+}
+
+func TestAnalyzeAccount(t *testing.T) {
+	modelText := `{"health_score": 140, "risk_indicators": ["No visit in 30 days"], "opportunities": ["Lab expansion"], "recommendations": ["Plan quarterly review"]}`
+	service, fake := newInsightTestService(t, modelText, nil)
+
+	insight, _, err := service.AnalyzeAccount("account-1", "user-1")
+	if err != nil {
+		t.Fatalf("AnalyzeAccount returned error: %v", err)
+	}
+
+	if insight.HealthScore != 100 {
+		t.Errorf("expected health score to be clamped to 100, got %d", insight.HealthScore)
+	}
+	if len(insight.Opportunities) != 1 || insight.Opportunities[0] != "Lab expansion" {
+		t.Errorf("unexpected opportunities: %v", insight.Opportunities)
+	}
+
+	prompt := fake.prompts[0]
+	for _, expected := range []string{"RSUD Jakarta", "Dr. Siti", "MRI Scanner Procurement"} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("prompt should contain %q", expected)
+		}
+	}
+}
+
+func TestAnalyzeAccount_UnparsableResponse(t *testing.T) {
+	service, _ := newInsightTestService(t, "The account looks healthy overall.", nil)
+
+	insight, _, err := service.AnalyzeAccount("account-1", "user-1")
+	if err != nil {
+		t.Fatalf("AnalyzeAccount returned error: %v", err)
+	}
+
+	if len(insight.Recommendations) != 1 || insight.Recommendations[0] != "The account looks healthy overall." {
+		t.Errorf("raw response should be returned as recommendation, got %v", insight.Recommendations)
+	}
+}
+
+func TestAnalyzePipeline(t *testing.T) {
+	modelText := `{"forecast": 250000000, "confidence_level": "medium", "trends": ["Deals concentrated in negotiation"], "recommendations": ["Push MRI deal to close"]}`
+	service, fake := newInsightTestService(t, modelText, nil)
+
+	insight, _, err := service.AnalyzePipeline(&ai.AnalyzePipelineRequest{
+		StartDate: "2024-03-01",
+		EndDate:   "2024-03-31",
+	}, "user-1")
+	if err != nil {
+		t.Fatalf("AnalyzePipeline returned error: %v", err)
+	}
+
+	if insight.Forecast != 250000000 {
+		t.Errorf("expected forecast 250000000, got %v", insight.Forecast)
+	}
+	if len(insight.Trends) != 1 {
+		t.Errorf("unexpected trends: %v", insight.Trends)
+	}
+
+	prompt := fake.prompts[0]
+	if !strings.Contains(prompt, "MRI Scanner Procurement") {
+		t.Error("prompt should contain deals closing within the period")
+	}
+	if strings.Contains(prompt, "Lab Reagent Contract") {
+		t.Error("prompt should not contain deals closing outside the period")
+	}
+}
@@ -8,6 +8,9 @@ import (
 
 	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/activity"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/contact"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/task"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/visit_report"
 )
 
@@ -166,6 +169,8 @@ Analyze this pharmaceutical sales visit report with deep industry knowledge and
    - Medium-term opportunities (within 1 month)
    - Include specific deliverables: proposals, samples, documentation, meetings, etc.
    - Consider pharmaceutical sales best practices: follow-up timing, documentation requirements, regulatory compliance
+   - Write each action item as a short, self-contained task (it may become a task in the CRM)
+   - Give each action item a priority (urgent, high, medium, low) and the number of days until it is due (due_in_days)
 
 5. STRATEGIC RECOMMENDATIONS (2-4 recommendations):
    - Sales strategy suggestions based on visit outcomes
@@ -189,7 +194,10 @@ OUTPUT FORMAT (JSON only, no additional text):
   "summary": "Executive summary here (2-3 sentences)",
   "sentiment": "positive|neutral|negative",
   "key_points": ["Point 1", "Point 2", "Point 3"],
-  "action_items": ["Action 1", "Action 2", "Action 3"],
+  "action_items": [
+    {"text": "Action 1", "priority": "urgent|high|medium|low", "due_in_days": 2},
+    {"text": "Action 2", "priority": "urgent|high|medium|low", "due_in_days": 7}
+  ],
   "recommendations": ["Recommendation 1", "Recommendation 2"]
 }
 
@@ -601,3 +609,281 @@ Use this context to provide relevant, accurate, and actionable answers. Referenc
 
 	return basePrompt + timeContext + modelInfo
 }
+
+// BuildToolInstructions explains to the model how to fetch CRM data with the chat tools
+func BuildToolInstructions() string {
+	return `FETCHING CRM DATA WITH TOOLS:
+- You have tools that query the CRM for the current user: search_accounts, list_deals_by_stage, get_overdue_tasks, get_account_visit_reports, search_leads, get_pipeline_forecast and get_data_access_settings.
+- When the user asks about accounts, deals, pipeline, tasks, visit reports, leads or forecasts, CALL THE MATCHING TOOL instead of guessing. Tool results are REAL DATA and count as context data.
+- Call only the tools you need, with filters (stage, status, search text, limit) that match the question, so results stay small.
+- To get visit reports of an account by name, call search_accounts first and use the returned account ID.
+- If a tool returns an "error" (for example access is not allowed), tell the user honestly and do not invent the missing data.
+- When asked which data you can access, call get_data_access_settings.
+- Never show tool names, raw JSON or IDs to the user; present results as described in RESPONSE FORMATTING.`
+}
+
+// BuildDealInsightContext builds context string for deal analysis
+func BuildDealInsightContext(deal *pipeline.Deal, activities []activity.Activity, visitReports []visit_report.VisitReport, tasks []task.Task) string {
+	var sb strings.Builder
+
+	sb.WriteString("=== PHARMACEUTICAL SALES DEAL/OPPORTUNITY ===\n\n")
+
+	sb.WriteString("DEAL INFORMATION:\n")
+	sb.WriteString(fmt.Sprintf("- Title: %s\n", deal.Title))
+	sb.WriteString(fmt.Sprintf("- Status: %s\n", deal.Status))
+	if deal.Stage != nil {
+		sb.WriteString(fmt.Sprintf("- Stage: %s\n", deal.Stage.Name))
+	}
+	sb.WriteString(fmt.Sprintf("- Value: %s\n", formatCurrencyRupiah(deal.Value)))
+	sb.WriteString(fmt.Sprintf("- Current Probability: %d%%\n", deal.Probability))
+	if deal.ExpectedCloseDate != nil {
+		sb.WriteString(fmt.Sprintf("- Expected Close Date: %s\n", deal.ExpectedCloseDate.Format(dateFormat)))
+	}
+	if deal.Account != nil {
+		sb.WriteString(fmt.Sprintf("- Account: %s\n", deal.Account.Name))
+	}
+	if deal.Contact != nil {
+		sb.WriteString(fmt.Sprintf("- Contact: %s\n", deal.Contact.Name))
+	}
+	if deal.Source != "" {
+		sb.WriteString(fmt.Sprintf("- Source: %s\n", deal.Source))
+	}
+	if deal.Description != "" {
+		sb.WriteString(fmt.Sprintf("- Description: %s\n", deal.Description))
+	}
+	if deal.Notes != "" {
+		sb.WriteString(fmt.Sprintf("- Notes: %s\n", deal.Notes))
+	}
+	sb.WriteString(fmt.Sprintf("- Created: %s\n", deal.CreatedAt.Format(dateFormat)))
+
+	writeActivitiesContext(&sb, activities)
+	writeVisitReportsContext(&sb, visitReports)
+	writeTasksContext(&sb, tasks)
+
+	sb.WriteString("\n=== END OF CONTEXT ===\n")
+
+	return sb.String()
+}
+
+// BuildDealInsightPrompt builds prompt for deal analysis
+func BuildDealInsightPrompt(context string) string {
+	return fmt.Sprintf(`You are an expert AI assistant specialized in pharmaceutical and healthcare sales pipeline analysis. Assess the likelihood of winning the following deal based on its stage, value, history of activities, visit reports and open tasks.
+
+CONTEXT DATA:
+%s
+
+ANALYSIS REQUIREMENTS:
+1. WIN PROBABILITY: estimate the probability (0-100) that this deal will be won
+2. NEXT STEPS: 2-4 concrete next steps the sales rep should take
+3. RISK FACTORS: signals that could cause the deal to be lost (stalled activity, overdue tasks, missing decision maker, etc.)
+4. RECOMMENDATIONS: 2-4 strategic recommendations to move the deal forward
+5. CONFIDENCE LEVEL: "high", "medium" or "low" depending on how much data supports your assessment
+
+OUTPUT FORMAT (JSON only, no additional text):
+{
+  "win_probability": 65,
+  "next_steps": ["Step 1", "Step 2"],
+  "risk_factors": ["Risk 1", "Risk 2"],
+  "recommendations": ["Recommendation 1", "Recommendation 2"],
+  "confidence_level": "high|medium|low"
+}
+
+Provide your analysis now:`, context)
+}
+
+// BuildAccountInsightContext builds context string for account health analysis
+func BuildAccountInsightContext(account *account.Account, contacts []contact.Contact, deals []pipeline.Deal, activities []activity.Activity, visitReports []visit_report.VisitReport, tasks []task.Task) string {
+	var sb strings.Builder
+
+	sb.WriteString("=== HEALTHCARE FACILITY (ACCOUNT) ===\n\n")
+
+	sb.WriteString("ACCOUNT INFORMATION:\n")
+	sb.WriteString(fmt.Sprintf("- Facility Name: %s\n", account.Name))
+	if account.Category != nil && account.Category.Name != "" {
+		sb.WriteString(fmt.Sprintf("- Facility Type/Category: %s\n", account.Category.Name))
+	}
+	if account.City != "" {
+		sb.WriteString(fmt.Sprintf("- City: %s\n", account.City))
+	}
+	if account.Province != "" {
+		sb.WriteString(fmt.Sprintf("- Province: %s\n", account.Province))
+	}
+	sb.WriteString(fmt.Sprintf("- Status: %s\n", account.Status))
+	sb.WriteString(fmt.Sprintf("- Customer Since: %s\n", account.CreatedAt.Format(dateFormat)))
+
+	if len(contacts) > 0 {
+		sb.WriteString(fmt.Sprintf("\n=== CONTACTS (%d) ===\n", len(contacts)))
+		for _, ct := range contacts {
+			line := fmt.Sprintf("- %s", ct.Name)
+			if ct.Position != "" {
+				line += fmt.Sprintf(" (%s)", ct.Position)
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+
+	if len(deals) > 0 {
+		sb.WriteString(fmt.Sprintf("\n=== DEALS (%d) ===\n", len(deals)))
+		for _, d := range deals {
+			stageName := "N/A"
+			if d.Stage != nil {
+				stageName = d.Stage.Name
+			}
+			sb.WriteString(fmt.Sprintf("- %s | %s | stage: %s | status: %s | probability: %d%%\n", d.Title, formatCurrencyRupiah(d.Value), stageName, d.Status, d.Probability))
+		}
+	}
+
+	writeActivitiesContext(&sb, activities)
+	writeVisitReportsContext(&sb, visitReports)
+	writeTasksContext(&sb, tasks)
+
+	sb.WriteString("\n=== END OF CONTEXT ===\n")
+
+	return sb.String()
+}
+
+// BuildAccountInsightPrompt builds prompt for account health analysis
+func BuildAccountInsightPrompt(context string) string {
+	return fmt.Sprintf(`You are an expert AI assistant specialized in pharmaceutical key account management. Evaluate the health of the relationship with the following healthcare facility based on its deals, contacts, activity history, visit reports and tasks.
+
+CONTEXT DATA:
+%s
+
+ANALYSIS REQUIREMENTS:
+1. HEALTH SCORE: an integer from 0 (relationship at risk) to 100 (very healthy)
+2. RISK INDICATORS: signals of churn or declining engagement (no recent visits, lost deals, overdue tasks, single point of contact, etc.)
+3. OPPORTUNITIES: upselling, cross-selling or relationship expansion opportunities
+4. RECOMMENDATIONS: 2-4 actionable recommendations for the account owner
+
+OUTPUT FORMAT (JSON only, no additional text):
+{
+  "health_score": 75,
+  "risk_indicators": ["Risk 1", "Risk 2"],
+  "opportunities": ["Opportunity 1", "Opportunity 2"],
+  "recommendations": ["Recommendation 1", "Recommendation 2"]
+}
+
+Provide your analysis now:`, context)
+}
+
+// BuildPipelineInsightContext builds context string for pipeline analysis
+func BuildPipelineInsightContext(summary *pipeline.PipelineSummaryResponse, openDeals []pipeline.Deal, startDate, endDate string) string {
+	var sb strings.Builder
+
+	sb.WriteString("=== SALES PIPELINE OVERVIEW ===\n\n")
+
+	if startDate != "" || endDate != "" {
+		sb.WriteString(fmt.Sprintf("PERIOD: %s to %s (by expected close date)\n\n", startDate, endDate))
+	}
+
+	if summary != nil {
+		sb.WriteString("SUMMARY:\n")
+		sb.WriteString(fmt.Sprintf("- Total Deals: %d (%s)\n", summary.TotalDeals, formatCurrencyRupiah(summary.TotalValue)))
+		sb.WriteString(fmt.Sprintf("- Open Deals: %d (%s)\n", summary.OpenDeals, formatCurrencyRupiah(summary.OpenValue)))
+		sb.WriteString(fmt.Sprintf("- Won Deals: %d (%s)\n", summary.WonDeals, formatCurrencyRupiah(summary.WonValue)))
+		sb.WriteString(fmt.Sprintf("- Lost Deals: %d (%s)\n", summary.LostDeals, formatCurrencyRupiah(summary.LostValue)))
+
+		if len(summary.ByStage) > 0 {
+			sb.WriteString("\nBY STAGE:\n")
+			for _, stage := range summary.ByStage {
+				sb.WriteString(fmt.Sprintf("- %s: %d deals (%s)\n", stage.StageName, stage.DealCount, formatCurrencyRupiah(stage.TotalValue)))
+			}
+		}
+	}
+
+	if len(openDeals) > 0 {
+		var weighted int64
+		sb.WriteString(fmt.Sprintf("\n=== OPEN DEALS (%d) ===\n", len(openDeals)))
+		for _, d := range openDeals {
+			stageName := "N/A"
+			if d.Stage != nil {
+				stageName = d.Stage.Name
+			}
+			closeDate := "N/A"
+			if d.ExpectedCloseDate != nil {
+				closeDate = d.ExpectedCloseDate.Format(dateFormat)
+			}
+			weighted += d.Value * int64(d.Probability) / 100
+			sb.WriteString(fmt.Sprintf("- %s | %s | stage: %s | probability: %d%% | expected close: %s | last updated: %s\n", d.Title, formatCurrencyRupiah(d.Value), stageName, d.Probability, closeDate, d.UpdatedAt.Format(dateFormat)))
+		}
+		sb.WriteString(fmt.Sprintf("\n- Weighted Open Value: %s\n", formatCurrencyRupiah(weighted)))
+	}
+
+	sb.WriteString("\n=== END OF CONTEXT ===\n")
+
+	return sb.String()
+}
+
+// BuildPipelineInsightPrompt builds prompt for pipeline analysis
+func BuildPipelineInsightPrompt(context string) string {
+	return fmt.Sprintf(`You are an expert AI assistant specialized in pharmaceutical sales forecasting. Analyze the following sales pipeline and forecast the revenue that will close.
+
+CONTEXT DATA:
+%s
+
+ANALYSIS REQUIREMENTS:
+1. FORECAST: expected closed-won revenue in Rupiah (number only, no separators), based on open deal values and probabilities
+2. CONFIDENCE LEVEL: "high", "medium" or "low"
+3. TRENDS: 2-4 observations about pipeline health (stage bottlenecks, concentration risk, aging deals, win/loss ratio)
+4. RECOMMENDATIONS: 2-4 actions for the sales team to improve conversion
+
+OUTPUT FORMAT (JSON only, no additional text):
+{
+  "forecast": 150000000,
+  "confidence_level": "high|medium|low",
+  "trends": ["Trend 1", "Trend 2"],
+  "recommendations": ["Recommendation 1", "Recommendation 2"]
+}
+
+Provide your analysis now:`, context)
+}
+
+// writeActivitiesContext appends recent activities (max 10) to the context
+func writeActivitiesContext(sb *strings.Builder, activities []activity.Activity) {
+	if len(activities) == 0 {
+		return
+	}
+	sb.WriteString("\n=== RECENT ACTIVITIES ===\n")
+	for i, act := range activities {
+		if i >= 10 {
+			break
+		}
+		sb.WriteString(fmt.Sprintf("- [%s] %s: %s\n", act.Timestamp.Format(dateFormat), act.Type, act.Description))
+	}
+}
+
+// writeVisitReportsContext appends recent visit reports (max 10) to the context
+func writeVisitReportsContext(sb *strings.Builder, visitReports []visit_report.VisitReport) {
+	if len(visitReports) == 0 {
+		return
+	}
+	sb.WriteString("\n=== RECENT VISIT REPORTS ===\n")
+	for i, vr := range visitReports {
+		if i >= 10 {
+			break
+		}
+		sb.WriteString(fmt.Sprintf("- [%s] status: %s | purpose: %s", vr.VisitDate.Format(dateFormat), vr.Status, vr.Purpose))
+		if vr.Notes != "" {
+			sb.WriteString(fmt.Sprintf(" | notes: %s", vr.Notes))
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// writeTasksContext appends tasks (max 10) to the context
+func writeTasksContext(sb *strings.Builder, tasks []task.Task) {
+	if len(tasks) == 0 {
+		return
+	}
+	sb.WriteString("\n=== TASKS ===\n")
+	for i, t := range tasks {
+		if i >= 10 {
+			break
+		}
+		dueDate := "N/A"
+		if t.DueDate != nil {
+			dueDate = t.DueDate.Format(dateFormat)
+		}
+		sb.WriteString(fmt.Sprintf("- %s | status: %s | priority: %s | due: %s\n", t.Title, t.Status, t.Priority, dueDate))
+	}
+}
@@ -1,17 +1,19 @@
 package ai
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
 	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/activity"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/ai"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_model_usage"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_settings"
-	"github.com/gilabs/crm-healthcare/api/internal/domain/contact"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/permission"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
@@ -19,6 +21,7 @@ import (
 	"github.com/gilabs/crm-healthcare/api/internal/domain/visit_report"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
 	"github.com/gilabs/crm-healthcare/api/pkg/cerebras"
+	"github.com/gilabs/crm-healthcare/api/pkg/llm"
 )
 
 var (
@@ -28,7 +31,7 @@ var (
 
 // Service represents AI service
 type Service struct {
-	cerebrasClient   *cerebras.Client
+	defaultProvider  llm.Provider // Cerebras client configured from environment
 	visitReportRepo  interfaces.VisitReportRepository
 	accountRepo      interfaces.AccountRepository
 	contactRepo      interfaces.ContactRepository
@@ -39,6 +42,9 @@ type Service struct {
 	pipelineRepo     interfaces.PipelineRepository
 	settingsRepo     interfaces.AISettingsRepository
 	permissionRepo   interfaces.PermissionRepository
+	conversationRepo interfaces.AIConversationRepository // Optional, set via SetConversationRepository
+	usageRepo        interfaces.AIModelUsageRepository   // Optional, set via SetUsageRepository
+	userRepo         interfaces.UserRepository           // Optional, set via SetUsageRepository
 	apiKey           string
 }
 
@@ -58,7 +64,7 @@ func NewService(
 	apiKey string,
 ) *Service {
 	return &Service{
-		cerebrasClient:   cerebrasClient,
+		defaultProvider: llm.NewCerebrasProvider(cerebrasClient),
 		visitReportRepo: visitReportRepo,
 		accountRepo:     accountRepo,
 		contactRepo:     contactRepo,
@@ -73,16 +79,54 @@ func NewService(
 	}
 }
 
-// validateAPIKey checks if API key is configured
-func (s *Service) validateAPIKey() error {
-	if s.apiKey == "" {
-		return ErrAPIKeyEmpty
+// currentProvider returns the provider selected in AI settings, or the environment Cerebras client
+// when no settings are saved
+func (s *Service) currentProvider() (llm.Provider, error) {
+	settings, err := s.settingsRepo.GetSettings()
+	if err != nil {
+		if s.apiKey == "" {
+			return nil, ErrAPIKeyEmpty
+		}
+		return s.defaultProvider, nil
 	}
-	return nil
+	return s.providerFromSettings(settings)
+}
+
+// providerFromSettings builds the provider chosen in AI settings at request time,
+// so switching providers from the settings page takes effect without a redeploy.
+// Cerebras without a saved API key or base URL uses the client configured from environment.
+func (s *Service) providerFromSettings(settings *ai_settings.AISettings) (llm.Provider, error) {
+	providerName := settings.Provider
+	if providerName == "" {
+		providerName = llm.ProviderCerebras
+	}
+
+	if providerName == llm.ProviderCerebras && settings.APIKey == "" && settings.BaseURL == "" {
+		if s.apiKey == "" {
+			return nil, ErrAPIKeyEmpty
+		}
+		return s.defaultProvider, nil
+	}
+
+	apiKey := settings.APIKey
+	if apiKey == "" && providerName == llm.ProviderCerebras {
+		apiKey = s.apiKey // Fallback to env
+	}
+
+	return llm.NewProvider(llm.Config{
+		Provider: providerName,
+		BaseURL:  settings.BaseURL,
+		APIKey:   apiKey,
+		Model:    settings.Model,
+	})
 }
 
 // AnalyzeVisitReport analyzes visit report and returns AI insights
-func (s *Service) AnalyzeVisitReport(visitReportID string) (*ai.VisitReportInsight, int, error) {
+func (s *Service) AnalyzeVisitReport(visitReportID string, userID string) (*ai.VisitReportInsight, int, error) {
+	if err := s.checkTokenQuota(userID); err != nil {
+		return nil, 0, err
+	}
+
 	// Get visit report
 	visitReport, err := s.visitReportRepo.FindByID(visitReportID)
 	if err != nil {
@@ -118,8 +162,9 @@ func (s *Service) AnalyzeVisitReport(visitReportID string) (*ai.VisitReportInsig
 		activities = activities[:5]
 	}
 
-	// Validate API key
-	if err := s.validateAPIKey(); err != nil {
+	// Resolve provider from settings
+	provider, err := s.currentProvider()
+	if err != nil {
 		return nil, 0, fmt.Errorf("AI service not configured: %w", err)
 	}
 
@@ -129,8 +174,8 @@ func (s *Service) AnalyzeVisitReport(visitReportID string) (*ai.VisitReportInsig
 	// Build prompt
 	prompt := BuildVisitReportPrompt(context)
 
-	// Call Cerebras API
-	response, err := s.cerebrasClient.Generate(&cerebras.GenerateRequest{
+	// Call AI provider
+	response, err := provider.Generate(&llm.GenerateRequest{
 		Prompt:      prompt,
 		MaxTokens:   800,
 		Temperature: 0.7,
@@ -138,14 +183,15 @@ func (s *Service) AnalyzeVisitReport(visitReportID string) (*ai.VisitReportInsig
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to generate insight: %w", err)
 	}
+	s.recordUsage(userID, "", ai_model_usage.FeatureVisitReportAnalysis, response.Tokens)
 
 	// Parse AI response
-	insight, err := s.parseVisitReportInsight(response.Text)
+	insight, err := s.parseVisitReportInsight(response.Text, visitReportID, time.Now())
 	if err != nil {
 		// If parsing fails, return raw response as summary
 		insight = &ai.VisitReportInsight{
 			Summary:     response.Text,
-			ActionItems: []string{},
+			ActionItems: []ai.ActionItem{},
 			Sentiment:   "neutral",
 			KeyPoints:   []string{},
 			Recommendations: []string{},
@@ -308,9 +354,9 @@ func (s *Service) hasUserPermission(userPerms *permission.GetUserPermissionsResp
 	return searchPermission(userPerms.Menus, permissionCode)
 }
 
-// Chat handles chat conversation with AI
-// userID is required to check user permissions for data access
-func (s *Service) Chat(message string, contextID string, contextType string, conversationHistory []ai.ChatMessage, model string, userID string) (*ai.ChatResponse, error) {
+// prepareChat loads settings and context data and builds the chat completion request.
+// CRM data beyond the explicit context is fetched by the model through chat tools.
+func (s *Service) prepareChat(message string, contextID string, contextType string, conversationHistory []ai.ChatMessage, model string, userID string) (*preparedChat, error) {
 	// Get AI settings
 	settings, err := s.settingsRepo.GetSettings()
 	if err != nil {
@@ -327,94 +373,19 @@ func (s *Service) Chat(message string, contextID string, contextType string, con
 		selectedModel = settings.Model
 	}
 
-	// Get API key from settings or fallback to env
-	apiKey := settings.APIKey
-	if apiKey == "" {
-		apiKey = s.apiKey // Fallback to env
-	}
-
-	if apiKey == "" {
-		return nil, fmt.Errorf("AI service not configured: API key is empty")
-	}
-
-	// Handle specific query about data privacy settings
-	messageLower := strings.ToLower(message)
-	if strings.Contains(messageLower, "data privacy") || strings.Contains(messageLower, "privacy") || 
-	   strings.Contains(messageLower, "data privasi") || strings.Contains(messageLower, "privasi") ||
-	   strings.Contains(messageLower, "akses data") || strings.Contains(messageLower, "data yang bisa") {
-		// Get data privacy settings
-		var dataPrivacy ai_settings.DataPrivacySettings
-		
-		if settings.DataPrivacy != nil {
-			if err := json.Unmarshal(settings.DataPrivacy, &dataPrivacy); err == nil {
-				privacyInfo := fmt.Sprintf("**PENGATURAN DATA PRIVACY:**\n\n")
-				privacyInfo += fmt.Sprintf("Akses ke data berikut diizinkan:\n\n")
-				if dataPrivacy.AllowAccounts {
-					privacyInfo += "✓ **Accounts** (Akun/Fasilitas Kesehatan)\n"
-				} else {
-					privacyInfo += "✗ **Accounts** (Akun/Fasilitas Kesehatan) - TIDAK diizinkan\n"
-				}
-				if dataPrivacy.AllowContacts {
-					privacyInfo += "✓ **Contacts** (Kontak/Dokter/Apoteker)\n"
-				} else {
-					privacyInfo += "✗ **Contacts** (Kontak/Dokter/Apoteker) - TIDAK diizinkan\n"
-				}
-				if dataPrivacy.AllowDeals {
-					privacyInfo += "✓ **Deals/Pipeline** (Kesempatan Penjualan)\n"
-				} else {
-					privacyInfo += "✗ **Deals/Pipeline** (Kesempatan Penjualan) - TIDAK diizinkan\n"
-				}
-				if dataPrivacy.AllowVisitReports {
-					privacyInfo += "✓ **Visit Reports** (Laporan Kunjungan)\n"
-				} else {
-					privacyInfo += "✗ **Visit Reports** (Laporan Kunjungan) - TIDAK diizinkan\n"
-				}
-				if dataPrivacy.AllowActivities {
-					privacyInfo += "✓ **Activities** (Aktivitas)\n"
-				} else {
-					privacyInfo += "✗ **Activities** (Aktivitas) - TIDAK diizinkan\n"
-				}
-				if dataPrivacy.AllowTasks {
-					privacyInfo += "✓ **Tasks** (Tugas)\n"
-				} else {
-					privacyInfo += "✗ **Tasks** (Tugas) - TIDAK diizinkan\n"
-				}
-				if dataPrivacy.AllowProducts {
-					privacyInfo += "✓ **Products** (Produk)\n"
-				} else {
-					privacyInfo += "✗ **Products** (Produk) - TIDAK diizinkan\n"
-				}
-				
-				// Return direct response about data privacy settings
-				return &ai.ChatResponse{
-					Message: privacyInfo + "\n\nIni adalah pengaturan data privacy yang saat ini aktif di sistem. Anda dapat mengubah pengaturan ini melalui halaman AI Settings.",
-					Tokens:  0, // No tokens consumed for this internal response
-				}, nil
-			}
-		}
-		
-		// Use default settings (all allowed) if DataPrivacy is nil
-		privacyInfo := fmt.Sprintf("**PENGATURAN DATA PRIVACY:**\n\n")
-		privacyInfo += fmt.Sprintf("Pengaturan data privacy belum dikonfigurasi. Secara default, semua jenis data dapat diakses:\n\n")
-		privacyInfo += "✓ **Accounts** (Akun/Fasilitas Kesehatan)\n"
-		privacyInfo += "✓ **Contacts** (Kontak/Dokter/Apoteker)\n"
-		privacyInfo += "✓ **Deals/Pipeline** (Kesempatan Penjualan)\n"
-		privacyInfo += "✓ **Visit Reports** (Laporan Kunjungan)\n"
-		privacyInfo += "✓ **Activities** (Aktivitas)\n"
-		privacyInfo += "✓ **Tasks** (Tugas)\n"
-		privacyInfo += "✓ **Products** (Produk)\n"
-		
-		return &ai.ChatResponse{
-			Message: privacyInfo + "\n\nAnda dapat mengatur data privacy melalui halaman AI Settings untuk membatasi akses ke jenis data tertentu.",
-			Tokens:  0,
-		}, nil
+	// Resolve the provider selected in settings
+	provider, err := s.providerFromSettings(settings)
+	if err != nil {
+		return nil, fmt.Errorf("AI service not configured: %w", err)
 	}
 
 	// Load context data if provided
 	var contextData string
 	var dataAccessInfo string
 	
-	if contextID != "" && contextType != "" {
+	if contextID != "" && contextType != "" && !s.isDataAllowed(contextType, userID) {
+		dataAccessInfo = "⚠️ Akses ke data " + contextType + " tidak diizinkan berdasarkan pengaturan privasi data atau permission yang Anda miliki."
+	} else if contextID != "" && contextType != "" {
 		// Load specific context data
 		switch contextType {
 		case "visit_report":
@@ -458,482 +429,6 @@ func (s *Service) Chat(message string, contextID string, contextType string, con
 				dataAccessInfo = "⚠️ Tidak dapat mengakses data lead dengan ID tersebut. Data mungkin tidak ditemukan atau tidak memiliki akses."
 			}
 		}
-	} else {
-		// Try to extract data from user message - ALWAYS try to get data
-		messageLower := strings.ToLower(message)
-		
-		// Check for analytics/statistics queries (HIGHEST PRIORITY - needs all deals data)
-		// These queries require comprehensive deals data for calculations
-		isAnalyticsQuery := strings.Contains(messageLower, "conversion rate") || 
-		                   strings.Contains(messageLower, "conversion") ||
-		                   strings.Contains(messageLower, "rate konversi") ||
-		                   strings.Contains(messageLower, "statistik") ||
-		                   strings.Contains(messageLower, "statistics") ||
-		                   strings.Contains(messageLower, "analisis") ||
-		                   strings.Contains(messageLower, "analysis") ||
-		                   strings.Contains(messageLower, "rata-rata") ||
-		                   strings.Contains(messageLower, "average") ||
-		                   strings.Contains(messageLower, "trend") ||
-		                   strings.Contains(messageLower, "perbandingan") ||
-		                   strings.Contains(messageLower, "comparison") ||
-		                   strings.Contains(messageLower, "breakdown") ||
-		                   (strings.Contains(messageLower, "berapa") && (strings.Contains(messageLower, "lead") || strings.Contains(messageLower, "closed won") || strings.Contains(messageLower, "closed lost")))
-		
-		if isAnalyticsQuery {
-			// Check data privacy and user permissions
-			allowed, _ := s.checkDataPrivacy("deal", userID)
-			if !allowed {
-				dataAccessInfo = "⚠️ Akses ke data deals/pipeline tidak diizinkan berdasarkan pengaturan privasi data atau permission yang Anda miliki."
-			} else {
-				// For analytics queries, fetch ALL deals (or at least a large sample) without stage filter
-				// This allows AI to calculate conversion rates, averages, etc.
-				req := &pipeline.ListDealsRequest{
-					Page:    1,
-					PerPage: 100, // Fetch more deals for analytics
-				}
-				
-				deals, _, err := s.dealRepo.List(req)
-				if err == nil && len(deals) > 0 {
-					// For analytics, limit to 50 deals max to prevent token overflow
-					maxDeals := 50
-					if len(deals) > maxDeals {
-						deals = deals[:maxDeals]
-					}
-					
-					// Transform deals to user-friendly format with names
-					dealsFormatted := s.formatDealsForAI(deals)
-					dealsJSON, _ := json.Marshal(dealsFormatted)
-					
-					// Build concise instruction for analytics
-					instruction := fmt.Sprintf("REAL DEALS DATA (%d deals for analytics):\n%s\n\nCalculate statistics using ONLY this data. Present results clearly with actual numbers.", len(deals), string(dealsJSON))
-					contextData = instruction
-					contextType = "deal"
-				} else {
-					dataAccessInfo = "⚠️ Tidak dapat mengakses data pipeline/deals dari database untuk perhitungan statistik. Data mungkin tidak tersedia."
-				}
-			}
-		}
-		
-		// Check priority: pipeline/deals first (more specific), then accounts
-		// Check if user is asking for pipeline/deals/sales funnel (HIGHEST PRIORITY)
-		if contextData == "" && (strings.Contains(messageLower, "pipeline") || strings.Contains(messageLower, "sales funnel") || 
-		   strings.Contains(messageLower, "funnel") || strings.Contains(messageLower, "deal") ||
-		   strings.Contains(messageLower, "opportunity") || strings.Contains(messageLower, "kesempatan")) {
-			// Check data privacy and user permissions
-			allowed, _ := s.checkDataPrivacy("deal", userID)
-			if !allowed {
-				dataAccessInfo = "⚠️ Akses ke data deals/pipeline tidak diizinkan berdasarkan pengaturan privasi data atau permission yang Anda miliki."
-			} else {
-				// Build request with optional stage filter
-				req := &pipeline.ListDealsRequest{
-					Page:    1,
-					PerPage: 20,
-				}
-				
-				// Extract stage filter from message if mentioned
-				var stageID string
-				if strings.Contains(messageLower, "lead") {
-					if stage, err := s.pipelineRepo.FindStageByCode("lead"); err == nil {
-						stageID = stage.ID
-					}
-				} else if strings.Contains(messageLower, "qualification") {
-					if stage, err := s.pipelineRepo.FindStageByCode("qualification"); err == nil {
-						stageID = stage.ID
-					}
-				} else if strings.Contains(messageLower, "proposal") {
-					if stage, err := s.pipelineRepo.FindStageByCode("proposal"); err == nil {
-						stageID = stage.ID
-					}
-				} else if strings.Contains(messageLower, "negotiation") {
-					if stage, err := s.pipelineRepo.FindStageByCode("negotiation"); err == nil {
-						stageID = stage.ID
-					}
-				} else if strings.Contains(messageLower, "closed won") || strings.Contains(messageLower, "won") {
-					if stage, err := s.pipelineRepo.FindStageByCode("closed_won"); err == nil {
-						stageID = stage.ID
-					}
-				} else if strings.Contains(messageLower, "closed lost") || strings.Contains(messageLower, "lost") {
-					if stage, err := s.pipelineRepo.FindStageByCode("closed_lost"); err == nil {
-						stageID = stage.ID
-					}
-				}
-				
-				if stageID != "" {
-					req.StageID = stageID
-				}
-				
-				deals, _, err := s.dealRepo.List(req)
-				if err == nil && len(deals) > 0 {
-					// Limit number of deals to prevent token overflow (max 15 deals for large responses)
-					maxDeals := 15
-					if len(deals) > maxDeals {
-						deals = deals[:maxDeals]
-					}
-					
-					// Transform deals to user-friendly format with names
-					dealsFormatted := s.formatDealsForAI(deals)
-					dealsJSON, _ := json.Marshal(dealsFormatted)
-					
-					// Build concise instruction
-					instruction := fmt.Sprintf("REAL PIPELINE/DEALS DATA (showing %d deals):\n%s\n\nPresent in Markdown table. Use [Title](deal://id) for clickable links. Show only names, not IDs.", len(deals), string(dealsJSON))
-					contextData = instruction
-					contextType = "deal"
-					fmt.Printf("Context data set with %d deals (context size: %d chars)\n", len(deals), len(contextData))
-				} else {
-					if err != nil {
-						fmt.Printf("Error fetching deals: %v\n", err)
-					}
-					dataAccessInfo = "⚠️ Tidak dapat mengakses data pipeline/deals dari database. Data mungkin tidak tersedia."
-				}
-				fmt.Printf("========================\n")
-			}
-		}
-		
-		// Check if user is asking for leads/lead management (HIGH PRIORITY - check before general data)
-		// This should be checked early to avoid being caught by "general data" logic
-		if contextData == "" && (strings.Contains(messageLower, "lead") || strings.Contains(messageLower, "lead management") || 
-		   strings.Contains(messageLower, "prospek") || strings.Contains(messageLower, "calon pelanggan") ||
-		   (strings.Contains(messageLower, "tampilkan") && strings.Contains(messageLower, "lead")) ||
-		   (strings.Contains(messageLower, "data") && strings.Contains(messageLower, "lead"))) {
-			// Check data privacy and user permissions
-			allowed, _ := s.checkDataPrivacy("lead", userID)
-			if !allowed {
-				dataAccessInfo = "⚠️ Akses ke data leads tidak diizinkan berdasarkan pengaturan privasi data atau permission yang Anda miliki."
-			} else {
-				// Build request with optional status filter
-				req := &lead.ListLeadsRequest{
-					Page:    1,
-					PerPage: 20, // No limit - AI will handle overflow automatically
-				}
-				
-				// Extract status filter from message if mentioned
-				if strings.Contains(messageLower, "new") {
-					req.Status = "new"
-				} else if strings.Contains(messageLower, "contacted") {
-					req.Status = "contacted"
-				} else if strings.Contains(messageLower, "qualified") {
-					req.Status = "qualified"
-				} else if strings.Contains(messageLower, "unqualified") {
-					req.Status = "unqualified"
-				} else if strings.Contains(messageLower, "nurturing") {
-					req.Status = "nurturing"
-				} else if strings.Contains(messageLower, "disqualified") {
-					req.Status = "disqualified"
-				} else if strings.Contains(messageLower, "converted") {
-					req.Status = "converted"
-				} else if strings.Contains(messageLower, "lost") {
-					req.Status = "lost"
-				}
-				
-				leads, total, err := s.leadRepo.List(req)
-				fmt.Printf("=== DATA FETCH DEBUG ===\n")
-				fmt.Printf("Fetching leads - Error: %v, Count: %d, Total: %d, Status: %s\n", err, len(leads), total, req.Status)
-				if err == nil && len(leads) > 0 {
-					// Limit number of leads to prevent token overflow (max 15 leads for large responses)
-					maxLeads := 15
-					if len(leads) > maxLeads {
-						leads = leads[:maxLeads]
-						fmt.Printf("Limited leads to %d to prevent token overflow\n", maxLeads)
-					}
-					
-					// Transform leads to user-friendly format
-					leadsFormatted := s.formatLeadsForAI(leads)
-					leadsJSON, _ := json.Marshal(leadsFormatted)
-					
-					// Build concise instruction
-					instruction := fmt.Sprintf("REAL LEADS DATA (showing %d of %d total):\n%s\n\nPresent in Markdown table. Use [Name](lead://id) for clickable links. Show only names, not IDs.", len(leads), total, string(leadsJSON))
-					contextData = instruction
-					contextType = "lead"
-				} else {
-					dataAccessInfo = "⚠️ Tidak dapat mengakses data leads dari database. Data mungkin tidak tersedia."
-				}
-			}
-		}
-		
-		// Check if user is asking for accounts (only if not pipeline)
-		if contextData == "" && (strings.Contains(messageLower, "account") || strings.Contains(messageLower, "akun") || 
-		   strings.Contains(messageLower, "rumah sakit") || strings.Contains(messageLower, "klinik") || 
-		   strings.Contains(messageLower, "apotek") || strings.Contains(messageLower, "facility")) {
-			// Check data privacy and user permissions
-			allowed, _ := s.checkDataPrivacy("account", userID)
-			if !allowed {
-				dataAccessInfo = "⚠️ Akses ke data accounts tidak diizinkan berdasarkan pengaturan privasi data atau permission yang Anda miliki."
-			} else {
-				accounts, total, err := s.accountRepo.List(&account.ListAccountsRequest{
-					Page:    1,
-					PerPage: 10,
-				})
-				if err == nil && len(accounts) > 0 {
-					// Transform accounts to user-friendly format with names
-					accountsFormatted := s.formatAccountsForAI(accounts)
-					accountsJSON, _ := json.Marshal(accountsFormatted)
-					contextData = fmt.Sprintf("REAL ACCOUNTS DATA FROM DATABASE (showing %d of %d total accounts):\n%s\n\nCRITICAL INSTRUCTION: You MUST use ONLY the data above. Present it in a Markdown table format. CRITICAL: NEVER show IDs as separate columns - IDs are ONLY used in clickable links. ALWAYS show ONLY NAMES (name, category, city, province) in tables. For clickable actions that trigger detail components, the 'Nama Akun' (Name) column MUST be formatted as [Name](account://id) to create clickable links. Example: [RSUD Jakarta](account://ab868b77-e9b3-429f-ad8c-d55ac1f6561b). Use the EXACT id from the data above - DO NOT create or invent IDs. DO NOT create columns like 'ID', 'Account ID', etc. - these should NOT appear in tables. DO NOT create, invent, or make up any data. DO NOT add columns that don't exist in the data. AFTER presenting the table, ALWAYS provide 1-2 insights, ask 2-3 follow-up questions to understand what the user wants, and offer actionable recommendations.", len(accounts), total, string(accountsJSON))
-					contextType = "account" // Set context type for proper prompt
-				} else {
-					dataAccessInfo = "⚠️ Tidak dapat mengakses data accounts dari database. Data mungkin tidak tersedia."
-				}
-			}
-		}
-		
-		// Check if user is asking for contacts (only if no data fetched yet)
-		if contextData == "" && (strings.Contains(messageLower, "contact") || strings.Contains(messageLower, "kontak") || 
-		   strings.Contains(messageLower, "dokter") || strings.Contains(messageLower, "apoteker")) {
-			// Check data privacy and user permissions
-			allowed, _ := s.checkDataPrivacy("contact", userID)
-			if !allowed {
-				if dataAccessInfo == "" {
-					dataAccessInfo = "⚠️ Akses ke data contacts tidak diizinkan berdasarkan pengaturan privasi data atau permission yang Anda miliki."
-				}
-			} else {
-				contacts, _, err := s.contactRepo.List(&contact.ListContactsRequest{
-					Page:    1,
-					PerPage: 10,
-				})
-				if err == nil && len(contacts) > 0 {
-					contactsJSON, _ := json.Marshal(contacts)
-					if contextData != "" {
-						contextData += "\n\n"
-					}
-					contextData += fmt.Sprintf("REAL CONTACTS DATA FROM DATABASE (showing %d contacts):\n%s\n\nCRITICAL INSTRUCTION: You MUST use ONLY the data above. Present it in a Markdown table format. CRITICAL: NEVER show IDs as separate columns - IDs are ONLY used in clickable links. ALWAYS show ONLY NAMES (name, email, phone, job_title) in tables. For clickable actions that trigger detail components, the Contact Name column MUST be formatted as [Name](contact://id) to allow clicking and opening contact detail. DO NOT create columns like 'ID', 'Contact ID', etc. - these should NOT appear in tables. DO NOT create, invent, or make up any data. AFTER presenting the table, ALWAYS provide 1-2 insights, ask 2-3 follow-up questions to understand what the user wants, and offer actionable recommendations.", len(contacts), string(contactsJSON))
-					if contextType == "" {
-						contextType = "contact"
-					}
-				} else {
-					if dataAccessInfo == "" {
-						dataAccessInfo = "⚠️ Tidak dapat mengakses data contacts dari database. Data mungkin tidak tersedia."
-					}
-				}
-			}
-		}
-		
-		
-		// Check if user is asking for visit reports (only if no data fetched yet)
-		if contextData == "" && (strings.Contains(messageLower, "visit") || strings.Contains(messageLower, "kunjungan") || 
-		   strings.Contains(messageLower, "laporan kunjungan")) {
-			// Check data privacy and user permissions
-			allowed, _ := s.checkDataPrivacy("visit_report", userID)
-			if !allowed {
-				if dataAccessInfo == "" {
-					dataAccessInfo = "⚠️ Akses ke data visit reports tidak diizinkan berdasarkan pengaturan privasi data atau permission yang Anda miliki."
-				}
-			} else {
-				// Build request with optional status filter
-				req := &visit_report.ListVisitReportsRequest{
-					Page:    1,
-					PerPage: 10,
-				}
-				
-				// Extract status filter from message if mentioned
-				if strings.Contains(messageLower, "approved") {
-					req.Status = "approved"
-				} else if strings.Contains(messageLower, "submitted") {
-					req.Status = "submitted"
-				} else if strings.Contains(messageLower, "draft") {
-					req.Status = "draft"
-				} else if strings.Contains(messageLower, "rejected") {
-					req.Status = "rejected"
-				}
-				
-				visitReports, _, err := s.visitReportRepo.List(req)
-				if err == nil && len(visitReports) > 0 {
-					// Transform visit reports to user-friendly format with names
-					visitReportsFormatted := s.formatVisitReportsForAI(visitReports)
-					visitReportsJSON, _ := json.Marshal(visitReportsFormatted)
-					if contextData != "" {
-						contextData += "\n\n"
-					}
-					contextData += fmt.Sprintf("REAL VISIT REPORTS DATA FROM DATABASE (showing %d visit reports):\n%s\n\nCRITICAL INSTRUCTION: You MUST use ONLY the data above. Present it in a Markdown table format. CRITICAL: NEVER show IDs as separate columns - IDs are ONLY used in clickable links. ALWAYS show ONLY NAMES (account_name, contact_name, purpose, status) in tables. For clickable actions that trigger detail components, use format [Name](type://ID) where type is 'visit', 'account', or 'contact'. DO NOT create columns like 'ID', 'Visit Report ID', 'Account ID', etc. - these should NOT appear in tables. DO NOT create, invent, or make up any data. DO NOT add columns that don't exist in the data. AFTER presenting the table, ALWAYS provide 1-2 insights, ask 2-3 follow-up questions to understand what the user wants, and offer actionable recommendations.", len(visitReports), string(visitReportsJSON))
-					if contextType == "" {
-						contextType = "visit_report"
-					}
-				} else {
-					if dataAccessInfo == "" {
-						dataAccessInfo = "⚠️ Tidak dapat mengakses data visit reports dari database. Data mungkin tidak tersedia."
-					}
-				}
-			}
-		}
-		
-		// Check if user is asking for tasks (only if no data fetched yet)
-		if contextData == "" && (strings.Contains(messageLower, "task") || strings.Contains(messageLower, "tugas")) {
-			// Check data privacy and user permissions
-			allowed, _ := s.checkDataPrivacy("task", userID)
-			if !allowed {
-				if dataAccessInfo == "" {
-					dataAccessInfo = "⚠️ Akses ke data tasks tidak diizinkan berdasarkan pengaturan privasi data atau permission yang Anda miliki."
-				}
-			} else {
-				// Build request with optional status filter
-				req := &task.ListTasksRequest{
-					Page:    1,
-					PerPage: 20,
-				}
-				
-				// Extract status filter from message if mentioned
-				if strings.Contains(messageLower, "pending") {
-					req.Status = "pending"
-				} else if strings.Contains(messageLower, "in_progress") || strings.Contains(messageLower, "in-progress") {
-					req.Status = "in_progress"
-				} else if strings.Contains(messageLower, "completed") || strings.Contains(messageLower, "done") {
-					req.Status = "completed"
-				} else if strings.Contains(messageLower, "cancelled") {
-					req.Status = "cancelled"
-				}
-				
-				tasks, _, err := s.taskRepo.List(req)
-				if err == nil && len(tasks) > 0 {
-					tasksJSON, _ := json.Marshal(tasks)
-					if contextData != "" {
-						contextData += "\n\n"
-					}
-					contextData += fmt.Sprintf("REAL TASKS DATA FROM DATABASE (showing %d tasks):\n%s\n\nCRITICAL INSTRUCTION: You MUST use ONLY the data above. Present it in a Markdown table format. CRITICAL: NEVER show IDs as separate columns - IDs are ONLY used in clickable links. ALWAYS show ONLY NAMES (title, account_name, contact_name, status, priority) in tables. For clickable actions that trigger detail components, use format [Name](type://ID) where type is 'task', 'account', or 'contact'. DO NOT create columns like 'ID', 'Task ID', 'Account ID', etc. - these should NOT appear in tables. DO NOT create, invent, or make up any data. AFTER presenting the table, ALWAYS provide 1-2 insights, ask 2-3 follow-up questions to understand what the user wants, and offer actionable recommendations.", len(tasks), string(tasksJSON))
-					if contextType == "" {
-						contextType = "task"
-					}
-				} else {
-					if dataAccessInfo == "" {
-						dataAccessInfo = "⚠️ Tidak dapat mengakses data tasks dari database. Data mungkin tidak tersedia."
-					}
-				}
-			}
-		}
-		
-		// Check if user is asking for forecast data (only if no data fetched yet)
-		if contextData == "" && (strings.Contains(messageLower, "forecast") || strings.Contains(messageLower, "grafik forecast") || 
-		   strings.Contains(messageLower, "prediksi") || strings.Contains(messageLower, "ramalan")) {
-			now := time.Now()
-			
-			// Check for specific forecast queries
-			isNextMonthQuery := strings.Contains(messageLower, "bulan depan") || strings.Contains(messageLower, "next month") || 
-			                    strings.Contains(messageLower, "month depan") || strings.Contains(messageLower, "bulan berikutnya")
-			isThreeMonthsQuery := strings.Contains(messageLower, "3 bulan") || strings.Contains(messageLower, "tiga bulan") ||
-			                      strings.Contains(messageLower, "three months") || strings.Contains(messageLower, "3 months")
-			isQuarterQuery := strings.Contains(messageLower, "kuartal") || strings.Contains(messageLower, "quarter") ||
-			                  strings.Contains(messageLower, "triwulan")
-			isYearQuery := strings.Contains(messageLower, "tahun") && (strings.Contains(messageLower, "ini") || 
-			              strings.Contains(messageLower, "depan") || strings.Contains(messageLower, "year"))
-			
-			var forecastStart, forecastEnd time.Time
-			var periodType string
-			
-			if isNextMonthQuery {
-				// Next month forecast
-				nextMonth := now.AddDate(0, 1, 0)
-				forecastStart = time.Date(nextMonth.Year(), nextMonth.Month(), 1, 0, 0, 0, 0, now.Location())
-				forecastEnd = forecastStart.AddDate(0, 1, 0).Add(-time.Second)
-				periodType = "month"
-			} else if isThreeMonthsQuery {
-				// 3 months ahead forecast
-				forecastStart = now
-				forecastEnd = now.AddDate(0, 3, 0)
-				periodType = "quarter"
-			} else if isQuarterQuery {
-				// Quarter forecast - check if next quarter or current
-				if strings.Contains(messageLower, "depan") || strings.Contains(messageLower, "berikutnya") || strings.Contains(messageLower, "next") {
-					// Next quarter
-					quarter := (now.Month() - 1) / 3
-					nextQuarter := quarter + 1
-					if nextQuarter >= 4 {
-						nextQuarter = 0
-						forecastStart = time.Date(now.Year()+1, 1, 1, 0, 0, 0, 0, now.Location())
-					} else {
-						forecastStart = time.Date(now.Year(), nextQuarter*3+1, 1, 0, 0, 0, 0, now.Location())
-					}
-					forecastEnd = forecastStart.AddDate(0, 3, 0).Add(-time.Second)
-				} else {
-					// Current quarter
-					quarter := (now.Month() - 1) / 3
-					forecastStart = time.Date(now.Year(), quarter*3+1, 1, 0, 0, 0, 0, now.Location())
-					forecastEnd = forecastStart.AddDate(0, 3, 0).Add(-time.Second)
-				}
-				periodType = "quarter"
-			} else if isYearQuery {
-				// Year forecast
-				if strings.Contains(messageLower, "depan") || strings.Contains(messageLower, "berikutnya") || strings.Contains(messageLower, "next") {
-					// Next year
-					forecastStart = time.Date(now.Year()+1, 1, 1, 0, 0, 0, 0, now.Location())
-				} else {
-					// Current year
-					forecastStart = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
-				}
-				forecastEnd = forecastStart.AddDate(1, 0, 0).Add(-time.Second)
-				periodType = "year"
-			} else {
-				// Default: current month forecast
-				forecastStart = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-				forecastEnd = forecastStart.AddDate(0, 1, 0).Add(-time.Second)
-				periodType = "month"
-			}
-			
-			forecast, err := s.dealRepo.GetForecast(periodType, forecastStart, forecastEnd)
-			
-			if err == nil && forecast != nil {
-				forecastJSON, _ := json.Marshal(forecast)
-				if contextData != "" {
-					contextData += "\n\n"
-				}
-				periodDesc := "Current Month"
-				if isNextMonthQuery {
-					periodDesc = "Next Month"
-				} else if isThreeMonthsQuery {
-					periodDesc = "Next 3 Months"
-				} else if isQuarterQuery {
-					if strings.Contains(messageLower, "depan") || strings.Contains(messageLower, "berikutnya") || strings.Contains(messageLower, "next") {
-						periodDesc = "Next Quarter"
-					} else {
-						periodDesc = "Current Quarter"
-					}
-				} else if isYearQuery {
-					if strings.Contains(messageLower, "depan") || strings.Contains(messageLower, "berikutnya") || strings.Contains(messageLower, "next") {
-						periodDesc = "Next Year"
-					} else {
-						periodDesc = "Current Year"
-					}
-				}
-				contextData += fmt.Sprintf("REAL FORECAST DATA FROM DATABASE (%s):\n%s\n\nCRITICAL: You MUST use ONLY this forecast data. DO NOT create, invent, or make up any forecast data. If forecast data is empty or incomplete, inform the user that forecast data is not available.", periodDesc, string(forecastJSON))
-			} else {
-				if dataAccessInfo == "" {
-					dataAccessInfo = "⚠️ Tidak dapat mengakses data forecast dari database. Data mungkin tidak tersedia."
-				}
-			}
-		}
-		
-		// If no specific data type detected but user is asking for general data, default to accounts
-		// BUT: Skip if message contains specific data type keywords (lead, deal, account, etc.)
-		hasSpecificDataType := strings.Contains(messageLower, "lead") || 
-		                       strings.Contains(messageLower, "deal") || 
-		                       strings.Contains(messageLower, "pipeline") ||
-		                       strings.Contains(messageLower, "account") ||
-		                       strings.Contains(messageLower, "contact") ||
-		                       strings.Contains(messageLower, "visit") ||
-		                       strings.Contains(messageLower, "task") ||
-		                       strings.Contains(messageLower, "product")
-		
-		if contextData == "" && !hasSpecificDataType && (strings.Contains(messageLower, "data") || strings.Contains(messageLower, "paparkan") || 
-		   strings.Contains(messageLower, "tampilkan") || strings.Contains(messageLower, "lihat") ||
-		   strings.Contains(messageLower, "sistem") || strings.Contains(messageLower, "database")) {
-			// Check data privacy and user permissions
-			allowed, _ := s.checkDataPrivacy("account", userID)
-			if !allowed {
-				dataAccessInfo = "⚠️ Akses ke data accounts tidak diizinkan berdasarkan pengaturan privasi data atau permission yang Anda miliki."
-			} else {
-				accounts, total, err := s.accountRepo.List(&account.ListAccountsRequest{
-					Page:    1,
-					PerPage: 10,
-				})
-				if err == nil && len(accounts) > 0 {
-					// Transform accounts to user-friendly format with names
-					accountsFormatted := s.formatAccountsForAI(accounts)
-					accountsJSON, _ := json.Marshal(accountsFormatted)
-					contextData = fmt.Sprintf("REAL ACCOUNTS DATA FROM DATABASE (showing %d of %d total accounts):\n%s\n\nCRITICAL INSTRUCTION: You MUST use ONLY the data above. Present it in a Markdown table format. CRITICAL: NEVER show IDs as separate columns - IDs are ONLY used in clickable links. ALWAYS show ONLY NAMES (name, category, city, province) in tables. For clickable actions that trigger detail components, the 'Nama Akun' (Name) column MUST be formatted as [Name](account://id) to create clickable links. Example: [RSUD Jakarta](account://ab868b77-e9b3-429f-ad8c-d55ac1f6561b). Use the EXACT id from the data above - DO NOT create or invent IDs. DO NOT create columns like 'ID', 'Account ID', etc. - these should NOT appear in tables. DO NOT create, invent, or make up any data. DO NOT add columns that don't exist in the data. AFTER presenting the table, ALWAYS provide 1-2 insights, ask 2-3 follow-up questions to understand what the user wants, and offer actionable recommendations.", len(accounts), total, string(accountsJSON))
-					contextType = "account"
-				} else {
-					if dataAccessInfo == "" {
-						dataAccessInfo = "⚠️ Tidak dapat mengakses data dari database. Data mungkin tidak tersedia."
-					}
-				}
-			}
-		}
 	}
 
 	// Get current time in configured timezone
@@ -946,35 +441,30 @@ func (s *Service) Chat(message string, contextID string, contextType string, con
 	if err != nil {
 		// If timezone is invalid, use UTC
 		loc = time.UTC
-		fmt.Printf("Warning: Invalid timezone '%s', using UTC instead\n", timezone)
+		log.Printf("Warning: invalid timezone %q, using UTC instead", timezone)
 	}
 	
 	currentTime := time.Now().In(loc)
 	
 	// Build system prompt based on context
 	systemPrompt := BuildSystemPrompt(contextID, contextType, contextData, dataAccessInfo, selectedModel, settings.Provider, currentTime, timezone)
+	systemPrompt += "\n\n" + BuildToolInstructions()
 
 	// Build messages with conversation history
-	messages := []cerebras.ChatMessage{
+	messages := []llm.Message{
 		{
 			Role:    "system",
 			Content: systemPrompt,
 		},
 	}
 
-	// Add conversation history (limit to last 10 messages to avoid token limit)
-	// Note: No dynamic reduction based on context size - AI will handle overflow automatically
-	historyLimit := 10
-	startIdx := 0
-	if len(conversationHistory) > historyLimit {
-		startIdx = len(conversationHistory) - historyLimit
-	}
-	
-	for i := startIdx; i < len(conversationHistory); i++ {
-		msg := conversationHistory[i]
+	// Add conversation history, keeping the most recent messages that fit the history token budget
+	conversationHistory = trimHistoryToTokenBudget(conversationHistory, chatHistoryTokenBudget)
+
+	for _, msg := range conversationHistory {
 		// Skip system messages from history (only include user and assistant)
 		if msg.Role == "user" || msg.Role == "assistant" {
-			messages = append(messages, cerebras.ChatMessage{
+			messages = append(messages, llm.Message{
 				Role:    msg.Role,
 				Content: msg.Content,
 			})
@@ -982,11 +472,43 @@ func (s *Service) Chat(message string, contextID string, contextType string, con
 	}
 
 	// Add current user message
-	messages = append(messages, cerebras.ChatMessage{
+	messages = append(messages, llm.Message{
 		Role:    "user",
 		Content: message,
 	})
 
+	// Cerebras model names from the UI dropdown are normalized; other providers receive the model as configured
+	if provider.Name() == llm.ProviderCerebras {
+		selectedModel, err = normalizeCerebrasModel(selectedModel)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Calculate optimal MaxTokens based on context size
+	// If context data is large, reduce max tokens to avoid hitting total context limit
+	maxTokens := 4000 // Increased default for longer responses
+	if len(contextData) > 50000 { // Large context (>50KB)
+		maxTokens = 3000
+	} else if len(contextData) > 100000 { // Very large context (>100KB)
+		maxTokens = 2000
+	}
+
+	return &preparedChat{
+		provider: provider,
+		request: &llm.ChatRequest{
+			Model:       selectedModel, // Pass the selected model
+			Messages:    messages,
+			MaxTokens:   maxTokens,
+			Temperature: 0.7,
+			Tools:       chatToolDefinitions(),
+		},
+		dataAccessInfo: dataAccessInfo,
+	}, nil
+}
+
+// normalizeCerebrasModel maps model names from the UI dropdown to Cerebras model IDs
+func normalizeCerebrasModel(selectedModel string) (string, error) {
 	// Normalize model name to lowercase for consistent matching
 	originalModel := selectedModel
 	selectedModel = strings.ToLower(selectedModel)
@@ -1026,22 +548,32 @@ func (s *Service) Chat(message string, contextID string, contextType string, con
 		// Model not found in available models
 		// Check if it's a GPT model (not GPT-OSS)
 		if strings.HasPrefix(selectedModel, "gpt-") && selectedModel != "gpt-oss-120b" {
-			return nil, fmt.Errorf("model '%s' tidak didukung. Model yang tersedia: llama-3.1-8b, llama-3.3-70b, qwen-3-32b, qwen3-235b, gpt-oss-120b, zai-glm-4.6. Silakan pilih model yang valid.", originalModel)
+			return "", fmt.Errorf("model '%s' tidak didukung. Model yang tersedia: llama-3.1-8b, llama-3.3-70b, qwen-3-32b, qwen3-235b, gpt-oss-120b, zai-glm-4.6. Silakan pilih model yang valid.", originalModel)
 		}
 		// For other unknown models, let the API handle it (might be valid but not in our map)
 	}
 
-	// Calculate optimal MaxTokens based on context size
-	// If context data is large, reduce max tokens to avoid hitting total context limit
-	maxTokens := 4000 // Increased default for longer responses
-	if len(contextData) > 50000 { // Large context (>50KB)
-		maxTokens = 3000
-	} else if len(contextData) > 100000 { // Very large context (>100KB)
-		maxTokens = 2000
+	return selectedModel, nil
+}
+
+// preparedChat holds a chat completion request ready to be sent to the model
+type preparedChat struct {
+	provider       llm.Provider
+	request        *llm.ChatRequest
+	dataAccessInfo string
+}
+
+// complete answers a chat message given its conversation history
+// userID is required to check user permissions for data access
+// When the model was called but the answer failed, the response carries the tokens used alongside the error.
+func (s *Service) complete(message string, contextID string, contextType string, conversationHistory []ai.ChatMessage, model string, userID string) (*ai.ChatResponse, error) {
+	prepared, err := s.prepareChat(message, contextID, contextType, conversationHistory, model, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Call Cerebras API with error handling and panic recovery
-	var response *cerebras.ChatResponse
+	// Call the provider with error handling and panic recovery, running the tools the model asks for
+	var response *llm.ChatResponse
 	var apiErr error
 	
 	// Add panic recovery for API calls
@@ -1052,36 +584,11 @@ func (s *Service) Chat(message string, contextID string, contextType string, con
 			}
 		}()
 		
-		response, apiErr = s.cerebrasClient.Chat(&cerebras.ChatRequest{
-			Model:      selectedModel, // Pass the selected model
-			Messages:   messages,
-			MaxTokens:  maxTokens,
-			Temperature: 0.7,
-		})
+		response, apiErr = s.runToolLoop(prepared.request, userID, prepared.provider.Chat)
 	}()
 	
 	if apiErr != nil {
-		fmt.Printf("=== CEREBRAS API ERROR ===\n")
-		fmt.Printf("Error: %v\n", apiErr)
-		fmt.Printf("Error type: %T\n", apiErr)
-		fmt.Printf("User message: %s\n", message)
-		fmt.Printf("Selected model: %s\n", selectedModel)
-		fmt.Printf("==========================\n")
-		
-		// Check if error is model not found
-		errorStr := apiErr.Error()
-		if strings.Contains(errorStr, "model_not_found") || 
-		   strings.Contains(errorStr, "does not exist") || 
-		   strings.Contains(errorStr, "not found") {
-			return nil, fmt.Errorf("model '%s' tidak ditemukan atau tidak tersedia. Model yang tersedia: llama-3.1-8b, llama-3.1-70b. Silakan pilih model yang valid.", selectedModel)
-		}
-		
-		// Check if error is about GPT models
-		if strings.Contains(errorStr, "gpt-") {
-			return nil, fmt.Errorf("model '%s' tidak didukung. Cerebras API hanya mendukung model Cerebras (contoh: llama-3.1-8b, llama-3.1-70b). Silakan pilih model Cerebras yang valid.", selectedModel)
-		}
-		
-		return nil, fmt.Errorf("gagal menghasilkan respons: %w", apiErr)
+		return usedTokens(response, prepared.request.Model), chatAPIError(apiErr, prepared.request.Model)
 	}
 	
 	// Validate response
@@ -1091,21 +598,105 @@ func (s *Service) Chat(message string, contextID string, contextType string, con
 	
 	// Check if Message is nil (defensive check)
 	if response.Message.Content == "" {
-		return nil, fmt.Errorf("empty message content from AI service")
+		return usedTokens(response, prepared.request.Model), fmt.Errorf("empty message content from AI service")
 	}
 
 	// Add data access info to response if needed
 	finalMessage := response.Message.Content
-	if dataAccessInfo != "" && !strings.Contains(finalMessage, dataAccessInfo) {
+	if dataAccessInfo := prepared.dataAccessInfo; dataAccessInfo != "" && !strings.Contains(finalMessage, dataAccessInfo) {
 		finalMessage = dataAccessInfo + "\n\n" + finalMessage
 	}
 
 	return &ai.ChatResponse{
 		Message: finalMessage,
 		Tokens:  response.Tokens,
+		Model:   prepared.request.Model,
+	}, nil
+}
+
+// completeStream answers a chat message like complete, but forwards the answer to onDelta as it is generated.
+// The returned response carries the full message and the token usage reported at the end of the stream.
+// Like complete, it returns the tokens used alongside the error when the stream fails or is aborted.
+func (s *Service) completeStream(ctx context.Context, message string, contextID string, contextType string, conversationHistory []ai.ChatMessage, model string, userID string, onDelta func(content string) error) (*ai.ChatResponse, error) {
+	prepared, err := s.prepareChat(message, contextID, contextType, conversationHistory, model, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Data access info is sent first since the full answer is not known up front
+	var streamed strings.Builder
+	if prepared.dataAccessInfo != "" {
+		streamed.WriteString(prepared.dataAccessInfo + "\n\n")
+		if err := onDelta(streamed.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Text the model writes before calling tools is streamed too, so the stored answer is everything the user saw
+	response, apiErr := s.runToolLoop(prepared.request, userID, func(req *llm.ChatRequest) (*llm.ChatResponse, error) {
+		var round strings.Builder
+		roundResponse, err := prepared.provider.ChatStream(ctx, req, func(content string) error {
+			streamed.WriteString(content)
+			round.WriteString(content)
+			return onDelta(content)
+		})
+		// Usage is only reported at the end of a stream; estimate a round that was cut off after the model answered
+		if err != nil && roundResponse == nil && round.Len() > 0 {
+			roundResponse = &llm.ChatResponse{Tokens: estimateRequestTokens(req) + estimateTokens(round.String())}
+		}
+		return roundResponse, err
+	})
+	if apiErr != nil {
+		return usedTokens(response, prepared.request.Model), chatAPIError(apiErr, prepared.request.Model)
+	}
+	if response.Message.Content == "" {
+		return usedTokens(response, prepared.request.Model), fmt.Errorf("empty message content from AI service")
+	}
+
+	return &ai.ChatResponse{
+		Message: streamed.String(),
+		Tokens:  response.Tokens,
+		Model:   prepared.request.Model,
 	}, nil
 }
 
+// usedTokens returns the token usage of a failed chat completion, or nil when no tokens were used
+func usedTokens(response *llm.ChatResponse, model string) *ai.ChatResponse {
+	if response == nil || response.Tokens == 0 {
+		return nil
+	}
+	return &ai.ChatResponse{Tokens: response.Tokens, Model: model}
+}
+
+// estimateRequestTokens approximates the prompt size of a chat request
+func estimateRequestTokens(req *llm.ChatRequest) int {
+	tokens := 0
+	for _, m := range req.Messages {
+		tokens += estimateTokens(m.Content)
+	}
+	return tokens
+}
+
+// chatAPIError logs a failed chat completion and converts it into a user-facing error
+func chatAPIError(apiErr error, selectedModel string) error {
+	log.Printf("AI chat completion failed (model %s, %T): %v", selectedModel, apiErr, apiErr)
+	
+	// Check if error is model not found
+	errorStr := apiErr.Error()
+	if strings.Contains(errorStr, "model_not_found") || 
+	   strings.Contains(errorStr, "does not exist") || 
+	   strings.Contains(errorStr, "not found") {
+		return fmt.Errorf("model '%s' tidak ditemukan atau tidak tersedia. Model yang tersedia: llama-3.1-8b, llama-3.1-70b. Silakan pilih model yang valid.", selectedModel)
+	}
+	
+	// Check if error is about GPT models
+	if strings.Contains(errorStr, "gpt-") {
+		return fmt.Errorf("model '%s' tidak didukung. Cerebras API hanya mendukung model Cerebras (contoh: llama-3.1-8b, llama-3.1-70b). Silakan pilih model Cerebras yang valid.", selectedModel)
+	}
+	
+	return fmt.Errorf("gagal menghasilkan respons: %w", apiErr)
+}
+
 // Helper function for min
 func min(a, b int) int {
 	if a < b {
@@ -1488,8 +1079,9 @@ func (s *Service) formatLeadsForAI(leads []lead.Lead) []LeadFormatted {
 	return formatted
 }
 
-// parseVisitReportInsight parses AI response into VisitReportInsight
-func (s *Service) parseVisitReportInsight(text string) (*ai.VisitReportInsight, error) {
+// parseVisitReportInsight parses AI response into VisitReportInsight.
+// Action items get stable IDs and a suggested priority and due date relative to now.
+func (s *Service) parseVisitReportInsight(text string, visitReportID string, now time.Time) (*ai.VisitReportInsight, error) {
 	// Clean up the text: remove comment markers and extra whitespace
 	cleaned := strings.TrimSpace(text)
 	
@@ -1526,7 +1118,7 @@ func (s *Service) parseVisitReportInsight(text string) (*ai.VisitReportInsight,
 	// Build the insight struct, handling different data types
 	insight := &ai.VisitReportInsight{
 		Summary:     "",
-		ActionItems: []string{},
+		ActionItems: []ai.ActionItem{},
 		Sentiment:   "neutral",
 		KeyPoints:   []string{},
 		Recommendations: []string{},
@@ -1553,16 +1145,28 @@ func (s *Service) parseVisitReportInsight(text string) (*ai.VisitReportInsight,
 
 	// Extract action_items (can be array of strings or array of objects)
 	if actionItems, ok := rawInsight["action_items"].([]interface{}); ok {
+		seen := make(map[string]bool)
 		for _, item := range actionItems {
+			var actionItem ai.ActionItem
 			if str, ok := item.(string); ok {
 				// Simple string
-				insight.ActionItems = append(insight.ActionItems, str)
+				actionItem = newActionItem(visitReportID, str, "", 0, now)
 			} else if obj, ok := item.(map[string]interface{}); ok {
-				// Object with description and urgency - convert to string
-				if desc, ok := obj["description"].(string); ok {
-					insight.ActionItems = append(insight.ActionItems, desc)
+				// Object with text, priority and due date in days
+				text, _ := obj["text"].(string)
+				if text == "" {
+					text, _ = obj["description"].(string)
 				}
+				priority, _ := obj["priority"].(string)
+				dueInDays, _ := obj["due_in_days"].(float64)
+				actionItem = newActionItem(visitReportID, text, priority, int(dueInDays), now)
 			}
+			// Skip empty items and repeated items, which would share an ID
+			if actionItem.Text == "" || seen[actionItem.ID] {
+				continue
+			}
+			seen[actionItem.ID] = true
+			insight.ActionItems = append(insight.ActionItems, actionItem)
 		}
 	}
 
@@ -1578,3 +1182,17 @@ func (s *Service) parseVisitReportInsight(text string) (*ai.VisitReportInsight,
 	return insight, nil
 }
 
+
+// newActionItem builds an action item of a visit report with a valid priority and a suggested due date
+func newActionItem(visitReportID string, text string, priority string, dueInDays int, now time.Time) ai.ActionItem {
+	text = strings.TrimSpace(text)
+	priority = ai.NormalizeActionItemPriority(priority)
+	dueDate := ai.SuggestedDueDate(priority, dueInDays, now)
+
+	return ai.ActionItem{
+		ID:               ai.ActionItemID(visitReportID, text),
+		Text:             text,
+		Priority:         priority,
+		SuggestedDueDate: &dueDate,
+	}
+}
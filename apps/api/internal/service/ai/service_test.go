@@ -0,0 +1,60 @@
+package ai
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai"
+)
+
+func TestParseVisitReportInsight_ActionItems(t *testing.T) {
+	now := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+	text := `{
+  "summary": "Good visit",
+  "sentiment": "positive",
+  "key_points": ["Interest in MRI"],
+  "action_items": [
+    {"text": "Send revised quotation", "priority": "high", "due_in_days": 2},
+    "Schedule product demo",
+    {"description": "Send  revised quotation", "priority": "low"},
+    {"text": "Call procurement", "priority": "asap"}
+  ],
+  "recommendations": ["Engage procurement early"]
+}`
+
+	service := &Service{}
+	insight, err := service.parseVisitReportInsight(text, "report-1", now)
+	if err != nil {
+		t.Fatalf("parseVisitReportInsight returned error: %v", err)
+	}
+
+	if len(insight.ActionItems) != 3 {
+		t.Fatalf("expected 3 action items (repeated item skipped), got %d: %+v", len(insight.ActionItems), insight.ActionItems)
+	}
+
+	quotation := insight.ActionItems[0]
+	if quotation.ID != ai.ActionItemID("report-1", "send revised   QUOTATION") {
+		t.Errorf("expected ID to ignore case and spacing, got %s", quotation.ID)
+	}
+	if quotation.Priority != "high" {
+		t.Errorf("expected priority high, got %s", quotation.Priority)
+	}
+	if want := time.Date(2024, 5, 8, 17, 0, 0, 0, time.UTC); !quotation.SuggestedDueDate.Equal(want) {
+		t.Errorf("expected due date %v, got %v", want, quotation.SuggestedDueDate)
+	}
+
+	demo := insight.ActionItems[1]
+	if demo.Priority != "medium" {
+		t.Errorf("expected default priority medium, got %s", demo.Priority)
+	}
+	if want := time.Date(2024, 5, 13, 17, 0, 0, 0, time.UTC); !demo.SuggestedDueDate.Equal(want) {
+		t.Errorf("expected due date from priority %v, got %v", want, demo.SuggestedDueDate)
+	}
+
+	if insight.ActionItems[2].Priority != "medium" {
+		t.Errorf("expected unknown priority to fall back to medium, got %s", insight.ActionItems[2].Priority)
+	}
+	if ai.ActionItemID("report-2", "Send revised quotation") == quotation.ID {
+		t.Error("expected IDs to differ between visit reports")
+	}
+}
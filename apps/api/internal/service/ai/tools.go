@@ -0,0 +1,489 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/task"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/visit_report"
+	"github.com/gilabs/crm-healthcare/api/pkg/llm"
+)
+
+const (
+	// defaultToolResultLimit is the number of records a tool returns when the model does not ask for a limit
+	defaultToolResultLimit = 10
+
+	// maxToolResultLimit caps the number of records a tool returns so results fit the context window
+	maxToolResultLimit = 25
+)
+
+// chatTool is a CRM query the model can call while answering a chat message
+type chatTool struct {
+	definition llm.Tool
+	// dataType is checked against AI data privacy settings and the user's permissions before the tool runs;
+	// empty for tools that do not read CRM records
+	dataType string
+	run      func(s *Service, args json.RawMessage, userID string) (interface{}, error)
+}
+
+// toolListResult is the result of a tool returning a list of records
+type toolListResult struct {
+	Total int64       `json:"total"`
+	Items interface{} `json:"items"`
+}
+
+// toolErrorResult tells the model why a tool call returned no data
+type toolErrorResult struct {
+	Error string `json:"error"`
+}
+
+// chatTools are the CRM queries available to the model, keyed by name
+var chatTools = map[string]chatTool{}
+
+func init() {
+	for _, t := range []chatTool{
+		{
+			definition: llm.Tool{
+				Name:        "search_accounts",
+				Description: "Search healthcare accounts (hospitals, clinics, pharmacies, distributors) by name. Returns name, category, city, province and status.",
+				Parameters: objectSchema(map[string]interface{}{
+					"query":  stringProperty("Text to search in the account name; empty lists all accounts"),
+					"status": enumProperty("Account status", "active", "inactive"),
+					"limit":  limitProperty(),
+				}),
+			},
+			dataType: "account",
+			run:      (*Service).toolSearchAccounts,
+		},
+		{
+			definition: llm.Tool{
+				Name:        "list_deals_by_stage",
+				Description: "List sales pipeline deals, optionally filtered by stage and status. Returns title, account, stage, value, probability and expected close date.",
+				Parameters: objectSchema(map[string]interface{}{
+					"stage":    stringProperty("Pipeline stage code or name, e.g. qualification, proposal, negotiation, closed_won, closed_lost"),
+					"pipeline": stringProperty("Pipeline name or code the stage belongs to; needed when several pipelines have a stage with that name"),
+					"status":   enumProperty("Deal status", "open", "won", "lost"),
+					"search":   stringProperty("Text to search in the deal title"),
+					"limit":    limitProperty(),
+				}),
+			},
+			dataType: "deal",
+			run:      (*Service).toolListDealsByStage,
+		},
+		{
+			definition: llm.Tool{
+				Name:        "get_overdue_tasks",
+				Description: "List pending or in-progress tasks whose due date has passed, oldest due date first.",
+				Parameters: objectSchema(map[string]interface{}{
+					"assigned_to_me": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Only tasks assigned to the current user",
+					},
+					"limit": limitProperty(),
+				}),
+			},
+			dataType: "task",
+			run:      (*Service).toolGetOverdueTasks,
+		},
+		{
+			definition: llm.Tool{
+				Name:        "get_account_visit_reports",
+				Description: "List the most recent visit reports of an account. Use search_accounts first to find the account ID.",
+				Parameters: objectSchema(map[string]interface{}{
+					"account_id": stringProperty("Account ID returned by search_accounts"),
+					"status":     enumProperty("Visit report status", "draft", "submitted", "approved", "rejected"),
+					"limit":      limitProperty(),
+				}, "account_id"),
+			},
+			dataType: "visit_report",
+			run:      (*Service).toolGetAccountVisitReports,
+		},
+		{
+			definition: llm.Tool{
+				Name:        "search_leads",
+				Description: "Search leads by name, company or email, optionally filtered by status. Returns name, company, source, status and score.",
+				Parameters: objectSchema(map[string]interface{}{
+					"query":  stringProperty("Text to search in lead name, company or email"),
+					"status": enumProperty("Lead status", "new", "contacted", "qualified", "unqualified", "nurturing", "disqualified", "converted", "lost"),
+					"limit":  limitProperty(),
+				}),
+			},
+			dataType: "lead",
+			run:      (*Service).toolSearchLeads,
+		},
+		{
+			definition: llm.Tool{
+				Name:        "get_pipeline_forecast",
+				Description: "Get the revenue forecast of open deals expected to close in a period.",
+				Parameters: objectSchema(map[string]interface{}{
+					"period": enumProperty("Forecast period", "current_month", "next_month", "next_3_months", "current_quarter", "next_quarter", "current_year", "next_year"),
+				}, "period"),
+			},
+			dataType: "deal",
+			run:      (*Service).toolGetPipelineForecast,
+		},
+		{
+			definition: llm.Tool{
+				Name:        "get_data_access_settings",
+				Description: "Get which CRM data types the assistant may read for the current user, based on AI data privacy settings and user permissions.",
+				Parameters:  objectSchema(map[string]interface{}{}),
+			},
+			run: (*Service).toolGetDataAccessSettings,
+		},
+	} {
+		chatTools[t.definition.Name] = t
+	}
+}
+
+// chatToolDefinitions returns the tool definitions sent to the model, sorted by name
+func chatToolDefinitions() []llm.Tool {
+	definitions := make([]llm.Tool, 0, len(chatTools))
+	for _, t := range chatTools {
+		definitions = append(definitions, t.definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Name < definitions[j].Name })
+	return definitions
+}
+
+// runChatTool executes a tool call after checking data privacy settings and permissions.
+// The result is JSON for the model; failures are reported to the model instead of aborting the chat.
+func (s *Service) runChatTool(call llm.ToolCall, userID string) string {
+	tool, ok := chatTools[call.Name]
+	if !ok {
+		return toolResultJSON(&toolErrorResult{Error: fmt.Sprintf("unknown tool %q", call.Name)})
+	}
+
+	if tool.dataType != "" && !s.isDataAllowed(tool.dataType, userID) {
+		return toolResultJSON(&toolErrorResult{Error: fmt.Sprintf("access to %s data is not allowed by AI data privacy settings or the user's permissions", tool.dataType)})
+	}
+
+	args := json.RawMessage(call.Arguments)
+	if strings.TrimSpace(call.Arguments) == "" {
+		args = json.RawMessage("{}")
+	}
+
+	result, err := tool.run(s, args, userID)
+	if err != nil {
+		return toolResultJSON(&toolErrorResult{Error: err.Error()})
+	}
+	return toolResultJSON(result)
+}
+
+func (s *Service) toolSearchAccounts(raw json.RawMessage, userID string) (interface{}, error) {
+	var args struct {
+		Query  string `json:"query"`
+		Status string `json:"status"`
+		Limit  int    `json:"limit"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	accounts, total, err := s.accountRepo.List(&account.ListAccountsRequest{
+		Page:    1,
+		PerPage: toolLimit(args.Limit),
+		Search:  args.Query,
+		Status:  args.Status,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search accounts")
+	}
+	return &toolListResult{Total: total, Items: s.formatAccountsForAI(accounts)}, nil
+}
+
+func (s *Service) toolListDealsByStage(raw json.RawMessage, userID string) (interface{}, error) {
+	var args struct {
+		Stage    string `json:"stage"`
+		Pipeline string `json:"pipeline"`
+		Status   string `json:"status"`
+		Search   string `json:"search"`
+		Limit    int    `json:"limit"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	req := &pipeline.ListDealsRequest{
+		Page:    1,
+		PerPage: toolLimit(args.Limit),
+		Search:  args.Search,
+		Status:  args.Status,
+	}
+	if args.Stage != "" {
+		stage, err := s.findStage(args.Stage, args.Pipeline)
+		if err != nil {
+			return nil, err
+		}
+		req.StageID = stage.ID
+	}
+
+	deals, total, err := s.dealRepo.List(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deals")
+	}
+	return &toolListResult{Total: total, Items: s.formatDealsForAI(deals)}, nil
+}
+
+func (s *Service) toolGetOverdueTasks(raw json.RawMessage, userID string) (interface{}, error) {
+	var args struct {
+		AssignedToMe bool `json:"assigned_to_me"`
+		Limit        int  `json:"limit"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var overdue []task.Task
+	for _, status := range []string{"pending", "in_progress"} {
+		req := &task.ListTasksRequest{
+			Page:      1,
+			PerPage:   100,
+			Status:    status,
+			DueDateTo: &now,
+		}
+		if args.AssignedToMe {
+			req.AssignedTo = userID
+		}
+		tasks, _, err := s.taskRepo.List(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tasks")
+		}
+		overdue = append(overdue, tasks...)
+	}
+
+	sort.Slice(overdue, func(i, j int) bool {
+		return overdue[i].DueDate != nil && (overdue[j].DueDate == nil || overdue[i].DueDate.Before(*overdue[j].DueDate))
+	})
+	total := int64(len(overdue))
+	if limit := toolLimit(args.Limit); len(overdue) > limit {
+		overdue = overdue[:limit]
+	}
+	return &toolListResult{Total: total, Items: s.formatTasksForAI(overdue)}, nil
+}
+
+func (s *Service) toolGetAccountVisitReports(raw json.RawMessage, userID string) (interface{}, error) {
+	var args struct {
+		AccountID string `json:"account_id"`
+		Status    string `json:"status"`
+		Limit     int    `json:"limit"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	if args.AccountID == "" {
+		return nil, fmt.Errorf("account_id is required")
+	}
+
+	// The account lookup is limited to the user's data scope like the visit reports themselves
+	if _, err := s.accountRepo.FindByID(args.AccountID); err != nil {
+		return nil, fmt.Errorf("account %s not found", args.AccountID)
+	}
+
+	visitReports, total, err := s.visitReportRepo.List(&visit_report.ListVisitReportsRequest{
+		Page:      1,
+		PerPage:   toolLimit(args.Limit),
+		AccountID: args.AccountID,
+		Status:    args.Status,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list visit reports")
+	}
+	return &toolListResult{Total: total, Items: s.formatVisitReportsForAI(visitReports)}, nil
+}
+
+func (s *Service) toolSearchLeads(raw json.RawMessage, userID string) (interface{}, error) {
+	var args struct {
+		Query  string `json:"query"`
+		Status string `json:"status"`
+		Limit  int    `json:"limit"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	leads, total, err := s.leadRepo.List(&lead.ListLeadsRequest{
+		Page:    1,
+		PerPage: toolLimit(args.Limit),
+		Search:  args.Query,
+		Status:  args.Status,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search leads")
+	}
+	return &toolListResult{Total: total, Items: s.formatLeadsForAI(leads)}, nil
+}
+
+func (s *Service) toolGetPipelineForecast(raw json.RawMessage, userID string) (interface{}, error) {
+	var args struct {
+		Period string `json:"period"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	quarterStart := time.Date(now.Year(), (now.Month()-1)/3*3+1, 1, 0, 0, 0, 0, now.Location())
+	yearStart := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+
+	var start, end time.Time
+	periodType := "month"
+	switch args.Period {
+	case "", "current_month":
+		start, end = monthStart, monthStart.AddDate(0, 1, 0)
+	case "next_month":
+		start = monthStart.AddDate(0, 1, 0)
+		end = start.AddDate(0, 1, 0)
+	case "next_3_months":
+		start, end = now, now.AddDate(0, 3, 0)
+		periodType = "quarter"
+	case "current_quarter":
+		start, end = quarterStart, quarterStart.AddDate(0, 3, 0)
+		periodType = "quarter"
+	case "next_quarter":
+		start = quarterStart.AddDate(0, 3, 0)
+		end = start.AddDate(0, 3, 0)
+		periodType = "quarter"
+	case "current_year":
+		start, end = yearStart, yearStart.AddDate(1, 0, 0)
+		periodType = "year"
+	case "next_year":
+		start = yearStart.AddDate(1, 0, 0)
+		end = start.AddDate(1, 0, 0)
+		periodType = "year"
+	default:
+		return nil, fmt.Errorf("unknown period %q", args.Period)
+	}
+
+	forecast, err := s.dealRepo.GetForecast(periodType, start, end.Add(-time.Second), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forecast")
+	}
+	return forecast, nil
+}
+
+func (s *Service) toolGetDataAccessSettings(raw json.RawMessage, userID string) (interface{}, error) {
+	access := map[string]bool{}
+	for _, dataType := range []string{"account", "contact", "deal", "lead", "visit_report", "activity", "task", "product"} {
+		access[dataType] = s.isDataAllowed(dataType, userID)
+	}
+	return access, nil
+}
+
+// findStage resolves a pipeline stage from its code or name.
+// Stage codes are only unique within a pipeline, so a stage found in several pipelines needs pipelineName to pick one.
+func (s *Service) findStage(stage string, pipelineName string) (*pipeline.PipelineStage, error) {
+	pipelines, err := s.pipelineRepo.ListPipelines(&pipeline.ListPipelinesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pipelines")
+	}
+	pipelineNames := make(map[string]string, len(pipelines))
+	pipelineID := ""
+	for _, p := range pipelines {
+		pipelineNames[p.ID] = p.Name
+		if pipelineName != "" && (strings.EqualFold(p.Name, pipelineName) || strings.EqualFold(p.Code, pipelineName)) {
+			pipelineID = p.ID
+		}
+	}
+	if pipelineName != "" && pipelineID == "" {
+		return nil, fmt.Errorf("unknown pipeline %q", pipelineName)
+	}
+
+	stages, err := s.pipelineRepo.ListStages(&pipeline.ListPipelineStagesRequest{PipelineID: pipelineID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pipeline stages")
+	}
+	code := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(stage), " ", "_"))
+	var matches, nameMatches []*pipeline.PipelineStage
+	codes := make([]string, 0, len(stages))
+	for i := range stages {
+		if stages[i].Code == code {
+			matches = append(matches, &stages[i])
+		} else if strings.EqualFold(stages[i].Name, stage) {
+			nameMatches = append(nameMatches, &stages[i])
+		}
+		codes = append(codes, stages[i].Code)
+	}
+	if len(matches) == 0 {
+		matches = nameMatches
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("unknown stage %q, available stages: %s", stage, strings.Join(codes, ", "))
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, 0, len(matches))
+	for _, match := range matches {
+		names = append(names, pipelineNames[match.PipelineID])
+	}
+	return nil, fmt.Errorf("stage %q exists in several pipelines (%s), specify the pipeline", stage, strings.Join(names, ", "))
+}
+
+// decodeToolArgs decodes the JSON arguments of a tool call
+func decodeToolArgs(raw json.RawMessage, target interface{}) error {
+	if err := json.Unmarshal(raw, target); err != nil {
+		return fmt.Errorf("invalid arguments: %v", err)
+	}
+	return nil
+}
+
+// toolLimit applies the default and maximum number of records returned by a tool
+func toolLimit(limit int) int {
+	if limit <= 0 {
+		return defaultToolResultLimit
+	}
+	if limit > maxToolResultLimit {
+		return maxToolResultLimit
+	}
+	return limit
+}
+
+func toolResultJSON(result interface{}) string {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return `{"error":"failed to encode result"}`
+	}
+	return string(data)
+}
+
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func stringProperty(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": description,
+	}
+}
+
+func enumProperty(description string, values ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": description,
+		"enum":        values,
+	}
+}
+
+func limitProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "integer",
+		"description": fmt.Sprintf("Maximum number of records to return (default %d, max %d)", defaultToolResultLimit, maxToolResultLimit),
+	}
+}
@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+)
+
+var (
+	ErrTokenQuotaExceeded = errors.New("monthly AI token quota exceeded")
+)
+
+// defaultUsageTimezone is used for quota months and usage days when AI settings have no valid timezone
+const defaultUsageTimezone = "Asia/Jakarta"
+
+// SetUsageRepository enables per-user token usage accounting and role token quotas.
+// Without it, usage is not recorded and quotas are not enforced.
+func (s *Service) SetUsageRepository(usageRepo interfaces.AIModelUsageRepository, userRepo interfaces.UserRepository) {
+	s.usageRepo = usageRepo
+	s.userRepo = userRepo
+}
+
+// checkTokenQuota returns ErrTokenQuotaExceeded when the user has used up the monthly quota of their role
+func (s *Service) checkTokenQuota(userID string) error {
+	if s.usageRepo == nil || userID == "" {
+		return nil
+	}
+
+	settings, err := s.settingsRepo.GetSettings()
+	if err != nil {
+		return nil // Quotas are not enforced without settings
+	}
+	quotas := settings.TokenQuotas()
+	if len(quotas) == 0 {
+		return nil
+	}
+
+	u, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if u.Role == nil {
+		return nil
+	}
+	quota, ok := quotas[u.Role.Code]
+	if !ok || quota <= 0 {
+		return nil
+	}
+
+	now := time.Now().In(usageLocation(settings.Timezone))
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	used, err := s.usageRepo.SumTokensByUser(userID, monthStart, now)
+	if err != nil {
+		return fmt.Errorf("failed to get token usage: %w", err)
+	}
+	if used >= quota {
+		return ErrTokenQuotaExceeded
+	}
+	return nil
+}
+
+// recordUsage adds the tokens of a model call to the user's daily usage.
+// Failures are logged only, since the tokens have already been spent.
+func (s *Service) recordUsage(userID string, model string, feature string, tokens int) {
+	if s.usageRepo == nil || userID == "" {
+		return
+	}
+
+	timezone := ""
+	if settings, err := s.settingsRepo.GetSettings(); err == nil {
+		timezone = settings.Timezone
+		if model == "" {
+			model = settings.Model
+		}
+	}
+	if model == "" {
+		model = "default"
+	}
+
+	usedAt := time.Now().In(usageLocation(timezone))
+	if err := s.usageRepo.Record(userID, model, feature, tokens, usedAt); err != nil {
+		log.Printf("Failed to record AI token usage for user %s: %v", userID, err)
+	}
+}
+
+// usageLocation returns the timezone used for usage days and quota months
+func usageLocation(timezone string) *time.Location {
+	if timezone == "" {
+		timezone = defaultUsageTimezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_model_usage"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/role"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/user"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+)
+
+type usageRecord struct {
+	userID  string
+	model   string
+	feature string
+	tokens  int
+}
+
+type fakeUsageRepo struct {
+	interfaces.AIModelUsageRepository
+	used    int64
+	records []usageRecord
+}
+
+func (r *fakeUsageRepo) Record(userID string, model string, feature string, tokens int, usedAt time.Time) error {
+	r.records = append(r.records, usageRecord{userID: userID, model: model, feature: feature, tokens: tokens})
+	return nil
+}
+
+func (r *fakeUsageRepo) SumTokensByUser(userID string, from time.Time, to time.Time) (int64, error) {
+	return r.used, nil
+}
+
+type fakeUserRepo struct {
+	interfaces.UserRepository
+	roleCode string
+}
+
+func (r *fakeUserRepo) FindByID(id string) (*user.User, error) {
+	return &user.User{ID: id, Role: &role.Role{Code: r.roleCode}}, nil
+}
+
+func newUsageTestService(t *testing.T, quotas string, used int64) (*Service, *fakeCerebras, *fakeUsageRepo) {
+	service, fake := newInsightTestService(t, `{"win_probability": 50}`, nil)
+	service.settingsRepo.(*fakeSettingsRepo).settings.RoleTokenQuotas = []byte(quotas)
+
+	usageRepo := &fakeUsageRepo{used: used}
+	service.SetUsageRepository(usageRepo, &fakeUserRepo{roleCode: "sales_rep"})
+	return service, fake, usageRepo
+}
+
+func TestTokenQuota_RecordsUsage(t *testing.T) {
+	service, _, usageRepo := newUsageTestService(t, `{"sales_rep": 1000}`, 100)
+
+	if _, _, err := service.AnalyzeDeal("deal-1", "user-1"); err != nil {
+		t.Fatalf("AnalyzeDeal returned error: %v", err)
+	}
+
+	if len(usageRepo.records) != 1 {
+		t.Fatalf("expected 1 usage record, got %d", len(usageRepo.records))
+	}
+	record := usageRepo.records[0]
+	if record.userID != "user-1" || record.feature != ai_model_usage.FeatureDealInsight || record.tokens != 42 {
+		t.Errorf("unexpected usage record: %+v", record)
+	}
+}
+
+func TestTokenQuota_Exceeded(t *testing.T) {
+	service, fake, usageRepo := newUsageTestService(t, `{"sales_rep": 1000}`, 1000)
+
+	if _, _, err := service.AnalyzeDeal("deal-1", "user-1"); err != ErrTokenQuotaExceeded {
+		t.Fatalf("expected ErrTokenQuotaExceeded, got %v", err)
+	}
+	if len(fake.prompts) != 0 {
+		t.Error("model should not be called when the quota is used up")
+	}
+	if len(usageRepo.records) != 0 {
+		t.Error("no usage should be recorded for a rejected call")
+	}
+}
+
+func TestTokenQuota_OtherRoleUnlimited(t *testing.T) {
+	service, _, _ := newUsageTestService(t, `{"manager": 1000}`, 5000)
+
+	if _, _, err := service.AnalyzeDeal("deal-1", "user-1"); err != nil {
+		t.Fatalf("roles without a quota should be unlimited, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package ai_conversation
+
+import (
+	"errors"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_conversation"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrConversationNotFound = errors.New("conversation not found")
+)
+
+type Service struct {
+	conversationRepo interfaces.AIConversationRepository
+}
+
+func NewService(conversationRepo interfaces.AIConversationRepository) *Service {
+	return &Service{
+		conversationRepo: conversationRepo,
+	}
+}
+
+// List returns the user's conversations with pagination, most recently active first
+func (s *Service) List(userID string, req *ai_conversation.ListConversationsRequest) ([]ai_conversation.ConversationResponse, *PaginationResult, error) {
+	conversations, total, err := s.conversationRepo.ListByUserID(userID, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]ai_conversation.ConversationResponse, len(conversations))
+	for i, c := range conversations {
+		responses[i] = *c.ToConversationResponse()
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	pagination := &PaginationResult{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      int(total),
+		TotalPages: int((total + int64(perPage) - 1) / int64(perPage)),
+	}
+
+	return responses, pagination, nil
+}
+
+// GetByID returns a conversation with its messages so the user can resume it
+func (s *Service) GetByID(id string, userID string) (*ai_conversation.ConversationResponse, error) {
+	conversation, err := s.conversationRepo.FindByIDWithMessages(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConversationNotFound
+		}
+		return nil, err
+	}
+	// Conversations of other users are reported as not found
+	if conversation.UserID != userID {
+		return nil, ErrConversationNotFound
+	}
+
+	return conversation.ToConversationResponse(), nil
+}
+
+// Rename changes the title of a conversation
+func (s *Service) Rename(id string, userID string, req *ai_conversation.UpdateConversationRequest) (*ai_conversation.ConversationResponse, error) {
+	conversation, err := s.findOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation.Title = req.Title
+	if err := s.conversationRepo.Update(conversation); err != nil {
+		return nil, err
+	}
+
+	return conversation.ToConversationResponse(), nil
+}
+
+// Delete deletes a conversation and its messages
+func (s *Service) Delete(id string, userID string) error {
+	if _, err := s.findOwned(id, userID); err != nil {
+		return err
+	}
+	return s.conversationRepo.Delete(id)
+}
+
+// findOwned returns the conversation when it belongs to the user
+func (s *Service) findOwned(id string, userID string) (*ai_conversation.Conversation, error) {
+	conversation, err := s.conversationRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConversationNotFound
+		}
+		return nil, err
+	}
+	if conversation.UserID != userID {
+		return nil, ErrConversationNotFound
+	}
+	return conversation, nil
+}
+
+// PaginationResult represents pagination result
+type PaginationResult struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
@@ -2,10 +2,16 @@ package ai_settings
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_settings"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/llm"
+)
+
+var (
+	ErrInvalidProviderConfig = errors.New("invalid AI provider configuration")
 )
 
 // Service represents AI settings service
@@ -79,6 +85,30 @@ func (s *Service) UpdateSettings(req *ai_settings.UpdateAISettingsRequest) (*ai_
 	if req.Timezone != "" {
 		settings.Timezone = req.Timezone
 	}
+	if req.RoleTokenQuotas != nil {
+		// Roles with a zero quota are dropped so they are unlimited again
+		quotas := make(map[string]int64, len(req.RoleTokenQuotas))
+		for roleCode, quota := range req.RoleTokenQuotas {
+			if quota > 0 {
+				quotas[roleCode] = quota
+			}
+		}
+		quotasJSON, err := json.Marshal(quotas)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal role token quotas: %w", err)
+		}
+		settings.RoleTokenQuotas = quotasJSON
+	}
+
+	// Reject provider settings that could not serve requests (e.g. OpenAI without API key)
+	if err := (llm.Config{
+		Provider: settings.Provider,
+		BaseURL:  settings.BaseURL,
+		APIKey:   settings.APIKey,
+		Model:    settings.Model,
+	}).Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProviderConfig, err)
+	}
 
 	if err := s.settingsRepo.UpdateSettings(settings); err != nil {
 		return nil, fmt.Errorf("failed to update settings: %w", err)
@@ -0,0 +1,88 @@
+package ai_usage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai_model_usage"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+)
+
+var (
+	ErrInvalidDateRange = errors.New("start date must not be after end date")
+)
+
+const dateFormat = "2006-01-02"
+
+type Service struct {
+	usageRepo interfaces.AIModelUsageRepository
+}
+
+func NewService(usageRepo interfaces.AIModelUsageRepository) *Service {
+	return &Service{
+		usageRepo: usageRepo,
+	}
+}
+
+// GetReport returns AI token usage broken down by user, model and feature.
+// The period defaults to the current month.
+func (s *Service) GetReport(req *ai_model_usage.UsageReportRequest) (*ai_model_usage.UsageReportResponse, error) {
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	end := now
+
+	if req.StartDate != "" {
+		parsed, err := time.Parse(dateFormat, req.StartDate)
+		if err != nil {
+			return nil, ErrInvalidDateRange
+		}
+		start = parsed
+	}
+	if req.EndDate != "" {
+		parsed, err := time.Parse(dateFormat, req.EndDate)
+		if err != nil {
+			return nil, ErrInvalidDateRange
+		}
+		end = parsed
+	}
+	if start.After(end) {
+		return nil, ErrInvalidDateRange
+	}
+
+	byUser, err := s.usageRepo.SummarizeByUser(start, end, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize usage by user: %w", err)
+	}
+	byModel, err := s.usageRepo.SummarizeByModel(start, end, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize usage by model: %w", err)
+	}
+	byFeature, err := s.usageRepo.SummarizeByFeature(start, end, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize usage by feature: %w", err)
+	}
+
+	report := &ai_model_usage.UsageReportResponse{
+		StartDate: start.Format(dateFormat),
+		EndDate:   end.Format(dateFormat),
+		ByUser:    byUser,
+		ByModel:   byModel,
+		ByFeature: byFeature,
+	}
+	if report.ByUser == nil {
+		report.ByUser = []ai_model_usage.UserUsage{}
+	}
+	if report.ByModel == nil {
+		report.ByModel = []ai_model_usage.ModelUsageSummary{}
+	}
+	if report.ByFeature == nil {
+		report.ByFeature = []ai_model_usage.FeatureUsage{}
+	}
+	for _, u := range report.ByUser {
+		report.TotalTokens += u.Tokens
+		report.TotalRequests += u.RequestCount
+	}
+
+	return report, nil
+}
@@ -0,0 +1,189 @@
+package audit_log
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/audit_log"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"gorm.io/datatypes"
+)
+
+// ignoredFields are bookkeeping fields that change on every write and carry no audit value
+var ignoredFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"deleted_at": true,
+}
+
+// Actor identifies who performed an audited action and from which request
+type Actor struct {
+	UserID    string
+	Email     string
+	Role      string
+	RequestID string
+	IPAddress string
+	UserAgent string
+}
+
+type Service struct {
+	auditLogRepo interfaces.AuditLogRepository
+}
+
+func NewService(auditLogRepo interfaces.AuditLogRepository) *Service {
+	return &Service{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+// Record stores an audit entry with the field-level difference between before and after.
+// before is nil for creates and after is nil for deletes. Updates without any change are skipped.
+func (s *Service) Record(actor *Actor, action, resourceType, resourceID string, before, after interface{}) error {
+	changes, err := Diff(before, after)
+	if err != nil {
+		return err
+	}
+	if action == audit_log.ActionUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	entry := &audit_log.AuditLog{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Changes:      datatypes.JSON(changesJSON),
+	}
+	if actor != nil {
+		if actor.UserID != "" {
+			entry.ActorID = &actor.UserID
+		}
+		entry.ActorEmail = actor.Email
+		entry.ActorRole = actor.Role
+		entry.RequestID = actor.RequestID
+		entry.IPAddress = actor.IPAddress
+		entry.UserAgent = actor.UserAgent
+	}
+
+	return s.auditLogRepo.Create(entry)
+}
+
+// List returns a list of audit logs with pagination
+func (s *Service) List(req *audit_log.ListAuditLogsRequest) ([]audit_log.AuditLogResponse, *PaginationResult, error) {
+	logs, total, err := s.auditLogRepo.List(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]audit_log.AuditLogResponse, len(logs))
+	for i, l := range logs {
+		responses[i] = *l.ToAuditLogResponse()
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	pagination := &PaginationResult{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      int(total),
+		TotalPages: int((total + int64(perPage) - 1) / int64(perPage)),
+	}
+
+	return responses, pagination, nil
+}
+
+// ListByResource returns the change history of a single record, newest first
+func (s *Service) ListByResource(resourceType, resourceID string, req *audit_log.ListAuditLogsRequest) ([]audit_log.AuditLogResponse, *PaginationResult, error) {
+	req.ResourceType = resourceType
+	req.ResourceID = resourceID
+	return s.List(req)
+}
+
+// Diff compares the JSON representation of two values field by field.
+// Nested objects (preloaded relations) are skipped; their foreign key fields already capture the change.
+func Diff(before, after interface{}) (map[string]response.ChangeValue, error) {
+	beforeFields, err := toFieldMap(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := toFieldMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]response.ChangeValue{}
+	for field, newValue := range afterFields {
+		oldValue := beforeFields[field]
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes[field] = response.ChangeValue{Old: oldValue, New: newValue}
+		}
+	}
+	for field, oldValue := range beforeFields {
+		if _, exists := afterFields[field]; !exists && oldValue != nil {
+			changes[field] = response.ChangeValue{Old: oldValue, New: nil}
+		}
+	}
+
+	return changes, nil
+}
+
+// toFieldMap flattens a value into its top-level JSON fields, dropping ignored and nested object fields
+func toFieldMap(value interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	for field, v := range raw {
+		if ignoredFields[field] {
+			continue
+		}
+		if _, isObject := v.(map[string]interface{}); isObject {
+			continue
+		}
+		if list, isList := v.([]interface{}); isList && len(list) > 0 {
+			if _, isObject := list[0].(map[string]interface{}); isObject {
+				continue
+			}
+		}
+		if v == nil {
+			continue
+		}
+		fields[field] = v
+	}
+
+	return fields, nil
+}
+
+// PaginationResult represents pagination result
+type PaginationResult struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
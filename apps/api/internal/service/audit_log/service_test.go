@@ -0,0 +1,134 @@
+package audit_log
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+)
+
+type category struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type record struct {
+	Name       string     `json:"name"`
+	Value      float64    `json:"value"`
+	AssignedTo *string    `json:"assigned_to"`
+	CloseDate  *time.Time `json:"close_date"`
+	Tags       []string   `json:"tags"`
+	CategoryID string     `json:"category_id"`
+	Category   *category  `json:"category,omitempty"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func TestDiff(t *testing.T) {
+	budi, sari := "budi", "sari"
+	march := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	april := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		before, after interface{}
+		want          map[string]response.ChangeValue
+	}{
+		{
+			name:   "unchanged",
+			before: &record{Name: "Pumps", Value: 100, AssignedTo: &budi, Tags: []string{"icu"}},
+			after:  &record{Name: "Pumps", Value: 100, AssignedTo: &budi, Tags: []string{"icu"}},
+			want:   map[string]response.ChangeValue{},
+		},
+		{
+			name:   "changed fields",
+			before: &record{Name: "Pumps", Value: 100},
+			after:  &record{Name: "Infusion pumps", Value: 250},
+			want: map[string]response.ChangeValue{
+				"name":  {Old: "Pumps", New: "Infusion pumps"},
+				"value": {Old: float64(100), New: float64(250)},
+			},
+		},
+		{
+			name:   "pointer changed",
+			before: &record{AssignedTo: &budi},
+			after:  &record{AssignedTo: &sari},
+			want:   map[string]response.ChangeValue{"assigned_to": {Old: "budi", New: "sari"}},
+		},
+		{
+			name:   "nil pointer set",
+			before: &record{},
+			after:  &record{AssignedTo: &budi},
+			want:   map[string]response.ChangeValue{"assigned_to": {Old: nil, New: "budi"}},
+		},
+		{
+			name:   "pointer cleared",
+			before: &record{AssignedTo: &budi},
+			after:  &record{},
+			want:   map[string]response.ChangeValue{"assigned_to": {Old: "budi", New: nil}},
+		},
+		{
+			name:   "time fields",
+			before: &record{CloseDate: &march, UpdatedAt: march},
+			after:  &record{CloseDate: &april, UpdatedAt: april},
+			want: map[string]response.ChangeValue{
+				"close_date": {Old: "2025-03-01T00:00:00Z", New: "2025-04-01T00:00:00Z"},
+			},
+		},
+		{
+			name:   "nested objects are skipped",
+			before: &record{CategoryID: "c1", Category: &category{ID: "c1", Name: "ICU"}},
+			after:  &record{CategoryID: "c2", Category: &category{ID: "c2", Name: "Surgery"}},
+			want:   map[string]response.ChangeValue{"category_id": {Old: "c1", New: "c2"}},
+		},
+		{
+			name:   "created record",
+			before: (*record)(nil),
+			after:  &record{Name: "Pumps"},
+			want: map[string]response.ChangeValue{
+				"name":        {Old: nil, New: "Pumps"},
+				"value":       {Old: nil, New: float64(0)},
+				"category_id": {Old: nil, New: ""},
+			},
+		},
+		{
+			name:   "deleted record",
+			before: &record{Name: "Pumps", Tags: []string{"icu"}},
+			after:  nil,
+			want: map[string]response.ChangeValue{
+				"name":        {Old: "Pumps", New: nil},
+				"value":       {Old: float64(0), New: nil},
+				"tags":        {Old: []interface{}{"icu"}, New: nil},
+				"category_id": {Old: "", New: nil},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got, err := Diff(tt.before, tt.after)
+		if err != nil {
+			t.Fatalf("%s: Diff: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestToFieldMap(t *testing.T) {
+	fields, err := toFieldMap(&record{Name: "Pumps", CategoryID: "c1", Category: &category{ID: "c1"}, UpdatedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("toFieldMap: %v", err)
+	}
+	for _, field := range []string{"updated_at", "category", "assigned_to", "close_date", "tags"} {
+		if _, exists := fields[field]; exists {
+			t.Errorf("expected %s to be dropped, got %v", field, fields[field])
+		}
+	}
+	if fields["name"] != "Pumps" || fields["category_id"] != "c1" {
+		t.Errorf("unexpected fields %v", fields)
+	}
+
+	if fields, err := toFieldMap((*record)(nil)); err != nil || len(fields) != 0 {
+		t.Errorf("expected no fields for a nil pointer, got %v, %v", fields, err)
+	}
+}
@@ -0,0 +1,137 @@
+package competitor
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/competitor"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCompetitorNotFound      = errors.New("competitor not found")
+	ErrCompetitorAlreadyExists = errors.New("competitor already exists")
+)
+
+type Service struct {
+	competitorRepo interfaces.CompetitorRepository
+}
+
+func NewService(competitorRepo interfaces.CompetitorRepository) *Service {
+	return &Service{
+		competitorRepo: competitorRepo,
+	}
+}
+
+// List returns a list of competitors
+func (s *Service) List(req *competitor.ListCompetitorsRequest) ([]competitor.CompetitorResponse, error) {
+	competitors, err := s.competitorRepo.List(req)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]competitor.CompetitorResponse, len(competitors))
+	for i := range competitors {
+		responses[i] = *competitors[i].ToCompetitorResponse()
+	}
+
+	return responses, nil
+}
+
+// GetByID returns a competitor by ID
+func (s *Service) GetByID(id string) (*competitor.CompetitorResponse, error) {
+	c, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	return c.ToCompetitorResponse(), nil
+}
+
+// Create creates a new competitor
+func (s *Service) Create(req *competitor.CreateCompetitorRequest) (*competitor.CompetitorResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := s.checkName(name, ""); err != nil {
+		return nil, err
+	}
+
+	status := req.Status
+	if status == "" {
+		status = "active"
+	}
+
+	c := &competitor.Competitor{
+		Name:        name,
+		Website:     req.Website,
+		Description: req.Description,
+		Status:      status,
+	}
+
+	if err := s.competitorRepo.Create(c); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(c.ID)
+}
+
+// Update updates a competitor
+func (s *Service) Update(id string, req *competitor.UpdateCompetitorRequest) (*competitor.CompetitorResponse, error) {
+	c, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		name := strings.TrimSpace(req.Name)
+		if err := s.checkName(name, id); err != nil {
+			return nil, err
+		}
+		c.Name = name
+	}
+	if req.Website != "" {
+		c.Website = req.Website
+	}
+	if req.Description != "" {
+		c.Description = req.Description
+	}
+	if req.Status != "" {
+		c.Status = req.Status
+	}
+
+	if err := s.competitorRepo.Update(c); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(c.ID)
+}
+
+// Delete deletes a competitor; deals lost to it keep their competitor
+func (s *Service) Delete(id string) error {
+	if _, err := s.find(id); err != nil {
+		return err
+	}
+	return s.competitorRepo.Delete(id)
+}
+
+func (s *Service) find(id string) (*competitor.Competitor, error) {
+	c, err := s.competitorRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCompetitorNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// checkName returns ErrCompetitorAlreadyExists when another competitor than excludeID has the name
+func (s *Service) checkName(name, excludeID string) error {
+	existing, err := s.competitorRepo.FindByName(name)
+	if err == nil && existing.ID != excludeID {
+		return ErrCompetitorAlreadyExists
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
@@ -4,7 +4,9 @@ import (
 	"errors"
 
 	"github.com/gilabs/crm-healthcare/api/internal/domain/contact"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/duplicate"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"gorm.io/gorm"
 )
 
@@ -14,20 +16,32 @@ var (
 	ErrContactRoleNotFound = errors.New("contact role not found")
 )
 
+// DuplicateCheckerInterface defines interface for finding existing contacts a new contact may duplicate
+type DuplicateCheckerInterface interface {
+	FindContactMatches(record duplicate.Record) ([]duplicate.Match, error)
+}
+
 type Service struct {
-	contactRepo     interfaces.ContactRepository
-	accountRepo     interfaces.AccountRepository
-	contactRoleRepo interfaces.ContactRoleRepository
+	contactRepo      interfaces.ContactRepository
+	accountRepo      interfaces.AccountRepository
+	contactRoleRepo  interfaces.ContactRoleRepository
+	duplicateChecker DuplicateCheckerInterface
 }
 
 func NewService(contactRepo interfaces.ContactRepository, accountRepo interfaces.AccountRepository, contactRoleRepo interfaces.ContactRoleRepository) *Service {
 	return &Service{
-		contactRepo:     contactRepo,
-		accountRepo:     accountRepo,
-		contactRoleRepo: contactRoleRepo,
+		contactRepo:      contactRepo,
+		accountRepo:      accountRepo,
+		contactRoleRepo:  contactRoleRepo,
+		duplicateChecker: nil, // Will be set via SetDuplicateChecker if needed
 	}
 }
 
+// SetDuplicateChecker sets the checker used to stop contacts from being created twice
+func (s *Service) SetDuplicateChecker(checker DuplicateCheckerInterface) {
+	s.duplicateChecker = checker
+}
+
 // PaginationResult represents pagination information
 type PaginationResult struct {
 	Page       int
@@ -70,6 +84,18 @@ func (s *Service) List(req *contact.ListContactsRequest) ([]contact.ContactRespo
 	return responses, pagination, nil
 }
 
+// Export writes every contact matching the list filters to w; rows are flushed to the client after each batch
+func (s *Service) Export(req *contact.ListContactsRequest, w export.Writer) error {
+	return s.contactRepo.FindInBatches(req, func(contacts []contact.Contact) error {
+		for i := range contacts {
+			if err := w.WriteRow(contacts[i].ExportRow()); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	})
+}
+
 // GetByID returns a contact by ID
 func (s *Service) GetByID(id string) (*contact.ContactResponse, error) {
 	c, err := s.contactRepo.FindByID(id)
@@ -112,6 +138,16 @@ func (s *Service) Create(req *contact.CreateContactRequest) (*contact.ContactRes
 		Notes:     req.Notes,
 	}
 
+	if s.duplicateChecker != nil && !req.IgnoreDuplicates {
+		matches, err := s.duplicateChecker.FindContactMatches(c.DuplicateRecord())
+		if err != nil {
+			return nil, err
+		}
+		if err := duplicate.Found(duplicate.EntityContact, matches); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.contactRepo.Create(c); err != nil {
 		return nil, err
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/gilabs/crm-healthcare/api/internal/domain/dashboard"
 	leaddomain "github.com/gilabs/crm-healthcare/api/internal/domain/lead"
 	pipelinedomain "github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/sales_target"
 	taskdomain "github.com/gilabs/crm-healthcare/api/internal/domain/task"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/user"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/visit_report"
@@ -26,6 +27,7 @@ type Service struct {
 	taskRepo        interfaces.TaskRepository
 	pipelineRepo    interfaces.PipelineRepository
 	leadRepo        interfaces.LeadRepository
+	salesTargetRepo interfaces.SalesTargetRepository
 }
 
 func NewService(
@@ -37,6 +39,7 @@ func NewService(
 	taskRepo interfaces.TaskRepository,
 	pipelineRepo interfaces.PipelineRepository,
 	leadRepo interfaces.LeadRepository,
+	salesTargetRepo interfaces.SalesTargetRepository,
 ) *Service {
 	return &Service{
 		visitReportRepo: visitReportRepo,
@@ -47,6 +50,7 @@ func NewService(
 		taskRepo:        taskRepo,
 		pipelineRepo:    pipelineRepo,
 		leadRepo:        leadRepo,
+		salesTargetRepo: salesTargetRepo,
 	}
 }
 
@@ -82,6 +86,22 @@ func parsePeriod(period string) (time.Time, time.Time) {
 	return start, end
 }
 
+// targetPeriod returns the calendar period the targets of a dashboard period apply to.
+// Periods to date (week, month, year) are compared against the target of the whole period;
+// custom ranges and today use the range itself.
+func targetPeriod(period string, start time.Time, end time.Time) (time.Time, time.Time) {
+	switch period {
+	case "week":
+		return start, start.AddDate(0, 0, 6)
+	case "month":
+		return start, start.AddDate(0, 1, -1)
+	case "year":
+		return start, start.AddDate(1, 0, -1)
+	default:
+		return start, end
+	}
+}
+
 // GetOverview returns dashboard overview
 func (s *Service) GetOverview(req *dashboard.DashboardRequest) (*dashboard.DashboardOverviewResponse, error) {
 	// Parse period
@@ -201,7 +221,7 @@ func (s *Service) GetOverview(req *dashboard.DashboardRequest) (*dashboard.Dashb
 	// Get pipeline/deals summary
 	var dealsSummary *pipelinedomain.PipelineSummaryResponse
 	if s.dealRepo != nil {
-		dealsSummary, err = s.dealRepo.GetSummary()
+		dealsSummary, err = s.dealRepo.GetSummary(req.PipelineID)
 		if err != nil && err != gorm.ErrRecordNotFound {
 			return nil, err
 		}
@@ -332,7 +352,34 @@ func (s *Service) GetOverview(req *dashboard.DashboardRequest) (*dashboard.Dashb
 		}
 	}
 
-	// Sales target is currently not configurable; keep defaults (zero target).
+	// Company sales target vs won deal revenue in the period
+	if s.salesTargetRepo != nil {
+		period := req.Period
+		if req.StartDate != "" && req.EndDate != "" {
+			period = ""
+		}
+		targetStart, targetEnd := targetPeriod(period, start, end)
+		targets, err := s.salesTargetRepo.ListOverlapping(targetStart, targetEnd)
+		if err != nil {
+			return nil, err
+		}
+		achievements, err := s.salesTargetRepo.AchievementByUser(start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		targetAmount, _, _ := sales_target.TargetFor(targets, sales_target.LevelCompany, "", targetStart, targetEnd)
+		achievedAmount, _ := sales_target.AchievementFor(achievements, nil)
+		attainment := sales_target.NewAttainment(targetAmount, achievedAmount, 0, 0)
+
+		targetStats = dashboard.TargetStats{
+			TargetAmount:            targetAmount,
+			TargetAmountFormatted:   formatCurrency(targetAmount),
+			AchievedAmount:          achievedAmount,
+			AchievedAmountFormatted: formatCurrency(achievedAmount),
+			ProgressPercent:         attainment.RevenueProgressPercent,
+		}
+	}
 
 	response := &dashboard.DashboardOverviewResponse{
 		Period: struct {
@@ -522,7 +569,7 @@ func (s *Service) GetPipelineSummary(req *dashboard.DashboardRequest) (*dashboar
 	}
 
 	// Get deal summary
-	summary, err := s.dealRepo.GetSummary()
+	summary, err := s.dealRepo.GetSummary(req.PipelineID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			summary = &pipelinedomain.PipelineSummaryResponse{
@@ -540,7 +587,8 @@ func (s *Service) GetPipelineSummary(req *dashboard.DashboardRequest) (*dashboar
 
 	// Get all active pipeline stages
 	listReq := &pipelinedomain.ListPipelineStagesRequest{
-		IsActive: func() *bool { b := true; return &b }(),
+		PipelineID: req.PipelineID,
+		IsActive:   func() *bool { b := true; return &b }(),
 	}
 	allStages, err := s.pipelineRepo.ListStages(listReq)
 	if err != nil {
@@ -777,6 +825,42 @@ func (s *Service) GetTopSalesRep(req *dashboard.DashboardRequest) ([]dashboard.T
 		return nil, err
 	}
 
+	// Attainment of user targets in the selected period or custom range (current month by default)
+	var targets []sales_target.SalesTarget
+	var achievements []sales_target.UserAchievement
+	var targetStart, targetEnd time.Time
+	if s.salesTargetRepo != nil {
+		var achievedStart, achievedEnd time.Time
+		period := req.Period
+		if req.StartDate != "" && req.EndDate != "" {
+			achievedStart, err = time.Parse("2006-01-02", req.StartDate)
+			if err != nil {
+				return nil, err
+			}
+			achievedEnd, err = time.Parse("2006-01-02", req.EndDate)
+			if err != nil {
+				return nil, err
+			}
+			achievedEnd = time.Date(achievedEnd.Year(), achievedEnd.Month(), achievedEnd.Day(), 23, 59, 59, 999999999, achievedEnd.Location())
+			period = ""
+		} else {
+			if period == "" || period == "today" {
+				period = "month"
+			}
+			achievedStart, achievedEnd = parsePeriod(period)
+		}
+		targetStart, targetEnd = targetPeriod(period, achievedStart, achievedEnd)
+
+		targets, err = s.salesTargetRepo.ListOverlapping(targetStart, targetEnd)
+		if err != nil {
+			return nil, err
+		}
+		achievements, err = s.salesTargetRepo.AchievementByUser(achievedStart, achievedEnd)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Build response
 	results := make([]dashboard.TopSalesRepResponse, 0)
 	for _, user := range users {
@@ -784,7 +868,14 @@ func (s *Service) GetTopSalesRep(req *dashboard.DashboardRequest) ([]dashboard.T
 		accountCount := len(salesRepAccountSet[user.ID])
 		activityCount := salesRepActivityCount[user.ID]
 
-		if visitCount > 0 || accountCount > 0 || activityCount > 0 {
+		var attainment *sales_target.Attainment
+		if revenueTarget, visitTarget, ok := sales_target.TargetFor(targets, sales_target.LevelUser, user.ID, targetStart, targetEnd); ok {
+			revenue, visits := sales_target.AchievementFor(achievements, []string{user.ID})
+			a := sales_target.NewAttainment(revenueTarget, revenue, visitTarget, visits)
+			attainment = &a
+		}
+
+		if visitCount > 0 || accountCount > 0 || activityCount > 0 || attainment != nil {
 			results = append(results, dashboard.TopSalesRepResponse{
 				SalesRep: struct {
 					ID    string `json:"id"`
@@ -798,6 +889,7 @@ func (s *Service) GetTopSalesRep(req *dashboard.DashboardRequest) ([]dashboard.T
 				VisitCount:    visitCount,
 				AccountCount:  accountCount,
 				ActivityCount: activityCount,
+				Attainment:    attainment,
 			})
 		}
 	}
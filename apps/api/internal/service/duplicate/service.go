@@ -0,0 +1,306 @@
+package duplicate
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/contact"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/duplicate"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLeadNotFound        = errors.New("lead not found")
+	ErrAccountNotFound     = errors.New("account not found")
+	ErrContactNotFound     = errors.New("contact not found")
+	ErrMergeSameRecord     = errors.New("a record cannot be merged into itself")
+	ErrMergeConvertedLeads = errors.New("both leads are already converted")
+)
+
+// LeadScorerInterface defines interface for rescoring a lead after its engagement moved
+type LeadScorerInterface interface {
+	RecomputeScore(leadID string) error
+}
+
+type Service struct {
+	duplicateRepo interfaces.DuplicateRepository
+	leadRepo      interfaces.LeadRepository
+	accountRepo   interfaces.AccountRepository
+	contactRepo   interfaces.ContactRepository
+	leadScorer    LeadScorerInterface
+}
+
+func NewService(duplicateRepo interfaces.DuplicateRepository, leadRepo interfaces.LeadRepository, accountRepo interfaces.AccountRepository, contactRepo interfaces.ContactRepository) *Service {
+	return &Service{
+		duplicateRepo: duplicateRepo,
+		leadRepo:      leadRepo,
+		accountRepo:   accountRepo,
+		contactRepo:   contactRepo,
+		leadScorer:    nil, // Will be set via SetLeadScorer if needed
+	}
+}
+
+// WithScope returns a copy of the service whose merged leads and accounts are limited to the given data scope.
+// Duplicate candidates are searched across all records, since a duplicate often belongs to another rep.
+func (s *Service) WithScope(scope *datascope.Scope) *Service {
+	scoped := *s
+	scoped.leadRepo = s.leadRepo.WithScope(scope)
+	scoped.accountRepo = s.accountRepo.WithScope(scope)
+	return &scoped
+}
+
+// SetLeadScorer sets the lead scorer used to rescore a surviving lead after a merge
+func (s *Service) SetLeadScorer(scorer LeadScorerInterface) {
+	s.leadScorer = scorer
+}
+
+// FindLeadMatches returns existing leads that may duplicate the record
+func (s *Service) FindLeadMatches(record duplicate.Record) ([]duplicate.Match, error) {
+	candidates, err := s.duplicateRepo.FindLeadCandidates(duplicate.NewQuery(record))
+	if err != nil {
+		return nil, err
+	}
+	return duplicate.FindMatches(duplicate.EntityLead, record, candidates), nil
+}
+
+// FindAccountMatches returns existing accounts that may duplicate the record
+func (s *Service) FindAccountMatches(record duplicate.Record) ([]duplicate.Match, error) {
+	candidates, err := s.duplicateRepo.FindAccountCandidates(duplicate.NewQuery(record))
+	if err != nil {
+		return nil, err
+	}
+	return duplicate.FindMatches(duplicate.EntityAccount, record, candidates), nil
+}
+
+// FindContactMatches returns existing contacts that may duplicate the record
+func (s *Service) FindContactMatches(record duplicate.Record) ([]duplicate.Match, error) {
+	candidates, err := s.duplicateRepo.FindContactCandidates(duplicate.NewQuery(record))
+	if err != nil {
+		return nil, err
+	}
+	return duplicate.FindMatches(duplicate.EntityContact, record, candidates), nil
+}
+
+// Check returns existing records that may duplicate a record about to be created
+func (s *Service) Check(req *duplicate.CheckDuplicatesRequest) ([]duplicate.Match, error) {
+	record := duplicate.Record{
+		Name:      req.Name,
+		Company:   req.Company,
+		Email:     req.Email,
+		Phone:     req.Phone,
+		City:      req.City,
+		AccountID: req.AccountID,
+	}
+	switch req.EntityType {
+	case duplicate.EntityAccount:
+		return s.FindAccountMatches(record)
+	case duplicate.EntityContact:
+		return s.FindContactMatches(record)
+	default:
+		return s.FindLeadMatches(record)
+	}
+}
+
+// LeadDuplicates returns the possible duplicates of a lead
+func (s *Service) LeadDuplicates(id string) ([]duplicate.Match, error) {
+	l, err := s.findLead(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.FindLeadMatches(l.DuplicateRecord())
+}
+
+// AccountDuplicates returns the possible duplicates of an account
+func (s *Service) AccountDuplicates(id string) ([]duplicate.Match, error) {
+	a, err := s.findAccount(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.FindAccountMatches(a.DuplicateRecord())
+}
+
+// ContactDuplicates returns the possible duplicates of a contact
+func (s *Service) ContactDuplicates(id string) ([]duplicate.Match, error) {
+	c, err := s.findContact(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.FindContactMatches(c.DuplicateRecord())
+}
+
+// MergeLeads merges the loser lead into the survivor: blank survivor fields are filled from the loser,
+// its deals, activities and visit reports move to the survivor and the loser is deleted
+func (s *Service) MergeLeads(survivorID string, loserID string) (*lead.LeadResponse, error) {
+	if survivorID == loserID {
+		return nil, ErrMergeSameRecord
+	}
+	survivor, err := s.findLead(survivorID)
+	if err != nil {
+		return nil, err
+	}
+	loser, err := s.findLead(loserID)
+	if err != nil {
+		return nil, err
+	}
+
+	survivorConverted := survivor.LeadStatus == "converted"
+	if survivorConverted && loser.LeadStatus == "converted" {
+		return nil, ErrMergeConvertedLeads
+	}
+	if !survivorConverted && loser.LeadStatus == "converted" {
+		// The survivor takes over the conversion, so the deal keeps its source lead
+		survivor.LeadStatus = loser.LeadStatus
+		survivor.OpportunityID = loser.OpportunityID
+		survivor.AccountID = loser.AccountID
+		survivor.ContactID = loser.ContactID
+		survivor.ConvertedAt = loser.ConvertedAt
+		survivor.ConvertedBy = loser.ConvertedBy
+	}
+	mergeLeadFields(survivor, loser)
+
+	if err := s.duplicateRepo.MergeLeads(survivor, loser.ID); err != nil {
+		return nil, err
+	}
+	if s.leadScorer != nil {
+		if err := s.leadScorer.RecomputeScore(survivor.ID); err != nil {
+			log.Printf("Warning: Failed to rescore lead %s after merge: %v", survivor.ID, err)
+		}
+	}
+
+	merged, err := s.findLead(survivor.ID)
+	if err != nil {
+		return nil, err
+	}
+	return merged.ToLeadResponse(), nil
+}
+
+// MergeAccounts merges the loser account into the survivor: blank survivor fields are filled from the loser,
+// its deals, contacts, leads, activities, tasks and visit reports move to the survivor and the loser is deleted
+func (s *Service) MergeAccounts(survivorID string, loserID string) (*account.AccountResponse, error) {
+	if survivorID == loserID {
+		return nil, ErrMergeSameRecord
+	}
+	survivor, err := s.findAccount(survivorID)
+	if err != nil {
+		return nil, err
+	}
+	loser, err := s.findAccount(loserID)
+	if err != nil {
+		return nil, err
+	}
+
+	fillBlank(&survivor.Address, loser.Address)
+	fillBlank(&survivor.City, loser.City)
+	fillBlank(&survivor.Province, loser.Province)
+	fillBlank(&survivor.Phone, loser.Phone)
+	fillBlank(&survivor.Email, loser.Email)
+	if survivor.AssignedTo == nil {
+		survivor.AssignedTo = loser.AssignedTo
+	}
+
+	if err := s.duplicateRepo.MergeAccounts(survivor, loser.ID); err != nil {
+		return nil, err
+	}
+
+	merged, err := s.findAccount(survivor.ID)
+	if err != nil {
+		return nil, err
+	}
+	return merged.ToAccountResponse(), nil
+}
+
+// MergeContacts merges the loser contact into the survivor: blank survivor fields are filled from the loser,
+// its deals, leads, activities, tasks and visit reports move to the survivor and the loser is deleted
+func (s *Service) MergeContacts(survivorID string, loserID string) (*contact.ContactResponse, error) {
+	if survivorID == loserID {
+		return nil, ErrMergeSameRecord
+	}
+	survivor, err := s.findContact(survivorID)
+	if err != nil {
+		return nil, err
+	}
+	loser, err := s.findContact(loserID)
+	if err != nil {
+		return nil, err
+	}
+
+	fillBlank(&survivor.Phone, loser.Phone)
+	fillBlank(&survivor.Email, loser.Email)
+	fillBlank(&survivor.Position, loser.Position)
+	fillBlank(&survivor.Notes, loser.Notes)
+
+	if err := s.duplicateRepo.MergeContacts(survivor, loser.ID); err != nil {
+		return nil, err
+	}
+
+	merged, err := s.findContact(survivor.ID)
+	if err != nil {
+		return nil, err
+	}
+	return merged.ToContactResponse(), nil
+}
+
+// mergeLeadFields fills the blank fields of the surviving lead from the loser
+func mergeLeadFields(survivor *lead.Lead, loser *lead.Lead) {
+	fillBlank(&survivor.LastName, loser.LastName)
+	fillBlank(&survivor.CompanyName, loser.CompanyName)
+	fillBlank(&survivor.Phone, loser.Phone)
+	fillBlank(&survivor.JobTitle, loser.JobTitle)
+	fillBlank(&survivor.Industry, loser.Industry)
+	fillBlank(&survivor.Notes, loser.Notes)
+	fillBlank(&survivor.Address, loser.Address)
+	fillBlank(&survivor.City, loser.City)
+	fillBlank(&survivor.Province, loser.Province)
+	fillBlank(&survivor.PostalCode, loser.PostalCode)
+	fillBlank(&survivor.Website, loser.Website)
+	if survivor.AssignedTo == nil {
+		survivor.AssignedTo = loser.AssignedTo
+	}
+	if loser.LeadScore > survivor.LeadScore {
+		survivor.LeadScore = loser.LeadScore
+	}
+}
+
+// fillBlank sets the field to the value when the field is empty
+func fillBlank(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+func (s *Service) findLead(id string) (*lead.Lead, error) {
+	l, err := s.leadRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLeadNotFound
+		}
+		return nil, err
+	}
+	return l, nil
+}
+
+func (s *Service) findAccount(id string) (*account.Account, error) {
+	a, err := s.accountRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *Service) findContact(id string) (*contact.Contact, error) {
+	c, err := s.contactRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrContactNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
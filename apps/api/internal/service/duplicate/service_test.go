@@ -0,0 +1,144 @@
+package duplicate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/duplicate"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+type fakeDuplicateRepo struct {
+	interfaces.DuplicateRepository
+	accounts     []duplicate.Record
+	mergedLead   *lead.Lead
+	mergedLoser  string
+	accountQuery duplicate.Query
+}
+
+func (r *fakeDuplicateRepo) FindAccountCandidates(q duplicate.Query) ([]duplicate.Record, error) {
+	r.accountQuery = q
+	return r.accounts, nil
+}
+
+func (r *fakeDuplicateRepo) MergeLeads(survivor *lead.Lead, loserID string) error {
+	copied := *survivor
+	r.mergedLead = &copied
+	r.mergedLoser = loserID
+	return nil
+}
+
+type fakeLeadRepo struct {
+	interfaces.LeadRepository
+	leads map[string]*lead.Lead
+}
+
+func (r *fakeLeadRepo) FindByID(id string) (*lead.Lead, error) {
+	l, ok := r.leads[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *l
+	return &copied, nil
+}
+
+type fakeAccountRepo struct {
+	interfaces.AccountRepository
+}
+
+func (r *fakeAccountRepo) FindByID(id string) (*account.Account, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestNormalizePhoneHandlesIndonesianFormats(t *testing.T) {
+	for _, phone := range []string{"+62 812-3456-7890", "62 812 3456 7890", "0812.3456.7890", "(+62) 0812 3456 7890", "0062 812 3456 7890", "812 3456 7890"} {
+		if got := duplicate.NormalizePhone(phone); got != "081234567890" {
+			t.Errorf("NormalizePhone(%q) = %q, want 081234567890", phone, got)
+		}
+	}
+	if got := duplicate.NormalizePhone("(021) 555-1234"); got != "0215551234" {
+		t.Errorf("expected landline 0215551234, got %q", got)
+	}
+	if got := duplicate.NormalizePhone("12-34"); got != "" {
+		t.Errorf("expected short number to be ignored, got %q", got)
+	}
+}
+
+func TestCompareMatchesFuzzyNamesOnlyInTheSameCity(t *testing.T) {
+	subject := duplicate.Record{Name: "Rumah Sakit Harapan Kita", City: "Kota Jakarta Barat"}
+
+	score, reasons := duplicate.Compare(subject, duplicate.Record{Name: "RS. Harapan Kitta", City: "Jakarta Barat"})
+	if len(reasons) != 1 || reasons[0] != duplicate.ReasonName || score < 80 {
+		t.Fatalf("expected fuzzy name match in the same city, got %d %v", score, reasons)
+	}
+
+	if _, reasons := duplicate.Compare(subject, duplicate.Record{Name: "RS Harapan Kita", City: "Surabaya"}); len(reasons) != 0 {
+		t.Errorf("expected no match in another city, got %v", reasons)
+	}
+
+	doctor := duplicate.Record{Name: "dr. Andi Wijaya, Sp.PD", Email: "Andi@Example.com ", AccountID: "rs-1"}
+	score, reasons = duplicate.Compare(doctor, duplicate.Record{Name: "Andi Wijaya", Email: "andi@example.com", AccountID: "rs-2"})
+	if score != 100 || len(reasons) != 1 || reasons[0] != duplicate.ReasonEmail {
+		t.Errorf("expected email-only match across accounts, got %d %v", score, reasons)
+	}
+}
+
+func TestFindAccountMatchesRanksByScore(t *testing.T) {
+	repo := &fakeDuplicateRepo{accounts: []duplicate.Record{
+		{ID: "a1", Name: "Klinik Sehat Sentosa", City: "Bandung"},
+		{ID: "a2", Name: "Apotek Lain", Phone: "+62 22 7654 321"},
+		{ID: "a3", Name: "Klinik Sentosa Sehat", City: "Bandung", Email: "info@sentosa.id"},
+		{ID: "a4", Name: "Klinik Maju", City: "Bandung"},
+	}}
+	svc := NewService(repo, &fakeLeadRepo{}, &fakeAccountRepo{}, nil)
+
+	matches, err := svc.FindAccountMatches(duplicate.Record{Name: "Klinik Sehat Sentosa", Email: "INFO@sentosa.id", Phone: "022-7654321", City: "Kota Bandung"})
+	if err != nil {
+		t.Fatalf("FindAccountMatches failed: %v", err)
+	}
+	if len(matches) != 3 || matches[0].ID != "a3" || matches[1].ID != "a2" || matches[2].ID != "a1" {
+		t.Fatalf("unexpected matches %+v", matches)
+	}
+	if repo.accountQuery.PhoneSuffix != "227654321" || len(repo.accountQuery.NameKeys) != 2 {
+		t.Errorf("unexpected candidate query %+v", repo.accountQuery)
+	}
+}
+
+func TestMergeLeadsTakesOverConversionAndFillsBlanks(t *testing.T) {
+	deal := "deal-1"
+	acc := "account-1"
+	leadRepo := &fakeLeadRepo{leads: map[string]*lead.Lead{
+		"keep": {ID: "keep", FirstName: "Sari", Email: "sari@rs.id", LeadStatus: "qualified", LeadScore: 40},
+		"drop": {ID: "drop", FirstName: "Sari", Phone: "0811", City: "Medan", LeadStatus: "converted", LeadScore: 70, OpportunityID: &deal, AccountID: &acc},
+	}}
+	repo := &fakeDuplicateRepo{}
+	svc := NewService(repo, leadRepo, &fakeAccountRepo{}, nil)
+
+	if _, err := svc.MergeLeads("keep", "drop"); err != nil {
+		t.Fatalf("MergeLeads failed: %v", err)
+	}
+	merged := repo.mergedLead
+	if repo.mergedLoser != "drop" || merged.ID != "keep" {
+		t.Fatalf("expected drop merged into keep, got %s into %v", repo.mergedLoser, merged)
+	}
+	if merged.LeadStatus != "converted" || merged.OpportunityID == nil || *merged.OpportunityID != deal {
+		t.Errorf("expected survivor to take over the conversion, got %+v", merged)
+	}
+	if merged.Email != "sari@rs.id" || merged.Phone != "0811" || merged.City != "Medan" || merged.LeadScore != 70 {
+		t.Errorf("expected blank fields filled from the loser, got %+v", merged)
+	}
+
+	leadRepo.leads["keep"].LeadStatus = "converted"
+	if _, err := svc.MergeLeads("keep", "drop"); !errors.Is(err, ErrMergeConvertedLeads) {
+		t.Errorf("expected ErrMergeConvertedLeads, got %v", err)
+	}
+	if _, err := svc.MergeLeads("keep", "keep"); !errors.Is(err, ErrMergeSameRecord) {
+		t.Errorf("expected ErrMergeSameRecord, got %v", err)
+	}
+	if _, err := svc.MergeLeads("keep", "missing"); !errors.Is(err, ErrLeadNotFound) {
+		t.Errorf("expected ErrLeadNotFound, got %v", err)
+	}
+}
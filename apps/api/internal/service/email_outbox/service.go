@@ -0,0 +1,317 @@
+package email_outbox
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/email_outbox"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/reminder"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/mailer"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmailNotFound       = errors.New("email not found")
+	ErrEmailNotRetryable   = errors.New("only failed emails can be retried")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrUnknownTemplate     = errors.New("no email template for notification type")
+	ErrMailerNotConfigured = errors.New("email delivery is not configured")
+)
+
+const (
+	// DefaultMaxAttempts is how often an email is tried before it is marked failed
+	DefaultMaxAttempts = 6
+	// DefaultBrandName is the sender name shown in the emails
+	DefaultBrandName = "CRM Healthcare"
+
+	batchSize    = 50               // Emails sent per run of the worker
+	attemptLease = 10 * time.Minute // Time a worker holds a claimed email before another may retry it
+	firstRetry   = time.Minute
+	maxRetry     = 6 * time.Hour
+)
+
+type Service struct {
+	outboxRepo   interfaces.EmailOutboxRepository
+	userRepo     interfaces.UserRepository
+	reminderRepo interfaces.ReminderRepository
+	sender       mailer.Sender
+	brandName    string
+	maxAttempts  int
+	now          func() time.Time
+}
+
+func NewService(
+	outboxRepo interfaces.EmailOutboxRepository,
+	userRepo interfaces.UserRepository,
+	reminderRepo interfaces.ReminderRepository,
+) *Service {
+	return &Service{
+		outboxRepo:   outboxRepo,
+		userRepo:     userRepo,
+		reminderRepo: reminderRepo,
+		brandName:    DefaultBrandName,
+		maxAttempts:  DefaultMaxAttempts,
+		now:          time.Now,
+	}
+}
+
+// SetSender sets the mailer used to send queued emails; without one nothing is queued
+func (s *Service) SetSender(sender mailer.Sender) {
+	s.sender = sender
+}
+
+// SetBrandName sets the name shown in the header and footer of the emails
+func (s *Service) SetBrandName(name string) {
+	if name != "" {
+		s.brandName = name
+	}
+}
+
+// SetMaxAttempts sets how often an email is tried before it is marked failed
+func (s *Service) SetMaxAttempts(maxAttempts int) {
+	if maxAttempts > 0 {
+		s.maxAttempts = maxAttempts
+	}
+}
+
+// Enqueue renders a notification with the template of its type and queues it for the user's email address.
+// The worker sends it on its next run.
+func (s *Service) Enqueue(n *email_outbox.NotificationEmail) (*email_outbox.EmailMessage, error) {
+	if s.sender == nil {
+		return nil, ErrMailerNotConfigured
+	}
+	tmpl, ok := templates[n.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTemplate, n.Type)
+	}
+
+	u, err := s.userRepo.FindByID(n.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	subject, text, html, err := tmpl.render(&templateData{
+		BrandName:     s.brandName,
+		RecipientName: u.Name,
+		Title:         n.Title,
+		Message:       n.Message,
+		Details:       n.Details,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("render %s email: %w", n.Type, err)
+	}
+
+	userID := u.ID
+	msg := &email_outbox.EmailMessage{
+		UserID:        &userID,
+		Recipient:     u.Email,
+		Template:      n.Type,
+		Subject:       truncate(subject, 255),
+		TextBody:      text,
+		HTMLBody:      html,
+		Status:        email_outbox.StatusPending,
+		MaxAttempts:   s.maxAttempts,
+		NextAttemptAt: s.now().UTC(),
+		ReminderID:    n.ReminderID,
+	}
+	if err := s.outboxRepo.Create(msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// ProcessDue sends the emails that are due and returns how many were sent. Every attempt is claimed
+// first, so several API instances can run the worker without sending an email twice.
+func (s *Service) ProcessDue() (int, error) {
+	if s.sender == nil {
+		return 0, nil
+	}
+
+	messages, err := s.outboxRepo.FindDue(s.now(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range messages {
+		msg := &messages[i]
+		claimed, err := s.outboxRepo.ClaimAttempt(msg.ID, msg.Attempts, s.now().Add(attemptLease))
+		if err != nil {
+			log.Printf("Warning: Failed to claim email %s: %v", msg.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		msg.Attempts++
+		if s.attempt(msg) {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// attempt sends a claimed email and records the outcome; it reports whether the email was sent
+func (s *Service) attempt(msg *email_outbox.EmailMessage) bool {
+	err := s.sender.Send(&mailer.Message{
+		To:       []string{msg.Recipient},
+		Subject:  msg.Subject,
+		Body:     msg.TextBody,
+		HTMLBody: msg.HTMLBody,
+	})
+
+	now := s.now().UTC()
+	switch {
+	case err == nil:
+		msg.Status = email_outbox.StatusSent
+		msg.SentAt = &now
+		msg.LastError = ""
+	case errors.Is(err, mailer.ErrInvalidRecipient) || msg.Attempts >= msg.MaxAttempts:
+		msg.Status = email_outbox.StatusFailed
+		msg.LastError = err.Error()
+	default:
+		msg.NextAttemptAt = now.Add(retryDelay(msg.Attempts))
+		msg.LastError = err.Error()
+	}
+
+	if err := s.outboxRepo.Update(msg); err != nil {
+		log.Printf("Warning: Failed to update email %s: %v", msg.ID, err)
+	}
+	if msg.Status != email_outbox.StatusPending {
+		s.updateReminder(msg)
+	}
+	if msg.Status == email_outbox.StatusFailed {
+		log.Printf("Warning: Email %s to %s failed after %d attempt(s): %s", msg.ID, msg.Recipient, msg.Attempts, msg.LastError)
+	}
+	return msg.Status == email_outbox.StatusSent
+}
+
+// updateReminder copies the final status of an email to the reminder it delivers
+func (s *Service) updateReminder(msg *email_outbox.EmailMessage) {
+	if msg.ReminderID == nil {
+		return
+	}
+	status := reminder.DeliveryStatusSent
+	if msg.Status == email_outbox.StatusFailed {
+		status = reminder.DeliveryStatusFailed
+	}
+	if err := s.reminderRepo.UpdateDeliveryStatus(*msg.ReminderID, status, msg.LastError); err != nil {
+		log.Printf("Warning: Failed to update delivery status of reminder %s: %v", *msg.ReminderID, err)
+	}
+}
+
+// List returns a list of queued emails
+func (s *Service) List(req *email_outbox.ListEmailMessagesRequest) ([]email_outbox.EmailMessageResponse, *PaginationResult, error) {
+	messages, total, err := s.outboxRepo.List(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]email_outbox.EmailMessageResponse, len(messages))
+	for i := range messages {
+		responses[i] = *messages[i].ToEmailMessageResponse()
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	totalPages := int((total + int64(perPage) - 1) / int64(perPage))
+
+	pagination := &PaginationResult{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      int(total),
+		TotalPages: totalPages,
+	}
+
+	return responses, pagination, nil
+}
+
+// GetByID returns a queued email by ID
+func (s *Service) GetByID(id string) (*email_outbox.EmailMessageResponse, error) {
+	msg, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	return msg.ToEmailMessageResponse(), nil
+}
+
+// Retry queues a failed email again with a fresh set of attempts
+func (s *Service) Retry(id string) (*email_outbox.EmailMessageResponse, error) {
+	msg, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Status != email_outbox.StatusFailed {
+		return nil, ErrEmailNotRetryable
+	}
+
+	msg.Status = email_outbox.StatusPending
+	msg.Attempts = 0
+	msg.MaxAttempts = s.maxAttempts
+	msg.NextAttemptAt = s.now().UTC()
+	msg.LastError = ""
+	if err := s.outboxRepo.Update(msg); err != nil {
+		return nil, err
+	}
+
+	if msg.ReminderID != nil {
+		if err := s.reminderRepo.UpdateDeliveryStatus(*msg.ReminderID, reminder.DeliveryStatusQueued, ""); err != nil {
+			log.Printf("Warning: Failed to update delivery status of reminder %s: %v", *msg.ReminderID, err)
+		}
+	}
+
+	return msg.ToEmailMessageResponse(), nil
+}
+
+func (s *Service) find(id string) (*email_outbox.EmailMessage, error) {
+	msg, err := s.outboxRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmailNotFound
+		}
+		return nil, err
+	}
+	return msg, nil
+}
+
+// retryDelay returns the wait after a failed attempt: one minute, then five times longer each attempt, at most six hours
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetry
+	for i := 1; i < attempts && delay < maxRetry; i++ {
+		delay *= 5
+	}
+	return min(delay, maxRetry)
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// PaginationResult represents pagination information
+type PaginationResult struct {
+	Page       int
+	PerPage    int
+	Total      int
+	TotalPages int
+}
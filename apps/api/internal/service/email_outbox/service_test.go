@@ -0,0 +1,232 @@
+package email_outbox
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/email_outbox"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/reminder"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/user"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/mailer"
+	"github.com/gilabs/crm-healthcare/api/pkg/mailer/mailertest"
+	"gorm.io/gorm"
+)
+
+type fakeOutboxRepo struct {
+	interfaces.EmailOutboxRepository
+	messages []*email_outbox.EmailMessage
+}
+
+func (r *fakeOutboxRepo) FindByID(id string) (*email_outbox.EmailMessage, error) {
+	for _, m := range r.messages {
+		if m.ID == id {
+			copied := *m
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeOutboxRepo) Create(m *email_outbox.EmailMessage) error {
+	m.ID = "email-" + m.Template
+	copied := *m
+	r.messages = append(r.messages, &copied)
+	return nil
+}
+
+func (r *fakeOutboxRepo) Update(m *email_outbox.EmailMessage) error {
+	for i := range r.messages {
+		if r.messages[i].ID == m.ID {
+			copied := *m
+			r.messages[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (r *fakeOutboxRepo) FindDue(now time.Time, limit int) ([]email_outbox.EmailMessage, error) {
+	var due []email_outbox.EmailMessage
+	for _, m := range r.messages {
+		if m.Status == email_outbox.StatusPending && !m.NextAttemptAt.After(now) {
+			due = append(due, *m)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeOutboxRepo) ClaimAttempt(id string, attempts int, leaseUntil time.Time) (bool, error) {
+	for _, m := range r.messages {
+		if m.ID == id && m.Status == email_outbox.StatusPending && m.Attempts == attempts {
+			m.Attempts++
+			m.NextAttemptAt = leaseUntil
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type fakeUserRepo struct {
+	interfaces.UserRepository
+}
+
+func (r *fakeUserRepo) FindByID(id string) (*user.User, error) {
+	if id == "user-1" {
+		return &user.User{ID: id, Name: "Budi <Santoso>", Email: "budi@example.com"}, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakeReminderRepo struct {
+	interfaces.ReminderRepository
+	statuses map[string]string
+}
+
+func (r *fakeReminderRepo) UpdateDeliveryStatus(id string, status string, deliveryError string) error {
+	r.statuses[id] = status
+	return nil
+}
+
+type clock struct{ now time.Time }
+
+func newTestService(t *testing.T) (*Service, *fakeOutboxRepo, *fakeReminderRepo, *mailertest.Server, *clock) {
+	t.Helper()
+	outbox := &fakeOutboxRepo{}
+	reminders := &fakeReminderRepo{statuses: map[string]string{}}
+	server := mailertest.NewServer(t)
+	c := &clock{now: time.Date(2025, 3, 10, 2, 0, 0, 0, time.UTC)}
+
+	svc := NewService(outbox, &fakeUserRepo{}, reminders)
+	svc.SetSender(mailer.NewSMTPSender(mailer.SMTPConfig{Host: server.Host, Port: server.Port, From: "crm@example.com"}))
+	svc.SetMaxAttempts(3)
+	svc.now = func() time.Time { return c.now }
+	return svc, outbox, reminders, server, c
+}
+
+func TestEnqueue_RendersTemplate(t *testing.T) {
+	svc, outbox, _, _, _ := newTestService(t)
+
+	msg, err := svc.Enqueue(&email_outbox.NotificationEmail{
+		UserID:  "user-1",
+		Type:    "reminder",
+		Title:   "Follow up RS Sehat",
+		Message: "Bring the <new> price list",
+		Details: []email_outbox.Detail{{Label: "Due", Value: "10 Mar 2025 14:00"}},
+	})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	if msg.Recipient != "budi@example.com" || msg.Subject != "Reminder: Follow up RS Sehat" || len(outbox.messages) != 1 {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+	if !strings.Contains(msg.TextBody, "Hello Budi <Santoso>,") || !strings.Contains(msg.TextBody, "Due: 10 Mar 2025 14:00") {
+		t.Errorf("unexpected text body %q", msg.TextBody)
+	}
+	if !strings.Contains(msg.HTMLBody, "Hello Budi &lt;Santoso&gt;,") || !strings.Contains(msg.HTMLBody, "Bring the &lt;new&gt; price list") {
+		t.Errorf("HTML body must escape values: %q", msg.HTMLBody)
+	}
+
+	if _, err := svc.Enqueue(&email_outbox.NotificationEmail{UserID: "user-1", Type: "invoice"}); !errors.Is(err, ErrUnknownTemplate) {
+		t.Errorf("expected ErrUnknownTemplate, got %v", err)
+	}
+	if _, err := svc.Enqueue(&email_outbox.NotificationEmail{UserID: "user-2", Type: "task"}); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound, got %v", err)
+	}
+
+	svc.SetSender(nil)
+	if _, err := svc.Enqueue(&email_outbox.NotificationEmail{UserID: "user-1", Type: "task"}); !errors.Is(err, ErrMailerNotConfigured) {
+		t.Errorf("expected ErrMailerNotConfigured, got %v", err)
+	}
+}
+
+func TestProcessDue_SendsAndUpdatesReminder(t *testing.T) {
+	svc, outbox, reminders, server, _ := newTestService(t)
+	reminderID := "reminder-1"
+	if _, err := svc.Enqueue(&email_outbox.NotificationEmail{UserID: "user-1", Type: "reminder", Title: "Call dr. Sari", ReminderID: &reminderID}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	sent, err := svc.ProcessDue()
+	if err != nil || sent != 1 {
+		t.Fatalf("ProcessDue = %d, %v; want 1", sent, err)
+	}
+	if n := len(server.Messages()); n != 1 {
+		t.Fatalf("expected 1 email, got %d", n)
+	}
+	msg := outbox.messages[0]
+	if msg.Status != email_outbox.StatusSent || msg.Attempts != 1 || msg.SentAt == nil {
+		t.Errorf("unexpected message %+v", msg)
+	}
+	if reminders.statuses[reminderID] != reminder.DeliveryStatusSent {
+		t.Errorf("expected the reminder to be sent, got %q", reminders.statuses[reminderID])
+	}
+
+	if sent, _ := svc.ProcessDue(); sent != 0 || len(server.Messages()) != 1 {
+		t.Error("a sent email must not be sent again")
+	}
+}
+
+func TestProcessDue_RetriesWithBackoffThenFails(t *testing.T) {
+	svc, outbox, reminders, server, c := newTestService(t)
+	reminderID := "reminder-1"
+	if _, err := svc.Enqueue(&email_outbox.NotificationEmail{UserID: "user-1", Type: "reminder", Title: "Call dr. Sari", ReminderID: &reminderID}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	server.Reject("451 try again later")
+
+	start := c.now
+	for attempt, wait := range []time.Duration{time.Minute, 5 * time.Minute} {
+		if sent, _ := svc.ProcessDue(); sent != 0 {
+			t.Fatalf("attempt %d: expected no email to be sent", attempt+1)
+		}
+		msg := outbox.messages[0]
+		if msg.Status != email_outbox.StatusPending || !msg.NextAttemptAt.Equal(c.now.Add(wait)) {
+			t.Fatalf("attempt %d: expected a retry after %v, got %+v", attempt+1, wait, msg)
+		}
+		if sent, _ := svc.ProcessDue(); sent != 0 || outbox.messages[0].Attempts != attempt+1 {
+			t.Fatalf("attempt %d: the email must wait for its retry", attempt+1)
+		}
+		c.now = msg.NextAttemptAt
+	}
+	if _, ok := reminders.statuses[reminderID]; ok {
+		t.Error("the reminder status must not change while the email is retried")
+	}
+
+	svc.ProcessDue()
+	msg := outbox.messages[0]
+	if msg.Status != email_outbox.StatusFailed || msg.Attempts != 3 || !strings.Contains(msg.LastError, "try again later") {
+		t.Fatalf("expected the email to fail after 3 attempts, got %+v", msg)
+	}
+	if reminders.statuses[reminderID] != reminder.DeliveryStatusFailed {
+		t.Errorf("expected the reminder to fail, got %q", reminders.statuses[reminderID])
+	}
+	if c.now.Sub(start) != 6*time.Minute {
+		t.Errorf("unexpected total wait %v", c.now.Sub(start))
+	}
+
+	server.Reject("")
+	if _, err := svc.Retry(msg.ID); err != nil {
+		t.Fatalf("Retry: %v", err)
+	}
+	if reminders.statuses[reminderID] != reminder.DeliveryStatusQueued {
+		t.Errorf("expected the reminder to be queued again, got %q", reminders.statuses[reminderID])
+	}
+	if sent, _ := svc.ProcessDue(); sent != 1 {
+		t.Error("expected the retried email to be sent")
+	}
+	if _, err := svc.Retry(msg.ID); !errors.Is(err, ErrEmailNotRetryable) {
+		t.Errorf("expected ErrEmailNotRetryable, got %v", err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	want := []time.Duration{time.Minute, 5 * time.Minute, 25 * time.Minute, 125 * time.Minute, 6 * time.Hour, 6 * time.Hour}
+	for i, delay := range want {
+		if got := retryDelay(i + 1); got != delay {
+			t.Errorf("retryDelay(%d) = %v, want %v", i+1, got, delay)
+		}
+	}
+}
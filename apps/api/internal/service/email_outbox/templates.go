@@ -0,0 +1,114 @@
+package email_outbox
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/email_outbox"
+)
+
+// templateData is what the email templates render
+type templateData struct {
+	BrandName     string
+	RecipientName string
+	Title         string
+	Message       string
+	Details       []email_outbox.Detail
+}
+
+// emailTemplate renders the subject, plain text and HTML body of one notification type
+type emailTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// htmlLayout wraps the "content" of every HTML email
+const htmlLayout = `<!DOCTYPE html>
+<html>
+<body style="margin:0;padding:24px;background:#f4f6f8;font-family:Arial,Helvetica,sans-serif;color:#1f2937;">
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="max-width:600px;margin:0 auto;background:#ffffff;border-radius:8px;">
+<tr><td style="padding:16px 24px;background:#0f766e;color:#ffffff;font-size:18px;font-weight:bold;border-radius:8px 8px 0 0;">{{.BrandName}}</td></tr>
+<tr><td style="padding:24px;font-size:14px;line-height:1.5;">
+<p>Hello {{.RecipientName}},</p>
+{{template "content" .}}
+{{if .Details}}<table role="presentation" cellpadding="0" cellspacing="0" style="margin-top:16px;">
+{{range .Details}}<tr><td style="padding:4px 16px 4px 0;color:#6b7280;">{{.Label}}</td><td style="padding:4px 0;">{{.Value}}</td></tr>
+{{end}}</table>{{end}}
+</td></tr>
+<tr><td style="padding:16px 24px;font-size:12px;color:#6b7280;border-top:1px solid #e5e7eb;">This email was sent automatically by {{.BrandName}}.</td></tr>
+</table>
+</body>
+</html>
+`
+
+// textLayout wraps the "content" of every plain text email
+const textLayout = `Hello {{.RecipientName}},
+
+{{template "content" .}}
+{{if .Details}}
+{{range .Details}}{{.Label}}: {{.Value}}
+{{end}}{{end}}
+--
+{{.BrandName}}
+`
+
+var templateSources = map[string]struct{ subject, text, html string }{
+	"reminder": {
+		subject: `Reminder: {{.Title}}`,
+		text:    `This is your reminder for "{{.Title}}".{{if .Message}}` + "\n\n" + `{{.Message}}{{end}}`,
+		html:    `<p>This is your reminder for <strong>{{.Title}}</strong>.</p>{{if .Message}}<p>{{.Message}}</p>{{end}}`,
+	},
+	"task": {
+		subject: `Task: {{.Title}}`,
+		text:    `There is an update on the task "{{.Title}}".{{if .Message}}` + "\n\n" + `{{.Message}}{{end}}`,
+		html:    `<p>There is an update on the task <strong>{{.Title}}</strong>.</p>{{if .Message}}<p>{{.Message}}</p>{{end}}`,
+	},
+	"deal": {
+		subject: `Deal update: {{.Title}}`,
+		text:    `There is an update on a deal: {{.Title}}.{{if .Message}}` + "\n\n" + `{{.Message}}{{end}}`,
+		html:    `<p>There is an update on a deal: <strong>{{.Title}}</strong>.</p>{{if .Message}}<p>{{.Message}}</p>{{end}}`,
+	},
+	"activity": {
+		subject: `Activity: {{.Title}}`,
+		text:    `A new activity was logged: {{.Title}}.{{if .Message}}` + "\n\n" + `{{.Message}}{{end}}`,
+		html:    `<p>A new activity was logged: <strong>{{.Title}}</strong>.</p>{{if .Message}}<p>{{.Message}}</p>{{end}}`,
+	},
+	"lead": {
+		subject: `{{.Title}}`,
+		text:    `{{.Title}}.{{if .Message}}` + "\n\n" + `{{.Message}}{{end}}` + "\n\n" + `Please follow up on the lead soon.`,
+		html:    `<p><strong>{{.Title}}</strong>.</p>{{if .Message}}<p>{{.Message}}</p>{{end}}<p>Please follow up on the lead soon.</p>`,
+	},
+}
+
+// templates holds the parsed template of each notification type
+var templates = parseTemplates()
+
+func parseTemplates() map[string]*emailTemplate {
+	parsed := make(map[string]*emailTemplate, len(templateSources))
+	for name, source := range templateSources {
+		parsed[name] = &emailTemplate{
+			subject: texttemplate.Must(texttemplate.New("subject").Parse(source.subject)),
+			text:    texttemplate.Must(texttemplate.Must(texttemplate.New("layout").Parse(textLayout)).New("content").Parse(source.text)),
+			html:    htmltemplate.Must(htmltemplate.Must(htmltemplate.New("layout").Parse(htmlLayout)).New("content").Parse(source.html)),
+		}
+	}
+	return parsed
+}
+
+// render returns the subject, text and HTML body of a notification email
+func (t *emailTemplate) render(data *templateData) (string, string, string, error) {
+	var subject, text, html bytes.Buffer
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return "", "", "", err
+	}
+	if err := t.text.ExecuteTemplate(&text, "layout", data); err != nil {
+		return "", "", "", err
+	}
+	if err := t.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return "", "", "", err
+	}
+	return strings.TrimSpace(subject.String()), text.String(), html.String(), nil
+}
@@ -34,6 +34,11 @@ func (s *Service) UploadImage(file *multipart.FileHeader) (string, error) {
 	return s.storage.UploadImage(file)
 }
 
+// UploadFile stores generated content under the given filename
+func (s *Service) UploadFile(filename string, data []byte, contentType string) (string, error) {
+	return s.storage.UploadFile(filename, data, contentType)
+}
+
 // DeleteFile deletes a file from storage
 func (s *Service) DeleteFile(filename string) error {
 	return s.storage.DeleteFile(filename)
@@ -85,6 +85,21 @@ func (s *LocalStorage) UploadImage(file *multipart.FileHeader) (string, error) {
 	return s.GetFileURL(filename), nil
 }
 
+// UploadFile writes generated content to the upload directory; the content type is implied by the file extension
+func (s *LocalStorage) UploadFile(filename string, data []byte, contentType string) (string, error) {
+	filename = filepath.Base(filename)
+	if err := os.WriteFile(filepath.Join(s.uploadDir, filename), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return s.GetFileURL(filename), nil
+}
+
+// ReadFile reads a file from the upload directory
+func (s *LocalStorage) ReadFile(filename string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.uploadDir, filepath.Base(filename)))
+}
+
 // DeleteFile deletes a file from storage
 func (s *LocalStorage) DeleteFile(filename string) error {
 	filePath := filepath.Join(s.uploadDir, filename)
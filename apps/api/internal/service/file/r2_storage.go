@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"image"
+	"io"
 	"mime/multipart"
 	"strings"
 
@@ -122,6 +123,49 @@ func (s *R2Storage) UploadImage(file *multipart.FileHeader) (string, error) {
 	return s.GetFileURL(filename), nil
 }
 
+// UploadFile uploads generated content to R2 under the given filename
+func (s *R2Storage) UploadFile(filename string, data []byte, contentType string) (string, error) {
+	// Add baseURL as prefix if provided
+	key := filename
+	if s.baseURL != "" {
+		prefix := strings.TrimPrefix(s.baseURL, "/")
+		key = fmt.Sprintf("%s/%s", prefix, filename)
+	}
+
+	_, err := s.client.PutObject(context.TODO(), &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload to R2: %w", err)
+	}
+
+	return s.GetFileURL(filename), nil
+}
+
+// ReadFile downloads a file from R2 storage
+func (s *R2Storage) ReadFile(filename string) ([]byte, error) {
+	// Add baseURL as prefix if provided
+	key := filename
+	if s.baseURL != "" {
+		prefix := strings.TrimPrefix(s.baseURL, "/")
+		key = fmt.Sprintf("%s/%s", prefix, filename)
+	}
+
+	out, err := s.client.GetObject(context.TODO(), &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download from R2: %w", err)
+	}
+	defer out.Body.Close()
+
+	return io.ReadAll(out.Body)
+}
+
 // DeleteFile deletes a file from R2 storage
 func (s *R2Storage) DeleteFile(filename string) error {
 	// Add baseURL as prefix if provided
@@ -6,6 +6,10 @@ import "mime/multipart"
 type StorageProvider interface {
 	// UploadImage uploads and compresses an image file, returns the public URL
 	UploadImage(file *multipart.FileHeader) (string, error)
+	// UploadFile stores generated content such as a PDF under the given filename, returns the public URL
+	UploadFile(filename string, data []byte, contentType string) (string, error)
+	// ReadFile returns the content of a stored file by filename
+	ReadFile(filename string) ([]byte, error)
 	// DeleteFile deletes a file from storage by filename
 	DeleteFile(filename string) error
 	// GetFileURL returns the public URL for a file
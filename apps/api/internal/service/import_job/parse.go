@@ -0,0 +1,219 @@
+package import_job
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/import_job"
+	"github.com/xuri/excelize/v2"
+)
+
+// ParseFile reads the header row and the non-blank data rows of a CSV or XLSX file.
+// CSV files may be comma, semicolon or tab separated; XLSX files are read from the first sheet.
+func ParseFile(fileName string, r io.Reader) ([]string, []import_job.Row, error) {
+	data, err := io.ReadAll(io.LimitReader(r, import_job.MaxFileSize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) > import_job.MaxFileSize {
+		return nil, nil, fmt.Errorf("%w: the file is larger than %dMB", ErrInvalidFile, import_job.MaxFileSize/(1024*1024))
+	}
+
+	var records [][]string
+	var lines []int
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		records, lines, err = readCSV(data)
+	case ".xlsx":
+		records, lines, err = readXLSX(data)
+	default:
+		return nil, nil, fmt.Errorf("%w: upload a .csv or .xlsx file", ErrInvalidFile)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("%w: the file is empty", ErrInvalidFile)
+	}
+
+	headers, err := readHeaders(records[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := make([]import_job.Row, 0, len(records)-1)
+	for i, record := range records[1:] {
+		if isBlank(record) {
+			continue
+		}
+		if len(rows) == import_job.MaxRows {
+			return nil, nil, fmt.Errorf("%w: the file has more than %d rows", ErrInvalidFile, import_job.MaxRows)
+		}
+		rows = append(rows, import_job.Row{Line: lines[i+1], Cells: record})
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("%w: the file has no data rows", ErrInvalidFile)
+	}
+	return headers, rows, nil
+}
+
+// readCSV reads all records of a CSV file and the line each record starts on
+func readCSV(data []byte) ([][]string, []int, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // UTF-8 BOM written by Excel
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var records [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+	return records, lines, nil
+}
+
+// detectDelimiter picks the separator used most in the first line; Excel in Indonesian locale saves with semicolons
+func detectDelimiter(data []byte) rune {
+	firstLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		firstLine = data[:i]
+	}
+	delimiter, most := ',', bytes.Count(firstLine, []byte(","))
+	for _, candidate := range []rune{';', '\t'} {
+		if n := bytes.Count(firstLine, []byte(string(candidate))); n > most {
+			delimiter, most = candidate, n
+		}
+	}
+	return delimiter
+}
+
+// readXLSX reads all rows of the first sheet of an XLSX file and their sheet row numbers
+func readXLSX(data []byte) ([][]string, []int, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil, fmt.Errorf("%w: the workbook has no sheets", ErrInvalidFile)
+	}
+	// Raw values keep numbers such as phone numbers and prices free of display formatting
+	records, err := f.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	// Leading blank rows are skipped so the header can start lower on the sheet
+	lines := make([]int, 0, len(records))
+	start := 0
+	for start < len(records) && isBlank(records[start]) {
+		start++
+	}
+	for i := start; i < len(records); i++ {
+		lines = append(lines, i+1)
+	}
+	return records[start:], lines, nil
+}
+
+// readHeaders trims the header cells, names blank ones after their position and rejects repeated headers
+func readHeaders(record []string) ([]string, error) {
+	headers := make([]string, len(record))
+	seen := make(map[string]bool, len(record))
+	for i, cell := range record {
+		header := strings.TrimSpace(cell)
+		if header == "" {
+			header = fmt.Sprintf("Column %d", i+1)
+		}
+		if seen[strings.ToLower(header)] {
+			return nil, fmt.Errorf("%w: column header %q appears more than once", ErrInvalidFile, header)
+		}
+		seen[strings.ToLower(header)] = true
+		headers[i] = header
+	}
+	return headers, nil
+}
+
+func isBlank(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseRupiah parses an amount in rupiah such as 1500000, 1.500.000, 1,500,000.50 or Rp 1.500.000,50 into sen
+func parseRupiah(value string) (int64, error) {
+	cleaned := strings.ToLower(strings.TrimSpace(value))
+	cleaned = strings.TrimPrefix(cleaned, "rp")
+	cleaned = strings.NewReplacer(" ", "", "\u00a0", "").Replace(cleaned) // Spaces and non-breaking spaces
+
+	dots, commas := strings.Count(cleaned, "."), strings.Count(cleaned, ",")
+	switch {
+	case dots > 0 && commas > 0:
+		// The separator that comes last is the decimal one
+		if strings.LastIndex(cleaned, ",") > strings.LastIndex(cleaned, ".") {
+			cleaned = strings.ReplaceAll(cleaned, ".", "")
+			cleaned = strings.Replace(cleaned, ",", ".", 1)
+		} else {
+			cleaned = strings.ReplaceAll(cleaned, ",", "")
+		}
+	case dots > 1 || (dots == 1 && isThousandsGroup(cleaned, ".")):
+		cleaned = strings.ReplaceAll(cleaned, ".", "")
+	case commas > 1 || (commas == 1 && isThousandsGroup(cleaned, ",")):
+		cleaned = strings.ReplaceAll(cleaned, ",", "")
+	case commas == 1:
+		cleaned = strings.Replace(cleaned, ",", ".", 1)
+	}
+
+	amount, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil || amount < 0 || math.IsInf(amount, 0) || math.IsNaN(amount) {
+		return 0, fmt.Errorf("%q is not a valid amount", value)
+	}
+	return int64(math.Round(amount * 100)), nil
+}
+
+// isThousandsGroup reports whether the only separator in the value is followed by exactly three digits
+func isThousandsGroup(value string, separator string) bool {
+	i := strings.Index(value, separator)
+	return i > 0 && len(value)-i-1 == 3
+}
+
+// parseWholeNumber parses a non-negative whole number, allowing thousands separators and a zero fraction (Excel writes 10 as 10.0)
+func parseWholeNumber(value string) (int, error) {
+	sen, err := parseRupiah(value)
+	if err != nil || sen%100 != 0 || sen/100 > math.MaxInt32 {
+		return 0, fmt.Errorf("%q is not a valid whole number", value)
+	}
+	return int(sen / 100), nil
+}
+
+// parseYesNo parses yes/no answers in English and Indonesian
+func parseYesNo(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "y", "true", "1", "ya":
+		return true, nil
+	case "no", "n", "false", "0", "tidak":
+		return false, nil
+	}
+	return false, fmt.Errorf("%q is not yes or no", value)
+}
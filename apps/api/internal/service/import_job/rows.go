@@ -0,0 +1,451 @@
+package import_job
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/contact"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/duplicate"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/import_job"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/product"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// requestValidator checks rows against the binding rules of the create request DTOs, like the API does
+var requestValidator = newRequestValidator()
+
+func newRequestValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		return strings.Split(f.Tag.Get("json"), ",")[0]
+	})
+	return v
+}
+
+// rowPlan represents how a row will be written: the create request built from it,
+// the errors keeping it from being imported and the existing record it duplicates
+type rowPlan struct {
+	line      int
+	values    map[string]string
+	errors    []import_job.RowError
+	duplicate *import_job.RowDuplicate
+	record    duplicate.Record
+	account   *account.CreateAccountRequest
+	contact   *contact.CreateContactRequest
+	lead      *lead.CreateLeadRequest
+	product   *product.CreateProductRequest
+}
+
+func (p *rowPlan) addError(field string, message string) {
+	p.errors = append(p.errors, import_job.RowError{Row: p.line, Field: field, Message: message})
+}
+
+func (p *rowPlan) hasError(field string) bool {
+	for _, e := range p.errors {
+		if e.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// validate adds an error for each binding rule the request breaks, skipping fields that already failed to resolve
+func (p *rowPlan) validate(req interface{}) {
+	err := requestValidator.Struct(req)
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return
+	}
+	for _, fe := range validationErrors {
+		// category_id, role_id and account_id are imported from the category, role and account columns
+		field := strings.TrimSuffix(fe.Field(), "_id")
+		if !p.hasError(field) {
+			p.addError(field, validationMessage(fe))
+		}
+	}
+}
+
+// validationMessage describes a broken binding rule for a spreadsheet user
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "min":
+		if fe.Kind() == reflect.String {
+			return "must be at least " + fe.Param() + " characters"
+		}
+		return "must be at least " + fe.Param()
+	case "max":
+		if fe.Kind() == reflect.String {
+			return "must be at most " + fe.Param() + " characters"
+		}
+		return "must be at most " + fe.Param()
+	}
+	return "is invalid"
+}
+
+// lookups caches the names resolved to IDs during one validation or import run
+type lookups struct {
+	categories        map[string]string // Lowercase account category name and code to ID
+	contactRoles      map[string]string // Lowercase contact role name and code to ID
+	productCategories map[string]string // Lowercase product category name and slug to ID
+	users             map[string]string // Lowercase email to user ID; empty when no user has the email
+	accounts          map[string][]string
+}
+
+func (s *Service) newLookups(entityType string) (*lookups, error) {
+	lk := &lookups{
+		categories:        make(map[string]string),
+		contactRoles:      make(map[string]string),
+		productCategories: make(map[string]string),
+		users:             make(map[string]string),
+		accounts:          make(map[string][]string),
+	}
+
+	switch entityType {
+	case import_job.EntityAccount:
+		categories, err := s.categoryRepo.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range categories {
+			lk.categories[strings.ToLower(c.Code)] = c.ID
+			lk.categories[strings.ToLower(c.Name)] = c.ID
+		}
+	case import_job.EntityContact:
+		roles, err := s.contactRoleRepo.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range roles {
+			lk.contactRoles[strings.ToLower(r.Code)] = r.ID
+			lk.contactRoles[strings.ToLower(r.Name)] = r.ID
+		}
+	case import_job.EntityProduct:
+		categories, err := s.productCategoryRepo.List(&product.ListProductCategoriesRequest{})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range categories {
+			lk.productCategories[strings.ToLower(c.Slug)] = c.ID
+			lk.productCategories[strings.ToLower(c.Name)] = c.ID
+		}
+	}
+	return lk, nil
+}
+
+// planRow builds the create request of a row, validates it and looks for the existing record it duplicates
+func (s *Service) planRow(entityType string, duplicateMode string, values map[string]string, lk *lookups, line int) (*rowPlan, error) {
+	p := &rowPlan{line: line, values: values}
+
+	var err error
+	switch entityType {
+	case import_job.EntityAccount:
+		err = s.planAccount(p, lk)
+	case import_job.EntityContact:
+		err = s.planContact(p, lk)
+	case import_job.EntityLead:
+		err = s.planLead(p, lk)
+	case import_job.EntityProduct:
+		s.planProduct(p, lk)
+	}
+	if err != nil || len(p.errors) > 0 {
+		return p, err
+	}
+
+	match, err := s.findDuplicate(entityType, p)
+	if err != nil || match == nil {
+		return p, err
+	}
+	match.Row = line
+	match.Action = import_job.ActionSkip
+	if duplicateMode == import_job.DuplicateUpsert && match.Score >= import_job.UpsertMinScore {
+		match.Action = import_job.ActionUpdate
+	}
+	p.duplicate = match
+	return p, nil
+}
+
+func (s *Service) planAccount(p *rowPlan, lk *lookups) error {
+	v := p.values
+	req := &account.CreateAccountRequest{
+		Name:             v["name"],
+		Address:          v["address"],
+		City:             v["city"],
+		Province:         v["province"],
+		Phone:            v["phone"],
+		Email:            v["email"],
+		Status:           enumValue(v["status"]),
+		IgnoreDuplicates: true, // Duplicates are skipped or updated by the import itself
+	}
+	if v["category"] != "" {
+		if id, ok := lk.categories[strings.ToLower(v["category"])]; ok {
+			req.CategoryID = id
+		} else {
+			p.addError("category", fmt.Sprintf("no account category named %q", v["category"]))
+		}
+	}
+	if err := s.resolveUser(p, lk, &req.AssignedTo); err != nil {
+		return err
+	}
+
+	p.validate(req)
+	p.account = req
+	p.record = duplicate.Record{Name: req.Name, Email: req.Email, Phone: req.Phone, City: req.City}
+	return nil
+}
+
+func (s *Service) planContact(p *rowPlan, lk *lookups) error {
+	v := p.values
+	req := &contact.CreateContactRequest{
+		Name:             v["name"],
+		Phone:            v["phone"],
+		Email:            v["email"],
+		Position:         v["position"],
+		Notes:            v["notes"],
+		IgnoreDuplicates: true, // Duplicates are skipped or updated by the import itself
+	}
+	if v["role"] != "" {
+		if id, ok := lk.contactRoles[strings.ToLower(v["role"])]; ok {
+			req.RoleID = id
+		} else {
+			p.addError("role", fmt.Sprintf("no contact role named %q", v["role"]))
+		}
+	}
+	if v["account"] != "" {
+		accountID, err := s.resolveAccount(p, lk, v["account"])
+		if err != nil {
+			return err
+		}
+		req.AccountID = accountID
+	}
+
+	p.validate(req)
+	p.contact = req
+	p.record = duplicate.Record{Name: req.Name, Email: req.Email, Phone: req.Phone, AccountID: req.AccountID}
+	return nil
+}
+
+func (s *Service) planLead(p *rowPlan, lk *lookups) error {
+	v := p.values
+	website := v["website"]
+	if website != "" && !strings.Contains(website, "://") {
+		website = "https://" + website
+	}
+	req := &lead.CreateLeadRequest{
+		FirstName:        v["first_name"],
+		LastName:         v["last_name"],
+		CompanyName:      v["company_name"],
+		Email:            v["email"],
+		Phone:            v["phone"],
+		JobTitle:         v["job_title"],
+		Industry:         v["industry"],
+		LeadSource:       enumValue(v["lead_source"]),
+		LeadStatus:       enumValue(v["lead_status"]),
+		Notes:            v["notes"],
+		Address:          v["address"],
+		City:             v["city"],
+		Province:         v["province"],
+		PostalCode:       v["postal_code"],
+		Country:          v["country"],
+		Website:          website,
+		IgnoreDuplicates: true, // Duplicates are skipped or updated by the import itself
+	}
+	if err := s.resolveUser(p, lk, &req.AssignedTo); err != nil {
+		return err
+	}
+
+	p.validate(req)
+	p.lead = req
+	p.record = duplicate.Record{
+		Name:    strings.TrimSpace(req.FirstName + " " + req.LastName),
+		Company: req.CompanyName,
+		Email:   req.Email,
+		Phone:   req.Phone,
+		City:    req.City,
+	}
+	return nil
+}
+
+func (s *Service) planProduct(p *rowPlan, lk *lookups) {
+	v := p.values
+	req := &product.CreateProductRequest{
+		Name:        v["name"],
+		SKU:         v["sku"],
+		Barcode:     v["barcode"],
+		Status:      enumValue(v["status"]),
+		Description: v["description"],
+	}
+	if v["category"] != "" {
+		if id, ok := lk.productCategories[strings.ToLower(v["category"])]; ok {
+			req.CategoryID = id
+		} else {
+			p.addError("category", fmt.Sprintf("no product category named %q", v["category"]))
+		}
+	}
+	if v["price"] == "" {
+		p.addError("price", "is required")
+	} else if price, err := parseRupiah(v["price"]); err != nil {
+		p.addError("price", err.Error())
+	} else {
+		req.Price = price
+	}
+	if v["cost"] != "" {
+		if cost, err := parseRupiah(v["cost"]); err != nil {
+			p.addError("cost", err.Error())
+		} else {
+			req.Cost = cost
+		}
+	}
+	if v["stock"] != "" {
+		if stock, err := parseWholeNumber(v["stock"]); err != nil {
+			p.addError("stock", err.Error())
+		} else {
+			req.Stock = stock
+		}
+	}
+	if v["taxable"] != "" {
+		if taxable, err := parseYesNo(v["taxable"]); err != nil {
+			p.addError("taxable", err.Error())
+		} else {
+			req.Taxable = &taxable
+		}
+	}
+
+	p.validate(req)
+	p.product = req
+}
+
+// resolveUser sets the ID of the user whose email is in the assigned_to column
+func (s *Service) resolveUser(p *rowPlan, lk *lookups, assignedTo *string) error {
+	email := strings.ToLower(p.values["assigned_to"])
+	if email == "" {
+		return nil
+	}
+	id, ok := lk.users[email]
+	if !ok {
+		u, err := s.userRepo.FindByEmail(email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if u != nil {
+			id = u.ID
+		}
+		lk.users[email] = id
+	}
+	if id == "" {
+		p.addError("assigned_to", fmt.Sprintf("no user with email %q", p.values["assigned_to"]))
+		return nil
+	}
+	*assignedTo = id
+	return nil
+}
+
+// resolveAccount returns the ID of the account a contact row belongs to, given as an account ID or a unique name
+func (s *Service) resolveAccount(p *rowPlan, lk *lookups, value string) (string, error) {
+	key := strings.ToLower(value)
+	ids, ok := lk.accounts[key]
+	if !ok {
+		if _, err := uuid.Parse(value); err == nil {
+			a, err := s.accountRepo.FindByID(value)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", err
+			}
+			if a != nil {
+				ids = []string{a.ID}
+			}
+		} else {
+			accounts, err := s.accountRepo.FindByName(value)
+			if err != nil {
+				return "", err
+			}
+			for _, a := range accounts {
+				ids = append(ids, a.ID)
+			}
+		}
+		lk.accounts[key] = ids
+	}
+
+	switch len(ids) {
+	case 0:
+		p.addError("account", fmt.Sprintf("no account named %q", value))
+		return "", nil
+	case 1:
+		return ids[0], nil
+	}
+	p.addError("account", fmt.Sprintf("%d accounts are named %q; use the account ID instead", len(ids), value))
+	return "", nil
+}
+
+// findDuplicate returns the existing record most likely duplicated by a valid row, or nil
+func (s *Service) findDuplicate(entityType string, p *rowPlan) (*import_job.RowDuplicate, error) {
+	if entityType == import_job.EntityProduct {
+		existing, err := s.productRepo.FindBySKU(p.product.SKU)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return &import_job.RowDuplicate{MatchID: existing.ID, MatchName: existing.Name, Score: 100}, nil
+	}
+	if s.duplicateChecker == nil {
+		return nil, nil
+	}
+
+	var matches []duplicate.Match
+	var err error
+	switch entityType {
+	case import_job.EntityAccount:
+		matches, err = s.duplicateChecker.FindAccountMatches(p.record)
+	case import_job.EntityContact:
+		matches, err = s.duplicateChecker.FindContactMatches(p.record)
+	case import_job.EntityLead:
+		matches, err = s.duplicateChecker.FindLeadMatches(p.record)
+	}
+	if err != nil || len(matches) == 0 {
+		return nil, err
+	}
+	best := matches[0]
+	return &import_job.RowDuplicate{MatchID: best.ID, MatchName: best.Name, Score: best.Score}, nil
+}
+
+// fileKeys returns the normalized values identifying the record of a valid row, to spot repeated rows in a file
+func fileKeys(entityType string, p *rowPlan) []string {
+	if entityType == import_job.EntityProduct {
+		return []string{"sku:" + strings.ToLower(p.product.SKU)}
+	}
+
+	var keys []string
+	if email := duplicate.NormalizeEmail(p.record.Email); email != "" {
+		keys = append(keys, "email:"+email)
+	}
+	if phone := duplicate.NormalizePhone(p.record.Phone); phone != "" {
+		keys = append(keys, "phone:"+phone)
+	}
+	if name := duplicate.NormalizeName(p.record.Name); name != "" && entityType != import_job.EntityLead {
+		keys = append(keys, "name:"+name+"|"+duplicate.NormalizeCity(p.record.City)+"|"+p.record.AccountID)
+	}
+	return keys
+}
+
+// enumValue turns spreadsheet spellings such as "Cold Call" or "Active" into API values such as cold_call and active
+func enumValue(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return strings.Join(strings.FieldsFunc(value, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "_")
+}
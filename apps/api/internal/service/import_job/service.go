@@ -0,0 +1,608 @@
+package import_job
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/contact"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/duplicate"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/import_job"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/product"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrImportJobNotFound  = errors.New("import job not found")
+	ErrInvalidEntityType  = errors.New("invalid import entity type")
+	ErrInvalidFile        = errors.New("invalid import file")
+	ErrImportNotAllowed   = errors.New("import job is already importing or finished")
+	ErrInvalidMappingJSON = errors.New("mapping must be a JSON object of field keys to column headers")
+)
+
+// progressInterval is the number of rows written between two progress updates of an import job
+const progressInterval = 100
+
+// MappingError is returned when a column mapping cannot be used
+type MappingError struct {
+	Problems []string
+}
+
+func (e *MappingError) Error() string {
+	return "invalid column mapping: " + strings.Join(e.Problems, "; ")
+}
+
+// DuplicateCheckerInterface defines interface for finding existing records an imported row duplicates
+type DuplicateCheckerInterface interface {
+	FindAccountMatches(record duplicate.Record) ([]duplicate.Match, error)
+	FindContactMatches(record duplicate.Record) ([]duplicate.Match, error)
+	FindLeadMatches(record duplicate.Record) ([]duplicate.Match, error)
+}
+
+// AccountWriterInterface defines interface for writing imported accounts with the account business rules
+type AccountWriterInterface interface {
+	Create(req *account.CreateAccountRequest) (*account.AccountResponse, error)
+	Update(id string, req *account.UpdateAccountRequest) (*account.AccountResponse, error)
+}
+
+// ContactWriterInterface defines interface for writing imported contacts with the contact business rules
+type ContactWriterInterface interface {
+	Create(req *contact.CreateContactRequest) (*contact.ContactResponse, error)
+	Update(id string, req *contact.UpdateContactRequest) (*contact.ContactResponse, error)
+}
+
+// LeadWriterInterface defines interface for writing imported leads, so they are scored and routed like other new leads
+type LeadWriterInterface interface {
+	Create(req *lead.CreateLeadRequest, createdBy string) (*lead.LeadResponse, error)
+	Update(id string, req *lead.UpdateLeadRequest) (*lead.LeadResponse, error)
+}
+
+// ProductWriterInterface defines interface for writing imported products with the product business rules
+type ProductWriterInterface interface {
+	CreateProduct(req *product.CreateProductRequest) (*product.ProductResponse, error)
+	UpdateProduct(id string, req *product.UpdateProductRequest) (*product.ProductResponse, error)
+}
+
+type Service struct {
+	importJobRepo       interfaces.ImportJobRepository
+	accountRepo         interfaces.AccountRepository
+	leadRepo            interfaces.LeadRepository
+	productRepo         interfaces.ProductRepository
+	categoryRepo        interfaces.CategoryRepository
+	contactRoleRepo     interfaces.ContactRoleRepository
+	productCategoryRepo interfaces.ProductCategoryRepository
+	userRepo            interfaces.UserRepository
+	accountWriter       AccountWriterInterface
+	contactWriter       ContactWriterInterface
+	leadWriter          LeadWriterInterface
+	productWriter       ProductWriterInterface
+	duplicateChecker    DuplicateCheckerInterface
+}
+
+func NewService(
+	importJobRepo interfaces.ImportJobRepository,
+	accountRepo interfaces.AccountRepository,
+	leadRepo interfaces.LeadRepository,
+	productRepo interfaces.ProductRepository,
+	categoryRepo interfaces.CategoryRepository,
+	contactRoleRepo interfaces.ContactRoleRepository,
+	productCategoryRepo interfaces.ProductCategoryRepository,
+	userRepo interfaces.UserRepository,
+	accountWriter AccountWriterInterface,
+	contactWriter ContactWriterInterface,
+	leadWriter LeadWriterInterface,
+	productWriter ProductWriterInterface,
+) *Service {
+	return &Service{
+		importJobRepo:       importJobRepo,
+		accountRepo:         accountRepo,
+		leadRepo:            leadRepo,
+		productRepo:         productRepo,
+		categoryRepo:        categoryRepo,
+		contactRoleRepo:     contactRoleRepo,
+		productCategoryRepo: productCategoryRepo,
+		userRepo:            userRepo,
+		accountWriter:       accountWriter,
+		contactWriter:       contactWriter,
+		leadWriter:          leadWriter,
+		productWriter:       productWriter,
+		duplicateChecker:    nil, // Will be set via SetDuplicateChecker if needed
+	}
+}
+
+// SetDuplicateChecker sets the checker used to find existing accounts, contacts and leads an imported row duplicates.
+// Without it only products are matched, by SKU.
+func (s *Service) SetDuplicateChecker(checker DuplicateCheckerInterface) {
+	s.duplicateChecker = checker
+}
+
+// Fields returns the importable fields of an entity type
+func (s *Service) Fields(entityType string) (*import_job.FieldsResponse, error) {
+	if !import_job.IsEntityType(entityType) {
+		return nil, ErrInvalidEntityType
+	}
+	return &import_job.FieldsResponse{EntityType: entityType, Fields: import_job.Fields[entityType]}, nil
+}
+
+// Create parses an uploaded file into a new import job and dry-runs it.
+// Columns are mapped from matching headers unless the request maps them; the job waits for a mapping
+// when required fields are left unmapped.
+func (s *Service) Create(req *import_job.CreateImportRequest, fileName string, file io.Reader, createdBy string) (*import_job.ImportJobResponse, error) {
+	headers, rows, err := ParseFile(fileName, file)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := import_job.AutoMapping(req.EntityType, headers)
+	if req.Mapping != "" {
+		var requested map[string]string
+		if err := json.Unmarshal([]byte(req.Mapping), &requested); err != nil {
+			return nil, ErrInvalidMappingJSON
+		}
+		for key, header := range requested {
+			if header == "" {
+				delete(mapping, key)
+			} else {
+				mapping[key] = header
+			}
+		}
+	}
+
+	duplicateMode := req.DuplicateMode
+	if duplicateMode == "" {
+		duplicateMode = import_job.DuplicateSkip
+	}
+
+	job := &import_job.ImportJob{
+		EntityType:    req.EntityType,
+		FileName:      fileName,
+		Status:        import_job.StatusNeedsMapping,
+		DuplicateMode: duplicateMode,
+		Headers:       mustJSON(headers),
+		Mapping:       mustJSON(mapping),
+		Rows:          mustJSON(rows),
+		TotalRows:     len(rows),
+		CreatedBy:     createdBy,
+	}
+	if len(import_job.MappingProblems(job.EntityType, mapping, headers)) == 0 {
+		if err := s.dryRun(job, headers, mapping, rows); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.importJobRepo.Create(job); err != nil {
+		return nil, err
+	}
+	return job.ToImportJobResponse(), nil
+}
+
+// Validate dry-runs an import job again, with a new column mapping or duplicate mode when given
+func (s *Service) Validate(id string, req *import_job.ValidateImportRequest) (*import_job.ImportJobResponse, error) {
+	job, err := s.findJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != import_job.StatusNeedsMapping && job.Status != import_job.StatusValidated {
+		return nil, ErrImportNotAllowed
+	}
+
+	var headers []string
+	var mapping map[string]string
+	_ = json.Unmarshal(job.Headers, &headers)
+	_ = json.Unmarshal(job.Mapping, &mapping)
+	if req.Mapping != nil {
+		mapping = req.Mapping
+	}
+	if problems := import_job.MappingProblems(job.EntityType, mapping, headers); len(problems) > 0 {
+		return nil, &MappingError{Problems: problems}
+	}
+	if req.DuplicateMode != "" {
+		job.DuplicateMode = req.DuplicateMode
+	}
+
+	rows, err := s.importJobRepo.FindRows(job.ID)
+	if err != nil {
+		return nil, err
+	}
+	job.Mapping = mustJSON(mapping)
+	if err := s.dryRun(job, headers, mapping, rows); err != nil {
+		return nil, err
+	}
+
+	if err := s.importJobRepo.Update(job); err != nil {
+		return nil, err
+	}
+	return job.ToImportJobResponse(), nil
+}
+
+// Commit starts writing the rows of a validated import job in the background.
+// Rows are validated again as they are written, so records created since the dry run are taken into account.
+func (s *Service) Commit(id string) (*import_job.ImportJobResponse, error) {
+	if _, err := s.findJob(id); err != nil {
+		return nil, err
+	}
+	started, err := s.importJobRepo.Start(id)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, ErrImportNotAllowed
+	}
+
+	job, err := s.findJob(id)
+	if err != nil {
+		return nil, err
+	}
+	go s.run(job.ID)
+	return job.ToImportJobResponse(), nil
+}
+
+// GetByID returns an import job with its validation report or import progress
+func (s *Service) GetByID(id string) (*import_job.ImportJobResponse, error) {
+	job, err := s.findJob(id)
+	if err != nil {
+		return nil, err
+	}
+	return job.ToImportJobResponse(), nil
+}
+
+// List returns a list of import jobs with pagination
+func (s *Service) List(req *import_job.ListImportJobsRequest) ([]import_job.ImportJobResponse, *PaginationResult, error) {
+	jobs, total, err := s.importJobRepo.List(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]import_job.ImportJobResponse, len(jobs))
+	for i := range jobs {
+		responses[i] = *jobs[i].ToImportJobResponse()
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	pagination := &PaginationResult{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      int(total),
+		TotalPages: int((total + int64(perPage) - 1) / int64(perPage)),
+	}
+
+	return responses, pagination, nil
+}
+
+// FailInterrupted fails the jobs left importing when the server stopped; their rows were partly written
+func (s *Service) FailInterrupted() (int64, error) {
+	return s.importJobRepo.MarkImportingAsFailed("import interrupted by a server restart; rows up to processed_rows were written")
+}
+
+// dryRun validates every row and reports errors and duplicates without writing anything
+func (s *Service) dryRun(job *import_job.ImportJob, headers []string, mapping map[string]string, rows []import_job.Row) error {
+	lk, err := s.newLookups(job.EntityType)
+	if err != nil {
+		return err
+	}
+
+	report := newReport()
+	validRows, duplicateRows := 0, 0
+	seen := make(map[string]int) // File key to the line of the first row with it
+	for _, row := range rows {
+		values := import_job.Values(mapping, headers, row)
+		plan, err := s.planRow(job.EntityType, job.DuplicateMode, values, lk, row.Line)
+		if err != nil {
+			return err
+		}
+		if len(plan.errors) > 0 {
+			report.addErrors(plan.errors)
+			continue
+		}
+		validRows++
+
+		dup := plan.duplicate
+		if dup == nil {
+			for _, key := range fileKeys(job.EntityType, plan) {
+				if line, ok := seen[key]; ok {
+					// The earlier row is written first, so this one will match the record it creates
+					dup = &import_job.RowDuplicate{Row: row.Line, MatchRow: line, Score: 100, Action: import_job.ActionSkip}
+					if job.DuplicateMode == import_job.DuplicateUpsert {
+						dup.Action = import_job.ActionUpdate
+					}
+					break
+				}
+			}
+		}
+		for _, key := range fileKeys(job.EntityType, plan) {
+			if _, ok := seen[key]; !ok {
+				seen[key] = row.Line
+			}
+		}
+		if dup != nil {
+			duplicateRows++
+			report.addDuplicate(*dup)
+		}
+	}
+
+	job.Status = import_job.StatusValidated
+	job.TotalRows = len(rows)
+	job.ValidRows = validRows
+	job.InvalidRows = len(rows) - validRows
+	job.DuplicateRows = duplicateRows
+	report.apply(job)
+	return nil
+}
+
+// run imports a started job in the background and records how it ended
+func (s *Service) run(jobID string) {
+	job, err := s.findJob(jobID)
+	if err != nil {
+		log.Printf("Warning: Failed to load import job %s: %v", jobID, err)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("import stopped unexpectedly: %v", r)
+		}
+		if err != nil {
+			log.Printf("Warning: Import job %s failed: %v", job.ID, err)
+			job.Status = import_job.StatusFailed
+			job.ErrorMessage = err.Error()
+		}
+		now := time.Now()
+		job.CompletedAt = &now
+		if err := s.importJobRepo.Update(job); err != nil {
+			log.Printf("Warning: Failed to save import job %s: %v", job.ID, err)
+		}
+	}()
+
+	err = s.process(job)
+}
+
+// process writes the rows of an import job: invalid rows fail, duplicates are skipped or update the existing
+// record and the other rows create new records. Progress is saved every progressInterval rows.
+func (s *Service) process(job *import_job.ImportJob) error {
+	var headers []string
+	var mapping map[string]string
+	_ = json.Unmarshal(job.Headers, &headers)
+	_ = json.Unmarshal(job.Mapping, &mapping)
+
+	rows, err := s.importJobRepo.FindRows(job.ID)
+	if err != nil {
+		return err
+	}
+	lk, err := s.newLookups(job.EntityType)
+	if err != nil {
+		return err
+	}
+
+	report := newReport()
+	job.ProcessedRows, job.CreatedCount, job.UpdatedCount, job.SkippedCount, job.FailedCount = 0, 0, 0, 0, 0
+	for i, row := range rows {
+		values := import_job.Values(mapping, headers, row)
+		plan, err := s.planRow(job.EntityType, job.DuplicateMode, values, lk, row.Line)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case len(plan.errors) > 0:
+			job.FailedCount++
+			report.addErrors(plan.errors)
+		case plan.duplicate != nil && plan.duplicate.Action == import_job.ActionSkip:
+			job.SkippedCount++
+			report.addDuplicate(*plan.duplicate)
+		case plan.duplicate != nil:
+			if err := s.update(job.EntityType, plan.duplicate.MatchID, plan); err != nil {
+				job.FailedCount++
+				report.addErrors([]import_job.RowError{{Row: row.Line, Message: err.Error()}})
+			} else {
+				job.UpdatedCount++
+				report.addDuplicate(*plan.duplicate)
+			}
+		default:
+			if err := s.create(job.EntityType, plan, job.CreatedBy); err != nil {
+				job.FailedCount++
+				report.addErrors([]import_job.RowError{{Row: row.Line, Message: err.Error()}})
+			} else {
+				job.CreatedCount++
+			}
+		}
+
+		job.ProcessedRows = i + 1
+		if job.ProcessedRows%progressInterval == 0 {
+			report.apply(job)
+			if err := s.importJobRepo.Update(job); err != nil {
+				log.Printf("Warning: Failed to save progress of import job %s: %v", job.ID, err)
+			}
+		}
+	}
+
+	job.Status = import_job.StatusCompleted
+	report.apply(job)
+	return nil
+}
+
+// create writes a valid row as a new record
+func (s *Service) create(entityType string, p *rowPlan, createdBy string) error {
+	var err error
+	switch entityType {
+	case import_job.EntityAccount:
+		_, err = s.accountWriter.Create(p.account)
+	case import_job.EntityContact:
+		_, err = s.contactWriter.Create(p.contact)
+	case import_job.EntityLead:
+		_, err = s.leadWriter.Create(p.lead, createdBy)
+	case import_job.EntityProduct:
+		_, err = s.productWriter.CreateProduct(p.product)
+	}
+	return err
+}
+
+// update overwrites an existing record with the non-empty values of a row
+func (s *Service) update(entityType string, id string, p *rowPlan) error {
+	switch entityType {
+	case import_job.EntityAccount:
+		req := p.account
+		assignedTo := req.AssignedTo
+		if assignedTo == "" {
+			// An empty assignee unassigns the account, so keep the current one
+			existing, err := s.accountRepo.FindByID(id)
+			if err != nil {
+				return err
+			}
+			if existing.AssignedTo != nil {
+				assignedTo = *existing.AssignedTo
+			}
+		}
+		_, err := s.accountWriter.Update(id, &account.UpdateAccountRequest{
+			Name:       req.Name,
+			CategoryID: req.CategoryID,
+			Address:    req.Address,
+			City:       req.City,
+			Province:   req.Province,
+			Phone:      req.Phone,
+			Email:      req.Email,
+			Status:     req.Status,
+			AssignedTo: assignedTo,
+		})
+		return err
+	case import_job.EntityContact:
+		req := p.contact
+		// The account is left as is: a contact matched by email may have moved to another account
+		_, err := s.contactWriter.Update(id, &contact.UpdateContactRequest{
+			Name:     req.Name,
+			RoleID:   req.RoleID,
+			Phone:    req.Phone,
+			Email:    req.Email,
+			Position: req.Position,
+			Notes:    req.Notes,
+		})
+		return err
+	case import_job.EntityLead:
+		req := p.lead
+		assignedTo := req.AssignedTo
+		if assignedTo == "" {
+			// An empty assignee unassigns the lead, so keep the current one
+			existing, err := s.leadRepo.FindByID(id)
+			if err != nil {
+				return err
+			}
+			if existing.AssignedTo != nil {
+				assignedTo = *existing.AssignedTo
+			}
+		}
+		_, err := s.leadWriter.Update(id, &lead.UpdateLeadRequest{
+			FirstName:   req.FirstName,
+			LastName:    req.LastName,
+			CompanyName: req.CompanyName,
+			Email:       req.Email,
+			Phone:       req.Phone,
+			JobTitle:    req.JobTitle,
+			Industry:    req.Industry,
+			LeadSource:  req.LeadSource,
+			LeadStatus:  req.LeadStatus,
+			AssignedTo:  assignedTo,
+			Notes:       req.Notes,
+			Address:     req.Address,
+			City:        req.City,
+			Province:    req.Province,
+			PostalCode:  req.PostalCode,
+			Country:     req.Country,
+			Website:     req.Website,
+		})
+		return err
+	case import_job.EntityProduct:
+		req := p.product
+		update := &product.UpdateProductRequest{
+			Name:        req.Name,
+			SKU:         req.SKU,
+			Barcode:     req.Barcode,
+			Price:       &req.Price,
+			CategoryID:  req.CategoryID,
+			Status:      req.Status,
+			Taxable:     req.Taxable,
+			Description: req.Description,
+		}
+		// Cost and stock are only overwritten when the row has them
+		if p.values["cost"] != "" {
+			update.Cost = &req.Cost
+		}
+		if p.values["stock"] != "" {
+			update.Stock = &req.Stock
+		}
+		_, err := s.productWriter.UpdateProduct(id, update)
+		return err
+	}
+	return nil
+}
+
+func (s *Service) findJob(id string) (*import_job.ImportJob, error) {
+	job, err := s.importJobRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImportJobNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// report collects the row errors and duplicates of a run, up to import_job.MaxReportedIssues each
+type report struct {
+	errors     []import_job.RowError
+	duplicates []import_job.RowDuplicate
+}
+
+func newReport() *report {
+	return &report{errors: make([]import_job.RowError, 0), duplicates: make([]import_job.RowDuplicate, 0)}
+}
+
+func (r *report) addErrors(errs []import_job.RowError) {
+	for _, e := range errs {
+		if len(r.errors) < import_job.MaxReportedIssues {
+			r.errors = append(r.errors, e)
+		}
+	}
+}
+
+func (r *report) addDuplicate(d import_job.RowDuplicate) {
+	if len(r.duplicates) < import_job.MaxReportedIssues {
+		r.duplicates = append(r.duplicates, d)
+	}
+}
+
+func (r *report) apply(job *import_job.ImportJob) {
+	job.Errors = mustJSON(r.errors)
+	job.Duplicates = mustJSON(r.duplicates)
+}
+
+// mustJSON encodes values that always marshal, such as string slices and maps
+func mustJSON(v interface{}) datatypes.JSON {
+	data, _ := json.Marshal(v)
+	return datatypes.JSON(data)
+}
+
+// PaginationResult represents pagination information
+type PaginationResult struct {
+	Page       int
+	PerPage    int
+	Total      int
+	TotalPages int
+}
@@ -0,0 +1,270 @@
+package import_job
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/category"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/duplicate"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/import_job"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/product"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/user"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+const pcDrugsID = "9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"
+
+type fakeImportJobRepo struct {
+	interfaces.ImportJobRepository
+	rows []import_job.Row
+}
+
+func (r *fakeImportJobRepo) FindRows(id string) ([]import_job.Row, error) {
+	return r.rows, nil
+}
+
+func (r *fakeImportJobRepo) Update(job *import_job.ImportJob) error {
+	return nil
+}
+
+type fakeCategoryRepo struct {
+	interfaces.CategoryRepository
+}
+
+func (r *fakeCategoryRepo) List() ([]category.Category, error) {
+	return []category.Category{{ID: "6f1c1a52-3b8e-4c6e-9f0a-1d2e3f4a5b60", Name: "Rumah Sakit", Code: "RS"}}, nil
+}
+
+type fakeProductCategoryRepo struct {
+	interfaces.ProductCategoryRepository
+}
+
+func (r *fakeProductCategoryRepo) List(req *product.ListProductCategoriesRequest) ([]product.ProductCategory, error) {
+	return []product.ProductCategory{{ID: pcDrugsID, Name: "Obat", Slug: "obat"}}, nil
+}
+
+type fakeProductRepo struct {
+	interfaces.ProductRepository
+	products []product.Product
+}
+
+func (r *fakeProductRepo) FindBySKU(sku string) (*product.Product, error) {
+	for i := range r.products {
+		if strings.EqualFold(r.products[i].SKU, sku) {
+			return &r.products[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakeUserRepo struct {
+	interfaces.UserRepository
+}
+
+func (r *fakeUserRepo) FindByEmail(email string) (*user.User, error) {
+	if email == "sari@gilabs.id" {
+		return &user.User{ID: "0c9d8e7f-6a5b-4c3d-8e2f-1a0b9c8d7e6f", Email: email}, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakeDuplicateChecker struct {
+	accounts []duplicate.Match
+}
+
+func (c *fakeDuplicateChecker) FindAccountMatches(record duplicate.Record) ([]duplicate.Match, error) {
+	if strings.EqualFold(record.Email, "info@rsharapan.id") {
+		return c.accounts, nil
+	}
+	return nil, nil
+}
+
+func (c *fakeDuplicateChecker) FindContactMatches(record duplicate.Record) ([]duplicate.Match, error) {
+	return nil, nil
+}
+
+func (c *fakeDuplicateChecker) FindLeadMatches(record duplicate.Record) ([]duplicate.Match, error) {
+	return nil, nil
+}
+
+type fakeProductWriter struct {
+	created []product.CreateProductRequest
+	updated map[string]product.UpdateProductRequest
+}
+
+func (w *fakeProductWriter) CreateProduct(req *product.CreateProductRequest) (*product.ProductResponse, error) {
+	w.created = append(w.created, *req)
+	return &product.ProductResponse{SKU: req.SKU}, nil
+}
+
+func (w *fakeProductWriter) UpdateProduct(id string, req *product.UpdateProductRequest) (*product.ProductResponse, error) {
+	w.updated[id] = *req
+	return &product.ProductResponse{ID: id}, nil
+}
+
+func TestParseFileReadsSemicolonCSVAndXLSX(t *testing.T) {
+	csvData := "\xef\xbb\xbfName;City;Phone\nRS Harapan;Bandung;\"022 123;4567\"\n;;\nKlinik Sehat;Bogor;0251 765 432\n"
+	headers, rows, err := ParseFile("accounts.CSV", strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("ParseFile(csv) failed: %v", err)
+	}
+	if strings.Join(headers, "|") != "Name|City|Phone" {
+		t.Fatalf("unexpected headers %q", headers)
+	}
+	if len(rows) != 2 || rows[0].Line != 2 || rows[0].Cells[2] != "022 123;4567" || rows[1].Line != 4 {
+		t.Fatalf("expected blank line skipped and line numbers kept, got %+v", rows)
+	}
+
+	f := excelize.NewFile()
+	_ = f.SetSheetRow("Sheet1", "A2", &[]interface{}{"SKU", "Price"})
+	_ = f.SetSheetRow("Sheet1", "A3", &[]interface{}{"PCT-500", 15000.5})
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("WriteToBuffer failed: %v", err)
+	}
+	headers, rows, err = ParseFile("products.xlsx", buf)
+	if err != nil {
+		t.Fatalf("ParseFile(xlsx) failed: %v", err)
+	}
+	if strings.Join(headers, "|") != "SKU|Price" || len(rows) != 1 || rows[0].Line != 3 || rows[0].Cells[1] != "15000.5" {
+		t.Fatalf("unexpected xlsx parse: %q %+v", headers, rows)
+	}
+
+	if _, _, err := ParseFile("accounts.pdf", strings.NewReader("x")); err == nil || !strings.Contains(err.Error(), ".csv or .xlsx") {
+		t.Errorf("expected unsupported file error, got %v", err)
+	}
+	if _, _, err := ParseFile("accounts.csv", strings.NewReader("Name,name\nA,B\n")); err == nil {
+		t.Error("expected repeated header error")
+	}
+}
+
+func TestParseRupiahHandlesLocalFormats(t *testing.T) {
+	cases := map[string]int64{
+		"1500000":         150000000,
+		"1.500.000":       150000000,
+		"Rp 1.500.000,50": 150000050,
+		"1,500,000.50":    150000050,
+		"12.5":            1250,
+		"1,5":             150,
+		"2.500":           250000,
+	}
+	for value, want := range cases {
+		if got, err := parseRupiah(value); err != nil || got != want {
+			t.Errorf("parseRupiah(%q) = %d, %v; want %d", value, got, err, want)
+		}
+	}
+	if _, err := parseRupiah("-10"); err == nil {
+		t.Error("expected negative amount to be rejected")
+	}
+	if stock, err := parseWholeNumber("10.0"); err != nil || stock != 10 {
+		t.Errorf("parseWholeNumber(10.0) = %d, %v", stock, err)
+	}
+}
+
+func TestAutoMappingAndMappingProblems(t *testing.T) {
+	headers := []string{"Nama", "Kategori", "E-mail", "Kota", "Sales Rep"}
+	mapping := import_job.AutoMapping(import_job.EntityAccount, headers)
+	want := map[string]string{"name": "Nama", "category": "Kategori", "email": "E-mail", "city": "Kota", "assigned_to": "Sales Rep"}
+	for key, header := range want {
+		if mapping[key] != header {
+			t.Errorf("expected %s mapped to %s, got %q", key, header, mapping[key])
+		}
+	}
+
+	problems := import_job.MappingProblems(import_job.EntityAccount, map[string]string{"name": "Nama", "colour": "Kota", "city": "Town"}, headers)
+	expected := []string{"column Town mapped to city is not in the file", "unknown field colour", "required field category is not mapped"}
+	if strings.Join(problems, "; ") != strings.Join(expected, "; ") {
+		t.Errorf("unexpected problems %q", problems)
+	}
+}
+
+func TestDryRunReportsRowErrorsAndDuplicates(t *testing.T) {
+	svc := NewService(&fakeImportJobRepo{}, nil, nil, nil, &fakeCategoryRepo{}, nil, nil, &fakeUserRepo{}, nil, nil, nil, nil)
+	svc.SetDuplicateChecker(&fakeDuplicateChecker{accounts: []duplicate.Match{
+		{ID: "acc-1", Name: "RS Harapan Kita", Score: 100, Reasons: []string{duplicate.ReasonEmail}},
+	}})
+
+	headers := []string{"Name", "Category", "Email", "City", "Owner"}
+	rows := []import_job.Row{
+		{Line: 2, Cells: []string{"RS Harapan Kita", "rumah sakit", "info@rsharapan.id", "Jakarta", ""}},
+		{Line: 3, Cells: []string{"Klinik Sentosa", "RS", "not-an-email", "Bandung", "nobody@gilabs.id"}},
+		{Line: 4, Cells: []string{"Apotek Maju", "Apotek", "", "Bogor", "sari@gilabs.id"}},
+		{Line: 5, Cells: []string{"Klinik Baru", "RS", "halo@klinikbaru.id", "Depok", "SARI@gilabs.id"}},
+		{Line: 6, Cells: []string{"Klinik Baru", "rs", "", "Kota Depok", ""}},
+	}
+	job := &import_job.ImportJob{EntityType: import_job.EntityAccount, DuplicateMode: import_job.DuplicateUpsert}
+	mapping := import_job.AutoMapping(job.EntityType, headers)
+
+	if err := svc.dryRun(job, headers, mapping, rows); err != nil {
+		t.Fatalf("dryRun failed: %v", err)
+	}
+	if job.Status != import_job.StatusValidated || job.TotalRows != 5 || job.ValidRows != 3 || job.InvalidRows != 2 || job.DuplicateRows != 2 {
+		t.Fatalf("unexpected counts %+v", job)
+	}
+
+	resp := job.ToImportJobResponse()
+	if len(resp.Errors) != 3 {
+		t.Fatalf("expected 3 row errors, got %+v", resp.Errors)
+	}
+	fields := map[string]int{}
+	for _, e := range resp.Errors {
+		fields[e.Field] = e.Row
+	}
+	if fields["email"] != 3 || fields["assigned_to"] != 3 || fields["category"] != 4 {
+		t.Errorf("unexpected row errors %+v", resp.Errors)
+	}
+
+	if len(resp.Duplicates) != 2 {
+		t.Fatalf("expected 2 duplicates, got %+v", resp.Duplicates)
+	}
+	if d := resp.Duplicates[0]; d.Row != 2 || d.MatchID != "acc-1" || d.Action != import_job.ActionUpdate {
+		t.Errorf("expected row 2 to update acc-1, got %+v", d)
+	}
+	if d := resp.Duplicates[1]; d.Row != 6 || d.MatchRow != 5 || d.MatchID != "" {
+		t.Errorf("expected row 6 to repeat row 5, got %+v", d)
+	}
+}
+
+func TestProcessUpsertsProductsBySKU(t *testing.T) {
+	repo := &fakeImportJobRepo{rows: []import_job.Row{
+		{Line: 2, Cells: []string{"Paracetamol 500mg", "pct-500", "Rp 12.500", "", "Obat", "ya"}},
+		{Line: 3, Cells: []string{"Amoxicillin 500mg", "AMX-500", "30000", "120", "obat", ""}},
+		{Line: 4, Cells: []string{"Vitamin C", "VTC-1", "abc", "5", "Suplemen", ""}},
+	}}
+	writer := &fakeProductWriter{updated: map[string]product.UpdateProductRequest{}}
+	productRepo := &fakeProductRepo{products: []product.Product{{ID: "prod-1", Name: "Paracetamol", SKU: "PCT-500"}}}
+	svc := NewService(repo, nil, nil, productRepo, nil, nil, &fakeProductCategoryRepo{}, nil, nil, nil, nil, writer)
+
+	headers := []string{"Name", "SKU", "Price", "Stock", "Category", "Taxable"}
+	job := &import_job.ImportJob{
+		EntityType:    import_job.EntityProduct,
+		DuplicateMode: import_job.DuplicateUpsert,
+		Status:        import_job.StatusImporting,
+		Headers:       mustJSON(headers),
+		Mapping:       mustJSON(import_job.AutoMapping(import_job.EntityProduct, headers)),
+	}
+
+	if err := svc.process(job); err != nil {
+		t.Fatalf("process failed: %v", err)
+	}
+	if job.Status != import_job.StatusCompleted || job.ProcessedRows != 3 || job.CreatedCount != 1 || job.UpdatedCount != 1 || job.FailedCount != 1 {
+		t.Fatalf("unexpected counts %+v", job)
+	}
+
+	update, ok := writer.updated["prod-1"]
+	if !ok || *update.Price != 1250000 || update.Stock != nil || update.Taxable == nil || !*update.Taxable {
+		t.Errorf("expected prod-1 updated with price and taxable but not stock, got %+v", update)
+	}
+	if len(writer.created) != 1 || writer.created[0].Stock != 120 || writer.created[0].CategoryID != pcDrugsID || writer.created[0].Taxable != nil {
+		t.Errorf("unexpected created products %+v", writer.created)
+	}
+
+	var rowErrors []import_job.RowError
+	_ = json.Unmarshal(job.Errors, &rowErrors)
+	if len(rowErrors) != 2 || rowErrors[0].Row != 4 || rowErrors[0].Field != "category" || rowErrors[1].Field != "price" {
+		t.Errorf("unexpected row errors %+v", rowErrors)
+	}
+}
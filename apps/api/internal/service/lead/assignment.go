@@ -0,0 +1,144 @@
+package lead
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_assignment"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/notification"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/user"
+)
+
+// NotifierInterface defines interface for notifying sales reps of leads routed to them
+type NotifierInterface interface {
+	CreateNotification(req *notification.CreateNotificationRequest) (*notification.NotificationResponse, error)
+}
+
+// SetNotifier sets the notifier used to tell sales reps about automatically assigned leads
+func (s *Service) SetNotifier(notifier NotifierInterface) {
+	s.notifier = notifier
+}
+
+// autoAssign routes an unassigned lead with the first matching active assignment rule that yields a sales rep
+func (s *Service) autoAssign(leadID string) error {
+	if s.assignRuleRepo == nil {
+		return nil
+	}
+	rules, err := s.assignRuleRepo.ListActive()
+	if err != nil {
+		return fmt.Errorf("failed to get assignment rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	l, err := s.systemLeadRepo.FindByID(leadID)
+	if err != nil {
+		return err
+	}
+	facts := lead_assignment.LeadFacts{
+		LeadSource: l.LeadSource,
+		Province:   l.Province,
+		City:       l.City,
+		Industry:   l.Industry,
+		LeadScore:  l.LeadScore,
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Matches(facts) {
+			continue
+		}
+		userID, err := s.pickAssignee(rule)
+		if err != nil {
+			return err
+		}
+		if userID == "" {
+			continue // No active rep for this rule; try the next one
+		}
+
+		if err := s.systemLeadRepo.Assign(l.ID, userID); err != nil {
+			return err
+		}
+		s.notifyAssignment(l, userID, rule)
+		return nil
+	}
+	return nil
+}
+
+// pickAssignee returns the sales rep a rule routes to, or an empty string when it has no active rep
+func (s *Service) pickAssignee(rule *lead_assignment.AssignmentRule) (string, error) {
+	if rule.Strategy == lead_assignment.StrategyUser {
+		if rule.UserID == nil {
+			return "", nil
+		}
+		u, err := s.userRepo.FindByID(*rule.UserID)
+		if err != nil || u.Status != "active" {
+			return "", nil
+		}
+		return u.ID, nil
+	}
+
+	if rule.TeamID == nil {
+		return "", nil
+	}
+	members, _, err := s.userRepo.List(&user.ListUsersRequest{TeamID: *rule.TeamID, Status: "active", Page: 1, PerPage: 100})
+	if err != nil {
+		return "", fmt.Errorf("failed to get team members: %w", err)
+	}
+	memberIDs := make([]string, len(members))
+	for i, m := range members {
+		memberIDs[i] = m.ID
+	}
+	sort.Strings(memberIDs) // Stable rotation order
+	if len(memberIDs) == 0 {
+		return "", nil
+	}
+
+	switch rule.Strategy {
+	case lead_assignment.StrategyRoundRobin:
+		return s.assignRuleRepo.AdvanceRoundRobin(rule.ID, memberIDs)
+	case lead_assignment.StrategyLeastLoaded:
+		openLeads, err := s.systemLeadRepo.CountOpenByAssignee(memberIDs)
+		if err != nil {
+			return "", fmt.Errorf("failed to count open leads: %w", err)
+		}
+		return lead_assignment.LeastLoaded(memberIDs, openLeads), nil
+	}
+	return "", nil
+}
+
+// notifyAssignment tells a sales rep about a lead routed to them; failures are logged only, since the lead is assigned
+func (s *Service) notifyAssignment(l *lead.Lead, userID string, rule *lead_assignment.AssignmentRule) {
+	if s.notifier == nil {
+		return
+	}
+
+	name := l.FirstName
+	if l.LastName != "" {
+		name += " " + l.LastName
+	}
+	message := fmt.Sprintf("%s (%s) was assigned to you by rule \"%s\".", name, l.LeadSource, rule.Name)
+	if l.CompanyName != "" {
+		message = fmt.Sprintf("%s from %s (%s) was assigned to you by rule \"%s\".", name, l.CompanyName, l.LeadSource, rule.Name)
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"lead_id": l.ID,
+		"rule_id": rule.ID,
+	})
+	_, err := s.notifier.CreateNotification(&notification.CreateNotificationRequest{
+		UserID:  userID,
+		Title:   "New lead assigned",
+		Message: message,
+		Type:    "lead",
+		Data:    string(data),
+		Email:   true,
+	})
+	if err != nil {
+		log.Printf("Failed to notify user %s of lead %s: %v", userID, l.ID, err)
+	}
+}
@@ -0,0 +1,195 @@
+package lead
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_assignment"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/notification"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/user"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+func (r *fakeLeadRepo) Assign(id string, userID string) error {
+	r.leads[id].AssignedTo = &userID
+	return nil
+}
+
+func (r *fakeLeadRepo) CountOpenByAssignee(userIDs []string) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	for _, l := range r.leads {
+		if l.AssignedTo != nil && l.LeadStatus != "converted" {
+			counts[*l.AssignedTo]++
+		}
+	}
+	return counts, nil
+}
+
+type fakeAssignRuleRepo struct {
+	interfaces.LeadAssignmentRuleRepository
+	rules []lead_assignment.AssignmentRule
+	err   error
+}
+
+func (r *fakeAssignRuleRepo) ListActive() ([]lead_assignment.AssignmentRule, error) {
+	return r.rules, r.err
+}
+
+func (r *fakeAssignRuleRepo) AdvanceRoundRobin(ruleID string, memberIDs []string) (string, error) {
+	for i := range r.rules {
+		if r.rules[i].ID != ruleID {
+			continue
+		}
+		last := ""
+		if r.rules[i].LastAssignedUserID != nil {
+			last = *r.rules[i].LastAssignedUserID
+		}
+		next := lead_assignment.NextRoundRobin(memberIDs, last)
+		r.rules[i].LastAssignedUserID = &next
+		return next, nil
+	}
+	return "", gorm.ErrRecordNotFound
+}
+
+type fakeUserRepo struct {
+	interfaces.UserRepository
+	users []user.User
+}
+
+func (r *fakeUserRepo) FindByID(id string) (*user.User, error) {
+	for i := range r.users {
+		if r.users[i].ID == id {
+			return &r.users[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) List(req *user.ListUsersRequest) ([]user.User, int64, error) {
+	var found []user.User
+	for _, u := range r.users {
+		if u.TeamID != nil && *u.TeamID == req.TeamID && (req.Status == "" || u.Status == req.Status) {
+			found = append(found, u)
+		}
+	}
+	return found, int64(len(found)), nil
+}
+
+type fakeNotifier struct {
+	sent []notification.CreateNotificationRequest
+}
+
+func (n *fakeNotifier) CreateNotification(req *notification.CreateNotificationRequest) (*notification.NotificationResponse, error) {
+	n.sent = append(n.sent, *req)
+	return &notification.NotificationResponse{UserID: req.UserID}, nil
+}
+
+func newAssignmentService(leadRepo *fakeLeadRepo, rules []lead_assignment.AssignmentRule) (*Service, *fakeNotifier) {
+	team := "team-1"
+	userRepo := &fakeUserRepo{users: []user.User{
+		{ID: "rep-b", Status: "active", TeamID: &team},
+		{ID: "rep-a", Status: "active", TeamID: &team},
+		{ID: "rep-c", Status: "inactive", TeamID: &team},
+		{ID: "manager", Status: "active"},
+	}}
+	svc := NewService(leadRepo, nil, nil, nil, nil, nil, nil, userRepo, nil, nil, nil, &fakeAssignRuleRepo{rules: rules})
+	notifier := &fakeNotifier{}
+	svc.SetNotifier(notifier)
+	return svc, notifier
+}
+
+func TestAssignmentRuleMatches(t *testing.T) {
+	minScore := 60
+	rule := lead_assignment.AssignmentRule{LeadSources: "referral, Event", Provinces: "DKI Jakarta", MinScore: &minScore}
+
+	if !rule.Matches(lead_assignment.LeadFacts{LeadSource: "event", Province: "dki jakarta", LeadScore: 75}) {
+		t.Error("expected rule to match")
+	}
+	if rule.Matches(lead_assignment.LeadFacts{LeadSource: "website", Province: "DKI Jakarta", LeadScore: 75}) {
+		t.Error("expected source mismatch")
+	}
+	if rule.Matches(lead_assignment.LeadFacts{LeadSource: "referral", Province: "DKI Jakarta", LeadScore: 40}) {
+		t.Error("expected score below minimum not to match")
+	}
+	if !(&lead_assignment.AssignmentRule{}).Matches(lead_assignment.LeadFacts{LeadSource: "other"}) {
+		t.Error("expected rule without conditions to match every lead")
+	}
+}
+
+func TestAutoAssignRoundRobinRotatesActiveMembersAndNotifies(t *testing.T) {
+	team := "team-1"
+	leadRepo := &fakeLeadRepo{leads: map[string]*lead.Lead{
+		"l1": {ID: "l1", FirstName: "Ani", LeadSource: "website", City: "Bandung"},
+		"l2": {ID: "l2", FirstName: "Budi", LeadSource: "website", City: "Bandung"},
+		"l3": {ID: "l3", FirstName: "Citra", LeadSource: "website", City: "Bandung"},
+	}}
+	rules := []lead_assignment.AssignmentRule{
+		{ID: "referrals", Name: "Referrals", LeadSources: "referral", Strategy: lead_assignment.StrategyUser, UserID: strPtr("manager")},
+		{ID: "bandung", Name: "Bandung", Cities: "bandung", Strategy: lead_assignment.StrategyRoundRobin, TeamID: &team},
+	}
+	svc, notifier := newAssignmentService(leadRepo, rules)
+
+	for _, id := range []string{"l1", "l2", "l3"} {
+		if err := svc.autoAssign(id); err != nil {
+			t.Fatalf("autoAssign(%s) failed: %v", id, err)
+		}
+	}
+
+	// Inactive rep-c is skipped; members rotate in ID order
+	want := map[string]string{"l1": "rep-a", "l2": "rep-b", "l3": "rep-a"}
+	for id, rep := range want {
+		if got := leadRepo.leads[id].AssignedTo; got == nil || *got != rep {
+			t.Errorf("lead %s: expected %s, got %v", id, rep, got)
+		}
+	}
+	if len(notifier.sent) != 3 || notifier.sent[0].UserID != "rep-a" || notifier.sent[0].Type != "lead" || !notifier.sent[0].Email {
+		t.Fatalf("unexpected notifications %+v", notifier.sent)
+	}
+}
+
+func TestAutoAssignLeastLoadedPicksRepWithFewestOpenLeads(t *testing.T) {
+	team := "team-1"
+	leadRepo := &fakeLeadRepo{leads: map[string]*lead.Lead{
+		"open-1": {ID: "open-1", AssignedTo: strPtr("rep-a"), LeadStatus: "new"},
+		"open-2": {ID: "open-2", AssignedTo: strPtr("rep-a"), LeadStatus: "contacted"},
+		"won":    {ID: "won", AssignedTo: strPtr("rep-b"), LeadStatus: "converted"},
+		"new":    {ID: "new", FirstName: "Dewi", LeadSource: "event"},
+	}}
+	rules := []lead_assignment.AssignmentRule{
+		{ID: "events", Name: "Events", Strategy: lead_assignment.StrategyLeastLoaded, TeamID: &team},
+	}
+	svc, notifier := newAssignmentService(leadRepo, rules)
+
+	if err := svc.autoAssign("new"); err != nil {
+		t.Fatalf("autoAssign failed: %v", err)
+	}
+	if got := leadRepo.leads["new"].AssignedTo; got == nil || *got != "rep-b" {
+		t.Fatalf("expected rep-b, got %v", got)
+	}
+	if len(notifier.sent) != 1 {
+		t.Errorf("expected one notification, got %d", len(notifier.sent))
+	}
+}
+
+func TestCreateLeavesLeadUnassignedWhenRoutingFails(t *testing.T) {
+	leadRepo := &fakeLeadRepo{leads: map[string]*lead.Lead{}}
+	svc, notifier := newAssignmentService(leadRepo, nil)
+	svc.assignRuleRepo.(*fakeAssignRuleRepo).err = errors.New("connection reset")
+
+	created, err := svc.Create(&lead.CreateLeadRequest{FirstName: "Dewi", LeadSource: "event"}, "user-1")
+	if err != nil {
+		t.Fatalf("expected the created lead despite the routing failure, got %v", err)
+	}
+	if created.ID != "lead-new" || created.AssignedTo != "" {
+		t.Errorf("expected unassigned lead-new, got %+v", created)
+	}
+	if len(notifier.sent) != 0 {
+		t.Errorf("expected no notification, got %d", len(notifier.sent))
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
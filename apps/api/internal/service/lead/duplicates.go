@@ -0,0 +1,69 @@
+package lead
+
+import (
+	"strings"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/duplicate"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
+)
+
+// DuplicateCheckerInterface defines interface for finding existing records a new lead, account or contact may duplicate
+type DuplicateCheckerInterface interface {
+	FindLeadMatches(record duplicate.Record) ([]duplicate.Match, error)
+	FindAccountMatches(record duplicate.Record) ([]duplicate.Match, error)
+	FindContactMatches(record duplicate.Record) ([]duplicate.Match, error)
+}
+
+// SetDuplicateChecker sets the checker used to stop leads, accounts and contacts from being created twice
+func (s *Service) SetDuplicateChecker(checker DuplicateCheckerInterface) {
+	s.duplicateChecker = checker
+}
+
+// checkLeadDuplicates returns a duplicate.FoundError when the lead may duplicate existing leads
+func (s *Service) checkLeadDuplicates(l *lead.Lead) error {
+	if s.duplicateChecker == nil {
+		return nil
+	}
+	matches, err := s.duplicateChecker.FindLeadMatches(l.DuplicateRecord())
+	if err != nil {
+		return err
+	}
+	return duplicate.Found(duplicate.EntityLead, matches)
+}
+
+// checkConversionDuplicates returns a duplicate.FoundError when the account or contact created from a lead
+// may duplicate existing ones. accountID is the existing account the contact joins, if any.
+func (s *Service) checkConversionDuplicates(l *lead.Lead, createAccount bool, createContact bool, accountID string) error {
+	if s.duplicateChecker == nil {
+		return nil
+	}
+
+	if createAccount && l.CompanyName != "" {
+		matches, err := s.duplicateChecker.FindAccountMatches(duplicate.Record{
+			Name:  l.CompanyName,
+			Email: l.Email,
+			Phone: l.Phone,
+			City:  l.City,
+		})
+		if err != nil {
+			return err
+		}
+		if err := duplicate.Found(duplicate.EntityAccount, matches); err != nil {
+			return err
+		}
+	}
+
+	if createContact {
+		matches, err := s.duplicateChecker.FindContactMatches(duplicate.Record{
+			Name:      strings.TrimSpace(l.FirstName + " " + l.LastName),
+			Email:     l.Email,
+			Phone:     l.Phone,
+			AccountID: accountID,
+		})
+		if err != nil {
+			return err
+		}
+		return duplicate.Found(duplicate.EntityContact, matches)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package lead
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/duplicate"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
+)
+
+func (r *fakeLeadRepo) Create(l *lead.Lead) error {
+	l.ID = "lead-new"
+	copied := *l
+	r.leads[l.ID] = &copied
+	return nil
+}
+
+type fakeDuplicateChecker struct {
+	leads []duplicate.Match
+}
+
+func (c *fakeDuplicateChecker) FindLeadMatches(record duplicate.Record) ([]duplicate.Match, error) {
+	return c.leads, nil
+}
+
+func (c *fakeDuplicateChecker) FindAccountMatches(record duplicate.Record) ([]duplicate.Match, error) {
+	return nil, nil
+}
+
+func (c *fakeDuplicateChecker) FindContactMatches(record duplicate.Record) ([]duplicate.Match, error) {
+	return nil, nil
+}
+
+func TestCreateRejectsDuplicateLeadUnlessIgnored(t *testing.T) {
+	leadRepo := &fakeLeadRepo{leads: map[string]*lead.Lead{}}
+	svc := NewService(leadRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	svc.SetDuplicateChecker(&fakeDuplicateChecker{leads: []duplicate.Match{
+		{EntityType: duplicate.EntityLead, ID: "lead-1", Score: 100, Reasons: []string{duplicate.ReasonEmail}},
+	}})
+	req := &lead.CreateLeadRequest{FirstName: "Budi", Email: "budi@klinik.id", LeadSource: "website"}
+
+	_, err := svc.Create(req, "user-1")
+	var found *duplicate.FoundError
+	if !errors.As(err, &found) || found.EntityType != duplicate.EntityLead || len(found.Matches) != 1 {
+		t.Fatalf("expected duplicate.FoundError, got %v", err)
+	}
+	if len(leadRepo.leads) != 0 {
+		t.Fatal("expected no lead to be created")
+	}
+
+	req.IgnoreDuplicates = true
+	created, err := svc.Create(req, "user-1")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if created.ID != "lead-new" {
+		t.Errorf("expected lead-new to be created, got %s", created.ID)
+	}
+}
@@ -0,0 +1,100 @@
+package lead
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_scoring"
+	"gorm.io/gorm"
+)
+
+// RecomputeScore scores a lead with the active scoring rules.
+// Without active rules the lead keeps its current, manually set score.
+func (s *Service) RecomputeScore(leadID string) error {
+	rules, err := s.activeScoringRules()
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+	return s.applyScore(leadID, rules, time.Now())
+}
+
+// RecomputeAllScores scores every lead with the active scoring rules and returns the number of leads scored
+func (s *Service) RecomputeAllScores() (int, error) {
+	rules, err := s.activeScoringRules()
+	if err != nil || len(rules) == 0 {
+		return 0, err
+	}
+
+	ids, err := s.systemLeadRepo.ListIDs()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	updated := 0
+	for _, id := range ids {
+		if err := s.applyScore(id, rules, now); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue // Deleted meanwhile
+			}
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+// activeScoringRules returns the active scoring rules, or none when scoring is not configured
+func (s *Service) activeScoringRules() ([]lead_scoring.ScoringRule, error) {
+	if s.scoringRuleRepo == nil {
+		return nil, nil
+	}
+	rules, err := s.scoringRuleRepo.ListActive()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scoring rules: %w", err)
+	}
+	return rules, nil
+}
+
+// applyScore evaluates the rules against a lead and its engagement and stores the score with its breakdown
+func (s *Service) applyScore(leadID string, rules []lead_scoring.ScoringRule, now time.Time) error {
+	l, err := s.systemLeadRepo.FindByID(leadID)
+	if err != nil {
+		return err
+	}
+	engagement, err := s.systemLeadRepo.GetEngagement(leadID)
+	if err != nil {
+		return fmt.Errorf("failed to get lead engagement: %w", err)
+	}
+
+	score, breakdown := lead_scoring.Evaluate(rules, scoringFacts(l, engagement), now)
+	breakdownJSON, err := json.Marshal(breakdown)
+	if err != nil {
+		return err
+	}
+	return s.systemLeadRepo.UpdateScore(leadID, score, breakdownJSON, now)
+}
+
+// scoringFacts collects the lead attributes scoring rules are evaluated against
+func scoringFacts(l *lead.Lead, engagement *lead_scoring.Engagement) lead_scoring.Facts {
+	return lead_scoring.Facts{
+		LeadSource: l.LeadSource,
+		Industry:   l.Industry,
+		JobTitle:   l.JobTitle,
+		Email:      l.Email,
+		Phone:      l.Phone,
+		Engagement: *engagement,
+	}
+}
+
+// withScoreBreakdown converts a lead to a response including the breakdown of its automatic score
+func withScoreBreakdown(l *lead.Lead) *lead.LeadResponse {
+	resp := l.ToLeadResponse()
+	if l.ScoredAt != nil && l.ScoreBreakdown != nil {
+		_ = json.Unmarshal(l.ScoreBreakdown, &resp.ScoreBreakdown)
+	}
+	return resp
+}
@@ -0,0 +1,209 @@
+package lead
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_scoring"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type fakeLeadRepo struct {
+	interfaces.LeadRepository
+	leads      map[string]*lead.Lead
+	engagement map[string]lead_scoring.Engagement
+}
+
+func (r *fakeLeadRepo) FindByID(id string) (*lead.Lead, error) {
+	l, ok := r.leads[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *l
+	return &copied, nil
+}
+
+func (r *fakeLeadRepo) ListIDs() ([]string, error) {
+	ids := make([]string, 0, len(r.leads))
+	for id := range r.leads {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (r *fakeLeadRepo) GetEngagement(id string) (*lead_scoring.Engagement, error) {
+	e := r.engagement[id]
+	return &e, nil
+}
+
+func (r *fakeLeadRepo) UpdateScore(id string, score int, breakdown datatypes.JSON, scoredAt time.Time) error {
+	l := r.leads[id]
+	l.LeadScore = score
+	l.ScoreBreakdown = breakdown
+	l.ScoredAt = &scoredAt
+	return nil
+}
+
+type fakeScoringRuleRepo struct {
+	interfaces.LeadScoringRuleRepository
+	rules []lead_scoring.ScoringRule
+	err   error
+}
+
+func (r *fakeScoringRuleRepo) ListActive() ([]lead_scoring.ScoringRule, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	var active []lead_scoring.ScoringRule
+	for _, rule := range r.rules {
+		if rule.IsActive {
+			active = append(active, rule)
+		}
+	}
+	return active, nil
+}
+
+func newScoringService(leadRepo *fakeLeadRepo, rules []lead_scoring.ScoringRule) *Service {
+	return NewService(leadRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, &fakeScoringRuleRepo{rules: rules}, nil)
+}
+
+func TestEvaluateMatchesRulesAndClampsScore(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	touched := now.AddDate(0, 0, -3)
+	rules := []lead_scoring.ScoringRule{
+		{ID: "source", Criterion: lead_scoring.CriterionLeadSource, Operator: lead_scoring.OperatorEquals, Value: "referral, partner", Points: 30, IsActive: true},
+		{ID: "title", Criterion: lead_scoring.CriterionJobTitle, Operator: lead_scoring.OperatorContains, Value: "director", Points: 40, IsActive: true},
+		{ID: "phone", Criterion: lead_scoring.CriterionHasPhone, Operator: lead_scoring.OperatorIsSet, Points: 10, IsActive: true},
+		{ID: "visits", Criterion: lead_scoring.CriterionVisitReportCount, Operator: lead_scoring.OperatorGTE, Value: "2", Points: 20, IsActive: true},
+		{ID: "recent", Criterion: lead_scoring.CriterionDaysSinceLastTouch, Operator: lead_scoring.OperatorLTE, Value: "7", Points: 25, IsActive: true},
+		{ID: "inactive", Criterion: lead_scoring.CriterionHasEmail, Operator: lead_scoring.OperatorIsSet, Points: 50, IsActive: false},
+	}
+	facts := lead_scoring.Facts{
+		LeadSource: "Referral",
+		JobTitle:   "Medical Director",
+		Email:      "a@b.c",
+		Engagement: lead_scoring.Engagement{VisitReportCount: 1, LastTouchAt: &touched},
+	}
+
+	score, breakdown := lead_scoring.Evaluate(rules, facts, now)
+	if score != 95 {
+		t.Fatalf("expected score 95, got %d (%+v)", score, breakdown)
+	}
+	if len(breakdown) != 3 || breakdown[0].RuleID != "source" || breakdown[1].RuleID != "title" || breakdown[2].RuleID != "recent" {
+		t.Errorf("unexpected breakdown %+v", breakdown)
+	}
+
+	facts.Phone = "0812"
+	if score, _ := lead_scoring.Evaluate(rules, facts, now); score != lead_scoring.MaxScore {
+		t.Errorf("expected score clamped to %d, got %d", lead_scoring.MaxScore, score)
+	}
+
+	// A lead that was never touched does not match recency rules
+	facts.LastTouchAt = nil
+	penalty := []lead_scoring.ScoringRule{
+		{ID: "stale", Criterion: lead_scoring.CriterionDaysSinceLastTouch, Operator: lead_scoring.OperatorGTE, Value: "30", Points: -20, IsActive: true},
+	}
+	if score, breakdown := lead_scoring.Evaluate(penalty, facts, now); score != 0 || len(breakdown) != 0 {
+		t.Errorf("expected no match for untouched lead, got %d %+v", score, breakdown)
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	cases := []struct {
+		criterion, operator, value string
+		valid                      bool
+	}{
+		{lead_scoring.CriterionLeadSource, lead_scoring.OperatorEquals, "referral", true},
+		{lead_scoring.CriterionLeadSource, lead_scoring.OperatorContains, "ref", false},
+		{lead_scoring.CriterionIndustry, lead_scoring.OperatorContains, " , ", false},
+		{lead_scoring.CriterionHasEmail, lead_scoring.OperatorIsSet, "", true},
+		{lead_scoring.CriterionActivityCount, lead_scoring.OperatorGTE, "3", true},
+		{lead_scoring.CriterionActivityCount, lead_scoring.OperatorGTE, "three", false},
+		{lead_scoring.CriterionDaysSinceLastTouch, lead_scoring.OperatorEquals, "7", false},
+	}
+	for _, c := range cases {
+		if got := lead_scoring.ValidateRule(c.criterion, c.operator, c.value); got != c.valid {
+			t.Errorf("ValidateRule(%s, %s, %q) = %v, want %v", c.criterion, c.operator, c.value, got, c.valid)
+		}
+	}
+}
+
+func TestRecomputeScoreStoresScoreAndBreakdown(t *testing.T) {
+	leadRepo := &fakeLeadRepo{
+		leads: map[string]*lead.Lead{
+			"lead-1": {ID: "lead-1", LeadSource: "event", Email: "x@y.z", LeadScore: 80},
+		},
+		engagement: map[string]lead_scoring.Engagement{
+			"lead-1": {ActivityCount: 4},
+		},
+	}
+	rules := []lead_scoring.ScoringRule{
+		{ID: "email", Name: "Email provided", Criterion: lead_scoring.CriterionHasEmail, Operator: lead_scoring.OperatorIsSet, Points: 5, IsActive: true},
+		{ID: "activities", Name: "Engaged", Criterion: lead_scoring.CriterionActivityCount, Operator: lead_scoring.OperatorGTE, Value: "3", Points: 10, IsActive: true},
+	}
+	svc := newScoringService(leadRepo, rules)
+
+	if err := svc.RecomputeScore("lead-1"); err != nil {
+		t.Fatalf("RecomputeScore failed: %v", err)
+	}
+
+	resp, err := svc.GetByID("lead-1")
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if resp.LeadScore != 15 || resp.ScoredAt == nil {
+		t.Fatalf("expected automatic score 15, got %d (scored at %v)", resp.LeadScore, resp.ScoredAt)
+	}
+	if len(resp.ScoreBreakdown) != 2 || resp.ScoreBreakdown[1].Name != "Engaged" || resp.ScoreBreakdown[1].Points != 10 {
+		t.Errorf("unexpected breakdown %+v", resp.ScoreBreakdown)
+	}
+
+	var stored []lead_scoring.ScoreItem
+	if err := json.Unmarshal(leadRepo.leads["lead-1"].ScoreBreakdown, &stored); err != nil || len(stored) != 2 {
+		t.Errorf("expected stored breakdown, got %s (%v)", leadRepo.leads["lead-1"].ScoreBreakdown, err)
+	}
+}
+
+func TestRecomputeScoreKeepsManualScoreWithoutActiveRules(t *testing.T) {
+	leadRepo := &fakeLeadRepo{
+		leads: map[string]*lead.Lead{
+			"lead-1": {ID: "lead-1", Email: "x@y.z", LeadScore: 70},
+		},
+	}
+	rules := []lead_scoring.ScoringRule{
+		{ID: "email", Criterion: lead_scoring.CriterionHasEmail, Operator: lead_scoring.OperatorIsSet, Points: 5, IsActive: false},
+	}
+	svc := newScoringService(leadRepo, rules)
+
+	if err := svc.RecomputeScore("lead-1"); err != nil {
+		t.Fatalf("RecomputeScore failed: %v", err)
+	}
+	updated, err := svc.RecomputeAllScores()
+	if err != nil || updated != 0 {
+		t.Fatalf("expected no leads rescored, got %d (%v)", updated, err)
+	}
+	if l := leadRepo.leads["lead-1"]; l.LeadScore != 70 || l.ScoredAt != nil {
+		t.Errorf("expected manual score 70 to be kept, got %d", l.LeadScore)
+	}
+}
+
+func TestCreateReturnsSavedLeadWhenScoringFails(t *testing.T) {
+	leadRepo := &fakeLeadRepo{leads: map[string]*lead.Lead{}}
+	svc := NewService(leadRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, &fakeScoringRuleRepo{err: errors.New("connection reset")}, nil)
+
+	created, err := svc.Create(&lead.CreateLeadRequest{FirstName: "Budi", LeadSource: "website", LeadScore: 20}, "user-1")
+	if err != nil {
+		t.Fatalf("expected the saved lead despite the scoring failure, got %v", err)
+	}
+	if created.ID != "lead-new" || created.LeadScore != 20 {
+		t.Errorf("expected lead-new with its manual score, got %+v", created)
+	}
+	if len(leadRepo.leads) != 1 {
+		t.Errorf("expected exactly one lead, got %d", len(leadRepo.leads))
+	}
+}
@@ -2,8 +2,10 @@ package lead
 
 import (
 	"errors"
+	"log"
 	"time"
 
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/activity"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/contact"
@@ -12,6 +14,7 @@ import (
 	"github.com/gilabs/crm-healthcare/api/internal/domain/user"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/visit_report"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"gorm.io/gorm"
 )
 
@@ -28,16 +31,21 @@ var (
 )
 
 type Service struct {
-	leadRepo        interfaces.LeadRepository
-	dealRepo        interfaces.DealRepository
-	pipelineRepo    interfaces.PipelineRepository
-	accountRepo     interfaces.AccountRepository
-	contactRepo     interfaces.ContactRepository
-	categoryRepo    interfaces.CategoryRepository
-	contactRoleRepo interfaces.ContactRoleRepository
-	userRepo        interfaces.UserRepository
-	activityRepo    interfaces.ActivityRepository    // For auto-migrate activities
-	visitReportRepo interfaces.VisitReportRepository // For auto-migrate visit reports
+	leadRepo         interfaces.LeadRepository
+	dealRepo         interfaces.DealRepository
+	pipelineRepo     interfaces.PipelineRepository
+	accountRepo      interfaces.AccountRepository
+	contactRepo      interfaces.ContactRepository
+	categoryRepo     interfaces.CategoryRepository
+	contactRoleRepo  interfaces.ContactRoleRepository
+	userRepo         interfaces.UserRepository
+	activityRepo     interfaces.ActivityRepository    // For auto-migrate activities
+	visitReportRepo  interfaces.VisitReportRepository // For auto-migrate visit reports
+	scoringRuleRepo  interfaces.LeadScoringRuleRepository
+	assignRuleRepo   interfaces.LeadAssignmentRuleRepository
+	systemLeadRepo   interfaces.LeadRepository // Unscoped; scoring and routing are not limited by the caller's data scope
+	notifier         NotifierInterface
+	duplicateChecker DuplicateCheckerInterface
 }
 
 func NewService(
@@ -51,21 +59,35 @@ func NewService(
 	userRepo interfaces.UserRepository,
 	activityRepo interfaces.ActivityRepository,
 	visitReportRepo interfaces.VisitReportRepository,
+	scoringRuleRepo interfaces.LeadScoringRuleRepository,
+	assignRuleRepo interfaces.LeadAssignmentRuleRepository,
 ) *Service {
 	return &Service{
-		leadRepo:        leadRepo,
-		dealRepo:        dealRepo,
-		pipelineRepo:    pipelineRepo,
-		accountRepo:     accountRepo,
-		contactRepo:     contactRepo,
-		categoryRepo:    categoryRepo,
-		contactRoleRepo: contactRoleRepo,
-		userRepo:        userRepo,
-		activityRepo:    activityRepo,
-		visitReportRepo: visitReportRepo,
+		leadRepo:         leadRepo,
+		dealRepo:         dealRepo,
+		pipelineRepo:     pipelineRepo,
+		accountRepo:      accountRepo,
+		contactRepo:      contactRepo,
+		categoryRepo:     categoryRepo,
+		contactRoleRepo:  contactRoleRepo,
+		userRepo:         userRepo,
+		activityRepo:     activityRepo,
+		visitReportRepo:  visitReportRepo,
+		scoringRuleRepo:  scoringRuleRepo,
+		assignRuleRepo:   assignRuleRepo,
+		systemLeadRepo:   leadRepo,
+		notifier:         nil, // Will be set via SetNotifier if needed
+		duplicateChecker: nil, // Will be set via SetDuplicateChecker if needed
 	}
 }
 
+// WithScope returns a copy of the service whose leads are limited to the given data scope
+func (s *Service) WithScope(scope *datascope.Scope) *Service {
+	scoped := *s
+	scoped.leadRepo = s.leadRepo.WithScope(scope)
+	return &scoped
+}
+
 // PaginationResult represents pagination information
 type PaginationResult struct {
 	Page       int
@@ -108,6 +130,18 @@ func (s *Service) List(req *lead.ListLeadsRequest) ([]lead.LeadResponse, *Pagina
 	return responses, pagination, nil
 }
 
+// Export writes every lead matching the list filters to w; rows are flushed to the client after each batch
+func (s *Service) Export(req *lead.ListLeadsRequest, w export.Writer) error {
+	return s.leadRepo.FindInBatches(req, func(leads []lead.Lead) error {
+		for i := range leads {
+			if err := w.WriteRow(leads[i].ExportRow()); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	})
+}
+
 // GetByID returns a lead by ID
 func (s *Service) GetByID(id string) (*lead.LeadResponse, error) {
 	l, err := s.leadRepo.FindByID(id)
@@ -117,7 +151,8 @@ func (s *Service) GetByID(id string) (*lead.LeadResponse, error) {
 		}
 		return nil, err
 	}
-	return l.ToLeadResponse(), nil
+
+	return withScoreBreakdown(l), nil
 }
 
 // Create creates a new lead
@@ -157,17 +192,33 @@ func (s *Service) Create(req *lead.CreateLeadRequest, createdBy string) (*lead.L
 		CreatedBy:   createdBy,
 	}
 
+	if !req.IgnoreDuplicates {
+		if err := s.checkLeadDuplicates(l); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.leadRepo.Create(l); err != nil {
 		return nil, err
 	}
+	// The lead is saved; a failed scoring leaves the previous score until the next rescore
+	if err := s.RecomputeScore(l.ID); err != nil {
+		log.Printf("Failed to recompute score of lead %s: %v", l.ID, err)
+	}
+	// A failed routing leaves the lead unassigned for manual assignment
+	if l.AssignedTo == nil {
+		if err := s.autoAssign(l.ID); err != nil {
+			log.Printf("Failed to auto-assign lead %s: %v", l.ID, err)
+		}
+	}
 
-	// Reload to get relations
-	l, err := s.leadRepo.FindByID(l.ID)
+	// Reload to get relations; unscoped, since routing may assign the lead outside the creator's data scope
+	l, err := s.systemLeadRepo.FindByID(l.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	return l.ToLeadResponse(), nil
+	return withScoreBreakdown(l), nil
 }
 
 // Update updates a lead
@@ -255,14 +306,11 @@ func (s *Service) Update(id string, req *lead.UpdateLeadRequest) (*lead.LeadResp
 	if err := s.leadRepo.Update(l); err != nil {
 		return nil, err
 	}
-
-	// Reload to get relations
-	l, err = s.leadRepo.FindByID(l.ID)
-	if err != nil {
-		return nil, err
+	if err := s.RecomputeScore(l.ID); err != nil {
+		log.Printf("Failed to recompute score of lead %s: %v", l.ID, err)
 	}
 
-	return l.ToLeadResponse(), nil
+	return s.GetByID(l.ID)
 }
 
 // Delete deletes a lead
@@ -313,6 +361,17 @@ func (s *Service) Convert(id string, req *lead.ConvertLeadRequest, convertedBy s
 		return nil, err
 	}
 
+	// Check for duplicates before creating anything, so a rejected conversion leaves no records behind
+	if !req.IgnoreDuplicates {
+		existingAccountID := ""
+		if !req.CreateAccount {
+			existingAccountID = req.AccountID
+		}
+		if err := s.checkConversionDuplicates(l, req.CreateAccount, req.CreateContact, existingAccountID); err != nil {
+			return nil, err
+		}
+	}
+
 	var accountID string
 	var contactID string
 	var createdAccount interface{}
@@ -431,6 +490,7 @@ func (s *Service) Convert(id string, req *lead.ConvertLeadRequest, convertedBy s
 		Description:       req.OpportunityDescription,
 		AccountID:         accountID,
 		ContactID:         contactID,
+		PipelineID:        stage.PipelineID,
 		StageID:           req.StageID,
 		Value:             dealValue,
 		Probability:       probability,
@@ -443,7 +503,13 @@ func (s *Service) Convert(id string, req *lead.ConvertLeadRequest, convertedBy s
 		CreatedBy:         convertedBy,
 	}
 
-	if err := s.dealRepo.Create(deal); err != nil {
+	stageChange := &pipeline.DealStageHistory{
+		ToStageID: deal.StageID,
+		EnteredAt: time.Now().UTC(),
+		ChangedBy: &convertedBy,
+		Source:    pipeline.StageChangeLeadConversion,
+	}
+	if err := s.dealRepo.Create(deal, stageChange); err != nil {
 		return nil, ErrOpportunityCreationFailed
 	}
 
@@ -579,6 +645,12 @@ func (s *Service) CreateAccountFromLead(leadID string, req *lead.CreateAccountFr
 		categoryID = categories[0].ID
 	}
 
+	if !req.IgnoreDuplicates {
+		if err := s.checkConversionDuplicates(l, true, req.CreateContact, ""); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create account
 	account := &account.Account{
 		Name:       l.CompanyName,
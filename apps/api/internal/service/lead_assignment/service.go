@@ -0,0 +1,179 @@
+package lead_assignment
+
+import (
+	"errors"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_assignment"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAssignmentRuleNotFound = errors.New("assignment rule not found")
+	ErrInvalidAssignmentRule  = errors.New("invalid assignment rule")
+	ErrUserNotFound           = errors.New("user not found")
+	ErrTeamNotFound           = errors.New("team not found")
+)
+
+type Service struct {
+	ruleRepo interfaces.LeadAssignmentRuleRepository
+	userRepo interfaces.UserRepository
+	teamRepo interfaces.TeamRepository
+}
+
+func NewService(ruleRepo interfaces.LeadAssignmentRuleRepository, userRepo interfaces.UserRepository, teamRepo interfaces.TeamRepository) *Service {
+	return &Service{
+		ruleRepo: ruleRepo,
+		userRepo: userRepo,
+		teamRepo: teamRepo,
+	}
+}
+
+// List returns all assignment rules by priority
+func (s *Service) List() ([]lead_assignment.AssignmentRule, error) {
+	return s.ruleRepo.List()
+}
+
+// GetByID returns an assignment rule by ID
+func (s *Service) GetByID(id string) (*lead_assignment.AssignmentRule, error) {
+	rule, err := s.ruleRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAssignmentRuleNotFound
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+// Create creates an assignment rule
+func (s *Service) Create(req *lead_assignment.CreateAssignmentRuleRequest) (*lead_assignment.AssignmentRule, error) {
+	rule := &lead_assignment.AssignmentRule{
+		Name:        req.Name,
+		Priority:    req.Priority,
+		IsActive:    true,
+		LeadSources: req.LeadSources,
+		Provinces:   req.Provinces,
+		Cities:      req.Cities,
+		Industries:  req.Industries,
+		MinScore:    req.MinScore,
+		MaxScore:    req.MaxScore,
+		Strategy:    req.Strategy,
+		UserID:      req.UserID,
+		TeamID:      req.TeamID,
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := s.validate(rule); err != nil {
+		return nil, err
+	}
+	if err := s.ruleRepo.Create(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// Update updates an assignment rule
+func (s *Service) Update(id string, req *lead_assignment.UpdateAssignmentRuleRequest) (*lead_assignment.AssignmentRule, error) {
+	rule, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		rule.Name = req.Name
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	if req.LeadSources != nil {
+		rule.LeadSources = *req.LeadSources
+	}
+	if req.Provinces != nil {
+		rule.Provinces = *req.Provinces
+	}
+	if req.Cities != nil {
+		rule.Cities = *req.Cities
+	}
+	if req.Industries != nil {
+		rule.Industries = *req.Industries
+	}
+	if req.MinScore != nil {
+		rule.MinScore = req.MinScore
+	}
+	if req.MaxScore != nil {
+		rule.MaxScore = req.MaxScore
+	}
+	if req.Strategy != "" && req.Strategy != rule.Strategy {
+		rule.Strategy = req.Strategy
+		rule.LastAssignedUserID = nil
+	}
+	if req.UserID != nil {
+		rule.UserID = req.UserID
+	}
+	if req.TeamID != nil {
+		if rule.TeamID == nil || *rule.TeamID != *req.TeamID {
+			rule.LastAssignedUserID = nil // Restart rotation for the new team
+		}
+		rule.TeamID = req.TeamID
+	}
+
+	// Keep only the owner the strategy uses
+	if rule.Strategy == lead_assignment.StrategyUser {
+		rule.TeamID = nil
+	} else {
+		rule.UserID = nil
+	}
+
+	if err := s.validate(rule); err != nil {
+		return nil, err
+	}
+	if err := s.ruleRepo.Update(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// Delete deletes an assignment rule
+func (s *Service) Delete(id string) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	return s.ruleRepo.Delete(id)
+}
+
+// validate checks the score range and that the user or team of the strategy exists
+func (s *Service) validate(rule *lead_assignment.AssignmentRule) error {
+	if rule.MinScore != nil && rule.MaxScore != nil && *rule.MinScore > *rule.MaxScore {
+		return ErrInvalidAssignmentRule
+	}
+
+	if rule.Strategy == lead_assignment.StrategyUser {
+		if rule.UserID == nil {
+			return ErrInvalidAssignmentRule
+		}
+		if _, err := s.userRepo.FindByID(*rule.UserID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		return nil
+	}
+
+	if rule.TeamID == nil {
+		return ErrInvalidAssignmentRule
+	}
+	if _, err := s.teamRepo.FindByID(*rule.TeamID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTeamNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package lead_scoring
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_scoring"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrScoringRuleNotFound = errors.New("scoring rule not found")
+	ErrInvalidScoringRule  = errors.New("operator or value is not valid for the criterion")
+	ErrScoringUnavailable  = errors.New("lead scoring is not available")
+)
+
+// LeadScorerInterface defines interface for rescoring leads after the rules change
+type LeadScorerInterface interface {
+	RecomputeAllScores() (int, error)
+}
+
+type Service struct {
+	ruleRepo   interfaces.LeadScoringRuleRepository
+	leadScorer LeadScorerInterface
+}
+
+func NewService(ruleRepo interfaces.LeadScoringRuleRepository) *Service {
+	return &Service{
+		ruleRepo:   ruleRepo,
+		leadScorer: nil, // Will be set via SetLeadScorer if needed
+	}
+}
+
+// SetLeadScorer sets the lead scorer used to rescore all leads after a rule change
+func (s *Service) SetLeadScorer(scorer LeadScorerInterface) {
+	s.leadScorer = scorer
+}
+
+// List returns all scoring rules
+func (s *Service) List() ([]lead_scoring.ScoringRule, error) {
+	return s.ruleRepo.List()
+}
+
+// GetByID returns a scoring rule by ID
+func (s *Service) GetByID(id string) (*lead_scoring.ScoringRule, error) {
+	rule, err := s.ruleRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScoringRuleNotFound
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+// Create creates a scoring rule and rescores all leads
+func (s *Service) Create(req *lead_scoring.CreateScoringRuleRequest) (*lead_scoring.ScoringRule, error) {
+	if !lead_scoring.ValidateRule(req.Criterion, req.Operator, req.Value) {
+		return nil, ErrInvalidScoringRule
+	}
+
+	rule := &lead_scoring.ScoringRule{
+		Name:      req.Name,
+		Criterion: req.Criterion,
+		Operator:  req.Operator,
+		Value:     req.Value,
+		Points:    req.Points,
+		IsActive:  true,
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := s.ruleRepo.Create(rule); err != nil {
+		return nil, err
+	}
+
+	s.rescoreLeads()
+	return rule, nil
+}
+
+// Update updates a scoring rule and rescores all leads
+func (s *Service) Update(id string, req *lead_scoring.UpdateScoringRuleRequest) (*lead_scoring.ScoringRule, error) {
+	rule, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		rule.Name = req.Name
+	}
+	if req.Criterion != "" {
+		rule.Criterion = req.Criterion
+	}
+	if req.Operator != "" {
+		rule.Operator = req.Operator
+	}
+	if req.Value != nil {
+		rule.Value = *req.Value
+	}
+	if req.Points != nil {
+		rule.Points = *req.Points
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if !lead_scoring.ValidateRule(rule.Criterion, rule.Operator, rule.Value) {
+		return nil, ErrInvalidScoringRule
+	}
+
+	if err := s.ruleRepo.Update(rule); err != nil {
+		return nil, err
+	}
+
+	s.rescoreLeads()
+	return rule, nil
+}
+
+// Delete deletes a scoring rule and rescores all leads
+func (s *Service) Delete(id string) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	if err := s.ruleRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.rescoreLeads()
+	return nil
+}
+
+// Recompute rescores all leads with the active rules and returns the number of leads scored
+func (s *Service) Recompute() (*lead_scoring.RecomputeScoresResponse, error) {
+	if s.leadScorer == nil {
+		return nil, ErrScoringUnavailable
+	}
+	updated, err := s.leadScorer.RecomputeAllScores()
+	if err != nil {
+		return nil, err
+	}
+	return &lead_scoring.RecomputeScoresResponse{Updated: updated}, nil
+}
+
+// rescoreLeads rescores all leads in the background, since it may touch many leads; failures are logged only
+func (s *Service) rescoreLeads() {
+	if s.leadScorer == nil {
+		return
+	}
+	go func() {
+		if _, err := s.leadScorer.RecomputeAllScores(); err != nil {
+			log.Printf("Failed to recompute lead scores: %v", err)
+		}
+	}()
+}
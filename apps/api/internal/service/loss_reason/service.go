@@ -0,0 +1,137 @@
+package loss_reason
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/loss_reason"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLossReasonNotFound      = errors.New("loss reason not found")
+	ErrLossReasonAlreadyExists = errors.New("loss reason already exists")
+)
+
+type Service struct {
+	lossReasonRepo interfaces.LossReasonRepository
+}
+
+func NewService(lossReasonRepo interfaces.LossReasonRepository) *Service {
+	return &Service{
+		lossReasonRepo: lossReasonRepo,
+	}
+}
+
+// List returns a list of loss reasons
+func (s *Service) List(req *loss_reason.ListLossReasonsRequest) ([]loss_reason.LossReasonResponse, error) {
+	reasons, err := s.lossReasonRepo.List(req)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]loss_reason.LossReasonResponse, len(reasons))
+	for i := range reasons {
+		responses[i] = *reasons[i].ToLossReasonResponse()
+	}
+
+	return responses, nil
+}
+
+// GetByID returns a loss reason by ID
+func (s *Service) GetByID(id string) (*loss_reason.LossReasonResponse, error) {
+	lr, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	return lr.ToLossReasonResponse(), nil
+}
+
+// Create creates a new loss reason
+func (s *Service) Create(req *loss_reason.CreateLossReasonRequest) (*loss_reason.LossReasonResponse, error) {
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	if err := s.checkCode(code, ""); err != nil {
+		return nil, err
+	}
+
+	status := req.Status
+	if status == "" {
+		status = "active"
+	}
+
+	lr := &loss_reason.LossReason{
+		Name:        strings.TrimSpace(req.Name),
+		Code:        code,
+		Description: req.Description,
+		Status:      status,
+	}
+
+	if err := s.lossReasonRepo.Create(lr); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(lr.ID)
+}
+
+// Update updates a loss reason
+func (s *Service) Update(id string, req *loss_reason.UpdateLossReasonRequest) (*loss_reason.LossReasonResponse, error) {
+	lr, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		lr.Name = strings.TrimSpace(req.Name)
+	}
+	if req.Code != "" {
+		code := strings.ToLower(strings.TrimSpace(req.Code))
+		if err := s.checkCode(code, id); err != nil {
+			return nil, err
+		}
+		lr.Code = code
+	}
+	if req.Description != "" {
+		lr.Description = req.Description
+	}
+	if req.Status != "" {
+		lr.Status = req.Status
+	}
+
+	if err := s.lossReasonRepo.Update(lr); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(lr.ID)
+}
+
+// Delete deletes a loss reason; deals lost for it keep their reason
+func (s *Service) Delete(id string) error {
+	if _, err := s.find(id); err != nil {
+		return err
+	}
+	return s.lossReasonRepo.Delete(id)
+}
+
+func (s *Service) find(id string) (*loss_reason.LossReason, error) {
+	lr, err := s.lossReasonRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLossReasonNotFound
+		}
+		return nil, err
+	}
+	return lr, nil
+}
+
+// checkCode returns ErrLossReasonAlreadyExists when another loss reason than excludeID uses the code
+func (s *Service) checkCode(code, excludeID string) error {
+	existing, err := s.lossReasonRepo.FindByCode(code)
+	if err == nil && existing.ID != excludeID {
+		return ErrLossReasonAlreadyExists
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
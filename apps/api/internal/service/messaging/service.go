@@ -0,0 +1,425 @@
+package messaging
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/messaging"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/reminder"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	gateway "github.com/gilabs/crm-healthcare/api/pkg/messaging"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPhoneRequired        = errors.New("a phone number is required to opt in")
+	ErrInvalidPhone         = errors.New("invalid phone number")
+	ErrNotOptedIn           = errors.New("user has not opted in to this channel")
+	ErrUnknownChannel       = errors.New("unknown messaging channel")
+	ErrChannelNotConfigured = errors.New("messaging channel is not configured")
+	ErrUnknownTemplate      = errors.New("no message template for notification type")
+)
+
+const (
+	// DefaultMaxAttempts is how often a message is tried before it is marked failed
+	DefaultMaxAttempts = 5
+	// DefaultBrandName prefixes every message
+	DefaultBrandName = "CRM Healthcare"
+	// DefaultCountryCode is used for phone numbers entered without one
+	DefaultCountryCode = "62"
+
+	batchSize    = 50               // Messages sent per run of the worker
+	attemptLease = 10 * time.Minute // Time a worker holds a claimed message before another may retry it
+	firstRetry   = time.Minute
+	maxRetry     = 6 * time.Hour
+)
+
+// preferredChannels is the order in which EnqueuePreferred picks a channel the user opted in to
+var preferredChannels = []string{gateway.ChannelWhatsApp, gateway.ChannelSMS}
+
+type Service struct {
+	repo          interfaces.MessagingRepository
+	reminderRepo  interfaces.ReminderRepository
+	gateways      map[string]gateway.Gateway
+	brandName     string
+	maxAttempts   int
+	countryCode   string
+	webhookSecret string
+	now           func() time.Time
+}
+
+func NewService(repo interfaces.MessagingRepository, reminderRepo interfaces.ReminderRepository) *Service {
+	return &Service{
+		repo:         repo,
+		reminderRepo: reminderRepo,
+		gateways:     make(map[string]gateway.Gateway),
+		brandName:    DefaultBrandName,
+		maxAttempts:  DefaultMaxAttempts,
+		countryCode:  DefaultCountryCode,
+		now:          time.Now,
+	}
+}
+
+// SetGateway sets the gateway that sends messages of a channel; channels without one queue nothing
+func (s *Service) SetGateway(channel string, gw gateway.Gateway) {
+	s.gateways[channel] = gw
+}
+
+// SetBrandName sets the name that prefixes every message
+func (s *Service) SetBrandName(name string) {
+	if name != "" {
+		s.brandName = name
+	}
+}
+
+// SetMaxAttempts sets how often a message is tried before it is marked failed
+func (s *Service) SetMaxAttempts(maxAttempts int) {
+	if maxAttempts > 0 {
+		s.maxAttempts = maxAttempts
+	}
+}
+
+// SetDefaultCountryCode sets the calling code assumed for phone numbers entered without one, e.g. 62
+func (s *Service) SetDefaultCountryCode(code string) {
+	if code != "" {
+		s.countryCode = code
+	}
+}
+
+// SetWebhookSecret sets the token delivery receipt webhooks must present; without one receipts are rejected
+func (s *Service) SetWebhookSecret(secret string) {
+	s.webhookSecret = secret
+}
+
+// GetPreference returns the messaging preference of a user; users who never set one get an empty preference
+func (s *Service) GetPreference(userID string) (*messaging.PreferenceResponse, error) {
+	p, err := s.findPreference(userID)
+	if err != nil {
+		return nil, err
+	}
+	return p.ToPreferenceResponse(), nil
+}
+
+// UpdatePreference stores the phone number of a user and the channels they opted in to
+func (s *Service) UpdatePreference(userID string, req *messaging.UpdatePreferenceRequest) (*messaging.PreferenceResponse, error) {
+	p, err := s.findPreference(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Phone != nil {
+		if *req.Phone == "" {
+			p.Phone, p.SMSOptIn, p.WhatsAppOptIn = "", false, false
+		} else {
+			phone, err := gateway.NormalizePhone(*req.Phone, s.countryCode)
+			if err != nil {
+				return nil, ErrInvalidPhone
+			}
+			p.Phone = phone
+		}
+	}
+
+	optedIn := false
+	if req.SMSOptIn != nil {
+		optedIn = optedIn || (*req.SMSOptIn && !p.SMSOptIn)
+		p.SMSOptIn = *req.SMSOptIn
+	}
+	if req.WhatsAppOptIn != nil {
+		optedIn = optedIn || (*req.WhatsAppOptIn && !p.WhatsAppOptIn)
+		p.WhatsAppOptIn = *req.WhatsAppOptIn
+	}
+	if (p.SMSOptIn || p.WhatsAppOptIn) && p.Phone == "" {
+		return nil, ErrPhoneRequired
+	}
+	if optedIn {
+		now := s.now().UTC()
+		p.OptedInAt = &now
+	}
+
+	if err := s.repo.SavePreference(p); err != nil {
+		return nil, err
+	}
+	return p.ToPreferenceResponse(), nil
+}
+
+// Enqueue renders a notification with the template of its type and queues it for the user's phone on
+// the requested channel. The worker sends it on its next run.
+func (s *Service) Enqueue(n *messaging.NotificationMessage) (*messaging.OutboundMessage, error) {
+	if n.Channel != gateway.ChannelSMS && n.Channel != gateway.ChannelWhatsApp {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownChannel, n.Channel)
+	}
+	if s.gateways[n.Channel] == nil {
+		return nil, ErrChannelNotConfigured
+	}
+	p, err := s.findPreference(n.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.OptedIn(n.Channel) {
+		return nil, ErrNotOptedIn
+	}
+	return s.enqueue(p, n.Channel, n)
+}
+
+// EnqueuePreferred queues a notification on the first configured channel the user opted in to, WhatsApp
+// before SMS. It returns nil without an error when there is no such channel.
+func (s *Service) EnqueuePreferred(n *messaging.NotificationMessage) (*messaging.OutboundMessage, error) {
+	p, err := s.findPreference(n.UserID)
+	if err != nil {
+		return nil, err
+	}
+	for _, channel := range preferredChannels {
+		if s.gateways[channel] != nil && p.OptedIn(channel) {
+			return s.enqueue(p, channel, n)
+		}
+	}
+	return nil, nil
+}
+
+func (s *Service) enqueue(p *messaging.Preference, channel string, n *messaging.NotificationMessage) (*messaging.OutboundMessage, error) {
+	tmpl, ok := templates[n.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTemplate, n.Type)
+	}
+	body, err := render(tmpl, &templateData{
+		BrandName: s.brandName,
+		Title:     n.Title,
+		Message:   n.Message,
+		When:      n.When,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("render %s message: %w", n.Type, err)
+	}
+
+	userID := p.UserID
+	msg := &messaging.OutboundMessage{
+		UserID:        &userID,
+		Channel:       channel,
+		Recipient:     p.Phone,
+		Template:      n.Type,
+		Body:          body,
+		Status:        messaging.StatusPending,
+		MaxAttempts:   s.maxAttempts,
+		NextAttemptAt: s.now().UTC(),
+		ReminderID:    n.ReminderID,
+	}
+	if err := s.repo.CreateMessage(msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// ProcessDue sends the messages that are due and returns how many the gateways accepted. Every attempt
+// is claimed first, so several API instances can run the worker without sending a message twice.
+func (s *Service) ProcessDue() (int, error) {
+	var channels []string
+	for channel, gw := range s.gateways {
+		if gw != nil {
+			channels = append(channels, channel)
+		}
+	}
+	if len(channels) == 0 {
+		return 0, nil
+	}
+
+	// Messages of channels that were switched off after they were queued wait until the channel is back
+	messages, err := s.repo.FindDueMessages(channels, s.now(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range messages {
+		msg := &messages[i]
+		claimed, err := s.repo.ClaimAttempt(msg.ID, msg.Attempts, s.now().Add(attemptLease))
+		if err != nil {
+			log.Printf("Warning: Failed to claim message %s: %v", msg.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		msg.Attempts++
+		if s.attempt(s.gateways[msg.Channel], msg) {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// attempt sends a claimed message and records the outcome; it reports whether the gateway accepted it
+func (s *Service) attempt(gw gateway.Gateway, msg *messaging.OutboundMessage) bool {
+	providerID, err := gw.Send(&gateway.Message{Channel: msg.Channel, To: msg.Recipient, Body: msg.Body})
+
+	now := s.now().UTC()
+	switch {
+	case err == nil:
+		msg.Status = messaging.StatusSent
+		msg.ProviderMessageID = providerID
+		msg.SentAt = &now
+		msg.LastError = ""
+	case gateway.IsPermanent(err) || msg.Attempts >= msg.MaxAttempts:
+		msg.Status = messaging.StatusFailed
+		msg.LastError = err.Error()
+	default:
+		msg.NextAttemptAt = now.Add(retryDelay(msg.Attempts))
+		msg.LastError = err.Error()
+	}
+
+	if err := s.repo.UpdateMessage(msg); err != nil {
+		log.Printf("Warning: Failed to update message %s: %v", msg.ID, err)
+	}
+	if msg.Status != messaging.StatusPending {
+		s.updateReminder(msg)
+	}
+	if msg.Status == messaging.StatusFailed {
+		log.Printf("Warning: %s message %s to %s failed after %d attempt(s): %s", msg.Channel, msg.ID, msg.Recipient, msg.Attempts, msg.LastError)
+	}
+	return msg.Status == messaging.StatusSent
+}
+
+// VerifyWebhookToken reports whether a receipt webhook presented the configured secret
+func (s *Service) VerifyWebhookToken(token string) bool {
+	if s.webhookSecret == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.webhookSecret)) == 1
+}
+
+// HandleReceipt applies a delivery receipt posted by the gateway of a channel. Payloads that are not
+// receipts, receipts for messages this service did not send and receipts that only repeat that a message
+// was sent are ignored.
+func (s *Service) HandleReceipt(channel string, contentType string, body []byte) error {
+	gw, ok := s.gateways[channel]
+	if !ok {
+		return ErrChannelNotConfigured
+	}
+	parser, ok := gw.(gateway.ReceiptParser)
+	if !ok {
+		return ErrChannelNotConfigured
+	}
+	receipt, err := parser.ParseReceipt(contentType, body)
+	if err != nil {
+		if errors.Is(err, gateway.ErrNoReceipt) {
+			return nil
+		}
+		return err
+	}
+
+	msg, err := s.repo.FindMessageByProviderID(channel, receipt.MessageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if msg.Status == messaging.StatusFailed || receipt.Status == gateway.StatusSent {
+		return nil
+	}
+
+	now := s.now().UTC()
+	switch receipt.Status {
+	case gateway.StatusDelivered:
+		if msg.Status == messaging.StatusDelivered {
+			return nil
+		}
+		msg.Status = messaging.StatusDelivered
+		msg.DeliveredAt = &now
+	case gateway.StatusFailed:
+		msg.Status = messaging.StatusFailed
+		msg.LastError = receipt.Error
+		if msg.LastError == "" {
+			msg.LastError = "provider reported the message as undelivered"
+		}
+	}
+
+	if err := s.repo.UpdateMessage(msg); err != nil {
+		return err
+	}
+	s.updateReminder(msg)
+	return nil
+}
+
+// updateReminder copies the status of a message to the reminder it delivers
+func (s *Service) updateReminder(msg *messaging.OutboundMessage) {
+	if msg.ReminderID == nil {
+		return
+	}
+	status := reminder.DeliveryStatusSent
+	switch msg.Status {
+	case messaging.StatusDelivered:
+		status = reminder.DeliveryStatusDelivered
+	case messaging.StatusFailed:
+		status = reminder.DeliveryStatusFailed
+	}
+	if err := s.reminderRepo.UpdateDeliveryStatus(*msg.ReminderID, status, msg.LastError); err != nil {
+		log.Printf("Warning: Failed to update delivery status of reminder %s: %v", *msg.ReminderID, err)
+	}
+}
+
+// ListMessages returns a list of queued and sent messages
+func (s *Service) ListMessages(req *messaging.ListOutboundMessagesRequest) ([]messaging.OutboundMessageResponse, *PaginationResult, error) {
+	messages, total, err := s.repo.ListMessages(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]messaging.OutboundMessageResponse, len(messages))
+	for i := range messages {
+		responses[i] = *messages[i].ToOutboundMessageResponse()
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	totalPages := int((total + int64(perPage) - 1) / int64(perPage))
+
+	pagination := &PaginationResult{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      int(total),
+		TotalPages: totalPages,
+	}
+
+	return responses, pagination, nil
+}
+
+func (s *Service) findPreference(userID string) (*messaging.Preference, error) {
+	p, err := s.repo.FindPreference(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &messaging.Preference{UserID: userID}, nil
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// retryDelay returns the wait after a failed attempt: one minute, then five times longer each attempt, at most six hours
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetry
+	for i := 1; i < attempts && delay < maxRetry; i++ {
+		delay *= 5
+	}
+	return min(delay, maxRetry)
+}
+
+// PaginationResult represents pagination information
+type PaginationResult struct {
+	Page       int
+	PerPage    int
+	Total      int
+	TotalPages int
+}
@@ -0,0 +1,281 @@
+package messaging
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/messaging"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/reminder"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	gateway "github.com/gilabs/crm-healthcare/api/pkg/messaging"
+	"gorm.io/gorm"
+)
+
+type fakeRepo struct {
+	interfaces.MessagingRepository
+	preferences map[string]*messaging.Preference
+	messages    []*messaging.OutboundMessage
+}
+
+func (r *fakeRepo) FindPreference(userID string) (*messaging.Preference, error) {
+	if p, ok := r.preferences[userID]; ok {
+		copied := *p
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRepo) SavePreference(p *messaging.Preference) error {
+	copied := *p
+	r.preferences[p.UserID] = &copied
+	return nil
+}
+
+func (r *fakeRepo) FindMessageByProviderID(channel string, providerMessageID string) (*messaging.OutboundMessage, error) {
+	for _, m := range r.messages {
+		if m.Channel == channel && m.ProviderMessageID == providerMessageID {
+			copied := *m
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRepo) CreateMessage(m *messaging.OutboundMessage) error {
+	m.ID = "message-" + m.Channel
+	copied := *m
+	r.messages = append(r.messages, &copied)
+	return nil
+}
+
+func (r *fakeRepo) UpdateMessage(m *messaging.OutboundMessage) error {
+	for i := range r.messages {
+		if r.messages[i].ID == m.ID {
+			copied := *m
+			r.messages[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepo) FindDueMessages(channels []string, now time.Time, limit int) ([]messaging.OutboundMessage, error) {
+	var due []messaging.OutboundMessage
+	for _, m := range r.messages {
+		if m.Status == messaging.StatusPending && slices.Contains(channels, m.Channel) && !m.NextAttemptAt.After(now) {
+			due = append(due, *m)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeRepo) ClaimAttempt(id string, attempts int, leaseUntil time.Time) (bool, error) {
+	for _, m := range r.messages {
+		if m.ID == id && m.Status == messaging.StatusPending && m.Attempts == attempts {
+			m.Attempts++
+			m.NextAttemptAt = leaseUntil
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type fakeReminderRepo struct {
+	interfaces.ReminderRepository
+	statuses map[string]string
+}
+
+func (r *fakeReminderRepo) UpdateDeliveryStatus(id string, status string, deliveryError string) error {
+	r.statuses[id] = status
+	return nil
+}
+
+// fakeGateway records sent messages and returns err, if set, instead of sending
+type fakeGateway struct {
+	sent []*gateway.Message
+	err  error
+}
+
+func (g *fakeGateway) Send(msg *gateway.Message) (string, error) {
+	if g.err != nil {
+		return "", g.err
+	}
+	g.sent = append(g.sent, msg)
+	return "provider-1", nil
+}
+
+func (g *fakeGateway) ParseReceipt(contentType string, body []byte) (*gateway.Receipt, error) {
+	return &gateway.Receipt{MessageID: "provider-1", Status: string(body)}, nil
+}
+
+type clock struct{ now time.Time }
+
+func newTestService() (*Service, *fakeRepo, *fakeReminderRepo, *fakeGateway, *clock) {
+	repo := &fakeRepo{preferences: map[string]*messaging.Preference{}}
+	reminders := &fakeReminderRepo{statuses: map[string]string{}}
+	whatsapp := &fakeGateway{}
+	c := &clock{now: time.Date(2025, 3, 10, 2, 0, 0, 0, time.UTC)}
+
+	svc := NewService(repo, reminders)
+	svc.SetGateway(gateway.ChannelWhatsApp, whatsapp)
+	svc.SetGateway(gateway.ChannelSMS, &fakeGateway{})
+	svc.SetMaxAttempts(2)
+	svc.now = func() time.Time { return c.now }
+	return svc, repo, reminders, whatsapp, c
+}
+
+func boolPtr(b bool) *bool    { return &b }
+func strPtr(s string) *string { return &s }
+
+func TestUpdatePreference(t *testing.T) {
+	svc, repo, _, _, c := newTestService()
+
+	if _, err := svc.UpdatePreference("user-1", &messaging.UpdatePreferenceRequest{WhatsAppOptIn: boolPtr(true)}); !errors.Is(err, ErrPhoneRequired) {
+		t.Errorf("expected ErrPhoneRequired, got %v", err)
+	}
+	if _, err := svc.UpdatePreference("user-1", &messaging.UpdatePreferenceRequest{Phone: strPtr("0812")}); !errors.Is(err, ErrInvalidPhone) {
+		t.Errorf("expected ErrInvalidPhone, got %v", err)
+	}
+
+	resp, err := svc.UpdatePreference("user-1", &messaging.UpdatePreferenceRequest{Phone: strPtr("0812-3456-7890"), WhatsAppOptIn: boolPtr(true)})
+	if err != nil {
+		t.Fatalf("UpdatePreference: %v", err)
+	}
+	if resp.Phone != "+6281234567890" || !resp.WhatsAppOptIn || resp.SMSOptIn || resp.OptedInAt == nil || !resp.OptedInAt.Equal(c.now) {
+		t.Errorf("unexpected preference %+v", resp)
+	}
+
+	resp, _ = svc.UpdatePreference("user-1", &messaging.UpdatePreferenceRequest{Phone: strPtr("")})
+	if resp.Phone != "" || resp.WhatsAppOptIn || repo.preferences["user-1"].WhatsAppOptIn {
+		t.Errorf("removing the phone must opt out of every channel, got %+v", resp)
+	}
+}
+
+func TestEnqueue_RequiresOptInAndGateway(t *testing.T) {
+	svc, repo, _, _, _ := newTestService()
+	n := &messaging.NotificationMessage{UserID: "user-1", Channel: gateway.ChannelSMS, Type: "task", Title: "Visit RS Sehat", When: "11 Mar 2025 09:00 WIB"}
+
+	if _, err := svc.Enqueue(n); !errors.Is(err, ErrNotOptedIn) {
+		t.Errorf("expected ErrNotOptedIn, got %v", err)
+	}
+
+	repo.preferences["user-1"] = &messaging.Preference{UserID: "user-1", Phone: "+6281234567890", SMSOptIn: true}
+	msg, err := svc.Enqueue(n)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if msg.Recipient != "+6281234567890" || msg.Body != "[CRM Healthcare] Task: Visit RS Sehat (due 11 Mar 2025 09:00 WIB)" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+
+	if _, err := svc.Enqueue(&messaging.NotificationMessage{UserID: "user-1", Channel: gateway.ChannelSMS, Type: "invoice"}); !errors.Is(err, ErrUnknownTemplate) {
+		t.Errorf("expected ErrUnknownTemplate, got %v", err)
+	}
+	if _, err := svc.Enqueue(&messaging.NotificationMessage{UserID: "user-1", Channel: "fax", Type: "task"}); !errors.Is(err, ErrUnknownChannel) {
+		t.Errorf("expected ErrUnknownChannel, got %v", err)
+	}
+	svc.SetGateway(gateway.ChannelSMS, nil)
+	if _, err := svc.Enqueue(n); !errors.Is(err, ErrChannelNotConfigured) {
+		t.Errorf("expected ErrChannelNotConfigured, got %v", err)
+	}
+}
+
+func TestEnqueuePreferred(t *testing.T) {
+	svc, repo, _, _, _ := newTestService()
+	n := &messaging.NotificationMessage{UserID: "user-1", Type: "task", Title: "Visit RS Sehat"}
+
+	if msg, err := svc.EnqueuePreferred(n); msg != nil || err != nil {
+		t.Errorf("expected nothing to be queued without opt-in, got %+v, %v", msg, err)
+	}
+
+	repo.preferences["user-1"] = &messaging.Preference{UserID: "user-1", Phone: "+6281234567890", SMSOptIn: true, WhatsAppOptIn: true}
+	msg, err := svc.EnqueuePreferred(n)
+	if err != nil || msg.Channel != gateway.ChannelWhatsApp {
+		t.Errorf("expected WhatsApp to be preferred, got %+v, %v", msg, err)
+	}
+}
+
+func TestProcessDue_SendsThenAppliesReceipts(t *testing.T) {
+	svc, repo, reminders, whatsapp, _ := newTestService()
+	repo.preferences["user-1"] = &messaging.Preference{UserID: "user-1", Phone: "+6281234567890", WhatsAppOptIn: true}
+	reminderID := "reminder-1"
+	if _, err := svc.Enqueue(&messaging.NotificationMessage{UserID: "user-1", Channel: gateway.ChannelWhatsApp, Type: "reminder", Title: "Call dr. Sari", ReminderID: &reminderID}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	sent, err := svc.ProcessDue()
+	if err != nil || sent != 1 || len(whatsapp.sent) != 1 {
+		t.Fatalf("ProcessDue = %d, %v; want 1", sent, err)
+	}
+	msg := repo.messages[0]
+	if msg.Status != messaging.StatusSent || msg.ProviderMessageID != "provider-1" || reminders.statuses[reminderID] != reminder.DeliveryStatusSent {
+		t.Errorf("unexpected message %+v", msg)
+	}
+	if sent, _ := svc.ProcessDue(); sent != 0 || len(whatsapp.sent) != 1 {
+		t.Error("a sent message must not be sent again")
+	}
+
+	if err := svc.HandleReceipt(gateway.ChannelWhatsApp, "application/json", []byte(gateway.StatusDelivered)); err != nil {
+		t.Fatalf("HandleReceipt: %v", err)
+	}
+	if msg := repo.messages[0]; msg.Status != messaging.StatusDelivered || msg.DeliveredAt == nil {
+		t.Errorf("expected the message to be delivered, got %+v", msg)
+	}
+	if reminders.statuses[reminderID] != reminder.DeliveryStatusDelivered {
+		t.Errorf("expected the reminder to be delivered, got %q", reminders.statuses[reminderID])
+	}
+
+	if err := svc.HandleReceipt(gateway.ChannelSMS, "application/json", []byte(gateway.StatusFailed)); err != nil {
+		t.Errorf("receipts for unknown messages must be ignored, got %v", err)
+	}
+}
+
+func TestProcessDue_RetriesThenFails(t *testing.T) {
+	svc, repo, reminders, whatsapp, c := newTestService()
+	repo.preferences["user-1"] = &messaging.Preference{UserID: "user-1", Phone: "+6281234567890", WhatsAppOptIn: true}
+	reminderID := "reminder-1"
+	svc.Enqueue(&messaging.NotificationMessage{UserID: "user-1", Channel: gateway.ChannelWhatsApp, Type: "reminder", Title: "Call dr. Sari", ReminderID: &reminderID})
+	whatsapp.err = errors.New("gateway returned 503 Service Unavailable")
+
+	svc.ProcessDue()
+	msg := repo.messages[0]
+	if msg.Status != messaging.StatusPending || !msg.NextAttemptAt.Equal(c.now.Add(time.Minute)) {
+		t.Fatalf("expected a retry after a minute, got %+v", msg)
+	}
+	if _, ok := reminders.statuses[reminderID]; ok {
+		t.Error("the reminder status must not change while the message is retried")
+	}
+
+	c.now = msg.NextAttemptAt
+	svc.ProcessDue()
+	if msg := repo.messages[0]; msg.Status != messaging.StatusFailed || msg.Attempts != 2 {
+		t.Errorf("expected the message to fail after 2 attempts, got %+v", msg)
+	}
+	if reminders.statuses[reminderID] != reminder.DeliveryStatusFailed {
+		t.Errorf("expected the reminder to fail, got %q", reminders.statuses[reminderID])
+	}
+}
+
+func TestProcessDue_PermanentErrorFailsImmediately(t *testing.T) {
+	svc, repo, _, whatsapp, _ := newTestService()
+	repo.preferences["user-1"] = &messaging.Preference{UserID: "user-1", Phone: "+6281234567890", WhatsAppOptIn: true}
+	svc.Enqueue(&messaging.NotificationMessage{UserID: "user-1", Channel: gateway.ChannelWhatsApp, Type: "lead", Title: "New lead assigned"})
+	whatsapp.err = &gateway.PermanentError{Err: errors.New("gateway returned 400 Bad Request: invalid number")}
+
+	svc.ProcessDue()
+	if msg := repo.messages[0]; msg.Status != messaging.StatusFailed || msg.Attempts != 1 {
+		t.Errorf("expected the message to fail on the first attempt, got %+v", msg)
+	}
+}
+
+func TestVerifyWebhookToken(t *testing.T) {
+	svc, _, _, _, _ := newTestService()
+	if svc.VerifyWebhookToken("") {
+		t.Error("receipts must be rejected without a configured secret")
+	}
+	svc.SetWebhookSecret("s3cret")
+	if !svc.VerifyWebhookToken("s3cret") || svc.VerifyWebhookToken("secret") {
+		t.Error("unexpected token verification")
+	}
+}
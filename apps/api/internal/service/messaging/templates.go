@@ -0,0 +1,50 @@
+package messaging
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+)
+
+// maxBodyRunes keeps a message within three SMS segments; WhatsApp allows more but reps read them on the go
+const maxBodyRunes = 480
+
+// templateData is what the message templates render
+type templateData struct {
+	BrandName string
+	Title     string
+	Message   string
+	When      string
+}
+
+var templateSources = map[string]string{
+	"reminder": `[{{.BrandName}}] Reminder: {{.Title}}{{if .When}} ({{.When}}){{end}}{{if .Message}}` + "\n" + `{{.Message}}{{end}}`,
+	"task":     `[{{.BrandName}}] Task: {{.Title}}{{if .When}} (due {{.When}}){{end}}{{if .Message}}` + "\n" + `{{.Message}}{{end}}`,
+	"deal":     `[{{.BrandName}}] Deal update: {{.Title}}{{if .Message}}` + "\n" + `{{.Message}}{{end}}`,
+	"activity": `[{{.BrandName}}] Activity: {{.Title}}{{if .When}} ({{.When}}){{end}}{{if .Message}}` + "\n" + `{{.Message}}{{end}}`,
+	"lead":     `[{{.BrandName}}] {{.Title}}{{if .Message}}` + "\n" + `{{.Message}}{{end}}` + "\n" + `Please follow up soon.`,
+}
+
+// templates holds the parsed template of each notification type
+var templates = parseTemplates()
+
+func parseTemplates() map[string]*template.Template {
+	parsed := make(map[string]*template.Template, len(templateSources))
+	for name, source := range templateSources {
+		parsed[name] = template.Must(template.New(name).Parse(source))
+	}
+	return parsed
+}
+
+// render returns the message body for a notification, shortened to maxBodyRunes
+func render(tmpl *template.Template, data *templateData) (string, error) {
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return "", err
+	}
+	text := strings.TrimSpace(body.String())
+	if runes := []rune(text); len(runes) > maxBodyRunes {
+		text = string(runes[:maxBodyRunes-3]) + "..."
+	}
+	return text, nil
+}
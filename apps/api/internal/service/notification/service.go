@@ -2,7 +2,10 @@ package notification
 
 import (
 	"errors"
+	"log"
 
+	"github.com/gilabs/crm-healthcare/api/internal/domain/email_outbox"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/messaging"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/notification"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
 	"gorm.io/gorm"
@@ -15,6 +18,8 @@ var (
 type Service struct {
 	notifRepo interfaces.NotificationRepository
 	hub       HubInterface
+	emailer   Emailer
+	messenger Messenger
 }
 
 // HubInterface defines interface for notification hub
@@ -31,11 +36,31 @@ func NewService(notifRepo interfaces.NotificationRepository) *Service {
 	}
 }
 
+// Emailer queues notification emails; implemented by the email outbox service
+type Emailer interface {
+	Enqueue(n *email_outbox.NotificationEmail) (*email_outbox.EmailMessage, error)
+}
+
+// Messenger queues notification text messages; implemented by the messaging service
+type Messenger interface {
+	EnqueuePreferred(n *messaging.NotificationMessage) (*messaging.OutboundMessage, error)
+}
+
 // SetHub sets the notification hub for broadcasting
 func (s *Service) SetHub(hub HubInterface) {
 	s.hub = hub
 }
 
+// SetEmailer sets the outbox for notifications that are also emailed; without one they stay in-app only
+func (s *Service) SetEmailer(emailer Emailer) {
+	s.emailer = emailer
+}
+
+// SetMessenger sets the messaging service for notifications that also go to the user's phone
+func (s *Service) SetMessenger(messenger Messenger) {
+	s.messenger = messenger
+}
+
 // CreateNotification creates a new notification
 func (s *Service) CreateNotification(req *notification.CreateNotificationRequest) (*notification.NotificationResponse, error) {
 	notifType := req.Type
@@ -78,6 +103,33 @@ func (s *Service) CreateNotification(req *notification.CreateNotificationRequest
 		s.hub.BroadcastNotification(response.UserID, notifMap)
 	}
 
+	// A failed email does not fail the in-app notification
+	if req.Email && s.emailer != nil {
+		_, err := s.emailer.Enqueue(&email_outbox.NotificationEmail{
+			UserID:  response.UserID,
+			Type:    response.Type,
+			Title:   response.Title,
+			Message: response.Message,
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to queue email for notification %s: %v", response.ID, err)
+		}
+	}
+
+	// Only users who opted in to SMS or WhatsApp get a message
+	if req.Messaging && s.messenger != nil {
+		_, err := s.messenger.EnqueuePreferred(&messaging.NotificationMessage{
+			UserID:  response.UserID,
+			Type:    response.Type,
+			Title:   response.Title,
+			Message: response.Message,
+			When:    req.When,
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to queue message for notification %s: %v", response.ID, err)
+		}
+	}
+
 	return response, nil
 }
 
@@ -2,7 +2,10 @@ package permission
 
 import (
 	"errors"
+	"sync"
+	"time"
 
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/permission"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
 	"gorm.io/gorm"
@@ -13,15 +16,31 @@ var (
 	ErrUserNotFound       = errors.New("user not found")
 )
 
+// rolePermissionCacheTTL bounds how long a role's permission codes and data scope are reused
+// before being reloaded from the database
+const rolePermissionCacheTTL = 5 * time.Minute
+
+type rolePermissionCacheEntry struct {
+	codes     []string
+	dataScope string
+	expiresAt time.Time
+}
+
 type Service struct {
 	permissionRepo interfaces.PermissionRepository
 	userRepo       interfaces.UserRepository
+	roleRepo       interfaces.RoleRepository
+
+	cacheMu   sync.RWMutex
+	roleCache map[string]rolePermissionCacheEntry
 }
 
-func NewService(permissionRepo interfaces.PermissionRepository, userRepo interfaces.UserRepository) *Service {
+func NewService(permissionRepo interfaces.PermissionRepository, userRepo interfaces.UserRepository, roleRepo interfaces.RoleRepository) *Service {
 	return &Service{
 		permissionRepo: permissionRepo,
 		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		roleCache:      make(map[string]rolePermissionCacheEntry),
 	}
 }
 
@@ -81,3 +100,96 @@ func (s *Service) GetMobilePermissions(userID string) (*permission.MobilePermiss
 	return s.permissionRepo.GetMobilePermissions(userID)
 }
 
+// GetRolePermissionCodes returns the permission codes granted to a role.
+// Results are cached per role code; call InvalidateRolePermissions after changing role permissions.
+func (s *Service) GetRolePermissionCodes(roleCode string) ([]string, error) {
+	entry, err := s.getRoleAccess(roleCode)
+	if err != nil {
+		return nil, err
+	}
+	return entry.codes, nil
+}
+
+// GetDataScope resolves which assigned records a user may access based on their role's data scope
+func (s *Service) GetDataScope(userID, roleCode string) (*datascope.Scope, error) {
+	entry, err := s.getRoleAccess(roleCode)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := &datascope.Scope{
+		Level:  entry.dataScope,
+		UserID: userID,
+	}
+
+	if scope.Level != datascope.LevelTeam {
+		return scope, nil
+	}
+
+	// Team scope: records of every member of the user's team, or own records without a team
+	u, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if u.TeamID == nil || *u.TeamID == "" {
+		scope.Level = datascope.LevelOwn
+		return scope, nil
+	}
+
+	memberIDs, err := s.userRepo.ListIDsByTeam(*u.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	scope.UserIDs = memberIDs
+
+	return scope, nil
+}
+
+// getRoleAccess returns cached permission codes and data scope for a role, loading them when expired
+func (s *Service) getRoleAccess(roleCode string) (rolePermissionCacheEntry, error) {
+	s.cacheMu.RLock()
+	entry, ok := s.roleCache[roleCode]
+	s.cacheMu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry, nil
+	}
+
+	codes, err := s.permissionRepo.GetCodesByRoleCode(roleCode)
+	if err != nil {
+		return rolePermissionCacheEntry{}, err
+	}
+
+	// Admin always sees all records
+	dataScope := datascope.LevelAll
+	if roleCode != "admin" {
+		r, err := s.roleRepo.FindByCode(roleCode)
+		if err != nil {
+			return rolePermissionCacheEntry{}, err
+		}
+		if r.DataScope != "" {
+			dataScope = r.DataScope
+		}
+	}
+
+	entry = rolePermissionCacheEntry{
+		codes:     codes,
+		dataScope: dataScope,
+		expiresAt: time.Now().Add(rolePermissionCacheTTL),
+	}
+
+	s.cacheMu.Lock()
+	s.roleCache[roleCode] = entry
+	s.cacheMu.Unlock()
+
+	return entry, nil
+}
+
+// InvalidateRolePermissions clears cached role permissions and data scopes so the next check reloads them
+func (s *Service) InvalidateRolePermissions() {
+	s.cacheMu.Lock()
+	s.roleCache = make(map[string]rolePermissionCacheEntry)
+	s.cacheMu.Unlock()
+}
@@ -0,0 +1,250 @@
+package pipeline
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+)
+
+// analyticsDefaultDays is the period the analytics cover when no dates are given
+const analyticsDefaultDays = 90
+
+// GetAnalytics returns time in stage, stage conversion, velocity and funnel analytics for the deals of a pipeline
+// created in the requested period
+func (s *Service) GetAnalytics(req *pipeline.PipelineAnalyticsRequest) (*pipeline.PipelineAnalyticsResponse, error) {
+	pipelineID, err := s.resolvePipelineID(req.PipelineID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+
+	var start, end time.Time
+	if req.StartDate != "" && req.EndDate != "" {
+		start, err = time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return nil, err
+		}
+		end, err = time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		end = end.AddDate(0, 0, 1)
+	} else {
+		end = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+		start = end.AddDate(0, 0, -analyticsDefaultDays)
+	}
+
+	stages, err := s.pipelineRepo.ListStages(&pipeline.ListPipelineStagesRequest{PipelineID: pipelineID})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.stageHistoryRepo.ListForAnalytics(pipelineID, start, end, req.AssignedTo)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := computeAnalytics(stages, entries, now)
+	resp.PipelineID = pipelineID
+	resp.Period = pipeline.ForecastPeriod{Type: "custom", Start: start, End: end.Add(-time.Second)}
+	resp.FormatValues()
+
+	return resp, nil
+}
+
+// dealPath is the stage history of one deal, oldest first
+type dealPath struct {
+	deal    *pipeline.Deal
+	entries []pipeline.DealStageHistory
+}
+
+// computeAnalytics builds the analytics from the stage history of a cohort of deals. Stages are expected in
+// pipeline order; entries grouped by deal, oldest first.
+func computeAnalytics(stages []pipeline.PipelineStage, entries []pipeline.DealStageHistory, now time.Time) *pipeline.PipelineAnalyticsResponse {
+	var paths []dealPath
+	for i := range entries {
+		if n := len(paths); n > 0 && paths[n-1].deal.ID == entries[i].DealID {
+			paths[n-1].entries = append(paths[n-1].entries, entries[i])
+			continue
+		}
+		if entries[i].Deal == nil {
+			continue
+		}
+		paths = append(paths, dealPath{deal: entries[i].Deal, entries: []pipeline.DealStageHistory{entries[i]}})
+	}
+
+	resp := &pipeline.PipelineAnalyticsResponse{
+		TimeInStage: timeInStage(stages, paths, now),
+		Funnel:      funnel(stages, paths),
+		Velocity:    velocity(stages, paths),
+	}
+	resp.Conversions = conversions(resp.Funnel)
+	return resp
+}
+
+// timeInStage averages the completed stays in each open stage. Won and lost stages are left out as deals stay
+// there for good.
+func timeInStage(stages []pipeline.PipelineStage, paths []dealPath, now time.Time) []pipeline.StageTimeStat {
+	stats := make([]pipeline.StageTimeStat, 0, len(stages))
+	for _, stage := range stages {
+		if stage.IsWon || stage.IsLost {
+			continue
+		}
+
+		stat := pipeline.StageTimeStat{StageID: stage.ID, StageName: stage.Name, StageCode: stage.Code}
+		var days []float64
+		for _, p := range paths {
+			for i := range p.entries {
+				e := &p.entries[i]
+				if e.ToStageID != stage.ID {
+					continue
+				}
+				if e.ExitedAt == nil {
+					stat.CurrentDeals++
+					continue
+				}
+				days = append(days, e.Duration(now).Hours()/24)
+			}
+		}
+
+		stat.CompletedStays = len(days)
+		if len(days) > 0 {
+			sort.Float64s(days)
+			var total float64
+			for _, d := range days {
+				total += d
+			}
+			stat.AvgDays = round2(total / float64(len(days)))
+			mid := len(days) / 2
+			if len(days)%2 == 1 {
+				stat.MedianDays = round2(days[mid])
+			} else {
+				stat.MedianDays = round2((days[mid-1] + days[mid]) / 2)
+			}
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// funnel counts the deals that reached each stage of the funnel, the open stages followed by the won stages in
+// pipeline order. A deal that reached a stage also counts for the stages before it, so skipping a stage does not
+// make the funnel widen.
+func funnel(stages []pipeline.PipelineStage, paths []dealPath) []pipeline.FunnelStage {
+	index := make(map[string]int)
+	var result []pipeline.FunnelStage
+	for _, stage := range stages {
+		if stage.IsLost {
+			continue
+		}
+		index[stage.ID] = len(result)
+		result = append(result, pipeline.FunnelStage{
+			StageID:   stage.ID,
+			StageName: stage.Name,
+			StageCode: stage.Code,
+			Color:     stage.Color,
+		})
+	}
+
+	entered := 0
+	for _, p := range paths {
+		furthest := -1
+		for _, e := range p.entries {
+			if i, ok := index[e.ToStageID]; ok && i > furthest {
+				furthest = i
+			}
+		}
+		if furthest < 0 {
+			continue
+		}
+		entered++
+		for i := 0; i <= furthest; i++ {
+			result[i].Deals++
+			result[i].Value += p.deal.Value
+		}
+	}
+
+	for i := range result {
+		result[i].Percentage = percentage(result[i].Deals, entered)
+	}
+	return result
+}
+
+// conversions returns the share of deals reaching each funnel stage that went on to reach the next
+func conversions(funnel []pipeline.FunnelStage) []pipeline.StageConversion {
+	result := make([]pipeline.StageConversion, 0, len(funnel))
+	for i := 0; i+1 < len(funnel); i++ {
+		from, to := funnel[i], funnel[i+1]
+		result = append(result, pipeline.StageConversion{
+			FromStageID:   from.StageID,
+			FromStageName: from.StageName,
+			ToStageID:     to.StageID,
+			ToStageName:   to.StageName,
+			Reached:       from.Deals,
+			Converted:     to.Deals,
+			Rate:          percentage(to.Deals, from.Deals),
+		})
+	}
+	return result
+}
+
+// velocity computes open deals × average open deal value × win rate ÷ average days from first stage to won
+func velocity(stages []pipeline.PipelineStage, paths []dealPath) pipeline.PipelineVelocity {
+	won := make(map[string]bool)
+	for _, stage := range stages {
+		if stage.IsWon {
+			won[stage.ID] = true
+		}
+	}
+
+	var v pipeline.PipelineVelocity
+	var openValue int64
+	var cycleDays float64
+	cycles := 0
+	for _, p := range paths {
+		switch p.deal.Status {
+		case "open":
+			v.OpenDeals++
+			openValue += p.deal.Value
+		case "won":
+			v.WonDeals++
+			for i := len(p.entries) - 1; i >= 0; i-- {
+				if won[p.entries[i].ToStageID] {
+					cycleDays += p.entries[i].EnteredAt.Sub(p.entries[0].EnteredAt).Hours() / 24
+					cycles++
+					break
+				}
+			}
+		case "lost":
+			v.LostDeals++
+		}
+	}
+
+	if v.OpenDeals > 0 {
+		v.AvgDealValue = openValue / int64(v.OpenDeals)
+	}
+	v.WinRate = percentage(v.WonDeals, v.WonDeals+v.LostDeals)
+	if cycles > 0 {
+		v.AvgCycleDays = round2(cycleDays / float64(cycles))
+		// A cycle shorter than a day counts as a day, so same-day wins do not inflate the velocity
+		cycle := v.AvgCycleDays
+		if cycle < 1 {
+			cycle = 1
+		}
+		v.VelocityPerDay = int64(float64(v.OpenDeals) * float64(v.AvgDealValue) * v.WinRate / 100 / cycle)
+	}
+	return v
+}
+
+// percentage returns part as a percentage of whole, rounded to two decimals
+func percentage(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return round2(float64(part) * 100 / float64(whole))
+}
+
+func round2(f float64) float64 {
+	return float64(int64(f*100+0.5)) / 100
+}
@@ -0,0 +1,190 @@
+package pipeline
+
+import (
+	"errors"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+// SetDealItemRepositories sets the repositories of deal line items and of the products they refer to
+func (s *Service) SetDealItemRepositories(dealItemRepo interfaces.DealItemRepository, productRepo interfaces.ProductRepository) {
+	s.dealItemRepo = dealItemRepo
+	s.productRepo = productRepo
+}
+
+// ListDealItems returns the line items of a deal
+func (s *Service) ListDealItems(dealID string) ([]pipeline.DealItemResponse, error) {
+	if err := s.checkDeal(dealID); err != nil {
+		return nil, err
+	}
+
+	items, err := s.dealItemRepo.ListByDeal(dealID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]pipeline.DealItemResponse, len(items))
+	for i := range items {
+		responses[i] = *items[i].ToDealItemResponse()
+	}
+
+	return responses, nil
+}
+
+// AddDealItem adds a product line to a deal and recalculates the deal value
+func (s *Service) AddDealItem(dealID string, req *pipeline.CreateDealItemRequest) (*pipeline.DealItemResponse, error) {
+	if err := s.checkDeal(dealID); err != nil {
+		return nil, err
+	}
+
+	p, err := s.productRepo.FindByID(req.ProductID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	item := &pipeline.DealItem{
+		DealID:          dealID,
+		ProductID:       p.ID,
+		Quantity:        req.Quantity,
+		UnitPrice:       p.Price,
+		DiscountPercent: req.DiscountPercent,
+		Notes:           req.Notes,
+	}
+	if req.UnitPrice != nil {
+		item.UnitPrice = *req.UnitPrice
+	}
+	if p.Taxable {
+		item.TaxRate = pipeline.DefaultTaxRate
+		if req.TaxRate != nil {
+			item.TaxRate = *req.TaxRate
+		}
+	}
+	item.Calculate()
+
+	if err := s.dealItemRepo.Create(item); err != nil {
+		return nil, err
+	}
+	if err := s.recalculateDealValue(dealID); err != nil {
+		return nil, err
+	}
+
+	return s.getDealItem(item.ID)
+}
+
+// UpdateDealItem updates a line item of a deal and recalculates the deal value
+func (s *Service) UpdateDealItem(dealID, itemID string, req *pipeline.UpdateDealItemRequest) (*pipeline.DealItemResponse, error) {
+	item, err := s.findDealItem(dealID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Quantity != nil {
+		item.Quantity = *req.Quantity
+	}
+	if req.UnitPrice != nil {
+		item.UnitPrice = *req.UnitPrice
+	}
+	if req.DiscountPercent != nil {
+		item.DiscountPercent = *req.DiscountPercent
+	}
+	// Products that are not taxable keep a tax rate of zero
+	if req.TaxRate != nil && item.Product != nil && item.Product.Taxable {
+		item.TaxRate = *req.TaxRate
+	}
+	if req.Notes != nil {
+		item.Notes = *req.Notes
+	}
+	item.Calculate()
+
+	item.Product = nil
+	if err := s.dealItemRepo.Update(item); err != nil {
+		return nil, err
+	}
+	if err := s.recalculateDealValue(dealID); err != nil {
+		return nil, err
+	}
+
+	return s.getDealItem(item.ID)
+}
+
+// DeleteDealItem deletes a line item of a deal and recalculates the deal value
+func (s *Service) DeleteDealItem(dealID, itemID string) error {
+	item, err := s.findDealItem(dealID, itemID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.dealItemRepo.Delete(item.ID); err != nil {
+		return err
+	}
+
+	return s.recalculateDealValue(dealID)
+}
+
+// checkDeal checks that the deal exists within the data scope of the service
+func (s *Service) checkDeal(dealID string) error {
+	if _, err := s.dealRepo.FindByID(dealID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDealNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// findDealItem returns a line item after checking that it belongs to the deal
+func (s *Service) findDealItem(dealID, itemID string) (*pipeline.DealItem, error) {
+	if err := s.checkDeal(dealID); err != nil {
+		return nil, err
+	}
+
+	item, err := s.dealItemRepo.FindByID(itemID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDealItemNotFound
+		}
+		return nil, err
+	}
+	if item.DealID != dealID {
+		return nil, ErrDealItemNotFound
+	}
+
+	return item, nil
+}
+
+func (s *Service) getDealItem(id string) (*pipeline.DealItemResponse, error) {
+	item, err := s.dealItemRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return item.ToDealItemResponse(), nil
+}
+
+// recalculateDealValue sets the value of a deal to the sum of the totals of its line items
+func (s *Service) recalculateDealValue(dealID string) error {
+	items, err := s.dealItemRepo.ListByDeal(dealID)
+	if err != nil {
+		return err
+	}
+
+	var value int64
+	for i := range items {
+		value += items[i].Total
+	}
+
+	return s.dealRepo.UpdateValue(dealID, value)
+}
+
+// hasItems reports whether the deal has line items, whose totals then make up its value
+func (s *Service) hasItems(dealID string) bool {
+	if s.dealItemRepo == nil {
+		return false
+	}
+	items, err := s.dealItemRepo.ListByDeal(dealID)
+	return err == nil && len(items) > 0
+}
@@ -0,0 +1,115 @@
+package pipeline
+
+import (
+	"errors"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLostReasonRequired = errors.New("a loss reason is required to close a deal as lost")
+	ErrCompetitorRequired = errors.New("a competitor is required to close a deal as lost")
+	ErrInvalidLossReason  = errors.New("loss reason not found or inactive")
+	ErrInvalidCompetitor  = errors.New("competitor not found or inactive")
+)
+
+// lossDetails are the loss details given with a create, update or move request
+type lossDetails struct {
+	reasonID     *string
+	competitorID *string
+	notes        string
+}
+
+// SetLossRepositories sets the repositories of the loss reasons and competitors recorded on lost deals
+func (s *Service) SetLossRepositories(lossReasonRepo interfaces.LossReasonRepository, competitorRepo interfaces.CompetitorRepository) {
+	s.lossReasonRepo = lossReasonRepo
+	s.competitorRepo = competitorRepo
+}
+
+// SetLossRequirements sets whether a loss reason and a competitor are required to close a deal as lost
+func (s *Service) SetLossRequirements(requireReason, requireCompetitor bool) {
+	s.requireLostReason = requireReason
+	s.requireLostCompetitor = requireCompetitor
+}
+
+// applyLoss records the loss details on a deal that is lost. When the deal has just been lost, the required
+// details are enforced and fromStageID, if any, is recorded as the stage it was lost from.
+func (s *Service) applyLoss(deal *pipeline.Deal, details lossDetails, justLost bool, fromStageID string) error {
+	if details.reasonID != nil && *details.reasonID != "" {
+		if err := s.checkLossReason(*details.reasonID); err != nil {
+			return err
+		}
+		deal.LostReasonID = details.reasonID
+	}
+	if details.competitorID != nil && *details.competitorID != "" {
+		if err := s.checkCompetitor(*details.competitorID); err != nil {
+			return err
+		}
+		deal.CompetitorID = details.competitorID
+	}
+	if details.notes != "" {
+		deal.LostNotes = details.notes
+	}
+
+	if !justLost {
+		return nil
+	}
+	if s.requireLostReason && deal.LostReasonID == nil {
+		return ErrLostReasonRequired
+	}
+	if s.requireLostCompetitor && deal.CompetitorID == nil {
+		return ErrCompetitorRequired
+	}
+	if fromStageID != "" {
+		deal.LostFromStageID = &fromStageID
+	} else {
+		deal.LostFromStageID = nil
+	}
+	return nil
+}
+
+// clearLoss removes the loss details of a deal that is no longer lost
+func clearLoss(deal *pipeline.Deal) {
+	deal.LostReasonID = nil
+	deal.CompetitorID = nil
+	deal.LostFromStageID = nil
+	deal.LostNotes = ""
+}
+
+// checkLossReason checks that a loss reason exists and is active
+func (s *Service) checkLossReason(id string) error {
+	if s.lossReasonRepo == nil {
+		return nil
+	}
+	reason, err := s.lossReasonRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidLossReason
+		}
+		return err
+	}
+	if reason.Status != "active" {
+		return ErrInvalidLossReason
+	}
+	return nil
+}
+
+// checkCompetitor checks that a competitor exists and is active
+func (s *Service) checkCompetitor(id string) error {
+	if s.competitorRepo == nil {
+		return nil
+	}
+	competitor, err := s.competitorRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidCompetitor
+		}
+		return err
+	}
+	if competitor.Status != "active" {
+		return ErrInvalidCompetitor
+	}
+	return nil
+}
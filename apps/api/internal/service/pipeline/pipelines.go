@@ -0,0 +1,173 @@
+package pipeline
+
+import (
+	"errors"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"gorm.io/gorm"
+)
+
+// resolvePipelineID returns the ID of the given pipeline after checking it exists, or of the default pipeline
+// when id is empty
+func (s *Service) resolvePipelineID(id string) (string, error) {
+	var p *pipeline.Pipeline
+	var err error
+	if id != "" {
+		p, err = s.pipelineRepo.FindPipelineByID(id)
+	} else {
+		p, err = s.pipelineRepo.FindDefaultPipeline()
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrPipelineNotFound
+		}
+		return "", err
+	}
+	return p.ID, nil
+}
+
+// checkPipeline checks that the pipeline of an optional pipeline filter exists
+func (s *Service) checkPipeline(id string) error {
+	if id == "" {
+		return nil
+	}
+	_, err := s.resolvePipelineID(id)
+	return err
+}
+
+// ListPipelines returns a list of pipelines
+func (s *Service) ListPipelines(req *pipeline.ListPipelinesRequest) ([]pipeline.PipelineResponse, error) {
+	pipelines, err := s.pipelineRepo.ListPipelines(req)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]pipeline.PipelineResponse, len(pipelines))
+	for i := range pipelines {
+		responses[i] = *pipelines[i].ToPipelineResponse()
+	}
+
+	return responses, nil
+}
+
+// GetPipelineByID returns a pipeline with its stages
+func (s *Service) GetPipelineByID(id string) (*pipeline.PipelineResponse, error) {
+	p, err := s.pipelineRepo.FindPipelineByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPipelineNotFound
+		}
+		return nil, err
+	}
+
+	return p.ToPipelineResponse(), nil
+}
+
+// CreatePipeline creates a new pipeline. The first pipeline becomes the default one.
+func (s *Service) CreatePipeline(req *pipeline.CreatePipelineRequest) (*pipeline.PipelineResponse, error) {
+	if existing, err := s.pipelineRepo.FindPipelineByCode(req.Code); err == nil && existing != nil {
+		return nil, ErrPipelineCodeExists
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	p := &pipeline.Pipeline{
+		Name:        req.Name,
+		Code:        req.Code,
+		Description: req.Description,
+		Order:       req.Order,
+		IsActive:    isActive,
+	}
+
+	if err := s.pipelineRepo.CreatePipeline(p); err != nil {
+		return nil, err
+	}
+
+	makeDefault := req.IsDefault
+	if !makeDefault {
+		if _, err := s.pipelineRepo.FindDefaultPipeline(); errors.Is(err, gorm.ErrRecordNotFound) {
+			makeDefault = true
+		}
+	}
+	if makeDefault {
+		if err := s.pipelineRepo.SetDefaultPipeline(p.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetPipelineByID(p.ID)
+}
+
+// UpdatePipeline updates a pipeline
+func (s *Service) UpdatePipeline(id string, req *pipeline.UpdatePipelineRequest) (*pipeline.PipelineResponse, error) {
+	p, err := s.pipelineRepo.FindPipelineByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPipelineNotFound
+		}
+		return nil, err
+	}
+
+	// There is always a default pipeline; it changes by making another pipeline the default
+	if p.IsDefault && ((req.IsDefault != nil && !*req.IsDefault) || (req.IsActive != nil && !*req.IsActive)) {
+		return nil, ErrDefaultPipeline
+	}
+
+	if req.Name != "" {
+		p.Name = req.Name
+	}
+	if req.Code != "" {
+		existing, err := s.pipelineRepo.FindPipelineByCode(req.Code)
+		if err == nil && existing != nil && existing.ID != id {
+			return nil, ErrPipelineCodeExists
+		}
+		p.Code = req.Code
+	}
+	if req.Description != "" {
+		p.Description = req.Description
+	}
+	if req.Order != nil {
+		p.Order = *req.Order
+	}
+	if req.IsActive != nil {
+		p.IsActive = *req.IsActive
+	}
+
+	if err := s.pipelineRepo.UpdatePipeline(p); err != nil {
+		return nil, err
+	}
+	if req.IsDefault != nil && *req.IsDefault && !p.IsDefault {
+		if err := s.pipelineRepo.SetDefaultPipeline(p.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetPipelineByID(p.ID)
+}
+
+// DeletePipeline deletes a pipeline and its stages. Pipelines with deals and the default pipeline are kept.
+func (s *Service) DeletePipeline(id string) error {
+	p, err := s.pipelineRepo.FindPipelineByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPipelineNotFound
+		}
+		return err
+	}
+	if p.IsDefault {
+		return ErrDefaultPipeline
+	}
+
+	_, total, err := s.dealRepo.List(&pipeline.ListDealsRequest{PipelineID: p.ID, Page: 1, PerPage: 1})
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		return ErrPipelineInUse
+	}
+
+	return s.pipelineRepo.DeletePipeline(p.ID)
+}
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"time"
 
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"gorm.io/gorm"
 )
 
@@ -14,22 +16,51 @@ var (
 	ErrDealNotFound          = errors.New("deal not found")
 	ErrAccountNotFound       = errors.New("account not found")
 	ErrInvalidStage          = errors.New("invalid pipeline stage")
+	ErrPipelineNotFound      = errors.New("pipeline not found")
+	ErrPipelineCodeExists    = errors.New("pipeline with this code already exists")
+	ErrPipelineInUse         = errors.New("pipeline still has deals")
+	ErrDefaultPipeline       = errors.New("the default pipeline cannot be deleted, deactivated or unset")
+	ErrStageNotInPipeline    = errors.New("stage does not belong to the pipeline")
+	ErrDealItemNotFound      = errors.New("deal item not found")
+	ErrProductNotFound       = errors.New("product not found")
 )
 
 type Service struct {
-	pipelineRepo interfaces.PipelineRepository
-	dealRepo     interfaces.DealRepository
-	accountRepo  interfaces.AccountRepository
+	pipelineRepo     interfaces.PipelineRepository
+	dealRepo         interfaces.DealRepository
+	accountRepo      interfaces.AccountRepository
+	stageHistoryRepo interfaces.DealStageHistoryRepository
+	dealItemRepo     interfaces.DealItemRepository
+	productRepo      interfaces.ProductRepository
+	lossReasonRepo   interfaces.LossReasonRepository
+	competitorRepo   interfaces.CompetitorRepository
+	now              func() time.Time
+
+	requireLostReason     bool
+	requireLostCompetitor bool
 }
 
-func NewService(pipelineRepo interfaces.PipelineRepository, dealRepo interfaces.DealRepository, accountRepo interfaces.AccountRepository) *Service {
+func NewService(pipelineRepo interfaces.PipelineRepository, dealRepo interfaces.DealRepository, accountRepo interfaces.AccountRepository, stageHistoryRepo interfaces.DealStageHistoryRepository) *Service {
 	return &Service{
-		pipelineRepo: pipelineRepo,
-		dealRepo:     dealRepo,
-		accountRepo:  accountRepo,
+		pipelineRepo:     pipelineRepo,
+		dealRepo:         dealRepo,
+		accountRepo:      accountRepo,
+		stageHistoryRepo: stageHistoryRepo,
+		now:              time.Now,
+		// Loss details are required unless SetLossRequirements relaxes them
+		requireLostReason:     true,
+		requireLostCompetitor: true,
 	}
 }
 
+// WithScope returns a copy of the service whose deals and analytics are limited to the given data scope
+func (s *Service) WithScope(scope *datascope.Scope) *Service {
+	scoped := *s
+	scoped.dealRepo = s.dealRepo.WithScope(scope)
+	scoped.stageHistoryRepo = s.stageHistoryRepo.WithScope(scope)
+	return &scoped
+}
+
 // ListStages returns a list of pipeline stages
 func (s *Service) ListStages(req *pipeline.ListPipelineStagesRequest) ([]pipeline.PipelineStageResponse, error) {
 	stages, err := s.pipelineRepo.ListStages(req)
@@ -94,6 +125,18 @@ func (s *Service) ListDeals(req *pipeline.ListDealsRequest) ([]pipeline.DealResp
 	return responses, pagination, nil
 }
 
+// ExportDeals writes every deal matching the list filters to w; rows are flushed to the client after each batch
+func (s *Service) ExportDeals(req *pipeline.ListDealsRequest, w export.Writer) error {
+	return s.dealRepo.FindInBatches(req, func(deals []pipeline.Deal) error {
+		for i := range deals {
+			if err := w.WriteRow(deals[i].ExportRow()); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	})
+}
+
 // GetDealByID returns a deal by ID
 func (s *Service) GetDealByID(id string) (*pipeline.DealResponse, error) {
 	deal, err := s.dealRepo.FindByID(id)
@@ -126,6 +169,9 @@ func (s *Service) CreateDeal(req *pipeline.CreateDealRequest, createdBy string)
 		}
 		return nil, err
 	}
+	if req.PipelineID != "" && req.PipelineID != stage.PipelineID {
+		return nil, ErrStageNotInPipeline
+	}
 
 	// Set default status based on stage
 	status := "open"
@@ -140,6 +186,7 @@ func (s *Service) CreateDeal(req *pipeline.CreateDealRequest, createdBy string)
 		Description:       req.Description,
 		AccountID:         req.AccountID,
 		ContactID:         req.ContactID,
+		PipelineID:        stage.PipelineID,
 		StageID:           req.StageID,
 		Value:             req.Value,
 		Probability:       req.Probability,
@@ -151,8 +198,14 @@ func (s *Service) CreateDeal(req *pipeline.CreateDealRequest, createdBy string)
 		Notes:             req.Notes,
 		CreatedBy:         createdBy,
 	}
+	if status == "lost" {
+		details := lossDetails{reasonID: req.LostReasonID, competitorID: req.CompetitorID, notes: req.LostNotes}
+		if err := s.applyLoss(deal, details, true, ""); err != nil {
+			return nil, err
+		}
+	}
 
-	if err := s.dealRepo.Create(deal); err != nil {
+	if err := s.dealRepo.Create(deal, s.stageChange(nil, deal.StageID, createdBy, pipeline.StageChangeCreate)); err != nil {
 		return nil, err
 	}
 
@@ -165,8 +218,8 @@ func (s *Service) CreateDeal(req *pipeline.CreateDealRequest, createdBy string)
 	return deal.ToDealResponse(), nil
 }
 
-// UpdateDeal updates a deal
-func (s *Service) UpdateDeal(id string, req *pipeline.UpdateDealRequest) (*pipeline.DealResponse, error) {
+// UpdateDeal updates a deal; changedBy is recorded in the stage history when the stage changes
+func (s *Service) UpdateDeal(id string, req *pipeline.UpdateDealRequest, changedBy string) (*pipeline.DealResponse, error) {
 	deal, err := s.dealRepo.FindByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -174,6 +227,8 @@ func (s *Service) UpdateDeal(id string, req *pipeline.UpdateDealRequest) (*pipel
 		}
 		return nil, err
 	}
+	previousStageID := deal.StageID
+	wasLost := deal.Status == "lost"
 
 	// Update fields if provided
 	if req.Title != "" {
@@ -205,6 +260,8 @@ func (s *Service) UpdateDeal(id string, req *pipeline.UpdateDealRequest) (*pipel
 			}
 			return nil, err
 		}
+		// A stage of another pipeline moves the deal to that pipeline
+		deal.PipelineID = stage.PipelineID
 		deal.StageID = req.StageID
 		// Update status based on stage
 		if stage.IsWon {
@@ -219,8 +276,9 @@ func (s *Service) UpdateDeal(id string, req *pipeline.UpdateDealRequest) (*pipel
 			deal.Status = "open"
 		}
 	}
-	// Update value if provided (using pointer to distinguish between not provided and zero value)
-	if req.Value != nil {
+	// Update value if provided (using pointer to distinguish between not provided and zero value);
+	// the value of a deal with line items follows its items
+	if req.Value != nil && !s.hasItems(deal.ID) {
 		deal.Value = *req.Value
 	}
 	if req.Probability != nil {
@@ -244,8 +302,20 @@ func (s *Service) UpdateDeal(id string, req *pipeline.UpdateDealRequest) (*pipel
 	if req.Notes != "" {
 		deal.Notes = req.Notes
 	}
+	if deal.Status == "lost" {
+		details := lossDetails{reasonID: req.LostReasonID, competitorID: req.CompetitorID, notes: req.LostNotes}
+		if err := s.applyLoss(deal, details, !wasLost, previousStageID); err != nil {
+			return nil, err
+		}
+	} else {
+		clearLoss(deal)
+	}
 
-	if err := s.dealRepo.Update(deal); err != nil {
+	var stageChange *pipeline.DealStageHistory
+	if deal.StageID != previousStageID {
+		stageChange = s.stageChange(&previousStageID, deal.StageID, changedBy, pipeline.StageChangeUpdate)
+	}
+	if err := s.dealRepo.Update(deal, stageChange); err != nil {
 		return nil, err
 	}
 
@@ -258,8 +328,8 @@ func (s *Service) UpdateDeal(id string, req *pipeline.UpdateDealRequest) (*pipel
 	return deal.ToDealResponse(), nil
 }
 
-// MoveDeal moves a deal to a different stage
-func (s *Service) MoveDeal(id string, req *pipeline.MoveDealRequest) (*pipeline.DealResponse, error) {
+// MoveDeal moves a deal to a different stage; changedBy is recorded in the stage history
+func (s *Service) MoveDeal(id string, req *pipeline.MoveDealRequest, changedBy string) (*pipeline.DealResponse, error) {
 	deal, err := s.dealRepo.FindByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -277,6 +347,13 @@ func (s *Service) MoveDeal(id string, req *pipeline.MoveDealRequest) (*pipeline.
 		return nil, err
 	}
 
+	// Deals move between the stages of their own pipeline; UpdateDeal moves them to another pipeline
+	if deal.PipelineID != "" && stage.PipelineID != deal.PipelineID {
+		return nil, ErrStageNotInPipeline
+	}
+
+	previousStageID := deal.StageID
+	wasLost := deal.Status == "lost"
 	deal.StageID = req.StageID
 	// Update status based on stage
 	if stage.IsWon {
@@ -290,8 +367,20 @@ func (s *Service) MoveDeal(id string, req *pipeline.MoveDealRequest) (*pipeline.
 	} else {
 		deal.Status = "open"
 	}
+	if deal.Status == "lost" {
+		details := lossDetails{reasonID: req.LostReasonID, competitorID: req.CompetitorID, notes: req.LostNotes}
+		if err := s.applyLoss(deal, details, !wasLost, previousStageID); err != nil {
+			return nil, err
+		}
+	} else {
+		clearLoss(deal)
+	}
 
-	if err := s.dealRepo.Update(deal); err != nil {
+	var stageChange *pipeline.DealStageHistory
+	if deal.StageID != previousStageID {
+		stageChange = s.stageChange(&previousStageID, deal.StageID, changedBy, pipeline.StageChangeMove)
+	}
+	if err := s.dealRepo.Update(deal, stageChange); err != nil {
 		return nil, err
 	}
 
@@ -304,6 +393,44 @@ func (s *Service) MoveDeal(id string, req *pipeline.MoveDealRequest) (*pipeline.
 	return deal.ToDealResponse(), nil
 }
 
+// stageChange builds the stage history entry of a deal entering a stage; the deal repository saves it with the deal
+func (s *Service) stageChange(fromStageID *string, toStageID, changedBy, source string) *pipeline.DealStageHistory {
+	entry := &pipeline.DealStageHistory{
+		FromStageID: fromStageID,
+		ToStageID:   toStageID,
+		EnteredAt:   s.now().UTC(),
+		Source:      source,
+	}
+	if changedBy != "" {
+		entry.ChangedBy = &changedBy
+	}
+	return entry
+}
+
+// GetDealStageHistory returns the stages a deal went through, oldest first
+func (s *Service) GetDealStageHistory(id string) ([]pipeline.DealStageHistoryResponse, error) {
+	deal, err := s.dealRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDealNotFound
+		}
+		return nil, err
+	}
+
+	entries, err := s.stageHistoryRepo.ListByDeal(deal.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	responses := make([]pipeline.DealStageHistoryResponse, len(entries))
+	for i := range entries {
+		responses[i] = *entries[i].ToDealStageHistoryResponse(now)
+	}
+
+	return responses, nil
+}
+
 // DeleteDeal deletes a deal
 func (s *Service) DeleteDeal(id string) error {
 	deal, err := s.dealRepo.FindByID(id)
@@ -317,13 +444,20 @@ func (s *Service) DeleteDeal(id string) error {
 	return s.dealRepo.Delete(deal.ID)
 }
 
-// GetSummary returns pipeline summary
-func (s *Service) GetSummary() (*pipeline.PipelineSummaryResponse, error) {
-	return s.dealRepo.GetSummary()
+// GetSummary returns the summary of one pipeline, or of all pipelines when pipelineID is empty
+func (s *Service) GetSummary(pipelineID string) (*pipeline.PipelineSummaryResponse, error) {
+	if err := s.checkPipeline(pipelineID); err != nil {
+		return nil, err
+	}
+	return s.dealRepo.GetSummary(pipelineID)
 }
 
-// GetForecast returns forecast data
-func (s *Service) GetForecast(periodType string) (*pipeline.ForecastResponse, error) {
+// GetForecast returns the forecast of one pipeline, or of all pipelines when pipelineID is empty
+func (s *Service) GetForecast(periodType string, pipelineID string) (*pipeline.ForecastResponse, error) {
+	if err := s.checkPipeline(pipelineID); err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 	var start, end time.Time
 
@@ -345,13 +479,18 @@ func (s *Service) GetForecast(periodType string) (*pipeline.ForecastResponse, er
 		periodType = "month"
 	}
 
-	return s.dealRepo.GetForecast(periodType, start, end)
+	return s.dealRepo.GetForecast(periodType, start, end, pipelineID)
 }
 
 // CreateStage creates a new pipeline stage
 func (s *Service) CreateStage(req *pipeline.CreateStageRequest) (*pipeline.PipelineStageResponse, error) {
-	// Check if code already exists
-	existing, err := s.pipelineRepo.FindStageByCode(req.Code)
+	pipelineID, err := s.resolvePipelineID(req.PipelineID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if code already exists in the pipeline
+	existing, err := s.pipelineRepo.FindStageByCode(pipelineID, req.Code)
 	if err == nil && existing != nil {
 		return nil, errors.New("pipeline stage with this code already exists")
 	}
@@ -363,6 +502,7 @@ func (s *Service) CreateStage(req *pipeline.CreateStageRequest) (*pipeline.Pipel
 	}
 
 	stage := &pipeline.PipelineStage{
+		PipelineID:  pipelineID,
 		Name:        req.Name,
 		Code:        req.Code,
 		Order:       req.Order,
@@ -401,8 +541,8 @@ func (s *Service) UpdateStage(id string, req *pipeline.UpdateStageRequest) (*pip
 		stage.Name = req.Name
 	}
 	if req.Code != "" {
-		// Check if new code already exists (excluding current stage)
-		existing, err := s.pipelineRepo.FindStageByCode(req.Code)
+		// Check if new code already exists in the pipeline (excluding current stage)
+		existing, err := s.pipelineRepo.FindStageByCode(stage.PipelineID, req.Code)
 		if err == nil && existing != nil && existing.ID != id {
 			return nil, errors.New("pipeline stage with this code already exists")
 		}
@@ -456,10 +596,12 @@ func (s *Service) DeleteStage(id string) error {
 	return s.pipelineRepo.DeleteStage(stage.ID)
 }
 
-// UpdateStagesOrder updates the order of multiple stages
+// UpdateStagesOrder updates the order of multiple stages of one pipeline
 func (s *Service) UpdateStagesOrder(req *pipeline.UpdateStagesOrderRequest) ([]pipeline.PipelineStageResponse, error) {
-	// Update each stage's order
-	for _, item := range req.Stages {
+	// Validate every stage before reordering any of them
+	stages := make([]*pipeline.PipelineStage, len(req.Stages))
+	pipelineID := req.PipelineID
+	for i, item := range req.Stages {
 		stage, err := s.pipelineRepo.FindStageByID(item.ID)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -467,22 +609,32 @@ func (s *Service) UpdateStagesOrder(req *pipeline.UpdateStagesOrderRequest) ([]p
 			}
 			return nil, err
 		}
+		if pipelineID == "" {
+			pipelineID = stage.PipelineID
+		}
+		if stage.PipelineID != pipelineID {
+			return nil, ErrStageNotInPipeline
+		}
+		stages[i] = stage
+	}
 
-		stage.Order = item.Order
-		if err := s.pipelineRepo.UpdateStage(stage); err != nil {
+	// Update each stage's order
+	for i, item := range req.Stages {
+		stages[i].Order = item.Order
+		if err := s.pipelineRepo.UpdateStage(stages[i]); err != nil {
 			return nil, err
 		}
 	}
 
-	// Return updated list of stages
-	listReq := &pipeline.ListPipelineStagesRequest{}
-	stages, err := s.pipelineRepo.ListStages(listReq)
+	// Return updated list of stages of the pipeline
+	listReq := &pipeline.ListPipelineStagesRequest{PipelineID: pipelineID}
+	updated, err := s.pipelineRepo.ListStages(listReq)
 	if err != nil {
 		return nil, err
 	}
 
-	responses := make([]pipeline.PipelineStageResponse, len(stages))
-	for i, stage := range stages {
+	responses := make([]pipeline.PipelineStageResponse, len(updated))
+	for i, stage := range updated {
 		responses[i] = *stage.ToPipelineStageResponse()
 	}
 
@@ -0,0 +1,448 @@
+package pipeline
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/competitor"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/loss_reason"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/product"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+type fakeDealRepo struct {
+	interfaces.DealRepository
+	deal         *pipeline.Deal
+	stageChanges []*pipeline.DealStageHistory
+}
+
+func (r *fakeDealRepo) FindByID(id string) (*pipeline.Deal, error) {
+	copied := *r.deal
+	return &copied, nil
+}
+
+func (r *fakeDealRepo) Update(d *pipeline.Deal, stageChange *pipeline.DealStageHistory) error {
+	r.deal = d
+	if stageChange != nil {
+		stageChange.DealID = d.ID
+		r.stageChanges = append(r.stageChanges, stageChange)
+	}
+	return nil
+}
+
+func (r *fakeDealRepo) UpdateValue(id string, value int64) error {
+	r.deal.Value = value
+	return nil
+}
+
+type fakePipelineRepo struct {
+	interfaces.PipelineRepository
+	stages map[string]*pipeline.PipelineStage
+}
+
+func (r *fakePipelineRepo) FindStageByID(id string) (*pipeline.PipelineStage, error) {
+	stage, ok := r.stages[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *stage
+	return &copied, nil
+}
+
+func (r *fakePipelineRepo) UpdateStage(stage *pipeline.PipelineStage) error {
+	r.stages[stage.ID] = stage
+	return nil
+}
+
+type fakeDealItemRepo struct {
+	interfaces.DealItemRepository
+	items []*pipeline.DealItem
+}
+
+func (r *fakeDealItemRepo) FindByID(id string) (*pipeline.DealItem, error) {
+	for _, item := range r.items {
+		if item.ID == id {
+			copied := *item
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeDealItemRepo) ListByDeal(dealID string) ([]pipeline.DealItem, error) {
+	var items []pipeline.DealItem
+	for _, item := range r.items {
+		if item.DealID == dealID {
+			items = append(items, *item)
+		}
+	}
+	return items, nil
+}
+
+func (r *fakeDealItemRepo) Create(item *pipeline.DealItem) error {
+	item.ID = fmt.Sprintf("item-%d", len(r.items)+1)
+	r.items = append(r.items, item)
+	return nil
+}
+
+func (r *fakeDealItemRepo) Update(item *pipeline.DealItem) error {
+	for i := range r.items {
+		if r.items[i].ID == item.ID {
+			r.items[i] = item
+		}
+	}
+	return nil
+}
+
+func (r *fakeDealItemRepo) Delete(id string) error {
+	for i := range r.items {
+		if r.items[i].ID == id {
+			r.items = append(r.items[:i], r.items[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+type fakeProductRepo struct {
+	interfaces.ProductRepository
+	products map[string]*product.Product
+}
+
+func (r *fakeProductRepo) FindByID(id string) (*product.Product, error) {
+	if p, ok := r.products[id]; ok {
+		return p, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakeStageHistoryRepo struct {
+	interfaces.DealStageHistoryRepository
+}
+
+type fakeLossReasonRepo struct {
+	interfaces.LossReasonRepository
+	reasons map[string]*loss_reason.LossReason
+}
+
+func (r *fakeLossReasonRepo) FindByID(id string) (*loss_reason.LossReason, error) {
+	if reason, ok := r.reasons[id]; ok {
+		return reason, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakeCompetitorRepo struct {
+	interfaces.CompetitorRepository
+	competitors map[string]*competitor.Competitor
+}
+
+func (r *fakeCompetitorRepo) FindByID(id string) (*competitor.Competitor, error) {
+	if c, ok := r.competitors[id]; ok {
+		return c, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestMoveAndUpdateDeal_RecordStageChanges(t *testing.T) {
+	now := time.Date(2025, 3, 11, 2, 0, 0, 0, time.UTC)
+	dealRepo := &fakeDealRepo{deal: &pipeline.Deal{ID: "deal-1", StageID: "lead", Status: "open"}}
+	pipelineRepo := &fakePipelineRepo{stages: map[string]*pipeline.PipelineStage{
+		"lead":     {ID: "lead"},
+		"proposal": {ID: "proposal"},
+		"won":      {ID: "won", IsWon: true},
+	}}
+	service := NewService(pipelineRepo, dealRepo, nil, &fakeStageHistoryRepo{})
+	service.now = func() time.Time { return now }
+
+	if _, err := service.MoveDeal("deal-1", &pipeline.MoveDealRequest{StageID: "proposal"}, "rep-1"); err != nil {
+		t.Fatalf("MoveDeal: %v", err)
+	}
+	if len(dealRepo.stageChanges) != 1 {
+		t.Fatalf("expected 1 stage change, got %d", len(dealRepo.stageChanges))
+	}
+	e := dealRepo.stageChanges[0]
+	if e.DealID != "deal-1" || e.FromStageID == nil || *e.FromStageID != "lead" || e.ToStageID != "proposal" ||
+		e.ChangedBy == nil || *e.ChangedBy != "rep-1" || e.Source != pipeline.StageChangeMove || !e.EnteredAt.Equal(now) {
+		t.Errorf("unexpected stage change %+v", e)
+	}
+
+	// Moving to the current stage and editing other fields are not stage changes
+	if _, err := service.MoveDeal("deal-1", &pipeline.MoveDealRequest{StageID: "proposal"}, "rep-1"); err != nil {
+		t.Fatalf("MoveDeal: %v", err)
+	}
+	if _, err := service.UpdateDeal("deal-1", &pipeline.UpdateDealRequest{Title: "Renewal"}, "rep-1"); err != nil {
+		t.Fatalf("UpdateDeal: %v", err)
+	}
+	if len(dealRepo.stageChanges) != 1 {
+		t.Fatalf("expected no new stage change, got %d", len(dealRepo.stageChanges))
+	}
+
+	if _, err := service.UpdateDeal("deal-1", &pipeline.UpdateDealRequest{StageID: "won"}, "manager-1"); err != nil {
+		t.Fatalf("UpdateDeal: %v", err)
+	}
+	if len(dealRepo.stageChanges) != 2 {
+		t.Fatalf("expected 2 stage changes, got %d", len(dealRepo.stageChanges))
+	}
+	e = dealRepo.stageChanges[1]
+	if *e.FromStageID != "proposal" || e.ToStageID != "won" || *e.ChangedBy != "manager-1" || e.Source != pipeline.StageChangeUpdate {
+		t.Errorf("unexpected stage change %+v", e)
+	}
+}
+
+func TestStagesStayWithinTheirPipeline(t *testing.T) {
+	dealRepo := &fakeDealRepo{deal: &pipeline.Deal{ID: "deal-1", PipelineID: "sales", StageID: "lead", Status: "open"}}
+	pipelineRepo := &fakePipelineRepo{stages: map[string]*pipeline.PipelineStage{
+		"lead":         {ID: "lead", PipelineID: "sales", Order: 1},
+		"proposal":     {ID: "proposal", PipelineID: "sales", Order: 2},
+		"tender-open":  {ID: "tender-open", PipelineID: "tender", Order: 1},
+		"tender-award": {ID: "tender-award", PipelineID: "tender", Order: 2, IsWon: true},
+	}}
+	service := NewService(pipelineRepo, dealRepo, nil, &fakeStageHistoryRepo{})
+
+	if _, err := service.MoveDeal("deal-1", &pipeline.MoveDealRequest{StageID: "tender-open"}, "rep-1"); err != ErrStageNotInPipeline {
+		t.Errorf("expected ErrStageNotInPipeline when moving to another pipeline's stage, got %v", err)
+	}
+	if dealRepo.deal.StageID != "lead" {
+		t.Errorf("expected the deal to stay in lead, got %s", dealRepo.deal.StageID)
+	}
+
+	// Updating the stage moves the deal into the stage's pipeline
+	if _, err := service.UpdateDeal("deal-1", &pipeline.UpdateDealRequest{StageID: "tender-award"}, "manager-1"); err != nil {
+		t.Fatalf("UpdateDeal: %v", err)
+	}
+	if dealRepo.deal.PipelineID != "tender" || dealRepo.deal.Status != "won" {
+		t.Errorf("expected a won deal in the tender pipeline, got %+v", dealRepo.deal)
+	}
+
+	_, err := service.UpdateStagesOrder(&pipeline.UpdateStagesOrderRequest{Stages: []pipeline.StageOrderItem{
+		{ID: "lead", Order: 2},
+		{ID: "tender-open", Order: 1},
+	}})
+	if err != ErrStageNotInPipeline {
+		t.Errorf("expected ErrStageNotInPipeline when reordering stages of two pipelines, got %v", err)
+	}
+	if pipelineRepo.stages["lead"].Order != 1 {
+		t.Error("expected no stage to be reordered")
+	}
+}
+
+func TestComputeAnalytics(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	at := func(d int) *time.Time { tm := day(d); return &tm }
+	stages := []pipeline.PipelineStage{
+		{ID: "lead", Name: "Lead", Order: 1},
+		{ID: "proposal", Name: "Proposal", Order: 2},
+		{ID: "negotiation", Name: "Negotiation", Order: 3},
+		{ID: "won", Name: "Closed Won", Order: 4, IsWon: true},
+		{ID: "lost", Name: "Closed Lost", Order: 5, IsLost: true},
+	}
+	won := &pipeline.Deal{ID: "a", Status: "won", Value: 1000000}
+	lost := &pipeline.Deal{ID: "b", Status: "lost", Value: 500000}
+	open := &pipeline.Deal{ID: "c", Status: "open", Value: 300000}
+	open2 := &pipeline.Deal{ID: "d", Status: "open", Value: 100000}
+	entries := []pipeline.DealStageHistory{
+		// a: lead 2 days, proposal 4 days, skips negotiation, won on day 7
+		{DealID: "a", Deal: won, ToStageID: "lead", EnteredAt: day(1), ExitedAt: at(3)},
+		{DealID: "a", Deal: won, ToStageID: "proposal", EnteredAt: day(3), ExitedAt: at(7)},
+		{DealID: "a", Deal: won, ToStageID: "won", EnteredAt: day(7)},
+		// b: lead 4 days, negotiation 1 day, lost
+		{DealID: "b", Deal: lost, ToStageID: "lead", EnteredAt: day(1), ExitedAt: at(5)},
+		{DealID: "b", Deal: lost, ToStageID: "negotiation", EnteredAt: day(5), ExitedAt: at(6)},
+		{DealID: "b", Deal: lost, ToStageID: "lost", EnteredAt: day(6)},
+		// c: still in lead
+		{DealID: "c", Deal: open, ToStageID: "lead", EnteredAt: day(2)},
+		// d: still in proposal
+		{DealID: "d", Deal: open2, ToStageID: "lead", EnteredAt: day(2), ExitedAt: at(5)},
+		{DealID: "d", Deal: open2, ToStageID: "proposal", EnteredAt: day(5)},
+	}
+
+	a := computeAnalytics(stages, entries, day(10))
+
+	if len(a.TimeInStage) != 3 {
+		t.Fatalf("expected time in the 3 open stages, got %d", len(a.TimeInStage))
+	}
+	lead := a.TimeInStage[0]
+	if lead.CompletedStays != 3 || lead.CurrentDeals != 1 || lead.AvgDays != 3 || lead.MedianDays != 3 {
+		t.Errorf("unexpected lead stats %+v", lead)
+	}
+	if p := a.TimeInStage[1]; p.CompletedStays != 1 || p.CurrentDeals != 1 || p.AvgDays != 4 {
+		t.Errorf("unexpected proposal stats %+v", p)
+	}
+
+	wantFunnel := []int{4, 3, 2, 1} // lead, proposal, negotiation, won; lost is not a funnel stage
+	if len(a.Funnel) != len(wantFunnel) {
+		t.Fatalf("expected %d funnel stages, got %d", len(wantFunnel), len(a.Funnel))
+	}
+	for i, want := range wantFunnel {
+		if a.Funnel[i].Deals != want {
+			t.Errorf("funnel stage %s: expected %d deals, got %d", a.Funnel[i].StageName, want, a.Funnel[i].Deals)
+		}
+	}
+	if a.Funnel[1].Value != 1600000 || a.Funnel[1].Percentage != 75 {
+		t.Errorf("unexpected proposal funnel stage %+v", a.Funnel[1])
+	}
+
+	if len(a.Conversions) != 3 || a.Conversions[0].Rate != 75 || a.Conversions[2].Rate != 50 {
+		t.Errorf("unexpected conversions %+v", a.Conversions)
+	}
+
+	v := a.Velocity
+	if v.OpenDeals != 2 || v.AvgDealValue != 200000 || v.WinRate != 50 || v.AvgCycleDays != 6 {
+		t.Errorf("unexpected velocity inputs %+v", v)
+	}
+	// 2 deals × Rp 2.000 × 50% ÷ 6 days
+	if v.VelocityPerDay != 33333 {
+		t.Errorf("expected velocity of 33333 sen per day, got %d", v.VelocityPerDay)
+	}
+}
+
+func TestDealItems_RecalculateDealValue(t *testing.T) {
+	dealRepo := &fakeDealRepo{deal: &pipeline.Deal{ID: "deal-1", StageID: "lead", Status: "open", Value: 999}}
+	itemRepo := &fakeDealItemRepo{}
+	productRepo := &fakeProductRepo{products: map[string]*product.Product{
+		"infusion-pump": {ID: "infusion-pump", Price: 1500000000, Taxable: true},
+		"training":      {ID: "training", Price: 250000000, Taxable: false},
+	}}
+	service := NewService(&fakePipelineRepo{}, dealRepo, nil, &fakeStageHistoryRepo{})
+	service.SetDealItemRepositories(itemRepo, productRepo)
+
+	// 2 pumps at the catalog price less 10%, with the default tax rate
+	pump, err := service.AddDealItem("deal-1", &pipeline.CreateDealItemRequest{ProductID: "infusion-pump", Quantity: 2, DiscountPercent: 10})
+	if err != nil {
+		t.Fatalf("AddDealItem: %v", err)
+	}
+	if pump.UnitPrice != 1500000000 || pump.Subtotal != 3000000000 || pump.DiscountAmount != 300000000 ||
+		pump.TaxRate != pipeline.DefaultTaxRate || pump.TaxAmount != 297000000 || pump.Total != 2997000000 {
+		t.Errorf("unexpected pump item %+v", pump)
+	}
+
+	// Products that are not taxable ignore the requested tax rate
+	taxRate := 11.0
+	unitPrice := int64(200000000)
+	training, err := service.AddDealItem("deal-1", &pipeline.CreateDealItemRequest{ProductID: "training", Quantity: 1, UnitPrice: &unitPrice, TaxRate: &taxRate})
+	if err != nil {
+		t.Fatalf("AddDealItem: %v", err)
+	}
+	if training.TaxAmount != 0 || training.Total != 200000000 {
+		t.Errorf("expected an untaxed training item of Rp 2.000.000, got %+v", training)
+	}
+	if dealRepo.deal.Value != 3197000000 {
+		t.Errorf("expected the deal value to be the sum of the item totals, got %d", dealRepo.deal.Value)
+	}
+
+	// A manual value does not override the value of a deal with line items
+	value := int64(100)
+	if _, err := service.UpdateDeal("deal-1", &pipeline.UpdateDealRequest{Value: &value}, "rep-1"); err != nil {
+		t.Fatalf("UpdateDeal: %v", err)
+	}
+	if dealRepo.deal.Value != 3197000000 {
+		t.Errorf("expected the deal value to follow its items, got %d", dealRepo.deal.Value)
+	}
+
+	quantity := 1
+	if _, err := service.UpdateDealItem("deal-1", pump.ID, &pipeline.UpdateDealItemRequest{Quantity: &quantity}); err != nil {
+		t.Fatalf("UpdateDealItem: %v", err)
+	}
+	if dealRepo.deal.Value != 1498500000+200000000 {
+		t.Errorf("expected the deal value to follow the new quantity, got %d", dealRepo.deal.Value)
+	}
+
+	if _, err := service.UpdateDealItem("deal-2", pump.ID, &pipeline.UpdateDealItemRequest{Quantity: &quantity}); err == nil {
+		t.Error("expected an error when updating the item through another deal")
+	}
+
+	if err := service.DeleteDealItem("deal-1", pump.ID); err != nil {
+		t.Fatalf("DeleteDealItem: %v", err)
+	}
+	if err := service.DeleteDealItem("deal-1", training.ID); err != nil {
+		t.Fatalf("DeleteDealItem: %v", err)
+	}
+	if dealRepo.deal.Value != 0 {
+		t.Errorf("expected a zero deal value without items, got %d", dealRepo.deal.Value)
+	}
+
+	if _, err := service.AddDealItem("deal-1", &pipeline.CreateDealItemRequest{ProductID: "unknown", Quantity: 1}); err != ErrProductNotFound {
+		t.Errorf("expected ErrProductNotFound, got %v", err)
+	}
+}
+
+func TestMoveDeal_RequiresLossDetails(t *testing.T) {
+	dealRepo := &fakeDealRepo{deal: &pipeline.Deal{ID: "deal-1", StageID: "proposal", Status: "open"}}
+	pipelineRepo := &fakePipelineRepo{stages: map[string]*pipeline.PipelineStage{
+		"proposal": {ID: "proposal"},
+		"lost":     {ID: "lost", IsLost: true},
+	}}
+	service := NewService(pipelineRepo, dealRepo, nil, &fakeStageHistoryRepo{})
+	service.SetLossRepositories(
+		&fakeLossReasonRepo{reasons: map[string]*loss_reason.LossReason{
+			"price":   {ID: "price", Status: "active"},
+			"retired": {ID: "retired", Status: "inactive"},
+		}},
+		&fakeCompetitorRepo{competitors: map[string]*competitor.Competitor{
+			"acme": {ID: "acme", Status: "active"},
+		}},
+	)
+
+	// Loss details are required by default
+	price, retired, acme, unknown := "price", "retired", "acme", "unknown"
+	tests := []struct {
+		name string
+		req  *pipeline.MoveDealRequest
+		want error
+	}{
+		{"no reason", &pipeline.MoveDealRequest{StageID: "lost", CompetitorID: &acme}, ErrLostReasonRequired},
+		{"no competitor", &pipeline.MoveDealRequest{StageID: "lost", LostReasonID: &price}, ErrCompetitorRequired},
+		{"inactive reason", &pipeline.MoveDealRequest{StageID: "lost", LostReasonID: &retired, CompetitorID: &acme}, ErrInvalidLossReason},
+		{"unknown competitor", &pipeline.MoveDealRequest{StageID: "lost", LostReasonID: &price, CompetitorID: &unknown}, ErrInvalidCompetitor},
+	}
+	for _, tt := range tests {
+		if _, err := service.MoveDeal("deal-1", tt.req, "rep-1"); err != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+	if dealRepo.deal.Status != "open" {
+		t.Fatalf("expected the deal to stay open, got %s", dealRepo.deal.Status)
+	}
+
+	req := &pipeline.MoveDealRequest{StageID: "lost", LostReasonID: &price, CompetitorID: &acme, LostNotes: "Acme offered 15% off"}
+	if _, err := service.MoveDeal("deal-1", req, "rep-1"); err != nil {
+		t.Fatalf("MoveDeal: %v", err)
+	}
+	deal := dealRepo.deal
+	if deal.Status != "lost" || *deal.LostReasonID != "price" || *deal.CompetitorID != "acme" || deal.LostNotes != "Acme offered 15% off" {
+		t.Errorf("unexpected loss details %+v", deal)
+	}
+	if deal.LostFromStageID == nil || *deal.LostFromStageID != "proposal" {
+		t.Errorf("expected the deal to be lost from proposal, got %v", deal.LostFromStageID)
+	}
+
+	// Editing a lost deal keeps its loss details without asking for them again
+	if _, err := service.UpdateDeal("deal-1", &pipeline.UpdateDealRequest{Title: "Renewal"}, "rep-1"); err != nil {
+		t.Fatalf("UpdateDeal: %v", err)
+	}
+	if *dealRepo.deal.LostReasonID != "price" || *dealRepo.deal.LostFromStageID != "proposal" {
+		t.Errorf("expected the loss details to be kept, got %+v", dealRepo.deal)
+	}
+
+	// Reopening the deal clears them
+	if _, err := service.MoveDeal("deal-1", &pipeline.MoveDealRequest{StageID: "proposal"}, "rep-1"); err != nil {
+		t.Fatalf("MoveDeal: %v", err)
+	}
+	deal = dealRepo.deal
+	if deal.LostReasonID != nil || deal.CompetitorID != nil || deal.LostFromStageID != nil || deal.LostNotes != "" {
+		t.Errorf("expected the loss details to be cleared, got %+v", deal)
+	}
+
+	// Deployments that opt out can close deals as lost without them
+	service.SetLossRequirements(false, false)
+	if _, err := service.MoveDeal("deal-1", &pipeline.MoveDealRequest{StageID: "lost"}, "rep-1"); err != nil {
+		t.Fatalf("MoveDeal without loss details: %v", err)
+	}
+	if dealRepo.deal.Status != "lost" {
+		t.Errorf("expected a lost deal, got %s", dealRepo.deal.Status)
+	}
+}
@@ -0,0 +1,489 @@
+package quotation
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/quotation"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/internal/service/file"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrQuotationNotFound = errors.New("quotation not found")
+	ErrRevisionNotFound  = errors.New("quotation revision not found")
+	ErrDealNotFound      = errors.New("deal not found")
+	ErrDealWithoutItems  = errors.New("deal has no line items to quote")
+	ErrInvalidStatus     = errors.New("quotation status does not allow this action")
+	ErrInvalidValidUntil = errors.New("valid_until must not be before the issue date")
+	ErrNoWonStage        = errors.New("the pipeline of the deal has no won stage")
+)
+
+// DealMover moves deals between pipeline stages; implemented by the pipeline service
+type DealMover interface {
+	MoveDeal(id string, req *pipeline.MoveDealRequest, changedBy string) (*pipeline.DealResponse, error)
+}
+
+// Renderer renders quotation revisions as PDF; implemented by the report service
+type Renderer interface {
+	RenderQuotation(q *quotation.Quotation, rev *quotation.QuotationRevision) ([]byte, error)
+}
+
+type Service struct {
+	quotationRepo interfaces.QuotationRepository
+	dealRepo      interfaces.DealRepository
+	dealItemRepo  interfaces.DealItemRepository
+	pipelineRepo  interfaces.PipelineRepository
+	dealMover     DealMover
+	renderer      Renderer
+	storage       file.StorageProvider
+	now           func() time.Time
+}
+
+func NewService(
+	quotationRepo interfaces.QuotationRepository,
+	dealRepo interfaces.DealRepository,
+	dealItemRepo interfaces.DealItemRepository,
+	pipelineRepo interfaces.PipelineRepository,
+	dealMover DealMover,
+	renderer Renderer,
+	storage file.StorageProvider,
+) *Service {
+	return &Service{
+		quotationRepo: quotationRepo,
+		dealRepo:      dealRepo,
+		dealItemRepo:  dealItemRepo,
+		pipelineRepo:  pipelineRepo,
+		dealMover:     dealMover,
+		renderer:      renderer,
+		storage:       storage,
+		now:           time.Now,
+	}
+}
+
+// WithScope returns a copy of the service limited to the quotations of deals within the given data scope
+func (s *Service) WithScope(scope *datascope.Scope) *Service {
+	scoped := *s
+	scoped.quotationRepo = s.quotationRepo.WithScope(scope)
+	scoped.dealRepo = s.dealRepo.WithScope(scope)
+	return &scoped
+}
+
+// List returns a list of quotations with pagination
+func (s *Service) List(req *quotation.ListQuotationsRequest) ([]quotation.QuotationResponse, *PaginationResult, error) {
+	quotations, total, err := s.quotationRepo.List(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]quotation.QuotationResponse, len(quotations))
+	for i := range quotations {
+		responses[i] = *quotations[i].ToQuotationResponse()
+	}
+
+	return responses, newPagination(req.Page, req.PerPage, total), nil
+}
+
+// GetByID returns a quotation with its current revision
+func (s *Service) GetByID(id string) (*quotation.QuotationResponse, error) {
+	q, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	return q.ToQuotationResponse(), nil
+}
+
+// ListRevisions returns the revisions of a quotation, newest first
+func (s *Service) ListRevisions(id string) ([]quotation.QuotationRevisionResponse, error) {
+	q, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]quotation.QuotationRevisionResponse, len(q.Revisions))
+	for i := range q.Revisions {
+		responses[i] = *q.Revisions[i].ToQuotationRevisionResponse()
+	}
+	return responses, nil
+}
+
+// RevisionPDF returns the PDF of a quotation revision and the file name to download it as
+func (s *Service) RevisionPDF(id string, revision int) ([]byte, string, error) {
+	q, err := s.find(id)
+	if err != nil {
+		return nil, "", err
+	}
+	rev := q.Revision(revision)
+	if rev == nil {
+		return nil, "", ErrRevisionNotFound
+	}
+
+	var content []byte
+	if rev.FileName == "" {
+		// Rendering failed when the revision was saved
+		content, err = s.renderRevision(q, rev)
+	} else {
+		content, err = s.storage.ReadFile(rev.FileName)
+		if err != nil {
+			err = fmt.Errorf("failed to read quotation: %w", err)
+		}
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return content, quotation.DownloadFileName(q.Number, rev.Revision), nil
+}
+
+// Create generates a numbered draft quotation from the line items of a deal, with its first revision
+func (s *Service) Create(req *quotation.CreateQuotationRequest, createdBy string) (*quotation.QuotationResponse, error) {
+	deal, err := s.findDeal(req.DealID)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentTerms := strings.TrimSpace(req.PaymentTerms)
+	if paymentTerms == "" {
+		paymentTerms = quotation.DefaultPaymentTerms
+	}
+	rev, err := s.newRevision(deal, 1, req.ValidUntil, paymentTerms, strings.TrimSpace(req.Notes), createdBy)
+	if err != nil {
+		return nil, err
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = deal.Title
+	}
+	q := &quotation.Quotation{
+		DealID:          deal.ID,
+		AccountID:       deal.AccountID,
+		Title:           title,
+		Status:          quotation.StatusDraft,
+		CurrentRevision: 1,
+		CreatedBy:       createdBy,
+		Revisions:       []quotation.QuotationRevision{*rev},
+	}
+	if err := s.quotationRepo.Create(q, s.numberPrefix()); err != nil {
+		return nil, err
+	}
+
+	// The PDF needs the quotation number, which is assigned when the quotation is saved
+	s.renderSavedRevision(q, &q.Revisions[0])
+
+	return s.GetByID(q.ID)
+}
+
+// Revise adds a revision with the current line items of the deal to a draft or sent quotation. Earlier
+// revisions are kept unchanged, and the quotation becomes a draft to be sent again.
+func (s *Service) Revise(id string, req *quotation.ReviseQuotationRequest, createdBy string) (*quotation.QuotationResponse, error) {
+	q, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if q.Status != quotation.StatusDraft && q.Status != quotation.StatusSent {
+		return nil, ErrInvalidStatus
+	}
+
+	deal, err := s.findDeal(q.DealID)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentTerms, notes := quotation.DefaultPaymentTerms, ""
+	if previous := q.Revision(q.CurrentRevision); previous != nil {
+		paymentTerms, notes = previous.PaymentTerms, previous.Notes
+	}
+	if req.PaymentTerms != nil {
+		paymentTerms = strings.TrimSpace(*req.PaymentTerms)
+	}
+	if req.Notes != nil {
+		notes = strings.TrimSpace(*req.Notes)
+	}
+
+	// The revision number is assigned when the revision is saved
+	rev, err := s.newRevision(deal, 0, req.ValidUntil, paymentTerms, notes, createdBy)
+	if err != nil {
+		return nil, err
+	}
+
+	q.Status = quotation.StatusDraft
+	q.SentAt = nil
+	added, err := s.quotationRepo.AddRevision(q, rev, quotation.StatusDraft, quotation.StatusSent)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		// Accepted or rejected by another request since it was read
+		return nil, ErrInvalidStatus
+	}
+	s.renderSavedRevision(q, rev)
+
+	return s.GetByID(q.ID)
+}
+
+// Send marks a draft quotation as sent to the customer
+func (s *Service) Send(id string) (*quotation.QuotationResponse, error) {
+	q, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if q.Status != quotation.StatusDraft {
+		return nil, ErrInvalidStatus
+	}
+
+	now := s.now()
+	q.Status = quotation.StatusSent
+	q.SentAt = &now
+	if err := s.updateStatus(q, quotation.StatusDraft); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(q.ID)
+}
+
+// Accept marks a sent quotation as accepted and moves its deal to the won stage of the deal's pipeline
+func (s *Service) Accept(id string, changedBy string) (*quotation.QuotationResponse, error) {
+	q, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if q.Status != quotation.StatusSent {
+		return nil, ErrInvalidStatus
+	}
+
+	deal, err := s.findDeal(q.DealID)
+	if err != nil {
+		return nil, err
+	}
+	wonStageID := ""
+	if deal.Stage == nil || !deal.Stage.IsWon {
+		if wonStageID, err = s.wonStageID(deal.PipelineID); err != nil {
+			return nil, err
+		}
+	}
+
+	// The quotation is accepted first, so a concurrent reject or revision can never leave a won deal behind
+	now := s.now()
+	q.Status = quotation.StatusAccepted
+	q.AcceptedAt = &now
+	if err := s.updateStatus(q, quotation.StatusSent); err != nil {
+		return nil, err
+	}
+	if wonStageID != "" {
+		if _, err := s.dealMover.MoveDeal(deal.ID, &pipeline.MoveDealRequest{StageID: wonStageID}, changedBy); err != nil {
+			q.Status = quotation.StatusSent
+			q.AcceptedAt = nil
+			if _, revertErr := s.quotationRepo.UpdateStatus(q, quotation.StatusAccepted); revertErr != nil {
+				log.Printf("Failed to revert acceptance of quotation %s: %v", q.ID, revertErr)
+			}
+			return nil, err
+		}
+	}
+
+	return s.GetByID(q.ID)
+}
+
+// Reject marks a sent quotation as rejected by the customer
+func (s *Service) Reject(id string, req *quotation.RejectQuotationRequest) (*quotation.QuotationResponse, error) {
+	q, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if q.Status != quotation.StatusSent {
+		return nil, ErrInvalidStatus
+	}
+
+	now := s.now()
+	q.Status = quotation.StatusRejected
+	q.RejectedAt = &now
+	q.RejectionReason = strings.TrimSpace(req.Reason)
+	if err := s.updateStatus(q, quotation.StatusSent); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(q.ID)
+}
+
+// newRevision builds a revision from the current line items of the deal and the account and contact of the deal
+func (s *Service) newRevision(deal *pipeline.Deal, number int, validUntil *string, paymentTerms, notes, createdBy string) (*quotation.QuotationRevision, error) {
+	items, err := s.dealItemRepo.ListByDeal(deal.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrDealWithoutItems
+	}
+
+	today := s.now().In(response.GetTimezoneWIB())
+	issueDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	rev := &quotation.QuotationRevision{
+		Revision:     number,
+		IssueDate:    issueDate,
+		ValidUntil:   issueDate.AddDate(0, 0, quotation.DefaultValidityDays),
+		PaymentTerms: paymentTerms,
+		Notes:        notes,
+		CreatedBy:    createdBy,
+	}
+	if validUntil != nil && *validUntil != "" {
+		parsed, err := time.Parse("2006-01-02", *validUntil)
+		if err != nil || parsed.Before(issueDate) {
+			return nil, ErrInvalidValidUntil
+		}
+		rev.ValidUntil = parsed
+	}
+	if deal.Account != nil {
+		rev.CustomerName = deal.Account.Name
+	}
+	if deal.Contact != nil {
+		rev.ContactName = deal.Contact.Name
+		rev.ContactEmail = deal.Contact.Email
+	}
+
+	for i, item := range items {
+		quoted := quotation.QuotationItem{
+			ProductID:       item.ProductID,
+			Quantity:        item.Quantity,
+			UnitPrice:       item.UnitPrice,
+			DiscountPercent: item.DiscountPercent,
+			DiscountAmount:  item.DiscountAmount,
+			TaxRate:         item.TaxRate,
+			TaxAmount:       item.TaxAmount,
+			Subtotal:        item.Subtotal,
+			Total:           item.Total,
+			Notes:           item.Notes,
+			SortOrder:       i + 1,
+		}
+		if item.Product != nil {
+			quoted.ProductName = item.Product.Name
+			quoted.SKU = item.Product.SKU
+		}
+		rev.Items = append(rev.Items, quoted)
+	}
+	rev.CalculateTotals()
+
+	return rev, nil
+}
+
+// renderSavedRevision renders the PDF of a revision after it is saved. A failure is only logged;
+// the PDF is rendered again when it is first downloaded.
+func (s *Service) renderSavedRevision(q *quotation.Quotation, rev *quotation.QuotationRevision) {
+	if _, err := s.renderRevision(q, rev); err != nil {
+		log.Printf("Failed to render PDF of quotation %s revision %d: %v", q.Number, rev.Revision, err)
+	}
+}
+
+// renderRevision renders the PDF of a saved revision and stores it under a random name. The name is only
+// recorded when the revision has no PDF yet, so a stored PDF is never replaced.
+func (s *Service) renderRevision(q *quotation.Quotation, rev *quotation.QuotationRevision) ([]byte, error) {
+	content, err := s.renderer.RenderQuotation(q, rev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render quotation: %w", err)
+	}
+
+	fileName := quotation.NewFileName()
+	if _, err := s.storage.UploadFile(fileName, content, "application/pdf"); err != nil {
+		return nil, fmt.Errorf("failed to store quotation: %w", err)
+	}
+
+	stored, err := s.quotationRepo.SetRevisionFile(rev.ID, fileName)
+	if err != nil || !stored {
+		// Another request stored the PDF of the revision first; both were rendered from the same revision
+		if deleteErr := s.storage.DeleteFile(fileName); deleteErr != nil {
+			log.Printf("Failed to delete duplicate quotation PDF %s: %v", fileName, deleteErr)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to save quotation file: %w", err)
+		}
+		return content, nil
+	}
+
+	rev.FileName = fileName
+	return content, nil
+}
+
+// wonStageID returns the ID of the won stage of a pipeline
+func (s *Service) wonStageID(pipelineID string) (string, error) {
+	stages, err := s.pipelineRepo.ListStages(&pipeline.ListPipelineStagesRequest{PipelineID: pipelineID})
+	if err != nil {
+		return "", err
+	}
+	for _, stage := range stages {
+		if stage.IsWon {
+			return stage.ID, nil
+		}
+	}
+	return "", ErrNoWonStage
+}
+
+// numberPrefix returns the prefix of the numbers of quotations created this month, e.g. QUO-202610-
+func (s *Service) numberPrefix() string {
+	return fmt.Sprintf("%s-%s-", quotation.NumberPrefix, s.now().In(response.GetTimezoneWIB()).Format("200601"))
+}
+
+// updateStatus saves the status of a quotation read in the from status; ErrInvalidStatus means another request
+// changed the status since it was read
+func (s *Service) updateStatus(q *quotation.Quotation, from string) error {
+	updated, err := s.quotationRepo.UpdateStatus(q, from)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrInvalidStatus
+	}
+	return nil
+}
+
+func (s *Service) find(id string) (*quotation.Quotation, error) {
+	q, err := s.quotationRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrQuotationNotFound
+		}
+		return nil, err
+	}
+	return q, nil
+}
+
+func (s *Service) findDeal(id string) (*pipeline.Deal, error) {
+	deal, err := s.dealRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDealNotFound
+		}
+		return nil, err
+	}
+	return deal, nil
+}
+
+func newPagination(page, perPage int, total int64) *PaginationResult {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+	return &PaginationResult{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      int(total),
+		TotalPages: int((total + int64(perPage) - 1) / int64(perPage)),
+	}
+}
+
+// PaginationResult represents pagination information
+type PaginationResult struct {
+	Page       int
+	PerPage    int
+	Total      int
+	TotalPages int
+}
@@ -0,0 +1,441 @@
+package quotation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/quotation"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/internal/service/file"
+	"gorm.io/gorm"
+)
+
+type fakeQuotationRepo struct {
+	interfaces.QuotationRepository
+	quotations map[string]*quotation.Quotation
+	// beforeWrite runs before a status is written, standing in for a concurrent request
+	beforeWrite func(stored *quotation.Quotation)
+}
+
+func (r *fakeQuotationRepo) FindByID(id string) (*quotation.Quotation, error) {
+	q, ok := r.quotations[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *q
+	copied.Revisions = append([]quotation.QuotationRevision(nil), q.Revisions...)
+	return &copied, nil
+}
+
+func (r *fakeQuotationRepo) Create(q *quotation.Quotation, prefix string) error {
+	q.ID = fmt.Sprintf("quotation-%d", len(r.quotations)+1)
+	q.Number = fmt.Sprintf("%s%04d", prefix, len(r.quotations)+1)
+	for i := range q.Revisions {
+		q.Revisions[i].ID = fmt.Sprintf("%s-r%d", q.ID, q.Revisions[i].Revision)
+		q.Revisions[i].QuotationID = q.ID
+	}
+	copied := *q
+	copied.Revisions = append([]quotation.QuotationRevision(nil), q.Revisions...)
+	r.quotations[q.ID] = &copied
+	return nil
+}
+
+func (r *fakeQuotationRepo) AddRevision(q *quotation.Quotation, rev *quotation.QuotationRevision, from ...string) (bool, error) {
+	stored := r.quotations[q.ID]
+	if !r.hasStatus(stored, from) {
+		return false, nil
+	}
+	rev.Revision = len(stored.Revisions) + 1
+	rev.ID = fmt.Sprintf("%s-r%d", q.ID, rev.Revision)
+	rev.QuotationID = q.ID
+	stored.Revisions = append([]quotation.QuotationRevision{*rev}, stored.Revisions...)
+	stored.CurrentRevision = rev.Revision
+	stored.Status = q.Status
+	stored.SentAt = q.SentAt
+	q.CurrentRevision = rev.Revision
+	return true, nil
+}
+
+func (r *fakeQuotationRepo) SetRevisionFile(revisionID string, fileName string) (bool, error) {
+	for _, q := range r.quotations {
+		for i := range q.Revisions {
+			if q.Revisions[i].ID == revisionID {
+				if q.Revisions[i].FileName != "" {
+					return false, nil
+				}
+				q.Revisions[i].FileName = fileName
+				return true, nil
+			}
+		}
+	}
+	return false, gorm.ErrRecordNotFound
+}
+
+func (r *fakeQuotationRepo) UpdateStatus(q *quotation.Quotation, from ...string) (bool, error) {
+	stored := r.quotations[q.ID]
+	if !r.hasStatus(stored, from) {
+		return false, nil
+	}
+	stored.Status = q.Status
+	stored.SentAt = q.SentAt
+	stored.AcceptedAt = q.AcceptedAt
+	stored.RejectedAt = q.RejectedAt
+	stored.RejectionReason = q.RejectionReason
+	return true, nil
+}
+
+func (r *fakeQuotationRepo) hasStatus(stored *quotation.Quotation, statuses []string) bool {
+	if r.beforeWrite != nil {
+		r.beforeWrite(stored)
+		r.beforeWrite = nil
+	}
+	for _, status := range statuses {
+		if stored.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeDealRepo struct {
+	interfaces.DealRepository
+	deal *pipeline.Deal
+}
+
+func (r *fakeDealRepo) FindByID(id string) (*pipeline.Deal, error) {
+	if r.deal.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *r.deal
+	return &copied, nil
+}
+
+type fakeDealItemRepo struct {
+	interfaces.DealItemRepository
+	items []pipeline.DealItem
+}
+
+func (r *fakeDealItemRepo) ListByDeal(dealID string) ([]pipeline.DealItem, error) {
+	return append([]pipeline.DealItem(nil), r.items...), nil
+}
+
+type fakePipelineRepo struct {
+	interfaces.PipelineRepository
+	stages []pipeline.PipelineStage
+}
+
+func (r *fakePipelineRepo) ListStages(req *pipeline.ListPipelineStagesRequest) ([]pipeline.PipelineStage, error) {
+	var stages []pipeline.PipelineStage
+	for _, stage := range r.stages {
+		if stage.PipelineID == req.PipelineID {
+			stages = append(stages, stage)
+		}
+	}
+	return stages, nil
+}
+
+// fakeDealMover moves the deal of fakeDealRepo like the pipeline service does
+type fakeDealMover struct {
+	dealRepo *fakeDealRepo
+	moves    []string
+	err      error
+}
+
+func (m *fakeDealMover) MoveDeal(id string, req *pipeline.MoveDealRequest, changedBy string) (*pipeline.DealResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.moves = append(m.moves, req.StageID)
+	m.dealRepo.deal.StageID = req.StageID
+	m.dealRepo.deal.Stage = &pipeline.PipelineStage{ID: req.StageID, IsWon: true}
+	m.dealRepo.deal.Status = "won"
+	return m.dealRepo.deal.ToDealResponse(), nil
+}
+
+type fakeRenderer struct{}
+
+func (fakeRenderer) RenderQuotation(q *quotation.Quotation, rev *quotation.QuotationRevision) ([]byte, error) {
+	return []byte(fmt.Sprintf("%%PDF-%s-%d", q.Number, rev.Revision)), nil
+}
+
+type fakeStorage struct {
+	file.StorageProvider
+	files map[string][]byte
+	err   error
+}
+
+func (s *fakeStorage) UploadFile(filename string, data []byte, contentType string) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	s.files[filename] = data
+	return "https://files.example.com/" + filename, nil
+}
+
+func (s *fakeStorage) DeleteFile(filename string) error {
+	delete(s.files, filename)
+	return nil
+}
+
+func (s *fakeStorage) ReadFile(filename string) ([]byte, error) {
+	data, ok := s.files[filename]
+	if !ok {
+		return nil, fmt.Errorf("file %s not found", filename)
+	}
+	return data, nil
+}
+
+func dealItem(productName string, quantity int, unitPrice int64, discount, taxRate float64) pipeline.DealItem {
+	item := pipeline.DealItem{
+		ProductID:       productName,
+		Product:         &pipeline.ProductRef{ID: productName, Name: productName},
+		Quantity:        quantity,
+		UnitPrice:       unitPrice,
+		DiscountPercent: discount,
+		TaxRate:         taxRate,
+	}
+	item.Calculate()
+	return item
+}
+
+func newTestService() (*Service, *fakeQuotationRepo, *fakeDealItemRepo, *fakeDealMover, *fakeStorage) {
+	dealRepo := &fakeDealRepo{deal: &pipeline.Deal{
+		ID:         "deal-1",
+		Title:      "Infusion pumps for RS Harapan Sehat",
+		AccountID:  "account-1",
+		Account:    &pipeline.AccountRef{ID: "account-1", Name: "RS Harapan Sehat"},
+		Contact:    &pipeline.ContactRef{ID: "contact-1", Name: "dr. Siti Rahayu", Email: "siti@example.co.id"},
+		PipelineID: "sales",
+		StageID:    "proposal",
+		Stage:      &pipeline.PipelineStage{ID: "proposal"},
+		Status:     "open",
+	}}
+	quotationRepo := &fakeQuotationRepo{quotations: map[string]*quotation.Quotation{}}
+	itemRepo := &fakeDealItemRepo{items: []pipeline.DealItem{
+		dealItem("Infusion pump", 2, 1500000000, 10, 11),
+		dealItem("Installation", 1, 250000000, 0, 0),
+	}}
+	pipelineRepo := &fakePipelineRepo{stages: []pipeline.PipelineStage{
+		{ID: "proposal", PipelineID: "sales"},
+		{ID: "other-won", PipelineID: "tenders", IsWon: true},
+		{ID: "won", PipelineID: "sales", IsWon: true},
+	}}
+	mover := &fakeDealMover{dealRepo: dealRepo}
+	storage := &fakeStorage{files: map[string][]byte{}}
+
+	service := NewService(quotationRepo, dealRepo, itemRepo, pipelineRepo, mover, fakeRenderer{}, storage)
+	service.now = func() time.Time { return time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC) }
+	return service, quotationRepo, itemRepo, mover, storage
+}
+
+func TestQuotation_CreateReviseAndAccept(t *testing.T) {
+	service, quotationRepo, itemRepo, mover, storage := newTestService()
+
+	q, err := service.Create(&quotation.CreateQuotationRequest{DealID: "deal-1"}, "rep-1")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if q.Number != "QUO-202610-0001" || q.Status != quotation.StatusDraft || q.Title != "Infusion pumps for RS Harapan Sehat" {
+		t.Errorf("unexpected quotation %+v", q)
+	}
+	first := q.Revision
+	if first == nil || first.Revision != 1 || len(first.Items) != 2 {
+		t.Fatalf("expected the first revision with the deal items, got %+v", first)
+	}
+	// 2 pumps of Rp 15.000.000 less 10% plus 11% tax, and an untaxed installation of Rp 2.500.000
+	if first.Subtotal != 3250000000 || first.DiscountTotal != 300000000 || first.TaxTotal != 297000000 || first.Total != 3247000000 {
+		t.Errorf("unexpected totals %+v", first)
+	}
+	if len(first.TaxSummary) != 2 || first.TaxSummary[1].Rate != 11 || first.TaxSummary[1].TaxableAmount != 2700000000 {
+		t.Errorf("unexpected tax summary %+v", first.TaxSummary)
+	}
+	if first.ValidUntil != time.Date(2026, 11, 16, 0, 0, 0, 0, time.UTC) || first.PaymentTerms != quotation.DefaultPaymentTerms {
+		t.Errorf("expected the default validity and payment terms, got %v and %q", first.ValidUntil, first.PaymentTerms)
+	}
+	if first.PDFURL != "/api/v1/quotations/"+q.ID+"/revisions/1/pdf" {
+		t.Errorf("unexpected PDF URL %q", first.PDFURL)
+	}
+	pdf, fileName, err := service.RevisionPDF(q.ID, 1)
+	if err != nil || fileName != "QUO-202610-0001-R1.pdf" || string(pdf) != "%PDF-QUO-202610-0001-1" {
+		t.Errorf("expected the rendered PDF to be stored, got %q, %q, %v", pdf, fileName, err)
+	}
+	// Stored names are random, not derived from the guessable quotation number
+	for name := range storage.files {
+		if strings.Contains(name, "QUO-") {
+			t.Errorf("expected a random storage name, got %q", name)
+		}
+	}
+
+	if _, err := service.Send(q.ID); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	// Revising a sent quotation copies the current deal items into a new revision and makes it a draft again
+	itemRepo.items = itemRepo.items[:1]
+	notes := "Harga termasuk pelatihan perawat"
+	q, err = service.Revise(q.ID, &quotation.ReviseQuotationRequest{Notes: &notes}, "rep-1")
+	if err != nil {
+		t.Fatalf("Revise: %v", err)
+	}
+	if q.CurrentRevision != 2 || q.Status != quotation.StatusDraft || q.SentAt != nil {
+		t.Errorf("expected a draft at revision 2, got %+v", q)
+	}
+	if q.Revision.Total != 2997000000 || q.Revision.Notes != notes {
+		t.Errorf("unexpected second revision %+v", q.Revision)
+	}
+	if pdf, _, err := service.RevisionPDF(q.ID, 2); err != nil || string(pdf) != "%PDF-QUO-202610-0001-2" {
+		t.Errorf("expected the PDF of the second revision, got %q, %v", pdf, err)
+	}
+	if pdf, _, err := service.RevisionPDF(q.ID, 1); err != nil || string(pdf) != "%PDF-QUO-202610-0001-1" {
+		t.Errorf("expected the PDF of the first revision to be kept, got %q, %v", pdf, err)
+	}
+	if _, _, err := service.RevisionPDF(q.ID, 3); err != ErrRevisionNotFound {
+		t.Errorf("expected ErrRevisionNotFound, got %v", err)
+	}
+	revisions, err := service.ListRevisions(q.ID)
+	if err != nil {
+		t.Fatalf("ListRevisions: %v", err)
+	}
+	if len(revisions) != 2 || revisions[1].Revision != 1 || revisions[1].Total != 3247000000 {
+		t.Errorf("expected the first revision to be kept unchanged, got %+v", revisions)
+	}
+
+	// Only sent quotations can be accepted
+	if _, err := service.Accept(q.ID, "manager-1"); err != ErrInvalidStatus {
+		t.Errorf("expected ErrInvalidStatus for a draft, got %v", err)
+	}
+	if _, err := service.Send(q.ID); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	q, err = service.Accept(q.ID, "manager-1")
+	if err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	if q.Status != quotation.StatusAccepted || q.AcceptedAt == nil {
+		t.Errorf("expected an accepted quotation, got %+v", q)
+	}
+	if len(mover.moves) != 1 || mover.moves[0] != "won" {
+		t.Errorf("expected the deal to move to the won stage of its pipeline, got %v", mover.moves)
+	}
+
+	if _, err := service.Revise(q.ID, &quotation.ReviseQuotationRequest{}, "rep-1"); err != ErrInvalidStatus {
+		t.Errorf("expected an accepted quotation to be final, got %v", err)
+	}
+	if len(quotationRepo.quotations[q.ID].Revisions) != 2 {
+		t.Errorf("expected two revisions, got %d", len(quotationRepo.quotations[q.ID].Revisions))
+	}
+}
+
+func TestQuotation_Validation(t *testing.T) {
+	service, _, itemRepo, mover, _ := newTestService()
+
+	past := "2026-10-01"
+	if _, err := service.Create(&quotation.CreateQuotationRequest{DealID: "deal-1", ValidUntil: &past}, "rep-1"); err != ErrInvalidValidUntil {
+		t.Errorf("expected ErrInvalidValidUntil, got %v", err)
+	}
+	if _, err := service.Create(&quotation.CreateQuotationRequest{DealID: "deal-2"}, "rep-1"); err != ErrDealNotFound {
+		t.Errorf("expected ErrDealNotFound, got %v", err)
+	}
+
+	q, err := service.Create(&quotation.CreateQuotationRequest{DealID: "deal-1", PaymentTerms: "50% DP, 50% after delivery"}, "rep-1")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := service.Reject(q.ID, &quotation.RejectQuotationRequest{Reason: "Budget"}); err != ErrInvalidStatus {
+		t.Errorf("expected a draft not to be rejected, got %v", err)
+	}
+	if _, err := service.Send(q.ID); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	q, err = service.Reject(q.ID, &quotation.RejectQuotationRequest{Reason: " Budget cut "})
+	if err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+	if q.Status != quotation.StatusRejected || q.RejectionReason != "Budget cut" || len(mover.moves) != 0 {
+		t.Errorf("expected a rejected quotation without moving the deal, got %+v", q)
+	}
+
+	itemRepo.items = nil
+	if _, err := service.Create(&quotation.CreateQuotationRequest{DealID: "deal-1"}, "rep-1"); err != ErrDealWithoutItems {
+		t.Errorf("expected ErrDealWithoutItems, got %v", err)
+	}
+}
+
+func TestQuotation_ConcurrentStatusChanges(t *testing.T) {
+	service, quotationRepo, _, mover, _ := newTestService()
+
+	q, err := service.Create(&quotation.CreateQuotationRequest{DealID: "deal-1"}, "rep-1")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := service.Send(q.ID); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	// A quotation rejected after Accept read it is neither accepted nor does it win the deal
+	quotationRepo.beforeWrite = func(stored *quotation.Quotation) { stored.Status = quotation.StatusRejected }
+	if _, err := service.Accept(q.ID, "manager-1"); err != ErrInvalidStatus {
+		t.Errorf("expected ErrInvalidStatus when the quotation was rejected first, got %v", err)
+	}
+	if quotationRepo.quotations[q.ID].Status != quotation.StatusRejected || len(mover.moves) != 0 {
+		t.Errorf("expected the rejection to stand and the deal not to move, got %q and %v", quotationRepo.quotations[q.ID].Status, mover.moves)
+	}
+
+	// A quotation accepted after Revise read it gets no new revision and stays accepted
+	quotationRepo.quotations[q.ID].Status = quotation.StatusSent
+	quotationRepo.beforeWrite = func(stored *quotation.Quotation) { stored.Status = quotation.StatusAccepted }
+	if _, err := service.Revise(q.ID, &quotation.ReviseQuotationRequest{}, "rep-1"); err != ErrInvalidStatus {
+		t.Errorf("expected ErrInvalidStatus when the quotation was accepted first, got %v", err)
+	}
+	if stored := quotationRepo.quotations[q.ID]; stored.Status != quotation.StatusAccepted || stored.CurrentRevision != 1 {
+		t.Errorf("expected an accepted quotation at revision 1, got %q at %d", stored.Status, stored.CurrentRevision)
+	}
+
+	// The acceptance is undone when the deal cannot be moved to the won stage
+	quotationRepo.quotations[q.ID].Status = quotation.StatusSent
+	mover.err = errors.New("deal is locked")
+	if _, err := service.Accept(q.ID, "manager-1"); err != mover.err {
+		t.Errorf("expected the move error, got %v", err)
+	}
+	if stored := quotationRepo.quotations[q.ID]; stored.Status != quotation.StatusSent || stored.AcceptedAt != nil {
+		t.Errorf("expected the quotation to be sent again, got %q accepted at %v", stored.Status, stored.AcceptedAt)
+	}
+}
+
+func TestQuotation_PDFRenderedOnDownloadWhenStoringFailed(t *testing.T) {
+	service, quotationRepo, _, _, storage := newTestService()
+
+	// The quotation is saved even when its PDF cannot be stored
+	storage.err = errors.New("storage unavailable")
+	q, err := service.Create(&quotation.CreateQuotationRequest{DealID: "deal-1"}, "rep-1")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(storage.files) != 0 || quotationRepo.quotations[q.ID].Revisions[0].FileName != "" {
+		t.Fatalf("expected no stored PDF, got %v", storage.files)
+	}
+
+	storage.err = nil
+	pdf, fileName, err := service.RevisionPDF(q.ID, 1)
+	if err != nil || fileName != "QUO-202610-0001-R1.pdf" || string(pdf) != "%PDF-QUO-202610-0001-1" {
+		t.Fatalf("expected the PDF to be rendered on download, got %q, %q, %v", pdf, fileName, err)
+	}
+	stored := quotationRepo.quotations[q.ID].Revisions[0].FileName
+	if stored == "" || string(storage.files[stored]) != "%PDF-QUO-202610-0001-1" {
+		t.Errorf("expected the rendered PDF to be stored, got %q", stored)
+	}
+
+	// A revision keeps the PDF stored first
+	rev := quotationRepo.quotations[q.ID].Revisions[0]
+	rev.FileName = ""
+	if _, err := service.renderRevision(&quotation.Quotation{Number: q.Number}, &rev); err != nil {
+		t.Fatalf("renderRevision: %v", err)
+	}
+	if quotationRepo.quotations[q.ID].Revisions[0].FileName != stored || len(storage.files) != 1 {
+		t.Errorf("expected the duplicate PDF to be dropped, got %v", storage.files)
+	}
+}
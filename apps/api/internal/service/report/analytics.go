@@ -0,0 +1,116 @@
+package report
+
+import (
+	"fmt"
+
+	pipelinedomain "github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/xuri/excelize/v2"
+)
+
+// addPipelineAnalyticsSheet adds the Velocity tab of the pipeline report: pipeline velocity, the funnel with
+// its stage-to-stage conversion and the average time deals spend in each stage
+func addPipelineAnalyticsSheet(f *excelize.File, a *pipelinedomain.PipelineAnalyticsResponse, titleStyle, subtitleStyle, headerStyle, dataStyle, numberStyle int) error {
+	sheet := "Velocity"
+	if _, err := f.NewSheet(sheet); err != nil {
+		return err
+	}
+
+	// writeRow writes values from column A, styling numbers as numbers
+	writeRow := func(row int, values ...interface{}) {
+		for i, v := range values {
+			cell := fmt.Sprintf("%c%d", 'A'+i, row)
+			f.SetCellValue(sheet, cell, v)
+			style := dataStyle
+			switch v.(type) {
+			case int, int64, float64:
+				style = numberStyle
+			}
+			f.SetCellStyle(sheet, cell, cell, style)
+		}
+	}
+	writeHeaders := func(row int, headers ...string) {
+		for i, header := range headers {
+			cell := fmt.Sprintf("%c%d", 'A'+i, row)
+			f.SetCellValue(sheet, cell, header)
+			f.SetCellStyle(sheet, cell, cell, headerStyle)
+		}
+	}
+	writeSection := func(row int, title string) {
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), title)
+		f.SetCellStyle(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), subtitleStyle)
+		f.MergeCell(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("F%d", row))
+	}
+
+	row := 1
+
+	// Title
+	f.SetCellValue(sheet, fmt.Sprintf("A%d", row), "Pipeline Velocity")
+	f.SetCellStyle(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), titleStyle)
+	f.MergeCell(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("F%d", row))
+	row++
+
+	// Period
+	f.SetCellValue(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("Deals created %s to %s",
+		a.Period.Start.Format("2006-01-02"),
+		a.Period.End.Format("2006-01-02")))
+	f.SetCellStyle(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), subtitleStyle)
+	f.MergeCell(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("F%d", row))
+	row += 2
+
+	// Velocity
+	writeSection(row, "Velocity")
+	row++
+	writeHeaders(row, "Metric", "Value")
+	row++
+	v := a.Velocity
+	metrics := []struct {
+		label string
+		value interface{}
+	}{
+		{"Open deals", v.OpenDeals},
+		{"Avg open deal value", float64(v.AvgDealValue) / 100.0},
+		{"Won deals", v.WonDeals},
+		{"Lost deals", v.LostDeals},
+		{"Win rate", fmt.Sprintf("%.1f%%", v.WinRate)},
+		{"Avg sales cycle (days)", v.AvgCycleDays},
+		{"Velocity per day", float64(v.VelocityPerDay) / 100.0},
+	}
+	for _, m := range metrics {
+		writeRow(row, m.label, m.value)
+		row++
+	}
+	row += 2
+
+	// Funnel and conversion
+	writeSection(row, "Funnel")
+	row++
+	writeHeaders(row, "Stage", "Deals", "Value", "% of Funnel", "Converted to Next", "Conversion Rate")
+	row++
+	for i, stage := range a.Funnel {
+		converted, rate := "", ""
+		if i < len(a.Conversions) {
+			converted = fmt.Sprintf("%d", a.Conversions[i].Converted)
+			rate = fmt.Sprintf("%.1f%%", a.Conversions[i].Rate)
+		}
+		writeRow(row, stage.StageName, stage.Deals, float64(stage.Value)/100.0, fmt.Sprintf("%.1f%%", stage.Percentage), converted, rate)
+		row++
+	}
+	row += 2
+
+	// Time in stage
+	writeSection(row, "Time in Stage")
+	row++
+	writeHeaders(row, "Stage", "Avg Days", "Median Days", "Completed Stays", "Deals in Stage")
+	row++
+	for _, stat := range a.TimeInStage {
+		writeRow(row, stat.StageName, stat.AvgDays, stat.MedianDays, stat.CompletedStays, stat.CurrentDeals)
+		row++
+	}
+
+	for i := 0; i < 6; i++ {
+		col := string(rune('A' + i))
+		f.SetColWidth(sheet, col, col, 20)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	pipelinedomain "github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/report"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestGeneratePipelineReportExcel_VelocitySheet(t *testing.T) {
+	s := &Service{brandName: DefaultBrandName}
+	data := &report.PipelineReportResponse{ByStage: map[string]int{}}
+	data.Analytics = &pipelinedomain.PipelineAnalyticsResponse{
+		Period: pipelinedomain.ForecastPeriod{
+			Type:  "custom",
+			Start: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2025, 3, 31, 23, 59, 59, 0, time.UTC),
+		},
+		TimeInStage: []pipelinedomain.StageTimeStat{{StageName: "Lead", CompletedStays: 3, AvgDays: 2.5}},
+		Funnel: []pipelinedomain.FunnelStage{
+			{StageName: "Lead", Deals: 4, Value: 160000000, Percentage: 100},
+			{StageName: "Closed Won", Deals: 1, Value: 100000000, Percentage: 25},
+		},
+		Conversions: []pipelinedomain.StageConversion{{FromStageName: "Lead", ToStageName: "Closed Won", Reached: 4, Converted: 1, Rate: 25}},
+		Velocity:    pipelinedomain.PipelineVelocity{OpenDeals: 2, AvgDealValue: 20000000, WinRate: 50, AvgCycleDays: 6, VelocityPerDay: 3333333},
+	}
+
+	xlsx, err := s.generatePipelineReportExcel(data)
+	if err != nil {
+		t.Fatalf("generate Excel: %v", err)
+	}
+	f, err := excelize.OpenReader(bytes.NewReader(xlsx))
+	if err != nil {
+		t.Fatalf("open Excel: %v", err)
+	}
+	defer f.Close()
+
+	rows, err := f.GetRows("Velocity")
+	if err != nil {
+		t.Fatalf("expected a Velocity sheet: %v", err)
+	}
+	found := map[string][]string{}
+	for _, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+		if _, seen := found[row[0]]; !seen {
+			found[row[0]] = row // The funnel comes before time in stage
+		}
+	}
+	if row := found["Velocity per day"]; len(row) < 2 || row[1] != "33333.33" {
+		t.Errorf("expected velocity in rupiah, got %v", row)
+	}
+	if row := found["Lead"]; len(row) < 6 || row[4] != "1" || row[5] != "25.0%" {
+		t.Errorf("expected the Lead funnel row with its conversion, got %v", row)
+	}
+
+	// Without analytics the report keeps its two tabs
+	data.Analytics = nil
+	xlsx, err = s.generatePipelineReportExcel(data)
+	if err != nil {
+		t.Fatalf("generate Excel: %v", err)
+	}
+	f2, err := excelize.OpenReader(bytes.NewReader(xlsx))
+	if err != nil {
+		t.Fatalf("open Excel: %v", err)
+	}
+	defer f2.Close()
+	if idx, _ := f2.GetSheetIndex("Velocity"); idx != -1 {
+		t.Error("expected no Velocity sheet without analytics")
+	}
+}
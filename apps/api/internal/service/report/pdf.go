@@ -0,0 +1,368 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"github.com/go-pdf/fpdf"
+)
+
+// DefaultBrandName is printed in the header of PDF reports unless SetBrandName is called
+const DefaultBrandName = "CRM Healthcare"
+
+// PDF colors; the brand blue matches the header fill of the Excel exports
+var (
+	pdfBrandColor = [3]int{30, 64, 175}
+	pdfMutedColor = [3]int{107, 114, 128}
+	pdfTileColor  = [3]int{239, 246, 255}
+	pdfTotalColor = [3]int{254, 226, 226}
+	pdfTotalText  = [3]int{153, 27, 27}
+	pdfStripe     = [3]int{249, 250, 251}
+)
+
+const (
+	pdfMaxBars  = 15  // bar charts show the largest values only
+	pdfRowH     = 6.0 // table and chart row height in mm
+	pdfTileH    = 16.0
+	pdfTileGap  = 3.0
+	pdfBarValue = 34.0 // width of the value label next to a bar
+)
+
+// pdfColumn is a table column; Weight is its share of the page width
+type pdfColumn struct {
+	Header string
+	Weight float64
+	Right  bool
+}
+
+// pdfMetric is one tile of a summary row
+type pdfMetric struct {
+	Label string
+	Value string
+}
+
+// pdfBar is one bar of a bar chart
+type pdfBar struct {
+	Label string
+	Value float64
+}
+
+// pdfReport renders a report PDF with fpdf's core fonts, so it needs no font files or headless browser.
+// Every page gets a branded header and a footer with the page number.
+type pdfReport struct {
+	pdf   *fpdf.Fpdf
+	tr    func(string) string
+	left  float64
+	width float64
+}
+
+func newPDFReport(brand, title, subtitle string, period [2]time.Time, landscape bool) *pdfReport {
+	periodLine := fmt.Sprintf("Period: %s to %s", formatPDFDate(period[0]), formatPDFDate(period[1]))
+	return newPDFDocument(brand, title, []string{subtitle, periodLine}, landscape)
+}
+
+// newPDFDocument starts a branded PDF with the title and the non-empty subtitle lines on its first page
+func newPDFDocument(brand, title string, subtitles []string, landscape bool) *pdfReport {
+	orientation := "P"
+	if landscape {
+		orientation = "L"
+	}
+	pdf := fpdf.New(orientation, "mm", "A4", "")
+	pdf.SetTitle(title, true)
+	pdf.SetAuthor(brand, true)
+	pdf.SetMargins(12, 24, 12)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("{nb}")
+
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageW, _ := pdf.GetPageSize()
+	r := &pdfReport{pdf: pdf, tr: tr, left: 12, width: pageW - 24}
+
+	generated := time.Now().In(response.GetTimezoneWIB()).Format("02 Jan 2006 15:04 WIB")
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFillColor(pdfBrandColor[0], pdfBrandColor[1], pdfBrandColor[2])
+		pdf.Rect(0, 0, pageW, 16, "F")
+		pdf.SetTextColor(255, 255, 255)
+		pdf.SetFont("Helvetica", "B", 13)
+		pdf.SetXY(r.left, 4)
+		pdf.CellFormat(r.width/2, 8, tr(brand), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(r.width/2, 8, tr(title), "", 0, "R", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+		pdf.SetXY(r.left, 24)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-11)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(pdfMutedColor[0], pdfMutedColor[1], pdfMutedColor[2])
+		pdf.CellFormat(r.width/2, 5, tr("Generated "+generated), "", 0, "L", false, 0, "")
+		pdf.CellFormat(r.width/2, 5, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(r.width, 9, tr(title), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(pdfMutedColor[0], pdfMutedColor[1], pdfMutedColor[2])
+	for _, subtitle := range subtitles {
+		if subtitle != "" {
+			pdf.CellFormat(r.width, 6, tr(subtitle), "", 1, "L", false, 0, "")
+		}
+	}
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(3)
+	return r
+}
+
+// ensureSpace starts a new page when less than height mm is left above the bottom margin
+func (r *pdfReport) ensureSpace(height float64) bool {
+	_, pageH := r.pdf.GetPageSize()
+	_, _, _, bottom := r.pdf.GetMargins()
+	if r.pdf.GetY()+height > pageH-bottom {
+		r.pdf.AddPage()
+		return true
+	}
+	return false
+}
+
+// section writes a section heading, keeping it on the same page as the first lines below it
+func (r *pdfReport) section(title string) {
+	r.ensureSpace(10 + 3*pdfRowH)
+	r.pdf.Ln(2)
+	r.pdf.SetFont("Helvetica", "B", 12)
+	r.pdf.SetTextColor(pdfBrandColor[0], pdfBrandColor[1], pdfBrandColor[2])
+	r.pdf.CellFormat(r.width, 7, r.tr(title), "", 1, "L", false, 0, "")
+	r.pdf.SetDrawColor(pdfBrandColor[0], pdfBrandColor[1], pdfBrandColor[2])
+	r.pdf.Line(r.left, r.pdf.GetY(), r.left+r.width, r.pdf.GetY())
+	r.pdf.SetTextColor(0, 0, 0)
+	r.pdf.Ln(2)
+}
+
+// summary writes the metrics as tiles, at most five per row
+func (r *pdfReport) summary(metrics []pdfMetric) {
+	perRow := len(metrics)
+	if perRow > 5 {
+		perRow = 5
+	}
+	if perRow == 0 {
+		return
+	}
+	tileW := (r.width - float64(perRow-1)*pdfTileGap) / float64(perRow)
+	for i := 0; i < len(metrics); i += perRow {
+		r.ensureSpace(pdfTileH)
+		y := r.pdf.GetY()
+		for j, metric := range metrics[i:min(i+perRow, len(metrics))] {
+			x := r.left + float64(j)*(tileW+pdfTileGap)
+			r.pdf.SetFillColor(pdfTileColor[0], pdfTileColor[1], pdfTileColor[2])
+			r.pdf.Rect(x, y, tileW, pdfTileH, "F")
+			r.pdf.SetXY(x+2, y+2)
+			r.pdf.SetFont("Helvetica", "", 8)
+			r.pdf.SetTextColor(pdfMutedColor[0], pdfMutedColor[1], pdfMutedColor[2])
+			r.pdf.CellFormat(tileW-4, 4, r.fit(metric.Label, tileW-4), "", 0, "L", false, 0, "")
+			r.pdf.SetXY(x+2, y+7)
+			r.pdf.SetFont("Helvetica", "B", 12)
+			r.pdf.SetTextColor(0, 0, 0)
+			r.pdf.CellFormat(tileW-4, 7, r.fit(metric.Value, tileW-4), "", 0, "L", false, 0, "")
+		}
+		r.pdf.SetXY(r.left, y+pdfTileH+pdfTileGap)
+	}
+}
+
+// table writes a table whose header is repeated on every page it spans. A non-nil total row is
+// highlighted and placed right below the header, like the grand total of the Excel export.
+func (r *pdfReport) table(columns []pdfColumn, rows [][]string, total []string) {
+	var weights float64
+	for _, column := range columns {
+		weights += column.Weight
+	}
+	widths := make([]float64, len(columns))
+	for i, column := range columns {
+		widths[i] = r.width * column.Weight / weights
+	}
+	fontSize := 9.0
+	if len(columns) > 8 {
+		fontSize = 7
+	}
+
+	header := func() {
+		r.pdf.SetFont("Helvetica", "B", fontSize)
+		r.pdf.SetFillColor(pdfBrandColor[0], pdfBrandColor[1], pdfBrandColor[2])
+		r.pdf.SetTextColor(255, 255, 255)
+		for i, column := range columns {
+			align := "L"
+			if column.Right {
+				align = "R"
+			}
+			r.pdf.CellFormat(widths[i], pdfRowH+1, r.fit(column.Header, widths[i]), "", 0, align, true, 0, "")
+		}
+		r.pdf.Ln(-1)
+		r.pdf.SetTextColor(0, 0, 0)
+	}
+	line := func(cells []string, style string, color [3]int, fill [3]int, filled bool) {
+		if r.ensureSpace(pdfRowH) {
+			header()
+		}
+		r.pdf.SetFont("Helvetica", style, fontSize)
+		r.pdf.SetTextColor(color[0], color[1], color[2])
+		r.pdf.SetFillColor(fill[0], fill[1], fill[2])
+		for i := range columns {
+			var cell string
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			align := "L"
+			if columns[i].Right {
+				align = "R"
+			}
+			r.pdf.CellFormat(widths[i], pdfRowH, r.fit(cell, widths[i]), "B", 0, align, filled, 0, "")
+		}
+		r.pdf.Ln(-1)
+	}
+
+	r.ensureSpace(pdfRowH*2 + 1)
+	r.pdf.SetDrawColor(229, 231, 235)
+	header()
+	if total != nil {
+		line(total, "B", pdfTotalText, pdfTotalColor, true)
+	}
+	for i, row := range rows {
+		line(row, "", [3]int{0, 0, 0}, pdfStripe, i%2 == 1)
+	}
+	r.pdf.SetTextColor(0, 0, 0)
+	r.pdf.Ln(3)
+}
+
+// barChart writes a horizontal bar chart of the largest values; format renders the value labels
+func (r *pdfReport) barChart(title string, bars []pdfBar, format func(float64) string) {
+	if len(bars) == 0 {
+		return
+	}
+	bars = append([]pdfBar(nil), bars...)
+	sort.SliceStable(bars, func(i, j int) bool { return bars[i].Value > bars[j].Value })
+	if len(bars) > pdfMaxBars {
+		bars = bars[:pdfMaxBars]
+		title = fmt.Sprintf("%s (top %d)", title, pdfMaxBars)
+	}
+	maxValue := bars[0].Value
+	if maxValue <= 0 {
+		return
+	}
+
+	r.section(title)
+	labelW := r.width * 0.28
+	barMaxW := r.width - labelW - pdfBarValue - 2
+	r.pdf.SetFont("Helvetica", "", 8)
+	for _, bar := range bars {
+		r.ensureSpace(pdfRowH)
+		y := r.pdf.GetY()
+		r.pdf.SetXY(r.left, y)
+		r.pdf.CellFormat(labelW, pdfRowH, r.fit(bar.Label, labelW-2), "", 0, "L", false, 0, "")
+
+		barW := barMaxW * math.Max(bar.Value, 0) / maxValue
+		if bar.Value > 0 && barW < 0.6 {
+			barW = 0.6
+		}
+		r.pdf.SetFillColor(pdfBrandColor[0], pdfBrandColor[1], pdfBrandColor[2])
+		r.pdf.Rect(r.left+labelW, y+1, barW, pdfRowH-2, "F")
+		r.pdf.SetXY(r.left+labelW+barW+1, y)
+		r.pdf.CellFormat(pdfBarValue, pdfRowH, r.tr(format(bar.Value)), "", 0, "L", false, 0, "")
+		r.pdf.SetXY(r.left, y+pdfRowH)
+	}
+	r.pdf.Ln(3)
+}
+
+// note writes a line of muted text, e.g. when a section has no data
+func (r *pdfReport) note(text string) {
+	r.ensureSpace(pdfRowH)
+	r.pdf.SetFont("Helvetica", "I", 9)
+	r.pdf.SetTextColor(pdfMutedColor[0], pdfMutedColor[1], pdfMutedColor[2])
+	r.pdf.CellFormat(r.width, pdfRowH, r.tr(text), "", 1, "L", false, 0, "")
+	r.pdf.SetTextColor(0, 0, 0)
+}
+
+// details writes label and value pairs, one per line, wrapping long values. A value without a label
+// takes the full width, e.g. for notes; pairs without a value are left out.
+func (r *pdfReport) details(fields []pdfMetric) {
+	labelW := r.width * 0.25
+	for _, field := range fields {
+		if field.Value == "" {
+			continue
+		}
+		r.ensureSpace(pdfRowH)
+		r.pdf.SetFont("Helvetica", "", 9)
+		valueW := r.width
+		if field.Label != "" {
+			r.pdf.SetTextColor(pdfMutedColor[0], pdfMutedColor[1], pdfMutedColor[2])
+			r.pdf.CellFormat(labelW, pdfRowH-1, r.fit(field.Label, labelW), "", 0, "L", false, 0, "")
+			r.pdf.SetTextColor(0, 0, 0)
+			valueW -= labelW
+		}
+		r.pdf.MultiCell(valueW, pdfRowH-1, r.tr(field.Value), "", "L", false)
+	}
+	r.pdf.Ln(2)
+}
+
+func (r *pdfReport) bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fit translates text to the PDF code page and shortens it with an ellipsis to fit width mm in the current font
+func (r *pdfReport) fit(text string, width float64) string {
+	text = r.tr(strings.Join(strings.Fields(text), " "))
+	width -= 2 // cell padding
+	if r.pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && r.pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
+
+// formatRupiah formats an amount in rupiah with Indonesian thousand separators, e.g. Rp 1.500.000
+func formatRupiah(amount float64) string {
+	return "Rp " + formatThousands(int64(math.Round(amount)))
+}
+
+// formatThousands formats a whole number with dots as thousand separators
+func formatThousands(n int64) string {
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	digits := fmt.Sprintf("%d", n)
+	var parts []string
+	for len(digits) > 3 {
+		parts = append([]string{digits[len(digits)-3:]}, parts...)
+		digits = digits[:len(digits)-3]
+	}
+	parts = append([]string{digits}, parts...)
+	return sign + strings.Join(parts, ".")
+}
+
+func formatCount(value float64) string {
+	return formatThousands(int64(math.Round(value)))
+}
+
+func formatPercent(value float64) string {
+	return fmt.Sprintf("%.1f%%", value)
+}
+
+// formatPDFDate formats a calendar date such as a report period bound as is, like the Excel export does
+func formatPDFDate(t time.Time) string {
+	return t.Format("02 Jan 2006")
+}
+
+func formatPDFDateTime(t time.Time) string {
+	return t.In(response.GetTimezoneWIB()).Format("02 Jan 2006 15:04")
+}
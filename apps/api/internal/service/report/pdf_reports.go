@@ -0,0 +1,383 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/report"
+)
+
+// generateVisitReportPDF generates a PDF for visit report with the sections of the Excel export
+func (s *Service) generateVisitReportPDF(data *report.VisitReportReportResponse) ([]byte, error) {
+	r := newPDFReport(s.brandName, "Visit Report", "", [2]time.Time{data.Period.Start, data.Period.End}, false)
+
+	r.section("Summary")
+	r.summary([]pdfMetric{
+		{Label: "Total", Value: formatThousands(int64(data.Summary.Total))},
+		{Label: "Completed", Value: formatThousands(int64(data.Summary.Completed))},
+		{Label: "Pending", Value: formatThousands(int64(data.Summary.Pending))},
+		{Label: "Approved", Value: formatThousands(int64(data.Summary.Approved))},
+		{Label: "Rejected", Value: formatThousands(int64(data.Summary.Rejected))},
+	})
+
+	bars := make([]pdfBar, 0, len(data.BySalesRep))
+	for _, stat := range data.BySalesRep {
+		bars = append(bars, pdfBar{Label: stat.SalesRep.Name, Value: float64(stat.VisitCount)})
+	}
+	r.barChart("Visits by Sales Rep", bars, formatCount)
+
+	r.section("By Account")
+	if len(data.ByAccount) == 0 {
+		r.note("No visits in this period.")
+	} else {
+		rows := make([][]string, 0, len(data.ByAccount))
+		for _, stat := range data.ByAccount {
+			rows = append(rows, []string{stat.Account.ID, stat.Account.Name, formatThousands(int64(stat.VisitCount))})
+		}
+		r.table([]pdfColumn{
+			{Header: "Account ID", Weight: 3},
+			{Header: "Account Name", Weight: 4},
+			{Header: "Visit Count", Weight: 1.5, Right: true},
+		}, rows, nil)
+	}
+
+	if len(data.BySalesRep) > 0 {
+		r.section("By Sales Rep")
+		rows := make([][]string, 0, len(data.BySalesRep))
+		for _, stat := range data.BySalesRep {
+			rows = append(rows, []string{stat.SalesRep.ID, stat.SalesRep.Name, formatThousands(int64(stat.VisitCount))})
+		}
+		r.table([]pdfColumn{
+			{Header: "Sales Rep ID", Weight: 3},
+			{Header: "Sales Rep Name", Weight: 4},
+			{Header: "Visit Count", Weight: 1.5, Right: true},
+		}, rows, nil)
+	}
+
+	if len(data.ByDate) > 0 {
+		r.section("By Date")
+		rows := make([][]string, 0, len(data.ByDate))
+		for _, stat := range data.ByDate {
+			rows = append(rows, []string{stat.Date, formatThousands(int64(stat.Count))})
+		}
+		r.table([]pdfColumn{
+			{Header: "Date", Weight: 1},
+			{Header: "Visit Count", Weight: 1, Right: true},
+		}, rows, nil)
+	}
+
+	return r.bytes()
+}
+
+// generatePipelineReportPDF generates a PDF for pipeline report: the sales funnel followed by the insights
+func (s *Service) generatePipelineReportPDF(data *report.PipelineReportResponse) ([]byte, error) {
+	r := newPDFReport(s.brandName, "Pipeline Report", "Sales Funnel", [2]time.Time{data.Period.Start, data.Period.End}, true)
+
+	teamStats := pipelineTeamStats(data.Deals)
+	avgDealValue := 0.0
+	teamClosingPercent := 0.0
+	if data.Summary.TotalDeals > 0 {
+		avgDealValue = data.Summary.TotalValue / float64(data.Summary.TotalDeals)
+		teamClosingPercent = (float64(data.Summary.WonDeals) / float64(data.Summary.TotalDeals)) * 100
+	}
+	mostSoldRep := mostSoldTeamMember(teamStats)
+	if mostSoldRep == "" {
+		mostSoldRep = "-"
+	}
+
+	r.section("Summary")
+	r.summary([]pdfMetric{
+		{Label: "Total opportunities", Value: formatThousands(int64(data.Summary.TotalDeals))},
+		{Label: "Total value", Value: formatRupiah(data.Summary.TotalValue)},
+		{Label: "Won", Value: formatRupiah(data.Summary.WonValue)},
+		{Label: "Expected", Value: formatRupiah(data.Summary.ExpectedRevenue)},
+		{Label: "Open", Value: formatRupiah(data.Summary.OpenValue)},
+		{Label: "# won opportunities", Value: formatThousands(int64(data.Summary.WonDeals))},
+		{Label: "# lost opportunities", Value: formatThousands(int64(data.Summary.LostDeals))},
+		{Label: "Avg value", Value: formatRupiah(avgDealValue)},
+		{Label: "Team closing %", Value: fmt.Sprintf("%.0f%%", teamClosingPercent)},
+		{Label: "Most sold rep", Value: mostSoldRep},
+	})
+
+	r.section("Sales Funnel")
+	funnelColumns := []pdfColumn{
+		{Header: "Company Name", Weight: 2.2},
+		{Header: "Contact Name", Weight: 1.6},
+		{Header: "Contact Email", Weight: 2.2},
+		{Header: "Stage", Weight: 1.3},
+		{Header: "Value", Weight: 1.6, Right: true},
+		{Header: "Probability", Weight: 1, Right: true},
+		{Header: "Expected Revenue", Weight: 1.6, Right: true},
+		{Header: "Creation Date", Weight: 1.2},
+		{Header: "Expected Close Date", Weight: 1.3},
+		{Header: "Team Member", Weight: 1.5},
+		{Header: "Progress to Won", Weight: 1, Right: true},
+		{Header: "Last Interacted On", Weight: 1.3},
+		{Header: "Next Step", Weight: 1.8},
+	}
+	funnelRows := make([][]string, 0, len(data.Deals))
+	for _, deal := range data.Deals {
+		expectedClose := ""
+		if deal.ExpectedCloseDate != nil {
+			expectedClose = formatPDFDate(*deal.ExpectedCloseDate)
+		}
+		lastInteracted := ""
+		if deal.LastInteractedOn != nil {
+			lastInteracted = formatPDFDate(*deal.LastInteractedOn)
+		}
+		funnelRows = append(funnelRows, []string{
+			deal.CompanyName,
+			deal.ContactName,
+			deal.ContactEmail,
+			deal.Stage,
+			formatRupiah(deal.Value),
+			fmt.Sprintf("%d%%", deal.Probability),
+			formatRupiah(deal.ExpectedRevenue),
+			formatPDFDate(deal.CreationDate),
+			expectedClose,
+			deal.TeamMember,
+			fmt.Sprintf("%d%%", deal.ProgressToWon),
+			lastInteracted,
+			deal.NextStep,
+		})
+	}
+	grandTotal := []string{"GRAND TOTAL", "", "", "", formatRupiah(data.Summary.TotalValue), "", formatRupiah(data.Summary.ExpectedRevenue)}
+	r.table(funnelColumns, funnelRows, grandTotal)
+
+	// Insights
+	stageBars := make([]pdfBar, 0, len(data.ByStage))
+	for stage, count := range data.ByStage {
+		stageBars = append(stageBars, pdfBar{Label: strings.ReplaceAll(stage, "_", " "), Value: float64(count)})
+	}
+	sort.Slice(stageBars, func(i, j int) bool { return stageBars[i].Label < stageBars[j].Label })
+	r.barChart("Opportunities by Stage", stageBars, formatCount)
+
+	members := make([]string, 0, len(teamStats))
+	for member := range teamStats {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+
+	if len(members) > 0 {
+		wonBars := make([]pdfBar, 0, len(members))
+		for _, member := range members {
+			wonBars = append(wonBars, pdfBar{Label: member, Value: teamStats[member].wonValue})
+		}
+		r.barChart("Won Value by Team Member", wonBars, formatRupiah)
+
+		r.section("Target, Won, Expected by Team Member")
+		rows := make([][]string, 0, len(members))
+		for _, member := range members {
+			stat := teamStats[member]
+			rows = append(rows, []string{member, "-", formatRupiah(stat.wonValue), formatRupiah(stat.expectedValue)})
+		}
+		r.table([]pdfColumn{
+			{Header: "Team Member", Weight: 3},
+			{Header: "Target", Weight: 2, Right: true},
+			{Header: "Won", Weight: 2, Right: true},
+			{Header: "Expected", Weight: 2, Right: true},
+		}, rows, []string{"Team (Total)", "-", formatRupiah(data.Summary.WonValue), formatRupiah(data.Summary.ExpectedRevenue)})
+
+		r.section("Closing % by Team Member")
+		rows = make([][]string, 0, len(members))
+		for _, member := range members {
+			stat := teamStats[member]
+			closingPct := 0.0
+			if stat.totalCount > 0 {
+				closingPct = (float64(stat.wonCount) / float64(stat.totalCount)) * 100
+			}
+			rows = append(rows, []string{member, fmt.Sprintf("%.0f%%", closingPct)})
+		}
+		r.table([]pdfColumn{
+			{Header: "Team Member", Weight: 3},
+			{Header: "Closing %", Weight: 1, Right: true},
+		}, rows, []string{"Team (Total)", fmt.Sprintf("%.0f%%", teamClosingPercent)})
+	}
+
+	accountEarnings := make(map[string]float64)
+	for _, deal := range data.Deals {
+		if deal.CompanyName != "" {
+			accountEarnings[deal.CompanyName] += deal.Value
+		}
+	}
+	if len(accountEarnings) > 0 {
+		accounts := make([]string, 0, len(accountEarnings))
+		for name := range accountEarnings {
+			accounts = append(accounts, name)
+		}
+		sort.SliceStable(accounts, func(i, j int) bool {
+			if accountEarnings[accounts[i]] != accountEarnings[accounts[j]] {
+				return accountEarnings[accounts[i]] > accountEarnings[accounts[j]]
+			}
+			return accounts[i] < accounts[j]
+		})
+
+		r.section("Earnings per Account")
+		rows := make([][]string, 0, len(accounts))
+		for _, name := range accounts {
+			rows = append(rows, []string{name, formatRupiah(accountEarnings[name])})
+		}
+		r.table([]pdfColumn{
+			{Header: "Account", Weight: 3},
+			{Header: "Earnings", Weight: 1, Right: true},
+		}, rows, nil)
+	}
+
+	if len(stageBars) > 0 {
+		r.section("Stage Breakdown")
+		rows := make([][]string, 0, len(stageBars))
+		for _, bar := range stageBars {
+			percentage := 0.0
+			if data.Summary.TotalDeals > 0 {
+				percentage = bar.Value / float64(data.Summary.TotalDeals) * 100
+			}
+			rows = append(rows, []string{bar.Label, formatCount(bar.Value), formatPercent(percentage)})
+		}
+		r.table([]pdfColumn{
+			{Header: "Stage", Weight: 3},
+			{Header: "Deal Count", Weight: 1, Right: true},
+			{Header: "Percentage", Weight: 1, Right: true},
+		}, rows, nil)
+	}
+
+	productRevenuePDF(r, data.ByProduct, data.ByProductCategory)
+
+	return r.bytes()
+}
+
+// generateSalesPerformanceReportPDF generates a PDF for sales performance report
+func (s *Service) generateSalesPerformanceReportPDF(data *report.SalesPerformanceReportResponse) ([]byte, error) {
+	r := newPDFReport(s.brandName, "Sales Performance Report", "", [2]time.Time{data.Period.Start, data.Period.End}, true)
+
+	r.section("Summary")
+	r.summary([]pdfMetric{
+		{Label: "Total Visits", Value: formatThousands(int64(data.Summary.TotalVisits))},
+		{Label: "Total Accounts", Value: formatThousands(int64(data.Summary.TotalAccounts))},
+		{Label: "Average Visits Per Account", Value: fmt.Sprintf("%.2f", data.Summary.AverageVisitsPerAccount)},
+	})
+
+	visitBars := make([]pdfBar, 0, len(data.BySalesRep))
+	revenueBars := make([]pdfBar, 0, len(data.BySalesRep))
+	for _, stat := range data.BySalesRep {
+		visitBars = append(visitBars, pdfBar{Label: stat.SalesRep.Name, Value: float64(stat.VisitCount)})
+		if stat.Attainment != nil {
+			revenueBars = append(revenueBars, pdfBar{Label: stat.SalesRep.Name, Value: float64(stat.Attainment.RevenueAchieved) / 100})
+		}
+	}
+	r.barChart("Visits by Sales Rep", visitBars, formatCount)
+	r.barChart("Revenue Achieved by Sales Rep", revenueBars, formatRupiah)
+
+	r.section("By Sales Rep")
+	if len(data.BySalesRep) == 0 {
+		r.note("No sales activity in this period.")
+		productRevenuePDF(r, data.ByProduct, data.ByProductCategory)
+		return r.bytes()
+	}
+	rows := make([][]string, 0, len(data.BySalesRep))
+	for _, stat := range data.BySalesRep {
+		row := []string{
+			stat.SalesRep.ID,
+			stat.SalesRep.Name,
+			stat.SalesRep.Email,
+			formatThousands(int64(stat.VisitCount)),
+			formatThousands(int64(stat.AccountCount)),
+			formatThousands(int64(stat.ActivityCount)),
+			fmt.Sprintf("%.2f%%", stat.CompletionRate),
+		}
+		if stat.Attainment != nil {
+			// Targets and achieved revenue are kept in sen
+			row = append(row,
+				formatRupiah(float64(stat.Attainment.RevenueTarget)/100),
+				formatRupiah(float64(stat.Attainment.RevenueAchieved)/100),
+				fmt.Sprintf("%.2f%%", stat.Attainment.RevenueProgressPercent),
+				formatThousands(int64(stat.Attainment.VisitTarget)),
+				formatThousands(int64(stat.Attainment.VisitsAchieved)),
+				fmt.Sprintf("%.2f%%", stat.Attainment.VisitProgressPercent),
+			)
+		}
+		rows = append(rows, row)
+	}
+	r.table([]pdfColumn{
+		{Header: "Sales Rep ID", Weight: 2},
+		{Header: "Sales Rep Name", Weight: 2},
+		{Header: "Email", Weight: 2.4},
+		{Header: "Visit Count", Weight: 1, Right: true},
+		{Header: "Account Count", Weight: 1.1, Right: true},
+		{Header: "Activity Count", Weight: 1.1, Right: true},
+		{Header: "Completion Rate (%)", Weight: 1.4, Right: true},
+		{Header: "Revenue Target", Weight: 1.7, Right: true},
+		{Header: "Revenue Achieved", Weight: 1.7, Right: true},
+		{Header: "Revenue Attainment (%)", Weight: 1.6, Right: true},
+		{Header: "Visit Target", Weight: 1, Right: true},
+		{Header: "Visits Achieved", Weight: 1.1, Right: true},
+		{Header: "Visit Attainment (%)", Weight: 1.5, Right: true},
+	}, rows, nil)
+
+	productRevenuePDF(r, data.ByProduct, data.ByProductCategory)
+
+	return r.bytes()
+}
+
+// generateAccountActivityReportPDF generates a PDF for account activity report
+func (s *Service) generateAccountActivityReportPDF(data *report.AccountActivityReportResponse) ([]byte, error) {
+	subtitle := fmt.Sprintf("Account: %s (%s)", data.AccountName, data.AccountID)
+	r := newPDFReport(s.brandName, "Account Activity Report", subtitle, [2]time.Time{data.Period.Start, data.Period.End}, false)
+
+	r.section("Summary")
+	r.summary([]pdfMetric{
+		{Label: "Total Visits", Value: formatThousands(int64(data.Summary.TotalVisits))},
+		{Label: "Total Activities", Value: formatThousands(int64(data.Summary.TotalActivities))},
+		{Label: "Total Contacts", Value: formatThousands(int64(data.Summary.TotalContacts))},
+	})
+
+	byType := make(map[string]int)
+	for _, activity := range data.Activities {
+		byType[activity.Type]++
+	}
+	typeBars := make([]pdfBar, 0, len(byType))
+	for activityType, count := range byType {
+		typeBars = append(typeBars, pdfBar{Label: activityType, Value: float64(count)})
+	}
+	sort.Slice(typeBars, func(i, j int) bool { return typeBars[i].Label < typeBars[j].Label })
+	r.barChart("Activities by Type", typeBars, formatCount)
+
+	r.section("Visits")
+	if len(data.Visits) == 0 {
+		r.note("No visits in this period.")
+	} else {
+		rows := make([][]string, 0, len(data.Visits))
+		for _, visit := range data.Visits {
+			rows = append(rows, []string{visit.ID, formatPDFDateTime(visit.VisitDate), visit.Purpose, visit.Status, visit.SalesRep.ID, visit.SalesRep.Name})
+		}
+		r.table([]pdfColumn{
+			{Header: "Visit ID", Weight: 2},
+			{Header: "Visit Date", Weight: 1.6},
+			{Header: "Purpose", Weight: 2.6},
+			{Header: "Status", Weight: 1.1},
+			{Header: "Sales Rep ID", Weight: 2},
+			{Header: "Sales Rep Name", Weight: 1.8},
+		}, rows, nil)
+	}
+
+	r.section("Activities")
+	if len(data.Activities) == 0 {
+		r.note("No activities in this period.")
+	} else {
+		rows := make([][]string, 0, len(data.Activities))
+		for _, activity := range data.Activities {
+			rows = append(rows, []string{activity.ID, activity.Type, activity.Description, formatPDFDateTime(activity.Timestamp), activity.User.ID, activity.User.Name})
+		}
+		r.table([]pdfColumn{
+			{Header: "Activity ID", Weight: 2},
+			{Header: "Type", Weight: 1.1},
+			{Header: "Description", Weight: 2.6},
+			{Header: "Timestamp", Weight: 1.6},
+			{Header: "User ID", Weight: 2},
+			{Header: "User Name", Weight: 1.8},
+		}, rows, nil)
+	}
+
+	return r.bytes()
+}
@@ -0,0 +1,139 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/quotation"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/report"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/sales_target"
+)
+
+func pdfPageCount(data []byte) int {
+	return bytes.Count(data, []byte("/Type /Page\n"))
+}
+
+func assertPDF(t *testing.T, data []byte, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("generate PDF: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Fatalf("expected a PDF, got %q", data[:min(len(data), 16)])
+	}
+}
+
+func TestFormatRupiah(t *testing.T) {
+	tests := map[float64]string{
+		0:         "Rp 0",
+		950:       "Rp 950",
+		1500000:   "Rp 1.500.000",
+		1234.6:    "Rp 1.235",
+		-25000000: "Rp -25.000.000",
+	}
+	for amount, want := range tests {
+		if got := formatRupiah(amount); got != want {
+			t.Errorf("formatRupiah(%v) = %q, want %q", amount, got, want)
+		}
+	}
+}
+
+func TestGeneratePipelineReportPDF(t *testing.T) {
+	s := &Service{brandName: DefaultBrandName}
+	data := &report.PipelineReportResponse{ByStage: map[string]int{"closed_won": 30, "proposal": 90}}
+	data.Period.Start = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	data.Period.End = time.Date(2025, 3, 31, 23, 59, 59, 0, time.UTC)
+	closeDate := time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 120; i++ {
+		deal := report.DealReportItem{
+			CompanyName:       fmt.Sprintf("RS Harapan Sehat %d", i),
+			ContactName:       "dr. Siti Rahayu, Sp.PD",
+			ContactEmail:      "siti.rahayu@example.co.id",
+			Stage:             "Proposal",
+			StageCode:         "proposal",
+			Value:             15000000,
+			Probability:       40,
+			ExpectedRevenue:   6000000,
+			CreationDate:      data.Period.Start,
+			ExpectedCloseDate: &closeDate,
+			TeamMember:        fmt.Sprintf("Rep %d", i%7),
+			NextStep:          "Kirim penawaran harga ke bagian pengadaan",
+		}
+		if i%4 == 0 {
+			deal.Stage, deal.StageCode = "Closed Won", "won"
+		}
+		data.Deals = append(data.Deals, deal)
+		data.Summary.TotalDeals++
+		data.Summary.TotalValue += deal.Value
+	}
+
+	pdfData, err := s.generatePipelineReportPDF(data)
+	assertPDF(t, pdfData, err)
+	// 120 funnel rows cannot fit on one landscape page; the header and footer are repeated on each page
+	if pages := pdfPageCount(pdfData); pages < 3 {
+		t.Errorf("expected the funnel to span several pages, got %d", pages)
+	}
+}
+
+func TestGenerateReportPDFs_EmptyData(t *testing.T) {
+	s := &Service{brandName: "PT Medika Nusantara"}
+
+	visits := &report.VisitReportReportResponse{}
+	pdfData, err := s.generateVisitReportPDF(visits)
+	assertPDF(t, pdfData, err)
+
+	performance := &report.SalesPerformanceReportResponse{}
+	performance.BySalesRep = []report.SalesPerformanceStat{{VisitCount: 3}}
+	attainment := sales_target.NewAttainment(100000000, 50000000, 10, 3)
+	performance.BySalesRep[0].Attainment = &attainment
+	pdfData, err = s.generateSalesPerformanceReportPDF(performance)
+	assertPDF(t, pdfData, err)
+
+	activity := &report.AccountActivityReportResponse{AccountName: "Apotek Kimia Farma – Cabang Bandung"}
+	pdfData, err = s.generateAccountActivityReportPDF(activity)
+	assertPDF(t, pdfData, err)
+	if pages := pdfPageCount(pdfData); pages != 1 {
+		t.Errorf("expected a single page, got %d", pages)
+	}
+}
+
+func TestMostSoldTeamMember(t *testing.T) {
+	stats := map[string]pipelineTeamStat{
+		"Budi":  {wonValue: 5000000},
+		"Andi":  {wonValue: 5000000},
+		"Citra": {wonValue: 1000000},
+	}
+	if got := mostSoldTeamMember(stats); got != "Andi" {
+		t.Errorf("expected ties to be broken by name, got %q", got)
+	}
+	if got := mostSoldTeamMember(map[string]pipelineTeamStat{"Budi": {}}); got != "" {
+		t.Errorf("expected no most sold rep without won deals, got %q", got)
+	}
+}
+
+func TestRenderQuotation(t *testing.T) {
+	s := &Service{brandName: "PT Medika Nusantara"}
+	q := &quotation.Quotation{Number: "QUO-202610-0001", Title: "Infusion pumps for RS Harapan Sehat", CurrentRevision: 2}
+	rev := &quotation.QuotationRevision{
+		Revision:     2,
+		IssueDate:    time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+		ValidUntil:   time.Date(2026, 11, 16, 0, 0, 0, 0, time.UTC),
+		PaymentTerms: "30 days after invoice",
+		Notes:        "Harga termasuk pelatihan perawat dan garansi 2 tahun",
+		CustomerName: "RS Harapan Sehat",
+		ContactName:  "dr. Siti Rahayu, Sp.PD",
+		Items: []quotation.QuotationItem{
+			{ProductName: "Infusion pump", SKU: "INF-01", Quantity: 2, UnitPrice: 1500000000, DiscountPercent: 10, DiscountAmount: 300000000, TaxRate: 11, TaxAmount: 297000000, Subtotal: 3000000000, Total: 2997000000},
+			{ProductName: "Installation", Quantity: 1, UnitPrice: 250000000, Subtotal: 250000000, Total: 250000000},
+		},
+	}
+	rev.CalculateTotals()
+
+	pdfData, err := s.RenderQuotation(q, rev)
+	assertPDF(t, pdfData, err)
+	if pages := pdfPageCount(pdfData); pages != 1 {
+		t.Errorf("expected a single page, got %d", pages)
+	}
+}
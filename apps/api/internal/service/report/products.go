@@ -0,0 +1,210 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	pipelinedomain "github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/report"
+	"github.com/xuri/excelize/v2"
+)
+
+// productRevenue returns the revenue of the deal line items matching req by product and by product category,
+// converted from sen to rupiah. Both are empty when no deal item repository is set.
+func (s *Service) productRevenue(req *pipelinedomain.ProductRevenueRequest) ([]report.ProductRevenueStat, []report.CategoryRevenueStat, error) {
+	products := make([]report.ProductRevenueStat, 0)
+	if s.dealItemRepo == nil {
+		return products, []report.CategoryRevenueStat{}, nil
+	}
+
+	rows, err := s.dealItemRepo.ProductRevenue(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, row := range rows {
+		products = append(products, report.ProductRevenueStat{
+			ProductID:    row.ProductID,
+			ProductName:  row.ProductName,
+			SKU:          row.SKU,
+			CategoryName: row.CategoryName,
+			Deals:        row.Deals,
+			Quantity:     row.Quantity,
+			Revenue:      float64(row.Revenue) / 100.0,
+			WonRevenue:   float64(row.WonRevenue) / 100.0,
+		})
+	}
+
+	return products, categoryRevenue(rows), nil
+}
+
+// categoryRevenue sums product revenue rows by product category, highest revenue first
+func categoryRevenue(rows []pipelinedomain.ProductRevenue) []report.CategoryRevenueStat {
+	byCategory := make(map[string]*report.CategoryRevenueStat)
+	revenue := make(map[string]int64)
+	wonRevenue := make(map[string]int64)
+	for _, row := range rows {
+		stat, ok := byCategory[row.CategoryID]
+		if !ok {
+			stat = &report.CategoryRevenueStat{CategoryID: row.CategoryID, CategoryName: row.CategoryName}
+			byCategory[row.CategoryID] = stat
+		}
+		stat.Products++
+		stat.Quantity += row.Quantity
+		// Sum in sen to avoid rounding errors
+		revenue[row.CategoryID] += row.Revenue
+		wonRevenue[row.CategoryID] += row.WonRevenue
+	}
+
+	categories := make([]report.CategoryRevenueStat, 0, len(byCategory))
+	for id, stat := range byCategory {
+		stat.Revenue = float64(revenue[id]) / 100.0
+		stat.WonRevenue = float64(wonRevenue[id]) / 100.0
+		categories = append(categories, *stat)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Revenue != categories[j].Revenue {
+			return categories[i].Revenue > categories[j].Revenue
+		}
+		return categories[i].CategoryName < categories[j].CategoryName
+	})
+
+	return categories
+}
+
+// writeProductRevenueCSV appends the By Product and By Product Category sections to a CSV report
+func writeProductRevenueCSV(csv *strings.Builder, products []report.ProductRevenueStat, categories []report.CategoryRevenueStat) {
+	csv.WriteString("\nBy Product\n")
+	csv.WriteString("Product ID,Product Name,SKU,Category,Deals,Quantity,Revenue,Won Revenue\n")
+	for _, p := range products {
+		csv.WriteString(fmt.Sprintf("%s,\"%s\",\"%s\",\"%s\",%d,%d,%.2f,%.2f\n",
+			p.ProductID,
+			p.ProductName,
+			p.SKU,
+			p.CategoryName,
+			p.Deals,
+			p.Quantity,
+			p.Revenue,
+			p.WonRevenue,
+		))
+	}
+
+	csv.WriteString("\nBy Product Category\n")
+	csv.WriteString("Category,Products,Quantity,Revenue,Won Revenue\n")
+	for _, c := range categories {
+		csv.WriteString(fmt.Sprintf("\"%s\",%d,%d,%.2f,%.2f\n",
+			c.CategoryName,
+			c.Products,
+			c.Quantity,
+			c.Revenue,
+			c.WonRevenue,
+		))
+	}
+}
+
+// addProductRevenueSheet adds the Products tab of a report: revenue by product and by product category
+func addProductRevenueSheet(f *excelize.File, subtitle string, products []report.ProductRevenueStat, categories []report.CategoryRevenueStat, titleStyle, subtitleStyle, headerStyle, dataStyle, numberStyle int) error {
+	sheet := "Products"
+	if _, err := f.NewSheet(sheet); err != nil {
+		return err
+	}
+
+	// writeRow writes values from column A, styling numbers as numbers
+	writeRow := func(row int, values ...interface{}) {
+		for i, v := range values {
+			cell := fmt.Sprintf("%c%d", 'A'+i, row)
+			f.SetCellValue(sheet, cell, v)
+			style := dataStyle
+			switch v.(type) {
+			case int, int64, float64:
+				style = numberStyle
+			}
+			f.SetCellStyle(sheet, cell, cell, style)
+		}
+	}
+	writeHeaders := func(row int, headers ...string) {
+		for i, header := range headers {
+			cell := fmt.Sprintf("%c%d", 'A'+i, row)
+			f.SetCellValue(sheet, cell, header)
+			f.SetCellStyle(sheet, cell, cell, headerStyle)
+		}
+	}
+	writeSection := func(row int, title string, style int) {
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), title)
+		f.SetCellStyle(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), style)
+		f.MergeCell(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("G%d", row))
+	}
+
+	row := 1
+	writeSection(row, "Revenue by Product", titleStyle)
+	row++
+	writeSection(row, subtitle, subtitleStyle)
+	row += 2
+
+	writeSection(row, "By Product", subtitleStyle)
+	row++
+	writeHeaders(row, "Product", "SKU", "Category", "Deals", "Quantity", "Revenue", "Won Revenue")
+	row++
+	for _, p := range products {
+		writeRow(row, p.ProductName, p.SKU, p.CategoryName, p.Deals, p.Quantity, p.Revenue, p.WonRevenue)
+		row++
+	}
+	row += 2
+
+	writeSection(row, "By Product Category", subtitleStyle)
+	row++
+	writeHeaders(row, "Category", "Products", "Quantity", "Revenue", "Won Revenue")
+	row++
+	for _, c := range categories {
+		writeRow(row, c.CategoryName, c.Products, c.Quantity, c.Revenue, c.WonRevenue)
+		row++
+	}
+
+	f.SetColWidth(sheet, "A", "A", 30)
+	f.SetColWidth(sheet, "B", "G", 18)
+
+	return nil
+}
+
+// productRevenuePDF adds the revenue by product and by product category sections to a PDF report
+func productRevenuePDF(r *pdfReport, products []report.ProductRevenueStat, categories []report.CategoryRevenueStat) {
+	r.section("Revenue by Product")
+	if len(products) == 0 {
+		r.note("No deal line items in this report.")
+		return
+	}
+
+	bars := make([]pdfBar, 0, len(categories))
+	for _, c := range categories {
+		bars = append(bars, pdfBar{Label: c.CategoryName, Value: c.Revenue})
+	}
+	r.barChart("Revenue by Product Category", bars, formatRupiah)
+
+	var quantity int64
+	var revenue, wonRevenue float64
+	rows := make([][]string, 0, len(products))
+	for _, p := range products {
+		quantity += p.Quantity
+		revenue += p.Revenue
+		wonRevenue += p.WonRevenue
+		rows = append(rows, []string{
+			p.ProductName,
+			p.SKU,
+			p.CategoryName,
+			formatThousands(int64(p.Deals)),
+			formatThousands(p.Quantity),
+			formatRupiah(p.Revenue),
+			formatRupiah(p.WonRevenue),
+		})
+	}
+	r.table([]pdfColumn{
+		{Header: "Product", Weight: 3},
+		{Header: "SKU", Weight: 1.5},
+		{Header: "Category", Weight: 2},
+		{Header: "Deals", Weight: 1, Right: true},
+		{Header: "Quantity", Weight: 1, Right: true},
+		{Header: "Revenue", Weight: 2, Right: true},
+		{Header: "Won Revenue", Weight: 2, Right: true},
+	}, rows, []string{"TOTAL", "", "", "", formatThousands(quantity), formatRupiah(revenue), formatRupiah(wonRevenue)})
+}
@@ -0,0 +1,60 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	pipelinedomain "github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+)
+
+type fakeDealItemRepo struct {
+	interfaces.DealItemRepository
+	rows []pipelinedomain.ProductRevenue
+	req  *pipelinedomain.ProductRevenueRequest
+}
+
+func (r *fakeDealItemRepo) ProductRevenue(req *pipelinedomain.ProductRevenueRequest) ([]pipelinedomain.ProductRevenue, error) {
+	r.req = req
+	return r.rows, nil
+}
+
+func TestProductRevenue_ByProductAndCategory(t *testing.T) {
+	repo := &fakeDealItemRepo{rows: []pipelinedomain.ProductRevenue{
+		{ProductID: "pump", ProductName: "Infusion Pump", CategoryID: "devices", CategoryName: "Medical Device", Deals: 3, Quantity: 5, Revenue: 750000001, WonRevenue: 300000000},
+		{ProductID: "para", ProductName: "Paracetamol", CategoryID: "drugs", CategoryName: "Drug", Deals: 2, Quantity: 400, Revenue: 80000000, WonRevenue: 80000000},
+		{ProductID: "monitor", ProductName: "Patient Monitor", CategoryID: "devices", CategoryName: "Medical Device", Deals: 1, Quantity: 1, Revenue: 50000002},
+	}}
+	s := &Service{dealItemRepo: repo}
+
+	products, categories, err := s.productRevenue(&pipelinedomain.ProductRevenueRequest{Status: "won"})
+	if err != nil {
+		t.Fatalf("productRevenue: %v", err)
+	}
+	if repo.req.Status != "won" {
+		t.Errorf("expected the request to reach the repository, got %+v", repo.req)
+	}
+	if len(products) != 3 || products[0].Revenue != 7500000.01 {
+		t.Errorf("expected products in rupiah, got %+v", products)
+	}
+	if len(categories) != 2 {
+		t.Fatalf("expected 2 categories, got %d", len(categories))
+	}
+	devices := categories[0]
+	if devices.CategoryName != "Medical Device" || devices.Products != 2 || devices.Quantity != 6 ||
+		devices.Revenue != 8000000.03 || devices.WonRevenue != 3000000 {
+		t.Errorf("unexpected device category %+v", devices)
+	}
+
+	var csv strings.Builder
+	writeProductRevenueCSV(&csv, products, categories)
+	if !strings.Contains(csv.String(), "\"Drug\",1,400,800000.00,800000.00\n") {
+		t.Errorf("expected the drug category in the CSV, got:\n%s", csv.String())
+	}
+
+	// Without line items the report still has empty sections
+	products, categories, err = (&Service{}).productRevenue(&pipelinedomain.ProductRevenueRequest{})
+	if err != nil || products == nil || categories == nil {
+		t.Errorf("expected empty sections, got %v %v %v", products, categories, err)
+	}
+}
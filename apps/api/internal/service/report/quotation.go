@@ -0,0 +1,93 @@
+package report
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/quotation"
+)
+
+// RenderQuotation renders a revision of a quotation as a branded PDF
+func (s *Service) RenderQuotation(q *quotation.Quotation, rev *quotation.QuotationRevision) ([]byte, error) {
+	subtitle := fmt.Sprintf("%s, revision %d", q.Number, rev.Revision)
+	r := newPDFDocument(s.brandName, "Quotation", []string{subtitle, q.Title}, false)
+
+	r.section("Customer")
+	r.details([]pdfMetric{
+		{Label: "Customer", Value: rev.CustomerName},
+		{Label: "Attention", Value: rev.ContactName},
+		{Label: "Email", Value: rev.ContactEmail},
+	})
+
+	r.section("Terms")
+	r.details([]pdfMetric{
+		{Label: "Quotation Number", Value: q.Number},
+		{Label: "Issue Date", Value: formatPDFDate(rev.IssueDate)},
+		{Label: "Valid Until", Value: formatPDFDate(rev.ValidUntil)},
+		{Label: "Payment Terms", Value: rev.PaymentTerms},
+	})
+
+	r.section("Items")
+	rows := make([][]string, 0, len(rev.Items))
+	for i, item := range rev.Items {
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1),
+			item.ProductName,
+			item.SKU,
+			formatThousands(int64(item.Quantity)),
+			formatRupiah(senToRupiah(item.UnitPrice)),
+			formatRate(item.DiscountPercent),
+			formatRate(item.TaxRate),
+			formatRupiah(senToRupiah(item.Total)),
+		})
+	}
+	r.table([]pdfColumn{
+		{Header: "No", Weight: 0.6},
+		{Header: "Product", Weight: 4},
+		{Header: "SKU", Weight: 1.6},
+		{Header: "Qty", Weight: 0.9, Right: true},
+		{Header: "Unit Price", Weight: 2.2, Right: true},
+		{Header: "Disc", Weight: 1, Right: true},
+		{Header: "Tax", Weight: 1, Right: true},
+		{Header: "Total", Weight: 2.4, Right: true},
+	}, rows, nil)
+
+	r.summary([]pdfMetric{
+		{Label: "Subtotal", Value: formatRupiah(senToRupiah(rev.Subtotal))},
+		{Label: "Discount", Value: formatRupiah(senToRupiah(rev.DiscountTotal))},
+		{Label: "Tax", Value: formatRupiah(senToRupiah(rev.TaxTotal))},
+		{Label: "Total", Value: formatRupiah(senToRupiah(rev.Total))},
+	})
+
+	r.section("Tax Summary")
+	taxRows := make([][]string, 0)
+	for _, line := range rev.TaxSummary() {
+		taxRows = append(taxRows, []string{
+			formatRate(line.Rate),
+			formatRupiah(senToRupiah(line.TaxableAmount)),
+			formatRupiah(senToRupiah(line.Tax)),
+		})
+	}
+	r.table([]pdfColumn{
+		{Header: "Tax Rate", Weight: 1},
+		{Header: "Taxable Amount", Weight: 2, Right: true},
+		{Header: "Tax", Weight: 2, Right: true},
+	}, taxRows, []string{"TOTAL", formatRupiah(senToRupiah(rev.Subtotal - rev.DiscountTotal)), formatRupiah(senToRupiah(rev.TaxTotal))})
+
+	if rev.Notes != "" {
+		r.section("Notes")
+		r.details([]pdfMetric{{Label: "", Value: rev.Notes}})
+	}
+
+	return r.bytes()
+}
+
+// senToRupiah converts an amount in sen to rupiah
+func senToRupiah(amount int64) float64 {
+	return float64(amount) / 100.0
+}
+
+// formatRate formats a discount or tax percentage without trailing zeros, e.g. 11% or 2.5%
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(rate, 'f', -1, 64) + "%"
+}
@@ -1,6 +1,7 @@
 package report
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -8,18 +9,30 @@ import (
 	"github.com/gilabs/crm-healthcare/api/internal/domain/activity"
 	pipelinedomain "github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/report"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/sales_target"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/visit_report"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
 	"github.com/xuri/excelize/v2"
 	"gorm.io/gorm"
 )
 
+var ErrUnknownReportType = errors.New("unknown report type")
+
 type Service struct {
-	visitReportRepo interfaces.VisitReportRepository
-	accountRepo     interfaces.AccountRepository
-	activityRepo    interfaces.ActivityRepository
-	userRepo        interfaces.UserRepository
-	dealRepo        interfaces.DealRepository
+	visitReportRepo   interfaces.VisitReportRepository
+	accountRepo       interfaces.AccountRepository
+	activityRepo      interfaces.ActivityRepository
+	userRepo          interfaces.UserRepository
+	dealRepo          interfaces.DealRepository
+	salesTargetRepo   interfaces.SalesTargetRepository
+	brandName         string
+	pipelineAnalytics PipelineAnalyticsProvider
+	dealItemRepo      interfaces.DealItemRepository
+}
+
+// PipelineAnalyticsProvider provides the stage history analytics included in the pipeline report
+type PipelineAnalyticsProvider interface {
+	GetAnalytics(req *pipelinedomain.PipelineAnalyticsRequest) (*pipelinedomain.PipelineAnalyticsResponse, error)
 }
 
 func NewService(
@@ -28,6 +41,7 @@ func NewService(
 	activityRepo interfaces.ActivityRepository,
 	userRepo interfaces.UserRepository,
 	dealRepo interfaces.DealRepository,
+	salesTargetRepo interfaces.SalesTargetRepository,
 ) *Service {
 	return &Service{
 		visitReportRepo: visitReportRepo,
@@ -35,9 +49,28 @@ func NewService(
 		activityRepo:    activityRepo,
 		userRepo:        userRepo,
 		dealRepo:        dealRepo,
+		salesTargetRepo: salesTargetRepo,
+		brandName:       DefaultBrandName,
 	}
 }
 
+// SetBrandName sets the company name printed in the header of PDF reports
+func (s *Service) SetBrandName(name string) {
+	if name != "" {
+		s.brandName = name
+	}
+}
+
+// SetPipelineAnalytics sets the provider of the analytics added to the pipeline report
+func (s *Service) SetPipelineAnalytics(provider PipelineAnalyticsProvider) {
+	s.pipelineAnalytics = provider
+}
+
+// SetDealItemRepository sets the repository of the deal line items behind the revenue by product sections
+func (s *Service) SetDealItemRepository(repo interfaces.DealItemRepository) {
+	s.dealItemRepo = repo
+}
+
 // GetVisitReportReport returns visit report report
 func (s *Service) GetVisitReportReport(req *report.ReportRequest) (*report.VisitReportReportResponse, error) {
 	var start, end time.Time
@@ -216,8 +249,9 @@ func (s *Service) GetPipelineReport(req *report.ReportRequest) (*report.Pipeline
 
 	// Get all deals (no date filter for now, as deals are not time-bound like visits)
 	deals, _, err := s.dealRepo.List(&pipelinedomain.ListDealsRequest{
-		Page:    1,
-		PerPage: 10000,
+		Page:       1,
+		PerPage:    10000,
+		PipelineID: req.PipelineID,
 	})
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, err
@@ -386,6 +420,26 @@ func (s *Service) GetPipelineReport(req *report.ReportRequest) (*report.Pipeline
 		Deals:   dealItems,
 	}
 
+	if s.pipelineAnalytics != nil {
+		analytics, err := s.pipelineAnalytics.GetAnalytics(&pipelinedomain.PipelineAnalyticsRequest{
+			StartDate:  start.Format("2006-01-02"),
+			EndDate:    end.Format("2006-01-02"),
+			AssignedTo: req.SalesRepID,
+			PipelineID: req.PipelineID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		response.Analytics = analytics
+	}
+
+	response.ByProduct, response.ByProductCategory, err = s.productRevenue(&pipelinedomain.ProductRevenueRequest{
+		PipelineID: req.PipelineID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return response, nil
 }
 
@@ -494,6 +548,51 @@ func (s *Service) GetSalesPerformanceReport(req *report.ReportRequest) (*report.
 		}
 	}
 
+	// Attainment of user targets in the period; reps with a target but no visits are included too
+	if s.salesTargetRepo != nil {
+		targets, err := s.salesTargetRepo.ListOverlapping(start, end)
+		if err != nil {
+			return nil, err
+		}
+		achievements, err := s.salesTargetRepo.AchievementByUser(start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range targets {
+			if t.Level != sales_target.LevelUser || t.UserID == nil {
+				continue
+			}
+			if _, listed := salesRepVisitCount[*t.UserID]; listed {
+				continue
+			}
+			if req.SalesRepID != "" && *t.UserID != req.SalesRepID {
+				continue
+			}
+			user, err := s.userRepo.FindByID(*t.UserID)
+			if err != nil {
+				continue
+			}
+			salesRepVisitCount[user.ID] = 0
+			stat := report.SalesPerformanceStat{ActivityCount: salesRepActivityCount[user.ID]}
+			stat.SalesRep.ID = user.ID
+			stat.SalesRep.Name = user.Name
+			stat.SalesRep.Email = user.Email
+			performanceStats = append(performanceStats, stat)
+		}
+
+		for i := range performanceStats {
+			userID := performanceStats[i].SalesRep.ID
+			revenueTarget, visitTarget, ok := sales_target.TargetFor(targets, sales_target.LevelUser, userID, start, end)
+			if !ok {
+				continue
+			}
+			revenue, visits := sales_target.AchievementFor(achievements, []string{userID})
+			attainment := sales_target.NewAttainment(revenueTarget, revenue, visitTarget, visits)
+			performanceStats[i].Attainment = &attainment
+		}
+	}
+
 	// Calculate summary
 	totalVisits := len(visitReports)
 	totalAccounts := 0
@@ -530,6 +629,17 @@ func (s *Service) GetSalesPerformanceReport(req *report.ReportRequest) (*report.
 		},
 	}
 
+	// Revenue counts won deals on the day they closed, as for sales targets
+	response.ByProduct, response.ByProductCategory, err = s.productRevenue(&pipelinedomain.ProductRevenueRequest{
+		AssignedTo: req.SalesRepID,
+		Status:     "won",
+		ClosedFrom: &start,
+		ClosedTo:   &end,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return response, nil
 }
 
@@ -674,7 +784,24 @@ func (s *Service) GetAccountActivityReport(req *report.ReportRequest) (*report.A
 	return response, nil
 }
 
-// ExportVisitReportReport exports visit report report as CSV or Excel
+// Export exports the report of the given type (see report.TypeVisitReports and friends) as CSV, Excel or PDF
+func (s *Service) Export(reportType string, req *report.ReportRequest, format string) ([]byte, string, error) {
+	switch reportType {
+	case report.TypeVisitReports:
+		return s.ExportVisitReportReport(req, format)
+	case report.TypePipeline:
+		return s.ExportPipelineReport(req, format)
+	case report.TypeSalesPerformance:
+		return s.ExportSalesPerformanceReport(req, format)
+	case report.TypeAccountActivity:
+		return s.ExportAccountActivityReport(req, format)
+	case report.TypeWinLoss:
+		return s.ExportWinLossReport(req, format)
+	}
+	return nil, "", ErrUnknownReportType
+}
+
+// ExportVisitReportReport exports visit report report as CSV, Excel or PDF
 func (s *Service) ExportVisitReportReport(req *report.ReportRequest, format string) ([]byte, string, error) {
 	// Get report data
 	reportData, err := s.GetVisitReportReport(req)
@@ -682,27 +809,28 @@ func (s *Service) ExportVisitReportReport(req *report.ReportRequest, format stri
 		return nil, "", err
 	}
 
-	// Generate filename
-	filename := "visit-report-export.csv"
-	if format == "excel" {
-		filename = "visit-report-export.xlsx"
-	}
-
 	// Generate file based on format
-	if format == "csv" {
+	switch format {
+	case "csv":
 		csvData := s.generateVisitReportCSV(reportData)
-		return csvData, filename, nil
-	} else {
+		return csvData, "visit-report-export.csv", nil
+	case "pdf":
+		pdfData, err := s.generateVisitReportPDF(reportData)
+		if err != nil {
+			return nil, "", err
+		}
+		return pdfData, "visit-report-export.pdf", nil
+	default:
 		// Generate Excel with styling
 		excelData, err := s.generateVisitReportExcel(reportData)
 		if err != nil {
 			return nil, "", err
 		}
-		return excelData, filename, nil
+		return excelData, "visit-report-export.xlsx", nil
 	}
 }
 
-// ExportPipelineReport exports pipeline report as CSV or Excel
+// ExportPipelineReport exports pipeline report as CSV, Excel or PDF
 func (s *Service) ExportPipelineReport(req *report.ReportRequest, format string) ([]byte, string, error) {
 	// Get report data
 	reportData, err := s.GetPipelineReport(req)
@@ -710,27 +838,28 @@ func (s *Service) ExportPipelineReport(req *report.ReportRequest, format string)
 		return nil, "", err
 	}
 
-	// Generate filename
-	filename := "pipeline-report-export.csv"
-	if format == "excel" {
-		filename = "pipeline-report-export.xlsx"
-	}
-
 	// Generate file based on format
-	if format == "csv" {
+	switch format {
+	case "csv":
 		csvData := s.generatePipelineReportCSV(reportData)
-		return csvData, filename, nil
-	} else {
+		return csvData, "pipeline-report-export.csv", nil
+	case "pdf":
+		pdfData, err := s.generatePipelineReportPDF(reportData)
+		if err != nil {
+			return nil, "", err
+		}
+		return pdfData, "pipeline-report-export.pdf", nil
+	default:
 		// Generate Excel with styling
 		excelData, err := s.generatePipelineReportExcel(reportData)
 		if err != nil {
 			return nil, "", err
 		}
-		return excelData, filename, nil
+		return excelData, "pipeline-report-export.xlsx", nil
 	}
 }
 
-// ExportSalesPerformanceReport exports sales performance report as CSV or Excel
+// ExportSalesPerformanceReport exports sales performance report as CSV, Excel or PDF
 func (s *Service) ExportSalesPerformanceReport(req *report.ReportRequest, format string) ([]byte, string, error) {
 	// Get report data
 	reportData, err := s.GetSalesPerformanceReport(req)
@@ -738,27 +867,28 @@ func (s *Service) ExportSalesPerformanceReport(req *report.ReportRequest, format
 		return nil, "", err
 	}
 
-	// Generate filename
-	filename := "sales-performance-report-export.csv"
-	if format == "excel" {
-		filename = "sales-performance-report-export.xlsx"
-	}
-
 	// Generate file based on format
-	if format == "csv" {
+	switch format {
+	case "csv":
 		csvData := s.generateSalesPerformanceReportCSV(reportData)
-		return csvData, filename, nil
-	} else {
+		return csvData, "sales-performance-report-export.csv", nil
+	case "pdf":
+		pdfData, err := s.generateSalesPerformanceReportPDF(reportData)
+		if err != nil {
+			return nil, "", err
+		}
+		return pdfData, "sales-performance-report-export.pdf", nil
+	default:
 		// Generate Excel with styling
 		excelData, err := s.generateSalesPerformanceReportExcel(reportData)
 		if err != nil {
 			return nil, "", err
 		}
-		return excelData, filename, nil
+		return excelData, "sales-performance-report-export.xlsx", nil
 	}
 }
 
-// ExportAccountActivityReport exports account activity report as CSV or Excel
+// ExportAccountActivityReport exports account activity report as CSV, Excel or PDF
 func (s *Service) ExportAccountActivityReport(req *report.ReportRequest, format string) ([]byte, string, error) {
 	// Get report data
 	reportData, err := s.GetAccountActivityReport(req)
@@ -766,23 +896,24 @@ func (s *Service) ExportAccountActivityReport(req *report.ReportRequest, format
 		return nil, "", err
 	}
 
-	// Generate filename
-	filename := "account-activity-report-export.csv"
-	if format == "excel" {
-		filename = "account-activity-report-export.xlsx"
-	}
-
 	// Generate file based on format
-	if format == "csv" {
+	switch format {
+	case "csv":
 		csvData := s.generateAccountActivityReportCSV(reportData)
-		return csvData, filename, nil
-	} else {
+		return csvData, "account-activity-report-export.csv", nil
+	case "pdf":
+		pdfData, err := s.generateAccountActivityReportPDF(reportData)
+		if err != nil {
+			return nil, "", err
+		}
+		return pdfData, "account-activity-report-export.pdf", nil
+	default:
 		// Generate Excel with styling
 		excelData, err := s.generateAccountActivityReportExcel(reportData)
 		if err != nil {
 			return nil, "", err
 		}
-		return excelData, filename, nil
+		return excelData, "account-activity-report-export.xlsx", nil
 	}
 }
 
@@ -862,6 +993,8 @@ func (s *Service) generatePipelineReportCSV(data *report.PipelineReportResponse)
 		))
 	}
 
+	writeProductRevenueCSV(&csv, data.ByProduct, data.ByProductCategory)
+
 	return []byte(csv.String())
 }
 
@@ -881,9 +1014,13 @@ func (s *Service) generateSalesPerformanceReportCSV(data *report.SalesPerformanc
 
 	// Write by sales rep
 	csv.WriteString("\nBy Sales Rep\n")
-	csv.WriteString("Sales Rep ID,Sales Rep Name,Email,Visit Count,Account Count,Activity Count,Completion Rate\n")
+	csv.WriteString("Sales Rep ID,Sales Rep Name,Email,Visit Count,Account Count,Activity Count,Completion Rate,Revenue Target,Revenue Achieved,Revenue Attainment,Visit Target,Visits Achieved,Visit Attainment\n")
 	for _, stat := range data.BySalesRep {
-		csv.WriteString(fmt.Sprintf("%s,\"%s\",\"%s\",%d,%d,%d,%.2f%%\n",
+		attainment := sales_target.Attainment{}
+		if stat.Attainment != nil {
+			attainment = *stat.Attainment
+		}
+		csv.WriteString(fmt.Sprintf("%s,\"%s\",\"%s\",%d,%d,%d,%.2f%%,%d,%d,%.2f%%,%d,%d,%.2f%%\n",
 			stat.SalesRep.ID,
 			stat.SalesRep.Name,
 			stat.SalesRep.Email,
@@ -891,9 +1028,17 @@ func (s *Service) generateSalesPerformanceReportCSV(data *report.SalesPerformanc
 			stat.AccountCount,
 			stat.ActivityCount,
 			stat.CompletionRate,
+			attainment.RevenueTarget,
+			attainment.RevenueAchieved,
+			attainment.RevenueProgressPercent,
+			attainment.VisitTarget,
+			attainment.VisitsAchieved,
+			attainment.VisitProgressPercent,
 		))
 	}
 
+	writeProductRevenueCSV(&csv, data.ByProduct, data.ByProductCategory)
+
 	return []byte(csv.String())
 }
 
@@ -1166,7 +1311,7 @@ func (s *Service) generateVisitReportExcel(data *report.VisitReportReportRespons
 	return buf.Bytes(), nil
 }
 
-// generatePipelineReportExcel generates Excel file for pipeline report with 2 tabs: Sales Funnel and Insights
+// generatePipelineReportExcel generates Excel file for pipeline report with 3 tabs: Sales Funnel, Insights and Velocity
 func (s *Service) generatePipelineReportExcel(data *report.PipelineReportResponse) ([]byte, error) {
 	f := excelize.NewFile()
 	defer func() {
@@ -1385,34 +1530,8 @@ func (s *Service) generatePipelineReportExcel(data *report.PipelineReportRespons
 	}
 
 	// Calculate team metrics (group by team member)
-	teamStats := make(map[string]struct {
-		wonValue   float64
-		expectedValue float64
-		wonCount   int
-		totalCount int
-	})
-	for _, deal := range data.Deals {
-		if deal.TeamMember != "" {
-			stat := teamStats[deal.TeamMember]
-			stat.totalCount++
-			stat.expectedValue += deal.ExpectedRevenue
-			if deal.StageCode == "won" || strings.Contains(strings.ToLower(deal.Stage), "won") {
-				stat.wonCount++
-				stat.wonValue += deal.Value
-			}
-			teamStats[deal.TeamMember] = stat
-		}
-	}
-
-	// Find most sold rep
-	mostSoldRep := ""
-	maxWonValue := 0.0
-	for rep, stat := range teamStats {
-		if stat.wonValue > maxWonValue {
-			maxWonValue = stat.wonValue
-			mostSoldRep = rep
-		}
-	}
+	teamStats := pipelineTeamStats(data.Deals)
+	mostSoldRep := mostSoldTeamMember(teamStats)
 
 	// Calculate team closing percentage
 	teamClosingPercent := 0.0
@@ -1662,6 +1781,18 @@ func (s *Service) generatePipelineReportExcel(data *report.PipelineReportRespons
 		f.SetColWidth(sheet2Name, col, col, 20)
 	}
 
+	// ===== TAB 3: Velocity =====
+	if data.Analytics != nil {
+		if err := addPipelineAnalyticsSheet(f, data.Analytics, titleStyle, subtitleStyle, headerStyle, dataStyle, numberStyle); err != nil {
+			return nil, err
+		}
+	}
+
+	// ===== TAB 4: Products =====
+	if err := addProductRevenueSheet(f, "All deals of the pipeline", data.ByProduct, data.ByProductCategory, titleStyle, subtitleStyle, headerStyle, dataStyle, numberStyle); err != nil {
+		return nil, err
+	}
+
 	// Set Sales Funnel as active sheet
 	f.SetActiveSheet(sheet1Index)
 
@@ -1673,6 +1804,45 @@ func (s *Service) generatePipelineReportExcel(data *report.PipelineReportRespons
 	return buf.Bytes(), nil
 }
 
+// pipelineTeamStat holds the deal totals of one team member in the pipeline report
+type pipelineTeamStat struct {
+	wonValue      float64
+	expectedValue float64
+	wonCount      int
+	totalCount    int
+}
+
+// pipelineTeamStats groups the deals of the pipeline report by team member
+func pipelineTeamStats(deals []report.DealReportItem) map[string]pipelineTeamStat {
+	teamStats := make(map[string]pipelineTeamStat)
+	for _, deal := range deals {
+		if deal.TeamMember != "" {
+			stat := teamStats[deal.TeamMember]
+			stat.totalCount++
+			stat.expectedValue += deal.ExpectedRevenue
+			if deal.StageCode == "won" || strings.Contains(strings.ToLower(deal.Stage), "won") {
+				stat.wonCount++
+				stat.wonValue += deal.Value
+			}
+			teamStats[deal.TeamMember] = stat
+		}
+	}
+	return teamStats
+}
+
+// mostSoldTeamMember returns the team member with the highest won value, or "" when nothing was won
+func mostSoldTeamMember(teamStats map[string]pipelineTeamStat) string {
+	mostSoldRep := ""
+	maxWonValue := 0.0
+	for rep, stat := range teamStats {
+		if stat.wonValue > maxWonValue || (stat.wonValue == maxWonValue && maxWonValue > 0 && rep < mostSoldRep) {
+			maxWonValue = stat.wonValue
+			mostSoldRep = rep
+		}
+	}
+	return mostSoldRep
+}
+
 // generateSalesPerformanceReportExcel generates Excel file for sales performance report with professional styling
 func (s *Service) generateSalesPerformanceReportExcel(data *report.SalesPerformanceReportResponse) ([]byte, error) {
 	f := excelize.NewFile()
@@ -1745,7 +1915,7 @@ func (s *Service) generateSalesPerformanceReportExcel(data *report.SalesPerforma
 		row++
 
 		// Headers
-		salesRepHeaders := []string{"Sales Rep ID", "Sales Rep Name", "Email", "Visit Count", "Account Count", "Activity Count", "Completion Rate (%)"}
+		salesRepHeaders := []string{"Sales Rep ID", "Sales Rep Name", "Email", "Visit Count", "Account Count", "Activity Count", "Completion Rate (%)", "Revenue Target", "Revenue Achieved", "Revenue Attainment (%)", "Visit Target", "Visits Achieved", "Visit Attainment (%)"}
 		for i, header := range salesRepHeaders {
 			cell := fmt.Sprintf("%c%d", 'A'+i, row)
 			f.SetCellValue(sheetName, cell, header)
@@ -1769,16 +1939,36 @@ func (s *Service) generateSalesPerformanceReportExcel(data *report.SalesPerforma
 			f.SetCellStyle(sheetName, fmt.Sprintf("F%d", row), fmt.Sprintf("F%d", row), numberStyle)
 			f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), fmt.Sprintf("%.2f%%", stat.CompletionRate))
 			f.SetCellStyle(sheetName, fmt.Sprintf("G%d", row), fmt.Sprintf("G%d", row), numberStyle)
+			if stat.Attainment != nil {
+				attainmentData := []interface{}{
+					stat.Attainment.RevenueTarget,
+					stat.Attainment.RevenueAchieved,
+					fmt.Sprintf("%.2f%%", stat.Attainment.RevenueProgressPercent),
+					stat.Attainment.VisitTarget,
+					stat.Attainment.VisitsAchieved,
+					fmt.Sprintf("%.2f%%", stat.Attainment.VisitProgressPercent),
+				}
+				for i, value := range attainmentData {
+					cell := fmt.Sprintf("%c%d", 'H'+i, row)
+					f.SetCellValue(sheetName, cell, value)
+					f.SetCellStyle(sheetName, cell, cell, numberStyle)
+				}
+			}
 			row++
 		}
 	}
 
 	// Auto-fit columns
-	for i := 0; i < 7; i++ {
+	for i := 0; i < 13; i++ {
 		col := string(rune('A' + i))
 		f.SetColWidth(sheetName, col, col, 18)
 	}
 
+	subtitle := fmt.Sprintf("Deals won %s to %s", data.Period.Start.Format("2006-01-02"), data.Period.End.Format("2006-01-02"))
+	if err := addProductRevenueSheet(f, subtitle, data.ByProduct, data.ByProductCategory, titleStyle, subtitleStyle, headerStyle, dataStyle, numberStyle); err != nil {
+		return nil, err
+	}
+
 	// Save to buffer
 	buf, err := f.WriteToBuffer()
 	if err != nil {
@@ -0,0 +1,628 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	pipelinedomain "github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/report"
+	"github.com/xuri/excelize/v2"
+)
+
+// notSpecified names the lost deals closed without a loss reason, competitor or stage
+const notSpecified = "Not specified"
+
+// GetWinLossReport returns the win/loss analysis of the deals won or lost in the period, with the lost deals
+// broken down by loss reason, competitor, stage lost from, sales rep and product
+func (s *Service) GetWinLossReport(req *report.ReportRequest) (*report.WinLossReportResponse, error) {
+	var start, end time.Time
+	if req.StartDate != "" && req.EndDate != "" {
+		var err error
+		start, err = time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return nil, err
+		}
+		end, err = time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		end = time.Date(end.Year(), end.Month(), end.Day(), 23, 59, 59, 999999999, end.Location())
+	} else {
+		// Default to last 30 days
+		end = time.Now()
+		start = end.AddDate(0, 0, -30)
+	}
+
+	deals, err := s.dealRepo.ListClosed(start, end, req.PipelineID, req.SalesRepID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &report.WinLossReportResponse{
+		LostDeals: make([]report.LostDealItem, 0),
+	}
+	response.Period.Start = start
+	response.Period.End = end
+
+	byReason := newLossBreakdown()
+	byCompetitor := newLossBreakdown()
+	byStage := newLossBreakdown()
+	byRep := make(map[string]*report.WinLossRepStat)
+	repOrder := make([]string, 0)
+
+	for i := range deals {
+		deal := &deals[i]
+		// Convert value from int64 (sen) to float64 (rupiah)
+		value := float64(deal.Value) / 100.0
+
+		rep, ok := byRep[deal.AssignedTo]
+		if !ok {
+			rep = &report.WinLossRepStat{}
+			rep.SalesRep.ID = deal.AssignedTo
+			rep.SalesRep.Name = "Unassigned"
+			if deal.AssignedUser != nil {
+				rep.SalesRep.Name = deal.AssignedUser.Name
+			}
+			byRep[deal.AssignedTo] = rep
+			repOrder = append(repOrder, deal.AssignedTo)
+		}
+
+		if deal.Status == "won" {
+			response.Summary.WonDeals++
+			response.Summary.WonValue += value
+			rep.WonDeals++
+			rep.WonValue += value
+			continue
+		}
+
+		response.Summary.LostDeals++
+		response.Summary.LostValue += value
+		rep.LostDeals++
+		rep.LostValue += value
+
+		item := report.LostDealItem{
+			ID:            deal.ID,
+			Title:         deal.Title,
+			Value:         value,
+			LostReason:    notSpecified,
+			Competitor:    notSpecified,
+			LostFromStage: notSpecified,
+			LostNotes:     deal.LostNotes,
+			ClosedOn:      deal.ActualCloseDate,
+		}
+		if deal.Account != nil {
+			item.AccountName = deal.Account.Name
+		}
+		item.SalesRep = rep.SalesRep.Name
+
+		reasonID := ""
+		if deal.LostReason != nil {
+			reasonID, item.LostReason = deal.LostReason.ID, deal.LostReason.Name
+		}
+		competitorID := ""
+		if deal.Competitor != nil {
+			competitorID, item.Competitor = deal.Competitor.ID, deal.Competitor.Name
+		}
+		stageID := ""
+		if deal.LostFromStage != nil {
+			stageID, item.LostFromStage = deal.LostFromStage.ID, deal.LostFromStage.Name
+		}
+		byReason.add(reasonID, item.LostReason, value)
+		byCompetitor.add(competitorID, item.Competitor, value)
+		byStage.add(stageID, item.LostFromStage, value)
+
+		response.LostDeals = append(response.LostDeals, item)
+	}
+
+	response.Summary.ClosedDeals = response.Summary.WonDeals + response.Summary.LostDeals
+	response.Summary.WinRate = winRate(response.Summary.WonDeals, response.Summary.LostDeals)
+
+	lostDeals := response.Summary.LostDeals
+	response.ByReason = byReason.stats(lostDeals)
+	response.ByCompetitor = byCompetitor.stats(lostDeals)
+	response.ByStage = byStage.stats(lostDeals)
+
+	response.BySalesRep = make([]report.WinLossRepStat, 0, len(byRep))
+	for _, id := range repOrder {
+		rep := byRep[id]
+		rep.WinRate = winRate(rep.WonDeals, rep.LostDeals)
+		response.BySalesRep = append(response.BySalesRep, *rep)
+	}
+	sort.SliceStable(response.BySalesRep, func(i, j int) bool {
+		if response.BySalesRep[i].LostDeals != response.BySalesRep[j].LostDeals {
+			return response.BySalesRep[i].LostDeals > response.BySalesRep[j].LostDeals
+		}
+		return response.BySalesRep[i].SalesRep.Name < response.BySalesRep[j].SalesRep.Name
+	})
+
+	response.ByProduct, err = s.winLossByProduct(req, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// winLossByProduct returns the line items of the deals won and lost in the period by product, most lost revenue
+// first. It is empty when no deal item repository is set.
+func (s *Service) winLossByProduct(req *report.ReportRequest, start, end time.Time) ([]report.WinLossProductStat, error) {
+	products := make([]report.WinLossProductStat, 0)
+	if s.dealItemRepo == nil {
+		return products, nil
+	}
+
+	byProduct := make(map[string]*report.WinLossProductStat)
+	for _, status := range []string{"won", "lost"} {
+		rows, err := s.dealItemRepo.ProductRevenue(&pipelinedomain.ProductRevenueRequest{
+			PipelineID: req.PipelineID,
+			AssignedTo: req.SalesRepID,
+			Status:     status,
+			ClosedFrom: &start,
+			ClosedTo:   &end,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			stat, ok := byProduct[row.ProductID]
+			if !ok {
+				stat = &report.WinLossProductStat{
+					ProductID:    row.ProductID,
+					ProductName:  row.ProductName,
+					SKU:          row.SKU,
+					CategoryName: row.CategoryName,
+				}
+				byProduct[row.ProductID] = stat
+			}
+			if status == "won" {
+				stat.WonDeals = row.Deals
+				stat.WonRevenue = float64(row.Revenue) / 100.0
+			} else {
+				stat.LostDeals = row.Deals
+				stat.LostRevenue = float64(row.Revenue) / 100.0
+			}
+		}
+	}
+
+	for _, stat := range byProduct {
+		stat.WinRate = winRate(stat.WonDeals, stat.LostDeals)
+		products = append(products, *stat)
+	}
+	sort.Slice(products, func(i, j int) bool {
+		if products[i].LostRevenue != products[j].LostRevenue {
+			return products[i].LostRevenue > products[j].LostRevenue
+		}
+		if products[i].WonRevenue != products[j].WonRevenue {
+			return products[i].WonRevenue > products[j].WonRevenue
+		}
+		return products[i].ProductName < products[j].ProductName
+	})
+
+	return products, nil
+}
+
+// lossBreakdown counts the lost deals by loss reason, competitor or stage, in order of first appearance
+type lossBreakdown struct {
+	byID  map[string]*report.LossBreakdownStat
+	order []string
+}
+
+func newLossBreakdown() *lossBreakdown {
+	return &lossBreakdown{byID: make(map[string]*report.LossBreakdownStat)}
+}
+
+func (b *lossBreakdown) add(id, name string, value float64) {
+	stat, ok := b.byID[id]
+	if !ok {
+		stat = &report.LossBreakdownStat{ID: id, Name: name}
+		b.byID[id] = stat
+		b.order = append(b.order, id)
+	}
+	stat.Deals++
+	stat.Value += value
+}
+
+// stats returns the breakdown with the share of each entry in the lost deals, most lost deals first
+func (b *lossBreakdown) stats(lostDeals int) []report.LossBreakdownStat {
+	stats := make([]report.LossBreakdownStat, 0, len(b.order))
+	for _, id := range b.order {
+		stat := *b.byID[id]
+		if lostDeals > 0 {
+			stat.Share = float64(stat.Deals) / float64(lostDeals) * 100
+		}
+		stats = append(stats, stat)
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Deals != stats[j].Deals {
+			return stats[i].Deals > stats[j].Deals
+		}
+		return stats[i].Value > stats[j].Value
+	})
+	return stats
+}
+
+// winRate returns the percentage of the closed deals that were won
+func winRate(won, lost int) float64 {
+	if won+lost == 0 {
+		return 0
+	}
+	return float64(won) / float64(won+lost) * 100
+}
+
+// ExportWinLossReport exports win/loss report as CSV, Excel or PDF
+func (s *Service) ExportWinLossReport(req *report.ReportRequest, format string) ([]byte, string, error) {
+	// Get report data
+	reportData, err := s.GetWinLossReport(req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Generate file based on format
+	switch format {
+	case "csv":
+		csvData := s.generateWinLossReportCSV(reportData)
+		return csvData, "win-loss-report-export.csv", nil
+	case "pdf":
+		pdfData, err := s.generateWinLossReportPDF(reportData)
+		if err != nil {
+			return nil, "", err
+		}
+		return pdfData, "win-loss-report-export.pdf", nil
+	default:
+		// Generate Excel with styling
+		excelData, err := s.generateWinLossReportExcel(reportData)
+		if err != nil {
+			return nil, "", err
+		}
+		return excelData, "win-loss-report-export.xlsx", nil
+	}
+}
+
+// generateWinLossReportCSV generates CSV data for win/loss report
+func (s *Service) generateWinLossReportCSV(data *report.WinLossReportResponse) []byte {
+	var csv strings.Builder
+
+	// Write summary
+	csv.WriteString("Period Start,Period End,Closed Deals,Won Deals,Won Value,Lost Deals,Lost Value,Win Rate\n")
+	csv.WriteString(fmt.Sprintf("%s,%s,%d,%d,%.2f,%d,%.2f,%.2f%%\n",
+		data.Period.Start.Format("2006-01-02"),
+		data.Period.End.Format("2006-01-02"),
+		data.Summary.ClosedDeals,
+		data.Summary.WonDeals,
+		data.Summary.WonValue,
+		data.Summary.LostDeals,
+		data.Summary.LostValue,
+		data.Summary.WinRate,
+	))
+
+	writeBreakdown := func(title, label string, stats []report.LossBreakdownStat) {
+		csv.WriteString("\n" + title + "\n")
+		csv.WriteString(label + ",Lost Deals,Lost Value,Share\n")
+		for _, stat := range stats {
+			csv.WriteString(fmt.Sprintf("\"%s\",%d,%.2f,%.2f%%\n", stat.Name, stat.Deals, stat.Value, stat.Share))
+		}
+	}
+	writeBreakdown("By Loss Reason", "Loss Reason", data.ByReason)
+	writeBreakdown("By Competitor", "Competitor", data.ByCompetitor)
+	writeBreakdown("By Stage Lost From", "Stage", data.ByStage)
+
+	csv.WriteString("\nBy Sales Rep\n")
+	csv.WriteString("Sales Rep ID,Sales Rep Name,Won Deals,Won Value,Lost Deals,Lost Value,Win Rate\n")
+	for _, stat := range data.BySalesRep {
+		csv.WriteString(fmt.Sprintf("%s,\"%s\",%d,%.2f,%d,%.2f,%.2f%%\n",
+			stat.SalesRep.ID,
+			stat.SalesRep.Name,
+			stat.WonDeals,
+			stat.WonValue,
+			stat.LostDeals,
+			stat.LostValue,
+			stat.WinRate,
+		))
+	}
+
+	csv.WriteString("\nBy Product\n")
+	csv.WriteString("Product ID,Product Name,SKU,Category,Won Deals,Won Revenue,Lost Deals,Lost Revenue,Win Rate\n")
+	for _, p := range data.ByProduct {
+		csv.WriteString(fmt.Sprintf("%s,\"%s\",\"%s\",\"%s\",%d,%.2f,%d,%.2f,%.2f%%\n",
+			p.ProductID,
+			p.ProductName,
+			p.SKU,
+			p.CategoryName,
+			p.WonDeals,
+			p.WonRevenue,
+			p.LostDeals,
+			p.LostRevenue,
+			p.WinRate,
+		))
+	}
+
+	csv.WriteString("\nLost Deals\n")
+	csv.WriteString("Deal ID,Title,Account,Sales Rep,Value,Loss Reason,Competitor,Lost From Stage,Closed On,Notes\n")
+	for _, deal := range data.LostDeals {
+		closedOn := ""
+		if deal.ClosedOn != nil {
+			closedOn = deal.ClosedOn.Format("2006-01-02")
+		}
+		csv.WriteString(fmt.Sprintf("%s,\"%s\",\"%s\",\"%s\",%.2f,\"%s\",\"%s\",\"%s\",%s,\"%s\"\n",
+			deal.ID,
+			deal.Title,
+			deal.AccountName,
+			deal.SalesRep,
+			deal.Value,
+			deal.LostReason,
+			deal.Competitor,
+			deal.LostFromStage,
+			closedOn,
+			strings.ReplaceAll(deal.LostNotes, "\"", "\"\""),
+		))
+	}
+
+	return []byte(csv.String())
+}
+
+// generateWinLossReportExcel generates Excel file for win/loss report with a Summary, a Lost Deals and a Products tab
+func (s *Service) generateWinLossReportExcel(data *report.WinLossReportResponse) ([]byte, error) {
+	f := excelize.NewFile()
+	defer func() {
+		if err := f.Close(); err != nil {
+			fmt.Printf("Error closing file: %v\n", err)
+		}
+	}()
+
+	sheetName := "Win Loss"
+	index, err := f.NewSheet(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	f.SetActiveSheet(index)
+	f.DeleteSheet("Sheet1")
+
+	// Create styles
+	titleStyle, _ := createExcelTitleStyle(f)
+	subtitleStyle, _ := createExcelSubtitleStyle(f)
+	headerStyle, _ := createExcelHeaderStyle(f)
+	dataStyle, _ := createExcelDataStyle(f)
+	numberStyle, _ := createExcelNumberStyle(f)
+
+	sheet := excelSheet{f: f, name: sheetName, headerStyle: headerStyle, dataStyle: dataStyle, numberStyle: numberStyle}
+	period := fmt.Sprintf("Period: %s to %s", data.Period.Start.Format("2006-01-02"), data.Period.End.Format("2006-01-02"))
+
+	row := 1
+	sheet.section(row, "Win/Loss Analysis", titleStyle)
+	row++
+	sheet.section(row, period, subtitleStyle)
+	row += 2
+
+	sheet.section(row, "Summary", subtitleStyle)
+	row++
+	sheet.headers(row, "Closed Deals", "Won Deals", "Won Value", "Lost Deals", "Lost Value", "Win Rate (%)")
+	row++
+	sheet.values(row, data.Summary.ClosedDeals, data.Summary.WonDeals, data.Summary.WonValue, data.Summary.LostDeals, data.Summary.LostValue, fmt.Sprintf("%.2f%%", data.Summary.WinRate))
+	row += 2
+
+	breakdowns := []struct {
+		title string
+		label string
+		stats []report.LossBreakdownStat
+	}{
+		{"By Loss Reason", "Loss Reason", data.ByReason},
+		{"By Competitor", "Competitor", data.ByCompetitor},
+		{"By Stage Lost From", "Stage", data.ByStage},
+	}
+	for _, breakdown := range breakdowns {
+		sheet.section(row, breakdown.title, subtitleStyle)
+		row++
+		sheet.headers(row, breakdown.label, "Lost Deals", "Lost Value", "Share (%)")
+		row++
+		for _, stat := range breakdown.stats {
+			sheet.values(row, stat.Name, stat.Deals, stat.Value, fmt.Sprintf("%.2f%%", stat.Share))
+			row++
+		}
+		row++
+	}
+
+	sheet.section(row, "By Sales Rep", subtitleStyle)
+	row++
+	sheet.headers(row, "Sales Rep", "Won Deals", "Won Value", "Lost Deals", "Lost Value", "Win Rate (%)")
+	row++
+	for _, stat := range data.BySalesRep {
+		sheet.values(row, stat.SalesRep.Name, stat.WonDeals, stat.WonValue, stat.LostDeals, stat.LostValue, fmt.Sprintf("%.2f%%", stat.WinRate))
+		row++
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 30)
+	f.SetColWidth(sheetName, "B", "F", 18)
+
+	// Lost Deals tab
+	if _, err := f.NewSheet("Lost Deals"); err != nil {
+		return nil, err
+	}
+	sheet.name = "Lost Deals"
+	row = 1
+	sheet.section(row, "Lost Deals", titleStyle)
+	row++
+	sheet.section(row, period, subtitleStyle)
+	row += 2
+	sheet.headers(row, "Title", "Account", "Sales Rep", "Value", "Loss Reason", "Competitor", "Lost From Stage", "Closed On", "Notes")
+	row++
+	for _, deal := range data.LostDeals {
+		closedOn := ""
+		if deal.ClosedOn != nil {
+			closedOn = deal.ClosedOn.Format("2006-01-02")
+		}
+		sheet.values(row, deal.Title, deal.AccountName, deal.SalesRep, deal.Value, deal.LostReason, deal.Competitor, deal.LostFromStage, closedOn, deal.LostNotes)
+		row++
+	}
+	f.SetColWidth(sheet.name, "A", "C", 28)
+	f.SetColWidth(sheet.name, "D", "H", 18)
+	f.SetColWidth(sheet.name, "I", "I", 40)
+
+	// Products tab
+	if _, err := f.NewSheet("Products"); err != nil {
+		return nil, err
+	}
+	sheet.name = "Products"
+	row = 1
+	sheet.section(row, "Win/Loss by Product", titleStyle)
+	row++
+	sheet.section(row, period, subtitleStyle)
+	row += 2
+	sheet.headers(row, "Product", "SKU", "Category", "Won Deals", "Won Revenue", "Lost Deals", "Lost Revenue", "Win Rate (%)")
+	row++
+	for _, p := range data.ByProduct {
+		sheet.values(row, p.ProductName, p.SKU, p.CategoryName, p.WonDeals, p.WonRevenue, p.LostDeals, p.LostRevenue, fmt.Sprintf("%.2f%%", p.WinRate))
+		row++
+	}
+	f.SetColWidth(sheet.name, "A", "A", 30)
+	f.SetColWidth(sheet.name, "B", "H", 18)
+
+	// Save to buffer
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// excelSheet writes the sections, headers and rows of a styled report sheet from column A
+type excelSheet struct {
+	f           *excelize.File
+	name        string
+	headerStyle int
+	dataStyle   int
+	numberStyle int
+}
+
+func (s excelSheet) section(row int, title string, style int) {
+	cell := fmt.Sprintf("A%d", row)
+	s.f.SetCellValue(s.name, cell, title)
+	s.f.SetCellStyle(s.name, cell, cell, style)
+	s.f.MergeCell(s.name, cell, fmt.Sprintf("F%d", row))
+}
+
+func (s excelSheet) headers(row int, headers ...string) {
+	for i, header := range headers {
+		cell := fmt.Sprintf("%c%d", 'A'+i, row)
+		s.f.SetCellValue(s.name, cell, header)
+		s.f.SetCellStyle(s.name, cell, cell, s.headerStyle)
+	}
+}
+
+// values writes a row, styling numbers as numbers
+func (s excelSheet) values(row int, values ...interface{}) {
+	for i, v := range values {
+		cell := fmt.Sprintf("%c%d", 'A'+i, row)
+		s.f.SetCellValue(s.name, cell, v)
+		style := s.dataStyle
+		switch v.(type) {
+		case int, int64, float64:
+			style = s.numberStyle
+		}
+		s.f.SetCellStyle(s.name, cell, cell, style)
+	}
+}
+
+// generateWinLossReportPDF generates a PDF of the win/loss report
+func (s *Service) generateWinLossReportPDF(data *report.WinLossReportResponse) ([]byte, error) {
+	r := newPDFReport(s.brandName, "Win/Loss Analysis", "", [2]time.Time{data.Period.Start, data.Period.End}, true)
+
+	r.section("Summary")
+	r.summary([]pdfMetric{
+		{Label: "Closed Deals", Value: formatThousands(int64(data.Summary.ClosedDeals))},
+		{Label: "Won", Value: fmt.Sprintf("%s (%s)", formatThousands(int64(data.Summary.WonDeals)), formatRupiah(data.Summary.WonValue))},
+		{Label: "Lost", Value: fmt.Sprintf("%s (%s)", formatThousands(int64(data.Summary.LostDeals)), formatRupiah(data.Summary.LostValue))},
+		{Label: "Win Rate", Value: formatPercent(data.Summary.WinRate)},
+	})
+
+	breakdowns := []struct {
+		title string
+		label string
+		stats []report.LossBreakdownStat
+	}{
+		{"By Loss Reason", "Loss Reason", data.ByReason},
+		{"By Competitor", "Competitor", data.ByCompetitor},
+		{"By Stage Lost From", "Stage", data.ByStage},
+	}
+	for _, breakdown := range breakdowns {
+		r.section(breakdown.title)
+		if len(breakdown.stats) == 0 {
+			r.note("No deals lost in this period.")
+			continue
+		}
+		bars := make([]pdfBar, 0, len(breakdown.stats))
+		rows := make([][]string, 0, len(breakdown.stats))
+		for _, stat := range breakdown.stats {
+			bars = append(bars, pdfBar{Label: stat.Name, Value: float64(stat.Deals)})
+			rows = append(rows, []string{
+				stat.Name,
+				formatThousands(int64(stat.Deals)),
+				formatRupiah(stat.Value),
+				formatPercent(stat.Share),
+			})
+		}
+		r.barChart("Lost Deals "+strings.ToLower(breakdown.title), bars, formatCount)
+		r.table([]pdfColumn{
+			{Header: breakdown.label, Weight: 3},
+			{Header: "Lost Deals", Weight: 1, Right: true},
+			{Header: "Lost Value", Weight: 2, Right: true},
+			{Header: "Share", Weight: 1, Right: true},
+		}, rows, nil)
+	}
+
+	r.section("By Sales Rep")
+	if len(data.BySalesRep) == 0 {
+		r.note("No deals closed in this period.")
+	} else {
+		rows := make([][]string, 0, len(data.BySalesRep))
+		for _, stat := range data.BySalesRep {
+			rows = append(rows, []string{
+				stat.SalesRep.Name,
+				formatThousands(int64(stat.WonDeals)),
+				formatRupiah(stat.WonValue),
+				formatThousands(int64(stat.LostDeals)),
+				formatRupiah(stat.LostValue),
+				formatPercent(stat.WinRate),
+			})
+		}
+		r.table([]pdfColumn{
+			{Header: "Sales Rep", Weight: 3},
+			{Header: "Won Deals", Weight: 1, Right: true},
+			{Header: "Won Value", Weight: 2, Right: true},
+			{Header: "Lost Deals", Weight: 1, Right: true},
+			{Header: "Lost Value", Weight: 2, Right: true},
+			{Header: "Win Rate", Weight: 1, Right: true},
+		}, rows, nil)
+	}
+
+	r.section("By Product")
+	if len(data.ByProduct) == 0 {
+		r.note("No deal line items in this report.")
+	} else {
+		rows := make([][]string, 0, len(data.ByProduct))
+		for _, p := range data.ByProduct {
+			rows = append(rows, []string{
+				p.ProductName,
+				p.SKU,
+				formatThousands(int64(p.WonDeals)),
+				formatRupiah(p.WonRevenue),
+				formatThousands(int64(p.LostDeals)),
+				formatRupiah(p.LostRevenue),
+				formatPercent(p.WinRate),
+			})
+		}
+		r.table([]pdfColumn{
+			{Header: "Product", Weight: 3},
+			{Header: "SKU", Weight: 1.5},
+			{Header: "Won Deals", Weight: 1, Right: true},
+			{Header: "Won Revenue", Weight: 2, Right: true},
+			{Header: "Lost Deals", Weight: 1, Right: true},
+			{Header: "Lost Revenue", Weight: 2, Right: true},
+			{Header: "Win Rate", Weight: 1, Right: true},
+		}, rows, nil)
+	}
+
+	return r.bytes()
+}
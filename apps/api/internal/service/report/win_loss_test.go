@@ -0,0 +1,129 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	pipelinedomain "github.com/gilabs/crm-healthcare/api/internal/domain/pipeline"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/report"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/xuri/excelize/v2"
+)
+
+type fakeClosedDealRepo struct {
+	interfaces.DealRepository
+	deals      []pipelinedomain.Deal
+	start, end time.Time
+}
+
+func (r *fakeClosedDealRepo) ListClosed(start, end time.Time, pipelineID, assignedTo string) ([]pipelinedomain.Deal, error) {
+	r.start, r.end = start, end
+	return r.deals, nil
+}
+
+// fakeStatusDealItemRepo returns the product revenue rows of the requested deal status
+type fakeStatusDealItemRepo struct {
+	interfaces.DealItemRepository
+	rows map[string][]pipelinedomain.ProductRevenue
+}
+
+func (r *fakeStatusDealItemRepo) ProductRevenue(req *pipelinedomain.ProductRevenueRequest) ([]pipelinedomain.ProductRevenue, error) {
+	return r.rows[req.Status], nil
+}
+
+func TestGetWinLossReport(t *testing.T) {
+	price := &pipelinedomain.LossReasonRef{ID: "price", Name: "Price"}
+	acme := &pipelinedomain.CompetitorRef{ID: "acme", Name: "Acme Medical"}
+	proposal := &pipelinedomain.PipelineStage{ID: "proposal", Name: "Proposal"}
+	budi := &pipelinedomain.UserRef{ID: "budi", Name: "Budi"}
+	sari := &pipelinedomain.UserRef{ID: "sari", Name: "Sari"}
+
+	dealRepo := &fakeClosedDealRepo{deals: []pipelinedomain.Deal{
+		{ID: "d1", Title: "Pumps", Status: "won", Value: 50000000, AssignedTo: "budi", AssignedUser: budi},
+		{ID: "d2", Title: "Monitors", Status: "lost", Value: 30000000, AssignedTo: "budi", AssignedUser: budi,
+			LostReason: price, Competitor: acme, LostFromStage: proposal, LostNotes: "Acme was 10% cheaper"},
+		{ID: "d3", Title: "Beds", Status: "lost", Value: 20000000, AssignedTo: "sari", AssignedUser: sari,
+			LostReason: price, LostFromStage: proposal},
+		{ID: "d4", Title: "Gloves", Status: "lost", Value: 10000000, AssignedTo: "sari", AssignedUser: sari},
+	}}
+	itemRepo := &fakeStatusDealItemRepo{rows: map[string][]pipelinedomain.ProductRevenue{
+		"won":  {{ProductID: "pump", ProductName: "Infusion Pump", Deals: 1, Revenue: 50000000}},
+		"lost": {{ProductID: "pump", ProductName: "Infusion Pump", Deals: 2, Revenue: 40000000}, {ProductID: "bed", ProductName: "Hospital Bed", Deals: 1, Revenue: 20000000}},
+	}}
+	s := &Service{dealRepo: dealRepo, dealItemRepo: itemRepo}
+
+	data, err := s.GetWinLossReport(&report.ReportRequest{StartDate: "2025-03-01", EndDate: "2025-03-31"})
+	if err != nil {
+		t.Fatalf("GetWinLossReport: %v", err)
+	}
+	if dealRepo.start.Format("2006-01-02") != "2025-03-01" || dealRepo.end.Format("2006-01-02") != "2025-03-31" {
+		t.Errorf("unexpected period %v to %v", dealRepo.start, dealRepo.end)
+	}
+
+	summary := data.Summary
+	if summary.ClosedDeals != 4 || summary.WonDeals != 1 || summary.LostDeals != 3 || summary.WonValue != 500000 ||
+		summary.LostValue != 600000 || summary.WinRate != 25 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	if len(data.ByReason) != 2 || data.ByReason[0].Name != "Price" || data.ByReason[0].Deals != 2 || data.ByReason[0].Value != 500000 {
+		t.Errorf("unexpected loss reasons %+v", data.ByReason)
+	}
+	if data.ByReason[1].Name != notSpecified || data.ByReason[1].ID != "" {
+		t.Errorf("expected the deal without a reason as not specified, got %+v", data.ByReason[1])
+	}
+	if len(data.ByCompetitor) != 2 || data.ByCompetitor[0].Name != notSpecified || data.ByCompetitor[0].Deals != 2 {
+		t.Errorf("unexpected competitors %+v", data.ByCompetitor)
+	}
+	if data.ByStage[0].Name != "Proposal" || data.ByStage[0].Deals != 2 {
+		t.Errorf("unexpected stages %+v", data.ByStage)
+	}
+
+	if len(data.BySalesRep) != 2 || data.BySalesRep[0].SalesRep.Name != "Sari" || data.BySalesRep[0].WinRate != 0 {
+		t.Fatalf("expected Sari with the most lost deals first, got %+v", data.BySalesRep)
+	}
+	if budiStat := data.BySalesRep[1]; budiStat.WonDeals != 1 || budiStat.LostDeals != 1 || budiStat.WinRate != 50 {
+		t.Errorf("unexpected sales rep stat %+v", budiStat)
+	}
+
+	if len(data.ByProduct) != 2 {
+		t.Fatalf("expected 2 products, got %+v", data.ByProduct)
+	}
+	pump := data.ByProduct[0]
+	if pump.ProductID != "pump" || pump.WonDeals != 1 || pump.LostDeals != 2 || pump.WonRevenue != 500000 ||
+		pump.LostRevenue != 400000 || fmt.Sprintf("%.2f", pump.WinRate) != "33.33" {
+		t.Errorf("unexpected product stat %+v", pump)
+	}
+
+	if len(data.LostDeals) != 3 || data.LostDeals[0].Competitor != "Acme Medical" || data.LostDeals[0].LostFromStage != "Proposal" {
+		t.Errorf("unexpected lost deals %+v", data.LostDeals)
+	}
+
+	csv := string(s.generateWinLossReportCSV(data))
+	if !strings.Contains(csv, "\"Price\",2,500000.00,66.67%\n") {
+		t.Errorf("expected the price reason in the CSV, got:\n%s", csv)
+	}
+
+	xlsx, err := s.generateWinLossReportExcel(data)
+	if err != nil {
+		t.Fatalf("generateWinLossReportExcel: %v", err)
+	}
+	f, err := excelize.OpenReader(bytes.NewReader(xlsx))
+	if err != nil {
+		t.Fatalf("open xlsx: %v", err)
+	}
+	defer f.Close()
+	if sheets := f.GetSheetList(); strings.Join(sheets, ",") != "Win Loss,Lost Deals,Products" {
+		t.Errorf("unexpected sheets %v", sheets)
+	}
+	if v, _ := f.GetCellValue("Lost Deals", "F5"); v != "Acme Medical" {
+		t.Errorf("expected the competitor of the first lost deal, got %q", v)
+	}
+
+	if _, err := s.generateWinLossReportPDF(data); err != nil {
+		t.Errorf("generateWinLossReportPDF: %v", err)
+	}
+}
@@ -0,0 +1,420 @@
+package report_subscription
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/report"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/report_subscription"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/cron"
+	"github.com/gilabs/crm-healthcare/api/pkg/mailer"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReportSubscriptionNotFound = errors.New("report subscription not found")
+	ErrInvalidSchedule            = errors.New("invalid schedule")
+	ErrAccountRequired            = errors.New("account_id is required for the account activity report")
+	ErrAccountNotFound            = errors.New("account not found")
+	ErrUserNotFound               = errors.New("user not found")
+	ErrMailerNotConfigured        = errors.New("email delivery is not configured")
+)
+
+// attachmentTypes are the MIME types of the report formats
+var attachmentTypes = map[string]string{
+	"csv":   "text/csv",
+	"excel": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"pdf":   "application/pdf",
+}
+
+// ReportExporter generates report files; implemented by the report service
+type ReportExporter interface {
+	Export(reportType string, req *report.ReportRequest, format string) ([]byte, string, error)
+}
+
+type Service struct {
+	subscriptionRepo interfaces.ReportSubscriptionRepository
+	accountRepo      interfaces.AccountRepository
+	userRepo         interfaces.UserRepository
+	exporter         ReportExporter
+	sender           mailer.Sender
+	now              func() time.Time
+}
+
+func NewService(
+	subscriptionRepo interfaces.ReportSubscriptionRepository,
+	accountRepo interfaces.AccountRepository,
+	userRepo interfaces.UserRepository,
+	exporter ReportExporter,
+) *Service {
+	return &Service{
+		subscriptionRepo: subscriptionRepo,
+		accountRepo:      accountRepo,
+		userRepo:         userRepo,
+		exporter:         exporter,
+		now:              time.Now,
+	}
+}
+
+// SetSender sets the mailer used to deliver reports; without one every delivery fails with ErrMailerNotConfigured
+func (s *Service) SetSender(sender mailer.Sender) {
+	s.sender = sender
+}
+
+// List returns a list of report subscriptions
+func (s *Service) List(req *report_subscription.ListReportSubscriptionsRequest) ([]report_subscription.ReportSubscriptionResponse, *PaginationResult, error) {
+	subscriptions, total, err := s.subscriptionRepo.List(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]report_subscription.ReportSubscriptionResponse, len(subscriptions))
+	for i := range subscriptions {
+		responses[i] = *subscriptions[i].ToReportSubscriptionResponse()
+	}
+
+	return responses, newPagination(req.Page, req.PerPage, total), nil
+}
+
+// GetByID returns a report subscription by ID
+func (s *Service) GetByID(id string) (*report_subscription.ReportSubscriptionResponse, error) {
+	sub, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	return sub.ToReportSubscriptionResponse(), nil
+}
+
+// Create creates a report subscription and schedules its first run
+func (s *Service) Create(req *report_subscription.CreateReportSubscriptionRequest, createdBy string) (*report_subscription.ReportSubscriptionResponse, error) {
+	sub := &report_subscription.ReportSubscription{
+		Name:        strings.TrimSpace(req.Name),
+		ReportType:  req.ReportType,
+		Format:      req.Format,
+		Period:      req.Period,
+		AccountID:   emptyToNil(req.AccountID),
+		SalesRepID:  emptyToNil(req.SalesRepID),
+		VisitStatus: req.VisitStatus,
+		Recipients:  report_subscription.JoinList(req.Recipients),
+		Schedule:    strings.TrimSpace(req.Schedule),
+		IsActive:    true,
+		CreatedBy:   createdBy,
+	}
+	if req.IsActive != nil {
+		sub.IsActive = *req.IsActive
+	}
+
+	if err := s.validate(sub); err != nil {
+		return nil, err
+	}
+	if err := s.schedule(sub); err != nil {
+		return nil, err
+	}
+
+	if err := s.subscriptionRepo.Create(sub); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(sub.ID)
+}
+
+// Update updates a report subscription; changing the schedule or reactivating it schedules the next run from now
+func (s *Service) Update(id string, req *report_subscription.UpdateReportSubscriptionRequest) (*report_subscription.ReportSubscriptionResponse, error) {
+	sub, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		sub.Name = strings.TrimSpace(req.Name)
+	}
+	if req.Format != "" {
+		sub.Format = req.Format
+	}
+	if req.Period != "" {
+		sub.Period = req.Period
+	}
+	if req.AccountID != nil {
+		sub.AccountID = emptyToNil(req.AccountID)
+	}
+	if req.SalesRepID != nil {
+		sub.SalesRepID = emptyToNil(req.SalesRepID)
+	}
+	if req.VisitStatus != nil {
+		sub.VisitStatus = *req.VisitStatus
+	}
+	if req.Recipients != nil {
+		sub.Recipients = report_subscription.JoinList(req.Recipients)
+	}
+	reschedule := false
+	if schedule := strings.TrimSpace(req.Schedule); schedule != "" && schedule != sub.Schedule {
+		sub.Schedule = schedule
+		reschedule = true
+	}
+	if req.IsActive != nil && *req.IsActive != sub.IsActive {
+		sub.IsActive = *req.IsActive
+		reschedule = true
+	}
+
+	if err := s.validate(sub); err != nil {
+		return nil, err
+	}
+	if reschedule {
+		if err := s.schedule(sub); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.subscriptionRepo.Update(sub); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(sub.ID)
+}
+
+// Delete deletes a report subscription; its delivery history is kept
+func (s *Service) Delete(id string) error {
+	if _, err := s.find(id); err != nil {
+		return err
+	}
+	return s.subscriptionRepo.Delete(id)
+}
+
+// ListDeliveries returns the delivery history of a report subscription
+func (s *Service) ListDeliveries(id string, req *report_subscription.ListReportDeliveriesRequest) ([]report_subscription.ReportDeliveryResponse, *PaginationResult, error) {
+	if _, err := s.find(id); err != nil {
+		return nil, nil, err
+	}
+
+	deliveries, total, err := s.subscriptionRepo.ListDeliveries(id, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]report_subscription.ReportDeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		responses[i] = *deliveries[i].ToReportDeliveryResponse()
+	}
+
+	return responses, newPagination(req.Page, req.PerPage, total), nil
+}
+
+// SendNow delivers a subscription immediately without changing its schedule. The delivery is returned
+// even when it failed; its status and error say why.
+func (s *Service) SendNow(id string, userID string) (*report_subscription.ReportDeliveryResponse, error) {
+	sub, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var triggeredBy *string
+	if userID != "" {
+		triggeredBy = &userID
+	}
+	return s.deliver(sub, report_subscription.TriggerManual, triggeredBy).ToReportDeliveryResponse(), nil
+}
+
+// RunDue delivers every subscription whose next run has come and returns how many were delivered.
+// Each run is claimed first, so several API instances can run the worker without sending duplicates.
+// A run missed while the server was down is delivered once, then the schedule continues from now.
+func (s *Service) RunDue() (int, error) {
+	now := s.now()
+	subscriptions, err := s.subscriptionRepo.FindDue(now)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range subscriptions {
+		sub := &subscriptions[i]
+		claimed, err := s.subscriptionRepo.ClaimRun(sub.ID, *sub.NextRunAt, nextRun(sub.Schedule, now))
+		if err != nil {
+			log.Printf("Warning: Failed to claim run of report subscription %s: %v", sub.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		s.deliver(sub, report_subscription.TriggerSchedule, nil)
+		delivered++
+	}
+	return delivered, nil
+}
+
+// deliver generates the report, emails it and records the delivery
+func (s *Service) deliver(sub *report_subscription.ReportSubscription, trigger string, triggeredBy *string) *report_subscription.ReportDelivery {
+	now := s.now()
+	start, end := report_subscription.PeriodRange(sub.Period, now.In(response.GetTimezoneWIB()))
+	delivery := &report_subscription.ReportDelivery{
+		SubscriptionID: sub.ID,
+		Trigger:        trigger,
+		Status:         report_subscription.DeliveryStatusSent,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		Recipients:     sub.Recipients,
+		TriggeredBy:    triggeredBy,
+		CreatedAt:      now,
+	}
+
+	if err := s.send(sub, delivery); err != nil {
+		delivery.Status = report_subscription.DeliveryStatusFailed
+		delivery.Error = err.Error()
+		log.Printf("Warning: Report subscription %s was not delivered: %v", sub.ID, err)
+	}
+
+	if err := s.subscriptionRepo.RecordDelivery(delivery); err != nil {
+		log.Printf("Warning: Failed to record delivery of report subscription %s: %v", sub.ID, err)
+	}
+	return delivery
+}
+
+func (s *Service) send(sub *report_subscription.ReportSubscription, delivery *report_subscription.ReportDelivery) error {
+	if s.sender == nil {
+		return ErrMailerNotConfigured
+	}
+
+	req := &report.ReportRequest{
+		StartDate: delivery.PeriodStart.Format("2006-01-02"),
+		EndDate:   delivery.PeriodEnd.Format("2006-01-02"),
+		Status:    sub.VisitStatus,
+	}
+	if sub.AccountID != nil {
+		req.AccountID = *sub.AccountID
+	}
+	if sub.SalesRepID != nil {
+		req.SalesRepID = *sub.SalesRepID
+	}
+	data, filename, err := s.exporter.Export(sub.ReportType, req, sub.Format)
+	if err != nil {
+		return fmt.Errorf("generate report: %w", err)
+	}
+
+	// pipeline-report-export.pdf becomes pipeline-report_2025-03-03_2025-03-09.pdf
+	ext := path.Ext(filename)
+	delivery.FileName = fmt.Sprintf("%s_%s_%s%s", strings.TrimSuffix(strings.TrimSuffix(filename, ext), "-export"), req.StartDate, req.EndDate, ext)
+	delivery.FileSize = len(data)
+
+	period := formatDate(delivery.PeriodStart)
+	if !delivery.PeriodEnd.Equal(delivery.PeriodStart) {
+		period += " to " + formatDate(delivery.PeriodEnd)
+	}
+	title := report.TypeTitles[sub.ReportType]
+
+	return s.sender.Send(&mailer.Message{
+		To:      sub.RecipientList(),
+		Subject: fmt.Sprintf("%s: %s, %s", sub.Name, title, period),
+		Body: fmt.Sprintf("Hello,\n\nPlease find attached the %s for %s.\n\n"+
+			"This email is sent automatically by the %q report subscription. "+
+			"Contact your CRM administrator to change or stop it.\n", title, period, sub.Name),
+		Attachments: []mailer.Attachment{
+			{Filename: delivery.FileName, ContentType: attachmentTypes[sub.Format], Data: data},
+		},
+	})
+}
+
+// validate checks the filters of a subscription
+func (s *Service) validate(sub *report_subscription.ReportSubscription) error {
+	if sub.ReportType == report.TypeAccountActivity && sub.AccountID == nil {
+		return ErrAccountRequired
+	}
+	if sub.AccountID != nil {
+		if _, err := s.accountRepo.FindByID(*sub.AccountID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAccountNotFound
+			}
+			return err
+		}
+	}
+	if sub.SalesRepID != nil {
+		if _, err := s.userRepo.FindByID(*sub.SalesRepID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// schedule validates the cron expression and sets the next run; inactive subscriptions have none
+func (s *Service) schedule(sub *report_subscription.ReportSubscription) error {
+	if _, err := cron.Parse(sub.Schedule); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	sub.NextRunAt = nil
+	if sub.IsActive {
+		sub.NextRunAt = nextRun(sub.Schedule, s.now())
+		if sub.NextRunAt == nil {
+			return fmt.Errorf("%w: the schedule never runs", ErrInvalidSchedule)
+		}
+	}
+	return nil
+}
+
+// nextRun returns the first run of the schedule in WIB after now, in UTC; nil when it never runs
+func nextRun(schedule string, now time.Time) *time.Time {
+	parsed, err := cron.Parse(schedule)
+	if err != nil {
+		return nil
+	}
+	next := parsed.Next(now.In(response.GetTimezoneWIB()))
+	if next.IsZero() {
+		return nil
+	}
+	next = next.UTC()
+	return &next
+}
+
+func (s *Service) find(id string) (*report_subscription.ReportSubscription, error) {
+	sub, err := s.subscriptionRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportSubscriptionNotFound
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
+func emptyToNil(value *string) *string {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil
+	}
+	return value
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("02 Jan 2006")
+}
+
+func newPagination(page int, perPage int, total int64) *PaginationResult {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+	return &PaginationResult{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      int(total),
+		TotalPages: int((total + int64(perPage) - 1) / int64(perPage)),
+	}
+}
+
+// PaginationResult represents pagination information
+type PaginationResult struct {
+	Page       int
+	PerPage    int
+	Total      int
+	TotalPages int
+}
@@ -0,0 +1,261 @@
+package report_subscription
+
+import (
+	"bytes"
+	"errors"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/account"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/report"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/report_subscription"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/mailer"
+	"github.com/gilabs/crm-healthcare/api/pkg/mailer/mailertest"
+	"gorm.io/gorm"
+)
+
+type fakeSubscriptionRepo struct {
+	interfaces.ReportSubscriptionRepository
+	subscriptions []*report_subscription.ReportSubscription
+	deliveries    []*report_subscription.ReportDelivery
+	claimed       map[string]bool // runs claimed by another worker
+}
+
+func (r *fakeSubscriptionRepo) FindByID(id string) (*report_subscription.ReportSubscription, error) {
+	for _, s := range r.subscriptions {
+		if s.ID == id {
+			copied := *s
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeSubscriptionRepo) FindDue(now time.Time) ([]report_subscription.ReportSubscription, error) {
+	var due []report_subscription.ReportSubscription
+	for _, s := range r.subscriptions {
+		if s.IsActive && s.NextRunAt != nil && !s.NextRunAt.After(now) {
+			due = append(due, *s)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeSubscriptionRepo) Create(s *report_subscription.ReportSubscription) error {
+	s.ID = "subscription-" + s.Name
+	copied := *s
+	r.subscriptions = append(r.subscriptions, &copied)
+	return nil
+}
+
+func (r *fakeSubscriptionRepo) ClaimRun(id string, scheduledAt time.Time, nextRunAt *time.Time) (bool, error) {
+	if r.claimed[id] {
+		return false, nil
+	}
+	for _, s := range r.subscriptions {
+		if s.ID == id && s.NextRunAt != nil && s.NextRunAt.Equal(scheduledAt) {
+			s.NextRunAt = nextRunAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeSubscriptionRepo) RecordDelivery(d *report_subscription.ReportDelivery) error {
+	r.deliveries = append(r.deliveries, d)
+	return nil
+}
+
+type fakeAccountRepo struct {
+	interfaces.AccountRepository
+}
+
+func (r *fakeAccountRepo) FindByID(id string) (*account.Account, error) {
+	if id == "account-1" {
+		return &account.Account{ID: id, Name: "RS Sehat"}, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakeExporter struct {
+	requests []report.ReportRequest
+}
+
+func (e *fakeExporter) Export(reportType string, req *report.ReportRequest, format string) ([]byte, string, error) {
+	if _, ok := report.TypeTitles[reportType]; !ok {
+		return nil, "", errors.New("unknown report type")
+	}
+	e.requests = append(e.requests, *req)
+	return []byte("%PDF-1.3 " + reportType), strings.ReplaceAll(reportType, "_", "-") + "-report-export.pdf", nil
+}
+
+func newTestService(t *testing.T, now time.Time) (*Service, *fakeSubscriptionRepo, *fakeExporter, *mailertest.Server) {
+	t.Helper()
+	repo := &fakeSubscriptionRepo{claimed: map[string]bool{}}
+	exporter := &fakeExporter{}
+	server := mailertest.NewServer(t)
+
+	svc := NewService(repo, &fakeAccountRepo{}, nil, exporter)
+	svc.SetSender(mailer.NewSMTPSender(mailer.SMTPConfig{
+		Host: server.Host,
+		Port: server.Port,
+		From: "reports@crm.example.com",
+	}))
+	svc.now = func() time.Time { return now }
+	return svc, repo, exporter, server
+}
+
+func weeklyPipeline(nextRunAt time.Time) *report_subscription.ReportSubscription {
+	return &report_subscription.ReportSubscription{
+		ID:         "subscription-weekly",
+		Name:       "Weekly pipeline",
+		ReportType: report.TypePipeline,
+		Format:     "pdf",
+		Period:     report_subscription.PeriodLastWeek,
+		Recipients: "budi@example.com,sari@example.com",
+		Schedule:   "0 7 * * MON",
+		IsActive:   true,
+		NextRunAt:  &nextRunAt,
+	}
+}
+
+func TestRunDue_DeliversAndSchedulesNextRun(t *testing.T) {
+	// Monday 10 March 2025, 07:00:30 WIB
+	now := time.Date(2025, 3, 10, 0, 0, 30, 0, time.UTC)
+	svc, repo, exporter, server := newTestService(t, now)
+	repo.subscriptions = append(repo.subscriptions, weeklyPipeline(time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)))
+
+	delivered, err := svc.RunDue()
+	if err != nil || delivered != 1 {
+		t.Fatalf("RunDue = %d, %v; want 1 delivery", delivered, err)
+	}
+
+	if len(exporter.requests) != 1 || exporter.requests[0].StartDate != "2025-03-03" || exporter.requests[0].EndDate != "2025-03-09" {
+		t.Fatalf("expected last week's report, got %+v", exporter.requests)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 || strings.Join(messages[0].To, ",") != "budi@example.com,sari@example.com" {
+		t.Fatalf("expected one email to both recipients, got %+v", messages)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(messages[0].Data))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	if subject := msg.Header.Get("Subject"); subject != "Weekly pipeline: Pipeline Report, 03 Mar 2025 to 09 Mar 2025" {
+		t.Errorf("unexpected subject %q", subject)
+	}
+	if !bytes.Contains(messages[0].Data, []byte("pipeline-report_2025-03-03_2025-03-09.pdf")) {
+		t.Error("expected the attachment to be named after the period")
+	}
+
+	if len(repo.deliveries) != 1 {
+		t.Fatalf("expected 1 delivery, got %d", len(repo.deliveries))
+	}
+	d := repo.deliveries[0]
+	if d.Status != report_subscription.DeliveryStatusSent || d.Trigger != report_subscription.TriggerSchedule || d.FileSize == 0 {
+		t.Errorf("unexpected delivery %+v", d)
+	}
+
+	// Next Monday 07:00 WIB
+	if next := repo.subscriptions[0].NextRunAt; next == nil || !next.Equal(time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected next run %v", next)
+	}
+
+	delivered, _ = svc.RunDue()
+	if delivered != 0 || len(server.Messages()) != 1 {
+		t.Errorf("expected no second delivery, got %d", delivered)
+	}
+}
+
+func TestRunDue_SkipsRunClaimedElsewhere(t *testing.T) {
+	now := time.Date(2025, 3, 10, 0, 0, 30, 0, time.UTC)
+	svc, repo, _, server := newTestService(t, now)
+	repo.subscriptions = append(repo.subscriptions, weeklyPipeline(time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)))
+	repo.claimed["subscription-weekly"] = true
+
+	delivered, err := svc.RunDue()
+	if err != nil || delivered != 0 {
+		t.Fatalf("RunDue = %d, %v; want no delivery", delivered, err)
+	}
+	if len(server.Messages()) != 0 || len(repo.deliveries) != 0 {
+		t.Error("a run claimed by another worker must not be sent")
+	}
+}
+
+func TestSendNow_RecordsFailedDelivery(t *testing.T) {
+	now := time.Date(2025, 3, 12, 3, 0, 0, 0, time.UTC)
+	svc, repo, _, server := newTestService(t, now)
+	sub := weeklyPipeline(time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC))
+	repo.subscriptions = append(repo.subscriptions, sub)
+	server.Reject("554 mailbox unavailable")
+
+	delivery, err := svc.SendNow(sub.ID, "user-1")
+	if err != nil {
+		t.Fatalf("SendNow: %v", err)
+	}
+	if delivery.Status != report_subscription.DeliveryStatusFailed || !strings.Contains(delivery.Error, "mailbox unavailable") {
+		t.Errorf("expected a failed delivery, got %+v", delivery)
+	}
+	if delivery.Trigger != report_subscription.TriggerManual || len(repo.deliveries) != 1 {
+		t.Errorf("expected a recorded manual delivery, got %+v", repo.deliveries)
+	}
+	if !repo.subscriptions[0].NextRunAt.Equal(time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC)) {
+		t.Error("sending now must not change the schedule")
+	}
+
+	svc.SetSender(nil)
+	delivery, _ = svc.SendNow(sub.ID, "user-1")
+	if delivery.Error != ErrMailerNotConfigured.Error() {
+		t.Errorf("expected ErrMailerNotConfigured, got %q", delivery.Error)
+	}
+}
+
+func TestCreate_SchedulesFirstRunInWIB(t *testing.T) {
+	// Sunday 9 March 2025, 23:30 WIB
+	now := time.Date(2025, 3, 9, 16, 30, 0, 0, time.UTC)
+	svc, _, _, _ := newTestService(t, now)
+
+	created, err := svc.Create(&report_subscription.CreateReportSubscriptionRequest{
+		Name:       "daily",
+		ReportType: report.TypeVisitReports,
+		Format:     "csv",
+		Period:     report_subscription.PeriodYesterday,
+		Recipients: []string{"budi@example.com"},
+		Schedule:   "30 6 * * *",
+	}, "user-1")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	// 06:30 WIB on Monday
+	if created.NextRunAt == nil || !created.NextRunAt.Equal(time.Date(2025, 3, 9, 23, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected next run %v", created.NextRunAt)
+	}
+
+	_, err = svc.Create(&report_subscription.CreateReportSubscriptionRequest{
+		Name:       "bad",
+		ReportType: report.TypeVisitReports,
+		Format:     "csv",
+		Period:     report_subscription.PeriodYesterday,
+		Recipients: []string{"budi@example.com"},
+		Schedule:   "every monday",
+	}, "user-1")
+	if !errors.Is(err, ErrInvalidSchedule) {
+		t.Errorf("expected ErrInvalidSchedule, got %v", err)
+	}
+
+	_, err = svc.Create(&report_subscription.CreateReportSubscriptionRequest{
+		Name:       "account",
+		ReportType: report.TypeAccountActivity,
+		Format:     "pdf",
+		Period:     report_subscription.PeriodLastMonth,
+		Recipients: []string{"budi@example.com"},
+		Schedule:   "@monthly",
+	}, "user-1")
+	if !errors.Is(err, ErrAccountRequired) {
+		t.Errorf("expected ErrAccountRequired, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package role
 import (
 	"errors"
 
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	roledomain "github.com/gilabs/crm-healthcare/api/internal/domain/role"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
 	"gorm.io/gorm"
@@ -14,12 +15,31 @@ var (
 )
 
 type Service struct {
-	roleRepo interfaces.RoleRepository
+	roleRepo        interfaces.RoleRepository
+	permissionCache PermissionCacheInterface
+}
+
+// PermissionCacheInterface defines interface for the role permission cache used by RequirePermission
+type PermissionCacheInterface interface {
+	InvalidateRolePermissions()
 }
 
 func NewService(roleRepo interfaces.RoleRepository) *Service {
 	return &Service{
-		roleRepo: roleRepo,
+		roleRepo:        roleRepo,
+		permissionCache: nil, // Will be set via SetPermissionCache if needed
+	}
+}
+
+// SetPermissionCache sets the permission cache invalidated when role permissions change
+func (s *Service) SetPermissionCache(cache PermissionCacheInterface) {
+	s.permissionCache = cache
+}
+
+// invalidatePermissionCache drops cached role permissions after a role change
+func (s *Service) invalidatePermissionCache() {
+	if s.permissionCache != nil {
+		s.permissionCache.InvalidateRolePermissions()
 	}
 }
 
@@ -73,6 +93,12 @@ func (s *Service) Create(req *roledomain.CreateRoleRequest) (*roledomain.RoleRes
 		mobileAccess = *req.MobileAccess
 	}
 
+	// Set default data scope
+	dataScope := req.DataScope
+	if dataScope == "" {
+		dataScope = datascope.LevelAll
+	}
+
 	// Create role
 	r := &roledomain.Role{
 		Name:        req.Name,
@@ -80,6 +106,7 @@ func (s *Service) Create(req *roledomain.CreateRoleRequest) (*roledomain.RoleRes
 		Description: req.Description,
 		Status:      status,
 		MobileAccess: mobileAccess,
+		DataScope:   dataScope,
 	}
 
 	if err := s.roleRepo.Create(r); err != nil {
@@ -135,9 +162,14 @@ func (s *Service) Update(id string, req *roledomain.UpdateRoleRequest) (*roledom
 		r.MobileAccess = *req.MobileAccess
 	}
 
+	if req.DataScope != "" {
+		r.DataScope = req.DataScope
+	}
+
 	if err := s.roleRepo.Update(r); err != nil {
 		return nil, err
 	}
+	s.invalidatePermissionCache()
 
 	// Reload with permissions
 	updatedRole, err := s.roleRepo.FindByID(r.ID)
@@ -159,7 +191,11 @@ func (s *Service) Delete(id string) error {
 		return err
 	}
 
-	return s.roleRepo.Delete(id)
+	if err := s.roleRepo.Delete(id); err != nil {
+		return err
+	}
+	s.invalidatePermissionCache()
+	return nil
 }
 
 // AssignPermissions assigns permissions to a role
@@ -173,7 +209,11 @@ func (s *Service) AssignPermissions(roleID string, permissionIDs []string) error
 		return err
 	}
 
-	return s.roleRepo.AssignPermissions(roleID, permissionIDs)
+	if err := s.roleRepo.AssignPermissions(roleID, permissionIDs); err != nil {
+		return err
+	}
+	s.invalidatePermissionCache()
+	return nil
 }
 
 // GetMobilePermissions returns mobile permissions for a role
@@ -201,6 +241,9 @@ func (s *Service) UpdateMobilePermissions(roleID string, req *roledomain.UpdateM
 		return err
 	}
 
-	return s.roleRepo.UpdateMobilePermissions(roleID, req)
+	if err := s.roleRepo.UpdateMobilePermissions(roleID, req); err != nil {
+		return err
+	}
+	s.invalidatePermissionCache()
+	return nil
 }
-
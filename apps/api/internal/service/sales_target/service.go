@@ -0,0 +1,246 @@
+package sales_target
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/sales_target"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/user"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSalesTargetNotFound      = errors.New("sales target not found")
+	ErrSalesTargetAlreadyExists = errors.New("sales target already exists for this owner and period")
+	ErrInvalidPeriod            = errors.New("invalid target period")
+	ErrUserNotFound             = errors.New("user not found")
+	ErrTeamNotFound             = errors.New("team not found")
+)
+
+type Service struct {
+	targetRepo interfaces.SalesTargetRepository
+	userRepo   interfaces.UserRepository
+	teamRepo   interfaces.TeamRepository
+}
+
+func NewService(targetRepo interfaces.SalesTargetRepository, userRepo interfaces.UserRepository, teamRepo interfaces.TeamRepository) *Service {
+	return &Service{
+		targetRepo: targetRepo,
+		userRepo:   userRepo,
+		teamRepo:   teamRepo,
+	}
+}
+
+// List returns a list of sales targets with their attainment
+func (s *Service) List(req *sales_target.ListSalesTargetsRequest) ([]sales_target.SalesTargetResponse, *PaginationResult, error) {
+	targets, total, err := s.targetRepo.List(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]sales_target.SalesTargetResponse, len(targets))
+	for i := range targets {
+		resp, err := s.toResponse(&targets[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		responses[i] = *resp
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	pagination := &PaginationResult{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      int(total),
+		TotalPages: int((total + int64(perPage) - 1) / int64(perPage)),
+	}
+
+	return responses, pagination, nil
+}
+
+// GetByID returns a sales target with its attainment
+func (s *Service) GetByID(id string) (*sales_target.SalesTargetResponse, error) {
+	t, err := s.targetRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSalesTargetNotFound
+		}
+		return nil, err
+	}
+
+	return s.toResponse(t)
+}
+
+// Create creates a sales target; each owner has at most one target per period
+func (s *Service) Create(req *sales_target.CreateSalesTargetRequest, createdBy string) (*sales_target.SalesTargetResponse, error) {
+	switch req.PeriodType {
+	case sales_target.PeriodMonth:
+		if req.Period < 1 || req.Period > 12 {
+			return nil, ErrInvalidPeriod
+		}
+	case sales_target.PeriodQuarter:
+		if req.Period < 1 || req.Period > 4 {
+			return nil, ErrInvalidPeriod
+		}
+	}
+	periodStart, periodEnd := sales_target.PeriodBounds(req.PeriodType, req.Year, req.Period)
+
+	t := &sales_target.SalesTarget{
+		Level:         req.Level,
+		PeriodType:    req.PeriodType,
+		PeriodStart:   periodStart,
+		PeriodEnd:     periodEnd,
+		RevenueTarget: req.RevenueTarget,
+		VisitTarget:   req.VisitTarget,
+		Notes:         req.Notes,
+		CreatedBy:     createdBy,
+	}
+
+	ownerID := ""
+	switch req.Level {
+	case sales_target.LevelUser:
+		if _, err := s.userRepo.FindByID(*req.UserID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrUserNotFound
+			}
+			return nil, err
+		}
+		ownerID = *req.UserID
+		t.UserID = req.UserID
+	case sales_target.LevelTeam:
+		if _, err := s.teamRepo.FindByID(*req.TeamID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrTeamNotFound
+			}
+			return nil, err
+		}
+		ownerID = *req.TeamID
+		t.TeamID = req.TeamID
+	}
+
+	_, err := s.targetRepo.FindByOwnerAndPeriod(req.Level, ownerID, req.PeriodType, periodStart)
+	if err == nil {
+		return nil, ErrSalesTargetAlreadyExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := s.targetRepo.Create(t); err != nil {
+		// A target created concurrently for the same owner and period is rejected by the unique index
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrSalesTargetAlreadyExists
+		}
+		return nil, err
+	}
+
+	return s.GetByID(t.ID)
+}
+
+// Update updates the target values of a sales target
+func (s *Service) Update(id string, req *sales_target.UpdateSalesTargetRequest) (*sales_target.SalesTargetResponse, error) {
+	t, err := s.targetRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSalesTargetNotFound
+		}
+		return nil, err
+	}
+
+	if req.RevenueTarget != nil {
+		t.RevenueTarget = *req.RevenueTarget
+	}
+	if req.VisitTarget != nil {
+		t.VisitTarget = *req.VisitTarget
+	}
+	if req.Notes != nil {
+		t.Notes = *req.Notes
+	}
+
+	if err := s.targetRepo.Update(t); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(t.ID)
+}
+
+// Delete deletes a sales target
+func (s *Service) Delete(id string) error {
+	if _, err := s.targetRepo.FindByID(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSalesTargetNotFound
+		}
+		return err
+	}
+
+	return s.targetRepo.Delete(id)
+}
+
+// toResponse adds owner names and the attainment so far to a sales target
+func (s *Service) toResponse(t *sales_target.SalesTarget) (*sales_target.SalesTargetResponse, error) {
+	resp := t.ToSalesTargetResponse()
+
+	var userIDs []string // nil counts every user, for company targets
+	switch t.Level {
+	case sales_target.LevelUser:
+		if t.UserID != nil {
+			userIDs = []string{*t.UserID}
+			if u, err := s.userRepo.FindByID(*t.UserID); err == nil {
+				resp.UserName = u.Name
+			}
+		}
+	case sales_target.LevelTeam:
+		if t.TeamID != nil {
+			if tm, err := s.teamRepo.FindByID(*t.TeamID); err == nil {
+				resp.TeamName = tm.Name
+			}
+			members, _, err := s.userRepo.List(&user.ListUsersRequest{TeamID: *t.TeamID, Page: 1, PerPage: 10000})
+			if err != nil {
+				return nil, err
+			}
+			userIDs = make([]string, len(members))
+			for i, m := range members {
+				userIDs[i] = m.ID
+			}
+		}
+	}
+
+	// Achievement counts up to today for running periods
+	achievedTo := t.PeriodEnd
+	if today := time.Now(); today.Before(achievedTo) {
+		achievedTo = today
+	}
+	var revenue int64
+	var visits int
+	if !achievedTo.Before(t.PeriodStart) {
+		achievements, err := s.targetRepo.AchievementByUser(t.PeriodStart, achievedTo)
+		if err != nil {
+			return nil, err
+		}
+		revenue, visits = sales_target.AchievementFor(achievements, userIDs)
+	}
+
+	attainment := sales_target.NewAttainment(t.RevenueTarget, revenue, t.VisitTarget, visits)
+	resp.Attainment = &attainment
+	return resp, nil
+}
+
+// PaginationResult represents pagination information
+type PaginationResult struct {
+	Page       int
+	PerPage    int
+	Total      int
+	TotalPages int
+}
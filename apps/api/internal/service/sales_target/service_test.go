@@ -0,0 +1,186 @@
+package sales_target
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/sales_target"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/team"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/user"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+type fakeTargetRepo struct {
+	interfaces.SalesTargetRepository
+	targets      []*sales_target.SalesTarget
+	achievements []sales_target.UserAchievement
+	raced        bool // Create fails like a target inserted concurrently for the same owner and period
+}
+
+func (r *fakeTargetRepo) FindByID(id string) (*sales_target.SalesTarget, error) {
+	for _, t := range r.targets {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeTargetRepo) FindByOwnerAndPeriod(level string, ownerID string, periodType string, periodStart time.Time) (*sales_target.SalesTarget, error) {
+	for _, t := range r.targets {
+		owner := ""
+		if t.UserID != nil {
+			owner = *t.UserID
+		} else if t.TeamID != nil {
+			owner = *t.TeamID
+		}
+		if t.Level == level && owner == ownerID && t.PeriodType == periodType && t.PeriodStart.Equal(periodStart) {
+			return t, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeTargetRepo) Create(t *sales_target.SalesTarget) error {
+	if r.raced {
+		return gorm.ErrDuplicatedKey
+	}
+	t.ID = "target-" + t.PeriodStart.Format("2006-01-02")
+	r.targets = append(r.targets, t)
+	return nil
+}
+
+func (r *fakeTargetRepo) AchievementByUser(from time.Time, to time.Time) ([]sales_target.UserAchievement, error) {
+	return r.achievements, nil
+}
+
+type fakeUserRepo struct {
+	interfaces.UserRepository
+	users []user.User
+}
+
+func (r *fakeUserRepo) FindByID(id string) (*user.User, error) {
+	for i := range r.users {
+		if r.users[i].ID == id {
+			return &r.users[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) List(req *user.ListUsersRequest) ([]user.User, int64, error) {
+	var found []user.User
+	for _, u := range r.users {
+		if u.TeamID != nil && *u.TeamID == req.TeamID {
+			found = append(found, u)
+		}
+	}
+	return found, int64(len(found)), nil
+}
+
+type fakeTeamRepo struct {
+	interfaces.TeamRepository
+}
+
+func (r *fakeTeamRepo) FindByID(id string) (*team.Team, error) {
+	if id != "team-1" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &team.Team{ID: id, Name: "North"}, nil
+}
+
+func TestTargetForUsesFinestPeriodProrated(t *testing.T) {
+	janStart, janEnd := sales_target.PeriodBounds(sales_target.PeriodMonth, 2025, 1)
+	yearStart, yearEnd := sales_target.PeriodBounds(sales_target.PeriodYear, 2025, 0)
+	targets := []sales_target.SalesTarget{
+		{Level: sales_target.LevelCompany, PeriodType: sales_target.PeriodYear, PeriodStart: yearStart, PeriodEnd: yearEnd, RevenueTarget: 1200, VisitTarget: 120},
+		{Level: sales_target.LevelCompany, PeriodType: sales_target.PeriodMonth, PeriodStart: janStart, PeriodEnd: janEnd, RevenueTarget: 310, VisitTarget: 31},
+	}
+
+	// The monthly target wins over the yearly one and is prorated to 10 of 31 days
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 10, 23, 59, 0, 0, time.UTC)
+	revenue, visits, ok := sales_target.TargetFor(targets, sales_target.LevelCompany, "", from, to)
+	if !ok || revenue != 100 || visits != 10 {
+		t.Fatalf("expected prorated monthly target 100/10, got %d/%d (ok=%v)", revenue, visits, ok)
+	}
+
+	if _, _, ok := sales_target.TargetFor(targets, sales_target.LevelUser, "user-1", from, to); ok {
+		t.Fatal("expected no user target")
+	}
+}
+
+func TestCreateRejectsInvalidAndDuplicatePeriods(t *testing.T) {
+	svc := NewService(&fakeTargetRepo{}, &fakeUserRepo{}, &fakeTeamRepo{})
+
+	_, err := svc.Create(&sales_target.CreateSalesTargetRequest{
+		Level: sales_target.LevelCompany, PeriodType: sales_target.PeriodQuarter, Year: 2025, Period: 5,
+	}, "admin")
+	if !errors.Is(err, ErrInvalidPeriod) {
+		t.Fatalf("expected ErrInvalidPeriod, got %v", err)
+	}
+
+	req := &sales_target.CreateSalesTargetRequest{
+		Level: sales_target.LevelCompany, PeriodType: sales_target.PeriodQuarter, Year: 2025, Period: 2, RevenueTarget: 5000,
+	}
+	resp, err := svc.Create(req, "admin")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if resp.PeriodStart.Format("2006-01-02") != "2025-04-01" || resp.PeriodEnd.Format("2006-01-02") != "2025-06-30" {
+		t.Errorf("unexpected period %s - %s", resp.PeriodStart, resp.PeriodEnd)
+	}
+
+	if _, err := svc.Create(req, "admin"); !errors.Is(err, ErrSalesTargetAlreadyExists) {
+		t.Fatalf("expected ErrSalesTargetAlreadyExists, got %v", err)
+	}
+}
+
+func TestCreateReportsConcurrentDuplicate(t *testing.T) {
+	svc := NewService(&fakeTargetRepo{raced: true}, &fakeUserRepo{}, &fakeTeamRepo{})
+
+	_, err := svc.Create(&sales_target.CreateSalesTargetRequest{
+		Level: sales_target.LevelCompany, PeriodType: sales_target.PeriodYear, Year: 2025, Period: 1, RevenueTarget: 5000,
+	}, "admin")
+	if !errors.Is(err, ErrSalesTargetAlreadyExists) {
+		t.Fatalf("expected ErrSalesTargetAlreadyExists, got %v", err)
+	}
+}
+
+func TestGetByIDComputesTeamAttainment(t *testing.T) {
+	teamID := "team-1"
+	start, end := sales_target.PeriodBounds(sales_target.PeriodMonth, 2025, 3)
+	targetRepo := &fakeTargetRepo{
+		targets: []*sales_target.SalesTarget{
+			{ID: "t1", Level: sales_target.LevelTeam, TeamID: &teamID, PeriodType: sales_target.PeriodMonth, PeriodStart: start, PeriodEnd: end, RevenueTarget: 1000, VisitTarget: 20},
+		},
+		achievements: []sales_target.UserAchievement{
+			{UserID: "u1", Revenue: 300, Visits: 4},
+			{UserID: "u2", Revenue: 200, Visits: 6},
+			{UserID: "u3", Revenue: 900, Visits: 9}, // Not in the team
+		},
+	}
+	userRepo := &fakeUserRepo{users: []user.User{
+		{ID: "u1", TeamID: &teamID},
+		{ID: "u2", TeamID: &teamID},
+		{ID: "u3"},
+	}}
+	svc := NewService(targetRepo, userRepo, &fakeTeamRepo{})
+
+	resp, err := svc.GetByID("t1")
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if resp.TeamName != "North" {
+		t.Errorf("expected team name North, got %q", resp.TeamName)
+	}
+	a := resp.Attainment
+	if a == nil || a.RevenueAchieved != 500 || a.VisitsAchieved != 10 {
+		t.Fatalf("unexpected attainment %+v", a)
+	}
+	if a.RevenueProgressPercent != 50 || a.VisitProgressPercent != 50 {
+		t.Errorf("expected 50%% progress, got %+v", a)
+	}
+}
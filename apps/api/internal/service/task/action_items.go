@@ -0,0 +1,126 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/reminder"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/task"
+	"gorm.io/gorm"
+)
+
+// actionItemReminderLead is how long before the due date the default reminder fires
+const actionItemReminderLead = 24 * time.Hour
+
+// CreateTasksFromActionItems turns selected AI action items of a visit report into tasks.
+// Tasks are linked to the visit report's account, contact and deal and assigned to its sales rep unless
+// another user is given. Each task gets a default in-app reminder; all records are created in one transaction.
+func (s *Service) CreateTasksFromActionItems(visitReportID string, req *task.CreateTasksFromActionItemsRequest, createdBy string) ([]task.TaskResponse, error) {
+	vr, err := s.visitReportRepo.FindByID(visitReportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVisitReportNotFound
+		}
+		return nil, err
+	}
+
+	// Item IDs are derived from the visit report and the item text, so a mismatch means the item is not from this report
+	ids := make([]string, 0, len(req.Items))
+	seen := make(map[string]bool)
+	for _, item := range req.Items {
+		if item.ID != ai.ActionItemID(vr.ID, item.Text) || seen[item.ID] {
+			return nil, ErrInvalidActionItem
+		}
+		seen[item.ID] = true
+		ids = append(ids, item.ID)
+	}
+
+	existing, err := s.taskRepo.FindByActionItemIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, ErrActionItemAlreadyConverted
+	}
+
+	now := time.Now()
+	tasks := make([]*task.Task, len(req.Items))
+	reminders := make([]*reminder.Reminder, len(req.Items))
+	for i, item := range req.Items {
+		assignedTo := vr.SalesRepID
+		if item.AssignedTo != "" {
+			if _, err := s.userRepo.FindByID(item.AssignedTo); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, ErrUserNotFound
+				}
+				return nil, err
+			}
+			assignedTo = item.AssignedTo
+		}
+
+		priority := ai.NormalizeActionItemPriority(item.Priority)
+		dueDate := ai.SuggestedDueDate(priority, 0, now)
+		if item.DueDate != nil {
+			dueDate = *item.DueDate
+		}
+
+		t := &task.Task{
+			Title:         actionItemTitle(item.Text),
+			Description:   fmt.Sprintf("Action item from the visit report of %s:\n%s", vr.VisitDate.Format("2006-01-02"), item.Text),
+			Type:          "follow_up",
+			Status:        "pending",
+			Priority:      priority,
+			DueDate:       &dueDate,
+			AssignedTo:    &assignedTo,
+			AccountID:     vr.AccountID,
+			ContactID:     vr.ContactID,
+			DealID:        vr.DealID,
+			VisitReportID: &vr.ID,
+			ActionItemID:  &req.Items[i].ID,
+			CreatedBy:     createdBy,
+		}
+		if assignedTo != createdBy && createdBy != "" {
+			t.AssignedFrom = &createdBy
+		}
+		tasks[i] = t
+
+		remindAt := dueDate.Add(-actionItemReminderLead)
+		if remindAt.Before(now) {
+			remindAt = now
+		}
+		reminders[i] = &reminder.Reminder{
+			RemindAt:     remindAt,
+			ReminderType: "in_app",
+			Message:      fmt.Sprintf("Task due %s: %s", dueDate.Format("2006-01-02 15:04"), t.Title),
+			CreatedBy:    createdBy,
+		}
+	}
+
+	if err := s.taskRepo.CreateWithReminders(tasks, reminders); err != nil {
+		return nil, err
+	}
+
+	// Reload to get relations
+	created, err := s.taskRepo.FindByActionItemIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]task.TaskResponse, len(created))
+	for i, t := range created {
+		responses[i] = *t.ToTaskResponse()
+	}
+	return responses, nil
+}
+
+// actionItemTitle shortens an action item to fit the task title
+func actionItemTitle(text string) string {
+	const maxTitleLength = 255
+	runes := []rune(text)
+	if len(runes) <= maxTitleLength {
+		return text
+	}
+	return string(runes[:maxTitleLength-3]) + "..."
+}
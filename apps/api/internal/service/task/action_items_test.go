@@ -0,0 +1,129 @@
+package task
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/ai"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/reminder"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/task"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/visit_report"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+type fakeTaskRepo struct {
+	interfaces.TaskRepository
+	tasks     []*task.Task
+	reminders []*reminder.Reminder
+}
+
+func (r *fakeTaskRepo) FindByActionItemIDs(ids []string) ([]task.Task, error) {
+	var found []task.Task
+	for _, t := range r.tasks {
+		for _, id := range ids {
+			if t.ActionItemID != nil && *t.ActionItemID == id {
+				found = append(found, *t)
+			}
+		}
+	}
+	return found, nil
+}
+
+func (r *fakeTaskRepo) CreateWithReminders(tasks []*task.Task, reminders []*reminder.Reminder) error {
+	for i, t := range tasks {
+		t.ID = "task-" + *t.ActionItemID
+		reminders[i].TaskID = t.ID
+	}
+	r.tasks = append(r.tasks, tasks...)
+	r.reminders = append(r.reminders, reminders...)
+	return nil
+}
+
+type fakeVisitReportRepo struct {
+	interfaces.VisitReportRepository
+	report *visit_report.VisitReport
+}
+
+func (r *fakeVisitReportRepo) WithScope(scope *datascope.Scope) interfaces.VisitReportRepository {
+	return r
+}
+
+func (r *fakeVisitReportRepo) FindByID(id string) (*visit_report.VisitReport, error) {
+	if r.report.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.report, nil
+}
+
+func newActionItemTestService() (*Service, *fakeTaskRepo) {
+	accountID := "account-1"
+	dealID := "deal-1"
+	taskRepo := &fakeTaskRepo{}
+	visitReportRepo := &fakeVisitReportRepo{report: &visit_report.VisitReport{
+		ID:         "report-1",
+		AccountID:  &accountID,
+		DealID:     &dealID,
+		SalesRepID: "rep-1",
+		VisitDate:  time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC),
+	}}
+	return NewService(taskRepo, nil, nil, nil, nil, nil, visitReportRepo), taskRepo
+}
+
+func TestCreateTasksFromActionItems(t *testing.T) {
+	service, taskRepo := newActionItemTestService()
+	due := time.Now().Add(72 * time.Hour)
+	req := &task.CreateTasksFromActionItemsRequest{Items: []task.ActionItemTaskRequest{
+		{ID: ai.ActionItemID("report-1", "Send revised quotation"), Text: "Send revised quotation", Priority: "high", DueDate: &due},
+		{ID: ai.ActionItemID("report-1", "Schedule product demo"), Text: "Schedule product demo"},
+	}}
+
+	created, err := service.CreateTasksFromActionItems("report-1", req, "manager-1")
+	if err != nil {
+		t.Fatalf("CreateTasksFromActionItems returned error: %v", err)
+	}
+	if len(created) != 2 || len(taskRepo.reminders) != 2 {
+		t.Fatalf("expected 2 tasks with reminders, got %d tasks and %d reminders", len(created), len(taskRepo.reminders))
+	}
+
+	first := created[0]
+	if first.AccountID != "account-1" || first.DealID != "deal-1" || first.VisitReportID != "report-1" {
+		t.Errorf("expected task linked to visit report, account and deal, got %+v", first)
+	}
+	if first.AssignedTo != "rep-1" || first.Priority != "high" || !first.DueDate.Equal(due) {
+		t.Errorf("unexpected assignment, priority or due date: %+v", first)
+	}
+	if created[1].Priority != "medium" || created[1].DueDate == nil {
+		t.Errorf("expected default priority and suggested due date, got %+v", created[1])
+	}
+	if rem := taskRepo.reminders[0]; rem.TaskID != first.ID || !rem.RemindAt.Equal(due.Add(-actionItemReminderLead)) {
+		t.Errorf("unexpected reminder: %+v", rem)
+	}
+
+	// The same item is never converted twice
+	_, err = service.CreateTasksFromActionItems("report-1", req, "manager-1")
+	if err != ErrActionItemAlreadyConverted {
+		t.Errorf("expected ErrActionItemAlreadyConverted, got %v", err)
+	}
+}
+
+func TestCreateTasksFromActionItems_InvalidItem(t *testing.T) {
+	service, taskRepo := newActionItemTestService()
+	req := &task.CreateTasksFromActionItemsRequest{Items: []task.ActionItemTaskRequest{
+		{ID: ai.ActionItemID("report-2", "Send revised quotation"), Text: "Send revised quotation"},
+	}}
+
+	_, err := service.CreateTasksFromActionItems("report-1", req, "manager-1")
+	if !errors.Is(err, ErrInvalidActionItem) {
+		t.Errorf("expected ErrInvalidActionItem, got %v", err)
+	}
+	if len(taskRepo.tasks) != 0 {
+		t.Errorf("expected no tasks, got %d", len(taskRepo.tasks))
+	}
+
+	if _, err := service.CreateTasksFromActionItems("report-9", req, "manager-1"); err != ErrVisitReportNotFound {
+		t.Errorf("expected ErrVisitReportNotFound, got %v", err)
+	}
+}
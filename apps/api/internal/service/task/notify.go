@@ -0,0 +1,48 @@
+package task
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/notification"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/task"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+)
+
+// NotifierInterface defines interface for notifying users of tasks assigned to them
+type NotifierInterface interface {
+	CreateNotification(req *notification.CreateNotificationRequest) (*notification.NotificationResponse, error)
+}
+
+// SetNotifier sets the notifier used to tell users about tasks assigned to them
+func (s *Service) SetNotifier(notifier NotifierInterface) {
+	s.notifier = notifier
+}
+
+// notifyAssignee tells the assignee of a task about it, in-app and by WhatsApp or SMS if they opted in.
+// Users are not notified of tasks they assigned to themselves.
+func (s *Service) notifyAssignee(t *task.Task, assignedBy string) {
+	if s.notifier == nil || t.AssignedTo == nil || *t.AssignedTo == assignedBy {
+		return
+	}
+
+	when := ""
+	if t.DueDate != nil {
+		when = t.DueDate.In(response.GetTimezoneWIB()).Format("02 Jan 2006 15:04") + " WIB"
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"task_id": t.ID,
+	})
+	_, err := s.notifier.CreateNotification(&notification.CreateNotificationRequest{
+		UserID:    *t.AssignedTo,
+		Title:     "New task assigned",
+		Message:   t.Title,
+		Type:      "task",
+		Data:      string(data),
+		Messaging: true,
+		When:      when,
+	})
+	if err != nil {
+		log.Printf("Failed to notify user %s of task %s: %v", *t.AssignedTo, t.ID, err)
+	}
+}
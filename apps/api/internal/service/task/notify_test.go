@@ -0,0 +1,69 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/notification"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/task"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/user"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+)
+
+type fakeAssignTaskRepo struct {
+	interfaces.TaskRepository
+	task *task.Task
+}
+
+func (r *fakeAssignTaskRepo) FindByID(id string) (*task.Task, error) {
+	copied := *r.task
+	return &copied, nil
+}
+
+func (r *fakeAssignTaskRepo) Update(t *task.Task) error {
+	r.task = t
+	return nil
+}
+
+type fakeUserRepo struct {
+	interfaces.UserRepository
+}
+
+func (r *fakeUserRepo) FindByID(id string) (*user.User, error) {
+	return &user.User{ID: id}, nil
+}
+
+type fakeNotifier struct {
+	requests []*notification.CreateNotificationRequest
+}
+
+func (n *fakeNotifier) CreateNotification(req *notification.CreateNotificationRequest) (*notification.NotificationResponse, error) {
+	n.requests = append(n.requests, req)
+	return &notification.NotificationResponse{}, nil
+}
+
+func TestAssignTask_NotifiesAssignee(t *testing.T) {
+	due := time.Date(2025, 3, 11, 2, 0, 0, 0, time.UTC)
+	taskRepo := &fakeAssignTaskRepo{task: &task.Task{ID: "task-1", Title: "Visit RS Sehat", DueDate: &due}}
+	notifier := &fakeNotifier{}
+	service := NewService(taskRepo, nil, &fakeUserRepo{}, nil, nil, nil, nil)
+	service.SetNotifier(notifier)
+
+	if _, err := service.AssignTask("task-1", &task.AssignTaskRequest{AssignedTo: "rep-1"}, "manager-1"); err != nil {
+		t.Fatalf("AssignTask: %v", err)
+	}
+	if len(notifier.requests) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(notifier.requests))
+	}
+	req := notifier.requests[0]
+	if req.UserID != "rep-1" || req.Type != "task" || req.Message != "Visit RS Sehat" || !req.Messaging || req.When != "11 Mar 2025 09:00 WIB" {
+		t.Errorf("unexpected notification %+v", req)
+	}
+
+	if _, err := service.AssignTask("task-1", &task.AssignTaskRequest{AssignedTo: "rep-1"}, "rep-1"); err != nil {
+		t.Fatalf("AssignTask: %v", err)
+	}
+	if len(notifier.requests) != 1 {
+		t.Error("users must not be notified of tasks they assigned to themselves")
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"time"
 
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/reminder"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/task"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/export"
 	"gorm.io/gorm"
 )
 
@@ -19,15 +21,20 @@ var (
 	ErrReminderNotFound = errors.New("reminder not found")
 	ErrTaskAlreadyCompleted = errors.New("task already completed")
 	ErrCannotMarkCompletedInProgress = errors.New("cannot mark completed task as in progress")
+	ErrVisitReportNotFound  = errors.New("visit report not found")
+	ErrInvalidActionItem    = errors.New("action item does not belong to the visit report")
+	ErrActionItemAlreadyConverted = errors.New("action item already converted to a task")
 )
 
 type Service struct {
-	taskRepo     interfaces.TaskRepository
-	reminderRepo interfaces.ReminderRepository
-	userRepo     interfaces.UserRepository
-	accountRepo  interfaces.AccountRepository
-	contactRepo  interfaces.ContactRepository
-	dealRepo     interfaces.DealRepository
+	taskRepo        interfaces.TaskRepository
+	reminderRepo    interfaces.ReminderRepository
+	userRepo        interfaces.UserRepository
+	accountRepo     interfaces.AccountRepository
+	contactRepo     interfaces.ContactRepository
+	dealRepo        interfaces.DealRepository
+	visitReportRepo interfaces.VisitReportRepository
+	notifier        NotifierInterface
 }
 
 func NewService(
@@ -37,17 +44,28 @@ func NewService(
 	accountRepo interfaces.AccountRepository,
 	contactRepo interfaces.ContactRepository,
 	dealRepo interfaces.DealRepository,
+	visitReportRepo interfaces.VisitReportRepository,
 ) *Service {
 	return &Service{
-		taskRepo:     taskRepo,
-		reminderRepo: reminderRepo,
-		userRepo:     userRepo,
-		accountRepo:  accountRepo,
-		contactRepo:  contactRepo,
-		dealRepo:     dealRepo,
+		taskRepo:        taskRepo,
+		reminderRepo:    reminderRepo,
+		userRepo:        userRepo,
+		accountRepo:     accountRepo,
+		contactRepo:     contactRepo,
+		dealRepo:        dealRepo,
+		visitReportRepo: visitReportRepo,
+		notifier:        nil, // Will be set via SetNotifier if needed
 	}
 }
 
+// WithScope returns a copy of the service whose tasks and visit reports are limited to the given data scope
+func (s *Service) WithScope(scope *datascope.Scope) *Service {
+	scoped := *s
+	scoped.taskRepo = s.taskRepo.WithScope(scope)
+	scoped.visitReportRepo = s.visitReportRepo.WithScope(scope)
+	return &scoped
+}
+
 // ListTasks returns a list of tasks with pagination
 func (s *Service) ListTasks(req *task.ListTasksRequest) ([]task.TaskResponse, *PaginationResult, error) {
 	tasks, total, err := s.taskRepo.List(req)
@@ -84,6 +102,18 @@ func (s *Service) ListTasks(req *task.ListTasksRequest) ([]task.TaskResponse, *P
 	return responses, pagination, nil
 }
 
+// ExportTasks writes every task matching the list filters to w; rows are flushed to the client after each batch
+func (s *Service) ExportTasks(req *task.ListTasksRequest, w export.Writer) error {
+	return s.taskRepo.FindInBatches(req, func(tasks []task.Task) error {
+		for i := range tasks {
+			if err := w.WriteRow(tasks[i].ExportRow()); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	})
+}
+
 // GetTaskByID returns a task by ID
 func (s *Service) GetTaskByID(id string) (*task.TaskResponse, error) {
 	t, err := s.taskRepo.FindByID(id)
@@ -205,6 +235,8 @@ func (s *Service) CreateTask(req *task.CreateTaskRequest, createdBy string) (*ta
 		return nil, err
 	}
 
+	s.notifyAssignee(t, createdBy)
+
 	return t.ToTaskResponse(), nil
 }
 
@@ -352,6 +384,8 @@ func (s *Service) AssignTask(id string, req *task.AssignTaskRequest, assignedBy
 		return nil, err
 	}
 
+	s.notifyAssignee(t, assignedBy)
+
 	return t.ToTaskResponse(), nil
 }
 
@@ -0,0 +1,167 @@
+package team
+
+import (
+	"errors"
+
+	"github.com/gilabs/crm-healthcare/api/internal/domain/team"
+	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTeamNotFound      = errors.New("team not found")
+	ErrTeamAlreadyExists = errors.New("team already exists")
+	ErrManagerNotFound   = errors.New("manager not found")
+)
+
+type Service struct {
+	teamRepo interfaces.TeamRepository
+	userRepo interfaces.UserRepository
+}
+
+func NewService(teamRepo interfaces.TeamRepository, userRepo interfaces.UserRepository) *Service {
+	return &Service{
+		teamRepo: teamRepo,
+		userRepo: userRepo,
+	}
+}
+
+// List returns a list of teams
+func (s *Service) List() ([]team.TeamResponse, error) {
+	teams, err := s.teamRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]team.TeamResponse, len(teams))
+	for i, t := range teams {
+		responses[i] = *t.ToTeamResponse()
+		if count, err := s.teamRepo.CountMembers(t.ID); err == nil {
+			responses[i].MemberCount = count
+		}
+	}
+
+	return responses, nil
+}
+
+// GetByID returns a team by ID
+func (s *Service) GetByID(id string) (*team.TeamResponse, error) {
+	t, err := s.teamRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, err
+	}
+
+	resp := t.ToTeamResponse()
+	if count, err := s.teamRepo.CountMembers(t.ID); err == nil {
+		resp.MemberCount = count
+	}
+	return resp, nil
+}
+
+// Create creates a new team
+func (s *Service) Create(req *team.CreateTeamRequest) (*team.TeamResponse, error) {
+	// Check if name already exists
+	_, err := s.teamRepo.FindByName(req.Name)
+	if err == nil {
+		return nil, ErrTeamAlreadyExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := s.validateManager(req.ManagerID); err != nil {
+		return nil, err
+	}
+
+	status := req.Status
+	if status == "" {
+		status = "active"
+	}
+
+	t := &team.Team{
+		Name:        req.Name,
+		Description: req.Description,
+		ManagerID:   req.ManagerID,
+		Status:      status,
+	}
+
+	if err := s.teamRepo.Create(t); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(t.ID)
+}
+
+// Update updates a team
+func (s *Service) Update(id string, req *team.UpdateTeamRequest) (*team.TeamResponse, error) {
+	t, err := s.teamRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, err
+	}
+
+	if req.Name != "" {
+		// Check if name already exists (excluding current team)
+		existingTeam, err := s.teamRepo.FindByName(req.Name)
+		if err == nil && existingTeam.ID != id {
+			return nil, ErrTeamAlreadyExists
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		t.Name = req.Name
+	}
+
+	if req.Description != "" {
+		t.Description = req.Description
+	}
+
+	if req.ManagerID != nil {
+		if err := s.validateManager(req.ManagerID); err != nil {
+			return nil, err
+		}
+		t.ManagerID = req.ManagerID
+	}
+
+	if req.Status != "" {
+		t.Status = req.Status
+	}
+
+	if err := s.teamRepo.Update(t); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(t.ID)
+}
+
+// Delete deletes a team and detaches its members
+func (s *Service) Delete(id string) error {
+	_, err := s.teamRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTeamNotFound
+		}
+		return err
+	}
+
+	return s.teamRepo.Delete(id)
+}
+
+// validateManager ensures the optional manager references an existing user
+func (s *Service) validateManager(managerID *string) error {
+	if managerID == nil || *managerID == "" {
+		return nil
+	}
+	if _, err := s.userRepo.FindByID(*managerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrManagerNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -14,17 +14,20 @@ var (
 	ErrUserNotFound      = errors.New("user not found")
 	ErrUserAlreadyExists = errors.New("user already exists")
 	ErrRoleNotFound      = errors.New("role not found")
+	ErrTeamNotFound      = errors.New("team not found")
 )
 
 type Service struct {
 	userRepo interfaces.UserRepository
 	roleRepo interfaces.RoleRepository
+	teamRepo interfaces.TeamRepository
 }
 
-func NewService(userRepo interfaces.UserRepository, roleRepo interfaces.RoleRepository) *Service {
+func NewService(userRepo interfaces.UserRepository, roleRepo interfaces.RoleRepository, teamRepo interfaces.TeamRepository) *Service {
 	return &Service{
 		userRepo: userRepo,
 		roleRepo: roleRepo,
+		teamRepo: teamRepo,
 	}
 }
 
@@ -85,6 +88,16 @@ func (s *Service) Create(req *user.CreateUserRequest) (*user.UserResponse, error
 		return nil, err
 	}
 
+	// Check if team exists
+	if req.TeamID != nil && *req.TeamID != "" {
+		if _, err := s.teamRepo.FindByID(*req.TeamID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrTeamNotFound
+			}
+			return nil, err
+		}
+	}
+
 	// Check if email already exists
 	_, err = s.userRepo.FindByEmail(req.Email)
 	if err == nil {
@@ -117,6 +130,7 @@ func (s *Service) Create(req *user.CreateUserRequest) (*user.UserResponse, error
 		AvatarURL: avatarURL,
 		RoleID:    req.RoleID,
 		Status:    status,
+		TeamID:    req.TeamID,
 	}
 
 	if err := s.userRepo.Create(u); err != nil {
@@ -176,6 +190,20 @@ func (s *Service) Update(id string, req *user.UpdateUserRequest) (*user.UserResp
 		u.Status = req.Status
 	}
 
+	if req.TeamID != nil {
+		if *req.TeamID == "" {
+			u.TeamID = nil
+		} else {
+			if _, err := s.teamRepo.FindByID(*req.TeamID); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, ErrTeamNotFound
+				}
+				return nil, err
+			}
+			u.TeamID = req.TeamID
+		}
+	}
+
 	if err := s.userRepo.Update(u); err != nil {
 		return nil, err
 	}
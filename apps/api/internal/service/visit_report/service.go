@@ -3,8 +3,10 @@ package visit_report
 import (
 	"encoding/json"
 	"errors"
+	"log"
 	"time"
 
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/activity"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/visit_report"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
@@ -18,12 +20,18 @@ var (
 	ErrInvalidStatus       = errors.New("invalid status transition")
 )
 
+// LeadScorerInterface defines interface for rescoring a lead after its visit reports change
+type LeadScorerInterface interface {
+	RecomputeScore(leadID string) error
+}
+
 type Service struct {
 	visitReportRepo interfaces.VisitReportRepository
 	accountRepo     interfaces.AccountRepository
 	contactRepo     interfaces.ContactRepository
 	userRepo        interfaces.UserRepository
 	activityRepo    interfaces.ActivityRepository
+	leadScorer      LeadScorerInterface
 }
 
 func NewService(visitReportRepo interfaces.VisitReportRepository, accountRepo interfaces.AccountRepository, contactRepo interfaces.ContactRepository, userRepo interfaces.UserRepository, activityRepo interfaces.ActivityRepository) *Service {
@@ -36,6 +44,28 @@ func NewService(visitReportRepo interfaces.VisitReportRepository, accountRepo in
 	}
 }
 
+// WithScope returns a copy of the service whose visit reports are limited to the given data scope
+func (s *Service) WithScope(scope *datascope.Scope) *Service {
+	scoped := *s
+	scoped.visitReportRepo = s.visitReportRepo.WithScope(scope)
+	return &scoped
+}
+
+// SetLeadScorer sets the lead scorer used to rescore linked leads
+func (s *Service) SetLeadScorer(scorer LeadScorerInterface) {
+	s.leadScorer = scorer
+}
+
+// rescoreLead rescores a linked lead; failures are logged only, since the visit report itself was saved
+func (s *Service) rescoreLead(leadID *string) {
+	if s.leadScorer == nil || leadID == nil || *leadID == "" {
+		return
+	}
+	if err := s.leadScorer.RecomputeScore(*leadID); err != nil {
+		log.Printf("Failed to recompute score of lead %s: %v", *leadID, err)
+	}
+}
+
 // PaginationResult represents pagination information
 type PaginationResult struct {
 	Page       int
@@ -374,6 +404,7 @@ func (s *Service) Update(id string, req *visit_report.UpdateVisitReportRequest)
 		vr.DealID = req.DealID
 	}
 
+	previousLeadID := vr.LeadID
 	if req.LeadID != nil {
 		vr.LeadID = req.LeadID
 	}
@@ -451,6 +482,10 @@ func (s *Service) Update(id string, req *visit_report.UpdateVisitReportRequest)
 	if err := s.visitReportRepo.Update(vr); err != nil {
 		return nil, err
 	}
+	if previousLeadID != nil && (vr.LeadID == nil || *vr.LeadID != *previousLeadID) {
+		s.rescoreLead(previousLeadID)
+	}
+	s.rescoreLead(vr.LeadID)
 
 	// Reload
 	updatedVR, err := s.visitReportRepo.FindByID(vr.ID)
@@ -487,7 +522,7 @@ func (s *Service) Update(id string, req *visit_report.UpdateVisitReportRequest)
 
 // Delete deletes a visit report
 func (s *Service) Delete(id string) error {
-	_, err := s.visitReportRepo.FindByID(id)
+	vr, err := s.visitReportRepo.FindByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrVisitReportNotFound
@@ -495,7 +530,11 @@ func (s *Service) Delete(id string) error {
 		return err
 	}
 
-	return s.visitReportRepo.Delete(id)
+	if err := s.visitReportRepo.Delete(id); err != nil {
+		return err
+	}
+	s.rescoreLead(vr.LeadID)
+	return nil
 }
 
 // CheckIn performs check-in for a visit report
@@ -858,5 +897,6 @@ func (s *Service) createActivity(vr *visit_report.VisitReport, activityType, des
 	}
 
 	_ = s.activityRepo.Create(activity) // Ignore error for now
+	s.rescoreLead(vr.LeadID)
 }
 
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"log"
+	"time"
+
+	emailoutboxservice "github.com/gilabs/crm-healthcare/api/internal/service/email_outbox"
+)
+
+// EmailOutboxWorker sends queued emails and retries failed ones
+type EmailOutboxWorker struct {
+	emailOutbox *emailoutboxservice.Service
+	ticker      *time.Ticker
+	stopChan    chan bool
+}
+
+// NewEmailOutboxWorker creates a new email outbox worker
+func NewEmailOutboxWorker(
+	emailOutbox *emailoutboxservice.Service,
+	interval time.Duration,
+) *EmailOutboxWorker {
+	return &EmailOutboxWorker{
+		emailOutbox: emailOutbox,
+		ticker:      time.NewTicker(interval),
+		stopChan:    make(chan bool),
+	}
+}
+
+// Start starts the email outbox worker
+func (w *EmailOutboxWorker) Start() {
+	log.Println("Email outbox worker started")
+
+	go func() {
+		for {
+			select {
+			case <-w.ticker.C:
+				w.sendDueEmails()
+			case <-w.stopChan:
+				w.ticker.Stop()
+				log.Println("Email outbox worker stopped")
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the email outbox worker
+func (w *EmailOutboxWorker) Stop() {
+	w.stopChan <- true
+}
+
+// sendDueEmails sends the queued emails whose next attempt is due
+func (w *EmailOutboxWorker) sendDueEmails() {
+	sent, err := w.emailOutbox.ProcessDue()
+	if err != nil {
+		log.Printf("Error finding due emails: %v", err)
+		return
+	}
+
+	if sent > 0 {
+		log.Printf("Sent %d queued email(s)", sent)
+	}
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"log"
+	"time"
+
+	messagingservice "github.com/gilabs/crm-healthcare/api/internal/service/messaging"
+)
+
+// MessagingWorker sends queued SMS and WhatsApp messages and retries failed ones
+type MessagingWorker struct {
+	messaging *messagingservice.Service
+	ticker    *time.Ticker
+	stopChan  chan bool
+}
+
+// NewMessagingWorker creates a new messaging worker
+func NewMessagingWorker(
+	messaging *messagingservice.Service,
+	interval time.Duration,
+) *MessagingWorker {
+	return &MessagingWorker{
+		messaging: messaging,
+		ticker:    time.NewTicker(interval),
+		stopChan:  make(chan bool),
+	}
+}
+
+// Start starts the messaging worker
+func (w *MessagingWorker) Start() {
+	log.Println("Messaging worker started")
+
+	go func() {
+		for {
+			select {
+			case <-w.ticker.C:
+				w.sendDueMessages()
+			case <-w.stopChan:
+				w.ticker.Stop()
+				log.Println("Messaging worker stopped")
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the messaging worker
+func (w *MessagingWorker) Stop() {
+	w.stopChan <- true
+}
+
+// sendDueMessages sends the queued messages whose next attempt is due
+func (w *MessagingWorker) sendDueMessages() {
+	sent, err := w.messaging.ProcessDue()
+	if err != nil {
+		log.Printf("Error finding due messages: %v", err)
+		return
+	}
+
+	if sent > 0 {
+		log.Printf("Sent %d queued message(s)", sent)
+	}
+}
@@ -2,20 +2,28 @@ package worker
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"time"
 
+	"github.com/gilabs/crm-healthcare/api/internal/domain/email_outbox"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/messaging"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/notification"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/reminder"
 	"github.com/gilabs/crm-healthcare/api/internal/hub"
+	emailoutboxservice "github.com/gilabs/crm-healthcare/api/internal/service/email_outbox"
+	messagingservice "github.com/gilabs/crm-healthcare/api/internal/service/messaging"
 	notificationservice "github.com/gilabs/crm-healthcare/api/internal/service/notification"
 	"github.com/gilabs/crm-healthcare/api/internal/repository/interfaces"
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
 )
 
 type ReminderWorker struct {
 	reminderRepo     interfaces.ReminderRepository
 	notificationService *notificationservice.Service
 	notificationHub  *hub.NotificationHub
+	emailOutbox      *emailoutboxservice.Service
+	messaging        *messagingservice.Service
 	ticker           *time.Ticker
 	stopChan         chan bool
 }
@@ -24,12 +32,16 @@ func NewReminderWorker(
 	reminderRepo interfaces.ReminderRepository,
 	notificationService *notificationservice.Service,
 	notificationHub *hub.NotificationHub,
+	emailOutbox *emailoutboxservice.Service,
+	messaging *messagingservice.Service,
 	interval time.Duration,
 ) *ReminderWorker {
 	return &ReminderWorker{
 		reminderRepo:        reminderRepo,
 		notificationService: notificationService,
 		notificationHub:     notificationHub,
+		emailOutbox:         emailOutbox,
+		messaging:           messaging,
 		ticker:              time.NewTicker(interval),
 		stopChan:            make(chan bool),
 	}
@@ -86,9 +98,17 @@ func (w *ReminderWorker) processReminders() {
 
 // processReminder processes a single reminder
 func (w *ReminderWorker) processReminder(rem *reminder.Reminder) error {
-	// Only process in_app reminders
-	if rem.ReminderType != "in_app" {
-		// Mark as sent even if we skip it
+	switch rem.ReminderType {
+	case "in_app":
+	case "email":
+		return w.queueEmail(rem)
+	case "sms", "whatsapp":
+		return w.queueMessage(rem)
+	default:
+		// No delivery channel for this type yet; mark as sent so it is not picked up again
+		if err := w.reminderRepo.UpdateDeliveryStatus(rem.ID, reminder.DeliveryStatusSkipped, "no delivery channel for "+rem.ReminderType+" reminders"); err != nil {
+			return err
+		}
 		return w.reminderRepo.MarkAsSent(rem.ID, time.Now())
 	}
 
@@ -145,6 +165,78 @@ func (w *ReminderWorker) processReminder(rem *reminder.Reminder) error {
 	w.notificationHub.BroadcastNotification(rem.CreatedBy, notifMap)
 
 	// Mark reminder as sent
+	if err := w.reminderRepo.UpdateDeliveryStatus(rem.ID, reminder.DeliveryStatusSent, ""); err != nil {
+		return err
+	}
+	return w.reminderRepo.MarkAsSent(rem.ID, time.Now())
+}
+
+// queueEmail hands an email reminder to the outbox, which reports the final delivery status back to the reminder
+func (w *ReminderWorker) queueEmail(rem *reminder.Reminder) error {
+	// Without a task the message is the title
+	title, message := rem.Message, ""
+	details := []email_outbox.Detail{
+		{Label: "Remind at", Value: rem.RemindAt.In(response.GetTimezoneWIB()).Format("02 Jan 2006 15:04") + " WIB"},
+	}
+	if rem.Task != nil {
+		title, message = rem.Task.Title, rem.Message
+		details = append([]email_outbox.Detail{{Label: "Task", Value: rem.Task.Title}}, details...)
+	}
+	if title == "" {
+		title = "You have a reminder"
+	}
+
+	status, deliveryError := reminder.DeliveryStatusQueued, ""
+	_, err := w.emailOutbox.Enqueue(&email_outbox.NotificationEmail{
+		UserID:     rem.CreatedBy,
+		Type:       "reminder",
+		Title:      title,
+		Message:    message,
+		Details:    details,
+		ReminderID: &rem.ID,
+	})
+	if err != nil {
+		status, deliveryError = reminder.DeliveryStatusFailed, err.Error()
+		log.Printf("Warning: Failed to queue email for reminder %s: %v", rem.ID, err)
+	}
+
+	if err := w.reminderRepo.UpdateDeliveryStatus(rem.ID, status, deliveryError); err != nil {
+		return err
+	}
 	return w.reminderRepo.MarkAsSent(rem.ID, time.Now())
 }
 
+// queueMessage hands an SMS or WhatsApp reminder to the messaging service, whose delivery receipts are
+// reported back to the reminder. Users who did not opt in to the channel are skipped.
+func (w *ReminderWorker) queueMessage(rem *reminder.Reminder) error {
+	title, message := rem.Message, ""
+	if rem.Task != nil {
+		title, message = rem.Task.Title, rem.Message
+	}
+	if title == "" {
+		title = "You have a reminder"
+	}
+
+	status, deliveryError := reminder.DeliveryStatusQueued, ""
+	_, err := w.messaging.Enqueue(&messaging.NotificationMessage{
+		UserID:     rem.CreatedBy,
+		Channel:    rem.ReminderType,
+		Type:       "reminder",
+		Title:      title,
+		Message:    message,
+		When:       rem.RemindAt.In(response.GetTimezoneWIB()).Format("02 Jan 2006 15:04") + " WIB",
+		ReminderID: &rem.ID,
+	})
+	switch {
+	case errors.Is(err, messagingservice.ErrNotOptedIn), errors.Is(err, messagingservice.ErrChannelNotConfigured):
+		status, deliveryError = reminder.DeliveryStatusSkipped, err.Error()
+	case err != nil:
+		status, deliveryError = reminder.DeliveryStatusFailed, err.Error()
+		log.Printf("Warning: Failed to queue %s message for reminder %s: %v", rem.ReminderType, rem.ID, err)
+	}
+
+	if err := w.reminderRepo.UpdateDeliveryStatus(rem.ID, status, deliveryError); err != nil {
+		return err
+	}
+	return w.reminderRepo.MarkAsSent(rem.ID, time.Now())
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"log"
+	"time"
+
+	reportsubscriptionservice "github.com/gilabs/crm-healthcare/api/internal/service/report_subscription"
+)
+
+// ReportSubscriptionWorker emails scheduled reports when their subscriptions are due
+type ReportSubscriptionWorker struct {
+	subscriptionService *reportsubscriptionservice.Service
+	ticker              *time.Ticker
+	stopChan            chan bool
+}
+
+// NewReportSubscriptionWorker creates a new report subscription worker
+func NewReportSubscriptionWorker(
+	subscriptionService *reportsubscriptionservice.Service,
+	interval time.Duration,
+) *ReportSubscriptionWorker {
+	return &ReportSubscriptionWorker{
+		subscriptionService: subscriptionService,
+		ticker:              time.NewTicker(interval),
+		stopChan:            make(chan bool),
+	}
+}
+
+// Start starts the report subscription worker
+func (w *ReportSubscriptionWorker) Start() {
+	log.Println("Report subscription worker started")
+
+	go func() {
+		for {
+			select {
+			case <-w.ticker.C:
+				w.deliverDueReports()
+			case <-w.stopChan:
+				w.ticker.Stop()
+				log.Println("Report subscription worker stopped")
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the report subscription worker
+func (w *ReportSubscriptionWorker) Stop() {
+	w.stopChan <- true
+}
+
+// deliverDueReports generates and emails the reports of due subscriptions
+func (w *ReportSubscriptionWorker) deliverDueReports() {
+	delivered, err := w.subscriptionService.RunDue()
+	if err != nil {
+		log.Printf("Error finding due report subscriptions: %v", err)
+		return
+	}
+
+	if delivered > 0 {
+		log.Printf("Delivered %d scheduled report(s)", delivered)
+	}
+}
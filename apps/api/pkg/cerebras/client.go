@@ -1,20 +1,27 @@
 package cerebras
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
+// truncatedResponseWarning is appended when a response stops at the token limit
+const truncatedResponseWarning = "\n\n⚠️ *Catatan: Response mungkin terpotong karena mencapai batas token. Silakan coba pertanyaan yang lebih spesifik atau minta data dalam batch yang lebih kecil, atau gunakan model yang lebih advanced*"
+
 // Client represents Cerebras API client
 type Client struct {
-	baseURL    string
-	apiKey     string
-	model      string // Default model name
-	httpClient *http.Client
+	baseURL          string
+	apiKey           string
+	model            string // Default model name
+	httpClient       *http.Client
+	streamHTTPClient *http.Client // No overall timeout, streams end when the model finishes
 }
 
 // NewClient creates a new Cerebras API client
@@ -32,9 +39,20 @@ func NewClient(baseURL, apiKey, model string) *Client {
 		httpClient: &http.Client{
 			Timeout: 120 * time.Second, // Increased timeout for longer responses
 		},
+		streamHTTPClient: &http.Client{
+			Transport: streamTransport(),
+		},
 	}
 }
 
+// streamTransport returns a transport that only bounds the wait for response headers,
+// so long streamed answers are not cut off by a total request timeout
+func streamTransport() http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 120 * time.Second
+	return transport
+}
+
 // GenerateRequest represents request to Cerebras API
 type GenerateRequest struct {
 	Prompt      string  `json:"prompt"`
@@ -52,8 +70,36 @@ type GenerateResponse struct {
 
 // ChatMessage represents a chat message
 type ChatMessage struct {
-	Role    string `json:"role"` // "user" or "assistant"
-	Content string `json:"content"`
+	Role       string     `json:"role"` // "system", "user", "assistant" or "tool"
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // Functions the assistant wants to call
+	ToolCallID string     `json:"tool_call_id,omitempty"` // Call answered by a "tool" message
+}
+
+// ToolCall represents a function call requested by the model
+type ToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"` // Always "function"
+	Function ToolCallFunction `json:"function"`
+}
+
+// ToolCallFunction represents the function name and JSON encoded arguments of a tool call
+type ToolCallFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// Tool represents a function the model may call
+type Tool struct {
+	Type     string       `json:"type"` // Always "function"
+	Function ToolFunction `json:"function"`
+}
+
+// ToolFunction describes a callable function with a JSON schema of its parameters
+type ToolFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
 }
 
 // ChatRequest represents chat request
@@ -62,6 +108,7 @@ type ChatRequest struct {
 	Model       string        `json:"model,omitempty"` // Model name (e.g., "llama-3.1-8b")
 	MaxTokens   int           `json:"max_tokens,omitempty"`
 	Temperature float64       `json:"temperature,omitempty"`
+	Tools       []Tool        `json:"tools,omitempty"` // Functions the model may call instead of answering
 }
 
 // ChatResponse represents chat response
@@ -191,6 +238,9 @@ func (c *Client) Chat(req *ChatRequest) (*ChatResponse, error) {
 		"max_tokens":  req.MaxTokens,
 		"temperature": req.Temperature,
 	}
+	if len(req.Tools) > 0 {
+		requestBody["tools"] = req.Tools
+	}
 
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
@@ -277,15 +327,182 @@ func (c *Client) Chat(req *ChatRequest) (*ChatResponse, error) {
 	
 	// If response was truncated (finish_reason == "length"), add warning
 	if finishReason == "length" {
-		messageContent += "\n\n⚠️ *Catatan: Response mungkin terpotong karena mencapai batas token. Silakan coba pertanyaan yang lebih spesifik atau minta data dalam batch yang lebih kecil, atau gunakan model yang lebih advanced*"
+		messageContent += truncatedResponseWarning
 	}
 
 	return &ChatResponse{
 		Message: ChatMessage{
-			Role:    apiResponse.Choices[0].Message.Role,
-			Content: messageContent,
+			Role:      apiResponse.Choices[0].Message.Role,
+			Content:   messageContent,
+			ToolCalls: apiResponse.Choices[0].Message.ToolCalls,
 		},
 		Tokens: apiResponse.Usage.TotalTokens,
 	}, nil
 }
 
+// ChatStream sends chat messages to Cerebras API with streaming enabled.
+// onDelta is called for every content chunk as it arrives; returning an error from it aborts the stream.
+// The returned response contains the full message and the token usage reported in the final chunk.
+func (c *Client) ChatStream(ctx context.Context, req *ChatRequest, onDelta func(content string) error) (*ChatResponse, error) {
+	// Set defaults
+	if req.MaxTokens == 0 {
+		req.MaxTokens = 2000
+	}
+	if req.Temperature == 0 {
+		req.Temperature = 0.7
+	}
+
+	model := req.Model
+	if model == "" {
+		model = c.model
+	}
+
+	requestBody := map[string]interface{}{
+		"model":       model,
+		"messages":    req.Messages,
+		"max_tokens":  req.MaxTokens,
+		"temperature": req.Temperature,
+		"stream":      true,
+	}
+	if len(req.Tools) > 0 {
+		requestBody["tools"] = req.Tools
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/v1/chat/completions", c.baseURL), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	}
+
+	resp, err := c.streamHTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var errorResponse struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Error.Message != "" {
+			return nil, fmt.Errorf("Cerebras API error (status %d): %s", resp.StatusCode, errorResponse.Error.Message)
+		}
+		return nil, fmt.Errorf("Cerebras API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var content strings.Builder
+	var toolCalls []ToolCall
+	var tokens int
+	var finishReason string
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue // Skip blank separators, comments and event names
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content   string          `json:"content"`
+					ToolCalls []toolCallDelta `json:"tool_calls"`
+				} `json:"delta"`
+				FinishReason string `json:"finish_reason"`
+			} `json:"choices"`
+			Usage *struct {
+				TotalTokens int `json:"total_tokens"`
+			} `json:"usage"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to parse stream chunk: %w, data: %s", err, data)
+		}
+		if chunk.Error != nil {
+			return nil, fmt.Errorf("Cerebras API error: %s", chunk.Error.Message)
+		}
+		if chunk.Usage != nil {
+			tokens = chunk.Usage.TotalTokens
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+			toolCalls = appendToolCallDeltas(toolCalls, choice.Delta.ToolCalls)
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if err := onDelta(choice.Delta.Content); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	// If response was truncated (finish_reason == "length"), add warning
+	if finishReason == "length" {
+		if err := onDelta(truncatedResponseWarning); err != nil {
+			return nil, err
+		}
+		content.WriteString(truncatedResponseWarning)
+	}
+
+	return &ChatResponse{
+		Message: ChatMessage{
+			Role:      "assistant",
+			Content:   content.String(),
+			ToolCalls: toolCalls,
+		},
+		Tokens: tokens,
+	}, nil
+}
+
+// toolCallDelta is a streamed fragment of a tool call; arguments arrive in pieces for the same index
+type toolCallDelta struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// appendToolCallDeltas merges streamed tool call fragments into complete tool calls
+func appendToolCallDeltas(calls []ToolCall, deltas []toolCallDelta) []ToolCall {
+	for _, d := range deltas {
+		for len(calls) <= d.Index {
+			calls = append(calls, ToolCall{Type: "function"})
+		}
+		call := &calls[d.Index]
+		if d.ID != "" {
+			call.ID = d.ID
+		}
+		call.Function.Name += d.Function.Name
+		call.Function.Arguments += d.Function.Arguments
+	}
+	return calls
+}
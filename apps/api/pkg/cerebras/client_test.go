@@ -0,0 +1,108 @@
+package cerebras
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChatStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if body["stream"] != true {
+			http.Error(w, "stream must be enabled", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		chunks := []string{
+			`{"choices":[{"delta":{"role":"assistant"}}]}`,
+			`{"choices":[{"delta":{"content":"Halo"}}]}`,
+			`{"choices":[{"delta":{"content":", dokter!"}}]}`,
+			`{"choices":[{"delta":{},"finish_reason":"stop"}],"usage":{"total_tokens":17}}`,
+		}
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+			w.(http.Flusher).Flush()
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key", "")
+
+	var deltas []string
+	response, err := client.ChatStream(context.Background(), &ChatRequest{
+		Messages: []ChatMessage{{Role: "user", Content: "Hai"}},
+	}, func(content string) error {
+		deltas = append(deltas, content)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatStream returned error: %v", err)
+	}
+
+	if strings.Join(deltas, "|") != "Halo|, dokter!" {
+		t.Errorf("unexpected deltas: %q", deltas)
+	}
+	if response.Message.Content != "Halo, dokter!" {
+		t.Errorf("unexpected message content: %q", response.Message.Content)
+	}
+	if response.Tokens != 17 {
+		t.Errorf("expected 17 tokens, got %d", response.Tokens)
+	}
+}
+
+func TestChatStream_Truncated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Partial\"},\"finish_reason\":\"length\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key", "")
+
+	var deltas []string
+	response, err := client.ChatStream(context.Background(), &ChatRequest{}, func(content string) error {
+		deltas = append(deltas, content)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatStream returned error: %v", err)
+	}
+
+	if len(deltas) != 2 || deltas[1] != truncatedResponseWarning {
+		t.Errorf("truncation warning should be streamed as the last delta, got %q", deltas)
+	}
+	if !strings.HasSuffix(response.Message.Content, truncatedResponseWarning) {
+		t.Error("message content should end with truncation warning")
+	}
+}
+
+func TestChatStream_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"message":"Model foo does not exist","code":"model_not_found"}}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key", "")
+
+	_, err := client.ChatStream(context.Background(), &ChatRequest{Model: "foo"}, func(string) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("expected API error, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var wib = time.FixedZone("WIB", 7*60*60)
+
+func TestNext(t *testing.T) {
+	// Wednesday 5 March 2025, 10:30 WIB
+	from := time.Date(2025, 3, 5, 10, 30, 0, 0, wib)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2025, 3, 5, 10, 45, 0, 0, wib)},
+		{"30 10 * * *", time.Date(2025, 3, 6, 10, 30, 0, 0, wib)},
+		{"0 7 * * mon", time.Date(2025, 3, 10, 7, 0, 0, 0, wib)},
+		{"0 7 * * 1-5", time.Date(2025, 3, 6, 7, 0, 0, 0, wib)},
+		{"0 8 1 * *", time.Date(2025, 4, 1, 8, 0, 0, 0, wib)},
+		{"@monthly", time.Date(2025, 4, 1, 0, 0, 0, 0, wib)},
+		{"0 0 * * 7", time.Date(2025, 3, 9, 0, 0, 0, 0, wib)},
+		{"0 9 29 2 *", time.Date(2028, 2, 29, 9, 0, 0, 0, wib)},
+		// Either day field matches when both are restricted
+		{"0 6 15 * fri", time.Date(2025, 3, 7, 6, 0, 0, 0, wib)},
+		{"0 12 1,15 jan,jul *", time.Date(2025, 7, 1, 12, 0, 0, 0, wib)},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.expr, err)
+		}
+		if got := s.Next(from); !got.Equal(tt.want) {
+			t.Errorf("Next(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestNext_Never(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := s.Next(time.Date(2025, 1, 1, 0, 0, 0, 0, wib)); !got.IsZero() {
+		t.Errorf("expected no run on 30 February, got %v", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "*/0 * * * *", "5-1 * * * *", "0 7 * * funday", "@every 5m"} {
+		if _, err := Parse(expr); !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("Parse(%q): expected ErrInvalidExpression, got %v", expr, err)
+		}
+	}
+}
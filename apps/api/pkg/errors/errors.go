@@ -132,6 +132,114 @@ var ErrorCodeMap = map[string]ErrorInfo{
 		HTTPStatus: http.StatusBadRequest,
 		Message:    "Invalid lead source",
 	},
+	"INVALID_ACTION_ITEM": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "Action item does not belong to the visit report",
+	},
+	"ACTION_ITEM_ALREADY_CONVERTED": {
+		HTTPStatus: http.StatusConflict,
+		Message:    "Action item already converted to a task",
+	},
+	"INVALID_TARGET_PERIOD": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "Invalid target period. Use month 1-12 for monthly and quarter 1-4 for quarterly targets",
+	},
+	"INVALID_SCORING_RULE": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "Invalid scoring rule. The operator or value does not fit the criterion",
+	},
+	"INVALID_ASSIGNMENT_RULE": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "Invalid assignment rule. Set user_id for the user strategy, team_id for team strategies and min_score at most max_score",
+	},
+	"DUPLICATE_FOUND": {
+		HTTPStatus: http.StatusConflict,
+		Message:    "Possible duplicate records found. Merge them or resend with ignore_duplicates set to true",
+	},
+	"MERGE_NOT_ALLOWED": {
+		HTTPStatus: http.StatusUnprocessableEntity,
+		Message:    "Records cannot be merged",
+	},
+	"INVALID_IMPORT_FILE": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "Invalid import file. Upload a .csv or .xlsx file with a header row",
+	},
+	"INVALID_IMPORT_MAPPING": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "Invalid column mapping. Map every required field to a column of the file",
+	},
+	"IMPORT_NOT_ALLOWED": {
+		HTTPStatus: http.StatusConflict,
+		Message:    "Import job is already importing or finished",
+	},
+	"EXPORT_TOO_LARGE": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "Too many rows for the requested export format",
+	},
+	"INVALID_SCHEDULE": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "Invalid schedule. Use a five-field cron expression such as \"0 7 * * 1\" or a macro such as @daily",
+	},
+	"ACCOUNT_REQUIRED": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "An account is required for the account activity report",
+	},
+	"EMAIL_NOT_RETRYABLE": {
+		HTTPStatus: http.StatusConflict,
+		Message:    "Only failed emails can be retried",
+	},
+	"PHONE_REQUIRED": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "A phone number is required to opt in to SMS or WhatsApp",
+	},
+	"INVALID_PHONE": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "Invalid phone number",
+	},
+	"PIPELINE_IN_USE": {
+		HTTPStatus: http.StatusConflict,
+		Message:    "Pipeline still has deals. Move or delete them before deleting the pipeline",
+	},
+	"DEFAULT_PIPELINE_REQUIRED": {
+		HTTPStatus: http.StatusConflict,
+		Message:    "The default pipeline cannot be deleted, deactivated or unset. Make another pipeline the default first",
+	},
+	"STAGE_NOT_IN_PIPELINE": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "Pipeline stage does not belong to the pipeline",
+	},
+	"QUOTATION_WITHOUT_ITEMS": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "The deal has no line items to quote. Add products to the deal first",
+	},
+	"INVALID_QUOTATION_STATUS": {
+		HTTPStatus: http.StatusConflict,
+		Message:    "The quotation status does not allow this action",
+	},
+	"INVALID_VALID_UNTIL": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "The validity date must not be before the issue date",
+	},
+	"NO_WON_STAGE": {
+		HTTPStatus: http.StatusConflict,
+		Message:    "The pipeline of the deal has no won stage to move the deal to",
+	},
+	"LOST_REASON_REQUIRED": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "A loss reason is required to close a deal as lost",
+	},
+	"COMPETITOR_REQUIRED": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "A competitor is required to close a deal as lost",
+	},
+	"INVALID_LOSS_REASON": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "The loss reason does not exist or is inactive",
+	},
+	"INVALID_COMPETITOR": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "The competitor does not exist or is inactive",
+	},
 	"ACCOUNT_CREATION_FAILED": {
 		HTTPStatus: http.StatusUnprocessableEntity,
 		Message:    "Failed to create account",
@@ -178,7 +286,19 @@ var ErrorCodeMap = map[string]ErrorInfo{
 	},
 	"AI_SERVICE_NOT_CONFIGURED": {
 		HTTPStatus: http.StatusServiceUnavailable,
-		Message:    "AI service is not configured. Please configure Cerebras API key",
+		Message:    "AI service is not configured. Please configure the AI provider and API key",
+	},
+	"AI_DATA_ACCESS_DENIED": {
+		HTTPStatus: http.StatusForbidden,
+		Message:    "AI access to this data is disabled",
+	},
+	"AI_PROVIDER_CONFIG_INVALID": {
+		HTTPStatus: http.StatusBadRequest,
+		Message:    "AI provider configuration is invalid",
+	},
+	"AI_TOKEN_QUOTA_EXCEEDED": {
+		HTTPStatus: http.StatusTooManyRequests,
+		Message:    "Monthly AI token quota for your role has been used up",
 	},
 }
 
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvWriter buffers rows until Flush, so nothing reaches the client before the first batch is read
+type csvWriter struct {
+	out    io.Writer
+	buffer *bufio.Writer
+	writer *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	buffer := bufio.NewWriterSize(w, 64*1024)
+	// UTF-8 BOM so Excel opens names with accents correctly
+	buffer.WriteString("\xef\xbb\xbf")
+
+	cw := &csvWriter{out: w, buffer: buffer, writer: csv.NewWriter(buffer), record: make([]string, len(columns))}
+	for i, column := range columns {
+		cw.record[i] = column.Header
+	}
+	if err := cw.writer.Write(cw.record); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) WriteRow(values []interface{}) error {
+	for i := range cw.record {
+		cw.record[i] = ""
+		if i < len(values) {
+			cw.record[i] = csvValue(normalize(values[i]))
+		}
+	}
+	return cw.writer.Write(cw.record)
+}
+
+func (cw *csvWriter) Flush() error {
+	cw.writer.Flush()
+	if err := cw.writer.Error(); err != nil {
+		return err
+	}
+	if err := cw.buffer.Flush(); err != nil {
+		return err
+	}
+	flushClient(cw.out)
+	return nil
+}
+
+func (cw *csvWriter) Close() error {
+	return cw.Flush()
+}
+
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return escapeFormula(v)
+	case time.Time:
+		return v.Format(dateTimeLayout)
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// escapeFormula prefixes text that a spreadsheet would run as a formula with a quote (CSV injection).
+// Phone numbers such as +62 812-3456 are left alone.
+func escapeFormula(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case '=', '@', '\t', '\r':
+		return "'" + value
+	case '+', '-':
+		if strings.Trim(value[1:], "0123456789 -().") != "" {
+			return "'" + value
+		}
+	}
+	return value
+}
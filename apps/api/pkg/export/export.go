@@ -0,0 +1,144 @@
+// Package export writes tabular data as CSV, XLSX or NDJSON one row at a time,
+// so list exports can be streamed to the client instead of built in memory.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gilabs/crm-healthcare/api/pkg/response"
+)
+
+// Format is an export file format
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatXLSX   Format = "xlsx"
+	FormatNDJSON Format = "ndjson"
+)
+
+// Formats lists the accepted values of the format query parameter
+var Formats = []string{string(FormatCSV), string(FormatXLSX), string(FormatNDJSON)}
+
+// BatchSize is the number of rows repositories load at a time when streaming an export
+const BatchSize = 500
+
+// ErrInvalidFormat is returned for an unsupported export format
+var ErrInvalidFormat = errors.New("invalid export format")
+
+// dateTimeLayout is used for times in CSV files; XLSX cells get a date format and NDJSON uses RFC 3339
+const dateTimeLayout = "2006-01-02 15:04:05"
+
+// ParseFormat parses the format query parameter; an empty value means CSV and "excel" is accepted for XLSX like the report exports
+func ParseFormat(value string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "csv":
+		return FormatCSV, nil
+	case "xlsx", "excel":
+		return FormatXLSX, nil
+	case "ndjson", "jsonl":
+		return FormatNDJSON, nil
+	}
+	return "", ErrInvalidFormat
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// FileName returns the download file name of an export, e.g. accounts_20250101_150405.csv
+func FileName(name string, f Format, now time.Time) string {
+	return fmt.Sprintf("%s_%s.%s", name, now.In(response.GetTimezoneWIB()).Format("20060102_150405"), f)
+}
+
+// Column is one column of an export; Key names the field in NDJSON and Header titles the column in CSV and XLSX
+type Column struct {
+	Key    string
+	Header string
+}
+
+// Writer writes the rows of an export. Values of a row follow the order of the columns;
+// strings, numbers, booleans, times and nil are supported, as are pointers to them.
+type Writer interface {
+	// WriteRow writes one row
+	WriteRow(values []interface{}) error
+
+	// Flush sends the rows written so far to the client where the format allows it
+	Flush() error
+
+	// Close finishes the file; it must be called once after the last row
+	Close() error
+}
+
+// NewWriter creates a writer of the given format and writes the header; sheet names the XLSX worksheet
+func NewWriter(f Format, w io.Writer, sheet string, columns []Column) (Writer, error) {
+	switch f {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatXLSX:
+		return newXLSXWriter(w, sheet, columns)
+	case FormatNDJSON:
+		return newNDJSONWriter(w, columns), nil
+	}
+	return nil, ErrInvalidFormat
+}
+
+// normalize dereferences pointers and converts times to WIB so the writers only see plain values
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *string:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *int:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *int64:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *float64:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *bool:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return v.In(response.GetTimezoneWIB())
+	case time.Time:
+		if v.IsZero() {
+			return nil
+		}
+		return v.In(response.GetTimezoneWIB())
+	}
+	return value
+}
+
+// flushClient pushes buffered response bytes to the client when the underlying writer is an HTTP response
+func flushClient(w io.Writer) {
+	if flusher, ok := w.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+}
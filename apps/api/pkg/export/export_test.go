@@ -0,0 +1,171 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var testColumns = []Column{
+	{Key: "name", Header: "Name"},
+	{Key: "phone", Header: "Phone"},
+	{Key: "score", Header: "Score"},
+	{Key: "assigned_to", Header: "Assigned To"},
+	{Key: "created_at", Header: "Created At"},
+}
+
+func testRows() [][]interface{} {
+	created := time.Date(2025, 3, 1, 2, 30, 0, 0, time.UTC) // 09:30 WIB
+	return [][]interface{}{
+		{"Apotek Sehat, Tbk", "+62 812-3456-7890", 85, (*string)(nil), created},
+		{"=HYPERLINK(\"http://evil\")", "-", 0, strPtr("user-1"), &created},
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func writeAll(t *testing.T, f Format) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(f, &buf, "leads", testColumns)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	for _, row := range testRows() {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Format
+		wantErr bool
+	}{
+		{value: "", want: FormatCSV},
+		{value: "CSV", want: FormatCSV},
+		{value: "xlsx", want: FormatXLSX},
+		{value: "excel", want: FormatXLSX},
+		{value: "ndjson", want: FormatNDJSON},
+		{value: "pdf", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.value)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidFormat) {
+				t.Errorf("ParseFormat(%q): expected ErrInvalidFormat, got %v", tt.value, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	got := string(writeAll(t, FormatCSV))
+
+	want := "\xef\xbb\xbf" +
+		"Name,Phone,Score,Assigned To,Created At\n" +
+		"\"Apotek Sehat, Tbk\",+62 812-3456-7890,85,,2025-03-01 09:30:00\n" +
+		"\"'=HYPERLINK(\"\"http://evil\"\")\",-,0,user-1,2025-03-01 09:30:00\n"
+	if got != want {
+		t.Errorf("unexpected CSV:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestCSVWriter_BuffersUntilFlush(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, "leads", testColumns)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if err := w.WriteRow(testRows()[0]); err != nil {
+		t.Fatalf("WriteRow: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected nothing written before Flush, got %d bytes", buf.Len())
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Apotek Sehat") {
+		t.Errorf("expected the row after Flush, got %q", buf.String())
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(string(writeAll(t, FormatNDJSON)), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), lines)
+	}
+
+	want := `{"name":"Apotek Sehat, Tbk","phone":"+62 812-3456-7890","score":85,"assigned_to":null,"created_at":"2025-03-01T09:30:00+07:00"}`
+	if lines[0] != want {
+		t.Errorf("unexpected first line:\n%s\nwant:\n%s", lines[0], want)
+	}
+	// Formulas are only escaped for spreadsheets
+	if !strings.Contains(lines[1], `"name":"=HYPERLINK(\"http://evil\")"`) || !strings.Contains(lines[1], `"assigned_to":"user-1"`) {
+		t.Errorf("unexpected second line: %s", lines[1])
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	f, err := excelize.OpenReader(bytes.NewReader(writeAll(t, FormatXLSX)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer f.Close()
+
+	if sheets := f.GetSheetList(); len(sheets) != 1 || sheets[0] != "leads" {
+		t.Fatalf("expected one sheet named leads, got %v", sheets)
+	}
+	rows, err := f.GetRows("leads")
+	if err != nil {
+		t.Fatalf("GetRows: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected header and 2 rows, got %d", len(rows))
+	}
+	if strings.Join(rows[0], "|") != "Name|Phone|Score|Assigned To|Created At" {
+		t.Errorf("unexpected header: %v", rows[0])
+	}
+	if rows[1][0] != "Apotek Sehat, Tbk" || rows[1][2] != "85" || rows[1][4] != "2025-03-01 09:30" {
+		t.Errorf("unexpected first row: %v", rows[1])
+	}
+	// Strings are stored as text, never as formulas
+	if formula, _ := f.GetCellFormula("leads", "A3"); formula != "" {
+		t.Errorf("expected no formula in A3, got %q", formula)
+	}
+}
+
+func TestEscapeFormula(t *testing.T) {
+	tests := map[string]string{
+		"=1+2":            "'=1+2",
+		"@SUM(A1)":        "'@SUM(A1)",
+		"+cmd|' /C calc'": "'+cmd|' /C calc'",
+		"-2+3":            "'-2+3",
+		"+62 21 555-0100": "+62 21 555-0100",
+		"-15.5":           "-15.5",
+		"Budi":            "Budi",
+	}
+	for value, want := range tests {
+		if got := escapeFormula(value); got != want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
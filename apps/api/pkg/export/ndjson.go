@@ -0,0 +1,56 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// ndjsonWriter writes one JSON object per line with the fields in column order; like CSV, rows are buffered until Flush
+type ndjsonWriter struct {
+	out    io.Writer
+	writer *bufio.Writer
+	keys   [][]byte
+}
+
+func newNDJSONWriter(w io.Writer, columns []Column) *ndjsonWriter {
+	keys := make([][]byte, len(columns))
+	for i, column := range columns {
+		keys[i], _ = json.Marshal(column.Key)
+	}
+	return &ndjsonWriter{out: w, writer: bufio.NewWriterSize(w, 64*1024), keys: keys}
+}
+
+func (nw *ndjsonWriter) WriteRow(values []interface{}) error {
+	nw.writer.WriteByte('{')
+	for i, key := range nw.keys {
+		var value interface{}
+		if i < len(values) {
+			value = normalize(values[i])
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			nw.writer.WriteByte(',')
+		}
+		nw.writer.Write(key)
+		nw.writer.WriteByte(':')
+		nw.writer.Write(encoded)
+	}
+	_, err := nw.writer.WriteString("}\n")
+	return err
+}
+
+func (nw *ndjsonWriter) Flush() error {
+	if err := nw.writer.Flush(); err != nil {
+		return err
+	}
+	flushClient(nw.out)
+	return nil
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nw.Flush()
+}
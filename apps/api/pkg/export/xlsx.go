@@ -0,0 +1,120 @@
+package export
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrTooManyRows is returned when an XLSX export would not fit on one worksheet
+var ErrTooManyRows = errors.New("too many rows for an XLSX worksheet, export as CSV or NDJSON instead")
+
+// maxXLSXRows is the row limit of an Excel worksheet
+const maxXLSXRows = 1048576
+
+// xlsxWriter writes rows through the excelize stream writer, which spills them to a temporary file
+// instead of keeping them in memory. The workbook itself can only be sent once it is complete, on Close.
+type xlsxWriter struct {
+	out       io.Writer
+	file      *excelize.File
+	stream    *excelize.StreamWriter
+	row       int
+	width     int
+	dateStyle int
+}
+
+func newXLSXWriter(w io.Writer, sheet string, columns []Column) (*xlsxWriter, error) {
+	f := excelize.NewFile()
+	defaultSheet := f.GetSheetName(0)
+	if sheet != "" && sheet != defaultSheet {
+		if err := f.SetSheetName(defaultSheet, sheet); err != nil {
+			f.Close()
+			return nil, err
+		}
+	} else {
+		sheet = defaultSheet
+	}
+
+	stream, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#1E40AF"}, Pattern: 1},
+		Font: &excelize.Font{Bold: true, Color: "#FFFFFF"},
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	dateFormat := "yyyy-mm-dd hh:mm"
+	dateStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	// Column widths and panes must be set before the first row
+	if len(columns) > 0 {
+		if err := stream.SetColWidth(1, len(columns), 20); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	if err := stream.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = excelize.Cell{StyleID: headerStyle, Value: column.Header}
+	}
+	if err := stream.SetRow("A1", header); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &xlsxWriter{out: w, file: f, stream: stream, row: 1, width: len(columns), dateStyle: dateStyle}, nil
+}
+
+func (xw *xlsxWriter) WriteRow(values []interface{}) error {
+	if xw.row == maxXLSXRows {
+		return ErrTooManyRows
+	}
+	xw.row++
+
+	cells := make([]interface{}, xw.width)
+	for i := range cells {
+		if i >= len(values) {
+			break
+		}
+		value := normalize(values[i])
+		if t, ok := value.(time.Time); ok {
+			value = excelize.Cell{StyleID: xw.dateStyle, Value: t}
+		}
+		cells[i] = value
+	}
+	cell, err := excelize.CoordinatesToCellName(1, xw.row)
+	if err != nil {
+		return err
+	}
+	return xw.stream.SetRow(cell, cells)
+}
+
+// Flush does nothing; an XLSX file is a zip archive that is only written once all rows are known
+func (xw *xlsxWriter) Flush() error {
+	return nil
+}
+
+func (xw *xlsxWriter) Close() error {
+	defer xw.file.Close()
+	if err := xw.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := xw.file.WriteTo(xw.out)
+	return err
+}
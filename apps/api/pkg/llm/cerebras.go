@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/gilabs/crm-healthcare/api/pkg/cerebras"
+)
+
+// cerebrasProvider adapts the Cerebras API client to the Provider interface
+type cerebrasProvider struct {
+	client *cerebras.Client
+}
+
+// NewCerebrasProvider wraps an existing Cerebras client
+func NewCerebrasProvider(client *cerebras.Client) Provider {
+	return &cerebrasProvider{client: client}
+}
+
+func cerebrasClient(cfg Config) *cerebras.Client {
+	baseURL := cfg.BaseURL
+	if baseURL != "" {
+		baseURL = normalizeBaseURL(baseURL)
+	}
+	return cerebras.NewClient(baseURL, cfg.APIKey, cfg.Model)
+}
+
+func (p *cerebrasProvider) Name() string {
+	return ProviderCerebras
+}
+
+func (p *cerebrasProvider) Generate(req *GenerateRequest) (*GenerateResponse, error) {
+	response, err := p.client.Generate(&cerebras.GenerateRequest{
+		Prompt:      req.Prompt,
+		Model:       req.Model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &GenerateResponse{Text: response.Text, Tokens: response.Tokens}, nil
+}
+
+func (p *cerebrasProvider) Chat(req *ChatRequest) (*ChatResponse, error) {
+	response, err := p.client.Chat(toCerebrasChatRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return fromCerebrasChatResponse(response), nil
+}
+
+func (p *cerebrasProvider) ChatStream(ctx context.Context, req *ChatRequest, onDelta func(content string) error) (*ChatResponse, error) {
+	response, err := p.client.ChatStream(ctx, toCerebrasChatRequest(req), onDelta)
+	if err != nil {
+		return nil, err
+	}
+	return fromCerebrasChatResponse(response), nil
+}
+
+func toCerebrasChatRequest(req *ChatRequest) *cerebras.ChatRequest {
+	messages := make([]cerebras.ChatMessage, len(req.Messages))
+	for i, m := range req.Messages {
+		messages[i] = cerebras.ChatMessage{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+		for _, call := range m.ToolCalls {
+			messages[i].ToolCalls = append(messages[i].ToolCalls, cerebras.ToolCall{
+				ID:       call.ID,
+				Type:     "function",
+				Function: cerebras.ToolCallFunction{Name: call.Name, Arguments: call.Arguments},
+			})
+		}
+	}
+
+	var tools []cerebras.Tool
+	for _, t := range req.Tools {
+		tools = append(tools, cerebras.Tool{
+			Type:     "function",
+			Function: cerebras.ToolFunction{Name: t.Name, Description: t.Description, Parameters: t.Parameters},
+		})
+	}
+
+	return &cerebras.ChatRequest{
+		Messages:    messages,
+		Model:       req.Model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		Tools:       tools,
+	}
+}
+
+func fromCerebrasChatResponse(response *cerebras.ChatResponse) *ChatResponse {
+	message := Message{Role: response.Message.Role, Content: response.Message.Content}
+	for _, call := range response.Message.ToolCalls {
+		message.ToolCalls = append(message.ToolCalls, ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		})
+	}
+	return &ChatResponse{
+		Message: message,
+		Tokens:  response.Tokens,
+	}
+}
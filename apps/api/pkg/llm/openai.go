@@ -0,0 +1,265 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// openAIProvider calls an OpenAI-compatible chat completions API (OpenAI, Ollama, vLLM, ...)
+type openAIProvider struct {
+	name             string
+	baseURL          string
+	apiKey           string
+	model            string
+	httpClient       *http.Client
+	streamHTTPClient *http.Client
+}
+
+// NewOpenAIProvider creates a provider for an OpenAI-compatible server.
+// apiKey may be empty for self-hosted servers that do not require authentication.
+func NewOpenAIProvider(name, baseURL, apiKey, model string) Provider {
+	streamTransport := http.DefaultTransport.(*http.Transport).Clone()
+	streamTransport.ResponseHeaderTimeout = 120 * time.Second
+
+	return &openAIProvider{
+		name:    name,
+		baseURL: normalizeBaseURL(baseURL),
+		apiKey:  apiKey,
+		model:   model,
+		httpClient: &http.Client{
+			Timeout: 120 * time.Second,
+		},
+		streamHTTPClient: &http.Client{
+			Transport: streamTransport, // No overall timeout, streams end when the model finishes
+		},
+	}
+}
+
+func (p *openAIProvider) Name() string {
+	return p.name
+}
+
+// Generate completes a single prompt by sending it as the only user message,
+// since the legacy completions endpoint is not available on all servers
+func (p *openAIProvider) Generate(req *GenerateRequest) (*GenerateResponse, error) {
+	response, err := p.Chat(&ChatRequest{
+		Messages:    []Message{{Role: "user", Content: req.Prompt}},
+		Model:       req.Model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &GenerateResponse{Text: response.Message.Content, Tokens: response.Tokens}, nil
+}
+
+func (p *openAIProvider) Chat(req *ChatRequest) (*ChatResponse, error) {
+	httpReq, err := p.newChatRequest(context.Background(), req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, p.apiError(resp.StatusCode, body)
+	}
+
+	var apiResponse struct {
+		Choices []struct {
+			Message      wireMessage `json:"message"`
+			FinishReason string      `json:"finish_reason"`
+		} `json:"choices"`
+		Usage struct {
+			TotalTokens int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w, body: %s", err, string(body))
+	}
+	if len(apiResponse.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in response")
+	}
+
+	message := apiResponse.Choices[0].Message.toMessage()
+	if message.Role == "" {
+		message.Role = "assistant"
+	}
+	if apiResponse.Choices[0].FinishReason == "length" {
+		message.Content += truncatedResponseWarning
+	}
+
+	return &ChatResponse{
+		Message: message,
+		Tokens:  apiResponse.Usage.TotalTokens,
+	}, nil
+}
+
+func (p *openAIProvider) ChatStream(ctx context.Context, req *ChatRequest, onDelta func(content string) error) (*ChatResponse, error) {
+	httpReq, err := p.newChatRequest(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.streamHTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, p.apiError(resp.StatusCode, body)
+	}
+
+	var content strings.Builder
+	var toolCalls []wireToolCall
+	var tokens int
+	var finishReason string
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue // Skip blank separators, comments and event names
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content   string              `json:"content"`
+					ToolCalls []wireToolCallDelta `json:"tool_calls"`
+				} `json:"delta"`
+				FinishReason string `json:"finish_reason"`
+			} `json:"choices"`
+			Usage *struct {
+				TotalTokens int `json:"total_tokens"`
+			} `json:"usage"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to parse stream chunk: %w, data: %s", err, data)
+		}
+		if chunk.Error != nil {
+			return nil, fmt.Errorf("%s API error: %s", p.name, chunk.Error.Message)
+		}
+		if chunk.Usage != nil {
+			tokens = chunk.Usage.TotalTokens
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+			toolCalls = appendToolCallDeltas(toolCalls, choice.Delta.ToolCalls)
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if err := onDelta(choice.Delta.Content); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	if finishReason == "length" {
+		if err := onDelta(truncatedResponseWarning); err != nil {
+			return nil, err
+		}
+		content.WriteString(truncatedResponseWarning)
+	}
+
+	message := wireMessage{Role: "assistant", Content: content.String(), ToolCalls: toolCalls}
+	return &ChatResponse{
+		Message: message.toMessage(),
+		Tokens:  tokens,
+	}, nil
+}
+
+// newChatRequest builds a chat completions HTTP request
+func (p *openAIProvider) newChatRequest(ctx context.Context, req *ChatRequest, stream bool) (*http.Request, error) {
+	if req.MaxTokens == 0 {
+		req.MaxTokens = 2000
+	}
+	if req.Temperature == 0 {
+		req.Temperature = 0.7
+	}
+
+	model := req.Model
+	if model == "" {
+		model = p.model
+	}
+
+	requestBody := map[string]interface{}{
+		"model":       model,
+		"messages":    toWireMessages(req.Messages),
+		"max_tokens":  req.MaxTokens,
+		"temperature": req.Temperature,
+	}
+	if len(req.Tools) > 0 {
+		requestBody["tools"] = toWireTools(req.Tools)
+	}
+	if stream {
+		requestBody["stream"] = true
+		requestBody["stream_options"] = map[string]interface{}{"include_usage": true} // Usage is sent in the final chunk
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/v1/chat/completions", p.baseURL), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.apiKey))
+	}
+
+	return httpReq, nil
+}
+
+// apiError builds an error from a non-200 response, preferring the API error message
+func (p *openAIProvider) apiError(statusCode int, body []byte) error {
+	var errorResponse struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Error.Message != "" {
+		return fmt.Errorf("%s API error (status %d): %s", p.name, statusCode, errorResponse.Error.Message)
+	}
+	return fmt.Errorf("%s API error (status %d): %s", p.name, statusCode, string(body))
+}
@@ -0,0 +1,148 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Provider names as stored in AI settings
+const (
+	ProviderCerebras         = "cerebras"
+	ProviderOpenAI           = "openai"
+	ProviderOpenAICompatible = "openai_compatible" // Self-hosted OpenAI-compatible servers such as Ollama or vLLM
+)
+
+var (
+	ErrUnsupportedProvider = errors.New("unsupported LLM provider")
+	ErrAPIKeyRequired      = errors.New("API key is required for this provider")
+	ErrBaseURLRequired     = errors.New("base URL is required for this provider")
+)
+
+// truncatedResponseWarning is appended when a response stops at the token limit
+const truncatedResponseWarning = "\n\n⚠️ *Catatan: Response mungkin terpotong karena mencapai batas token. Silakan coba pertanyaan yang lebih spesifik atau minta data dalam batch yang lebih kecil, atau gunakan model yang lebih advanced*"
+
+// Message represents a single chat message
+type Message struct {
+	Role       string     `json:"role"` // "system", "user", "assistant" or "tool"
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"-"` // Functions the assistant wants to call
+	ToolCallID string     `json:"-"` // Call answered by a "tool" message
+}
+
+// Tool describes a function the model may call to fetch data before answering
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]interface{} // JSON schema of the arguments
+}
+
+// ToolCall represents a function call requested by the model
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments string // JSON encoded arguments
+}
+
+// GenerateRequest represents a single-prompt completion request
+type GenerateRequest struct {
+	Prompt      string
+	Model       string // Optional, defaults to the provider model
+	MaxTokens   int
+	Temperature float64
+}
+
+// GenerateResponse represents a single-prompt completion response
+type GenerateResponse struct {
+	Text   string
+	Tokens int
+}
+
+// ChatRequest represents a chat completion request
+type ChatRequest struct {
+	Messages    []Message
+	Model       string // Optional, defaults to the provider model
+	MaxTokens   int
+	Temperature float64
+	Tools       []Tool // Optional, functions the model may call instead of answering
+}
+
+// ChatResponse represents a chat completion response
+type ChatResponse struct {
+	Message Message
+	Tokens  int
+}
+
+// Provider is a large language model backend used by the AI service
+type Provider interface {
+	// Name returns the provider name as stored in AI settings
+	Name() string
+
+	// Generate completes a single prompt
+	Generate(req *GenerateRequest) (*GenerateResponse, error)
+
+	// Chat completes a conversation
+	Chat(req *ChatRequest) (*ChatResponse, error)
+
+	// ChatStream completes a conversation and calls onDelta for every chunk as it arrives.
+	// Returning an error from onDelta aborts the stream.
+	ChatStream(ctx context.Context, req *ChatRequest, onDelta func(content string) error) (*ChatResponse, error)
+}
+
+// Config represents the settings needed to build a provider
+type Config struct {
+	Provider string
+	BaseURL  string
+	APIKey   string
+	Model    string
+}
+
+// Validate checks that the provider is supported and has the settings it cannot work without.
+// A Cerebras API key is not required here since it may come from the environment.
+func (c Config) Validate() error {
+	switch c.Provider {
+	case "", ProviderCerebras:
+		return nil
+	case ProviderOpenAI:
+		if c.APIKey == "" {
+			return ErrAPIKeyRequired
+		}
+		return nil
+	case ProviderOpenAICompatible:
+		if c.BaseURL == "" {
+			return ErrBaseURLRequired
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedProvider, c.Provider)
+	}
+}
+
+// NewProvider builds the provider selected in the config
+func NewProvider(cfg Config) (Provider, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch cfg.Provider {
+	case ProviderOpenAI:
+		baseURL := cfg.BaseURL
+		if baseURL == "" {
+			baseURL = "https://api.openai.com"
+		}
+		return NewOpenAIProvider(ProviderOpenAI, baseURL, cfg.APIKey, cfg.Model), nil
+	case ProviderOpenAICompatible:
+		return NewOpenAIProvider(ProviderOpenAICompatible, cfg.BaseURL, cfg.APIKey, cfg.Model), nil
+	default:
+		if cfg.APIKey == "" {
+			return nil, ErrAPIKeyRequired
+		}
+		return NewCerebrasProvider(cerebrasClient(cfg)), nil
+	}
+}
+
+// normalizeBaseURL strips a trailing slash and "/v1" so endpoint paths can be appended
+func normalizeBaseURL(baseURL string) string {
+	return strings.TrimSuffix(strings.TrimRight(baseURL, "/"), "/v1")
+}
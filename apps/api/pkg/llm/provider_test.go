@@ -0,0 +1,132 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		want    string
+		wantErr error
+	}{
+		{name: "cerebras", cfg: Config{Provider: ProviderCerebras, APIKey: "key"}, want: ProviderCerebras},
+		{name: "empty defaults to cerebras", cfg: Config{APIKey: "key"}, want: ProviderCerebras},
+		{name: "cerebras without key", cfg: Config{Provider: ProviderCerebras}, wantErr: ErrAPIKeyRequired},
+		{name: "openai", cfg: Config{Provider: ProviderOpenAI, APIKey: "key"}, want: ProviderOpenAI},
+		{name: "openai without key", cfg: Config{Provider: ProviderOpenAI}, wantErr: ErrAPIKeyRequired},
+		{name: "openai compatible without key", cfg: Config{Provider: ProviderOpenAICompatible, BaseURL: "http://localhost:11434"}, want: ProviderOpenAICompatible},
+		{name: "openai compatible without base URL", cfg: Config{Provider: ProviderOpenAICompatible}, wantErr: ErrBaseURLRequired},
+		{name: "unsupported", cfg: Config{Provider: "anthropic", APIKey: "key"}, wantErr: ErrUnsupportedProvider},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewProvider(tt.cfg)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if provider.Name() != tt.want {
+				t.Errorf("expected provider %s, got %s", tt.want, provider.Name())
+			}
+		})
+	}
+}
+
+func TestOpenAIProvider_Chat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "" {
+			http.Error(w, "unexpected authorization header", http.StatusBadRequest)
+			return
+		}
+
+		var body struct {
+			Model    string    `json:"model"`
+			Messages []Message `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":"%s replied to %d messages"},"finish_reason":"stop"}],"usage":{"total_tokens":9}}`, body.Model, len(body.Messages))
+	}))
+	defer server.Close()
+
+	// Base URL with trailing /v1 as commonly copied from Ollama docs
+	provider, err := NewProvider(Config{Provider: ProviderOpenAICompatible, BaseURL: server.URL + "/v1/", Model: "llama3.1"})
+	if err != nil {
+		t.Fatalf("NewProvider returned error: %v", err)
+	}
+
+	response, err := provider.Chat(&ChatRequest{
+		Messages: []Message{{Role: "system", Content: "You are helpful"}, {Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("Chat returned error: %v", err)
+	}
+
+	if response.Message.Content != "llama3.1 replied to 2 messages" {
+		t.Errorf("unexpected content: %q", response.Message.Content)
+	}
+	if response.Tokens != 9 {
+		t.Errorf("expected 9 tokens, got %d", response.Tokens)
+	}
+}
+
+func TestOpenAIProvider_ChatStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":{"message":"invalid api key"}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hello\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\" world\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"total_tokens\":5}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(Config{Provider: ProviderOpenAI, BaseURL: server.URL, APIKey: "sk-test", Model: "gpt-4o-mini"})
+	if err != nil {
+		t.Fatalf("NewProvider returned error: %v", err)
+	}
+
+	var streamed string
+	response, err := provider.ChatStream(context.Background(), &ChatRequest{
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	}, func(content string) error {
+		streamed += content
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatStream returned error: %v", err)
+	}
+
+	if streamed != "Hello world" || response.Message.Content != "Hello world" {
+		t.Errorf("unexpected streamed content %q, message %q", streamed, response.Message.Content)
+	}
+	if response.Tokens != 5 {
+		t.Errorf("expected 5 tokens, got %d", response.Tokens)
+	}
+}
@@ -0,0 +1,94 @@
+package llm
+
+// wireMessage is a chat message in the OpenAI chat completions format
+type wireMessage struct {
+	Role       string         `json:"role"`
+	Content    string         `json:"content"`
+	ToolCalls  []wireToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+// wireToolCall is a tool call in the OpenAI chat completions format
+type wireToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// wireToolCallDelta is a streamed fragment of a tool call; arguments arrive in pieces for the same index
+type wireToolCallDelta struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// wireTool is a function definition in the OpenAI chat completions format
+type wireTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string                 `json:"name"`
+		Description string                 `json:"description"`
+		Parameters  map[string]interface{} `json:"parameters"`
+	} `json:"function"`
+}
+
+func toWireMessages(messages []Message) []wireMessage {
+	wire := make([]wireMessage, len(messages))
+	for i, m := range messages {
+		wire[i] = wireMessage{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+		for _, call := range m.ToolCalls {
+			var wireCall wireToolCall
+			wireCall.ID = call.ID
+			wireCall.Type = "function"
+			wireCall.Function.Name = call.Name
+			wireCall.Function.Arguments = call.Arguments
+			wire[i].ToolCalls = append(wire[i].ToolCalls, wireCall)
+		}
+	}
+	return wire
+}
+
+func toWireTools(tools []Tool) []wireTool {
+	wire := make([]wireTool, len(tools))
+	for i, t := range tools {
+		wire[i].Type = "function"
+		wire[i].Function.Name = t.Name
+		wire[i].Function.Description = t.Description
+		wire[i].Function.Parameters = t.Parameters
+	}
+	return wire
+}
+
+func (m wireMessage) toMessage() Message {
+	message := Message{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+	for _, call := range m.ToolCalls {
+		message.ToolCalls = append(message.ToolCalls, ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		})
+	}
+	return message
+}
+
+// appendToolCallDeltas merges streamed tool call fragments into complete tool calls
+func appendToolCallDeltas(calls []wireToolCall, deltas []wireToolCallDelta) []wireToolCall {
+	for _, d := range deltas {
+		for len(calls) <= d.Index {
+			calls = append(calls, wireToolCall{Type: "function"})
+		}
+		call := &calls[d.Index]
+		if d.ID != "" {
+			call.ID = d.ID
+		}
+		call.Function.Name += d.Function.Name
+		call.Function.Arguments += d.Function.Arguments
+	}
+	return calls
+}
@@ -0,0 +1,164 @@
+// Package mailer sends email with attachments over SMTP.
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNoRecipients     = errors.New("email has no recipients")
+	ErrInvalidRecipient = errors.New("invalid recipient address")
+)
+
+// Sender delivers email messages
+type Sender interface {
+	Send(msg *Message) error
+}
+
+// Message is a plain text email with an optional HTML alternative and attachments
+type Message struct {
+	To          []string
+	Subject     string
+	Body        string
+	HTMLBody    string // Sent as a multipart/alternative of Body when set
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to a message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// build renders the message as RFC 5322 text with a multipart/mixed body
+func (m *Message) build(from mail.Address, now time.Time) ([]byte, error) {
+	if len(m.To) == 0 {
+		return nil, ErrNoRecipients
+	}
+	to := make([]string, len(m.To))
+	for i, address := range m.To {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRecipient, address)
+		}
+		to[i] = parsed.String()
+	}
+
+	var buf bytes.Buffer
+	body := multipart.NewWriter(&buf)
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", from.String())
+	header("To", strings.Join(to, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", singleLine(m.Subject)))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@%s>", uuid.New().String(), domainOf(from.Address)))
+	header("MIME-Version", "1.0")
+	header("Content-Type", fmt.Sprintf("multipart/mixed; boundary=%q", body.Boundary()))
+	buf.WriteString("\r\n")
+
+	if m.HTMLBody == "" {
+		if err := writeTextPart(body, "text/plain", m.Body); err != nil {
+			return nil, err
+		}
+	} else {
+		var parts bytes.Buffer
+		alternative := multipart.NewWriter(&parts)
+		if err := writeTextPart(alternative, "text/plain", m.Body); err != nil {
+			return nil, err
+		}
+		if err := writeTextPart(alternative, "text/html", m.HTMLBody); err != nil {
+			return nil, err
+		}
+		if err := alternative.Close(); err != nil {
+			return nil, err
+		}
+		part, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type": {fmt.Sprintf("multipart/alternative; boundary=%q", alternative.Boundary())},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write(parts.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, attachment := range m.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"name": attachment.Filename})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64Lines(part, attachment.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeTextPart adds a quoted-printable UTF-8 part with CRLF line endings
+func writeTextPart(w *multipart.Writer, contentType string, text string) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType + "; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(text, "\n", "\r\n"))); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// writeBase64Lines writes data as base64 in lines of 76 characters, as RFC 2045 requires
+func writeBase64Lines(w interface{ Write([]byte) (int, error) }, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := w.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := w.Write([]byte(encoded + "\r\n"))
+	return err
+}
+
+// singleLine drops line breaks so a subject cannot inject headers
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(strings.NewReplacer("\r", " ", "\n", " ").Replace(s)), " ")
+}
+
+func domainOf(address string) string {
+	if i := strings.LastIndex(address, "@"); i >= 0 && i < len(address)-1 {
+		return address[i+1:]
+	}
+	return "localhost"
+}
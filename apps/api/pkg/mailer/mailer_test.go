@@ -0,0 +1,166 @@
+package mailer_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/gilabs/crm-healthcare/api/pkg/mailer"
+	"github.com/gilabs/crm-healthcare/api/pkg/mailer/mailertest"
+)
+
+func newSender(server *mailertest.Server, username, password string) *mailer.SMTPSender {
+	return mailer.NewSMTPSender(mailer.SMTPConfig{
+		Host:     server.Host,
+		Port:     server.Port,
+		Username: username,
+		Password: password,
+		From:     "reports@crm.example.com",
+		FromName: "CRM Healthcare",
+	})
+}
+
+func TestSMTPSender_SendsAttachment(t *testing.T) {
+	server := mailertest.NewServerWithAuth(t, "mailer", "secret")
+	sender := newSender(server, "mailer", "secret")
+
+	pdf := bytes.Repeat([]byte("%PDF-1.3 report "), 20)
+	err := sender.Send(&mailer.Message{
+		To:      []string{"Budi Santoso <budi@example.com>", "procurement@rs-sehat.co.id"},
+		Subject: "Weekly pipeline – 03 Mar 2025\r\nBcc: attacker@example.com",
+		Body:    "Attached is the pipeline report.\n.\nRegards",
+		Attachments: []mailer.Attachment{
+			{Filename: "pipeline-report-export.pdf", ContentType: "application/pdf", Data: pdf},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+	got := messages[0]
+	if got.From != "reports@crm.example.com" || strings.Join(got.To, ",") != "budi@example.com,procurement@rs-sehat.co.id" {
+		t.Errorf("unexpected envelope: from %q to %v", got.From, got.To)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(got.Data))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	if msg.Header.Get("Bcc") != "" {
+		t.Error("subject line break must not add headers")
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "Weekly pipeline – 03 Mar 2025 Bcc: attacker@example.com" {
+		t.Errorf("unexpected subject %q", subject)
+	}
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("content type: %v", err)
+	}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	text, err := reader.NextPart()
+	if err != nil {
+		t.Fatalf("text part: %v", err)
+	}
+	body, _ := io.ReadAll(text)
+	if string(body) != "Attached is the pipeline report.\r\n.\r\nRegards" {
+		t.Errorf("unexpected body %q", body)
+	}
+	attachment, err := reader.NextPart()
+	if err != nil {
+		t.Fatalf("attachment part: %v", err)
+	}
+	if attachment.FileName() != "pipeline-report-export.pdf" {
+		t.Errorf("unexpected filename %q", attachment.FileName())
+	}
+	data, _ := io.ReadAll(base64.NewDecoder(base64.StdEncoding, attachment))
+	if !bytes.Equal(data, pdf) {
+		t.Error("attachment does not round-trip")
+	}
+}
+
+func TestSMTPSender_Errors(t *testing.T) {
+	server := mailertest.NewServerWithAuth(t, "mailer", "secret")
+	msg := &mailer.Message{To: []string{"budi@example.com"}, Subject: "Report", Body: "Hi"}
+
+	if err := newSender(server, "mailer", "wrong").Send(msg); err == nil {
+		t.Error("expected an authentication error")
+	}
+
+	sender := newSender(server, "mailer", "secret")
+	if err := sender.Send(&mailer.Message{Subject: "Report"}); !errors.Is(err, mailer.ErrNoRecipients) {
+		t.Errorf("expected ErrNoRecipients, got %v", err)
+	}
+	if err := sender.Send(&mailer.Message{To: []string{"not an address"}}); !errors.Is(err, mailer.ErrInvalidRecipient) {
+		t.Errorf("expected ErrInvalidRecipient, got %v", err)
+	}
+
+	server.Reject("554 mailbox unavailable")
+	if err := sender.Send(msg); err == nil || !strings.Contains(err.Error(), "mailbox unavailable") {
+		t.Errorf("expected the rejection, got %v", err)
+	}
+	if n := len(server.Messages()); n != 0 {
+		t.Errorf("expected no accepted messages, got %d", n)
+	}
+}
+
+func TestSMTPSender_SendsHTMLAlternative(t *testing.T) {
+	server := mailertest.NewServer(t)
+	sender := newSender(server, "", "")
+
+	err := sender.Send(&mailer.Message{
+		To:       []string{"budi@example.com"},
+		Subject:  "Task reminder",
+		Body:     "Follow up with RS Sehat",
+		HTMLBody: "<p>Follow up with <strong>RS Sehat</strong></p>",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(server.Messages()[0].Data))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	_, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	part, err := multipart.NewReader(msg.Body, params["boundary"]).NextPart()
+	if err != nil {
+		t.Fatalf("alternative part: %v", err)
+	}
+	mediaType, params, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("expected multipart/alternative, got %q", mediaType)
+	}
+
+	alternatives := multipart.NewReader(part, params["boundary"])
+	var bodies []string
+	for {
+		alternative, err := alternatives.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart: %v", err)
+		}
+		data, _ := io.ReadAll(alternative)
+		bodies = append(bodies, alternative.Header.Get("Content-Type")+": "+string(data))
+	}
+	want := []string{
+		"text/plain; charset=utf-8: Follow up with RS Sehat",
+		"text/html; charset=utf-8: <p>Follow up with <strong>RS Sehat</strong></p>",
+	}
+	if strings.Join(bodies, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected alternatives %q", bodies)
+	}
+}
@@ -0,0 +1,193 @@
+// Package mailertest provides an in-process SMTP server for testing code that sends email.
+package mailertest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Message is an email accepted by the server
+type Message struct {
+	From string
+	To   []string
+	Data []byte // Raw message as sent after DATA
+}
+
+// Server is a minimal SMTP server listening on a random local port. It accepts every message
+// unless Reject is called, and requires AUTH PLAIN when created with credentials.
+type Server struct {
+	Host string
+	Port int
+
+	listener net.Listener
+	username string
+	password string
+
+	mu       sync.Mutex
+	messages []Message
+	reject   string
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a server that is shut down when the test ends
+func NewServer(t testing.TB) *Server {
+	return NewServerWithAuth(t, "", "")
+}
+
+// NewServerWithAuth starts a server that requires the given credentials
+func NewServerWithAuth(t testing.TB, username, password string) *Server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("mailertest: listen: %v", err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	s := &Server{Host: "127.0.0.1", Port: addr.Port, listener: listener, username: username, password: password}
+	s.wg.Add(1)
+	go s.serve()
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Close stops the server
+func (s *Server) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+// Messages returns the messages accepted so far
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Reject makes the server refuse further messages after DATA with the given reply, e.g. "554 mailbox full"
+func (s *Server) Reject(reply string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reject = reply
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(lines ...string) {
+		for _, line := range lines {
+			conn.Write([]byte(line + "\r\n"))
+		}
+	}
+
+	reply("220 mailertest ESMTP")
+	var current Message
+	authenticated := s.username == ""
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO":
+			if s.username != "" {
+				reply("250-mailertest", "250-8BITMIME", "250 AUTH PLAIN")
+			} else {
+				reply("250-mailertest", "250 8BITMIME")
+			}
+		case "HELO":
+			reply("250 mailertest")
+		case "AUTH":
+			fields := strings.Fields(line)
+			if len(fields) != 3 || strings.ToUpper(fields[1]) != "PLAIN" {
+				reply("504 unsupported authentication mechanism")
+				continue
+			}
+			decoded, _ := base64.StdEncoding.DecodeString(fields[2])
+			parts := bytes.Split(decoded, []byte{0})
+			if len(parts) == 3 && string(parts[1]) == s.username && string(parts[2]) == s.password {
+				authenticated = true
+				reply("235 authenticated")
+			} else {
+				reply("535 authentication failed")
+			}
+		case "MAIL":
+			if !authenticated {
+				reply("530 authentication required")
+				continue
+			}
+			current = Message{From: addressOf(line)}
+			reply("250 OK")
+		case "RCPT":
+			current.To = append(current.To, addressOf(line))
+			reply("250 OK")
+		case "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data bytes.Buffer
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+			current.Data = data.Bytes()
+
+			s.mu.Lock()
+			rejection := s.reject
+			if rejection == "" {
+				s.messages = append(s.messages, current)
+			}
+			s.mu.Unlock()
+			if rejection != "" {
+				reply(rejection)
+			} else {
+				reply("250 OK: queued as " + strconv.Itoa(len(s.Messages())))
+			}
+			current = Message{}
+		case "RSET":
+			current = Message{}
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+// addressOf extracts the address of a MAIL FROM:<...> or RCPT TO:<...> command
+func addressOf(line string) string {
+	start, end := strings.Index(line, "<"), strings.Index(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
@@ -0,0 +1,103 @@
+package mailer
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig configures an SMTP sender
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // Empty to send without authentication, e.g. to a local SMTP stand-in
+	Password string
+	From     string
+	FromName string
+	Timeout  time.Duration
+}
+
+// SMTPSender sends messages through an SMTP server. Port 465 uses implicit TLS; on other ports the connection
+// is upgraded with STARTTLS when the server offers it, so plain local servers such as Mailpit work unchanged.
+type SMTPSender struct {
+	config SMTPConfig
+	from   mail.Address
+}
+
+// NewSMTPSender creates a sender for the configured server
+func NewSMTPSender(config SMTPConfig) *SMTPSender {
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &SMTPSender{
+		config: config,
+		from:   mail.Address{Name: config.FromName, Address: config.From},
+	}
+}
+
+// Send delivers the message to all its recipients in one SMTP transaction
+func (s *SMTPSender) Send(msg *Message) error {
+	data, err := msg.build(s.from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	dialer := &net.Dialer{Timeout: s.config.Timeout}
+	var conn net.Conn
+	if s.config.Port == 465 {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: s.config.Host})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("connect to SMTP server: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(s.config.Timeout))
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if _, isTLS := conn.(*tls.Conn); !isTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: s.config.Host}); err != nil {
+				return fmt.Errorf("STARTTLS: %w", err)
+			}
+		}
+	}
+	if s.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.config.From); err != nil {
+		return fmt.Errorf("MAIL FROM: %w", err)
+	}
+	for _, to := range msg.To {
+		address, _ := mail.ParseAddress(to) // validated by build
+		if err := client.Rcpt(address.Address); err != nil {
+			return fmt.Errorf("RCPT TO %s: %w", address.Address, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("DATA: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return fmt.Errorf("write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("send message: %w", err)
+	}
+	return client.Quit()
+}
@@ -0,0 +1,118 @@
+// Package messaging sends SMS and WhatsApp messages through provider gateways.
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Channels
+const (
+	ChannelSMS      = "sms"
+	ChannelWhatsApp = "whatsapp"
+)
+
+// Delivery statuses reported by receipts
+const (
+	StatusSent      = "sent"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+var (
+	ErrInvalidPhone = errors.New("invalid phone number")
+	// ErrNoReceipt is returned for webhook payloads without a message ID, e.g. inbound WhatsApp messages
+	ErrNoReceipt = errors.New("payload is not a delivery receipt")
+)
+
+// Message is a text message to one phone number
+type Message struct {
+	Channel string
+	To      string // E.164, e.g. +6281234567890
+	Body    string
+}
+
+// Receipt is a delivery status reported by a provider
+type Receipt struct {
+	MessageID string // Provider message ID returned by Send
+	Status    string // sent, delivered, failed
+	Error     string
+}
+
+// Gateway sends messages through a provider and returns the provider message ID
+type Gateway interface {
+	Send(msg *Message) (string, error)
+}
+
+// ReceiptParser reads delivery receipts posted by a provider
+type ReceiptParser interface {
+	ParseReceipt(contentType string, body []byte) (*Receipt, error)
+}
+
+// PermanentError is a send error that retrying will not fix, e.g. a rejected phone number
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// IsPermanent reports whether err should not be retried
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// NormalizePhone returns a phone number in E.164 form. Local numbers starting with 0, and numbers
+// without a prefix such as 812..., get the default country code, e.g. 0812-3456-7890 becomes +6281234567890.
+func NormalizePhone(raw string, defaultCountryCode string) (string, error) {
+	var digits strings.Builder
+	international := false
+	for i, r := range strings.TrimSpace(raw) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+			international = true
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", fmt.Errorf("%w: %q", ErrInvalidPhone, raw)
+		}
+	}
+
+	number := digits.String()
+	switch {
+	case international:
+	case strings.HasPrefix(number, "00"):
+		number = number[2:]
+	case strings.HasPrefix(number, defaultCountryCode):
+	case strings.HasPrefix(number, "0"):
+		number = defaultCountryCode + number[1:]
+	default:
+		number = defaultCountryCode + number
+	}
+
+	// E.164 allows at most 15 digits; shorter than 8 is not a reachable mobile number
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPhone, raw)
+	}
+	return "+" + number, nil
+}
+
+// NormalizeStatus maps provider statuses such as Twilio's undelivered or WhatsApp's read to sent, delivered or failed
+func NormalizeStatus(status string) string {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "delivered", "read":
+		return StatusDelivered
+	case "failed", "undelivered", "rejected", "expired", "canceled":
+		return StatusFailed
+	default:
+		return StatusSent
+	}
+}
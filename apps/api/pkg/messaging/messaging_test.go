@@ -0,0 +1,145 @@
+package messaging
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"0812-3456-7890", "+6281234567890"},
+		{"+62 812 3456 7890", "+6281234567890"},
+		{"6281234567890", "+6281234567890"},
+		{"006281234567890", "+6281234567890"},
+		{"81234567890", "+6281234567890"},
+		{"(021) 555.1234", "+62215551234"},
+		{"+65 9123 4567", "+6591234567"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizePhone(tt.raw, "62")
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizePhone(%q) = %q, %v; want %q", tt.raw, got, err, tt.want)
+		}
+	}
+
+	for _, raw := range []string{"", "12345", "0812abc", "+62 812 3456 7890 1234", "62+812"} {
+		if got, err := NormalizePhone(raw, "62"); !errors.Is(err, ErrInvalidPhone) {
+			t.Errorf("NormalizePhone(%q) = %q, %v; want ErrInvalidPhone", raw, got, err)
+		}
+	}
+}
+
+func TestWebhookGateway_SendJSON(t *testing.T) {
+	var gotBody, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody, gotAuth = string(body), r.Header.Get("Authorization")
+		w.Write([]byte(`{"messaging_product":"whatsapp","messages":[{"id":"wamid.123"}]}`))
+	}))
+	defer server.Close()
+
+	gateway, err := NewWebhookGateway(WebhookConfig{
+		URL:            server.URL,
+		AuthHeader:     "Bearer token",
+		BodyTemplate:   `{"messaging_product":"whatsapp","to":{{json .To}},"type":"text","text":{"body":{{json .Body}}}}`,
+		MessageIDField: "messages.0.id",
+	})
+	if err != nil {
+		t.Fatalf("NewWebhookGateway: %v", err)
+	}
+
+	id, err := gateway.Send(&Message{Channel: ChannelWhatsApp, To: "+6281234567890", Body: "Visit \"RS Sehat\"\nat 10:00"})
+	if err != nil || id != "wamid.123" {
+		t.Fatalf("Send = %q, %v", id, err)
+	}
+	want := `{"messaging_product":"whatsapp","to":"+6281234567890","type":"text","text":{"body":"Visit \"RS Sehat\"\nat 10:00"}}`
+	if gotBody != want || gotAuth != "Bearer token" {
+		t.Errorf("unexpected request %s (auth %q)", gotBody, gotAuth)
+	}
+}
+
+func TestWebhookGateway_SendForm(t *testing.T) {
+	var got url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		got = r.PostForm
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid":"SM123","status":"queued"}`))
+	}))
+	defer server.Close()
+
+	gateway, _ := NewWebhookGateway(WebhookConfig{
+		URL:            server.URL,
+		ContentType:    "application/x-www-form-urlencoded",
+		BodyTemplate:   `To={{urlquery .To}}&From=%2B15005550006&Body={{urlquery .Body}}`,
+		MessageIDField: "sid",
+	})
+	id, err := gateway.Send(&Message{Channel: ChannelSMS, To: "+6281234567890", Body: "Call dr. Sari & confirm"})
+	if err != nil || id != "SM123" {
+		t.Fatalf("Send = %q, %v", id, err)
+	}
+	if got.Get("To") != "+6281234567890" || got.Get("Body") != "Call dr. Sari & confirm" || got.Get("From") != "+15005550006" {
+		t.Errorf("unexpected form %v", got)
+	}
+}
+
+func TestWebhookGateway_SendErrors(t *testing.T) {
+	status := http.StatusBadRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"message":"invalid number"}`))
+	}))
+	defer server.Close()
+
+	gateway, _ := NewWebhookGateway(WebhookConfig{URL: server.URL})
+	msg := &Message{Channel: ChannelSMS, To: "+6281234567890", Body: "Hi"}
+
+	if _, err := gateway.Send(msg); !IsPermanent(err) {
+		t.Errorf("expected a permanent error for 400, got %v", err)
+	}
+	status = http.StatusTooManyRequests
+	if _, err := gateway.Send(msg); err == nil || IsPermanent(err) {
+		t.Errorf("expected a retryable error for 429, got %v", err)
+	}
+	status = http.StatusBadGateway
+	if _, err := gateway.Send(msg); err == nil || IsPermanent(err) {
+		t.Errorf("expected a retryable error for 502, got %v", err)
+	}
+
+	if _, err := NewWebhookGateway(WebhookConfig{URL: server.URL, BodyTemplate: "{{.To"}); err == nil {
+		t.Error("expected a template error")
+	}
+}
+
+func TestWebhookGateway_ParseReceipt(t *testing.T) {
+	whatsapp, _ := NewWebhookGateway(WebhookConfig{
+		ReceiptIDField:     "entry.0.changes.0.value.statuses.0.id",
+		ReceiptStatusField: "entry.0.changes.0.value.statuses.0.status",
+		ReceiptErrorField:  "entry.0.changes.0.value.statuses.0.errors.0.title",
+	})
+	receipt, err := whatsapp.ParseReceipt("application/json", []byte(`{"entry":[{"changes":[{"value":{"statuses":[
+		{"id":"wamid.123","status":"failed","errors":[{"code":131026,"title":"Message undeliverable"}]}]}}]}]}`))
+	if err != nil {
+		t.Fatalf("ParseReceipt: %v", err)
+	}
+	if receipt.MessageID != "wamid.123" || receipt.Status != StatusFailed || receipt.Error != "Message undeliverable" {
+		t.Errorf("unexpected receipt %+v", receipt)
+	}
+
+	twilio, _ := NewWebhookGateway(WebhookConfig{ReceiptIDField: "MessageSid", ReceiptStatusField: "MessageStatus"})
+	receipt, err = twilio.ParseReceipt("application/x-www-form-urlencoded; charset=utf-8", []byte("MessageSid=SM123&MessageStatus=delivered"))
+	if err != nil || receipt.MessageID != "SM123" || receipt.Status != StatusDelivered {
+		t.Errorf("unexpected receipt %+v, %v", receipt, err)
+	}
+
+	if _, err := twilio.ParseReceipt("application/x-www-form-urlencoded", []byte("MessageStatus=sent")); !errors.Is(err, ErrNoReceipt) {
+		t.Error("expected an error for a receipt without message ID")
+	}
+}
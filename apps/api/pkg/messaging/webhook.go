@@ -0,0 +1,190 @@
+package messaging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultBodyTemplate is the request body sent when WebhookConfig.BodyTemplate is empty
+const DefaultBodyTemplate = `{"channel":{{json .Channel}},"to":{{json .To}},"body":{{json .Body}}}`
+
+// WebhookConfig configures a gateway that posts each message to a provider's HTTP API.
+//
+// The body template is a text/template over Message; the json function quotes a value for JSON
+// bodies and urlquery escapes it for form bodies. For example:
+//
+//	Twilio:            URL  https://api.twilio.com/2010-04-01/Accounts/<sid>/Messages.json
+//	                   Body To=whatsapp:{{urlquery .To}}&From=whatsapp:%2B62...&Body={{urlquery .Body}}
+//	                   Content type application/x-www-form-urlencoded, message ID field sid,
+//	                   receipt fields MessageSid and MessageStatus
+//	WhatsApp Business: URL  https://graph.facebook.com/v19.0/<phone-number-id>/messages
+//	                   Body {"messaging_product":"whatsapp","to":{{json .To}},"type":"text","text":{"body":{{json .Body}}}}
+//	                   Message ID field messages.0.id, receipt fields entry.0.changes.0.value.statuses.0.id
+//	                   and entry.0.changes.0.value.statuses.0.status
+type WebhookConfig struct {
+	URL                string
+	AuthHeader         string // Value of the Authorization header, e.g. "Bearer <token>"
+	ContentType        string // Defaults to application/json
+	BodyTemplate       string
+	MessageIDField     string // Dotted path of the message ID in the JSON response, e.g. messages.0.id
+	ReceiptIDField     string // Dotted path or form field of the message ID in a receipt
+	ReceiptStatusField string // Dotted path or form field of the status in a receipt
+	ReceiptErrorField  string // Optional dotted path or form field of the error in a receipt
+	Timeout            time.Duration
+}
+
+// WebhookGateway sends messages to a provider's HTTP API and parses its delivery receipts
+type WebhookGateway struct {
+	config WebhookConfig
+	body   *template.Template
+	client *http.Client
+}
+
+// NewWebhookGateway creates a gateway; it fails when the body template does not parse
+func NewWebhookGateway(config WebhookConfig) (*WebhookGateway, error) {
+	if config.ContentType == "" {
+		config.ContentType = "application/json"
+	}
+	if config.BodyTemplate == "" {
+		config.BodyTemplate = DefaultBodyTemplate
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 15 * time.Second
+	}
+
+	body, err := template.New("body").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			encoded, err := json.Marshal(v)
+			return string(encoded), err
+		},
+	}).Parse(config.BodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parse body template: %w", err)
+	}
+
+	return &WebhookGateway{
+		config: config,
+		body:   body,
+		client: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// Send posts the message and returns the provider message ID. Client errors other than
+// 429 Too Many Requests are returned as a PermanentError.
+func (g *WebhookGateway) Send(msg *Message) (string, error) {
+	var body bytes.Buffer
+	if err := g.body.Execute(&body, msg); err != nil {
+		return "", &PermanentError{Err: fmt.Errorf("render request body: %w", err)}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, g.config.URL, &body)
+	if err != nil {
+		return "", &PermanentError{Err: err}
+	}
+	req.Header.Set("Content-Type", g.config.ContentType)
+	req.Header.Set("Accept", "application/json")
+	if g.config.AuthHeader != "" {
+		req.Header.Set("Authorization", g.config.AuthHeader)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("call gateway: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("gateway returned %s: %s", resp.Status, snippet(respBody))
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return "", &PermanentError{Err: err}
+		}
+		return "", err
+	}
+
+	if g.config.MessageIDField == "" {
+		return "", nil
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(respBody, &decoded); err != nil {
+		return "", fmt.Errorf("decode gateway response: %w", err)
+	}
+	return lookup(decoded, g.config.MessageIDField), nil
+}
+
+// ParseReceipt reads a receipt posted as JSON or as a form
+func (g *WebhookGateway) ParseReceipt(contentType string, body []byte) (*Receipt, error) {
+	var field func(path string) string
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/x-www-form-urlencoded" {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("decode receipt: %w", err)
+		}
+		field = values.Get
+	} else {
+		var decoded interface{}
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			return nil, fmt.Errorf("decode receipt: %w", err)
+		}
+		field = func(path string) string { return lookup(decoded, path) }
+	}
+
+	receipt := &Receipt{MessageID: field(g.config.ReceiptIDField)}
+	if receipt.MessageID == "" {
+		return nil, fmt.Errorf("%w: no %s", ErrNoReceipt, g.config.ReceiptIDField)
+	}
+	receipt.Status = NormalizeStatus(field(g.config.ReceiptStatusField))
+	if g.config.ReceiptErrorField != "" {
+		receipt.Error = field(g.config.ReceiptErrorField)
+	}
+	return receipt, nil
+}
+
+// lookup follows a dotted path such as messages.0.id through decoded JSON; it returns "" when the path is missing
+func lookup(value interface{}, path string) string {
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return ""
+			}
+			value = v[i]
+		default:
+			return ""
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+}
+
+// snippet shortens a response body for error messages
+func snippet(body []byte) string {
+	s := strings.TrimSpace(string(body))
+	if len(s) > 200 {
+		return s[:200] + "..."
+	}
+	return s
+}
@@ -165,6 +165,7 @@ func getDeleteMessage(resourceType string) string {
 		"flow_rule":        "Flow rule berhasil dihapus",
 		"reminder":         "Reminder berhasil dihapus",
 		"product_category": "Product category berhasil dihapus",
+		"ai_conversation":  "Conversation berhasil dihapus",
 	}
 
 	if msg, ok := messages[resourceType]; ok {
@@ -0,0 +1,42 @@
+package seeders
+
+import (
+	"log"
+
+	"github.com/gilabs/crm-healthcare/api/internal/database"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/lead_scoring"
+)
+
+// SeedLeadScoringRules seeds the default lead scoring rules
+func SeedLeadScoringRules() error {
+	// Check if scoring rules already exist
+	var count int64
+	database.DB.Model(&lead_scoring.ScoringRule{}).Count(&count)
+	if count > 0 {
+		log.Println("Lead scoring rules already seeded, skipping...")
+		return nil
+	}
+
+	rules := []lead_scoring.ScoringRule{
+		{Name: "Referral or partner lead", Criterion: lead_scoring.CriterionLeadSource, Operator: lead_scoring.OperatorEquals, Value: "referral,partner", Points: 20},
+		{Name: "Event or website lead", Criterion: lead_scoring.CriterionLeadSource, Operator: lead_scoring.OperatorEquals, Value: "event,website", Points: 10},
+		{Name: "Healthcare industry", Criterion: lead_scoring.CriterionIndustry, Operator: lead_scoring.OperatorContains, Value: "health,hospital,pharma,clinic,medical", Points: 15},
+		{Name: "Decision maker", Criterion: lead_scoring.CriterionJobTitle, Operator: lead_scoring.OperatorContains, Value: "director,head,manager,owner,chief", Points: 15},
+		{Name: "Email provided", Criterion: lead_scoring.CriterionHasEmail, Operator: lead_scoring.OperatorIsSet, Points: 5},
+		{Name: "Phone provided", Criterion: lead_scoring.CriterionHasPhone, Operator: lead_scoring.OperatorIsSet, Points: 5},
+		{Name: "Engaged (3+ activities)", Criterion: lead_scoring.CriterionActivityCount, Operator: lead_scoring.OperatorGTE, Value: "3", Points: 10},
+		{Name: "Visited", Criterion: lead_scoring.CriterionVisitReportCount, Operator: lead_scoring.OperatorGTE, Value: "1", Points: 15},
+		{Name: "Touched in the last 14 days", Criterion: lead_scoring.CriterionDaysSinceLastTouch, Operator: lead_scoring.OperatorLTE, Value: "14", Points: 10},
+		{Name: "No touch for 60+ days", Criterion: lead_scoring.CriterionDaysSinceLastTouch, Operator: lead_scoring.OperatorGTE, Value: "60", Points: -15},
+	}
+
+	for i := range rules {
+		rules[i].IsActive = true
+		if err := database.DB.Create(&rules[i]).Error; err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Seeded %d lead scoring rules", len(rules))
+	return nil
+}
@@ -0,0 +1,71 @@
+package seeders
+
+import (
+	"log"
+
+	"github.com/gilabs/crm-healthcare/api/internal/database"
+	"github.com/gilabs/crm-healthcare/api/internal/domain/loss_reason"
+)
+
+// SeedLossReasons seeds the loss_reasons table
+func SeedLossReasons() error {
+	log.Println("Seeding loss reasons...")
+
+	lossReasons := []loss_reason.LossReason{
+		{
+			Name:        "Price",
+			Code:        "price",
+			Description: "Our offer was more expensive than the alternative",
+			Status:      "active",
+		},
+		{
+			Name:        "Budget Cut",
+			Code:        "budget_cut",
+			Description: "The customer no longer has budget for the purchase",
+			Status:      "active",
+		},
+		{
+			Name:        "Lost to Competitor",
+			Code:        "competitor",
+			Description: "The customer chose a competing product or vendor",
+			Status:      "active",
+		},
+		{
+			Name:        "No Decision",
+			Code:        "no_decision",
+			Description: "The customer did not make a decision",
+			Status:      "active",
+		},
+		{
+			Name:        "Product Fit",
+			Code:        "product_fit",
+			Description: "Our products did not meet the requirements of the customer",
+			Status:      "active",
+		},
+		{
+			Name:        "Timing",
+			Code:        "timing",
+			Description: "The purchase was postponed",
+			Status:      "active",
+		},
+	}
+
+	for _, lr := range lossReasons {
+		var existing loss_reason.LossReason
+		if err := database.DB.Where("code = ?", lr.Code).First(&existing).Error; err != nil {
+			if err.Error() == "record not found" {
+				if err := database.DB.Create(&lr).Error; err != nil {
+					return err
+				}
+				log.Printf("Created loss reason: %s", lr.Name)
+			} else {
+				return err
+			}
+		} else {
+			log.Printf("Loss reason %s already exists, skipping", lr.Code)
+		}
+	}
+
+	log.Println("Loss reasons seeded successfully")
+	return nil
+}
@@ -13,10 +13,6 @@ func SeedPermissions() error {
 	// Check if permissions already exist
 	var count int64
 	database.DB.Model(&permission.Permission{}).Count(&count)
-	if count > 0 {
-		log.Println("Permissions already seeded, skipping...")
-		return nil
-	}
 
 	// Get menus
 	var dashboardMenu permission.Menu
@@ -95,6 +91,9 @@ func SeedPermissions() error {
 		{userPageMenu.ID, "DELETE_USERS", "Delete Users", "DELETE", &userPageMenu},
 		{userPageMenu.ID, "ROLES", "Manage Roles", "ROLES", &userPageMenu},
 		{userPageMenu.ID, "PERMISSIONS", "Manage Permissions", "PERMISSIONS", &userPageMenu},
+		{userPageMenu.ID, "VIEW_AUDIT_LOGS", "View Audit Logs", "AUDIT", &userPageMenu},
+		{userPageMenu.ID, "MANAGE_EMAIL_OUTBOX", "Manage Email Outbox", "EMAIL", &userPageMenu},
+		{userPageMenu.ID, "MANAGE_MESSAGING", "Manage SMS & WhatsApp Messages", "MESSAGING", &userPageMenu},
 
 		// Sales CRM actions
 		{salesCRMMenu.ID, "VIEW_SALES_CRM", "View Sales CRM", "VIEW", &salesCRMMenu},
@@ -105,6 +104,8 @@ func SeedPermissions() error {
 		{accountsMenu.ID, "EDIT_ACCOUNTS", "Edit Accounts", "EDIT", &accountsMenu},
 		{accountsMenu.ID, "DELETE_ACCOUNTS", "Delete Accounts", "DELETE", &accountsMenu},
 		{accountsMenu.ID, "DETAIL_ACCOUNTS", "Detail Accounts", "DETAIL", &accountsMenu},
+		{accountsMenu.ID, "MERGE_ACCOUNTS", "Merge Duplicate Accounts", "MERGE", &accountsMenu},
+		{accountsMenu.ID, "IMPORT_ACCOUNTS", "Import Accounts and Contacts", "IMPORT", &accountsMenu},
 		{accountsMenu.ID, "CATEGORY", "Manage Categories", "CATEGORY", &accountsMenu},
 		{accountsMenu.ID, "ROLE", "Manage Contact Roles", "ROLE", &accountsMenu},
 
@@ -114,7 +115,11 @@ func SeedPermissions() error {
 		{leadsMenu.ID, "EDIT_LEADS", "Edit Leads", "EDIT", &leadsMenu},
 		{leadsMenu.ID, "DELETE_LEADS", "Delete Leads", "DELETE", &leadsMenu},
 		{leadsMenu.ID, "CONVERT_LEADS", "Convert Leads", "CONVERT", &leadsMenu},
+		{leadsMenu.ID, "MERGE_LEADS", "Merge Duplicate Leads", "MERGE", &leadsMenu},
+		{leadsMenu.ID, "IMPORT_LEADS", "Import Leads", "IMPORT", &leadsMenu},
 		{leadsMenu.ID, "CREATE_ACCOUNT_FROM_LEAD", "Create Account From Lead", "CREATE_ACCOUNT", &leadsMenu},
+		{leadsMenu.ID, "MANAGE_LEAD_SCORING", "Manage Lead Scoring", "SCORING", &leadsMenu},
+		{leadsMenu.ID, "MANAGE_LEAD_ASSIGNMENT", "Manage Lead Assignment", "ASSIGNMENT", &leadsMenu},
 		{leadsMenu.ID, "VIEW_ANALYTICS", "View Lead Analytics", "ANALYTICS", &leadsMenu},
 
 		// Pipeline actions
@@ -126,7 +131,14 @@ func SeedPermissions() error {
 		{pipelineMenu.ID, "MOVE_DEALS", "Move Deals", "MOVE", &pipelineMenu},
 		{pipelineMenu.ID, "VIEW_SUMMARY", "View Summary", "SUMMARY", &pipelineMenu},
 		{pipelineMenu.ID, "VIEW_FORECAST", "View Forecast", "FORECAST", &pipelineMenu},
+		{pipelineMenu.ID, "VIEW_PIPELINE_ANALYTICS", "View Pipeline Analytics", "ANALYTICS", &pipelineMenu},
 		{pipelineMenu.ID, "STAGES", "Manage Pipeline Stages", "STAGES", &pipelineMenu},
+		{pipelineMenu.ID, "PIPELINES", "Manage Pipelines", "PIPELINES", &pipelineMenu},
+		{pipelineMenu.ID, "VIEW_QUOTATIONS", "View Quotations", "VIEW_QUOTATIONS", &pipelineMenu},
+		{pipelineMenu.ID, "MANAGE_QUOTATIONS", "Create, Revise and Send Quotations", "QUOTATIONS", &pipelineMenu},
+		{pipelineMenu.ID, "CLOSE_QUOTATIONS", "Accept and Reject Quotations", "CLOSE_QUOTATIONS", &pipelineMenu},
+		{pipelineMenu.ID, "LOSS_REASONS", "Manage Loss Reasons", "LOSS_REASONS", &pipelineMenu},
+		{pipelineMenu.ID, "COMPETITORS", "Manage Competitors", "COMPETITORS", &pipelineMenu},
 
 		// Task & Reminder actions
 		{tasksMenu.ID, "VIEW_TASKS", "View Tasks", "VIEW", &tasksMenu},
@@ -149,6 +161,7 @@ func SeedPermissions() error {
 		{productsMenu.ID, "CREATE_PRODUCTS", "Create Products", "CREATE", &productsMenu},
 		{productsMenu.ID, "EDIT_PRODUCTS", "Edit Products", "EDIT", &productsMenu},
 		{productsMenu.ID, "DELETE_PRODUCTS", "Delete Products", "DELETE", &productsMenu},
+		{productsMenu.ID, "IMPORT_PRODUCTS", "Import Products", "IMPORT", &productsMenu},
 
 		// Product Categories actions (represented as tabs under Products menu, not a separate sidebar menu)
 		{productsMenu.ID, "VIEW_PRODUCT_CATEGORIES", "View Product Categories", "VIEW", &productsMenu},
@@ -160,6 +173,9 @@ func SeedPermissions() error {
 		{reportsMenu.ID, "VIEW_REPORTS", "View Reports", "VIEW", &reportsMenu},
 		{reportsMenu.ID, "GENERATE_REPORTS", "Generate Reports", "CREATE", &reportsMenu},
 		{reportsMenu.ID, "EXPORT_REPORTS", "Export Reports", "EXPORT", &reportsMenu},
+		{reportsMenu.ID, "VIEW_SALES_TARGETS", "View Sales Targets", "VIEW", &reportsMenu},
+		{reportsMenu.ID, "MANAGE_SALES_TARGETS", "Manage Sales Targets", "TARGETS", &reportsMenu},
+		{reportsMenu.ID, "MANAGE_REPORT_SUBSCRIPTIONS", "Manage Report Subscriptions", "SUBSCRIPTIONS", &reportsMenu},
 
 		// AI Chatbot actions
 		{aiChatbotMenu.ID, "VIEW_AI_CHATBOT", "View AI Chatbot", "VIEW", &aiChatbotMenu},
@@ -169,6 +185,39 @@ func SeedPermissions() error {
 		{aiSettingsMenu.ID, "EDIT_AI_SETTINGS", "Edit AI Settings", "EDIT", &aiSettingsMenu},
 	}
 
+	// Permissions already seeded: only add codes introduced since, keeping role assignments intact
+	if count > 0 {
+		createdCount := 0
+		for _, act := range actions {
+			var existing permission.Permission
+			if err := database.DB.Where("code = ?", act.code).First(&existing).Error; err == nil {
+				continue
+			}
+			perm := permission.Permission{
+				Name:   act.name,
+				Code:   act.code,
+				MenuID: &act.menuID,
+				Action: act.action,
+			}
+			if err := database.DB.Create(&perm).Error; err != nil {
+				return err
+			}
+			createdCount++
+			log.Printf("Created permission: %s (%s)", perm.Name, perm.Code)
+		}
+		if createdCount > 0 {
+			if err := SyncAdminPermissions(); err != nil {
+				log.Printf("Warning: Failed to sync admin permissions: %v", err)
+			}
+		}
+		// Routes now require these codes, so sales users of existing databases need them too
+		if err := SyncSalesPermissions(); err != nil {
+			log.Printf("Warning: Failed to sync sales permissions: %v", err)
+		}
+		log.Println("Permissions already seeded, skipping...")
+		return nil
+	}
+
 	// Create permissions
 	var permissionIDs []string
 	for _, act := range actions {
@@ -231,92 +280,8 @@ func SeedPermissions() error {
 		log.Printf("Warning: Viewer role not found, skipping viewer permission assignment: %v", err)
 	}
 
-	// Assign permissions to sales role
-	// Full access to Visit Reports and Task management (for mobile app)
-	var salesRole role.Role
-	if err := database.DB.Where("code = ?", "sales").First(&salesRole).Error; err == nil {
-		// Get all Visit Reports permissions
-		var visitReportPermissions []permission.Permission
-		if err := database.DB.Where("code IN (?)", []string{
-			"VIEW_VISIT_REPORTS",
-			"CREATE_VISIT_REPORTS",
-			"EDIT_VISIT_REPORTS",
-			"DELETE_VISIT_REPORTS",
-			"APPROVE_VISIT_REPORTS",
-			"REJECT_VISIT_REPORTS",
-			"ACTIVITY",
-		}).Find(&visitReportPermissions).Error; err == nil {
-			salesPermissionCount := 0
-			for _, perm := range visitReportPermissions {
-				if err := database.DB.Exec(
-					"INSERT INTO role_permissions (role_id, permission_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
-					salesRole.ID, perm.ID,
-				).Error; err != nil {
-					log.Printf("Warning: Failed to assign permission %s to sales: %v", perm.Code, err)
-				} else {
-					salesPermissionCount++
-				}
-			}
-			log.Printf("Assigned %d Visit Reports permissions to sales role", salesPermissionCount)
-		}
-
-		// Get Task permissions (VIEW, CREATE, EDIT for mobile app)
-		var taskPermissions []permission.Permission
-		if err := database.DB.Where("code IN (?)", []string{
-			"VIEW_TASKS",
-			"CREATE_TASKS",
-			"EDIT_TASKS",
-		}).Find(&taskPermissions).Error; err == nil {
-			taskPermissionCount := 0
-			for _, perm := range taskPermissions {
-				if err := database.DB.Exec(
-					"INSERT INTO role_permissions (role_id, permission_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
-					salesRole.ID, perm.ID,
-				).Error; err != nil {
-					log.Printf("Warning: Failed to assign permission %s to sales: %v", perm.Code, err)
-				} else {
-					taskPermissionCount++
-				}
-			}
-			log.Printf("Assigned %d Task permissions to sales role", taskPermissionCount)
-		}
-
-		// Get Accounts permissions (VIEW, CREATE, EDIT, DELETE for mobile app)
-		var accountsPermissions []permission.Permission
-		if err := database.DB.Where("code IN (?)", []string{
-			"VIEW_ACCOUNTS",
-			"CREATE_ACCOUNTS",
-			"EDIT_ACCOUNTS",
-			"DELETE_ACCOUNTS",
-		}).Find(&accountsPermissions).Error; err == nil {
-			accountsPermissionCount := 0
-			for _, perm := range accountsPermissions {
-				if err := database.DB.Exec(
-					"INSERT INTO role_permissions (role_id, permission_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
-					salesRole.ID, perm.ID,
-				).Error; err != nil {
-					log.Printf("Warning: Failed to assign permission %s to sales: %v", perm.Code, err)
-				} else {
-					accountsPermissionCount++
-				}
-			}
-			log.Printf("Assigned %d Accounts permissions to sales role", accountsPermissionCount)
-		}
-
-		// Also assign VIEW_DASHBOARD for mobile dashboard access
-		var dashboardPermission permission.Permission
-		if err := database.DB.Where("code = ?", "VIEW_DASHBOARD").First(&dashboardPermission).Error; err == nil {
-			if err := database.DB.Exec(
-				"INSERT INTO role_permissions (role_id, permission_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
-				salesRole.ID, dashboardPermission.ID,
-			).Error; err != nil {
-				log.Printf("Warning: Failed to assign VIEW_DASHBOARD to sales: %v", err)
-			} else {
-				log.Printf("Assigned VIEW_DASHBOARD permission to sales role")
-			}
-		}
-	} else {
-		log.Printf("Warning: Sales role not found, skipping sales permission assignment: %v", err)
+	if err := SyncSalesPermissions(); err != nil {
+		log.Printf("Warning: Failed to sync sales permissions: %v", err)
 	}
 
 	log.Println("Permissions seeded successfully")
@@ -353,3 +318,82 @@ func SyncAdminPermissions() error {
 	log.Printf("Synced %d permissions to admin role (total: %d)", assignedCount, len(allPermissions))
 	return nil
 }
+
+// salesPermissionCodes are the permissions of the sales role: the visit reports, tasks, accounts, leads,
+// pipeline and AI chat screens of the web and mobile apps
+var salesPermissionCodes = []string{
+	// Visit Reports
+	"VIEW_VISIT_REPORTS",
+	"CREATE_VISIT_REPORTS",
+	"EDIT_VISIT_REPORTS",
+	"DELETE_VISIT_REPORTS",
+	"APPROVE_VISIT_REPORTS",
+	"REJECT_VISIT_REPORTS",
+	"ACTIVITY",
+
+	// Tasks
+	"VIEW_TASKS",
+	"CREATE_TASKS",
+	"EDIT_TASKS",
+
+	// Accounts
+	"VIEW_ACCOUNTS",
+	"DETAIL_ACCOUNTS",
+	"CREATE_ACCOUNTS",
+	"EDIT_ACCOUNTS",
+	"DELETE_ACCOUNTS",
+
+	// Leads
+	"VIEW_LEADS",
+	"CREATE_LEADS",
+	"EDIT_LEADS",
+	"CONVERT_LEADS",
+	"CREATE_ACCOUNT_FROM_LEAD",
+
+	// Pipeline
+	"VIEW_PIPELINE",
+	"DETAIL_DEALS",
+	"CREATE_DEALS",
+	"EDIT_DEALS",
+	"MOVE_DEALS",
+	"VIEW_SUMMARY",
+	"VIEW_FORECAST",
+	"VIEW_QUOTATIONS",
+	"MANAGE_QUOTATIONS",
+
+	// Products picked as deal line items
+	"VIEW_PRODUCTS",
+
+	// Dashboard and AI chat
+	"VIEW_DASHBOARD",
+	"VIEW_AI_CHATBOT",
+}
+
+// SyncSalesPermissions grants the sales role every code in salesPermissionCodes it does not have yet
+func SyncSalesPermissions() error {
+	var salesRole role.Role
+	if err := database.DB.Where("code = ?", "sales").First(&salesRole).Error; err != nil {
+		log.Printf("Warning: Sales role not found, skipping sales permission assignment: %v", err)
+		return nil
+	}
+
+	var permissions []permission.Permission
+	if err := database.DB.Where("code IN (?)", salesPermissionCodes).Find(&permissions).Error; err != nil {
+		return err
+	}
+
+	assignedCount := 0
+	for _, perm := range permissions {
+		if err := database.DB.Exec(
+			"INSERT INTO role_permissions (role_id, permission_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+			salesRole.ID, perm.ID,
+		).Error; err != nil {
+			log.Printf("Warning: Failed to assign permission %s to sales: %v", perm.Code, err)
+		} else {
+			assignedCount++
+		}
+	}
+
+	log.Printf("Synced %d permissions to sales role", assignedCount)
+	return nil
+}
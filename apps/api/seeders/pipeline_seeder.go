@@ -14,7 +14,14 @@ import (
 // Note: "Lead" stage is NOT included because leads don't go into pipeline.
 // Leads are managed in Lead Management module, and only converted leads (deals) enter the pipeline.
 // Pipeline stages are for deals/opportunities only.
+// The stages belong to the default pipeline, which is created by the database migration.
 func SeedPipelineStages() error {
+	var defaultPipeline pipeline.Pipeline
+	if err := database.DB.Where("is_default = ?", true).First(&defaultPipeline).Error; err != nil {
+		log.Printf("Error finding default pipeline: %v", err)
+		return err
+	}
+
 	stages := []pipeline.PipelineStage{
 		{
 			Name:        "Qualification",
@@ -69,10 +76,12 @@ func SeedPipelineStages() error {
 	}
 
 	for _, stage := range stages {
+		stage.PipelineID = defaultPipeline.ID
+
 		var existing pipeline.PipelineStage
 		// Use Unscoped() to check including soft-deleted records
 		// This prevents duplicate key errors when a soft-deleted record exists
-		err := database.DB.Unscoped().Where("code = ?", stage.Code).First(&existing).Error
+		err := database.DB.Unscoped().Where("pipeline_id = ? AND code = ?", stage.PipelineID, stage.Code).First(&existing).Error
 		if err == nil {
 			// Stage already exists (even if soft-deleted), skip
 			log.Printf("Pipeline stage %s already exists, skipping...", stage.Code)
@@ -4,6 +4,7 @@ import (
 	"log"
 
 	"github.com/gilabs/crm-healthcare/api/internal/database"
+	"github.com/gilabs/crm-healthcare/api/internal/datascope"
 	"github.com/gilabs/crm-healthcare/api/internal/domain/role"
 )
 
@@ -45,6 +46,7 @@ func SeedRoles() error {
 			Description: "Sales role for mobile app access",
 			Status:      "active",
 			MobileAccess: true, // Only sales role can access mobile app
+			DataScope:   datascope.LevelOwn, // Sales reps only see their own territory
 		},
 		{
 			Name:        "Viewer",
@@ -64,6 +64,11 @@ func SeedAll() error {
 		return err
 	}
 
+	// Seed loss reasons (picked when a deal is closed as lost)
+	if err := SeedLossReasons(); err != nil {
+		return err
+	}
+
 	// Seed deals (requires users, accounts, contacts, pipeline stages)
 	if err := SeedDeals(); err != nil {
 		return err
@@ -94,6 +99,11 @@ func SeedAll() error {
 		return err
 	}
 
+	// Seed lead scoring rules
+	if err := SeedLeadScoringRules(); err != nil {
+		return err
+	}
+
 	// Seed leads (requires users for assigned_to and created_by)
 	if err := SeedLeads(); err != nil {
 		return err
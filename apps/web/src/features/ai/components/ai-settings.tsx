@@ -110,7 +110,7 @@ export function AISettings() {
               <SelectContent>
                 <SelectItem value="cerebras">Cerebras</SelectItem>
                 <SelectItem value="openai">OpenAI</SelectItem>
-                <SelectItem value="anthropic">Anthropic</SelectItem>
+                <SelectItem value="openai_compatible">OpenAI-compatible (Ollama, vLLM)</SelectItem>
               </SelectContent>
             </Select>
             <p className="text-xs text-muted-foreground">
@@ -31,6 +31,8 @@ import { DealDetailModal } from "@/features/sales-crm/pipeline-management/compon
 import { LeadDetailModal } from "@/features/sales-crm/lead-management/components/lead-detail-modal";
 import { templateCategories, getTemplatesByCategory, type ChatTemplate } from "../data/chat-templates";
 
+const CONVERSATION_ID_STORAGE_KEY = "ai_chatbot_conversation_id";
+
 interface Message {
   id: string;
   role: "user" | "assistant";
@@ -80,6 +82,10 @@ export function Chatbot() {
   
   // Persist conversation to sessionStorage
   const { clearConversation } = useConversationStorage(messages, setMessages);
+  // Server-side conversation the chat continues; history is loaded by the API
+  const [conversationId, setConversationId] = useState<string | undefined>(
+    () => (typeof window !== "undefined" ? sessionStorage.getItem(CONVERSATION_ID_STORAGE_KEY) ?? undefined : undefined)
+  );
   
   // Use settings.model as default, but allow user to override via Select
   const [userSelectedModel, setUserSelectedModel] = useState<string | null>(null);
@@ -187,14 +193,6 @@ export function Chatbot() {
     const currentInput = input;
     setInput("");
 
-    // Prepare conversation history (exclude the initial greeting and current message)
-    const conversationHistory = messages
-      .filter((msg) => msg.id !== "initial-greeting") // Exclude initial greeting
-      .map((msg) => ({
-        role: msg.role,
-        content: msg.content,
-      }));
-
     const modelToUse = selectedModel || settings.model || undefined;
     
     // Log model being sent
@@ -208,7 +206,7 @@ export function Chatbot() {
     sendMessage(
       {
         message: currentInput,
-        conversation_history: conversationHistory.length > 0 ? conversationHistory : undefined,
+        conversation_id: conversationId,
         model: modelToUse,
       },
       {
@@ -223,6 +221,11 @@ export function Chatbot() {
           console.log("Tokens used:", response.data.tokens);
           console.log("=========================");
 
+          if (response.data.conversation_id) {
+            setConversationId(response.data.conversation_id);
+            sessionStorage.setItem(CONVERSATION_ID_STORAGE_KEY, response.data.conversation_id);
+          }
+
           const assistantMessage: Message = {
             id: generateMessageId(),
             role: "assistant",
@@ -751,6 +754,8 @@ export function Chatbot() {
                     <button
                       onClick={() => {
                         clearConversation();
+                        setConversationId(undefined);
+                        sessionStorage.removeItem(CONVERSATION_ID_STORAGE_KEY);
                         setMessages([{
                           id: "initial-greeting",
                           role: "assistant",
@@ -11,8 +11,11 @@ import {
   DialogTitle,
 } from "@/components/ui/dialog";
 import { useAnalyzeVisitReport } from "../hooks/useAnalyzeVisitReport";
+import { useCreateTasksFromActionItems } from "../hooks/useCreateTasksFromActionItems";
 import type { VisitReportInsight } from "../types";
 import { Badge } from "@/components/ui/badge";
+import { Checkbox } from "@/components/ui/checkbox";
+import { toast } from "sonner";
 import { CheckCircle2, XCircle, AlertCircle } from "lucide-react";
 import { useTranslations } from "next-intl";
 
@@ -28,7 +31,9 @@ export function VisitReportInsightsButton({
   const t = useTranslations("visitReportInsights");
   const [open, setOpen] = useState(false);
   const [insight, setInsight] = useState<VisitReportInsight | null>(null);
+  const [selectedItemIds, setSelectedItemIds] = useState<string[]>([]);
   const { mutate: analyze, isPending } = useAnalyzeVisitReport();
+  const { mutate: createTasks, isPending: isCreatingTasks } = useCreateTasksFromActionItems();
 
   const handleAnalyze = () => {
     analyze(
@@ -36,12 +41,46 @@ export function VisitReportInsightsButton({
       {
         onSuccess: (response) => {
           setInsight(response.data.data);
+          setSelectedItemIds([]);
           setOpen(true);
         },
       }
     );
   };
 
+  const toggleActionItem = (id: string, checked: boolean) => {
+    setSelectedItemIds((prev) =>
+      checked ? [...prev, id] : prev.filter((itemId) => itemId !== id)
+    );
+  };
+
+  const handleCreateTasks = () => {
+    if (!insight) return;
+    const items = insight.action_items
+      .filter((item) => selectedItemIds.includes(item.id))
+      .map((item) => ({
+        id: item.id,
+        text: item.text,
+        priority: item.priority,
+        due_date: item.suggested_due_date ?? undefined,
+      }));
+
+    createTasks(
+      { visit_report_id: visitReportId, items },
+      {
+        onSuccess: () => {
+          toast.success(t("actionItems.tasksCreated"));
+          setSelectedItemIds([]);
+        },
+        onError: (error: unknown) => {
+          const message =
+            error instanceof Error ? error.message : t("actionItems.tasksFailed");
+          toast.error(message);
+        },
+      }
+    );
+  };
+
   const getSentimentColor = (sentiment: string) => {
     switch (sentiment) {
       case "positive":
@@ -146,11 +185,39 @@ export function VisitReportInsightsButton({
               {insight.action_items && insight.action_items.length > 0 && (
                 <div>
                   <h3 className="text-sm font-semibold mb-2">{t("sections.actionItems")}</h3>
-                  <ul className="list-disc list-inside space-y-1 text-sm text-muted-foreground">
-                    {insight.action_items.map((item, idx) => (
-                      <li key={idx}>{item}</li>
+                  <ul className="space-y-2 text-sm text-muted-foreground">
+                    {insight.action_items.map((item) => (
+                      <li key={item.id} className="flex items-start gap-2">
+                        <Checkbox
+                          className="mt-0.5"
+                          checked={selectedItemIds.includes(item.id)}
+                          onCheckedChange={(checked) => toggleActionItem(item.id, checked === true)}
+                        />
+                        <div className="flex-1">
+                          <p>{item.text}</p>
+                          <div className="mt-1 flex items-center gap-2 text-xs">
+                            <Badge variant="outline">{t(`actionItems.priority.${item.priority}`)}</Badge>
+                            {item.suggested_due_date && (
+                              <span>
+                                {t("actionItems.due", {
+                                  date: new Date(item.suggested_due_date).toLocaleDateString(),
+                                })}
+                              </span>
+                            )}
+                          </div>
+                        </div>
+                      </li>
                     ))}
                   </ul>
+                  <Button
+                    className="mt-3 gap-2"
+                    size="sm"
+                    onClick={handleCreateTasks}
+                    disabled={selectedItemIds.length === 0 || isCreatingTasks}
+                  >
+                    {isCreatingTasks && <Loader2 className="h-4 w-4 animate-spin" />}
+                    {isCreatingTasks ? t("actionItems.creatingTasks") : t("actionItems.createTasks")}
+                  </Button>
                 </div>
               )}
 
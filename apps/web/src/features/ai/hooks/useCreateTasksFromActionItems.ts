@@ -0,0 +1,15 @@
+import { useMutation, useQueryClient } from "@tanstack/react-query";
+import { aiService } from "../services/aiService";
+import type { CreateTasksFromActionItemsRequest } from "../types";
+
+export function useCreateTasksFromActionItems() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: (data: CreateTasksFromActionItemsRequest) =>
+      aiService.createTasksFromActionItems(data),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["tasks"] });
+    },
+  });
+}
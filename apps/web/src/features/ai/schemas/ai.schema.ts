@@ -19,7 +19,7 @@ export const chatSchema = z.object({
   context_type: z
     .enum(["visit_report", "deal", "contact", "account", "lead"])
     .optional(),
-  conversation_history: z.array(chatMessageSchema).optional(),
+  conversation_id: z.string().uuid().optional(),
 });
 
 export type ChatFormData = z.infer<typeof chatSchema>;
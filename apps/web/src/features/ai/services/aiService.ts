@@ -5,6 +5,7 @@ import type {
   ChatRequest,
   ChatAPIResponse,
   AISettingsResponse,
+  CreateTasksFromActionItemsRequest,
 } from "../types";
 
 export const aiService = {
@@ -18,6 +19,17 @@ export const aiService = {
     return response.data;
   },
 
+  async createTasksFromActionItems({
+    visit_report_id,
+    items,
+  }: CreateTasksFromActionItemsRequest): Promise<{ success: boolean; data: { id: string }[] }> {
+    const response = await apiClient.post<{ success: boolean; data: { id: string }[] }>(
+      `/tasks/from-visit-report/${visit_report_id}`,
+      { items }
+    );
+    return response.data;
+  },
+
   async chat(data: ChatRequest): Promise<ChatAPIResponse> {
     const response = await apiClient.post<ChatAPIResponse>("/ai/chat", data);
     return response.data;
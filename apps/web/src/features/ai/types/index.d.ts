@@ -1,8 +1,15 @@
 export type InsightType = "visit_report" | "deal" | "contact" | "account";
 
+export interface ActionItem {
+  id: string;
+  text: string;
+  priority: "low" | "medium" | "high" | "urgent";
+  suggested_due_date: string | null;
+}
+
 export interface VisitReportInsight {
   summary: string;
-  action_items: string[];
+  action_items: ActionItem[];
   sentiment: "positive" | "neutral" | "negative";
   key_points: string[];
   recommendations: string[];
@@ -18,6 +25,19 @@ export interface AnalyzeVisitReportRequest {
   visit_report_id: string;
 }
 
+export interface ActionItemTaskRequest {
+  id: string;
+  text: string;
+  priority?: ActionItem["priority"];
+  due_date?: string;
+  assigned_to?: string;
+}
+
+export interface CreateTasksFromActionItemsRequest {
+  visit_report_id: string;
+  items: ActionItemTaskRequest[];
+}
+
 export interface AnalyzeVisitReportResponse {
   success: boolean;
   data: InsightResponse;
@@ -34,7 +54,7 @@ export interface ChatRequest {
   message: string;
   context?: string;
   context_type?: "visit_report" | "deal" | "contact" | "account" | "lead";
-  conversation_history?: ChatMessage[];
+  conversation_id?: string;
   model?: string;
 }
 
@@ -47,13 +67,16 @@ export interface AISettingsResponse {
   api_key?: string;
   data_privacy: AIDataPrivacySettings;
   timezone: string;
+  role_token_quotas: Record<string, number>;
   created_at: string;
   updated_at: string;
 }
 
 export interface ChatResponse {
+  conversation_id?: string;
   message: string;
   tokens: number;
+  model?: string;
 }
 
 export interface ChatAPIResponse {
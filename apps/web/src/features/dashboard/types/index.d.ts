@@ -152,6 +152,15 @@ export interface TopAccount {
   last_visit_date?: string;
 }
 
+export interface SalesTargetAttainment {
+  revenue_target: number;
+  revenue_achieved: number;
+  revenue_progress_percent: number;
+  visit_target: number;
+  visits_achieved: number;
+  visit_progress_percent: number;
+}
+
 export interface TopSalesRep {
   sales_rep: {
     id: string;
@@ -161,6 +170,7 @@ export interface TopSalesRep {
   visit_count: number;
   account_count: number;
   activity_count: number;
+  attainment?: SalesTargetAttainment;
 }
 
 export interface RecentActivity {
@@ -1,4 +1,4 @@
-export type NotificationType = "reminder" | "task" | "deal" | "activity";
+export type NotificationType = "reminder" | "task" | "deal" | "activity" | "lead";
 
 export interface Notification {
   id: string;
@@ -67,6 +67,15 @@ export interface PipelineReport {
   }>;
 }
 
+export interface SalesTargetAttainment {
+  revenue_target: number;
+  revenue_achieved: number;
+  revenue_progress_percent: number;
+  visit_target: number;
+  visits_achieved: number;
+  visit_progress_percent: number;
+}
+
 export interface SalesPerformanceReport {
   period: {
     start: string;
@@ -82,6 +91,7 @@ export interface SalesPerformanceReport {
     account_count: number;
     activity_count: number;
     completion_rate: number;
+    attainment?: SalesTargetAttainment;
   }>;
   summary: {
     total_visits: number;
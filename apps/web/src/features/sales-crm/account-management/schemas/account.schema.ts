@@ -13,6 +13,7 @@ export const createAccountSchema = z.object({
   email: z.string().email("Invalid email format").optional().or(z.literal("")),
   status: z.enum(["active", "inactive"]).optional().default("active"),
   assigned_to: z.string().uuid("Invalid user ID").optional().or(z.literal("")),
+  ignore_duplicates: z.boolean().optional(),
 });
 
 export const updateAccountSchema = z.object({
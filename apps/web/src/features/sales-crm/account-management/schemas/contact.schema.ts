@@ -11,6 +11,7 @@ export const createContactSchema = z.object({
   email: z.string().email("Invalid email format").optional().or(z.literal("")),
   position: z.string().optional(),
   notes: z.string().optional(),
+  ignore_duplicates: z.boolean().optional(),
 });
 
 export const updateContactSchema = z.object({
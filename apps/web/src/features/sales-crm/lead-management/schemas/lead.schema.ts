@@ -19,6 +19,7 @@ export const createLeadSchema = z.object({
   postal_code: z.string().max(20, "Postal code must be at most 20 characters").optional(),
   country: z.string().max(100, "Country must be at most 100 characters").optional(),
   website: z.string().url("Invalid website URL").max(255, "Website must be at most 255 characters").optional().or(z.literal("")),
+  ignore_duplicates: z.boolean().optional(),
 });
 
 export const updateLeadSchema = z.object({
@@ -53,6 +54,7 @@ export const convertLeadSchema = z.object({
   create_contact: z.boolean().optional(),
   account_id: z.string().uuid("Invalid account ID").optional(),
   contact_id: z.string().uuid("Invalid contact ID").optional(),
+  ignore_duplicates: z.boolean().optional(),
 });
 
 export type CreateLeadFormData = z.infer<typeof createLeadSchema>;
@@ -1,3 +1,21 @@
+export interface LeadScoreItem {
+  rule_id: string;
+  name: string;
+  criterion: string;
+  points: number;
+}
+
+export interface DuplicateMatch {
+  entity_type: "lead" | "account" | "contact";
+  id: string;
+  name: string;
+  email: string;
+  phone: string;
+  city?: string;
+  score: number;
+  reasons: Array<"email" | "phone" | "name">;
+}
+
 export interface Lead {
   id: string;
   first_name: string;
@@ -10,6 +28,8 @@ export interface Lead {
   lead_source: string;
   lead_status: "new" | "contacted" | "qualified" | "unqualified" | "nurturing" | "disqualified" | "converted" | "lost";
   lead_score: number;
+  score_breakdown?: LeadScoreItem[];
+  scored_at?: string | null;
   assigned_to: string;
   assigned_user?: {
     id: string;